@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/smartctl"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,7 +36,8 @@ func (e *httpStatusError) Error() string {
 	return fmt.Sprintf("unexpected API status: %s: %s", e.Status, e.Body)
 }
 
-// authTransport is an http.RoundTripper that injects a Bearer token into every request.
+// authTransport is an http.RoundTripper that injects a Bearer token (when set) and the collector
+// version and host OS headers into every request.
 type authTransport struct {
 	base  http.RoundTripper
 	token string
@@ -39,10 +45,41 @@ type authTransport struct {
 
 func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req = req.Clone(req.Context())
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	req.Header.Set(pkg.HeaderCollectorVersion, version.VERSION)
+	req.Header.Set(pkg.HeaderHostOS, hostOSInfo())
+	req.Header.Set(pkg.HeaderHostArch, runtime.GOARCH)
 	return t.base.RoundTrip(req)
 }
 
+var (
+	hostOSInfoOnce  sync.Once
+	hostOSInfoValue string
+)
+
+// hostOSInfo returns the distribution name from /etc/os-release (PRETTY_NAME), falling back to
+// the Go OS name.
+func hostOSInfo() string {
+	hostOSInfoOnce.Do(func() {
+		hostOSInfoValue = runtime.GOOS
+		content, err := os.ReadFile("/etc/os-release")
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				if value = strings.Trim(strings.TrimSpace(value), `"'`); value != "" {
+					hostOSInfoValue = value
+				}
+				return
+			}
+		}
+	})
+	return hostOSInfoValue
+}
+
 // NewHTTPClient creates an HTTP client with the specified timeout in seconds
 func NewHTTPClient(timeoutSeconds int) *http.Client {
 	return &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
@@ -50,11 +87,10 @@ func NewHTTPClient(timeoutSeconds int) *http.Client {
 
 // NewAuthHTTPClient creates an HTTP client that injects a Bearer token when apiToken is non-empty.
 func NewAuthHTTPClient(timeoutSeconds int, apiToken string) *http.Client {
-	client := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
-	if apiToken != "" {
-		client.Transport = &authTransport{token: apiToken, base: http.DefaultTransport}
+	return &http.Client{
+		Timeout:   time.Duration(timeoutSeconds) * time.Second,
+		Transport: &authTransport{token: apiToken, base: http.DefaultTransport},
 	}
-	return client
 }
 
 func (c *BaseCollector) getJson(url string, target interface{}) error {
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/analogj/scrutiny/collector/pkg/collector"
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,8 +16,23 @@ func TestNewAuthHTTPClient_NoToken(t *testing.T) {
 
 	client := collector.NewAuthHTTPClient(60, "")
 
-	// With no token, Transport should be nil (uses http.DefaultTransport)
-	require.Nil(t, client.Transport, "transport should be nil when no token is provided")
+	// With no token, no Authorization header is sent, but the collector metadata headers are
+	var receivedAuth, receivedVersion, receivedOS string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAuth = r.Header.Get("Authorization")
+		receivedVersion = r.Header.Get(pkg.HeaderCollectorVersion)
+		receivedOS = r.Header.Get(pkg.HeaderHostOS)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Empty(t, receivedAuth, "no auth header should be set without token")
+	require.Equal(t, version.VERSION, receivedVersion)
+	require.NotEmpty(t, receivedOS)
 }
 
 func TestNewAuthHTTPClient_WithToken(t *testing.T) {
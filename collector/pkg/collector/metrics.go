@@ -37,12 +37,17 @@ func CreateMetricsCollector(appConfig config.Interface, logger *logrus.Entry, ap
 		return MetricsCollector{}, err
 	}
 
+	httpClient, err := NewAPIClient(appConfig, logger, appConfig.GetAPITimeout())
+	if err != nil {
+		return MetricsCollector{}, err
+	}
+
 	sc := MetricsCollector{
 		config:      appConfig,
 		apiEndpoint: apiEndpointUrl,
 		BaseCollector: BaseCollector{
 			logger:     logger,
-			httpClient: NewAuthHTTPClient(appConfig.GetAPITimeout(), appConfig.GetAPIToken()),
+			httpClient: httpClient,
 		},
 		shell: shell.Create(),
 	}
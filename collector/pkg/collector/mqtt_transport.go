@@ -0,0 +1,306 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/collector/pkg/config"
+	collectormodels "github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+)
+
+// API transports, selected with api.transport
+const (
+	TransportHTTP = "http"
+	TransportMQTT = "mqtt"
+)
+
+// mqttQoS is used for the requests and responses, so they are delivered at least once.
+const mqttQoS = 1
+
+const mqttConnectTimeout = 10 * time.Second
+
+// NewAPIClient creates the HTTP client the collectors use for API requests. With api.transport set
+// to mqtt, the requests are published to the MQTT broker instead, for hosts that can reach the
+// broker but not the Scrutiny web server. defaultTimeout (in seconds) applies unless api.timeout is set.
+func NewAPIClient(appConfig config.Interface, logger *logrus.Entry, defaultTimeout int) (*http.Client, error) {
+	if appConfig == nil {
+		return NewAuthHTTPClient(defaultTimeout, ""), nil
+	}
+
+	timeout := defaultTimeout
+	if appConfig.IsSet("api.timeout") {
+		timeout = appConfig.GetAPITimeout()
+	}
+	apiToken := appConfig.GetAPIToken()
+
+	switch transport := strings.ToLower(appConfig.GetString("api.transport")); transport {
+	case "", TransportHTTP:
+		return NewAuthHTTPClient(timeout, apiToken), nil
+	case TransportMQTT:
+		roundTripper, err := mqttRoundTripperFor(appConfig, logger, time.Duration(timeout)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{
+			Timeout:   time.Duration(timeout) * time.Second,
+			Transport: &authTransport{token: apiToken, base: roundTripper},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid api.transport %q: must be %s or %s", transport, TransportHTTP, TransportMQTT)
+	}
+}
+
+// MQTTClientID returns mqtt.client_id, defaulting to one per host so that the responses to a
+// host's requests are only delivered to that host.
+func MQTTClientID(appConfig config.Interface) string {
+	if clientID := strings.TrimSpace(appConfig.GetString("mqtt.client_id")); clientID != "" {
+		return clientID
+	}
+	host := strings.TrimSpace(appConfig.GetString("host.id"))
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	host = strings.NewReplacer("/", "_", "+", "_", "#", "_", " ", "_").Replace(host)
+	if host == "" {
+		host = "unknown"
+	}
+	return "scrutiny-collector-" + host
+}
+
+var (
+	mqttRoundTrippersMu sync.Mutex
+	// mqttRoundTrippers shares one broker connection between the collectors of a process
+	mqttRoundTrippers = map[string]*mqttRoundTripper{}
+)
+
+func mqttRoundTripperFor(appConfig config.Interface, logger *logrus.Entry, timeout time.Duration) (*mqttRoundTripper, error) {
+	broker := appConfig.GetString("mqtt.broker")
+	if broker == "" {
+		return nil, errors.New("mqtt.broker is required when api.transport is mqtt")
+	}
+	clientID := MQTTClientID(appConfig)
+	if err := collectormodels.ValidateTransportClientID(clientID); err != nil {
+		return nil, err
+	}
+	endpoint, err := url.Parse(appConfig.GetString("api.endpoint"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid api.endpoint: %w", err)
+	}
+
+	mqttRoundTrippersMu.Lock()
+	defer mqttRoundTrippersMu.Unlock()
+	key := broker + "|" + clientID
+	if rt, ok := mqttRoundTrippers[key]; ok {
+		return rt, nil
+	}
+
+	opts := pahomqtt.NewClientOptions()
+	opts.AddBroker(broker)
+	opts.SetClientID(clientID)
+	opts.SetUsername(appConfig.GetString("mqtt.username"))
+	opts.SetPassword(appConfig.GetString("mqtt.password"))
+	opts.SetAutoReconnect(true)
+	opts.SetConnectTimeout(mqttConnectTimeout)
+
+	rt := newMQTTRoundTripper(nil, clientID, endpoint.Path, timeout, logger)
+	// the broker drops the subscription with the session, renew it on every reconnect
+	opts.SetOnConnectHandler(func(client pahomqtt.Client) {
+		if err := rt.subscribe(); err != nil {
+			logger.Warnf("MQTT: %v", err)
+		}
+	})
+	rt.client = pahomqtt.NewClient(opts)
+	mqttRoundTrippers[key] = rt
+	return rt, nil
+}
+
+// mqttRoundTripper is an http.RoundTripper that publishes each request to the collector's request
+// topic and waits for the response with the same ID on its response topic.
+type mqttRoundTripper struct {
+	client   pahomqtt.Client
+	clientID string
+	basePath string // path of api.endpoint, stripped from the request paths
+	timeout  time.Duration
+	logger   *logrus.Entry
+
+	connectMu sync.Mutex
+	mu        sync.Mutex
+	pending   map[string]chan collectormodels.TransportResponse
+}
+
+func newMQTTRoundTripper(client pahomqtt.Client, clientID, basePath string, timeout time.Duration, logger *logrus.Entry) *mqttRoundTripper {
+	return &mqttRoundTripper{
+		client:   client,
+		clientID: clientID,
+		basePath: strings.TrimSuffix(basePath, "/") + "/",
+		timeout:  timeout,
+		logger:   logger,
+		pending:  map[string]chan collectormodels.TransportResponse{},
+	}
+}
+
+// connect connects to the broker on the first request, so creating a collector does not block.
+func (t *mqttRoundTripper) connect() error {
+	t.connectMu.Lock()
+	defer t.connectMu.Unlock()
+	if t.client.IsConnected() {
+		return nil
+	}
+	token := t.client.Connect()
+	if !token.WaitTimeout(mqttConnectTimeout) {
+		return fmt.Errorf("MQTT connect timed out after %v", mqttConnectTimeout)
+	}
+	if token.Error() != nil {
+		return fmt.Errorf("MQTT connect failed: %w", token.Error())
+	}
+	// subscribe before the first request is published, the connect handler runs asynchronously
+	return t.subscribe()
+}
+
+func (t *mqttRoundTripper) subscribe() error {
+	topic := collectormodels.TransportResponseTopic(t.clientID)
+	token := t.client.Subscribe(topic, mqttQoS, func(_ pahomqtt.Client, msg pahomqtt.Message) {
+		t.handleResponse(msg.Payload())
+	})
+	if !token.WaitTimeout(mqttConnectTimeout) {
+		return fmt.Errorf("MQTT subscribe to %s timed out", topic)
+	}
+	if token.Error() != nil {
+		return fmt.Errorf("MQTT subscribe to %s failed: %w", topic, token.Error())
+	}
+	return nil
+}
+
+func (t *mqttRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.connect(); err != nil {
+		return nil, err
+	}
+
+	envelope, err := t.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(chan collectormodels.TransportResponse, 1)
+	t.mu.Lock()
+	t.pending[envelope.ID] = responses
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, envelope.ID)
+		t.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	defer cancel()
+
+	token := t.client.Publish(collectormodels.TransportRequestTopic(t.clientID), mqttQoS, false, payload)
+	select {
+	case <-token.Done():
+		if token.Error() != nil {
+			return nil, fmt.Errorf("MQTT publish of %s %s failed: %w", req.Method, envelope.Path, token.Error())
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("MQTT publish of %s %s: %w", req.Method, envelope.Path, ctx.Err())
+	}
+
+	select {
+	case response := <-responses:
+		return buildHTTPResponse(req, response), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no MQTT response to %s %s: %w", req.Method, envelope.Path, ctx.Err())
+	}
+}
+
+func (t *mqttRoundTripper) buildRequest(req *http.Request) (*collectormodels.TransportRequest, error) {
+	id, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+	envelope := &collectormodels.TransportRequest{
+		ID:      id,
+		Method:  req.Method,
+		Path:    strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, t.basePath), "/"),
+		Query:   req.URL.RawQuery,
+		Headers: map[string]string{},
+	}
+	for name := range req.Header {
+		envelope.Headers[name] = req.Header.Get(name)
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			if !json.Valid(body) {
+				return nil, fmt.Errorf("the MQTT transport only supports JSON request bodies (%s %s)", req.Method, envelope.Path)
+			}
+			envelope.Body = body
+		}
+	}
+	return envelope, nil
+}
+
+func (t *mqttRoundTripper) handleResponse(payload []byte) {
+	var response collectormodels.TransportResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		t.logger.Warnf("MQTT: ignoring invalid response on %s: %v", collectormodels.TransportResponseTopic(t.clientID), err)
+		return
+	}
+
+	t.mu.Lock()
+	responses, ok := t.pending[response.ID]
+	t.mu.Unlock()
+	if !ok {
+		// a duplicate delivery (QoS 1) or the response to a request that timed out
+		t.logger.Debugf("MQTT: ignoring response to unknown request %s", response.ID)
+		return
+	}
+	select {
+	case responses <- response:
+	default:
+	}
+}
+
+func buildHTTPResponse(req *http.Request, response collectormodels.TransportResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode:    response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
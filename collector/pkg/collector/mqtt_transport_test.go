@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	collectormodels "github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type doneToken struct{ err error }
+
+func (t doneToken) Wait() bool                     { return true }
+func (t doneToken) WaitTimeout(time.Duration) bool { return true }
+func (t doneToken) Error() error                   { return t.err }
+func (t doneToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+type fakeMessage struct {
+	pahomqtt.Message
+	payload []byte
+}
+
+func (m fakeMessage) Payload() []byte { return m.payload }
+
+// fakeBroker answers every request published to it with respond, on the subscribed response topic.
+type fakeBroker struct {
+	pahomqtt.Client
+	respond  func(req collectormodels.TransportRequest) *collectormodels.TransportResponse
+	handlers map[string]pahomqtt.MessageHandler
+	requests []collectormodels.TransportRequest
+}
+
+func (b *fakeBroker) IsConnected() bool { return true }
+
+func (b *fakeBroker) Subscribe(topic string, _ byte, handler pahomqtt.MessageHandler) pahomqtt.Token {
+	b.handlers[topic] = handler
+	return doneToken{}
+}
+
+func (b *fakeBroker) Publish(topic string, qos byte, retained bool, payload interface{}) pahomqtt.Token {
+	var req collectormodels.TransportRequest
+	if err := json.Unmarshal(payload.([]byte), &req); err != nil {
+		return doneToken{err: err}
+	}
+	b.requests = append(b.requests, req)
+	if response := b.respond(req); response != nil {
+		responseJSON, _ := json.Marshal(response)
+		handler := b.handlers["scrutiny/collector/scrutiny-collector-nas/response"]
+		go handler(b, fakeMessage{payload: responseJSON})
+	}
+	return doneToken{}
+}
+
+func newFakeBrokerTransport(t *testing.T, respond func(req collectormodels.TransportRequest) *collectormodels.TransportResponse) (*http.Client, *fakeBroker) {
+	broker := &fakeBroker{respond: respond, handlers: map[string]pahomqtt.MessageHandler{}}
+	rt := newMQTTRoundTripper(broker, "scrutiny-collector-nas", "/scrutiny/", 200*time.Millisecond, logrus.WithField("test", t.Name()))
+	require.NoError(t, rt.subscribe())
+	return &http.Client{Transport: &authTransport{token: "secret", base: rt}}, broker
+}
+
+func TestMQTTRoundTripper_SendsRequestAndReturnsResponse(t *testing.T) {
+	client, broker := newFakeBrokerTransport(t, func(req collectormodels.TransportRequest) *collectormodels.TransportResponse {
+		return &collectormodels.TransportResponse{ID: req.ID, Status: http.StatusOK, Body: json.RawMessage(`{"success":true,"data":[]}`)}
+	})
+
+	resp, err := client.Post("http://scrutiny.local:8080/scrutiny/api/devices/register", "application/json", bytes.NewBufferString(`{"data":[]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"success":true,"data":[]}`, string(body))
+
+	require.Len(t, broker.requests, 1)
+	req := broker.requests[0]
+	require.NotEmpty(t, req.ID)
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "api/devices/register", req.Path)
+	require.Equal(t, "Bearer secret", req.Headers["Authorization"])
+	require.JSONEq(t, `{"data":[]}`, string(req.Body))
+}
+
+func TestMQTTRoundTripper_ReturnsErrorStatus(t *testing.T) {
+	client, _ := newFakeBrokerTransport(t, func(req collectormodels.TransportRequest) *collectormodels.TransportResponse {
+		return &collectormodels.TransportResponse{ID: req.ID, Status: http.StatusUnauthorized, Body: json.RawMessage(`{"success":false}`)}
+	})
+
+	resp, err := client.Post("http://scrutiny.local:8080/scrutiny/api/zfs/pools/register", "application/json", bytes.NewBufferString(`{}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Error(t, validateAPIResponse(nil, resp))
+}
+
+func TestMQTTRoundTripper_TimesOutWithoutResponse(t *testing.T) {
+	client, _ := newFakeBrokerTransport(t, func(req collectormodels.TransportRequest) *collectormodels.TransportResponse {
+		// a response to another request is ignored
+		return &collectormodels.TransportResponse{ID: "other", Status: http.StatusOK}
+	})
+
+	_, err := client.Post("http://scrutiny.local:8080/scrutiny/api/filesystems/summary", "application/json", bytes.NewBufferString(`{}`))
+	require.ErrorContains(t, err, "no MQTT response")
+}
+
+func TestMQTTRoundTripper_RejectsNonJSONBody(t *testing.T) {
+	client, broker := newFakeBrokerTransport(t, func(req collectormodels.TransportRequest) *collectormodels.TransportResponse {
+		return nil
+	})
+
+	_, err := client.Post("http://scrutiny.local:8080/scrutiny/api/devices/register", "text/plain", bytes.NewBufferString("not json"))
+	require.Error(t, err)
+	require.Empty(t, broker.requests)
+}
@@ -21,20 +21,15 @@ func CreateSelfTestCollector(appConfig config.Interface, logger *logrus.Entry, a
 		return SelfTestCollector{}, err
 	}
 
-	timeout := 60
-	if appConfig != nil && appConfig.IsSet("api.timeout") {
-		timeout = appConfig.GetAPITimeout()
-	}
-
-	apiToken := ""
-	if appConfig != nil {
-		apiToken = appConfig.GetAPIToken()
+	httpClient, err := NewAPIClient(appConfig, logger, 60)
+	if err != nil {
+		return SelfTestCollector{}, err
 	}
 
 	stc := SelfTestCollector{
 		BaseCollector: BaseCollector{
 			logger:     logger,
-			httpClient: NewAuthHTTPClient(timeout, apiToken),
+			httpClient: httpClient,
 		},
 		apiEndpoint: apiEndpointUrl,
 		logger:      logger,
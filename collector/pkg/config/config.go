@@ -50,6 +50,12 @@ func (c *configuration) Init() error {
 	c.SetDefault("api.endpoint", "http://localhost:8080")
 	c.SetDefault("api.timeout", 60)
 	c.SetDefault("api.token", "")
+	c.SetDefault("api.transport", "http")
+
+	c.SetDefault("mqtt.broker", "")
+	c.SetDefault("mqtt.username", "")
+	c.SetDefault("mqtt.password", "")
+	c.SetDefault("mqtt.client_id", "")
 
 	c.SetDefault("commands.metrics_smartctl_bin", "smartctl")
 	c.SetDefault(configKeyMetricsScanArgs, "--scan --json")
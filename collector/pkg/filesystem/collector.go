@@ -30,21 +30,16 @@ func CreateCollector(appConfig config.Interface, logger *logrus.Entry, apiEndpoi
 		return nil, err
 	}
 
-	timeout := 60
-	if appConfig != nil && appConfig.IsSet("api.timeout") {
-		timeout = appConfig.GetAPITimeout()
-	}
-
-	apiToken := ""
-	if appConfig != nil {
-		apiToken = appConfig.GetAPIToken()
+	httpClient, err := basecollector.NewAPIClient(appConfig, logger, 60)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Collector{
 		config:      appConfig,
 		logger:      logger,
 		apiEndpoint: apiEndpointURL,
-		httpClient:  basecollector.NewAuthHTTPClient(timeout, apiToken),
+		httpClient:  httpClient,
 		now:         time.Now,
 	}, nil
 }
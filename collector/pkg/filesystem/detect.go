@@ -20,6 +20,8 @@ type mountEntry struct {
 type statfsResult struct {
 	totalBytes     int64
 	availableBytes int64
+	totalInodes    int64
+	freeInodes     int64
 }
 
 var excludedFSTypes = map[string]struct{}{
@@ -103,6 +105,12 @@ func collectSnapshots(reader io.Reader, hostID string, now time.Time, statfsFn f
 			usedPercent = (float64(usedBytes) / float64(stats.totalBytes)) * 100
 		}
 
+		// filesystems without a fixed inode table (e.g. Btrfs) may report 0 total inodes
+		usedInodes := stats.totalInodes - stats.freeInodes
+		if usedInodes < 0 {
+			usedInodes = 0
+		}
+
 		snapshots = append(snapshots, models.FilesystemCapacity{
 			HostID:         hostID,
 			MountPoint:     mount.MountPoint,
@@ -112,6 +120,9 @@ func collectSnapshots(reader io.Reader, hostID string, now time.Time, statfsFn f
 			UsedBytes:      usedBytes,
 			AvailableBytes: stats.availableBytes,
 			UsedPercent:    usedPercent,
+			InodesTotal:    stats.totalInodes,
+			InodesUsed:     usedInodes,
+			InodesFree:     stats.freeInodes,
 			UpdatedAt:      now,
 		})
 	}
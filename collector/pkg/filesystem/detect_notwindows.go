@@ -24,5 +24,7 @@ func statfsForPath(path string) (statfsResult, error) {
 	return statfsResult{
 		totalBytes:     totalBytes,
 		availableBytes: availableBytes,
+		totalInodes:    int64(stat.Files), //nolint:gosec // inode counts fit in int64
+		freeInodes:     int64(stat.Ffree), //nolint:gosec // inode counts fit in int64
 	}, nil
 }
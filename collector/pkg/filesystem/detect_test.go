@@ -27,7 +27,7 @@ func TestCollectSnapshotsFiltersPseudoFilesystemsAndZFS(t *testing.T) {
 	statfsFn := func(path string) (statfsResult, error) {
 		switch path {
 		case "/":
-			return statfsResult{totalBytes: 1000, availableBytes: 250}, nil
+			return statfsResult{totalBytes: 1000, availableBytes: 250, totalInodes: 100, freeInodes: 40}, nil
 		case "/data":
 			return statfsResult{totalBytes: 2000, availableBytes: 500}, nil
 		case "/etc/hosts", "/opt/scrutiny/config":
@@ -45,6 +45,10 @@ func TestCollectSnapshotsFiltersPseudoFilesystemsAndZFS(t *testing.T) {
 	require.Equal(t, "/", snapshots[0].MountPoint)
 	require.Equal(t, "/data", snapshots[1].MountPoint)
 	require.InDelta(t, 75.0, snapshots[0].UsedPercent, 0.001)
+	require.Equal(t, int64(100), snapshots[0].InodesTotal)
+	require.Equal(t, int64(60), snapshots[0].InodesUsed)
+	require.Equal(t, int64(40), snapshots[0].InodesFree)
+	require.Zero(t, snapshots[1].InodesTotal)
 }
 
 func TestCollectSnapshotsFiltersContainerSpecificMountPoints(t *testing.T) {
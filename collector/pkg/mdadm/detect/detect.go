@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -29,6 +30,9 @@ type Detect struct {
 // /proc/mdstat is the native path on bare metal.
 var mdstatPaths = []string{"/host/proc/mdstat", "/proc/mdstat"}
 
+// mdSysfsRoots lists the sysfs mounts to read md attributes from, in the same order.
+var mdSysfsRoots = []string{"/host/sys", "/sys"}
+
 // openMdstat opens the first available mdstat file.
 func openMdstat() (*os.File, error) {
 	for _, path := range mdstatPaths {
@@ -163,6 +167,13 @@ func (d *Detect) enrichArrayMetrics(array *models.MDADMArray, metrics *models.MD
 		}
 	}
 
+	// The mismatch count is only exposed in sysfs and is updated by check and repair runs.
+	if mismatchCount, mismatchErr := readMismatchCount(name); mismatchErr != nil {
+		d.Logger.Debugf("Could not read mismatch count for %s: %v", name, mismatchErr)
+	} else {
+		metrics.MismatchCount = mismatchCount
+	}
+
 	// Get filesystem-level used bytes if the array is mounted in the container.
 	if usedBytes, statErr := d.getMountUsage(devicePath); statErr != nil {
 		d.Logger.Debugf("Could not get mount usage for %s (may not be mounted in container): %v", devicePath, statErr)
@@ -171,6 +182,19 @@ func (d *Detect) enrichArrayMetrics(array *models.MDADMArray, metrics *models.MD
 	}
 }
 
+// readMismatchCount reads /sys/block/<name>/md/mismatch_cnt, the number of sectors the last
+// check or repair found to be inconsistent between the array members.
+func readMismatchCount(name string) (int64, error) {
+	for _, root := range mdSysfsRoots {
+		content, err := os.ReadFile(filepath.Join(root, "block", name, "md", "mismatch_cnt"))
+		if err != nil {
+			continue
+		}
+		return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	}
+	return 0, fmt.Errorf("mismatch_cnt not found under any of: %v", mdSysfsRoots)
+}
+
 // mdstatProgressPattern matches the "check|resync|recovery|rebuild = X%" progress line in /proc/mdstat.
 var mdstatProgressPattern = regexp.MustCompile(`(?:check|resync|recovery|rebuild)\s*=\s*(\d+(?:\.\d+)?)%`)
 
@@ -3,6 +3,7 @@ package detect
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/analogj/scrutiny/collector/pkg/config"
@@ -166,6 +167,23 @@ func TestParseMdstatDevicesReturnsEmptySliceForMissingHeader(t *testing.T) {
 	assert.Empty(t, devices)
 }
 
+func TestReadMismatchCount(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "block", "md0", "md"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "block", "md0", "md", "mismatch_cnt"), []byte("128\n"), 0o644))
+
+	previous := mdSysfsRoots
+	mdSysfsRoots = []string{filepath.Join(root, "missing"), root}
+	t.Cleanup(func() { mdSysfsRoots = previous })
+
+	count, err := readMismatchCount("md0")
+	require.NoError(t, err)
+	assert.Equal(t, int64(128), count)
+
+	_, err = readMismatchCount("md1")
+	assert.Error(t, err)
+}
+
 func TestParseMdadmExportUUID(t *testing.T) {
 	output := `MD_LEVEL=raid1
 MD_DEVICES=2
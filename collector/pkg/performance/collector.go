@@ -38,21 +38,16 @@ func CreateCollector(appConfig config.Interface, logger *logrus.Entry, apiEndpoi
 		return nil, err
 	}
 
-	timeout := 300 // longer timeout for benchmarks
-	if appConfig != nil && appConfig.IsSet("api.timeout") {
-		timeout = appConfig.GetAPITimeout()
-	}
-
-	apiToken := ""
-	if appConfig != nil {
-		apiToken = appConfig.GetAPIToken()
+	httpClient, err := basecollector.NewAPIClient(appConfig, logger, 300) // longer timeout for benchmarks
+	if err != nil {
+		return nil, err
 	}
 
 	c := &Collector{
 		config:      appConfig,
 		logger:      logger,
 		apiEndpoint: apiEndpointUrl,
-		httpClient:  basecollector.NewAuthHTTPClient(timeout, apiToken),
+		httpClient:  httpClient,
 	}
 
 	return c, nil
@@ -9,7 +9,9 @@ import (
 
 	utils "github.com/analogj/go-util/utils"
 	"github.com/analogj/scrutiny/pkg/startup"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/backup"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/errors"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/web"
@@ -107,23 +109,7 @@ func newCLIApp(cfg config.Interface, bootstrapLogger *logrus.Entry) *cli.App {
 				Name:  "start",
 				Usage: "Start the scrutiny server",
 				Action: func(c *cli.Context) error {
-					if c.IsSet("config") {
-						if err := cfg.ReadConfig(c.String("config"), bootstrapLogger); err != nil { // Find and read the config file
-							//ignore "could not find config file"
-							bootstrapLogger.Printf("Could not find config file at specified path: %s", c.String("config"))
-							return err
-						}
-					}
-
-					if c.Bool("debug") {
-						cfg.Set("log.level", "DEBUG")
-					}
-
-					if c.IsSet(flagLogFile) {
-						cfg.Set(cfgKeyLogFile, c.String(flagLogFile))
-					}
-
-					webLogger, logFile, err := CreateLogger(cfg)
+					webLogger, logFile, err := setupCommand(c, cfg, bootstrapLogger)
 					if logFile != nil {
 						defer logFile.Close()
 					}
@@ -141,29 +127,130 @@ func newCLIApp(cfg config.Interface, bootstrapLogger *logrus.Entry) *cli.App {
 					return webServer.Start()
 				},
 
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:  "config",
-						Usage: "Specify the path to the config file",
-					},
-					&cli.StringFlag{
-						Name:    flagLogFile,
-						Usage:   "Path to file for logging. Leave empty to use STDOUT",
-						Value:   "",
-						EnvVars: []string{"SCRUTINY_LOG_FILE"},
-					},
+				Flags: commonFlags(),
+			},
+			{
+				Name:      "backup",
+				Usage:     "Write a backup archive of the database, time-series data and config file",
+				ArgsUsage: "[archive path]",
+				Action: func(c *cli.Context) error {
+					logger, logFile, err := setupCommand(c, cfg, bootstrapLogger)
+					if logFile != nil {
+						defer logFile.Close()
+					}
+					if err != nil {
+						return err
+					}
+
+					archivePath := c.Args().First()
+					if archivePath == "" {
+						archivePath = backup.ArchiveName(time.Now())
+					}
+
+					repo, err := database.NewScrutinyRepositoryWithoutMigration(cfg, logger)
+					if err != nil {
+						return err
+					}
+					defer repo.Close()
+
+					_, err = backup.Create(c.Context, cfg, logger, repo, archivePath, backup.Options{ConfigFile: commandConfigPath(c)})
+					return err
+				},
+				Flags: commonFlags(),
+			},
+			{
+				Name:      "restore",
+				Usage:     "Restore a backup archive created by `scrutiny backup` (Scrutiny must be stopped)",
+				ArgsUsage: "<archive path>",
+				Action: func(c *cli.Context) error {
+					logger, logFile, err := setupCommand(c, cfg, bootstrapLogger)
+					if logFile != nil {
+						defer logFile.Close()
+					}
+					if err != nil {
+						return err
+					}
 
+					archivePath := c.Args().First()
+					if archivePath == "" {
+						return fmt.Errorf("the path of the backup archive to restore is required")
+					}
+
+					opts := backup.RestoreOptions{Force: c.Bool("force")}
+					if c.Bool("restore-config") {
+						opts.ConfigFile = commandConfigPath(c)
+					}
+					_, err = backup.Restore(c.Context, cfg, logger, archivePath, opts, func() (database.DeviceRepo, error) {
+						return database.NewScrutinyRepository(cfg, logger)
+					})
+					return err
+				},
+				Flags: append(commonFlags(),
 					&cli.BoolFlag{
-						Name:    "debug",
-						Usage:   "Enable debug logging",
-						EnvVars: []string{"SCRUTINY_DEBUG", "DEBUG"},
+						Name:  "force",
+						Usage: "Replace an existing database (and config file, with --restore-config)",
 					},
-				},
+					&cli.BoolFlag{
+						Name:  "restore-config",
+						Usage: "Also restore the config file stored in the archive",
+					},
+				),
 			},
 		},
 	}
 }
 
+// commonFlags returns the flags shared by every command.
+func commonFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Specify the path to the config file",
+		},
+		&cli.StringFlag{
+			Name:    flagLogFile,
+			Usage:   "Path to file for logging. Leave empty to use STDOUT",
+			Value:   "",
+			EnvVars: []string{"SCRUTINY_LOG_FILE"},
+		},
+
+		&cli.BoolFlag{
+			Name:    "debug",
+			Usage:   "Enable debug logging",
+			EnvVars: []string{"SCRUTINY_DEBUG", "DEBUG"},
+		},
+	}
+}
+
+// setupCommand applies the common flags to the config and creates the command logger.
+func setupCommand(c *cli.Context, cfg config.Interface, bootstrapLogger *logrus.Entry) (*logrus.Entry, *os.File, error) {
+	if c.IsSet("config") {
+		if err := cfg.ReadConfig(c.String("config"), bootstrapLogger); err != nil { // Find and read the config file
+			//ignore "could not find config file"
+			bootstrapLogger.Printf("Could not find config file at specified path: %s", c.String("config"))
+			return nil, nil, err
+		}
+	}
+
+	if c.Bool("debug") {
+		cfg.Set("log.level", "DEBUG")
+	}
+
+	if c.IsSet(flagLogFile) {
+		cfg.Set(cfgKeyLogFile, c.String(flagLogFile))
+	}
+
+	return CreateLogger(cfg)
+}
+
+// commandConfigPath returns the config file used by the command: --config, or the default location.
+func commandConfigPath(c *cli.Context) string {
+	if c.IsSet("config") {
+		return c.String("config")
+	}
+	return resolveWebConfigPath()
+}
+
 func scrutinyBanner(projectName string) string {
 	versionInfo := "dev-" + version.VERSION
 	if len(goos) > 0 && len(goarch) > 0 {
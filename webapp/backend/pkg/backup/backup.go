@@ -0,0 +1,278 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
+	"github.com/sirupsen/logrus"
+)
+
+// Options controls the optional parts of a backup archive.
+type Options struct {
+	// ConfigFile is the scrutiny.yaml to include in the archive. Skipped when empty or missing.
+	ConfigFile string
+}
+
+// Create writes a backup archive (tar.gz) to outputPath containing a consistent snapshot of the
+// SQLite database, every point of the raw and down-sampled time-series buckets, the config file
+// and a manifest. With the SQLite time-series backend the points already live in the database
+// snapshot, so they are not exported a second time. The archive is written to a temporary file
+// first, so a failed backup never leaves a truncated archive behind.
+func Create(ctx context.Context, appConfig config.Interface, logger logrus.FieldLogger, repo database.DeviceRepo, outputPath string, opts Options) (*Manifest, error) {
+	if _, err := os.Stat(outputPath); err == nil {
+		return nil, fmt.Errorf("backup archive %s already exists", outputPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return nil, fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	workDir, err := os.MkdirTemp(filepath.Dir(outputPath), ".scrutiny-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create backup work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	migrations, err := repo.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		FormatVersion:     FormatVersion,
+		ScrutinyVersion:   version.VERSION,
+		CreatedAt:         time.Now().UTC(),
+		DatabaseType:      database.DATABASE_TYPE_SQLITE,
+		SchemaVersion:     database.SchemaVersion(migrations),
+		Migrations:        migrations,
+		TimeSeriesBackend: appConfig.GetString("web.timeseries.backend"),
+		TimeSeriesBucket:  appConfig.GetString("web.influxdb.bucket"),
+		Buckets:           map[string]int{},
+	}
+
+	logger.Infof("Backing up database...")
+	if err := repo.BackupDatabase(ctx, filepath.Join(workDir, databaseFileName)); err != nil {
+		return nil, err
+	}
+
+	entries := []string{databaseFileName}
+	if strings.EqualFold(manifest.TimeSeriesBackend, database.TIMESERIES_BACKEND_SQLITE) {
+		logger.Infof("Time-series data is stored in the database, skipping time-series export")
+	} else {
+		logger.Infof("Backing up time-series data...")
+		if err := exportTimeSeries(ctx, repo, filepath.Join(workDir, timeSeriesFileName), manifest); err != nil {
+			return nil, err
+		}
+		entries = append(entries, timeSeriesFileName)
+	}
+
+	if opts.ConfigFile != "" {
+		if _, err := os.Stat(opts.ConfigFile); err == nil {
+			if err := copyFile(opts.ConfigFile, filepath.Join(workDir, configFileName)); err != nil {
+				return nil, fmt.Errorf("could not copy config file: %w", err)
+			}
+			entries = append(entries, configFileName)
+		} else {
+			logger.Warnf("Config file %s not found, it will not be included in the backup", opts.ConfigFile)
+		}
+	}
+
+	for _, name := range entries {
+		file, err := describeFile(filepath.Join(workDir, name), name)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	tmpPath := outputPath + ".tmp"
+	if err := writeArchive(tmpPath, workDir, manifest, entries); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("could not finalize backup archive: %w", err)
+	}
+
+	logger.Infof("Backup written to %s (schema %s, %d time-series buckets)", outputPath, manifest.SchemaVersion, len(manifest.Buckets))
+	return manifest, nil
+}
+
+// ReadManifest reads the manifest of a backup archive without extracting anything else.
+func ReadManifest(archivePath string) (*Manifest, error) {
+	var manifest *Manifest
+	err := walkArchive(archivePath, func(header *tar.Header, reader io.Reader) (bool, error) {
+		if header.Name != manifestFileName {
+			return false, fmt.Errorf("invalid backup archive: expected %s as the first entry, found %s", manifestFileName, header.Name)
+		}
+		manifest = &Manifest{}
+		if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+			return false, fmt.Errorf("could not parse backup manifest: %w", err)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("invalid backup archive: %s is empty", archivePath)
+	}
+	return manifest, nil
+}
+
+func exportTimeSeries(ctx context.Context, repo database.DeviceRepo, path string, manifest *Manifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	buckets, err := repo.ExportTimeSeries(ctx, func(point database.TimeSeriesExportPoint) error {
+		manifest.Buckets[point.Bucket]++
+		return encoder.Encode(point)
+	})
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		// record empty buckets as well, so the manifest lists everything that was exported
+		manifest.Buckets[bucket] += 0
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeArchive(path string, workDir string, manifest *Manifest, entries []string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not create backup archive: %w", err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: manifestFileName, Mode: 0o600, Size: int64(len(manifestData)), ModTime: manifest.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(manifestData); err != nil {
+		return err
+	}
+
+	for _, name := range entries {
+		if err := addArchiveFile(tarWriter, filepath.Join(workDir, name), name, manifest.CreatedAt); err != nil {
+			return fmt.Errorf("could not add %s to backup archive: %w", name, err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func addArchiveFile(tarWriter *tar.Writer, path string, name string, modTime time.Time) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: info.Size(), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// walkArchive calls fn for every entry of a tar.gz archive until fn returns false or an error.
+func walkArchive(archivePath string, fn func(header *tar.Header, reader io.Reader) (bool, error)) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("could not open backup archive: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backup archive: %w", err)
+		}
+		next, err := fn(header, tarReader)
+		if err != nil || !next {
+			return err
+		}
+	}
+}
+
+func describeFile(path string, name string) (ManifestFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{Name: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func copyFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
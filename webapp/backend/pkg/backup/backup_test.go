@@ -0,0 +1,196 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig(t *testing.T, dir string) config.Interface {
+	t.Helper()
+
+	cfg, err := config.Create()
+	require.NoError(t, err)
+	cfg.Set("web.database.location", filepath.Join(dir, "scrutiny.db"))
+	cfg.Set("web.timeseries.backend", "sqlite")
+	cfg.Set("web.influxdb.retention_policy", false)
+	return cfg
+}
+
+func newTestRepo(t *testing.T, cfg config.Interface) database.DeviceRepo {
+	t.Helper()
+
+	database.ResetMigrationGuardForTests()
+	repo, err := database.NewScrutinyRepository(cfg, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestCreateAndRestore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+
+	sourceDir := t.TempDir()
+	sourceConfig := newTestConfig(t, sourceDir)
+	sourceRepo := newTestRepo(t, sourceConfig)
+
+	require.NoError(t, sourceRepo.RegisterDevice(ctx, models.Device{DeviceID: "device-1", WWN: "wwn-1", ModelName: "Disk", SerialNumber: "S1"}))
+	pointTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, sourceRepo.ImportTimeSeriesPoint(ctx, database.TimeSeriesExportPoint{
+		Bucket:      "metrics",
+		Measurement: "temp",
+		Tags:        map[string]string{"device_wwn": "wwn-1"},
+		Fields:      map[string]interface{}{"temp": int64(41)},
+		Time:        pointTime,
+	}))
+
+	configFile := filepath.Join(sourceDir, "scrutiny.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("version: 1\n"), 0o600))
+
+	archivePath := filepath.Join(sourceDir, "backups", "backup.tar.gz")
+	manifest, err := Create(ctx, sourceConfig, logger, sourceRepo, archivePath, Options{ConfigFile: configFile})
+	require.NoError(t, err)
+	require.Equal(t, FormatVersion, manifest.FormatVersion)
+	require.NotEmpty(t, manifest.SchemaVersion)
+	// the SQLite time-series backend keeps its points in the database snapshot
+	require.Empty(t, manifest.Buckets)
+	require.Len(t, manifest.Files, 2)
+	_, ok := manifest.File(timeSeriesFileName)
+	require.False(t, ok)
+
+	readManifest, err := ReadManifest(archivePath)
+	require.NoError(t, err)
+	require.Equal(t, manifest.SchemaVersion, readManifest.SchemaVersion)
+
+	// restore into a fresh installation
+	targetDir := t.TempDir()
+	targetConfig := newTestConfig(t, targetDir)
+	restoredConfigFile := filepath.Join(targetDir, "scrutiny.yaml")
+	var targetRepo database.DeviceRepo
+	_, err = Restore(ctx, targetConfig, logger, archivePath, RestoreOptions{ConfigFile: restoredConfigFile}, func() (database.DeviceRepo, error) {
+		targetRepo = newTestRepo(t, targetConfig)
+		return targetRepo, nil
+	})
+	require.NoError(t, err)
+
+	device, err := targetRepo.GetDeviceByID(ctx, "device-1")
+	require.NoError(t, err)
+	require.Equal(t, "wwn-1", device.WWN)
+
+	history, err := targetRepo.GetSmartTemperatureHistory(ctx, database.DURATION_KEY_DAY)
+	require.NoError(t, err)
+	require.Len(t, history["device-1"], 1)
+	require.Equal(t, int64(41), history["device-1"][0].Temp)
+
+	restoredConfig, err := os.ReadFile(restoredConfigFile)
+	require.NoError(t, err)
+	require.Equal(t, "version: 1\n", string(restoredConfig))
+
+	// an existing database is only replaced with Force
+	_, err = Restore(ctx, targetConfig, logger, archivePath, RestoreOptions{}, nil)
+	require.ErrorContains(t, err, "already exists")
+}
+
+func TestManifestValidate(t *testing.T) {
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		Migrations:    []string{"m1", "m2"},
+		Files:         []ManifestFile{{Name: databaseFileName}},
+	}
+	require.NoError(t, manifest.Validate([]string{"m1", "m2", "m3"}))
+	require.ErrorContains(t, manifest.Validate([]string{"m1"}), "newer Scrutiny version")
+
+	manifest.FormatVersion = FormatVersion + 1
+	require.ErrorContains(t, manifest.Validate([]string{"m1", "m2"}), "unsupported backup format version")
+
+	manifest.FormatVersion = FormatVersion
+	manifest.Files = nil
+	require.ErrorContains(t, manifest.Validate([]string{"m1", "m2"}), "does not contain")
+
+	for _, name := range []string{"../scrutiny.db", "/etc/cron.d/scrutiny", "nested/scrutiny.db", "other.db"} {
+		manifest.Files = []ManifestFile{{Name: databaseFileName}, {Name: name}}
+		require.ErrorContains(t, manifest.Validate([]string{"m1", "m2"}), "unexpected file", name)
+	}
+	manifest.Files = []ManifestFile{{Name: databaseFileName}, {Name: databaseFileName}}
+	require.ErrorContains(t, manifest.Validate([]string{"m1", "m2"}), "more than once")
+}
+
+func TestExtractArchive_RejectsUnsafeEntries(t *testing.T) {
+	manifest := &Manifest{Files: []ManifestFile{{Name: databaseFileName}}}
+	for name, header := range map[string]*tar.Header{
+		"traversal": {Name: "../" + databaseFileName, Typeflag: tar.TypeReg, Mode: 0o600},
+		"absolute":  {Name: "/tmp/" + databaseFileName, Typeflag: tar.TypeReg, Mode: 0o600},
+		"symlink":   {Name: databaseFileName, Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd", Mode: 0o600},
+		"directory": {Name: databaseFileName, Typeflag: tar.TypeDir, Mode: 0o700},
+	} {
+		archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+		out, err := os.Create(archivePath)
+		require.NoError(t, err)
+		gzipWriter := gzip.NewWriter(out)
+		tarWriter := tar.NewWriter(gzipWriter)
+		require.NoError(t, tarWriter.WriteHeader(header))
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		require.NoError(t, out.Close())
+
+		workDir := t.TempDir()
+		require.Error(t, extractArchive(archivePath, workDir, manifest), name)
+		entries, err := os.ReadDir(workDir)
+		require.NoError(t, err)
+		require.Empty(t, entries, name)
+	}
+}
+
+func TestRestore_RejectsCorruptArchive(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+
+	sourceDir := t.TempDir()
+	sourceConfig := newTestConfig(t, sourceDir)
+	sourceRepo := newTestRepo(t, sourceConfig)
+	archivePath := filepath.Join(sourceDir, "backup.tar.gz")
+	_, err := Create(ctx, sourceConfig, logger, sourceRepo, archivePath, Options{})
+	require.NoError(t, err)
+
+	// rewrite the archive, truncating the database entry while keeping the original manifest
+	corruptPath := filepath.Join(sourceDir, "corrupt.tar.gz")
+	out, err := os.Create(corruptPath)
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, walkArchive(archivePath, func(header *tar.Header, reader io.Reader) (bool, error) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return false, err
+		}
+		if header.Name == databaseFileName {
+			data = data[:len(data)/2]
+		}
+		header.Size = int64(len(data))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return false, err
+		}
+		_, err = tarWriter.Write(data)
+		return true, err
+	}))
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, out.Close())
+
+	targetConfig := newTestConfig(t, t.TempDir())
+	_, err = Restore(ctx, targetConfig, logger, corruptPath, RestoreOptions{}, nil)
+	require.ErrorContains(t, err, "checksum mismatch")
+	require.NoFileExists(t, targetConfig.GetString("web.database.location"))
+}
@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// FormatVersion is bumped whenever the archive layout changes incompatibly.
+	FormatVersion = 1
+
+	manifestFileName   = "manifest.json"
+	databaseFileName   = "scrutiny.db"
+	timeSeriesFileName = "timeseries.jsonl"
+	configFileName     = "scrutiny.yaml"
+)
+
+// archiveFileNames are the only entries, besides the manifest, that a backup archive may contain.
+var archiveFileNames = map[string]bool{
+	databaseFileName:   true,
+	timeSeriesFileName: true,
+	configFileName:     true,
+}
+
+// Manifest describes the contents of a backup archive. It is always the first entry of the archive,
+// so restores can validate compatibility before extracting anything.
+type Manifest struct {
+	FormatVersion   int       `json:"format_version"`
+	ScrutinyVersion string    `json:"scrutiny_version"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	DatabaseType string `json:"database_type"`
+	// SchemaVersion is the newest applied schema migration; Migrations lists every applied migration.
+	SchemaVersion string   `json:"schema_version"`
+	Migrations    []string `json:"migrations"`
+
+	TimeSeriesBackend string `json:"timeseries_backend"`
+	// TimeSeriesBucket is the base bucket name; exported buckets are named after it (e.g. metrics_weekly).
+	TimeSeriesBucket string         `json:"timeseries_bucket"`
+	Buckets          map[string]int `json:"buckets"`
+
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile records the size and checksum of a single archive entry.
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// File returns the manifest entry for name, if present.
+func (m *Manifest) File(name string) (ManifestFile, bool) {
+	for _, file := range m.Files {
+		if file.Name == name {
+			return file, true
+		}
+	}
+	return ManifestFile{}, false
+}
+
+// Validate checks that this build of Scrutiny can restore the archive: the archive format must be
+// supported and every migration applied to the backed-up database must be known to this build
+// (otherwise the backup was taken by a newer version with a schema we cannot read).
+func (m *Manifest) Validate(knownMigrations []string) error {
+	if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+		return fmt.Errorf("unsupported backup format version %d (this build supports up to %d)", m.FormatVersion, FormatVersion)
+	}
+	if _, ok := m.File(databaseFileName); !ok {
+		return fmt.Errorf("backup archive does not contain %s", databaseFileName)
+	}
+	seen := map[string]bool{}
+	for _, file := range m.Files {
+		if !archiveFileNames[file.Name] {
+			return fmt.Errorf("backup manifest lists unexpected file %q", file.Name)
+		}
+		if seen[file.Name] {
+			return fmt.Errorf("backup manifest lists %s more than once", file.Name)
+		}
+		seen[file.Name] = true
+	}
+
+	known := map[string]bool{}
+	for _, id := range knownMigrations {
+		known[id] = true
+	}
+	unknown := []string{}
+	for _, id := range m.Migrations {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("backup was created by a newer Scrutiny version (%s, schema %s) with migrations this build does not know: %v",
+			m.ScrutinyVersion, m.SchemaVersion, unknown)
+	}
+	return nil
+}
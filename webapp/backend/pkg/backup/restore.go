@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/sirupsen/logrus"
+)
+
+// RestoreOptions controls how a backup archive is restored.
+type RestoreOptions struct {
+	// Force replaces an existing database at web.database.location.
+	Force bool
+	// ConfigFile receives the scrutiny.yaml stored in the archive. Skipped when empty.
+	ConfigFile string
+}
+
+// Restore validates a backup archive against this build, then replaces the SQLite database with the
+// archived snapshot and re-imports the archived time-series points into the configured backend.
+// Archives taken with the SQLite time-series backend carry their points in the database snapshot.
+// Scrutiny must not be running while a restore is in progress.
+//
+// repoFactory is called once the database is in place; it should run migrations so that backups
+// taken by older versions are upgraded to the current schema before time-series data is imported.
+func Restore(ctx context.Context, appConfig config.Interface, logger logrus.FieldLogger, archivePath string, opts RestoreOptions, repoFactory func() (database.DeviceRepo, error)) (*Manifest, error) {
+	manifest, err := ReadManifest(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.Validate(database.KnownMigrationIDs()); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(appConfig.GetString("web.database.type"), database.DATABASE_TYPE_POSTGRES) {
+		return nil, fmt.Errorf("restore requires the SQLite metadata store; use pg_restore or managed backups for PostgreSQL")
+	}
+
+	databasePath := appConfig.GetString("web.database.location")
+	if _, err := os.Stat(databasePath); err == nil && !opts.Force {
+		return nil, fmt.Errorf("database %s already exists; stop Scrutiny and re-run with --force to replace it", databasePath)
+	}
+	if opts.ConfigFile != "" {
+		if _, err := os.Stat(opts.ConfigFile); err == nil && !opts.Force {
+			return nil, fmt.Errorf("config file %s already exists; re-run with --force to replace it", opts.ConfigFile)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(databasePath), 0o755); err != nil {
+		return nil, fmt.Errorf("could not create database directory: %w", err)
+	}
+
+	// extract (and verify) every entry before touching the existing installation
+	workDir, err := os.MkdirTemp(filepath.Dir(databasePath), ".scrutiny-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create restore work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	if err := extractArchive(archivePath, workDir, manifest); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Restoring database to %s", databasePath)
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(databasePath + suffix); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not remove stale %s file: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(filepath.Join(workDir, databaseFileName), databasePath); err != nil {
+		return nil, fmt.Errorf("could not move restored database into place: %w", err)
+	}
+
+	if opts.ConfigFile != "" {
+		if _, ok := manifest.File(configFileName); ok {
+			logger.Infof("Restoring config file to %s", opts.ConfigFile)
+			if err := copyFile(filepath.Join(workDir, configFileName), opts.ConfigFile); err != nil {
+				return nil, fmt.Errorf("could not restore config file: %w", err)
+			}
+		} else {
+			logger.Warnf("Backup archive does not contain a config file, %s left unchanged", opts.ConfigFile)
+		}
+	}
+
+	repo, err := repoFactory()
+	if err != nil {
+		return nil, fmt.Errorf("could not open restored database: %w", err)
+	}
+	defer repo.Close()
+
+	if _, ok := manifest.File(timeSeriesFileName); !ok {
+		if strings.EqualFold(manifest.TimeSeriesBackend, database.TIMESERIES_BACKEND_SQLITE) &&
+			!strings.EqualFold(appConfig.GetString("web.timeseries.backend"), database.TIMESERIES_BACKEND_SQLITE) {
+			logger.Warnf("Backup stores its time-series data in the SQLite database, but web.timeseries.backend is %q; set it to %q to use the restored history",
+				appConfig.GetString("web.timeseries.backend"), database.TIMESERIES_BACKEND_SQLITE)
+		}
+		logger.Infof("Restore complete (schema %s)", manifest.SchemaVersion)
+		return manifest, nil
+	}
+
+	logger.Infof("Restoring time-series data...")
+	imported, err := importTimeSeries(ctx, repo, filepath.Join(workDir, timeSeriesFileName), manifest.TimeSeriesBucket, appConfig.GetString("web.influxdb.bucket"))
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Restore complete: imported %d time-series points (schema %s)", imported, manifest.SchemaVersion)
+	return manifest, nil
+}
+
+func extractArchive(archivePath string, workDir string, manifest *Manifest) error {
+	extracted := map[string]bool{}
+	err := walkArchive(archivePath, func(header *tar.Header, reader io.Reader) (bool, error) {
+		if header.Name == manifestFileName {
+			return true, nil
+		}
+		// only extract the known files, so a crafted archive cannot write outside the work directory
+		if !archiveFileNames[header.Name] {
+			return false, fmt.Errorf("backup archive contains unexpected entry %q", header.Name)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return false, fmt.Errorf("backup archive entry %s is not a regular file", header.Name)
+		}
+		if extracted[header.Name] {
+			return false, fmt.Errorf("backup archive contains %s more than once", header.Name)
+		}
+		expected, ok := manifest.File(header.Name)
+		if !ok {
+			return false, fmt.Errorf("backup archive contains unexpected entry %s", header.Name)
+		}
+
+		out, err := os.OpenFile(filepath.Join(workDir, expected.Name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return false, err
+		}
+		hash := sha256.New()
+		size, err := io.Copy(io.MultiWriter(out, hash), reader)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not extract %s: %w", expected.Name, err)
+		}
+		if size != expected.Size || hex.EncodeToString(hash.Sum(nil)) != expected.SHA256 {
+			return false, fmt.Errorf("backup archive is corrupt: checksum mismatch for %s", expected.Name)
+		}
+		extracted[expected.Name] = true
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range manifest.Files {
+		if !extracted[file.Name] {
+			return fmt.Errorf("backup archive is incomplete: missing %s", file.Name)
+		}
+	}
+	return nil
+}
+
+// importTimeSeries writes every archived point back, renaming buckets when the archive was taken
+// with a different base bucket name than the one configured now.
+func importTimeSeries(ctx context.Context, repo database.DeviceRepo, path string, sourceBucket string, targetBucket string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	imported := 0
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var point database.TimeSeriesExportPoint
+		if err := json.Unmarshal(scanner.Bytes(), &point); err != nil {
+			return imported, fmt.Errorf("could not parse time-series point %d: %w", imported+1, err)
+		}
+		if sourceBucket != "" && targetBucket != "" && strings.HasPrefix(point.Bucket, sourceBucket) {
+			point.Bucket = targetBucket + strings.TrimPrefix(point.Bucket, sourceBucket)
+		}
+		if err := repo.ImportTimeSeriesPoint(ctx, point); err != nil {
+			return imported, fmt.Errorf("could not import time-series point into %s: %w", point.Bucket, err)
+		}
+		imported++
+	}
+	return imported, scanner.Err()
+}
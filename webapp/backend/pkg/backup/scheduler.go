@@ -0,0 +1,235 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBackupCheckInterval = 5 * time.Minute
+
+	archivePrefix     = "scrutiny-backup-"
+	archiveSuffix     = ".tar.gz"
+	archiveTimeFormat = "20060102T150405Z"
+)
+
+// ArchiveName returns the file name used for a backup taken at t.
+func ArchiveName(t time.Time) string {
+	return archivePrefix + t.UTC().Format(archiveTimeFormat) + archiveSuffix
+}
+
+// Scheduler writes backups to a local directory on a fixed interval and rotates old archives.
+// It is configured with the web.backup.schedule.* keys, which are re-read on every check.
+type Scheduler struct {
+	appConfig   config.Interface
+	logger      logrus.FieldLogger
+	deviceRepo  database.DeviceRepo
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stopCh      chan struct{}
+	repoFactory func() (database.DeviceRepo, error)
+
+	repoMu sync.Mutex
+	wg     sync.WaitGroup
+	mu     sync.Mutex // guards started/stopped flags
+
+	started bool
+	stopped bool
+}
+
+// NewScheduler creates a new backup scheduler
+func NewScheduler(appConfig config.Interface, logger logrus.FieldLogger, repoFactory func() (database.DeviceRepo, error)) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		appConfig:   appConfig,
+		logger:      logger,
+		stopCh:      make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+		repoFactory: repoFactory,
+	}
+}
+
+// Start begins the scheduler goroutine
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop gracefully shuts down the scheduler
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.logger.Debug("Stopping backup scheduler...")
+	s.cancel()
+	close(s.stopCh)
+	s.wg.Wait()
+
+	s.repoMu.Lock()
+	if s.deviceRepo != nil {
+		s.deviceRepo.Close()
+		s.deviceRepo = nil
+	}
+	s.repoMu.Unlock()
+
+	s.logger.Info("Backup scheduler stopped")
+}
+
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(DefaultBackupCheckInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Backup scheduler started")
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.checkAndRun(time.Now())
+		}
+	}
+}
+
+func (s *Scheduler) checkAndRun(now time.Time) {
+	if !s.appConfig.GetBool("web.backup.schedule.enabled") {
+		return
+	}
+
+	directory := s.appConfig.GetString("web.backup.schedule.directory")
+	archives, err := listArchives(directory)
+	if err != nil {
+		s.logger.Errorf("Backup scheduler: could not list %s: %v", directory, err)
+		return
+	}
+
+	var lastRun time.Time
+	if len(archives) > 0 {
+		lastRun = archives[len(archives)-1].createdAt
+	}
+	if !isBackupDue(now, lastRun, s.appConfig.GetInt("web.backup.schedule.interval_hours")) {
+		return
+	}
+
+	if err := s.runBackup(now, directory); err != nil {
+		s.logger.Errorf("Scheduled backup failed: %v", err)
+		return
+	}
+
+	if err := rotateArchives(directory, s.appConfig.GetInt("web.backup.schedule.keep"), s.logger); err != nil {
+		s.logger.Errorf("Backup scheduler: could not rotate old backups: %v", err)
+	}
+}
+
+func (s *Scheduler) runBackup(now time.Time, directory string) error {
+	repo, err := s.getRepo()
+	if err != nil {
+		return fmt.Errorf("failed to get device repo for backup: %w", err)
+	}
+	_, err = Create(s.ctx, s.appConfig, s.logger, repo, filepath.Join(directory, ArchiveName(now)), Options{})
+	return err
+}
+
+func (s *Scheduler) getRepo() (database.DeviceRepo, error) {
+	s.repoMu.Lock()
+	defer s.repoMu.Unlock()
+
+	if s.deviceRepo != nil {
+		return s.deviceRepo, nil
+	}
+
+	repo, err := s.repoFactory()
+	if err != nil {
+		return nil, err
+	}
+	s.deviceRepo = repo
+	return repo, nil
+}
+
+func isBackupDue(now, lastRun time.Time, intervalHours int) bool {
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+	if lastRun.IsZero() {
+		return true
+	}
+	return !now.Before(lastRun.Add(time.Duration(intervalHours) * time.Hour))
+}
+
+type archiveFile struct {
+	path      string
+	createdAt time.Time
+}
+
+// listArchives returns the scheduled backup archives in directory, oldest first. Files that do not
+// follow the ArchiveName pattern (e.g. manual backups) are ignored.
+func listArchives(directory string) ([]archiveFile, error) {
+	entries, err := os.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	archives := []archiveFile{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveSuffix) {
+			continue
+		}
+		createdAt, err := time.Parse(archiveTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveSuffix))
+		if err != nil {
+			continue
+		}
+		archives = append(archives, archiveFile{path: filepath.Join(directory, name), createdAt: createdAt})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].createdAt.Before(archives[j].createdAt)
+	})
+	return archives, nil
+}
+
+// rotateArchives deletes the oldest scheduled backups so that at most keep archives remain.
+// keep <= 0 disables rotation.
+func rotateArchives(directory string, keep int, logger logrus.FieldLogger) error {
+	if keep <= 0 {
+		return nil
+	}
+	archives, err := listArchives(directory)
+	if err != nil {
+		return err
+	}
+	for len(archives) > keep {
+		if err := os.Remove(archives[0].path); err != nil {
+			return err
+		}
+		logger.Infof("Removed old backup %s", archives[0].path)
+		archives = archives[1:]
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBackupDue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	assert.True(t, isBackupDue(now, time.Time{}, 24))
+	assert.False(t, isBackupDue(now, now.Add(-23*time.Hour), 24))
+	assert.True(t, isBackupDue(now, now.Add(-24*time.Hour), 24))
+	// invalid intervals fall back to daily
+	assert.False(t, isBackupDue(now, now.Add(-2*time.Hour), 0))
+}
+
+func TestRotateArchives(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 10, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ArchiveName(start.AddDate(0, 0, i))), []byte("x"), 0o600))
+	}
+	// manual backups are never rotated
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manual.tar.gz"), []byte("x"), 0o600))
+
+	require.NoError(t, rotateArchives(dir, 2, logrus.New()))
+
+	archives, err := listArchives(dir)
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Equal(t, start.AddDate(0, 0, 3), archives[0].createdAt)
+	assert.Equal(t, start.AddDate(0, 0, 4), archives[1].createdAt)
+	assert.FileExists(t, filepath.Join(dir, "manual.tar.gz"))
+}
@@ -40,11 +40,34 @@ func (c *configuration) Init() error {
 	c.SetDefault("web.src.frontend.path", "/opt/scrutiny/web")
 	c.SetDefault("web.database.location", "/opt/scrutiny/config/scrutiny.db")
 	c.SetDefault("web.database.journal_mode", "WAL")
+	c.SetDefault("web.database.type", "sqlite")
+	c.SetDefault("web.database.dsn", "")
 
 	c.SetDefault("log.level", "INFO")
 	c.SetDefault("log.file", "")
 
 	c.SetDefault("notify.urls", []string{})
+	c.SetDefault("notify.template_dir", "")
+	c.SetDefault("notify.retry.max_age_hours", 24)
+
+	c.SetDefault("web.timeseries.backend", "influxdb")
+
+	// Optional copy of every SMART submission to an external time-series sink: a second InfluxDB,
+	// an InfluxDB line protocol endpoint or a Prometheus remote-write receiver (e.g. VictoriaMetrics)
+	c.SetDefault("web.smart_forward.enabled", false)
+	c.SetDefault("web.smart_forward.type", "line_protocol")
+	c.SetDefault("web.smart_forward.url", "")
+	c.SetDefault("web.smart_forward.headers", map[string]string{})
+	c.SetDefault("web.smart_forward.timeout_seconds", 10)
+	c.SetDefault("web.smart_forward.influxdb.org", "")
+	c.SetDefault("web.smart_forward.influxdb.bucket", "")
+	c.SetDefault("web.smart_forward.influxdb.token", "")
+
+	// Scheduled backups (see `scrutiny backup`); disabled by default
+	c.SetDefault("web.backup.schedule.enabled", false)
+	c.SetDefault("web.backup.schedule.directory", "/opt/scrutiny/config/backups")
+	c.SetDefault("web.backup.schedule.interval_hours", 24)
+	c.SetDefault("web.backup.schedule.keep", 7)
 
 	c.SetDefault("web.influxdb.scheme", "http")
 	c.SetDefault("web.influxdb.host", "localhost")
@@ -75,6 +98,15 @@ func (c *configuration) Init() error {
 	// Optional bearer token for securing the Prometheus /api/metrics endpoint independently.
 	// When empty (default), the endpoint is open (or protected by web.auth if enabled).
 	c.SetDefault("web.metrics.token", "")
+	// Optional OTLP push of the same metrics, for OpenTelemetry collectors instead of Prometheus scrapers.
+	// Headers and resource attributes are maps, or "key1=value1,key2=value2" strings in environment variables.
+	c.SetDefault("web.metrics.otlp.enabled", false)
+	c.SetDefault("web.metrics.otlp.endpoint", "http://localhost:4318")
+	c.SetDefault("web.metrics.otlp.protocol", "http")
+	c.SetDefault("web.metrics.otlp.interval_seconds", 60)
+	c.SetDefault("web.metrics.otlp.timeout_seconds", 10)
+	c.SetDefault("web.metrics.otlp.headers", map[string]string{})
+	c.SetDefault("web.metrics.otlp.resource_attributes", map[string]string{})
 
 	// Uptime Kuma push monitor
 	c.SetDefault("web.uptime_kuma.insecure_skip_verify", false)
@@ -88,6 +120,9 @@ func (c *configuration) Init() error {
 	c.SetDefault("web.mqtt.topic_prefix", "homeassistant")
 	c.SetDefault("web.mqtt.qos", 1)
 	c.SetDefault("web.mqtt.retain", true)
+	c.SetDefault("web.mqtt.commands.enabled", false)
+	c.SetDefault("web.mqtt.commands.allowed_topics", []string{})
+	c.SetDefault("web.mqtt.collector_transport.enabled", false)
 
 	// Authentication settings
 	// Auth is disabled by default for backward compatibility with existing deployments.
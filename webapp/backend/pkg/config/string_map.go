@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StringMap reads a map setting, either from the config file or, as with the
+// OTEL_EXPORTER_OTLP_HEADERS environment variable, as a "key1=value1,key2=value2" string.
+func StringMap(appConfig Interface, key string) (map[string]string, error) {
+	result := map[string]string{}
+	switch value := appConfig.Get(key).(type) {
+	case nil:
+		return result, nil
+	case string:
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			name, val, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("invalid %s entry %q: must be key=value", key, pair)
+			}
+			result[strings.TrimSpace(name)] = strings.TrimSpace(val)
+		}
+		return result, nil
+	default:
+		if err := appConfig.UnmarshalKey(key, &result); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return result, nil
+	}
+}
@@ -66,3 +66,10 @@ const (
 	//shortcut
 	MetricsStatusThresholdBoth MetricsStatusThreshold = 3
 )
+
+// Request headers collectors send with every API call, recorded on the reporting Host
+const (
+	HeaderCollectorVersion = "X-Scrutiny-Collector-Version"
+	HeaderHostOS           = "X-Scrutiny-Host-OS"
+	HeaderHostArch         = "X-Scrutiny-Host-Arch"
+)
@@ -2,15 +2,30 @@ package database
 
 const (
 	// Viper config keys
-	cfgInfluxDBOrg             = "web.influxdb.org"
-	cfgInfluxDBBucket          = "web.influxdb.bucket"
-	cfgInfluxDBRetentionPolicy = "web.influxdb.retention_policy"
-	cfgDatabaseLocation        = "web.database.location"
+	cfgInfluxDBOrg                = "web.influxdb.org"
+	cfgInfluxDBBucket             = "web.influxdb.bucket"
+	cfgInfluxDBRetentionPolicy    = "web.influxdb.retention_policy"
+	cfgRetentionDaily             = "web.influxdb.retention.daily"
+	cfgRetentionWeekly            = "web.influxdb.retention.weekly"
+	cfgRetentionMonthly           = "web.influxdb.retention.monthly"
+	cfgDatabaseLocation           = "web.database.location"
+	cfgDatabaseType               = "web.database.type"
+	cfgDatabaseDSN                = "web.database.dsn"
+	cfgTimeSeriesBackend          = "web.timeseries.backend"
+	cfgSmartForwardEnabled        = "web.smart_forward.enabled"
+	cfgSmartForwardType           = "web.smart_forward.type"
+	cfgSmartForwardURL            = "web.smart_forward.url"
+	cfgSmartForwardHeaders        = "web.smart_forward.headers"
+	cfgSmartForwardTimeoutSeconds = "web.smart_forward.timeout_seconds"
+	cfgSmartForwardInfluxDBOrg    = "web.smart_forward.influxdb.org"
+	cfgSmartForwardInfluxDBBucket = "web.smart_forward.influxdb.bucket"
+	cfgSmartForwardInfluxDBToken  = "web.smart_forward.influxdb.token"
 
 	// GORM query conditions
 	queryDeviceID = "device_id = ?"
 	queryGUID     = "guid = ?"
 	queryUUID     = "uuid = ?"
+	queryHostID   = "host_id = ?"
 
 	// Error format strings
 	errDeviceNotFound          = "could not get device from DB: %v"
@@ -16,6 +16,10 @@ type HealthCheckStatus struct {
 	Status    string `json:"status"`          // "ok" or "error"
 	LatencyMs int64  `json:"latency_ms"`      // Response time in milliseconds
 	Error     string `json:"error,omitempty"` // Error message if status is "error"
+
+	// Depth and DeadLetters are only set by the notification_queue check
+	Depth       *int64 `json:"depth,omitempty"`
+	DeadLetters *int64 `json:"dead_letters,omitempty"`
 }
 
 // HealthCheckResult contains the results of all health checks
@@ -44,10 +48,21 @@ type DeviceRepo interface {
 	UpdateDeviceMuted(ctx context.Context, deviceID string, muted bool) error
 	UpdateDeviceLabel(ctx context.Context, deviceID string, label string) error
 	UpdateDeviceMaxTBW(ctx context.Context, deviceID string, maxTBW float64) error
+	UpdateDeviceInventory(ctx context.Context, deviceID string, inventory models.DeviceInventory) error
+	MarkDeviceWarrantyNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error
+	MarkDeviceWarrantyExpiredNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error
 	UpdateDeviceSmartDisplayMode(ctx context.Context, deviceID string, mode string) error
 	UpdateDeviceHasForcedFailure(ctx context.Context, deviceID string, hasForcedFailure bool) error
 	UpdateDeviceMissedPingTimeout(ctx context.Context, deviceID string, timeoutMinutes int) error
 	MergeDevices(ctx context.Context, sourceDeviceID string, destinationDeviceID string) error
+	// RecordDeviceReplacement records that one device succeeded another (see models.DeviceReplacement).
+	RecordDeviceReplacement(ctx context.Context, replacement models.DeviceReplacement) (models.DeviceReplacement, error)
+	DeleteDeviceReplacement(ctx context.Context, id uint) error
+	GetDeviceReplacements(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceReplacement, error)
+	// GetDeviceLineage returns the chain of devices that preceded and succeeded a device.
+	GetDeviceLineage(ctx context.Context, deviceID string) (models.DeviceLineage, error)
+	// GetDeviceLineages returns the lineages of the bays or pool members matching filter.
+	GetDeviceLineages(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceLineage, error)
 	DeleteDevice(ctx context.Context, deviceID string) error
 	// RecalculateDeviceStatusFromHistory re-evaluates device status from stored SMART data
 	// with current overrides applied. Used when overrides are added/modified/deleted.
@@ -62,6 +77,11 @@ type DeviceRepo interface {
 	// GetLatestSmartSubmission returns the most recent raw SMART submission (without daily aggregation)
 	// for use in delta evaluation before writing a new submission.
 	GetLatestSmartSubmission(ctx context.Context, wwn string) ([]measurements.Smart, error)
+	// StreamSmartHistory hands every stored SMART submission within durationKey to fn, oldest first,
+	// without aggregation. Used for exports, where the full history may not fit in memory.
+	StreamSmartHistory(ctx context.Context, wwn string, durationKey string, fn func(smart measurements.Smart) error) error
+	// StreamTemperatureHistory hands every stored temperature point within durationKey to fn, oldest first.
+	StreamTemperatureHistory(ctx context.Context, wwn string, durationKey string, fn func(temp measurements.SmartTemperature) error) error
 
 	SaveSmartTemperature(ctx context.Context, wwn string, deviceID string, collectorSmartData *collector.SmartInfo, retrieveSCTTemperatureHistory bool) error
 
@@ -148,10 +168,84 @@ type DeviceRepo interface {
 	SavePerformanceResults(ctx context.Context, wwn string, perfData *measurements.Performance) error
 	GetPerformanceHistory(ctx context.Context, wwn string, durationKey string) ([]measurements.Performance, error)
 	GetPerformanceBaseline(ctx context.Context, wwn string, count int) (*measurements.PerformanceBaseline, error)
+	// StreamPerformanceHistory hands every stored benchmark result within durationKey to fn, oldest first.
+	StreamPerformanceHistory(ctx context.Context, wwn string, durationKey string, fn func(perf measurements.Performance) error) error
+
+	// Backup & restore operations
+	// BackupDatabase writes a consistent snapshot of the SQLite metadata database to destinationPath.
+	BackupDatabase(ctx context.Context, destinationPath string) error
+	// GetAppliedMigrations returns the IDs of every applied schema migration.
+	GetAppliedMigrations(ctx context.Context) ([]string, error)
+	// ExportTimeSeries streams every point of the raw and down-sampled buckets, returning the exported bucket names.
+	ExportTimeSeries(ctx context.Context, fn func(point TimeSeriesExportPoint) error) ([]string, error)
+	// ImportTimeSeriesPoint writes a previously exported point back into its bucket.
+	ImportTimeSeriesPoint(ctx context.Context, point TimeSeriesExportPoint) error
+
+	// Host operations
+	// RecordHostCheckIn creates the host if needed and records a collector check-in for it.
+	RecordHostCheckIn(ctx context.Context, hostID string, collectorType string, info models.HostCollectorInfo) error
+	GetHosts(ctx context.Context) ([]models.Host, error)
+	GetHost(ctx context.Context, hostID string) (models.Host, error)
+	UpdateHost(ctx context.Context, hostID string, update models.HostUpdate) error
+	// UpdateHostMuted and UpdateHostArchived also apply the flag to every device, pool and filesystem of the host.
+	UpdateHostMuted(ctx context.Context, hostID string, muted bool) error
+	UpdateHostArchived(ctx context.Context, hostID string, archived bool) error
+	// RenameHost changes a host ID and rewrites the host_id of every record that references it.
+	RenameHost(ctx context.Context, hostID string, newHostID string) error
+	// GetHostSummaries aggregates device, pool and filesystem status per host.
+	GetHostSummaries(ctx context.Context) ([]models.HostSummary, error)
+
+	// Tag operations (devices, ZFS pools, mdadm arrays and Btrfs filesystems)
+	// SetResourceTags replaces the tags of a resource and returns the normalized tags that were stored.
+	SetResourceTags(ctx context.Context, resourceType string, resourceID string, tags []string) ([]string, error)
+	// GetResourceTags returns the tags of every resource of a type, keyed by resource ID.
+	GetResourceTags(ctx context.Context, resourceType string) (map[string][]string, error)
+	GetTags(ctx context.Context) ([]models.TagCount, error)
 
 	// Notify URL operations (UI-configurable notification endpoints)
 	GetNotifyUrls(ctx context.Context) ([]models.NotifyUrl, error)
 	SaveNotifyUrl(ctx context.Context, notifyUrl *models.NotifyUrl) error
 	DeleteNotifyUrl(ctx context.Context, id uint) error
 	UpdateNotifyUrlHeartbeat(ctx context.Context, id uint, enabled bool) error
+	UpdateNotifyUrlRules(ctx context.Context, id uint, rules []models.NotifyRoutingRule) error
+	UpdateNotifyUrlEscalation(ctx context.Context, id uint, escalation bool) error
+
+	// Incident operations (device failures from first notification until recovery)
+	// OpenDeviceIncident returns the unresolved incident of a device, opening one if needed.
+	OpenDeviceIncident(ctx context.Context, device models.Device, failureType string, subject string, message string) (models.Incident, error)
+	MarkIncidentNotified(ctx context.Context, id uint, at time.Time) error
+	MarkIncidentEscalated(ctx context.Context, id uint, at time.Time) error
+	GetIncidents(ctx context.Context, status string) ([]models.Incident, error)
+	GetIncident(ctx context.Context, id uint) (models.Incident, error)
+	AcknowledgeIncident(ctx context.Context, id uint, by string, note string, at time.Time) (models.Incident, error)
+	ResolveIncident(ctx context.Context, id uint, at time.Time) (models.Incident, error)
+
+	// Notification history and persistent quiet hours queue
+	SaveNotificationRecord(ctx context.Context, record *models.NotificationRecord) error
+	GetNotificationHistory(ctx context.Context, filter models.NotificationHistoryFilter) ([]models.NotificationRecord, error)
+	EnqueueQuietNotification(ctx context.Context, queued *models.QueuedNotification) error
+	GetQuietNotifications(ctx context.Context) ([]models.QueuedNotification, error)
+	DeleteQuietNotifications(ctx context.Context, ids []uint) error
+
+	// Maintenance windows (scoped notification suppression during planned work)
+	CreateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	GetMaintenanceWindows(ctx context.Context) ([]models.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, id uint) (models.MaintenanceWindow, error)
+	EndMaintenanceWindow(ctx context.Context, id uint, at time.Time) (models.MaintenanceWindow, error)
+	// GetActiveMaintenanceWindows returns the windows active at now, with pool and array members resolved.
+	GetActiveMaintenanceWindows(ctx context.Context, now time.Time) ([]models.MaintenanceWindow, error)
+
+	// Durable notification retry queue (failed deliveries resent with backoff, then dead-lettered)
+	EnqueueNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error
+	GetDueNotificationRetries(ctx context.Context, now time.Time, limit int) ([]models.NotificationRetry, error)
+	GetNotificationRetries(ctx context.Context, status string) ([]models.NotificationRetry, error)
+	UpdateNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error
+	DeleteNotificationRetry(ctx context.Context, id uint) error
+	// CountNotificationRetries returns the number of pending retries and dead letters.
+	CountNotificationRetries(ctx context.Context) (int64, int64, error)
+
+	// Device lifecycle events (devices added, disappeared, reappeared or swapped on a host)
+	// RecordDeviceLifecycleEvents detects and stores the events of a registration, given the devices known before it.
+	RecordDeviceLifecycleEvents(ctx context.Context, known []models.Device, registered []models.Device) ([]models.DeviceLifecycleEvent, error)
+	GetDeviceLifecycleEvents(ctx context.Context, filter models.DeviceLifecycleFilter) ([]models.DeviceLifecycleEvent, error)
 }
@@ -0,0 +1,15 @@
+package m20261018000000
+
+type TimeSeriesPoint struct {
+	ID          uint   `gorm:"primaryKey"`
+	Bucket      string `gorm:"not null;index:idx_time_series_lookup,priority:1"`
+	Measurement string `gorm:"not null;index:idx_time_series_lookup,priority:2"`
+	SeriesKey   string `gorm:"index:idx_time_series_lookup,priority:3"`
+	Timestamp   int64  `gorm:"not null;index:idx_time_series_lookup,priority:4"`
+	Tags        string
+	Fields      string
+}
+
+func (TimeSeriesPoint) TableName() string {
+	return "time_series_points"
+}
@@ -0,0 +1,22 @@
+package m20261018000003
+
+import "time"
+
+type DeviceReplacement struct {
+	ID                   uint `gorm:"primaryKey"`
+	CreatedAt            time.Time
+	ReplacedAt           time.Time
+	OldDeviceID          string `gorm:"uniqueIndex;not null"`
+	NewDeviceID          string `gorm:"uniqueIndex;not null"`
+	Reason               string
+	Notes                string
+	HostId               string
+	Slot                 string `gorm:"index"`
+	PoolMemberships      string `gorm:"type:text"`
+	ReplacedModelName    string
+	ReplacedSerialNumber string
+}
+
+func (DeviceReplacement) TableName() string {
+	return "device_replacements"
+}
@@ -0,0 +1,27 @@
+package m20261018000004
+
+import "time"
+
+type Host struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	HostID      string `gorm:"primaryKey"`
+	Label       string
+	Description string
+
+	CollectorVersion string
+	OSInfo           string
+	Architecture     string
+
+	MissedPingTimeoutMinutes int `gorm:"default:0"`
+	Muted                    bool
+	Archived                 bool
+}
+
+type HostCheckIn struct {
+	HostID           string `gorm:"primaryKey"`
+	CollectorType    string `gorm:"primaryKey"`
+	CollectorVersion string
+	LastSeenAt       time.Time
+}
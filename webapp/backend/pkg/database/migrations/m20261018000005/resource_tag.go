@@ -0,0 +1,10 @@
+package m20261018000005
+
+import "time"
+
+type ResourceTag struct {
+	CreatedAt    time.Time
+	ResourceType string `gorm:"primaryKey"`
+	ResourceID   string `gorm:"primaryKey"`
+	Tag          string `gorm:"primaryKey;index"`
+}
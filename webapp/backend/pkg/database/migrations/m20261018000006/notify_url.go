@@ -0,0 +1,18 @@
+package m20261018000006
+
+import "time"
+
+type NotifyUrl struct {
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	URL              string `gorm:"not null"`
+	Label            string
+	Source           string `gorm:"default:'ui'"`
+	HeartbeatEnabled bool   `gorm:"default:true"`
+	ID               uint   `gorm:"primaryKey"`
+	Rules            string `gorm:"type:text"`
+}
+
+func (NotifyUrl) TableName() string {
+	return "notify_urls"
+}
@@ -0,0 +1,28 @@
+package m20261018000007
+
+import "time"
+
+type Incident struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	DeviceID    string `gorm:"index"`
+	HostID      string
+	DeviceName  string
+	DeviceLabel string
+	FailureType string
+	Status      string `gorm:"index"`
+	Subject     string
+	Message     string
+
+	OpenedAt          time.Time
+	LastNotifiedAt    *time.Time
+	NotificationCount int
+	AcknowledgedAt    *time.Time
+	AcknowledgedBy    string
+	AckNote           string
+	EscalatedAt       *time.Time
+	ResolvedAt        *time.Time
+
+	ID uint `gorm:"primaryKey"`
+}
@@ -0,0 +1,29 @@
+package m20261018000008
+
+import "time"
+
+type NotificationRecord struct {
+	CreatedAt time.Time `gorm:"index"`
+
+	FailureType  string `gorm:"index"`
+	HostID       string
+	DeviceName   string
+	DeviceSerial string
+	Subject      string
+	Outcome      string `gorm:"index"`
+	SuppressedBy string
+	Error        string
+
+	// JSON-encoded []NotificationTargetResult
+	Targets string `gorm:"type:text"`
+
+	ID uint `gorm:"primaryKey"`
+}
+
+type QueuedNotification struct {
+	QueuedAt time.Time
+	Subject  string
+	Message  string
+
+	ID uint `gorm:"primaryKey"`
+}
@@ -0,0 +1,40 @@
+package m20261018000009
+
+import "time"
+
+type MaintenanceWindow struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Name       string
+	ScopeType  string `gorm:"index"`
+	ScopeID    string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	Recurrence string
+	EndedAt    *time.Time `gorm:"index"`
+	CreatedBy  string
+
+	ID uint `gorm:"primaryKey"`
+}
+
+// NotificationRecord adds the maintenance window that suppressed a notification.
+type NotificationRecord struct {
+	CreatedAt time.Time `gorm:"index"`
+
+	FailureType  string `gorm:"index"`
+	HostID       string
+	DeviceName   string
+	DeviceSerial string
+	Subject      string
+	Outcome      string `gorm:"index"`
+	SuppressedBy string
+	Error        string
+
+	MaintenanceWindowID *uint `gorm:"index"`
+
+	// JSON-encoded []NotificationTargetResult
+	Targets string `gorm:"type:text"`
+
+	ID uint `gorm:"primaryKey"`
+}
@@ -0,0 +1,24 @@
+package m20261018000010
+
+import "time"
+
+type NotificationRetry struct {
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+
+	URL         string
+	FailureType string `gorm:"index"`
+	Subject     string
+
+	Payload      string `gorm:"type:text"`
+	HTMLMessage  string `gorm:"type:text"`
+	WebhookEvent string `gorm:"type:text"`
+
+	Status        string `gorm:"index"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	DeadAt        *time.Time
+
+	ID uint `gorm:"primaryKey"`
+}
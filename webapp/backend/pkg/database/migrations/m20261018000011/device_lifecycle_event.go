@@ -0,0 +1,25 @@
+package m20261018000011
+
+import "time"
+
+type DeviceLifecycleEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	Type         string `gorm:"index"`
+	HostID       string `gorm:"index"`
+	DeviceID     string `gorm:"index"`
+	DeviceName   string
+	ModelName    string
+	SerialNumber string
+
+	PreviousDeviceID     string `gorm:"index"`
+	PreviousModelName    string
+	PreviousSerialNumber string
+	Slot                 string
+	PoolMemberships      string `gorm:"type:text"`
+}
+
+func (DeviceLifecycleEvent) TableName() string {
+	return "device_lifecycle_events"
+}
@@ -0,0 +1,21 @@
+package m20261018000014
+
+import "time"
+
+type FilesystemCapacity struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+
+	HostID         string `gorm:"primaryKey"`
+	MountPoint     string `gorm:"primaryKey"`
+	SourceDevice   string
+	FilesystemType string
+	TotalBytes     int64
+	UsedBytes      int64
+	AvailableBytes int64
+	UsedPercent    float64
+	InodesTotal    int64
+	InodesUsed     int64
+	InodesFree     int64
+}
@@ -0,0 +1,17 @@
+package m20261018000015
+
+import "time"
+
+type QueuedNotification struct {
+	QueuedAt    time.Time
+	FailureType string
+	HostID      string
+	Severity    string
+	Subject     string
+	Message     string
+
+	// JSON-encoded []string
+	DatabaseUrls string `gorm:"type:text"`
+
+	ID uint `gorm:"primaryKey"`
+}
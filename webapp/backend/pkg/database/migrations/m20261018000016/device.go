@@ -0,0 +1,52 @@
+package m20261018000016
+
+import (
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/common"
+)
+
+type Device struct {
+	CreatedAt                 time.Time
+	UpdatedAt                 time.Time
+	DeletedAt                 *time.Time
+	DeviceID                  string              `json:"device_id" gorm:"column:device_id;primary_key"`
+	FormFactor                string              `json:"form_factor"`
+	DeviceType                string              `json:"device_type"`
+	DeviceUUID                string              `json:"device_uuid"`
+	DeviceSerialID            string              `json:"device_serial_id"`
+	DeviceLabel               string              `json:"device_label"`
+	Manufacturer              string              `json:"manufacturer"`
+	ModelFamily               string              `json:"model_family"`
+	ModelName                 string              `json:"model_name"`
+	InterfaceType             string              `json:"interface_type"`
+	InterfaceSpeed            string              `json:"interface_speed"`
+	SerialNumber              string              `json:"serial_number"`
+	Firmware                  string              `json:"firmware"`
+	WWN                       string              `json:"wwn"`
+	DeviceProtocol            string              `json:"device_protocol"`
+	DeviceName                string              `json:"device_name"`
+	Label                     string              `json:"label"`
+	HostId                    string              `json:"host_id"`
+	CollectorVersion          string              `json:"collector_version"`
+	SmartDisplayMode          string              `json:"smart_display_mode" gorm:"default:'scrutiny'"`
+	SmartSupport              common.SmartSupport `json:"smart_support"`
+	Capacity                  int64               `json:"capacity"`
+	RotationSpeed             int                 `json:"rotational_speed"`
+	MissedPingTimeoutOverride int                 `json:"missed_ping_timeout_override" gorm:"default:0"`
+	DeviceStatus              pkg.DeviceStatus    `json:"device_status"`
+	Archived                  bool                `json:"archived"`
+	Muted                     bool                `json:"muted"`
+	HasForcedFailure          bool                `json:"has_forced_failure" gorm:"default:false"`
+	WarrantyNotifiedAt        *time.Time          `json:"-"`
+	WarrantyExpiredNotifiedAt *time.Time          `json:"-"`
+
+	// Inventory
+	PurchaseDate      *time.Time `json:"purchase_date"`
+	WarrantyExpiresAt *time.Time `json:"warranty_expires_at"`
+	PurchasePrice     *float64   `json:"purchase_price"`
+	Vendor            string     `json:"vendor"`
+	RMAContact        string     `json:"rma_contact"`
+	Location          string     `json:"location"`
+}
@@ -41,6 +41,35 @@ func (m *MockDeviceRepo) EXPECT() *MockDeviceRepoMockRecorder {
 	return m.recorder
 }
 
+// AcknowledgeIncident mocks base method.
+func (m *MockDeviceRepo) AcknowledgeIncident(ctx context.Context, id uint, by, note string, at time.Time) (models.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeIncident", ctx, id, by, note, at)
+	ret0, _ := ret[0].(models.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcknowledgeIncident indicates an expected call of AcknowledgeIncident.
+func (mr *MockDeviceRepoMockRecorder) AcknowledgeIncident(ctx, id, by, note, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeIncident", reflect.TypeOf((*MockDeviceRepo)(nil).AcknowledgeIncident), ctx, id, by, note, at)
+}
+
+// BackupDatabase mocks base method.
+func (m *MockDeviceRepo) BackupDatabase(ctx context.Context, destinationPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupDatabase", ctx, destinationPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BackupDatabase indicates an expected call of BackupDatabase.
+func (mr *MockDeviceRepoMockRecorder) BackupDatabase(ctx, destinationPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupDatabase", reflect.TypeOf((*MockDeviceRepo)(nil).BackupDatabase), ctx, destinationPath)
+}
+
 // Close mocks base method.
 func (m *MockDeviceRepo) Close() error {
 	m.ctrl.T.Helper()
@@ -55,6 +84,36 @@ func (mr *MockDeviceRepoMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDeviceRepo)(nil).Close))
 }
 
+// CountNotificationRetries mocks base method.
+func (m *MockDeviceRepo) CountNotificationRetries(ctx context.Context) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountNotificationRetries", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountNotificationRetries indicates an expected call of CountNotificationRetries.
+func (mr *MockDeviceRepoMockRecorder) CountNotificationRetries(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountNotificationRetries", reflect.TypeOf((*MockDeviceRepo)(nil).CountNotificationRetries), ctx)
+}
+
+// CreateMaintenanceWindow mocks base method.
+func (m *MockDeviceRepo) CreateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMaintenanceWindow", ctx, window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMaintenanceWindow indicates an expected call of CreateMaintenanceWindow.
+func (mr *MockDeviceRepoMockRecorder) CreateMaintenanceWindow(ctx, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMaintenanceWindow", reflect.TypeOf((*MockDeviceRepo)(nil).CreateMaintenanceWindow), ctx, window)
+}
+
 // DeleteAttributeOverride mocks base method.
 func (m *MockDeviceRepo) DeleteAttributeOverride(ctx context.Context, id uint) error {
 	m.ctrl.T.Helper()
@@ -97,6 +156,20 @@ func (mr *MockDeviceRepoMockRecorder) DeleteDevice(ctx, deviceID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDevice", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteDevice), ctx, deviceID)
 }
 
+// DeleteDeviceReplacement mocks base method.
+func (m *MockDeviceRepo) DeleteDeviceReplacement(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeviceReplacement", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeviceReplacement indicates an expected call of DeleteDeviceReplacement.
+func (mr *MockDeviceRepoMockRecorder) DeleteDeviceReplacement(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeviceReplacement", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteDeviceReplacement), ctx, id)
+}
+
 // DeleteMdadmArray mocks base method.
 func (m *MockDeviceRepo) DeleteMdadmArray(ctx context.Context, uuid string) error {
 	m.ctrl.T.Helper()
@@ -111,6 +184,20 @@ func (mr *MockDeviceRepoMockRecorder) DeleteMdadmArray(ctx, uuid interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMdadmArray", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteMdadmArray), ctx, uuid)
 }
 
+// DeleteNotificationRetry mocks base method.
+func (m *MockDeviceRepo) DeleteNotificationRetry(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotificationRetry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNotificationRetry indicates an expected call of DeleteNotificationRetry.
+func (mr *MockDeviceRepoMockRecorder) DeleteNotificationRetry(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotificationRetry", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteNotificationRetry), ctx, id)
+}
+
 // DeleteNotifyUrl mocks base method.
 func (m *MockDeviceRepo) DeleteNotifyUrl(ctx context.Context, id uint) error {
 	m.ctrl.T.Helper()
@@ -125,6 +212,20 @@ func (mr *MockDeviceRepoMockRecorder) DeleteNotifyUrl(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotifyUrl", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteNotifyUrl), ctx, id)
 }
 
+// DeleteQuietNotifications mocks base method.
+func (m *MockDeviceRepo) DeleteQuietNotifications(ctx context.Context, ids []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQuietNotifications", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteQuietNotifications indicates an expected call of DeleteQuietNotifications.
+func (mr *MockDeviceRepoMockRecorder) DeleteQuietNotifications(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQuietNotifications", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteQuietNotifications), ctx, ids)
+}
+
 // DeleteZFSPool mocks base method.
 func (m *MockDeviceRepo) DeleteZFSPool(ctx context.Context, guid string) error {
 	m.ctrl.T.Helper()
@@ -139,6 +240,79 @@ func (mr *MockDeviceRepoMockRecorder) DeleteZFSPool(ctx, guid interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteZFSPool", reflect.TypeOf((*MockDeviceRepo)(nil).DeleteZFSPool), ctx, guid)
 }
 
+// EndMaintenanceWindow mocks base method.
+func (m *MockDeviceRepo) EndMaintenanceWindow(ctx context.Context, id uint, at time.Time) (models.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndMaintenanceWindow", ctx, id, at)
+	ret0, _ := ret[0].(models.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndMaintenanceWindow indicates an expected call of EndMaintenanceWindow.
+func (mr *MockDeviceRepoMockRecorder) EndMaintenanceWindow(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndMaintenanceWindow", reflect.TypeOf((*MockDeviceRepo)(nil).EndMaintenanceWindow), ctx, id, at)
+}
+
+// EnqueueNotificationRetry mocks base method.
+func (m *MockDeviceRepo) EnqueueNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueNotificationRetry", ctx, retry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueNotificationRetry indicates an expected call of EnqueueNotificationRetry.
+func (mr *MockDeviceRepoMockRecorder) EnqueueNotificationRetry(ctx, retry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueNotificationRetry", reflect.TypeOf((*MockDeviceRepo)(nil).EnqueueNotificationRetry), ctx, retry)
+}
+
+// EnqueueQuietNotification mocks base method.
+func (m *MockDeviceRepo) EnqueueQuietNotification(ctx context.Context, queued *models.QueuedNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueQuietNotification", ctx, queued)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueQuietNotification indicates an expected call of EnqueueQuietNotification.
+func (mr *MockDeviceRepoMockRecorder) EnqueueQuietNotification(ctx, queued interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueQuietNotification", reflect.TypeOf((*MockDeviceRepo)(nil).EnqueueQuietNotification), ctx, queued)
+}
+
+// ExportTimeSeries mocks base method.
+func (m *MockDeviceRepo) ExportTimeSeries(ctx context.Context, fn func(database.TimeSeriesExportPoint) error) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTimeSeries", ctx, fn)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportTimeSeries indicates an expected call of ExportTimeSeries.
+func (mr *MockDeviceRepoMockRecorder) ExportTimeSeries(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTimeSeries", reflect.TypeOf((*MockDeviceRepo)(nil).ExportTimeSeries), ctx, fn)
+}
+
+// GetActiveMaintenanceWindows mocks base method.
+func (m *MockDeviceRepo) GetActiveMaintenanceWindows(ctx context.Context, now time.Time) ([]models.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveMaintenanceWindows", ctx, now)
+	ret0, _ := ret[0].([]models.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveMaintenanceWindows indicates an expected call of GetActiveMaintenanceWindows.
+func (mr *MockDeviceRepoMockRecorder) GetActiveMaintenanceWindows(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveMaintenanceWindows", reflect.TypeOf((*MockDeviceRepo)(nil).GetActiveMaintenanceWindows), ctx, now)
+}
+
 // GetAllOverridesForDisplay mocks base method.
 func (m *MockDeviceRepo) GetAllOverridesForDisplay(ctx context.Context) ([]models.AttributeOverride, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +328,21 @@ func (mr *MockDeviceRepoMockRecorder) GetAllOverridesForDisplay(ctx interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOverridesForDisplay", reflect.TypeOf((*MockDeviceRepo)(nil).GetAllOverridesForDisplay), ctx)
 }
 
+// GetAppliedMigrations mocks base method.
+func (m *MockDeviceRepo) GetAppliedMigrations(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppliedMigrations", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppliedMigrations indicates an expected call of GetAppliedMigrations.
+func (mr *MockDeviceRepoMockRecorder) GetAppliedMigrations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppliedMigrations", reflect.TypeOf((*MockDeviceRepo)(nil).GetAppliedMigrations), ctx)
+}
+
 // GetAttributeOverrideByID mocks base method.
 func (m *MockDeviceRepo) GetAttributeOverrideByID(ctx context.Context, id uint) (*models.AttributeOverride, error) {
 	m.ctrl.T.Helper()
@@ -304,6 +493,66 @@ func (mr *MockDeviceRepoMockRecorder) GetDeviceDetails(ctx, deviceID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceDetails", reflect.TypeOf((*MockDeviceRepo)(nil).GetDeviceDetails), ctx, deviceID)
 }
 
+// GetDeviceLifecycleEvents mocks base method.
+func (m *MockDeviceRepo) GetDeviceLifecycleEvents(ctx context.Context, filter models.DeviceLifecycleFilter) ([]models.DeviceLifecycleEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceLifecycleEvents", ctx, filter)
+	ret0, _ := ret[0].([]models.DeviceLifecycleEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceLifecycleEvents indicates an expected call of GetDeviceLifecycleEvents.
+func (mr *MockDeviceRepoMockRecorder) GetDeviceLifecycleEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceLifecycleEvents", reflect.TypeOf((*MockDeviceRepo)(nil).GetDeviceLifecycleEvents), ctx, filter)
+}
+
+// GetDeviceLineage mocks base method.
+func (m *MockDeviceRepo) GetDeviceLineage(ctx context.Context, deviceID string) (models.DeviceLineage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceLineage", ctx, deviceID)
+	ret0, _ := ret[0].(models.DeviceLineage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceLineage indicates an expected call of GetDeviceLineage.
+func (mr *MockDeviceRepoMockRecorder) GetDeviceLineage(ctx, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceLineage", reflect.TypeOf((*MockDeviceRepo)(nil).GetDeviceLineage), ctx, deviceID)
+}
+
+// GetDeviceLineages mocks base method.
+func (m *MockDeviceRepo) GetDeviceLineages(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceLineage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceLineages", ctx, filter)
+	ret0, _ := ret[0].([]models.DeviceLineage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceLineages indicates an expected call of GetDeviceLineages.
+func (mr *MockDeviceRepoMockRecorder) GetDeviceLineages(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceLineages", reflect.TypeOf((*MockDeviceRepo)(nil).GetDeviceLineages), ctx, filter)
+}
+
+// GetDeviceReplacements mocks base method.
+func (m *MockDeviceRepo) GetDeviceReplacements(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceReplacement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceReplacements", ctx, filter)
+	ret0, _ := ret[0].([]models.DeviceReplacement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceReplacements indicates an expected call of GetDeviceReplacements.
+func (mr *MockDeviceRepoMockRecorder) GetDeviceReplacements(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceReplacements", reflect.TypeOf((*MockDeviceRepo)(nil).GetDeviceReplacements), ctx, filter)
+}
+
 // GetDeviceSelfTests mocks base method.
 func (m *MockDeviceRepo) GetDeviceSelfTests(ctx context.Context, deviceID string) ([]models.DeviceSelfTest, error) {
 	m.ctrl.T.Helper()
@@ -349,6 +598,21 @@ func (mr *MockDeviceRepoMockRecorder) GetDevicesLastSeenTimes(ctx interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDevicesLastSeenTimes", reflect.TypeOf((*MockDeviceRepo)(nil).GetDevicesLastSeenTimes), ctx)
 }
 
+// GetDueNotificationRetries mocks base method.
+func (m *MockDeviceRepo) GetDueNotificationRetries(ctx context.Context, now time.Time, limit int) ([]models.NotificationRetry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueNotificationRetries", ctx, now, limit)
+	ret0, _ := ret[0].([]models.NotificationRetry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueNotificationRetries indicates an expected call of GetDueNotificationRetries.
+func (mr *MockDeviceRepoMockRecorder) GetDueNotificationRetries(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueNotificationRetries", reflect.TypeOf((*MockDeviceRepo)(nil).GetDueNotificationRetries), ctx, now, limit)
+}
+
 // GetFilesystemSummary mocks base method.
 func (m *MockDeviceRepo) GetFilesystemSummary(ctx context.Context) (map[string][]models.FilesystemCapacity, map[string]*models.FilesystemHostStatus, error) {
 	m.ctrl.T.Helper()
@@ -365,6 +629,81 @@ func (mr *MockDeviceRepoMockRecorder) GetFilesystemSummary(ctx interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilesystemSummary", reflect.TypeOf((*MockDeviceRepo)(nil).GetFilesystemSummary), ctx)
 }
 
+// GetHost mocks base method.
+func (m *MockDeviceRepo) GetHost(ctx context.Context, hostID string) (models.Host, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHost", ctx, hostID)
+	ret0, _ := ret[0].(models.Host)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHost indicates an expected call of GetHost.
+func (mr *MockDeviceRepoMockRecorder) GetHost(ctx, hostID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHost", reflect.TypeOf((*MockDeviceRepo)(nil).GetHost), ctx, hostID)
+}
+
+// GetHostSummaries mocks base method.
+func (m *MockDeviceRepo) GetHostSummaries(ctx context.Context) ([]models.HostSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostSummaries", ctx)
+	ret0, _ := ret[0].([]models.HostSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostSummaries indicates an expected call of GetHostSummaries.
+func (mr *MockDeviceRepoMockRecorder) GetHostSummaries(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostSummaries", reflect.TypeOf((*MockDeviceRepo)(nil).GetHostSummaries), ctx)
+}
+
+// GetHosts mocks base method.
+func (m *MockDeviceRepo) GetHosts(ctx context.Context) ([]models.Host, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHosts", ctx)
+	ret0, _ := ret[0].([]models.Host)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHosts indicates an expected call of GetHosts.
+func (mr *MockDeviceRepoMockRecorder) GetHosts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHosts", reflect.TypeOf((*MockDeviceRepo)(nil).GetHosts), ctx)
+}
+
+// GetIncident mocks base method.
+func (m *MockDeviceRepo) GetIncident(ctx context.Context, id uint) (models.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncident", ctx, id)
+	ret0, _ := ret[0].(models.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncident indicates an expected call of GetIncident.
+func (mr *MockDeviceRepoMockRecorder) GetIncident(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncident", reflect.TypeOf((*MockDeviceRepo)(nil).GetIncident), ctx, id)
+}
+
+// GetIncidents mocks base method.
+func (m *MockDeviceRepo) GetIncidents(ctx context.Context, status string) ([]models.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncidents", ctx, status)
+	ret0, _ := ret[0].([]models.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncidents indicates an expected call of GetIncidents.
+func (mr *MockDeviceRepoMockRecorder) GetIncidents(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidents", reflect.TypeOf((*MockDeviceRepo)(nil).GetIncidents), ctx, status)
+}
+
 // GetLatestMdadmMetrics mocks base method.
 func (m *MockDeviceRepo) GetLatestMdadmMetrics(ctx context.Context, uuid string) (*measurements.MDADMMetrics, error) {
 	m.ctrl.T.Helper()
@@ -395,6 +734,36 @@ func (mr *MockDeviceRepoMockRecorder) GetLatestSmartSubmission(ctx, wwn interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSmartSubmission", reflect.TypeOf((*MockDeviceRepo)(nil).GetLatestSmartSubmission), ctx, wwn)
 }
 
+// GetMaintenanceWindow mocks base method.
+func (m *MockDeviceRepo) GetMaintenanceWindow(ctx context.Context, id uint) (models.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaintenanceWindow", ctx, id)
+	ret0, _ := ret[0].(models.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenanceWindow indicates an expected call of GetMaintenanceWindow.
+func (mr *MockDeviceRepoMockRecorder) GetMaintenanceWindow(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceWindow", reflect.TypeOf((*MockDeviceRepo)(nil).GetMaintenanceWindow), ctx, id)
+}
+
+// GetMaintenanceWindows mocks base method.
+func (m *MockDeviceRepo) GetMaintenanceWindows(ctx context.Context) ([]models.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaintenanceWindows", ctx)
+	ret0, _ := ret[0].([]models.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenanceWindows indicates an expected call of GetMaintenanceWindows.
+func (mr *MockDeviceRepoMockRecorder) GetMaintenanceWindows(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceWindows", reflect.TypeOf((*MockDeviceRepo)(nil).GetMaintenanceWindows), ctx)
+}
+
 // GetMdadmArrayDetails mocks base method.
 func (m *MockDeviceRepo) GetMdadmArrayDetails(ctx context.Context, uuid string) (models.MDADMArray, error) {
 	m.ctrl.T.Helper()
@@ -469,6 +838,36 @@ func (mr *MockDeviceRepoMockRecorder) GetMergedOverrides(ctx interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMergedOverrides", reflect.TypeOf((*MockDeviceRepo)(nil).GetMergedOverrides), ctx)
 }
 
+// GetNotificationHistory mocks base method.
+func (m *MockDeviceRepo) GetNotificationHistory(ctx context.Context, filter models.NotificationHistoryFilter) ([]models.NotificationRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationHistory", ctx, filter)
+	ret0, _ := ret[0].([]models.NotificationRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationHistory indicates an expected call of GetNotificationHistory.
+func (mr *MockDeviceRepoMockRecorder) GetNotificationHistory(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationHistory", reflect.TypeOf((*MockDeviceRepo)(nil).GetNotificationHistory), ctx, filter)
+}
+
+// GetNotificationRetries mocks base method.
+func (m *MockDeviceRepo) GetNotificationRetries(ctx context.Context, status string) ([]models.NotificationRetry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationRetries", ctx, status)
+	ret0, _ := ret[0].([]models.NotificationRetry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationRetries indicates an expected call of GetNotificationRetries.
+func (mr *MockDeviceRepoMockRecorder) GetNotificationRetries(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationRetries", reflect.TypeOf((*MockDeviceRepo)(nil).GetNotificationRetries), ctx, status)
+}
+
 // GetNotifyUrls mocks base method.
 func (m *MockDeviceRepo) GetNotifyUrls(ctx context.Context) ([]models.NotifyUrl, error) {
 	m.ctrl.T.Helper()
@@ -529,6 +928,36 @@ func (mr *MockDeviceRepoMockRecorder) GetPreviousSmartSubmission(ctx, wwn interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreviousSmartSubmission", reflect.TypeOf((*MockDeviceRepo)(nil).GetPreviousSmartSubmission), ctx, wwn)
 }
 
+// GetQuietNotifications mocks base method.
+func (m *MockDeviceRepo) GetQuietNotifications(ctx context.Context) ([]models.QueuedNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuietNotifications", ctx)
+	ret0, _ := ret[0].([]models.QueuedNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuietNotifications indicates an expected call of GetQuietNotifications.
+func (mr *MockDeviceRepoMockRecorder) GetQuietNotifications(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuietNotifications", reflect.TypeOf((*MockDeviceRepo)(nil).GetQuietNotifications), ctx)
+}
+
+// GetResourceTags mocks base method.
+func (m *MockDeviceRepo) GetResourceTags(ctx context.Context, resourceType string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResourceTags", ctx, resourceType)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResourceTags indicates an expected call of GetResourceTags.
+func (mr *MockDeviceRepoMockRecorder) GetResourceTags(ctx, resourceType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResourceTags", reflect.TypeOf((*MockDeviceRepo)(nil).GetResourceTags), ctx, resourceType)
+}
+
 // GetSettingValue mocks base method.
 func (m *MockDeviceRepo) GetSettingValue(ctx context.Context, key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -589,6 +1018,21 @@ func (mr *MockDeviceRepoMockRecorder) GetSummary(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockDeviceRepo)(nil).GetSummary), ctx)
 }
 
+// GetTags mocks base method.
+func (m *MockDeviceRepo) GetTags(ctx context.Context) ([]models.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx)
+	ret0, _ := ret[0].([]models.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockDeviceRepoMockRecorder) GetTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockDeviceRepo)(nil).GetTags), ctx)
+}
+
 // GetWorkloadInsights mocks base method.
 func (m *MockDeviceRepo) GetWorkloadInsights(ctx context.Context, durationKey string) (map[string]*models.WorkloadInsight, error) {
 	m.ctrl.T.Helper()
@@ -679,6 +1123,20 @@ func (mr *MockDeviceRepoMockRecorder) HealthCheck(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockDeviceRepo)(nil).HealthCheck), ctx)
 }
 
+// ImportTimeSeriesPoint mocks base method.
+func (m *MockDeviceRepo) ImportTimeSeriesPoint(ctx context.Context, point database.TimeSeriesExportPoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTimeSeriesPoint", ctx, point)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportTimeSeriesPoint indicates an expected call of ImportTimeSeriesPoint.
+func (mr *MockDeviceRepoMockRecorder) ImportTimeSeriesPoint(ctx, point interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTimeSeriesPoint", reflect.TypeOf((*MockDeviceRepo)(nil).ImportTimeSeriesPoint), ctx, point)
+}
+
 // LoadSettings mocks base method.
 func (m *MockDeviceRepo) LoadSettings(ctx context.Context) (*models.Settings, error) {
 	m.ctrl.T.Helper()
@@ -694,6 +1152,62 @@ func (mr *MockDeviceRepoMockRecorder) LoadSettings(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSettings", reflect.TypeOf((*MockDeviceRepo)(nil).LoadSettings), ctx)
 }
 
+// MarkDeviceWarrantyExpiredNotified mocks base method.
+func (m *MockDeviceRepo) MarkDeviceWarrantyExpiredNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeviceWarrantyExpiredNotified", ctx, deviceID, notifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeviceWarrantyExpiredNotified indicates an expected call of MarkDeviceWarrantyExpiredNotified.
+func (mr *MockDeviceRepoMockRecorder) MarkDeviceWarrantyExpiredNotified(ctx, deviceID, notifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeviceWarrantyExpiredNotified", reflect.TypeOf((*MockDeviceRepo)(nil).MarkDeviceWarrantyExpiredNotified), ctx, deviceID, notifiedAt)
+}
+
+// MarkDeviceWarrantyNotified mocks base method.
+func (m *MockDeviceRepo) MarkDeviceWarrantyNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeviceWarrantyNotified", ctx, deviceID, notifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeviceWarrantyNotified indicates an expected call of MarkDeviceWarrantyNotified.
+func (mr *MockDeviceRepoMockRecorder) MarkDeviceWarrantyNotified(ctx, deviceID, notifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeviceWarrantyNotified", reflect.TypeOf((*MockDeviceRepo)(nil).MarkDeviceWarrantyNotified), ctx, deviceID, notifiedAt)
+}
+
+// MarkIncidentEscalated mocks base method.
+func (m *MockDeviceRepo) MarkIncidentEscalated(ctx context.Context, id uint, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkIncidentEscalated", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkIncidentEscalated indicates an expected call of MarkIncidentEscalated.
+func (mr *MockDeviceRepoMockRecorder) MarkIncidentEscalated(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkIncidentEscalated", reflect.TypeOf((*MockDeviceRepo)(nil).MarkIncidentEscalated), ctx, id, at)
+}
+
+// MarkIncidentNotified mocks base method.
+func (m *MockDeviceRepo) MarkIncidentNotified(ctx context.Context, id uint, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkIncidentNotified", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkIncidentNotified indicates an expected call of MarkIncidentNotified.
+func (mr *MockDeviceRepoMockRecorder) MarkIncidentNotified(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkIncidentNotified", reflect.TypeOf((*MockDeviceRepo)(nil).MarkIncidentNotified), ctx, id, at)
+}
+
 // MergeDevices mocks base method.
 func (m *MockDeviceRepo) MergeDevices(ctx context.Context, sourceDeviceID, destinationDeviceID string) error {
 	m.ctrl.T.Helper()
@@ -708,6 +1222,21 @@ func (mr *MockDeviceRepoMockRecorder) MergeDevices(ctx, sourceDeviceID, destinat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeDevices", reflect.TypeOf((*MockDeviceRepo)(nil).MergeDevices), ctx, sourceDeviceID, destinationDeviceID)
 }
 
+// OpenDeviceIncident mocks base method.
+func (m *MockDeviceRepo) OpenDeviceIncident(ctx context.Context, device models.Device, failureType, subject, message string) (models.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDeviceIncident", ctx, device, failureType, subject, message)
+	ret0, _ := ret[0].(models.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDeviceIncident indicates an expected call of OpenDeviceIncident.
+func (mr *MockDeviceRepoMockRecorder) OpenDeviceIncident(ctx, device, failureType, subject, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDeviceIncident", reflect.TypeOf((*MockDeviceRepo)(nil).OpenDeviceIncident), ctx, device, failureType, subject, message)
+}
+
 // RecalculateDeviceStatusFromHistory mocks base method.
 func (m *MockDeviceRepo) RecalculateDeviceStatusFromHistory(ctx context.Context, deviceID string) error {
 	m.ctrl.T.Helper()
@@ -722,6 +1251,50 @@ func (mr *MockDeviceRepoMockRecorder) RecalculateDeviceStatusFromHistory(ctx, de
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecalculateDeviceStatusFromHistory", reflect.TypeOf((*MockDeviceRepo)(nil).RecalculateDeviceStatusFromHistory), ctx, deviceID)
 }
 
+// RecordDeviceLifecycleEvents mocks base method.
+func (m *MockDeviceRepo) RecordDeviceLifecycleEvents(ctx context.Context, known, registered []models.Device) ([]models.DeviceLifecycleEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeviceLifecycleEvents", ctx, known, registered)
+	ret0, _ := ret[0].([]models.DeviceLifecycleEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordDeviceLifecycleEvents indicates an expected call of RecordDeviceLifecycleEvents.
+func (mr *MockDeviceRepoMockRecorder) RecordDeviceLifecycleEvents(ctx, known, registered interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeviceLifecycleEvents", reflect.TypeOf((*MockDeviceRepo)(nil).RecordDeviceLifecycleEvents), ctx, known, registered)
+}
+
+// RecordDeviceReplacement mocks base method.
+func (m *MockDeviceRepo) RecordDeviceReplacement(ctx context.Context, replacement models.DeviceReplacement) (models.DeviceReplacement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeviceReplacement", ctx, replacement)
+	ret0, _ := ret[0].(models.DeviceReplacement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordDeviceReplacement indicates an expected call of RecordDeviceReplacement.
+func (mr *MockDeviceRepoMockRecorder) RecordDeviceReplacement(ctx, replacement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeviceReplacement", reflect.TypeOf((*MockDeviceRepo)(nil).RecordDeviceReplacement), ctx, replacement)
+}
+
+// RecordHostCheckIn mocks base method.
+func (m *MockDeviceRepo) RecordHostCheckIn(ctx context.Context, hostID, collectorType string, info models.HostCollectorInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordHostCheckIn", ctx, hostID, collectorType, info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordHostCheckIn indicates an expected call of RecordHostCheckIn.
+func (mr *MockDeviceRepoMockRecorder) RecordHostCheckIn(ctx, hostID, collectorType, info interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordHostCheckIn", reflect.TypeOf((*MockDeviceRepo)(nil).RecordHostCheckIn), ctx, hostID, collectorType, info)
+}
+
 // RegisterBtrfsFilesystem mocks base method.
 func (m *MockDeviceRepo) RegisterBtrfsFilesystem(ctx context.Context, filesystem *models.BtrfsFilesystem) error {
 	m.ctrl.T.Helper()
@@ -778,6 +1351,20 @@ func (mr *MockDeviceRepoMockRecorder) RegisterZFSPool(ctx, pool interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterZFSPool", reflect.TypeOf((*MockDeviceRepo)(nil).RegisterZFSPool), ctx, pool)
 }
 
+// RenameHost mocks base method.
+func (m *MockDeviceRepo) RenameHost(ctx context.Context, hostID, newHostID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameHost", ctx, hostID, newHostID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameHost indicates an expected call of RenameHost.
+func (mr *MockDeviceRepoMockRecorder) RenameHost(ctx, hostID, newHostID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameHost", reflect.TypeOf((*MockDeviceRepo)(nil).RenameHost), ctx, hostID, newHostID)
+}
+
 // ResetDeviceStatus mocks base method.
 func (m *MockDeviceRepo) ResetDeviceStatus(ctx context.Context, deviceID string) (models.Device, error) {
 	m.ctrl.T.Helper()
@@ -793,6 +1380,21 @@ func (mr *MockDeviceRepoMockRecorder) ResetDeviceStatus(ctx, deviceID interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDeviceStatus", reflect.TypeOf((*MockDeviceRepo)(nil).ResetDeviceStatus), ctx, deviceID)
 }
 
+// ResolveIncident mocks base method.
+func (m *MockDeviceRepo) ResolveIncident(ctx context.Context, id uint, at time.Time) (models.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveIncident", ctx, id, at)
+	ret0, _ := ret[0].(models.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveIncident indicates an expected call of ResolveIncident.
+func (mr *MockDeviceRepoMockRecorder) ResolveIncident(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveIncident", reflect.TypeOf((*MockDeviceRepo)(nil).ResolveIncident), ctx, id, at)
+}
+
 // SaveAttributeOverride mocks base method.
 func (m *MockDeviceRepo) SaveAttributeOverride(ctx context.Context, override *models.AttributeOverride) error {
 	m.ctrl.T.Helper()
@@ -849,6 +1451,20 @@ func (mr *MockDeviceRepoMockRecorder) SaveMdadmMetrics(ctx, uuid, metrics interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMdadmMetrics", reflect.TypeOf((*MockDeviceRepo)(nil).SaveMdadmMetrics), ctx, uuid, metrics)
 }
 
+// SaveNotificationRecord mocks base method.
+func (m *MockDeviceRepo) SaveNotificationRecord(ctx context.Context, record *models.NotificationRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotificationRecord", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotificationRecord indicates an expected call of SaveNotificationRecord.
+func (mr *MockDeviceRepoMockRecorder) SaveNotificationRecord(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotificationRecord", reflect.TypeOf((*MockDeviceRepo)(nil).SaveNotificationRecord), ctx, record)
+}
+
 // SaveNotifyUrl mocks base method.
 func (m *MockDeviceRepo) SaveNotifyUrl(ctx context.Context, notifyUrl *models.NotifyUrl) error {
 	m.ctrl.T.Helper()
@@ -934,6 +1550,21 @@ func (mr *MockDeviceRepoMockRecorder) SaveZFSPoolMetrics(ctx, pool interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveZFSPoolMetrics", reflect.TypeOf((*MockDeviceRepo)(nil).SaveZFSPoolMetrics), ctx, pool)
 }
 
+// SetResourceTags mocks base method.
+func (m *MockDeviceRepo) SetResourceTags(ctx context.Context, resourceType, resourceID string, tags []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetResourceTags", ctx, resourceType, resourceID, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetResourceTags indicates an expected call of SetResourceTags.
+func (mr *MockDeviceRepoMockRecorder) SetResourceTags(ctx, resourceType, resourceID, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetResourceTags", reflect.TypeOf((*MockDeviceRepo)(nil).SetResourceTags), ctx, resourceType, resourceID, tags)
+}
+
 // SetSettingValue mocks base method.
 func (m *MockDeviceRepo) SetSettingValue(ctx context.Context, key, value string) error {
 	m.ctrl.T.Helper()
@@ -948,6 +1579,48 @@ func (mr *MockDeviceRepoMockRecorder) SetSettingValue(ctx, key, value interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSettingValue", reflect.TypeOf((*MockDeviceRepo)(nil).SetSettingValue), ctx, key, value)
 }
 
+// StreamPerformanceHistory mocks base method.
+func (m *MockDeviceRepo) StreamPerformanceHistory(ctx context.Context, wwn, durationKey string, fn func(measurements.Performance) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPerformanceHistory", ctx, wwn, durationKey, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPerformanceHistory indicates an expected call of StreamPerformanceHistory.
+func (mr *MockDeviceRepoMockRecorder) StreamPerformanceHistory(ctx, wwn, durationKey, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPerformanceHistory", reflect.TypeOf((*MockDeviceRepo)(nil).StreamPerformanceHistory), ctx, wwn, durationKey, fn)
+}
+
+// StreamSmartHistory mocks base method.
+func (m *MockDeviceRepo) StreamSmartHistory(ctx context.Context, wwn, durationKey string, fn func(measurements.Smart) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSmartHistory", ctx, wwn, durationKey, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamSmartHistory indicates an expected call of StreamSmartHistory.
+func (mr *MockDeviceRepoMockRecorder) StreamSmartHistory(ctx, wwn, durationKey, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSmartHistory", reflect.TypeOf((*MockDeviceRepo)(nil).StreamSmartHistory), ctx, wwn, durationKey, fn)
+}
+
+// StreamTemperatureHistory mocks base method.
+func (m *MockDeviceRepo) StreamTemperatureHistory(ctx context.Context, wwn, durationKey string, fn func(measurements.SmartTemperature) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamTemperatureHistory", ctx, wwn, durationKey, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamTemperatureHistory indicates an expected call of StreamTemperatureHistory.
+func (mr *MockDeviceRepoMockRecorder) StreamTemperatureHistory(ctx, wwn, durationKey, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTemperatureHistory", reflect.TypeOf((*MockDeviceRepo)(nil).StreamTemperatureHistory), ctx, wwn, durationKey, fn)
+}
+
 // UpdateBtrfsFilesystemArchived mocks base method.
 func (m *MockDeviceRepo) UpdateBtrfsFilesystemArchived(ctx context.Context, uuid string, archived bool) error {
 	m.ctrl.T.Helper()
@@ -1033,6 +1706,20 @@ func (mr *MockDeviceRepoMockRecorder) UpdateDeviceHasForcedFailure(ctx, deviceID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeviceHasForcedFailure", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateDeviceHasForcedFailure), ctx, deviceID, hasForcedFailure)
 }
 
+// UpdateDeviceInventory mocks base method.
+func (m *MockDeviceRepo) UpdateDeviceInventory(ctx context.Context, deviceID string, inventory models.DeviceInventory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeviceInventory", ctx, deviceID, inventory)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeviceInventory indicates an expected call of UpdateDeviceInventory.
+func (mr *MockDeviceRepoMockRecorder) UpdateDeviceInventory(ctx, deviceID, inventory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeviceInventory", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateDeviceInventory), ctx, deviceID, inventory)
+}
+
 // UpdateDeviceLabel mocks base method.
 func (m *MockDeviceRepo) UpdateDeviceLabel(ctx context.Context, deviceID, label string) error {
 	m.ctrl.T.Helper()
@@ -1118,6 +1805,48 @@ func (mr *MockDeviceRepoMockRecorder) UpdateDeviceStatus(ctx, deviceID, status i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeviceStatus", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateDeviceStatus), ctx, deviceID, status)
 }
 
+// UpdateHost mocks base method.
+func (m *MockDeviceRepo) UpdateHost(ctx context.Context, hostID string, update models.HostUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHost", ctx, hostID, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHost indicates an expected call of UpdateHost.
+func (mr *MockDeviceRepoMockRecorder) UpdateHost(ctx, hostID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHost", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateHost), ctx, hostID, update)
+}
+
+// UpdateHostArchived mocks base method.
+func (m *MockDeviceRepo) UpdateHostArchived(ctx context.Context, hostID string, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHostArchived", ctx, hostID, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHostArchived indicates an expected call of UpdateHostArchived.
+func (mr *MockDeviceRepoMockRecorder) UpdateHostArchived(ctx, hostID, archived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHostArchived", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateHostArchived), ctx, hostID, archived)
+}
+
+// UpdateHostMuted mocks base method.
+func (m *MockDeviceRepo) UpdateHostMuted(ctx context.Context, hostID string, muted bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHostMuted", ctx, hostID, muted)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHostMuted indicates an expected call of UpdateHostMuted.
+func (mr *MockDeviceRepoMockRecorder) UpdateHostMuted(ctx, hostID, muted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHostMuted", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateHostMuted), ctx, hostID, muted)
+}
+
 // UpdateMdadmArrayArchived mocks base method.
 func (m *MockDeviceRepo) UpdateMdadmArrayArchived(ctx context.Context, uuid string, archived bool) error {
 	m.ctrl.T.Helper()
@@ -1160,6 +1889,34 @@ func (mr *MockDeviceRepoMockRecorder) UpdateMdadmArrayMuted(ctx, uuid, muted int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMdadmArrayMuted", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateMdadmArrayMuted), ctx, uuid, muted)
 }
 
+// UpdateNotificationRetry mocks base method.
+func (m *MockDeviceRepo) UpdateNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationRetry", ctx, retry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationRetry indicates an expected call of UpdateNotificationRetry.
+func (mr *MockDeviceRepoMockRecorder) UpdateNotificationRetry(ctx, retry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationRetry", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateNotificationRetry), ctx, retry)
+}
+
+// UpdateNotifyUrlEscalation mocks base method.
+func (m *MockDeviceRepo) UpdateNotifyUrlEscalation(ctx context.Context, id uint, escalation bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotifyUrlEscalation", ctx, id, escalation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotifyUrlEscalation indicates an expected call of UpdateNotifyUrlEscalation.
+func (mr *MockDeviceRepoMockRecorder) UpdateNotifyUrlEscalation(ctx, id, escalation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotifyUrlEscalation", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateNotifyUrlEscalation), ctx, id, escalation)
+}
+
 // UpdateNotifyUrlHeartbeat mocks base method.
 func (m *MockDeviceRepo) UpdateNotifyUrlHeartbeat(ctx context.Context, id uint, enabled bool) error {
 	m.ctrl.T.Helper()
@@ -1174,6 +1931,20 @@ func (mr *MockDeviceRepoMockRecorder) UpdateNotifyUrlHeartbeat(ctx, id, enabled
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotifyUrlHeartbeat", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateNotifyUrlHeartbeat), ctx, id, enabled)
 }
 
+// UpdateNotifyUrlRules mocks base method.
+func (m *MockDeviceRepo) UpdateNotifyUrlRules(ctx context.Context, id uint, rules []models.NotifyRoutingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotifyUrlRules", ctx, id, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotifyUrlRules indicates an expected call of UpdateNotifyUrlRules.
+func (mr *MockDeviceRepoMockRecorder) UpdateNotifyUrlRules(ctx, id, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotifyUrlRules", reflect.TypeOf((*MockDeviceRepo)(nil).UpdateNotifyUrlRules), ctx, id, rules)
+}
+
 // UpdateZFSPoolArchived mocks base method.
 func (m *MockDeviceRepo) UpdateZFSPoolArchived(ctx context.Context, guid string, archived bool) error {
 	m.ctrl.T.Helper()
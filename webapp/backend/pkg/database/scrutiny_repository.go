@@ -2,13 +2,8 @@ package database
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -16,10 +11,6 @@ import (
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
-	"github.com/glebarez/sqlite"
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
-	"github.com/influxdata/influxdb-client-go/v2/api"
-	"github.com/influxdata/influxdb-client-go/v2/domain"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
@@ -68,36 +59,14 @@ func ResetMigrationGuardForTests() {
 	migrationOnceErr = nil
 }
 
-// openGormDatabase opens the SQLite database with the configured journal mode and pragmas,
+// openGormDatabase opens the metadata store (SQLite by default, or PostgreSQL via web.database.type),
 // returning a descriptive error for the common read-only/cap_drop Docker failure mode.
 func openGormDatabase(appConfig config.Interface, globalLogger logrus.FieldLogger) (*gorm.DB, error) {
-	globalLogger.Infof("Trying to connect to scrutiny sqlite db: %s\n", appConfig.GetString(cfgDatabaseLocation))
-
-	// When a transaction cannot lock the database, because it is already locked by another one,
-	// SQLite by default throws an error: database is locked. This behavior is usually not appropriate when
-	// concurrent access is needed, typically when multiple processes write to the same database.
-	// PRAGMA busy_timeout lets you set a timeout or a handler for these events. When setting a timeout,
-	// SQLite will try the transaction multiple times within this timeout.
-	// fixes #341
-	// https://rsqlite.r-dbi.org/reference/sqlitesetbusyhandler
-	// retrying for 30000 milliseconds, 30seconds - this would be unreasonable for a distributed multi-tenant application,
-	// but should be fine for local usage.
-	//
-	// WAL (Write-Ahead Logging) mode is used by default to reduce filesystem operations and improve
-	// compatibility with Docker containers running with restricted capabilities (cap_drop: [ALL]).
-	// fixes #772 (upstream), #25
-	// https://www.sqlite.org/wal.html
-	journalMode := appConfig.GetString("web.database.journal_mode")
-	if journalMode == "" {
-		journalMode = "WAL"
-	}
-
-	pragmaStr := sqlitePragmaString(map[string]string{
-		"busy_timeout": "30000",
-		"journal_mode": journalMode,
-		"temp_store":   "MEMORY",
-		"synchronous":  "NORMAL",
-	})
+	dialector, target, err := newGormDialector(appConfig)
+	if err != nil {
+		return nil, err
+	}
+	globalLogger.Infof("Trying to connect to scrutiny %s\n", target)
 
 	// Configure GORM logger based on debug mode
 	// In production (non-debug), use Silent mode for no performance impact
@@ -120,7 +89,7 @@ func openGormDatabase(appConfig config.Interface, globalLogger logrus.FieldLogge
 		},
 	)
 
-	database, err := gorm.Open(sqlite.Open(appConfig.GetString(cfgDatabaseLocation)+pragmaStr), &gorm.Config{
+	database, err := gorm.Open(dialector, &gorm.Config{
 		Logger:                                   gormLoggerConfig,
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
@@ -136,58 +105,10 @@ func openGormDatabase(appConfig config.Interface, globalLogger logrus.FieldLogge
 		}
 		return nil, fmt.Errorf("Failed to connect to database! - %v", err)
 	}
-	globalLogger.Infof("Successfully connected to scrutiny sqlite db: %s\n", appConfig.GetString(cfgDatabaseLocation))
+	globalLogger.Infof("Successfully connected to scrutiny %s\n", target)
 	return database, nil
 }
 
-// setupInfluxClient creates the InfluxDB client and runs first-time setup when the server is
-// un-initialized.
-func setupInfluxClient(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger) (influxdb2.Client, error) {
-	// Create a new client using an InfluxDB server base URL and an authentication token
-	influxdbUrl := fmt.Sprintf("%s://%s:%s", appConfig.GetString("web.influxdb.scheme"), appConfig.GetString("web.influxdb.host"), appConfig.GetString("web.influxdb.port"))
-	globalLogger.Debugf("InfluxDB url: %s", influxdbUrl)
-
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: appConfig.GetBool("web.influxdb.tls.insecure_skip_verify"),
-	}
-	globalLogger.Infof("InfluxDB certificate verification: %t\n", !tlsConfig.InsecureSkipVerify)
-
-	client := influxdb2.NewClientWithOptions(
-		influxdbUrl,
-		appConfig.GetString("web.influxdb.token"),
-		influxdb2.DefaultOptions().SetTLSConfig(tlsConfig),
-	)
-
-	globalLogger.Debugf("Determine Influxdb setup status...")
-	influxSetupComplete, err := InfluxSetupComplete(influxdbUrl, tlsConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check influxdb setup status - %w", err)
-	}
-
-	if !influxSetupComplete {
-		globalLogger.Debugf("Influxdb un-initialized, running first-time setup...")
-
-		// if no token is provided, but we have a valid server, we're going to assume this is the first setup of our server.
-		// we will initialize with a predetermined username & password, that you should change.
-
-		// metrics bucket will have a retention period of 8 days (since it will be down-sampled once a week)
-		// in seconds (60seconds * 60minutes * 24hours * 15 days) = 1_296_000 (see EnsureBucket() function)
-		_, err := client.SetupWithToken(
-			ctx,
-			appConfig.GetString("web.influxdb.init_username"),
-			appConfig.GetString("web.influxdb.init_password"),
-			appConfig.GetString(cfgInfluxDBOrg),
-			appConfig.GetString(cfgInfluxDBBucket),
-			0,
-			appConfig.GetString("web.influxdb.token"),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return client, nil
-}
-
 func newScrutinyRepository(appConfig config.Interface, globalLogger logrus.FieldLogger, runMigrations bool) (DeviceRepo, error) {
 	backgroundContext := context.Background()
 
@@ -196,49 +117,16 @@ func newScrutinyRepository(appConfig config.Interface, globalLogger logrus.Field
 		return nil, err
 	}
 
-	client, err := setupInfluxClient(backgroundContext, appConfig, globalLogger)
+	timeSeries, err := newTimeSeriesStore(backgroundContext, appConfig, globalLogger, database)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use blocking write client for writes to desired bucket
-	writeAPI := client.WriteAPIBlocking(appConfig.GetString(cfgInfluxDBOrg), appConfig.GetString(cfgInfluxDBBucket))
-
-	// Get query client
-	queryAPI := client.QueryAPI(appConfig.GetString(cfgInfluxDBOrg))
-
-	// Get task client
-	taskAPI := client.TasksAPI()
-
-	if writeAPI == nil || queryAPI == nil || taskAPI == nil {
-		return nil, fmt.Errorf("Failed to connect to influxdb!")
-	}
-
 	deviceRepo := scrutinyRepository{
-		appConfig:      appConfig,
-		logger:         globalLogger,
-		influxClient:   client,
-		influxWriteApi: writeAPI,
-		influxQueryApi: queryAPI,
-		influxTaskApi:  taskAPI,
-		gormClient:     database,
-	}
-
-	orgInfo, err := client.OrganizationsAPI().FindOrganizationByName(backgroundContext, appConfig.GetString(cfgInfluxDBOrg))
-	if err != nil {
-		return nil, err
-	}
-
-	// Initialize Buckets (if necessary)
-	err = deviceRepo.EnsureBuckets(backgroundContext, orgInfo)
-	if err != nil {
-		return nil, err
-	}
-
-	// Initialize Background Tasks
-	err = deviceRepo.EnsureTasks(backgroundContext, *orgInfo.Id)
-	if err != nil {
-		return nil, err
+		appConfig:  appConfig,
+		logger:     globalLogger,
+		timeSeries: timeSeries,
+		gormClient: database,
 	}
 
 	////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -253,6 +141,11 @@ func newScrutinyRepository(appConfig config.Interface, globalLogger logrus.Field
 		}
 	}
 
+	// the embedded time-series backend down-samples and prunes its own buckets
+	if sqliteStore, ok := timeSeries.(*sqliteTimeSeriesStore); ok {
+		sqliteStore.startMaintenance()
+	}
+
 	return &deviceRepo, nil
 }
 
@@ -260,16 +153,14 @@ type scrutinyRepository struct {
 	appConfig config.Interface
 	logger    logrus.FieldLogger
 
-	influxWriteApi api.WriteAPIBlocking
-	influxQueryApi api.QueryAPI
-	influxTaskApi  api.TasksAPI
-	influxClient   influxdb2.Client
+	// timeSeries stores SMART, temperature, performance and array/pool metrics (InfluxDB or embedded SQLite)
+	timeSeries timeSeriesStore
 
 	gormClient *gorm.DB
 }
 
 func (sr *scrutinyRepository) Close() error {
-	sr.influxClient.Close()
+	sr.timeSeries.Close()
 	return nil
 }
 
@@ -279,39 +170,22 @@ func (sr *scrutinyRepository) HealthCheck(ctx context.Context) (*HealthCheckResu
 		Checks: make(map[string]HealthCheckStatus),
 	}
 
-	// Check InfluxDB health with latency measurement
-	influxStart := time.Now()
-	status, err := sr.influxClient.Health(ctx)
-	influxLatency := time.Since(influxStart).Milliseconds()
-
-	if err != nil {
-		result.Status = "unhealthy"
-		result.Checks["influxdb"] = HealthCheckStatus{
-			Status:    "error",
-			LatencyMs: influxLatency,
-			Error:     err.Error(),
-		}
-	} else if status.Status != "pass" {
+	// Check time-series backend health with latency measurement
+	timeSeriesCheckName, timeSeriesCheck := sr.timeSeries.HealthCheck(ctx)
+	if timeSeriesCheck.Status != "ok" {
 		result.Status = "unhealthy"
-		result.Checks["influxdb"] = HealthCheckStatus{
-			Status:    "error",
-			LatencyMs: influxLatency,
-			Error:     fmt.Sprintf("influxdb status: %s", status.Status),
-		}
-	} else {
-		result.Checks["influxdb"] = HealthCheckStatus{
-			Status:    "ok",
-			LatencyMs: influxLatency,
-		}
 	}
+	result.Checks[timeSeriesCheckName] = timeSeriesCheck
 
-	// Check SQLite health with actual query execution (not just ping)
+	// Check metadata store health with actual query execution (not just ping)
 	sqliteStart := time.Now()
 	// Execute a simple query to verify database is responsive
 	var count int64
-	err = sr.gormClient.WithContext(ctx).Table("settings").Count(&count).Error
+	err := sr.gormClient.WithContext(ctx).Table("settings").Count(&count).Error
 	sqliteLatency := time.Since(sqliteStart).Milliseconds()
 
+	// the metadata store check keeps its "sqlite" key for every database type, so existing
+	// monitoring that reads checks.sqlite keeps working when PostgreSQL is used
 	if err != nil {
 		result.Status = "unhealthy"
 		result.Checks["sqlite"] = HealthCheckStatus{
@@ -326,6 +200,15 @@ func (sr *scrutinyRepository) HealthCheck(ctx context.Context) (*HealthCheckResu
 		}
 	}
 
+	// Report the notification retry queue depth. Informational only: a backlog of undelivered
+	// notifications does not make the server unhealthy.
+	pending, dead, err := sr.CountNotificationRetries(ctx)
+	if err != nil {
+		result.Checks["notification_queue"] = HealthCheckStatus{Status: "error", Error: err.Error()}
+	} else {
+		result.Checks["notification_queue"] = HealthCheckStatus{Status: "ok", Depth: &pending, DeadLetters: &dead}
+	}
+
 	// Return error only if critically unhealthy (for backwards compatibility with existing callers)
 	if result.Status == "unhealthy" {
 		// Build error message from failed checks
@@ -341,93 +224,6 @@ func (sr *scrutinyRepository) HealthCheck(ctx context.Context) (*HealthCheckResu
 	return result, nil
 }
 
-func InfluxSetupComplete(influxEndpoint string, tlsConfig *tls.Config) (bool, error) {
-	influxUri, err := url.Parse(influxEndpoint)
-	if err != nil {
-		return false, err
-	}
-	influxUri, err = influxUri.Parse("/api/v2/setup")
-	if err != nil {
-		return false, err
-	}
-
-	client := &http.Client{
-		Transport: &http.Transport{TLSClientConfig: tlsConfig},
-		Timeout:   10 * time.Second,
-	}
-	res, err := client.Get(influxUri.String())
-	if err != nil {
-		return false, err
-	}
-	defer res.Body.Close()
-
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return false, err
-	}
-
-	type SetupStatus struct {
-		Allowed bool `json:"allowed"`
-	}
-	var data SetupStatus
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return false, err
-	}
-	return !data.Allowed, nil
-}
-
-func (sr *scrutinyRepository) EnsureBuckets(ctx context.Context, org *domain.Organization) error {
-	// in tests, we may not want to set a retention policy. If "false", we can set data with old timestamps,
-	// then manually run the down sampling scripts. This should be true for production environments.
-	applyRetention := sr.appConfig.GetBool(cfgInfluxDBRetentionPolicy)
-
-	var mainRule, weeklyRule, monthlyRule domain.RetentionRule
-	if applyRetention {
-		mainRule = domain.RetentionRule{EverySeconds: int64(sr.appConfig.GetInt("web.influxdb.retention.daily"))}
-		weeklyRule = domain.RetentionRule{EverySeconds: int64(sr.appConfig.GetInt("web.influxdb.retention.weekly"))}
-		monthlyRule = domain.RetentionRule{EverySeconds: int64(sr.appConfig.GetInt("web.influxdb.retention.monthly"))}
-	}
-
-	baseBucket := sr.appConfig.GetString(cfgInfluxDBBucket)
-	// main bucket plus the weekly/monthly down-sampling buckets
-	if err := sr.ensureRetentionBucket(ctx, org, baseBucket, mainRule, applyRetention); err != nil {
-		return err
-	}
-	if err := sr.ensureRetentionBucket(ctx, org, baseBucket+"_weekly", weeklyRule, applyRetention); err != nil {
-		return err
-	}
-	if err := sr.ensureRetentionBucket(ctx, org, baseBucket+"_monthly", monthlyRule, applyRetention); err != nil {
-		return err
-	}
-
-	// metrics_yearly bucket will have an infinite retention period
-	yearlyBucket := baseBucket + "_yearly"
-	if _, foundErr := sr.influxClient.BucketsAPI().FindBucketByName(ctx, yearlyBucket); foundErr != nil {
-		if _, err := sr.influxClient.BucketsAPI().CreateBucketWithName(ctx, org, yearlyBucket); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// ensureRetentionBucket creates bucketName with the given retention rule when it does not exist,
-// or (when applyRetention is set) updates the existing bucket's retention rule.
-func (sr *scrutinyRepository) ensureRetentionBucket(ctx context.Context, org *domain.Organization, bucketName string, retentionRule domain.RetentionRule, applyRetention bool) error {
-	found, foundErr := sr.influxClient.BucketsAPI().FindBucketByName(ctx, bucketName)
-	if foundErr != nil {
-		_, err := sr.influxClient.BucketsAPI().CreateBucketWithName(ctx, org, bucketName, retentionRule)
-		return err
-	}
-	if applyRetention {
-		// correctly set the retention period (may not be able to do it during setup/creation)
-		found.RetentionRules = domain.RetentionRules{retentionRule}
-		_, _ = sr.influxClient.BucketsAPI().UpdateBucket(ctx, found)
-	}
-	return nil
-}
-
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // DeviceSummary
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -447,17 +243,12 @@ func (sr *scrutinyRepository) GetSummary(ctx context.Context) (map[string]*model
 		wwnToDeviceID[device.WWN] = device.DeviceID
 	}
 
-	result, err := sr.influxQueryApi.Query(ctx, summaryFluxQuery(sr.appConfig.GetString(cfgInfluxDBBucket)))
+	rows, err := sr.timeSeries.QuerySmartSummary(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer result.Close()
-	// Use Next() to iterate over query result lines
-	for result.Next() {
-		sr.applySummaryRecord(summaries, wwnToDeviceID, result.Record().Values())
-	}
-	if result.Err() != nil {
-		sr.logger.Errorf("Query error: %s", result.Err().Error())
+	for _, values := range rows {
+		sr.applySummaryRecord(summaries, wwnToDeviceID, values)
 	}
 
 	sr.attachTemperatureHistory(ctx, summaries)
@@ -465,72 +256,6 @@ func (sr *scrutinyRepository) GetSummary(ctx context.Context) (map[string]*model
 	return summaries, nil
 }
 
-// summaryFluxQuery builds the flux query that fetches the latest summary metrics (basic metrics +
-// SSD health + risk indicator attributes) per device across the daily/weekly/monthly/yearly buckets.
-func summaryFluxQuery(bucketBaseName string) string {
-	return fmt.Sprintf(`
-  	import "influxdata/influxdb/schema"
-  	bucketBaseName = "%s"
-
-	// Fields to retrieve for summary (basic metrics + SSD health + risk indicator attributes)
-	summaryFields = (r) =>
-		r["_field"] == "temp" or
-		r["_field"] == "power_on_hours" or
-		r["_field"] == "date" or
-		r["_field"] == "attr.percentage_used.value" or
-		r["_field"] == "attr.devstat_7_8.raw_value" or
-		r["_field"] == "attr.177.value" or
-		r["_field"] == "attr.233.value" or
-		r["_field"] == "attr.231.value" or
-		r["_field"] == "attr.232.value" or
-		r["_field"] == "attr.5.raw_value" or
-		r["_field"] == "attr.197.raw_value" or
-		r["_field"] == "attr.198.raw_value" or
-		r["_field"] == "attr.media_errors.value" or
-		r["_field"] == "attr.scsi_grown_defect_list.value"
-
-	dailyData = from(bucket: bucketBaseName)
-	|> range(start: -10y, stop: now())
-	|> filter(fn: (r) => r["_measurement"] == "smart" )
-	|> filter(fn: summaryFields)
-	|> last()
-	|> schema.fieldsAsCols()
-	|> group(columns: ["device_wwn"])
-
-	weeklyData = from(bucket: bucketBaseName + "_weekly")
-	|> range(start: -10y, stop: now())
-	|> filter(fn: (r) => r["_measurement"] == "smart" )
-	|> filter(fn: summaryFields)
-	|> last()
-	|> schema.fieldsAsCols()
-	|> group(columns: ["device_wwn"])
-
-	monthlyData = from(bucket: bucketBaseName + "_monthly")
-	|> range(start: -10y, stop: now())
-	|> filter(fn: (r) => r["_measurement"] == "smart" )
-	|> filter(fn: summaryFields)
-	|> last()
-	|> schema.fieldsAsCols()
-	|> group(columns: ["device_wwn"])
-
-	yearlyData = from(bucket: bucketBaseName + "_yearly")
-	|> range(start: -10y, stop: now())
-	|> filter(fn: (r) => r["_measurement"] == "smart" )
-	|> filter(fn: summaryFields)
-	|> last()
-	|> schema.fieldsAsCols()
-	|> group(columns: ["device_wwn"])
-
-	union(tables: [dailyData, weeklyData, monthlyData, yearlyData])
-	|> sort(columns: ["_time"], desc: false)
-	|> group(columns: ["device_wwn"])
-	|> tail(n: 1)
-	|> yield(name: "last")
-		`,
-		bucketBaseName,
-	)
-}
-
 // applySummaryRecord parses a single summary query record and populates the matching device summary.
 func (sr *scrutinyRepository) applySummaryRecord(summaries map[string]*models.DeviceSummary, wwnToDeviceID map[string]string, values map[string]interface{}) {
 	deviceWWN, ok := values["device_wwn"]
@@ -611,7 +336,7 @@ func (sr *scrutinyRepository) attachTemperatureHistory(ctx context.Context, summ
 }
 
 // GetDevicesLastSeenTimes returns a map of device WWN to the timestamp of their last SMART submission.
-// This queries InfluxDB for the most recent submission time for each device, which is more efficient
+// This queries the time-series backend for the most recent submission time for each device, which is more efficient
 // than calling GetSummary when only timestamps are needed.
 func (sr *scrutinyRepository) GetDevicesLastSeenTimes(ctx context.Context) (map[string]time.Time, error) {
 	// Build WWN-to-DeviceID map for re-keying InfluxDB results
@@ -624,68 +349,19 @@ func (sr *scrutinyRepository) GetDevicesLastSeenTimes(ctx context.Context) (map[
 		wwnToDeviceID[devices[i].WWN] = devices[i].DeviceID
 	}
 
-	result, err := sr.influxQueryApi.Query(ctx, lastSeenFluxQuery(sr.appConfig.GetString(cfgInfluxDBBucket)))
+	rows, err := sr.timeSeries.QueryLastSeen(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query last seen times: %w", err)
 	}
-	defer result.Close()
 
 	lastSeenTimes := map[string]time.Time{}
-	for result.Next() {
-		applyLastSeenRecord(lastSeenTimes, wwnToDeviceID, result.Record().Values())
-	}
-
-	if result.Err() != nil {
-		return nil, fmt.Errorf("query result error: %w", result.Err())
+	for _, values := range rows {
+		applyLastSeenRecord(lastSeenTimes, wwnToDeviceID, values)
 	}
 
 	return lastSeenTimes, nil
 }
 
-// lastSeenFluxQuery builds the flux query that returns the most recent SMART submission time per
-// device across all buckets. The "temp" field is used because it is always present in SMART data
-// (Date is stored as the point timestamp _time, not a field).
-func lastSeenFluxQuery(bucketBaseName string) string {
-	return fmt.Sprintf(`
-import "influxdata/influxdb/schema"
-bucketBaseName = "%s"
-
-dailyData = from(bucket: bucketBaseName)
-|> range(start: -10y, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["_field"] == "temp")
-|> last()
-|> group(columns: ["device_wwn"])
-
-weeklyData = from(bucket: bucketBaseName + "_weekly")
-|> range(start: -10y, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["_field"] == "temp")
-|> last()
-|> group(columns: ["device_wwn"])
-
-monthlyData = from(bucket: bucketBaseName + "_monthly")
-|> range(start: -10y, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["_field"] == "temp")
-|> last()
-|> group(columns: ["device_wwn"])
-
-yearlyData = from(bucket: bucketBaseName + "_yearly")
-|> range(start: -10y, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["_field"] == "temp")
-|> last()
-|> group(columns: ["device_wwn"])
-
-union(tables: [dailyData, weeklyData, monthlyData, yearlyData])
-|> group(columns: ["device_wwn"])
-|> sort(columns: ["_time"], desc: false)
-|> last()
-|> yield(name: "last_seen")
-	`, bucketBaseName)
-}
-
 // applyLastSeenRecord re-keys a last-seen query record from WWN to DeviceID and keeps the most
 // recent timestamp seen for that device.
 func applyLastSeenRecord(lastSeenTimes map[string]time.Time, wwnToDeviceID map[string]string, values map[string]interface{}) {
@@ -710,110 +386,8 @@ func applyLastSeenRecord(lastSeenTimes map[string]time.Time, wwnToDeviceID map[s
 	}
 }
 
-// GetAvailableInfluxDBBuckets returns a list of bucket names available in InfluxDB.
+// GetAvailableInfluxDBBuckets returns a list of bucket names available in the time-series backend.
 // This is used for diagnostics to verify required buckets exist.
 func (sr *scrutinyRepository) GetAvailableInfluxDBBuckets(ctx context.Context) ([]string, error) {
-	org := sr.appConfig.GetString(cfgInfluxDBOrg)
-
-	// Query InfluxDB for all buckets in the organization
-	buckets, err := sr.influxClient.BucketsAPI().FindBucketsByOrgName(ctx, org)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query InfluxDB buckets: %w", err)
-	}
-
-	bucketNames := make([]string, 0, len(*buckets))
-	for _, bucket := range *buckets {
-		bucketNames = append(bucketNames, bucket.Name)
-	}
-
-	return bucketNames, nil
-}
-
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Helper Methods
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-
-func (sr *scrutinyRepository) lookupBucketName(durationKey string) string {
-	switch durationKey {
-	case DURATION_KEY_DAY:
-	case DURATION_KEY_WEEK:
-		//data stored in the last week
-		return sr.appConfig.GetString(cfgInfluxDBBucket)
-	case DURATION_KEY_MONTH:
-		// data stored in the last month (after the first week)
-		return fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket))
-	case DURATION_KEY_YEAR:
-		// data stored in the last year (after the first month)
-		return fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket))
-	case DURATION_KEY_FOREVER:
-		//data stored before the last year
-		return fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket))
-	}
-	return sr.appConfig.GetString(cfgInfluxDBBucket)
-}
-
-func (sr *scrutinyRepository) lookupDuration(durationKey string) []string {
-	switch durationKey {
-	case DURATION_KEY_DAY:
-		//data stored in the last day
-		return []string{INFLUX_DURATION_1_DAY, INFLUX_NOW}
-	case DURATION_KEY_WEEK:
-		//data stored in the last week
-		return []string{INFLUX_DURATION_1_WEEK, INFLUX_NOW}
-	case DURATION_KEY_MONTH:
-		// data stored in the last month (after the first week)
-		return []string{INFLUX_DURATION_1_MONTH, INFLUX_DURATION_1_WEEK}
-	case DURATION_KEY_YEAR:
-		// data stored in the last year (after the first month)
-		return []string{INFLUX_DURATION_1_YEAR, INFLUX_DURATION_1_MONTH}
-	case DURATION_KEY_FOREVER:
-		//data stored before the last year
-		return []string{INFLUX_DURATION_10_YEARS, INFLUX_DURATION_1_YEAR}
-	}
-	return []string{INFLUX_DURATION_1_WEEK, INFLUX_NOW}
-}
-
-func (sr *scrutinyRepository) lookupResolution(durationKey string) string {
-	switch durationKey {
-	case DURATION_KEY_DAY:
-		// Return raw data for daily view so tooltip timestamps match actual collection times
-		return ""
-	default:
-		// Return data with 1h resolution for other summaries
-		return RESOLUTION_1_HOUR
-	}
-}
-
-func (sr *scrutinyRepository) lookupNestedDurationKeys(durationKey string) []string {
-	switch durationKey {
-	case DURATION_KEY_DAY:
-		//all data is stored in a single bucket, but we want a finer resolution
-		return []string{DURATION_KEY_DAY}
-	case DURATION_KEY_WEEK:
-		//all data is stored in a single bucket
-		return []string{DURATION_KEY_WEEK}
-	case DURATION_KEY_MONTH:
-		//data is stored in the week bucket and the month bucket
-		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH}
-	case DURATION_KEY_YEAR:
-		// data stored in the last year (after the first month)
-		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH, DURATION_KEY_YEAR}
-	case DURATION_KEY_FOREVER:
-		//data stored before the last year
-		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH, DURATION_KEY_YEAR, DURATION_KEY_FOREVER}
-	}
-	return []string{DURATION_KEY_WEEK}
-}
-
-func sqlitePragmaString(pragmas map[string]string) string {
-	q := url.Values{}
-	for key, val := range pragmas {
-		q.Add("_pragma", key+"="+val)
-	}
-
-	queryStr := q.Encode()
-	if len(queryStr) > 0 {
-		return "?" + queryStr
-	}
-	return ""
+	return sr.timeSeries.BucketNames(ctx)
 }
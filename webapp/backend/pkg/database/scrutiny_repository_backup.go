@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Backup & Restore
+// //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// TimeSeriesExportPoint is a single time-series point in a backend-neutral form, as stored in backup
+// archives. Points exported from InfluxDB can be restored into the SQLite backend and vice versa.
+type TimeSeriesExportPoint struct {
+	Bucket      string
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+// timeSeriesExportJSON is the on-disk form of TimeSeriesExportPoint. Fields use the typed
+// encodedFields representation so integers, floats and booleans survive the round trip.
+type timeSeriesExportJSON struct {
+	Bucket      string            `json:"bucket"`
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Fields      json.RawMessage   `json:"fields"`
+	Time        time.Time         `json:"time"`
+}
+
+func (p TimeSeriesExportPoint) MarshalJSON() ([]byte, error) {
+	fields, err := encodeFields(normalizeFields(p.Fields))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(timeSeriesExportJSON{
+		Bucket:      p.Bucket,
+		Measurement: p.Measurement,
+		Tags:        p.Tags,
+		Fields:      json.RawMessage(fields),
+		Time:        p.Time.UTC(),
+	})
+}
+
+func (p *TimeSeriesExportPoint) UnmarshalJSON(data []byte) error {
+	var raw timeSeriesExportJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	fields, err := decodeFields(string(raw.Fields))
+	if err != nil {
+		return fmt.Errorf("could not decode fields of %s point: %w", raw.Measurement, err)
+	}
+	if raw.Tags == nil {
+		raw.Tags = map[string]string{}
+	}
+	*p = TimeSeriesExportPoint{
+		Bucket:      raw.Bucket,
+		Measurement: raw.Measurement,
+		Tags:        raw.Tags,
+		Fields:      fields,
+		Time:        raw.Time,
+	}
+	return nil
+}
+
+// BackupDatabase writes a consistent snapshot of the SQLite metadata database to destinationPath.
+// VACUUM INTO reads the database inside a single transaction, so the snapshot is consistent even
+// while Scrutiny is running in WAL mode, and the result is a standalone (non-WAL) database file.
+func (sr *scrutinyRepository) BackupDatabase(ctx context.Context, destinationPath string) error {
+	if isPostgres(sr.gormClient) {
+		return fmt.Errorf("database backups are only supported for the SQLite metadata store; use pg_dump or managed backups for PostgreSQL")
+	}
+	if _, err := os.Stat(destinationPath); err == nil {
+		return fmt.Errorf("backup destination %s already exists", destinationPath)
+	}
+	if err := sr.gormClient.WithContext(ctx).Exec("VACUUM INTO ?", destinationPath).Error; err != nil {
+		return fmt.Errorf("could not snapshot database: %w", err)
+	}
+	return nil
+}
+
+// GetAppliedMigrations returns the IDs of every migration recorded in the migrations table, sorted.
+func (sr *scrutinyRepository) GetAppliedMigrations(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := sr.gormClient.WithContext(ctx).Table("migrations").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("could not read applied migrations: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ExportTimeSeries streams every point of the raw and down-sampled buckets to fn, and returns the
+// names of the buckets that were exported. Buckets that do not exist yet are skipped.
+func (sr *scrutinyRepository) ExportTimeSeries(ctx context.Context, fn func(point TimeSeriesExportPoint) error) ([]string, error) {
+	available, err := sr.timeSeries.BucketNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, bucket := range available {
+		exists[bucket] = true
+	}
+
+	exported := []string{}
+	for _, bucket := range historyBuckets(sr.appConfig.GetString(cfgInfluxDBBucket)) {
+		if !exists[bucket] {
+			continue
+		}
+		if err := sr.timeSeries.ExportBucket(ctx, bucket, fn); err != nil {
+			return nil, fmt.Errorf("could not export bucket %s: %w", bucket, err)
+		}
+		exported = append(exported, bucket)
+	}
+	return exported, nil
+}
+
+// ImportTimeSeriesPoint writes a previously exported point back into its bucket. Points that are
+// older than the bucket's retention period are skipped.
+func (sr *scrutinyRepository) ImportTimeSeriesPoint(ctx context.Context, point TimeSeriesExportPoint) error {
+	err := sr.timeSeries.WriteBucketPoint(ctx, point.Bucket, point.Measurement, point.Tags, point.Fields, point.Time)
+	return sr.ignorePastRetentionPolicyError(err)
+}
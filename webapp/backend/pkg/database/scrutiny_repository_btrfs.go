@@ -92,6 +92,9 @@ func (sr *scrutinyRepository) GetBtrfsFilesystems(ctx context.Context) ([]models
 	if err := sr.gormClient.WithContext(ctx).Where("archived = ?", false).Find(&filesystems).Error; err != nil {
 		return nil, fmt.Errorf("could not get Btrfs filesystems from DB: %v", err)
 	}
+	if err := sr.attachBtrfsFilesystemTags(ctx, filesystems); err != nil {
+		return nil, err
+	}
 	return filesystems, nil
 }
 
@@ -105,6 +108,11 @@ func (sr *scrutinyRepository) GetBtrfsFilesystemDetails(ctx context.Context, uui
 		return filesystem, err
 	}
 	filesystem.Devices = devices
+	tags, err := sr.getResourceTagsOf(ctx, models.TagResourceBtrfsFilesystem, uuid)
+	if err != nil {
+		return filesystem, err
+	}
+	filesystem.Tags = tags
 	return filesystem, nil
 }
 
@@ -142,25 +150,12 @@ func (sr *scrutinyRepository) DeleteBtrfsFilesystem(ctx context.Context, uuid st
 	if err := sr.gormClient.WithContext(ctx).Where(queryUUID, uuid).Delete(&models.BtrfsFilesystem{}).Error; err != nil {
 		return err
 	}
-
-	buckets := []string{
-		sr.appConfig.GetString(cfgInfluxDBBucket),
-		fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket)),
+	if err := sr.deleteResourceTags(ctx, models.TagResourceBtrfsFilesystem, uuid); err != nil {
+		return err
 	}
 
-	for _, bucket := range buckets {
-		if err := sr.influxClient.DeleteAPI().DeleteWithName(
-			ctx,
-			sr.appConfig.GetString(cfgInfluxDBOrg),
-			bucket,
-			time.Now().AddDate(-10, 0, 0),
-			time.Now(),
-			fmt.Sprintf(`filesystem_uuid=%q`, uuid),
-		); err != nil {
-			return err
-		}
+	if err := sr.timeSeries.DeleteSeries(ctx, "filesystem_uuid", uuid); err != nil {
+		return err
 	}
 	return nil
 }
@@ -200,39 +195,23 @@ func (sr *scrutinyRepository) SaveBtrfsMetrics(ctx context.Context, filesystem *
 		ScrubSuperErrors:  filesystem.ScrubSuperErrors,
 	}
 	tags, fields := metrics.Flatten()
-	return sr.saveDatapoint(sr.influxWriteApi, "btrfs_filesystem", tags, fields, metrics.Date, ctx)
+	return sr.timeSeries.WritePoint(ctx, "btrfs_filesystem", tags, fields, metrics.Date)
 }
 
 func (sr *scrutinyRepository) GetBtrfsMetricsHistory(ctx context.Context, uuid string, durationKey string) ([]measurements.BtrfsMetrics, error) {
-	bucketName := sr.lookupBucketName(durationKey)
-	duration := sr.lookupDuration(durationKey)
-	queryStr := fmt.Sprintf(`
-		from(bucket: "%s")
-		|> range(start: %s, stop: %s)
-		|> filter(fn: (r) => r["_measurement"] == "btrfs_filesystem")
-		|> filter(fn: (r) => r["filesystem_uuid"] == params.uuid)
-		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
-		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-		|> sort(columns: ["_time"], desc: false)
-	`, bucketName, duration[0], duration[1])
-
-	result, err := sr.influxQueryApi.QueryWithParams(ctx, queryStr, map[string]interface{}{"uuid": uuid})
+	rows, err := sr.timeSeries.QueryBtrfsHistory(ctx, uuid, durationKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query Btrfs metrics: %v", err)
 	}
-	defer result.Close()
 
 	history := []measurements.BtrfsMetrics{}
-	for result.Next() {
-		metrics, err := measurements.NewBtrfsMetricsFromInfluxDB(result.Record().Values())
+	for _, values := range rows {
+		metrics, err := measurements.NewBtrfsMetricsFromInfluxDB(values)
 		if err != nil {
 			sr.logger.Warnf("Failed to parse Btrfs metrics: %v", err)
 			continue
 		}
 		history = append(history, *metrics)
 	}
-	if result.Err() != nil {
-		return nil, result.Err()
-	}
 	return history, nil
 }
@@ -16,7 +16,7 @@ func createBtrfsTestRepository(t *testing.T) *scrutinyRepository {
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, db.AutoMigrate(&models.BtrfsFilesystem{}, &models.BtrfsDevice{}))
+	require.NoError(t, db.AutoMigrate(&models.BtrfsFilesystem{}, &models.BtrfsDevice{}, &models.ResourceTag{}))
 
 	return &scrutinyRepository{gormClient: db}
 }
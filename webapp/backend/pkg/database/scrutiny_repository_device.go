@@ -156,6 +156,9 @@ func (sr *scrutinyRepository) GetDevices(ctx context.Context) ([]models.Device,
 	if err := sr.attachDeviceEnduranceOverrides(ctx, devices); err != nil {
 		return nil, err
 	}
+	if err := sr.attachDeviceTags(ctx, devices); err != nil {
+		return nil, err
+	}
 	return devices, nil
 }
 
@@ -166,6 +169,7 @@ func (sr *scrutinyRepository) UpdateDevice(ctx context.Context, deviceID string,
 		return device, fmt.Errorf(errDeviceNotFound, err)
 	}
 
+	previousStatus := device.DeviceStatus
 	err := device.UpdateFromCollectorSmartInfo(*collectorSmartData)
 	if err != nil {
 		return device, err
@@ -175,7 +179,15 @@ func (sr *scrutinyRepository) UpdateDevice(ctx context.Context, deviceID string,
 	}
 	// Explicitly update device_status to handle zero values, since GORM's Updates(struct)
 	// silently skips zero-value fields and DeviceStatusPassed is 0.
-	return device, sr.gormClient.Model(&device).Update("device_status", device.DeviceStatus).Error
+	if err := sr.gormClient.Model(&device).Update("device_status", device.DeviceStatus).Error; err != nil {
+		return device, err
+	}
+	if previousStatus != pkg.DeviceStatusPassed && device.DeviceStatus == pkg.DeviceStatusPassed {
+		if err := sr.resolveDeviceIncidents(ctx, device.DeviceID); err != nil {
+			sr.logger.Warnf("Failed to resolve incidents for device %s: %v", device.DeviceID, err)
+		}
+	}
+	return device, nil
 }
 
 // Update Device Status
@@ -200,9 +212,12 @@ func (sr *scrutinyRepository) ResetDeviceStatus(ctx context.Context, deviceID st
 	// Use map-based update because GORM's Updates(struct) silently skips zero-value fields,
 	// and DeviceStatusPassed is 0. Without this, the device_status column is never actually
 	// reset in the database.
-	return device, sr.gormClient.Model(&device).Updates(map[string]interface{}{
+	if err := sr.gormClient.Model(&device).Updates(map[string]interface{}{
 		"device_status": pkg.DeviceStatusPassed,
-	}).Error
+	}).Error; err != nil {
+		return device, err
+	}
+	return device, sr.resolveDeviceIncidents(ctx, device.DeviceID)
 }
 
 // RecalculateDeviceStatusFromHistory re-evaluates device status from stored SMART data
@@ -316,6 +331,12 @@ func (sr *scrutinyRepository) GetDeviceDetails(ctx context.Context, deviceID str
 	}
 	device = devices[0]
 
+	tags, err := sr.getResourceTagsOf(ctx, models.TagResourceDevice, deviceID)
+	if err != nil {
+		return models.Device{}, err
+	}
+	device.Tags = tags
+
 	return device, nil
 }
 
@@ -376,6 +397,46 @@ func (sr *scrutinyRepository) UpdateDeviceMaxTBW(ctx context.Context, deviceID s
 	return sr.gormClient.WithContext(ctx).Save(&override).Error
 }
 
+// UpdateDeviceInventory replaces the inventory fields of a device. Changing the warranty expiry
+// re-arms the warranty expiry notification.
+func (sr *scrutinyRepository) UpdateDeviceInventory(ctx context.Context, deviceID string, inventory models.DeviceInventory) error {
+	var device models.Device
+	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, deviceID).First(&device).Error; err != nil {
+		return fmt.Errorf(errDeviceNotFound, err)
+	}
+
+	updates := map[string]interface{}{
+		"purchase_date":       inventory.PurchaseDate,
+		"warranty_expires_at": inventory.WarrantyExpiresAt,
+		"purchase_price":      inventory.PurchasePrice,
+		"vendor":              inventory.Vendor,
+		"rma_contact":         inventory.RMAContact,
+		"location":            inventory.Location,
+	}
+	if !sameDate(device.WarrantyExpiresAt, inventory.WarrantyExpiresAt) {
+		updates["warranty_notified_at"] = nil
+		updates["warranty_expired_notified_at"] = nil
+	}
+	return sr.gormClient.WithContext(ctx).Model(&device).Updates(updates).Error
+}
+
+// MarkDeviceWarrantyNotified records that the warranty expiry notification was sent for a device.
+func (sr *scrutinyRepository) MarkDeviceWarrantyNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error {
+	return sr.gormClient.WithContext(ctx).Model(&models.Device{}).Where(queryDeviceID, deviceID).Update("warranty_notified_at", notifiedAt).Error
+}
+
+// MarkDeviceWarrantyExpiredNotified records that the warranty expired notification was sent for a device.
+func (sr *scrutinyRepository) MarkDeviceWarrantyExpiredNotified(ctx context.Context, deviceID string, notifiedAt time.Time) error {
+	return sr.gormClient.WithContext(ctx).Model(&models.Device{}).Where(queryDeviceID, deviceID).Update("warranty_expired_notified_at", notifiedAt).Error
+}
+
+func sameDate(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
 // Update Device Smart Display Mode (user preference for attribute value display)
 func (sr *scrutinyRepository) UpdateDeviceSmartDisplayMode(ctx context.Context, deviceID string, mode string) error {
 	// Validate mode is one of the allowed values
@@ -410,7 +471,7 @@ func (sr *scrutinyRepository) UpdateDeviceMissedPingTimeout(ctx context.Context,
 }
 
 func (sr *scrutinyRepository) DeleteDevice(ctx context.Context, deviceID string) error {
-	// Look up device to get WWN for InfluxDB cleanup
+	// Look up device to get WWN for time-series cleanup
 	var device models.Device
 	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, deviceID).First(&device).Error; err != nil {
 		return fmt.Errorf("could not find device: %w", err)
@@ -419,28 +480,18 @@ func (sr *scrutinyRepository) DeleteDevice(ctx context.Context, deviceID string)
 	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, deviceID).Delete(&models.Device{}).Error; err != nil {
 		return err
 	}
+	if err := sr.deleteResourceTags(ctx, models.TagResourceDevice, deviceID); err != nil {
+		return err
+	}
+	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, deviceID).Delete(&models.Incident{}).Error; err != nil {
+		return err
+	}
 
-	// Delete data from InfluxDB using WWN (InfluxDB tags use device_wwn)
+	// Delete time-series data using WWN (time-series tags use device_wwn)
 	if device.WWN != "" {
-		buckets := []string{
-			sr.appConfig.GetString(cfgInfluxDBBucket),
-			fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-			fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-			fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		}
-
-		for _, bucket := range buckets {
-			sr.logger.Infof("Deleting data for %s (wwn: %s) in bucket: %s", deviceID, device.WWN, bucket)
-			if err := sr.influxClient.DeleteAPI().DeleteWithName(
-				ctx,
-				sr.appConfig.GetString(cfgInfluxDBOrg),
-				bucket,
-				time.Now().AddDate(-10, 0, 0),
-				time.Now(),
-				fmt.Sprintf("device_wwn=%q", device.WWN),
-			); err != nil {
-				return err
-			}
+		sr.logger.Infof("Deleting data for %s (wwn: %s)", deviceID, device.WWN)
+		if err := sr.timeSeries.DeleteSeries(ctx, "device_wwn", device.WWN); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDeviceInventory_RearmsWarrantyNotificationOnExpiryChange(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.DeviceEnduranceOverride{}))
+	ctx := context.Background()
+	require.NoError(t, repo.RegisterDevice(ctx, models.Device{DeviceID: "device-1", WWN: "wwn-1", SerialNumber: "S1"}))
+
+	expiry := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	price := 129.99
+	inventory := models.DeviceInventory{WarrantyExpiresAt: &expiry, PurchasePrice: &price, Vendor: "Acme", Location: "Rack 2, bay 4"}
+	require.NoError(t, repo.UpdateDeviceInventory(ctx, "device-1", inventory))
+	require.NoError(t, repo.MarkDeviceWarrantyNotified(ctx, "device-1", time.Now()))
+
+	// editing other fields keeps the notification state
+	inventory.Location = "Rack 3, bay 1"
+	require.NoError(t, repo.UpdateDeviceInventory(ctx, "device-1", inventory))
+	device, err := repo.GetDeviceDetails(ctx, "device-1")
+	require.NoError(t, err)
+	require.Equal(t, "Rack 3, bay 1", device.Location)
+	require.Equal(t, 129.99, *device.PurchasePrice)
+	require.True(t, expiry.Equal(*device.WarrantyExpiresAt))
+	require.NotNil(t, device.WarrantyNotifiedAt)
+
+	// a new expiry date re-arms the notification
+	extended := expiry.AddDate(1, 0, 0)
+	inventory.WarrantyExpiresAt = &extended
+	require.NoError(t, repo.UpdateDeviceInventory(ctx, "device-1", inventory))
+	device, err = repo.GetDeviceDetails(ctx, "device-1")
+	require.NoError(t, err)
+	require.Nil(t, device.WarrantyNotifiedAt)
+
+	// the collector re-registering the device does not touch inventory data
+	require.NoError(t, repo.RegisterDevice(ctx, models.Device{DeviceID: "device-1", WWN: "wwn-1", SerialNumber: "S1", DeviceName: "sdb"}))
+	device, err = repo.GetDeviceDetails(ctx, "device-1")
+	require.NoError(t, err)
+	require.Equal(t, "Acme", device.Vendor)
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+// RecordDeviceLifecycleEvents compares the devices registered by collectors with the devices known
+// before the registration, then stores and returns the lifecycle events of the registering hosts.
+// Pool memberships are looked up for the devices a swap could involve, to match a replacement to
+// the device it replaced, and swaps are annotated with the pools the replaced device was a member of.
+func (sr *scrutinyRepository) RecordDeviceLifecycleEvents(ctx context.Context, known []models.Device, registered []models.Device) ([]models.DeviceLifecycleEvent, error) {
+	hostIDs := []string{}
+	byHost := map[string][]models.Device{}
+	for _, device := range registered {
+		if _, ok := byHost[device.HostId]; !ok {
+			hostIDs = append(hostIDs, device.HostId)
+		}
+		byHost[device.HostId] = append(byHost[device.HostId], device)
+	}
+
+	now := time.Now().UTC()
+	recorded := []models.DeviceLifecycleEvent{}
+	for _, hostID := range hostIDs {
+		latest, err := sr.latestDeviceLifecycleEvents(ctx, hostID)
+		if err != nil {
+			return recorded, err
+		}
+
+		events := models.DetectDeviceLifecycleEvents(hostID, known, models.DeviceLifecycleStates(latest), byHost[hostID], sr.cachedPoolMemberships(ctx), now)
+		if len(events) == 0 {
+			continue
+		}
+		if err := sr.gormClient.WithContext(ctx).Create(&events).Error; err != nil {
+			return recorded, fmt.Errorf("could not save device lifecycle events: %w", err)
+		}
+		recorded = append(recorded, events...)
+	}
+	return recorded, nil
+}
+
+// GetDeviceLifecycleEvents returns the lifecycle events matching the filter, newest first.
+func (sr *scrutinyRepository) GetDeviceLifecycleEvents(ctx context.Context, filter models.DeviceLifecycleFilter) ([]models.DeviceLifecycleEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultNotificationHistoryLimit
+	}
+	if limit > maxNotificationHistoryLimit {
+		limit = maxNotificationHistoryLimit
+	}
+
+	query := sr.gormClient.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit)
+	if filter.HostID != "" {
+		query = query.Where("host_id = ?", filter.HostID)
+	}
+	if filter.DeviceID != "" {
+		query = query.Where("device_id = ? OR previous_device_id = ?", filter.DeviceID, filter.DeviceID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	events := []models.DeviceLifecycleEvent{}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("could not get device lifecycle events from DB: %w", err)
+	}
+	return events, nil
+}
+
+// latestDeviceLifecycleEvents returns the events that decide the lifecycle state of a host's
+// devices (oldest first): the latest event about each device, and the latest swap that replaced it.
+// Registrations are frequent, so the rest of the history is not loaded.
+func (sr *scrutinyRepository) latestDeviceLifecycleEvents(ctx context.Context, hostID string) ([]models.DeviceLifecycleEvent, error) {
+	latestByDevice := sr.gormClient.Model(&models.DeviceLifecycleEvent{}).
+		Select("MAX(id)").Where("host_id = ?", hostID).Group("device_id")
+	latestByPrevious := sr.gormClient.Model(&models.DeviceLifecycleEvent{}).
+		Select("MAX(id)").Where("host_id = ? AND previous_device_id <> ''", hostID).Group("previous_device_id")
+
+	events := []models.DeviceLifecycleEvent{}
+	err := sr.gormClient.WithContext(ctx).
+		Where("id IN (?) OR id IN (?)", latestByDevice, latestByPrevious).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not get device lifecycle events from DB: %w", err)
+	}
+	return events, nil
+}
+
+// cachedPoolMemberships returns a lookup of device pool memberships that queries each device once.
+// Lookup failures are logged and treated as no membership.
+func (sr *scrutinyRepository) cachedPoolMemberships(ctx context.Context) func(models.Device) []models.PoolMembership {
+	cache := map[string][]models.PoolMembership{}
+	return func(device models.Device) []models.PoolMembership {
+		if memberships, ok := cache[device.DeviceID]; ok {
+			return memberships
+		}
+		memberships, err := sr.devicePoolMemberships(ctx, device)
+		if err != nil {
+			sr.logger.Warnf("Failed to look up pool memberships of device %s: %v", device.DeviceID, err)
+		}
+		cache[device.DeviceID] = memberships
+		return memberships
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordDeviceLifecycleEvents_SwapCarriesPoolMembership(t *testing.T) {
+	repo := createDeviceReplacementTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.DeviceLifecycleEvent{}))
+	ctx := context.Background()
+
+	known := []models.Device{
+		{DeviceID: "a", DeviceName: "sdb", HostId: "nas", SerialNumber: "S1"},
+		{DeviceID: "b", DeviceName: "sdc", HostId: "nas", SerialNumber: "S2", ModelName: "WDC WD40EFRX", DeviceInventory: models.DeviceInventory{Location: "Bay 3"}},
+	}
+	require.NoError(t, repo.gormClient.Create(&models.MDADMArray{UUID: "md-uuid", Name: "md0", HostID: "nas", Devices: []string{"/dev/sdb1", "/dev/sdc1"}}).Error)
+
+	// the first registration of a host sets the baseline
+	events, err := repo.RecordDeviceLifecycleEvents(ctx, nil, known)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	registered := []models.Device{known[0], {DeviceID: "c", DeviceName: "sdc", HostId: "nas", SerialNumber: "S9"}}
+	events, err = repo.RecordDeviceLifecycleEvents(ctx, known, registered)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, models.LifecycleEventDeviceSwapped, events[0].Type)
+	require.Equal(t, "Bay 3", events[0].Slot)
+	require.Equal(t, []models.PoolMembership{{Type: models.PoolTypeMdadm, ID: "md-uuid", Name: "md0", Member: "/dev/sdc1"}}, events[0].PoolMemberships)
+
+	// the replaced device is not reported as disappeared on the next registration
+	known = append(known, registered[1])
+	events, err = repo.RecordDeviceLifecycleEvents(ctx, known, registered)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	// the replaced device coming back is reported as reappeared
+	events, err = repo.RecordDeviceLifecycleEvents(ctx, known, known)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, models.LifecycleEventDeviceReappeared, events[0].Type)
+	require.Equal(t, "b", events[0].DeviceID)
+
+	stored, err := repo.GetDeviceLifecycleEvents(ctx, models.DeviceLifecycleFilter{DeviceID: "b"})
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	require.Equal(t, models.LifecycleEventDeviceReappeared, stored[0].Type)
+	require.Equal(t, models.LifecycleEventDeviceSwapped, stored[1].Type)
+	require.Equal(t, events[0].PoolMemberships, stored[0].PoolMemberships)
+
+	swaps, err := repo.GetDeviceLifecycleEvents(ctx, models.DeviceLifecycleFilter{HostID: "nas", Type: models.LifecycleEventDeviceSwapped})
+	require.NoError(t, err)
+	require.Len(t, swaps, 1)
+	require.Equal(t, "md0", swaps[0].PoolMemberships[0].Name)
+}
+
+func TestLatestDeviceLifecycleEvents_OnlyLoadsTheLatestEventPerDevice(t *testing.T) {
+	repo := createDeviceReplacementTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.DeviceLifecycleEvent{}))
+	ctx := context.Background()
+
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	history := []models.DeviceLifecycleEvent{
+		{CreatedAt: base, Type: models.LifecycleEventDeviceDisappeared, HostID: "nas", DeviceID: "a"},
+		{CreatedAt: base.Add(time.Hour), Type: models.LifecycleEventDeviceReappeared, HostID: "nas", DeviceID: "a"},
+		{CreatedAt: base.Add(2 * time.Hour), Type: models.LifecycleEventDeviceDisappeared, HostID: "nas", DeviceID: "a"},
+		{CreatedAt: base.Add(3 * time.Hour), Type: models.LifecycleEventDeviceSwapped, HostID: "nas", DeviceID: "c", PreviousDeviceID: "b"},
+		{CreatedAt: base.Add(4 * time.Hour), Type: models.LifecycleEventDeviceReappeared, HostID: "nas", DeviceID: "b"},
+		{CreatedAt: base.Add(5 * time.Hour), Type: models.LifecycleEventNewDevice, HostID: "backup", DeviceID: "x"},
+	}
+	require.NoError(t, repo.gormClient.Create(&history).Error)
+
+	latest, err := repo.latestDeviceLifecycleEvents(ctx, "nas")
+	require.NoError(t, err)
+	require.Len(t, latest, 3)
+	require.Equal(t, history[2].ID, latest[0].ID)
+	require.Equal(t, history[3].ID, latest[1].ID)
+	require.Equal(t, history[4].ID, latest[2].ID)
+	require.Equal(t, map[string]string{
+		"a": models.LifecycleEventDeviceDisappeared,
+		"b": models.LifecycleEventDeviceReappeared,
+		"c": models.LifecycleEventDeviceSwapped,
+	}, models.DeviceLifecycleStates(latest))
+}
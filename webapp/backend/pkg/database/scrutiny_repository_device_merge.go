@@ -5,11 +5,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
-	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"gorm.io/gorm"
 )
 
@@ -28,14 +25,20 @@ func (sr *scrutinyRepository) MergeDevices(ctx context.Context, sourceDeviceID s
 		return fmt.Errorf("could not find destination device: %w", err)
 	}
 
-	if err := sr.copyInfluxDeviceHistory(ctx, &sourceDevice, &destinationDevice); err != nil {
+	if err := sr.checkMergeDeviceReplacements(ctx, sourceDevice.DeviceID, destinationDevice.DeviceID); err != nil {
 		return err
 	}
 
-	if err := sr.deleteInfluxDeviceHistory(ctx, &sourceDevice); err != nil {
+	if err := sr.timeSeries.CopyDeviceHistory(ctx, sourceDevice.WWN, &destinationDevice); err != nil {
 		return err
 	}
 
+	if sourceDevice.WWN != "" {
+		if err := sr.timeSeries.DeleteSeries(ctx, "device_wwn", sourceDevice.WWN); err != nil {
+			return fmt.Errorf("could not delete source history: %w", err)
+		}
+	}
+
 	return sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if sourceDevice.CreatedAt.Before(destinationDevice.CreatedAt) {
 			if err := tx.Model(&destinationDevice).Update("created_at", sourceDevice.CreatedAt).Error; err != nil {
@@ -43,106 +46,30 @@ func (sr *scrutinyRepository) MergeDevices(ctx context.Context, sourceDeviceID s
 			}
 		}
 
-		if err := tx.Where(queryDeviceID, sourceDevice.DeviceID).Delete(&sourceDevice).Error; err != nil {
-			return fmt.Errorf("could not delete source device: %w", err)
+		if err := replaceDeviceReplacementReferences(tx, sourceDevice.DeviceID, destinationDevice.DeviceID); err != nil {
+			return err
 		}
 
-		return nil
-	})
-}
-
-func (sr *scrutinyRepository) copyInfluxDeviceHistory(ctx context.Context, sourceDevice, destinationDevice *models.Device) error {
-	for _, bucket := range sr.deviceHistoryBuckets() {
-		for _, measurement := range []string{"smart", "temp", "performance"} {
-			points, err := sr.queryDeviceMeasurementPoints(ctx, bucket, measurement, sourceDevice.WWN, destinationDevice)
-			if err != nil {
-				return fmt.Errorf("could not query %s history in bucket %s: %w", measurement, bucket, err)
-			}
-			for i := range points {
-				if err := sr.influxWriteApi.WritePoint(ctx, points[i]); err != nil {
-					return fmt.Errorf("could not write %s history in bucket %s: %w", measurement, bucket, err)
-				}
-			}
+		if err := copyResourceTags(tx, models.TagResourceDevice, sourceDevice.DeviceID, destinationDevice.DeviceID); err != nil {
+			return err
 		}
-	}
-	return nil
-}
-
-func (sr *scrutinyRepository) deleteInfluxDeviceHistory(ctx context.Context, sourceDevice *models.Device) error {
-	if sourceDevice == nil || sourceDevice.WWN == "" {
-		return nil
-	}
-
-	for _, bucket := range sr.deviceHistoryBuckets() {
-		if err := sr.influxClient.DeleteAPI().DeleteWithName(
-			ctx,
-			sr.appConfig.GetString(cfgInfluxDBOrg),
-			bucket,
-			time.Now().AddDate(-10, 0, 0),
-			time.Now().AddDate(10, 0, 0),
-			fmt.Sprintf(`device_wwn=%q`, sourceDevice.WWN),
-		); err != nil {
-			return fmt.Errorf("could not delete source history from bucket %s: %w", bucket, err)
+		if err := tx.Where(queryResourceTag, models.TagResourceDevice, sourceDevice.DeviceID).Delete(&models.ResourceTag{}).Error; err != nil {
+			return fmt.Errorf("could not delete source device tags: %w", err)
 		}
-	}
 
-	return nil
-}
-
-func (sr *scrutinyRepository) deviceHistoryBuckets() []string {
-	buckets := []string{
-		sr.appConfig.GetString(cfgInfluxDBBucket),
-		fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-	}
-	return buckets
-}
-
-func (sr *scrutinyRepository) queryDeviceMeasurementPoints(ctx context.Context, bucket string, measurement string, sourceWWN string, destinationDevice *models.Device) ([]*write.Point, error) {
-	if sourceWWN == "" {
-		return nil, nil
-	}
-
-	queryStr := fmt.Sprintf(`
-from(bucket: "%s")
-|> range(start: -10y, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "%s")
-|> filter(fn: (r) => r["device_wwn"] == "%s")
-|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-|> sort(columns: ["_time"], desc: false)
-`, bucket, measurement, sourceWWN)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
-	if err != nil {
-		return nil, err
-	}
-	defer result.Close()
-
-	points := []*write.Point{}
-	for result.Next() {
-		values := result.Record().Values()
-		fields := measurementFields(measurement, values)
-		if len(fields) == 0 {
-			continue
+		if err := tx.Model(&models.Incident{}).Where(queryDeviceID, sourceDevice.DeviceID).Update("device_id", destinationDevice.DeviceID).Error; err != nil {
+			return fmt.Errorf("could not move source device incidents: %w", err)
 		}
-		tags := measurementTags(measurement, values, destinationDevice)
-		point := influxdb2.NewPoint(
-			measurement,
-			tags,
-			fields,
-			values["_time"].(time.Time),
-		)
-		points = append(points, point)
-	}
 
-	if result.Err() != nil {
-		return nil, result.Err()
-	}
+		if err := tx.Where(queryDeviceID, sourceDevice.DeviceID).Delete(&sourceDevice).Error; err != nil {
+			return fmt.Errorf("could not delete source device: %w", err)
+		}
 
-	return points, nil
+		return nil
+	})
 }
 
+// measurementTags rebuilds the tag set of a copied point for the destination device identity.
 func measurementTags(measurement string, values map[string]interface{}, destinationDevice *models.Device) map[string]string {
 	tags := map[string]string{
 		"device_wwn": destinationDevice.WWN,
@@ -167,6 +94,7 @@ func measurementTags(measurement string, values map[string]interface{}, destinat
 	return tags
 }
 
+// measurementFields extracts the field values of a pivoted point, skipping tags and query metadata.
 func measurementFields(measurement string, values map[string]interface{}) map[string]interface{} {
 	fields := map[string]interface{}{}
 	tagKeys := map[string]bool{
@@ -13,7 +13,6 @@ import (
 	"github.com/analogj/scrutiny/webapp/backend/pkg/deviceid"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/golang/mock/gomock"
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -47,6 +46,7 @@ func TestMergeDevices_Integration(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.token").Return("my-super-secret-auth-token").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
+	fakeConfig.EXPECT().GetString("web.timeseries.backend").Return("influxdb").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.init_username").Return("admin").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.init_password").Return("password12345").AnyTimes()
 	fakeConfig.EXPECT().GetBool("web.influxdb.tls.insecure_skip_verify").Return(false).AnyTimes()
@@ -86,7 +86,7 @@ func TestMergeDevices_Integration(t *testing.T) {
 	olderCreatedAt := time.Now().Add(-48 * time.Hour)
 	require.NoError(t, repo.gormClient.Model(&models.Device{}).Where(queryDeviceID, sourceDevice.DeviceID).Update("created_at", olderCreatedAt).Error)
 
-	tempPoint := influxdb2.NewPoint(
+	require.NoError(t, repo.timeSeries.WritePoint(ctx,
 		"temp",
 		map[string]string{
 			"device_wwn": sourceDevice.WWN,
@@ -96,8 +96,7 @@ func TestMergeDevices_Integration(t *testing.T) {
 			"temp": int64(42),
 		},
 		time.Now().Add(-1*time.Hour),
-	)
-	require.NoError(t, repo.influxWriteApi.WritePoint(ctx, tempPoint))
+	))
 
 	require.NoError(t, repo.MergeDevices(ctx, sourceDevice.DeviceID, destinationDevice.DeviceID))
 
@@ -20,7 +20,7 @@ func createDeviceRegisterTestRepository(t *testing.T) *scrutinyRepository {
 
 	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, db.AutoMigrate(&models.Device{}))
+	require.NoError(t, db.AutoMigrate(&models.Device{}, &models.ResourceTag{}, &models.Incident{}))
 
 	return &scrutinyRepository{
 		gormClient: db,
@@ -0,0 +1,355 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrDeviceReplacementNotFound is returned when a replacement record does not exist.
+var ErrDeviceReplacementNotFound = errors.New("device replacement not found")
+
+// RecordDeviceReplacement records that replacement.OldDeviceID was succeeded by
+// replacement.NewDeviceID. The old device is archived, and its slot (inventory location) and label
+// carry over to the new device when the new device has none of its own; its tags are added to the
+// new device's. When no slot or pool memberships are given they are taken from the old device.
+func (sr *scrutinyRepository) RecordDeviceReplacement(ctx context.Context, replacement models.DeviceReplacement) (models.DeviceReplacement, error) {
+	if !replacement.Reason.Valid() {
+		return replacement, fmt.Errorf("invalid replacement reason %q", replacement.Reason)
+	}
+	if replacement.OldDeviceID == replacement.NewDeviceID {
+		return replacement, fmt.Errorf("a device cannot replace itself")
+	}
+
+	var oldDevice, newDevice models.Device
+	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, replacement.OldDeviceID).First(&oldDevice).Error; err != nil {
+		return replacement, fmt.Errorf("could not find replaced device: %w", err)
+	}
+	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, replacement.NewDeviceID).First(&newDevice).Error; err != nil {
+		return replacement, fmt.Errorf("could not find replacement device: %w", err)
+	}
+
+	existing, err := sr.getDeviceReplacements(ctx)
+	if err != nil {
+		return replacement, err
+	}
+	for _, other := range existing {
+		if other.OldDeviceID == oldDevice.DeviceID {
+			return replacement, fmt.Errorf("device %s was already replaced by %s", oldDevice.DeviceID, other.NewDeviceID)
+		}
+		if other.NewDeviceID == newDevice.DeviceID {
+			return replacement, fmt.Errorf("device %s already replaced %s", newDevice.DeviceID, other.OldDeviceID)
+		}
+	}
+	// the new device must not be an ancestor of the old one, or the lineage would loop
+	byNew := replacementsByNewDevice(existing)
+	for current := oldDevice.DeviceID; ; {
+		previous, ok := byNew[current]
+		if !ok {
+			break
+		}
+		if previous.OldDeviceID == newDevice.DeviceID {
+			return replacement, fmt.Errorf("device %s is a predecessor of %s", newDevice.DeviceID, oldDevice.DeviceID)
+		}
+		current = previous.OldDeviceID
+	}
+
+	if replacement.ReplacedAt.IsZero() {
+		replacement.ReplacedAt = time.Now().UTC()
+	}
+	replacement.HostId = oldDevice.HostId
+	replacement.Slot = strings.TrimSpace(replacement.Slot)
+	if replacement.Slot == "" {
+		replacement.Slot = oldDevice.Location
+	}
+	if len(replacement.PoolMemberships) == 0 {
+		memberships, err := sr.devicePoolMemberships(ctx, oldDevice)
+		if err != nil {
+			return replacement, err
+		}
+		replacement.PoolMemberships = memberships
+	}
+	replacement.ReplacedModelName = oldDevice.ModelName
+	replacement.ReplacedSerialNumber = oldDevice.SerialNumber
+	replacement.ID = 0
+
+	err = sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&replacement).Error; err != nil {
+			return fmt.Errorf("could not record device replacement: %w", err)
+		}
+		if err := tx.Model(&models.Device{}).Where(queryDeviceID, oldDevice.DeviceID).Update("archived", true).Error; err != nil {
+			return fmt.Errorf("could not archive replaced device: %w", err)
+		}
+
+		carryOver := map[string]interface{}{}
+		if newDevice.Location == "" && replacement.Slot != "" {
+			carryOver["location"] = replacement.Slot
+		}
+		if newDevice.Label == "" && oldDevice.Label != "" {
+			carryOver["label"] = oldDevice.Label
+		}
+		if len(carryOver) > 0 {
+			if err := tx.Model(&models.Device{}).Where(queryDeviceID, newDevice.DeviceID).Updates(carryOver).Error; err != nil {
+				return fmt.Errorf("could not update replacement device: %w", err)
+			}
+		}
+		return copyResourceTags(tx, models.TagResourceDevice, oldDevice.DeviceID, newDevice.DeviceID)
+	})
+	return replacement, err
+}
+
+// DeleteDeviceReplacement removes a replacement record. The replaced device stays archived.
+func (sr *scrutinyRepository) DeleteDeviceReplacement(ctx context.Context, id uint) error {
+	result := sr.gormClient.WithContext(ctx).Delete(&models.DeviceReplacement{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceReplacementNotFound
+	}
+	return nil
+}
+
+// GetDeviceReplacements returns the replacement records matching filter, oldest first.
+func (sr *scrutinyRepository) GetDeviceReplacements(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceReplacement, error) {
+	replacements, err := sr.getDeviceReplacements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched := []models.DeviceReplacement{}
+	for i := range replacements {
+		if filter.Matches(&replacements[i]) {
+			matched = append(matched, replacements[i])
+		}
+	}
+	return matched, nil
+}
+
+// GetDeviceLineage returns the full chain of predecessors and successors of a device.
+func (sr *scrutinyRepository) GetDeviceLineage(ctx context.Context, deviceID string) (models.DeviceLineage, error) {
+	var device models.Device
+	if err := sr.gormClient.WithContext(ctx).Where(queryDeviceID, deviceID).First(&device).Error; err != nil {
+		return models.DeviceLineage{}, fmt.Errorf(errDeviceNotFound, err)
+	}
+	replacements, err := sr.getDeviceReplacements(ctx)
+	if err != nil {
+		return models.DeviceLineage{}, err
+	}
+	lineages, err := sr.buildLineages(ctx, replacements, []string{deviceID})
+	if err != nil {
+		return models.DeviceLineage{}, err
+	}
+	return lineages[0], nil
+}
+
+// GetDeviceLineages returns the lineage of every bay or pool member matching filter. For a slot
+// lookup, devices currently located in the slot are included even if they were never replaced.
+func (sr *scrutinyRepository) GetDeviceLineages(ctx context.Context, filter models.ReplacementFilter) ([]models.DeviceLineage, error) {
+	replacements, err := sr.getDeviceReplacements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceIDs := []string{}
+	for i := range replacements {
+		if filter.Matches(&replacements[i]) {
+			deviceIDs = append(deviceIDs, replacements[i].NewDeviceID)
+		}
+	}
+	if filter.Slot != "" {
+		query := sr.gormClient.WithContext(ctx).Model(&models.Device{}).Where("LOWER(location) = ?", strings.ToLower(filter.Slot))
+		if filter.HostId != "" {
+			query = query.Where("host_id = ?", filter.HostId)
+		}
+		var slotDeviceIDs []string
+		if err := query.Pluck("device_id", &slotDeviceIDs).Error; err != nil {
+			return nil, fmt.Errorf("could not get devices in slot: %w", err)
+		}
+		deviceIDs = append(deviceIDs, slotDeviceIDs...)
+	}
+
+	return sr.buildLineages(ctx, replacements, deviceIDs)
+}
+
+func (sr *scrutinyRepository) getDeviceReplacements(ctx context.Context) ([]models.DeviceReplacement, error) {
+	replacements := []models.DeviceReplacement{}
+	if err := sr.gormClient.WithContext(ctx).Order("replaced_at ASC, id ASC").Find(&replacements).Error; err != nil {
+		return nil, fmt.Errorf("could not get device replacements from DB: %w", err)
+	}
+	return replacements, nil
+}
+
+func replacementsByNewDevice(replacements []models.DeviceReplacement) map[string]models.DeviceReplacement {
+	byNew := make(map[string]models.DeviceReplacement, len(replacements))
+	for _, replacement := range replacements {
+		byNew[replacement.NewDeviceID] = replacement
+	}
+	return byNew
+}
+
+// buildLineages returns one lineage per distinct chain containing any of deviceIDs, in the order
+// the chains are first referenced.
+func (sr *scrutinyRepository) buildLineages(ctx context.Context, replacements []models.DeviceReplacement, deviceIDs []string) ([]models.DeviceLineage, error) {
+	byNew := replacementsByNewDevice(replacements)
+	byOld := make(map[string]models.DeviceReplacement, len(replacements))
+	for _, replacement := range replacements {
+		byOld[replacement.OldDeviceID] = replacement
+	}
+
+	chains := [][]string{}
+	chainReplacements := [][]models.DeviceReplacement{}
+	seenRoots := map[string]bool{}
+	for _, deviceID := range deviceIDs {
+		root := deviceID
+		for visited := map[string]bool{root: true}; ; {
+			previous, ok := byNew[root]
+			if !ok || visited[previous.OldDeviceID] {
+				break
+			}
+			root = previous.OldDeviceID
+			visited[root] = true
+		}
+		if seenRoots[root] {
+			continue
+		}
+		seenRoots[root] = true
+
+		chain := []string{root}
+		links := []models.DeviceReplacement{}
+		inChain := map[string]bool{root: true}
+		for current := root; ; {
+			next, ok := byOld[current]
+			if !ok || inChain[next.NewDeviceID] {
+				break
+			}
+			inChain[next.NewDeviceID] = true
+			links = append(links, next)
+			chain = append(chain, next.NewDeviceID)
+			current = next.NewDeviceID
+		}
+		chains = append(chains, chain)
+		chainReplacements = append(chainReplacements, links)
+	}
+
+	allIDs := []string{}
+	for _, chain := range chains {
+		allIDs = append(allIDs, chain...)
+	}
+	devices := []models.Device{}
+	if len(allIDs) > 0 {
+		if err := sr.gormClient.WithContext(ctx).Where("device_id IN ?", allIDs).Find(&devices).Error; err != nil {
+			return nil, fmt.Errorf("could not get lineage devices from DB: %w", err)
+		}
+	}
+	devicesByID := make(map[string]models.Device, len(devices))
+	for _, device := range devices {
+		devicesByID[device.DeviceID] = device
+	}
+
+	lineages := make([]models.DeviceLineage, 0, len(chains))
+	for i, chain := range chains {
+		lineage := models.DeviceLineage{Devices: []models.Device{}, Replacements: chainReplacements[i]}
+		for _, deviceID := range chain {
+			if device, ok := devicesByID[deviceID]; ok {
+				lineage.Devices = append(lineage.Devices, device)
+			}
+		}
+		lineages = append(lineages, lineage)
+	}
+	return lineages, nil
+}
+
+// devicePoolMemberships finds the ZFS pools, mdadm arrays and Btrfs filesystems on the device's
+// host that list the device (or one of its partitions) as a member.
+func (sr *scrutinyRepository) devicePoolMemberships(ctx context.Context, device models.Device) ([]models.PoolMembership, error) {
+	memberships := []models.PoolMembership{}
+	if device.DeviceName == "" {
+		return memberships, nil
+	}
+
+	pools := []models.ZFSPool{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id = ?", device.HostId).Find(&pools).Error; err != nil {
+		return nil, fmt.Errorf("could not get ZFS pools from DB: %w", err)
+	}
+	for _, pool := range pools {
+		vdevs := []models.ZFSVdev{}
+		if err := sr.gormClient.WithContext(ctx).Where("pool_guid = ? AND path <> ''", pool.GUID).Find(&vdevs).Error; err != nil {
+			return nil, fmt.Errorf("could not get ZFS vdevs from DB: %w", err)
+		}
+		for _, vdev := range vdevs {
+			if models.PoolMemberMatchesDevice(vdev.Path, device.DeviceName) {
+				memberships = append(memberships, models.PoolMembership{Type: models.PoolTypeZFS, ID: pool.GUID, Name: pool.Name, Member: vdev.Path})
+			}
+		}
+	}
+
+	arrays := []models.MDADMArray{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id = ?", device.HostId).Find(&arrays).Error; err != nil {
+		return nil, fmt.Errorf("could not get MDADM arrays from DB: %w", err)
+	}
+	for _, array := range arrays {
+		for _, member := range array.Devices {
+			if models.PoolMemberMatchesDevice(member, device.DeviceName) {
+				memberships = append(memberships, models.PoolMembership{Type: models.PoolTypeMdadm, ID: array.UUID, Name: array.Name, Member: member})
+			}
+		}
+	}
+
+	filesystems := []models.BtrfsFilesystem{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id = ?", device.HostId).Find(&filesystems).Error; err != nil {
+		return nil, fmt.Errorf("could not get Btrfs filesystems from DB: %w", err)
+	}
+	for _, filesystem := range filesystems {
+		btrfsDevices := []models.BtrfsDevice{}
+		if err := sr.gormClient.WithContext(ctx).Where(queryBtrfsFilesystemUUID, filesystem.UUID).Find(&btrfsDevices).Error; err != nil {
+			return nil, fmt.Errorf("could not get Btrfs devices from DB: %w", err)
+		}
+		for _, btrfsDevice := range btrfsDevices {
+			if models.PoolMemberMatchesDevice(btrfsDevice.Path, device.DeviceName) {
+				memberships = append(memberships, models.PoolMembership{Type: models.PoolTypeBtrfs, ID: filesystem.UUID, Name: filesystem.Label, Member: btrfsDevice.Path})
+			}
+		}
+	}
+
+	return memberships, nil
+}
+
+// checkMergeDeviceReplacements rejects merging two devices whose replacement records would collide,
+// such as a device and its own successor.
+func (sr *scrutinyRepository) checkMergeDeviceReplacements(ctx context.Context, sourceDeviceID string, destinationDeviceID string) error {
+	replacements, err := sr.getDeviceReplacements(ctx)
+	if err != nil {
+		return err
+	}
+	replaced := map[string]bool{}
+	replacing := map[string]bool{}
+	for _, replacement := range replacements {
+		if (replacement.OldDeviceID == sourceDeviceID && replacement.NewDeviceID == destinationDeviceID) ||
+			(replacement.OldDeviceID == destinationDeviceID && replacement.NewDeviceID == sourceDeviceID) {
+			return fmt.Errorf("cannot merge device %s into %s: one replaced the other", sourceDeviceID, destinationDeviceID)
+		}
+		replaced[replacement.OldDeviceID] = true
+		replacing[replacement.NewDeviceID] = true
+	}
+	if (replaced[sourceDeviceID] && replaced[destinationDeviceID]) || (replacing[sourceDeviceID] && replacing[destinationDeviceID]) {
+		return fmt.Errorf("cannot merge device %s into %s: both have conflicting replacement records", sourceDeviceID, destinationDeviceID)
+	}
+	return nil
+}
+
+// replaceDeviceReplacementReferences points the replacement records of sourceDeviceID at
+// destinationDeviceID when two devices are merged.
+func replaceDeviceReplacementReferences(tx *gorm.DB, sourceDeviceID string, destinationDeviceID string) error {
+	if err := tx.Model(&models.DeviceReplacement{}).Where("old_device_id = ?", sourceDeviceID).Update("old_device_id", destinationDeviceID).Error; err != nil {
+		return fmt.Errorf("could not update device replacements: %w", err)
+	}
+	if err := tx.Model(&models.DeviceReplacement{}).Where("new_device_id = ?", sourceDeviceID).Update("new_device_id", destinationDeviceID).Error; err != nil {
+		return fmt.Errorf("could not update device replacements: %w", err)
+	}
+	return nil
+}
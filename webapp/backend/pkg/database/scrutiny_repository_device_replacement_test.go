@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func createDeviceReplacementTestRepository(t *testing.T) *scrutinyRepository {
+	t.Helper()
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(
+		&models.DeviceReplacement{},
+		&models.ZFSPool{},
+		&models.ZFSVdev{},
+		&models.MDADMArray{},
+		&models.BtrfsFilesystem{},
+		&models.BtrfsDevice{},
+	))
+	return repo
+}
+
+func TestRecordDeviceReplacement_CarriesOverSlotAndPoolMembership(t *testing.T) {
+	repo := createDeviceReplacementTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "old", DeviceName: "sdc", HostId: "nas", ModelName: "WDC WD40EFRX", SerialNumber: "WD-1", Label: "parity", DeviceInventory: models.DeviceInventory{Location: "Bay 3"}}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "new", DeviceName: "sdc", HostId: "nas"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.MDADMArray{UUID: "md-uuid", Name: "md0", HostID: "nas", Devices: []string{"/dev/sdb1", "/dev/sdc1"}}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.MDADMArray{UUID: "other-host", Name: "md0", HostID: "backup", Devices: []string{"/dev/sdc1"}}).Error)
+
+	replacedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	replacement, err := repo.RecordDeviceReplacement(ctx, models.DeviceReplacement{OldDeviceID: "old", NewDeviceID: "new", Reason: models.ReplacementReasonFailed, ReplacedAt: replacedAt})
+	require.NoError(t, err)
+	require.NotZero(t, replacement.ID)
+	require.Equal(t, "Bay 3", replacement.Slot)
+	require.Equal(t, "nas", replacement.HostId)
+	require.Equal(t, "WDC WD40EFRX", replacement.ReplacedModelName)
+	require.Equal(t, []models.PoolMembership{{Type: models.PoolTypeMdadm, ID: "md-uuid", Name: "md0", Member: "/dev/sdc1"}}, replacement.PoolMemberships)
+
+	var oldDevice, newDevice models.Device
+	require.NoError(t, repo.gormClient.Where(queryDeviceID, "old").First(&oldDevice).Error)
+	require.NoError(t, repo.gormClient.Where(queryDeviceID, "new").First(&newDevice).Error)
+	require.True(t, oldDevice.Archived)
+	require.Equal(t, "Bay 3", newDevice.Location)
+	require.Equal(t, "parity", newDevice.Label)
+
+	// a device can only be replaced once
+	_, err = repo.RecordDeviceReplacement(ctx, models.DeviceReplacement{OldDeviceID: "old", NewDeviceID: "new", Reason: models.ReplacementReasonRMA})
+	require.Error(t, err)
+
+	lineages, err := repo.GetDeviceLineages(ctx, models.ReplacementFilter{PoolType: models.PoolTypeMdadm, PoolID: "md-uuid", PoolMember: "sdc1"})
+	require.NoError(t, err)
+	require.Len(t, lineages, 1)
+	require.Len(t, lineages[0].Devices, 2)
+}
+
+func TestGetDeviceLineage_FollowsChainInBothDirections(t *testing.T) {
+	repo := createDeviceReplacementTestRepository(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"gen1", "gen2", "gen3", "spare"} {
+		require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: id, HostId: "nas"}).Error)
+	}
+	_, err := repo.RecordDeviceReplacement(ctx, models.DeviceReplacement{OldDeviceID: "gen1", NewDeviceID: "gen2", Reason: models.ReplacementReasonFailed, Slot: "Bay 1", ReplacedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	_, err = repo.RecordDeviceReplacement(ctx, models.DeviceReplacement{OldDeviceID: "gen2", NewDeviceID: "gen3", Reason: models.ReplacementReasonUpgraded, ReplacedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	// gen1 cannot succeed its own descendant
+	_, err = repo.RecordDeviceReplacement(ctx, models.DeviceReplacement{OldDeviceID: "gen3", NewDeviceID: "gen1", Reason: models.ReplacementReasonFailed})
+	require.Error(t, err)
+
+	lineage, err := repo.GetDeviceLineage(ctx, "gen2")
+	require.NoError(t, err)
+	deviceIDs := []string{}
+	for _, device := range lineage.Devices {
+		deviceIDs = append(deviceIDs, device.DeviceID)
+	}
+	require.Equal(t, []string{"gen1", "gen2", "gen3"}, deviceIDs)
+	require.Len(t, lineage.Replacements, 2)
+
+	// the slot carried over from gen2 to gen3, so the bay lineage finds the whole chain once
+	lineages, err := repo.GetDeviceLineages(ctx, models.ReplacementFilter{Slot: "bay 1"})
+	require.NoError(t, err)
+	require.Len(t, lineages, 1)
+	require.Len(t, lineages[0].Devices, 3)
+
+	// merging a device into its own successor would collapse the chain
+	require.Error(t, repo.checkMergeDeviceReplacements(ctx, "gen2", "gen3"))
+	require.NoError(t, repo.checkMergeDeviceReplacements(ctx, "spare", "gen3"))
+
+	replacements, err := repo.GetDeviceReplacements(ctx, models.ReplacementFilter{})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteDeviceReplacement(ctx, replacements[1].ID))
+	require.ErrorIs(t, repo.DeleteDeviceReplacement(ctx, replacements[1].ID), ErrDeviceReplacementNotFound)
+	lineage, err = repo.GetDeviceLineage(ctx, "gen3")
+	require.NoError(t, err)
+	require.Len(t, lineage.Devices, 1)
+}
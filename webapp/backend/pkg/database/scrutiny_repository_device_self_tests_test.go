@@ -69,14 +69,18 @@ func createDeviceSelfTestRepository(t *testing.T) *scrutinyRepository {
 
 	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, db.AutoMigrate(&models.Device{}, &models.DeviceSelfTest{}, &models.AttributeOverride{}))
+	require.NoError(t, db.AutoMigrate(&models.Device{}, &models.DeviceSelfTest{}, &models.AttributeOverride{}, &models.ResourceTag{}, &models.Incident{}))
 
 	return &scrutinyRepository{
-		appConfig:      fakeConfig,
-		gormClient:     db,
-		logger:         logrus.New(),
-		influxWriteApi: &stubWriteAPI{},
-		influxQueryApi: &stubQueryAPI{},
+		appConfig:  fakeConfig,
+		gormClient: db,
+		logger:     logrus.New(),
+		timeSeries: &influxTimeSeriesStore{
+			appConfig:      fakeConfig,
+			logger:         logrus.New(),
+			influxWriteApi: &stubWriteAPI{},
+			influxQueryApi: &stubQueryAPI{},
+		},
 	}
 }
 
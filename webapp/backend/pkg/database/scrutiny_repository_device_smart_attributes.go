@@ -2,14 +2,10 @@ package database
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
-	"github.com/influxdata/influxdb-client-go/v2/api"
 )
 
 // //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -36,7 +32,7 @@ func (sr *scrutinyRepository) SaveSmartAttributes(ctx context.Context, wwn strin
 
 	// Apply delta-based evaluation for cumulative counter attributes (e.g., UltraDMA CRC Error Count).
 	// Fetch the most recent existing SMART submission to compare values. Uses GetLatestSmartSubmission
-	// (offset=0) because this is called BEFORE the current data is written to InfluxDB.
+	// (offset=0) because this is called BEFORE the current data is written to the time-series backend.
 	// If a cumulative counter hasn't increased, suppress the warning since the underlying issue
 	// may have been resolved.
 	previousSmartData, prevErr := sr.GetLatestSmartSubmission(ctx, wwn)
@@ -62,7 +58,18 @@ func (sr *scrutinyRepository) SaveSmartAttributes(ctx context.Context, wwn strin
 	}
 
 	// write point immediately
-	return deviceSmartData, sr.saveDatapoint(sr.influxWriteApi, "smart", tags, fields, deviceSmartData.Date, ctx)
+	if err := sr.timeSeries.WritePoint(ctx, "smart", tags, fields, deviceSmartData.Date); err != nil {
+		return deviceSmartData, err
+	}
+
+	if forwarder := currentSmartForwarder(); forwarder != nil {
+		var forwardDevice *models.Device
+		if devErr == nil {
+			forwardDevice = &device
+		}
+		forwarder.Forward(smartForwardTags(tags, forwardDevice), fields, deviceSmartData.Date)
+	}
+	return deviceSmartData, nil
 }
 
 // extractPreviousRawValues extracts raw values from a previous SMART submission into a map
@@ -82,32 +89,19 @@ func extractPreviousRawValues(previousSmart *measurements.Smart) map[string]int6
 // For example, with selectEntries = 5, selectEntries = 0, the most recent 5 are returned. With selectEntries = 3, selectEntries = 2, entries
 // 2 to 4 are returned (2 being the third newest, since it is zero-indexed)
 func (sr *scrutinyRepository) GetSmartAttributeHistory(ctx context.Context, wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) ([]measurements.Smart, error) {
-	// Get SMartResults from InfluxDB
-
-	// Get parser flux query result
-	// Note: WWN is validated at the handler level before reaching this function
-	queryStr := sr.aggregateSmartAttributesQuery(wwn, durationKey, selectEntries, selectEntriesOffset, attributes)
-	sr.logger.Infoln(queryStr)
+	// Get SMartResults from the time-series backend
+	rows, err := sr.timeSeries.QuerySmartHistory(ctx, wwn, durationKey, selectEntries, selectEntriesOffset, attributes)
+	if err != nil {
+		return nil, err
+	}
 
 	smartResults := []measurements.Smart{}
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
-	if err == nil {
-		defer result.Close()
-		// Use Next() to iterate over query result lines
-		for result.Next() {
-			smartData, err := measurements.NewSmartFromInfluxDB(result.Record().Values(), sr.logger)
-			if err != nil {
-				return nil, err
-			}
-			smartResults = append(smartResults, *smartData)
-
-		}
-		if result.Err() != nil {
-			sr.logger.Errorf("Query error: %s", result.Err().Error())
+	for _, values := range rows {
+		smartData, err := measurements.NewSmartFromInfluxDB(values, sr.logger)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		return nil, err
+		smartResults = append(smartResults, *smartData)
 	}
 
 	return smartResults, nil
@@ -126,6 +120,18 @@ func (sr *scrutinyRepository) GetSmartAttributeHistory(ctx context.Context, wwn
 
 }
 
+// StreamSmartHistory passes each raw SMART submission within durationKey to fn, oldest first.
+// Note: WWN is validated at the handler level before reaching this function.
+func (sr *scrutinyRepository) StreamSmartHistory(ctx context.Context, wwn string, durationKey string, fn func(smart measurements.Smart) error) error {
+	return sr.timeSeries.StreamDeviceSeries(ctx, "smart", wwn, durationKey, func(values timeSeriesRow) error {
+		smartData, err := measurements.NewSmartFromInfluxDB(values, sr.logger)
+		if err != nil {
+			return err
+		}
+		return fn(*smartData)
+	})
+}
+
 // GetPreviousSmartSubmission returns the previous raw SMART submission without daily aggregation.
 // This is used for repeat notification detection to compare against the actual previous submission,
 // not the previous day's aggregated value.
@@ -134,210 +140,30 @@ func (sr *scrutinyRepository) GetSmartAttributeHistory(ctx context.Context, wwn
 func (sr *scrutinyRepository) GetPreviousSmartSubmission(ctx context.Context, wwn string) ([]measurements.Smart, error) {
 	// Query raw data from the metrics bucket (last week) without aggregation
 	// Use offset=1 to skip the most recent entry (which is the one just saved)
-	queryStr := fmt.Sprintf(`
-import "influxdata/influxdb/schema"
-from(bucket: "%s")
-|> range(start: -1w, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["device_wwn"] == "%s")
-|> schema.fieldsAsCols()
-|> group()
-|> sort(columns: ["_time"], desc: true)
-|> limit(n: 1, offset: 1)
-`, sr.appConfig.GetString(cfgInfluxDBBucket), wwn)
-
-	sr.logger.Debugln("GetPreviousSmartSubmission query:", queryStr)
-
-	smartResults := []measurements.Smart{}
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
-	if err != nil {
-		return nil, err
-	}
-	defer result.Close()
-
-	for result.Next() {
-		smartData, err := measurements.NewSmartFromInfluxDB(result.Record().Values(), sr.logger)
-		if err != nil {
-			return nil, err
-		}
-		smartResults = append(smartResults, *smartData)
-	}
-
-	if result.Err() != nil {
-		return nil, result.Err()
-	}
-
-	return smartResults, nil
+	return sr.querySmartSubmission(ctx, wwn, 1)
 }
 
 // GetLatestSmartSubmission returns the most recent raw SMART submission without daily aggregation.
-// This is used for delta evaluation BEFORE writing the current data to InfluxDB, so offset=0
+// This is used for delta evaluation BEFORE writing the current data to the time-series backend, so offset=0
 // returns the actual most recent existing entry (which is the previous submission).
 // Note: WWN is validated at the handler level before reaching this function.
 func (sr *scrutinyRepository) GetLatestSmartSubmission(ctx context.Context, wwn string) ([]measurements.Smart, error) {
-	queryStr := fmt.Sprintf(`
-import "influxdata/influxdb/schema"
-from(bucket: "%s")
-|> range(start: -1w, stop: now())
-|> filter(fn: (r) => r["_measurement"] == "smart")
-|> filter(fn: (r) => r["device_wwn"] == "%s")
-|> schema.fieldsAsCols()
-|> group()
-|> sort(columns: ["_time"], desc: true)
-|> limit(n: 1, offset: 0)
-`, sr.appConfig.GetString(cfgInfluxDBBucket), wwn)
-
-	sr.logger.Debugln("GetLatestSmartSubmission query:", queryStr)
-
-	smartResults := []measurements.Smart{}
+	return sr.querySmartSubmission(ctx, wwn, 0)
+}
 
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+func (sr *scrutinyRepository) querySmartSubmission(ctx context.Context, wwn string, offset int) ([]measurements.Smart, error) {
+	rows, err := sr.timeSeries.QuerySmartSubmission(ctx, wwn, offset)
 	if err != nil {
 		return nil, err
 	}
-	defer result.Close()
 
-	for result.Next() {
-		smartData, err := measurements.NewSmartFromInfluxDB(result.Record().Values(), sr.logger)
+	smartResults := []measurements.Smart{}
+	for _, values := range rows {
+		smartData, err := measurements.NewSmartFromInfluxDB(values, sr.logger)
 		if err != nil {
 			return nil, err
 		}
 		smartResults = append(smartResults, *smartData)
 	}
-
-	if result.Err() != nil {
-		return nil, result.Err()
-	}
-
 	return smartResults, nil
 }
-
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Helper Methods
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-
-func (sr *scrutinyRepository) saveDatapoint(influxWriteApi api.WriteAPIBlocking, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time, ctx context.Context) error {
-	//sr.logger.Debugf("Storing datapoint in measurement '%s'. tags: %d fields: %d", measurement, len(tags), len(fields))
-	p := influxdb2.NewPoint(measurement,
-		tags,
-		fields,
-		date)
-
-	// write point immediately
-	return influxWriteApi.WritePoint(ctx, p)
-}
-
-func (sr *scrutinyRepository) aggregateSmartAttributesQuery(wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) string {
-
-	/*
-
-		import "influxdata/influxdb/schema"
-		weekData = from(bucket: "metrics")
-		|> range(start: -1w, stop: now())
-		|> filter(fn: (r) => r["_measurement"] == "smart" )
-		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
-		|> tail(n: 10, offset: 0)
-		|> schema.fieldsAsCols()
-
-		monthData = from(bucket: "metrics_weekly")
-		|> range(start: -1mo, stop: -1w)
-		|> filter(fn: (r) => r["_measurement"] == "smart" )
-		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
-		|> tail(n: 10, offset: 0)
-		|> schema.fieldsAsCols()
-
-		yearData = from(bucket: "metrics_monthly")
-		|> range(start: -1y, stop: -1mo)
-		|> filter(fn: (r) => r["_measurement"] == "smart" )
-		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
-		|> tail(n: 10, offset: 0)
-		|> schema.fieldsAsCols()
-
-		foreverData = from(bucket: "metrics_yearly")
-		|> range(start: -10y, stop: -1y)
-		|> filter(fn: (r) => r["_measurement"] == "smart" )
-		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
-		|> tail(n: 10, offset: 0)
-		|> schema.fieldsAsCols()
-
-		union(tables: [weekData, monthData, yearData, foreverData])
-		|> group()
-		|> sort(columns: ["_time"], desc: true)
-		|> tail(n: 6, offset: 4)
-		|> yield(name: "last")
-
-	*/
-
-	partialQueryStr := []string{
-		`import "influxdata/influxdb/schema"`,
-	}
-
-	nestedDurationKeys := sr.lookupNestedDurationKeys(durationKey)
-
-	if len(nestedDurationKeys) == 1 {
-		//there's only one bucket being queried, no need to union, just aggregate the dataset and return
-		subqueryParts := []string{
-			sr.generateSmartAttributesSubquery(wwn, nestedDurationKeys[0], 0, 0, attributes),
-			fmt.Sprintf(`%sData`, nestedDurationKeys[0]),
-			`|> sort(columns: ["_time"], desc: true)`,
-		}
-		if selectEntries > 0 {
-			// Use limit() instead of tail() after desc sort to get the newest entries
-			subqueryParts = append(subqueryParts, fmt.Sprintf(`|> limit(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
-		}
-		subqueryParts = append(subqueryParts, `|> yield()`)
-		partialQueryStr = append(partialQueryStr, subqueryParts...)
-		return strings.Join(partialQueryStr, "\n")
-	}
-
-	subQueries := []string{}
-	subQueryNames := []string{}
-	for _, nestedDurationKey := range nestedDurationKeys {
-		subQueryNames = append(subQueryNames, fmt.Sprintf(`%sData`, nestedDurationKey))
-		if selectEntries > 0 {
-			// We only need the last `n + offset` # of entries from each table to guarantee we can
-			// get the last `n` # of entries starting from `offset` of the union
-			subQueries = append(subQueries, sr.generateSmartAttributesSubquery(wwn, nestedDurationKey, selectEntries+selectEntriesOffset, 0, attributes))
-		} else {
-			subQueries = append(subQueries, sr.generateSmartAttributesSubquery(wwn, nestedDurationKey, 0, 0, attributes))
-		}
-	}
-	partialQueryStr = append(partialQueryStr, subQueries...)
-	partialQueryStr = append(partialQueryStr, []string{
-		fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", ")),
-		`|> group()`,
-		`|> sort(columns: ["_time"], desc: true)`,
-	}...)
-	if selectEntries > 0 {
-		// Use limit() instead of tail() after desc sort to get the newest entries
-		// tail() would get the oldest entries from the end, but we want the newest from the beginning
-		partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> limit(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
-	}
-	partialQueryStr = append(partialQueryStr, `|> yield(name: "last")`)
-
-	return strings.Join(partialQueryStr, "\n")
-}
-
-// generateSmartAttributesSubquery generates a subquery for SMART attributes.
-// Note: WWN is validated at the handler level before reaching this function.
-func (sr *scrutinyRepository) generateSmartAttributesSubquery(wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) string {
-	bucketName := sr.lookupBucketName(durationKey)
-	durationRange := sr.lookupDuration(durationKey)
-
-	partialQueryStr := []string{
-		fmt.Sprintf(`%sData = from(bucket: "%s")`, durationKey, bucketName),
-		fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
-		`|> filter(fn: (r) => r["_measurement"] == "smart" )`,
-		fmt.Sprintf(`|> filter(fn: (r) => r["device_wwn"] == "%s" )`, wwn),
-	}
-
-	partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> aggregateWindow(every: %s, fn: last, createEmpty: false)`, RESOLUTION_1_DAY))
-
-	if selectEntries > 0 {
-		partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> tail(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
-	}
-	partialQueryStr = append(partialQueryStr, "|> schema.fieldsAsCols()")
-
-	return strings.Join(partialQueryStr, "\n")
-}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	// Supported values for the web.database.type config key
+	DATABASE_TYPE_SQLITE   = "sqlite"
+	DATABASE_TYPE_POSTGRES = "postgres"
+)
+
+// databaseType returns the normalized web.database.type value, defaulting to sqlite.
+func databaseType(appConfig config.Interface) (string, error) {
+	dbType := strings.ToLower(strings.TrimSpace(appConfig.GetString(cfgDatabaseType)))
+	switch dbType {
+	case "", DATABASE_TYPE_SQLITE:
+		return DATABASE_TYPE_SQLITE, nil
+	case DATABASE_TYPE_POSTGRES, "postgresql":
+		return DATABASE_TYPE_POSTGRES, nil
+	default:
+		return "", fmt.Errorf("unsupported web.database.type %q (expected %q or %q)", dbType, DATABASE_TYPE_SQLITE, DATABASE_TYPE_POSTGRES)
+	}
+}
+
+// newGormDialector builds the GORM dialector for the configured metadata store, along with a
+// description of the target that is safe to log (no credentials).
+func newGormDialector(appConfig config.Interface) (gorm.Dialector, string, error) {
+	dbType, err := databaseType(appConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if dbType == DATABASE_TYPE_POSTGRES {
+		dsn := appConfig.GetString(cfgDatabaseDSN)
+		if dsn == "" {
+			return nil, "", fmt.Errorf("web.database.dsn is required when web.database.type is %q", DATABASE_TYPE_POSTGRES)
+		}
+		return postgres.Open(dsn), "postgres db: " + redactDSN(dsn), nil
+	}
+
+	location := appConfig.GetString(cfgDatabaseLocation)
+	return sqlite.Open(location + sqlitePragmaString(sqlitePragmas(appConfig))), "sqlite db: " + location, nil
+}
+
+// sqlitePragmas returns the connection pragmas for the SQLite metadata store.
+//
+// When a transaction cannot lock the database, because it is already locked by another one,
+// SQLite by default throws an error: database is locked. This behavior is usually not appropriate when
+// concurrent access is needed, typically when multiple processes write to the same database.
+// PRAGMA busy_timeout lets you set a timeout or a handler for these events. When setting a timeout,
+// SQLite will try the transaction multiple times within this timeout.
+// fixes #341
+// https://rsqlite.r-dbi.org/reference/sqlitesetbusyhandler
+// retrying for 30000 milliseconds, 30seconds - this would be unreasonable for a distributed multi-tenant application,
+// but should be fine for local usage.
+//
+// WAL (Write-Ahead Logging) mode is used by default to reduce filesystem operations and improve
+// compatibility with Docker containers running with restricted capabilities (cap_drop: [ALL]).
+// fixes #772 (upstream), #25
+// https://www.sqlite.org/wal.html
+func sqlitePragmas(appConfig config.Interface) map[string]string {
+	journalMode := appConfig.GetString("web.database.journal_mode")
+	if journalMode == "" {
+		journalMode = "WAL"
+	}
+
+	return map[string]string{
+		"busy_timeout": "30000",
+		"journal_mode": journalMode,
+		"temp_store":   "MEMORY",
+		"synchronous":  "NORMAL",
+	}
+}
+
+func sqlitePragmaString(pragmas map[string]string) string {
+	q := url.Values{}
+	for key, val := range pragmas {
+		q.Add("_pragma", key+"="+val)
+	}
+
+	queryStr := q.Encode()
+	if len(queryStr) > 0 {
+		return "?" + queryStr
+	}
+	return ""
+}
+
+// redactDSN strips the password from a postgres DSN (URL or key=value form) for logging.
+func redactDSN(dsn string) string {
+	if parsed, err := url.Parse(dsn); err == nil && parsed.Scheme != "" {
+		if parsed.User != nil {
+			if _, hasPassword := parsed.User.Password(); hasPassword {
+				parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+			}
+		}
+		return parsed.String()
+	}
+
+	fields := strings.Fields(dsn)
+	for i, field := range fields {
+		if strings.HasPrefix(strings.ToLower(field), "password=") {
+			fields[i] = "password=xxxxx"
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// isPostgres reports whether tx is connected to PostgreSQL. Migrations use it to pick between the
+// SQLite table-rebuild statements and their native PostgreSQL equivalents.
+func isPostgres(tx *gorm.DB) bool {
+	return tx.Dialector.Name() == DATABASE_TYPE_POSTGRES
+}
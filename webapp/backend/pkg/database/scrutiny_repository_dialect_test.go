@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGormDialector_DefaultsToSqlite(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(cfgDatabaseType).Return("").AnyTimes()
+	fakeConfig.EXPECT().GetString(cfgDatabaseLocation).Return("/tmp/scrutiny.db").AnyTimes()
+	fakeConfig.EXPECT().GetString("web.database.journal_mode").Return("DELETE").AnyTimes()
+
+	dialector, target, err := newGormDialector(fakeConfig)
+	require.NoError(t, err)
+	require.Equal(t, DATABASE_TYPE_SQLITE, dialector.Name())
+	require.Equal(t, "sqlite db: /tmp/scrutiny.db", target)
+}
+
+func TestNewGormDialector_Postgres(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString(cfgDatabaseType).Return("PostgreSQL").AnyTimes()
+	fakeConfig.EXPECT().GetString(cfgDatabaseDSN).Return("postgres://scrutiny:secret@db:5432/scrutiny?sslmode=disable").AnyTimes()
+
+	dialector, target, err := newGormDialector(fakeConfig)
+	require.NoError(t, err)
+	require.Equal(t, DATABASE_TYPE_POSTGRES, dialector.Name())
+	require.NotContains(t, target, "secret")
+}
+
+func TestNewGormDialector_Errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	missingDSN := mock_config.NewMockInterface(mockCtrl)
+	missingDSN.EXPECT().GetString(cfgDatabaseType).Return("postgres").AnyTimes()
+	missingDSN.EXPECT().GetString(cfgDatabaseDSN).Return("").AnyTimes()
+	_, _, err := newGormDialector(missingDSN)
+	require.ErrorContains(t, err, "web.database.dsn is required")
+
+	unknownType := mock_config.NewMockInterface(mockCtrl)
+	unknownType.EXPECT().GetString(cfgDatabaseType).Return("mysql").AnyTimes()
+	_, _, err = newGormDialector(unknownType)
+	require.ErrorContains(t, err, "unsupported web.database.type")
+}
+
+func TestRedactDSN(t *testing.T) {
+	require.Equal(t, "postgres://scrutiny:xxxxx@db:5432/scrutiny", redactDSN("postgres://scrutiny:secret@db:5432/scrutiny"))
+	require.Equal(t, "postgres://db/scrutiny", redactDSN("postgres://db/scrutiny"))
+	require.Equal(t, "host=db user=scrutiny password=xxxxx dbname=scrutiny", redactDSN("host=db user=scrutiny password=secret dbname=scrutiny"))
+}
@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrHostNotFound is returned when a host does not exist.
+var ErrHostNotFound = errors.New("host not found")
+
+// hostChildTables lists the tables that reference a host through their host_id column.
+var hostChildTables = []string{
+	"devices",
+	"zfs_pools",
+	"mdadm_arrays",
+	"btrfs_filesystems",
+	"filesystem_capacities",
+	"filesystem_host_statuses",
+	"device_replacements",
+	"host_check_ins",
+	"incidents",
+	"notification_records",
+	"device_lifecycle_events",
+}
+
+// hostFlagTables lists the child tables that have their own muted/archived flags.
+var hostFlagTables = []string{"devices", "zfs_pools", "mdadm_arrays", "btrfs_filesystems"}
+
+// RecordHostCheckIn creates the host if needed and records that a collector of collectorType
+// reported for it. Check-ins without a host ID are ignored.
+func (sr *scrutinyRepository) RecordHostCheckIn(ctx context.Context, hostID string, collectorType string, info models.HostCollectorInfo) error {
+	hostID = strings.TrimSpace(hostID)
+	if hostID == "" {
+		return nil
+	}
+
+	return sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Host{HostID: hostID}).Error; err != nil {
+			return fmt.Errorf("could not create host: %w", err)
+		}
+
+		updates := map[string]interface{}{}
+		if info.CollectorVersion != "" {
+			updates["collector_version"] = info.CollectorVersion
+		}
+		if info.OSInfo != "" {
+			updates["os_info"] = info.OSInfo
+		}
+		if info.Architecture != "" {
+			updates["architecture"] = info.Architecture
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Host{}).Where(queryHostID, hostID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("could not update host: %w", err)
+			}
+		}
+
+		checkIn := models.HostCheckIn{
+			HostID:           hostID,
+			CollectorType:    collectorType,
+			CollectorVersion: info.CollectorVersion,
+			LastSeenAt:       time.Now().UTC(),
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "host_id"}, {Name: "collector_type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"collector_version", "last_seen_at"}),
+		}).Create(&checkIn).Error
+	})
+}
+
+// GetHosts returns every host with its collector check-ins, ordered by host ID.
+func (sr *scrutinyRepository) GetHosts(ctx context.Context) ([]models.Host, error) {
+	hosts := []models.Host{}
+	if err := sr.gormClient.WithContext(ctx).Preload("CheckIns").Order("host_id ASC").Find(&hosts).Error; err != nil {
+		return nil, fmt.Errorf("could not get hosts from DB: %w", err)
+	}
+	return hosts, nil
+}
+
+// GetHost returns a single host with its collector check-ins.
+func (sr *scrutinyRepository) GetHost(ctx context.Context, hostID string) (models.Host, error) {
+	var host models.Host
+	err := sr.gormClient.WithContext(ctx).Preload("CheckIns").Where(queryHostID, hostID).First(&host).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Host{}, ErrHostNotFound
+	}
+	if err != nil {
+		return models.Host{}, fmt.Errorf("could not get host from DB: %w", err)
+	}
+	return host, nil
+}
+
+// UpdateHost replaces the user-editable fields of a host.
+func (sr *scrutinyRepository) UpdateHost(ctx context.Context, hostID string, update models.HostUpdate) error {
+	if update.MissedPingTimeoutMinutes < 0 {
+		return fmt.Errorf("missed_ping_timeout_minutes must be >= 0")
+	}
+	result := sr.gormClient.WithContext(ctx).Model(&models.Host{}).Where(queryHostID, hostID).Updates(map[string]interface{}{
+		"label":                       strings.TrimSpace(update.Label),
+		"description":                 strings.TrimSpace(update.Description),
+		"missed_ping_timeout_minutes": update.MissedPingTimeoutMinutes,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHostNotFound
+	}
+	return nil
+}
+
+// UpdateHostMuted mutes or unmutes a host together with all of its devices, pools and filesystems.
+func (sr *scrutinyRepository) UpdateHostMuted(ctx context.Context, hostID string, muted bool) error {
+	return sr.updateHostFlag(ctx, hostID, "muted", muted)
+}
+
+// UpdateHostArchived archives or unarchives a host together with all of its devices, pools and
+// filesystems.
+func (sr *scrutinyRepository) UpdateHostArchived(ctx context.Context, hostID string, archived bool) error {
+	return sr.updateHostFlag(ctx, hostID, "archived", archived)
+}
+
+func (sr *scrutinyRepository) updateHostFlag(ctx context.Context, hostID string, column string, value bool) error {
+	return sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Host{}).Where(queryHostID, hostID).Update(column, value)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrHostNotFound
+		}
+		for _, table := range hostFlagTables {
+			if err := tx.Table(table).Where(queryHostID, hostID).Update(column, value).Error; err != nil {
+				return fmt.Errorf("could not update %s of %s: %w", column, table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// RenameHost changes a host's ID and updates every record that references it. Collectors must be
+// reconfigured with the new host.id, otherwise their next check-in recreates the old host.
+func (sr *scrutinyRepository) RenameHost(ctx context.Context, hostID string, newHostID string) error {
+	newHostID = strings.TrimSpace(newHostID)
+	if newHostID == "" {
+		return fmt.Errorf("new host ID is required")
+	}
+	if newHostID == hostID {
+		return nil
+	}
+
+	return sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Host{}).Where(queryHostID, hostID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrHostNotFound
+		}
+		if err := tx.Model(&models.Host{}).Where(queryHostID, newHostID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("host %q already exists", newHostID)
+		}
+
+		for _, table := range hostChildTables {
+			if err := tx.Table(table).Where(queryHostID, hostID).Update("host_id", newHostID).Error; err != nil {
+				return fmt.Errorf("could not rename host in %s: %w", table, err)
+			}
+		}
+		if err := tx.Model(&models.MaintenanceWindow{}).
+			Where("scope_type = ? AND scope_id = ?", models.MaintenanceScopeHost, hostID).
+			Update("scope_id", newHostID).Error; err != nil {
+			return fmt.Errorf("could not rename host in maintenance windows: %w", err)
+		}
+		if err := renameHostInRoutingRules(tx, hostID, newHostID); err != nil {
+			return err
+		}
+		return tx.Model(&models.Host{}).Where(queryHostID, hostID).Update("host_id", newHostID).Error
+	})
+}
+
+// renameHostInRoutingRules updates the host_ids of notification URL routing rules, which are
+// stored as JSON and cannot be updated with a single query.
+func renameHostInRoutingRules(tx *gorm.DB, hostID string, newHostID string) error {
+	notifyUrls := []models.NotifyUrl{}
+	if err := tx.Find(&notifyUrls).Error; err != nil {
+		return fmt.Errorf("could not get notification URLs from DB: %w", err)
+	}
+	for _, notifyUrl := range notifyUrls {
+		renamed := false
+		for i := range notifyUrl.Rules {
+			for j, ruleHostID := range notifyUrl.Rules[i].HostIDs {
+				// rules match host IDs case-insensitively
+				if strings.EqualFold(ruleHostID, hostID) {
+					notifyUrl.Rules[i].HostIDs[j] = newHostID
+					renamed = true
+				}
+			}
+		}
+		if !renamed {
+			continue
+		}
+		if err := tx.Model(&models.NotifyUrl{ID: notifyUrl.ID}).Select("rules").Updates(&models.NotifyUrl{Rules: notifyUrl.Rules}).Error; err != nil {
+			return fmt.Errorf("could not rename host in the rules of notification URL %d: %w", notifyUrl.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetHostSummaries aggregates device, pool and filesystem status per host.
+func (sr *scrutinyRepository) GetHostSummaries(ctx context.Context) ([]models.HostSummary, error) {
+	hosts, err := sr.GetHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]*models.HostSummary, len(hosts))
+	for i := range hosts {
+		summaries[hosts[i].HostID] = &models.HostSummary{Host: hosts[i], LastSeenAt: hosts[i].LatestCheckIn()}
+	}
+
+	devices := []models.Device{}
+	if err := sr.gormClient.WithContext(ctx).Select("host_id", "device_status", "archived").Where("host_id <> ''").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("could not get devices from DB: %w", err)
+	}
+	for _, device := range devices {
+		if summary := summaries[device.HostId]; summary != nil {
+			countHostStatus(&summary.Devices, device.Archived, device.DeviceStatus == pkg.DeviceStatusPassed)
+		}
+	}
+
+	pools := []models.ZFSPool{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id <> ''").Find(&pools).Error; err != nil {
+		return nil, fmt.Errorf("could not get ZFS pools from DB: %w", err)
+	}
+	for i := range pools {
+		if summary := summaries[pools[i].HostID]; summary != nil {
+			countHostStatus(&summary.ZFSPools, pools[i].Archived, pools[i].IsHealthy())
+		}
+	}
+
+	arrays := []models.MDADMArray{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id <> ''").Find(&arrays).Error; err != nil {
+		return nil, fmt.Errorf("could not get MDADM arrays from DB: %w", err)
+	}
+	for _, array := range arrays {
+		summary := summaries[array.HostID]
+		if summary == nil {
+			continue
+		}
+		healthy := true
+		if !array.Archived {
+			latest, err := sr.GetLatestMdadmMetrics(ctx, array.UUID)
+			if err != nil {
+				sr.logger.Warnf("Failed to get latest metrics for MDADM array %s: %v", array.UUID, err)
+			} else if latest != nil {
+				healthy = latest.FailedDevices == 0 && !strings.Contains(strings.ToLower(latest.State), "degraded")
+			}
+		}
+		countHostStatus(&summary.MdadmArrays, array.Archived, healthy)
+	}
+
+	filesystems := []models.BtrfsFilesystem{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id <> ''").Find(&filesystems).Error; err != nil {
+		return nil, fmt.Errorf("could not get Btrfs filesystems from DB: %w", err)
+	}
+	for i := range filesystems {
+		if summary := summaries[filesystems[i].HostID]; summary != nil {
+			countHostStatus(&summary.BtrfsFilesystems, filesystems[i].Archived, filesystems[i].IsHealthy())
+		}
+	}
+
+	filesystemsByHost, hostStatuses, err := sr.GetFilesystemSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for hostID, capacities := range filesystemsByHost {
+		if summary := summaries[hostID]; summary != nil {
+			summary.FilesystemCount = len(capacities)
+		}
+	}
+	for hostID, status := range hostStatuses {
+		if summary := summaries[hostID]; summary != nil {
+			summary.FilesystemStatus = string(status.Status)
+		}
+	}
+
+	result := make([]models.HostSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].HostID < result[j].HostID })
+	return result, nil
+}
+
+func countHostStatus(counts *models.HostStatusCounts, archived bool, healthy bool) {
+	counts.Total++
+	switch {
+	case archived:
+		counts.Archived++
+	case healthy:
+		counts.Passed++
+	default:
+		counts.Failed++
+	}
+}
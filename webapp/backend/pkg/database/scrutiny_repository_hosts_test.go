@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func createHostTestRepository(t *testing.T) *scrutinyRepository {
+	t.Helper()
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(
+		&models.Host{},
+		&models.HostCheckIn{},
+		&models.ZFSPool{},
+		&models.MDADMArray{},
+		&models.BtrfsFilesystem{},
+		&models.FilesystemCapacity{},
+		&models.FilesystemHostStatus{},
+		&models.DeviceReplacement{},
+		&models.Incident{},
+		&models.NotificationRecord{},
+		&models.MaintenanceWindow{},
+		&models.DeviceLifecycleEvent{},
+		&models.NotifyUrl{},
+	))
+	return repo
+}
+
+func TestRecordHostCheckIn_CreatesHostAndTracksCollectorTypes(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeMetrics, models.HostCollectorInfo{CollectorVersion: "1.0.0", OSInfo: "Debian GNU/Linux 12 (bookworm)", Architecture: "amd64"}))
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeZFS, models.HostCollectorInfo{CollectorVersion: "1.1.0"}))
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeMetrics, models.HostCollectorInfo{CollectorVersion: "1.1.0"}))
+	// check-ins without a host ID are ignored
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+
+	hosts, err := repo.GetHosts(ctx)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+
+	host := hosts[0]
+	require.Equal(t, "nas", host.HostID)
+	require.Equal(t, "1.1.0", host.CollectorVersion)
+	// empty fields leave the stored values unchanged
+	require.Equal(t, "Debian GNU/Linux 12 (bookworm)", host.OSInfo)
+	require.Equal(t, "amd64", host.Architecture)
+	require.Len(t, host.CheckIns, 2)
+	for _, checkIn := range host.CheckIns {
+		require.Equal(t, "1.1.0", checkIn.CollectorVersion)
+	}
+	require.NotNil(t, host.LatestCheckIn())
+}
+
+func TestUpdateHostFlags_CascadeToChildRecords(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sda", HostId: "nas"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sdb", HostId: "backup"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.ZFSPool{GUID: "pool-1", Name: "tank", HostID: "nas"}).Error)
+
+	require.NoError(t, repo.UpdateHostMuted(ctx, "nas", true))
+	require.NoError(t, repo.UpdateHostArchived(ctx, "nas", true))
+	require.ErrorIs(t, repo.UpdateHostMuted(ctx, "missing", true), ErrHostNotFound)
+
+	var nasDevice, backupDevice models.Device
+	require.NoError(t, repo.gormClient.Where(queryDeviceID, "sda").First(&nasDevice).Error)
+	require.NoError(t, repo.gormClient.Where(queryDeviceID, "sdb").First(&backupDevice).Error)
+	require.True(t, nasDevice.Muted)
+	require.True(t, nasDevice.Archived)
+	require.False(t, backupDevice.Muted)
+
+	var pool models.ZFSPool
+	require.NoError(t, repo.gormClient.Where("guid = ?", "pool-1").First(&pool).Error)
+	require.True(t, pool.Muted)
+	require.True(t, pool.Archived)
+}
+
+func TestUpdateHost_SetsEditableFields(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.UpdateHost(ctx, "nas", models.HostUpdate{Label: " Basement NAS ", Description: "TrueNAS box", MissedPingTimeoutMinutes: 720}))
+	require.Error(t, repo.UpdateHost(ctx, "nas", models.HostUpdate{MissedPingTimeoutMinutes: -1}))
+	require.ErrorIs(t, repo.UpdateHost(ctx, "missing", models.HostUpdate{}), ErrHostNotFound)
+
+	host, err := repo.GetHost(ctx, "nas")
+	require.NoError(t, err)
+	require.Equal(t, "Basement NAS", host.Label)
+	require.Equal(t, "TrueNAS box", host.Description)
+	require.Equal(t, 720, host.MissedPingTimeoutMinutes)
+}
+
+func TestRenameHost_UpdatesChildRecords(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "old-name", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "taken", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sda", HostId: "old-name"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.FilesystemCapacity{HostID: "old-name", MountPoint: "/"}).Error)
+
+	require.Error(t, repo.RenameHost(ctx, "old-name", "taken"))
+	require.ErrorIs(t, repo.RenameHost(ctx, "missing", "new-name"), ErrHostNotFound)
+	require.NoError(t, repo.RenameHost(ctx, "old-name", "new-name"))
+
+	_, err := repo.GetHost(ctx, "old-name")
+	require.ErrorIs(t, err, ErrHostNotFound)
+	host, err := repo.GetHost(ctx, "new-name")
+	require.NoError(t, err)
+	require.Len(t, host.CheckIns, 1)
+
+	var device models.Device
+	require.NoError(t, repo.gormClient.Where(queryDeviceID, "sda").First(&device).Error)
+	require.Equal(t, "new-name", device.HostId)
+
+	var filesystem models.FilesystemCapacity
+	require.NoError(t, repo.gormClient.First(&filesystem).Error)
+	require.Equal(t, "new-name", filesystem.HostID)
+}
+
+func TestRenameHost_UpdatesNotificationState(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "old-name", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.gormClient.Create(&models.Incident{DeviceID: "sda", HostID: "old-name", Status: models.IncidentStatusOpen}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.NotificationRecord{HostID: "old-name", FailureType: "SmartFailure"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.DeviceLifecycleEvent{HostID: "old-name", DeviceID: "sda", Type: models.LifecycleEventDeviceDisappeared}).Error)
+	hostWindow := models.MaintenanceWindow{ScopeType: models.MaintenanceScopeHost, ScopeID: "old-name"}
+	deviceWindow := models.MaintenanceWindow{ScopeType: models.MaintenanceScopeDevice, ScopeID: "old-name"}
+	require.NoError(t, repo.gormClient.Create(&hostWindow).Error)
+	require.NoError(t, repo.gormClient.Create(&deviceWindow).Error)
+	routed := models.NotifyUrl{URL: "ntfy://pager", Rules: []models.NotifyRoutingRule{
+		{HostIDs: []string{"other", "OLD-NAME"}},
+		{FailureTypes: []string{"SmartFailure"}},
+	}}
+	unrouted := models.NotifyUrl{URL: "ntfy://chat", Rules: []models.NotifyRoutingRule{{HostIDs: []string{"other"}}}}
+	require.NoError(t, repo.gormClient.Create(&routed).Error)
+	require.NoError(t, repo.gormClient.Create(&unrouted).Error)
+
+	require.NoError(t, repo.RenameHost(ctx, "old-name", "new-name"))
+
+	var incident models.Incident
+	require.NoError(t, repo.gormClient.First(&incident).Error)
+	require.Equal(t, "new-name", incident.HostID)
+	var record models.NotificationRecord
+	require.NoError(t, repo.gormClient.First(&record).Error)
+	require.Equal(t, "new-name", record.HostID)
+	var event models.DeviceLifecycleEvent
+	require.NoError(t, repo.gormClient.First(&event).Error)
+	require.Equal(t, "new-name", event.HostID)
+
+	require.NoError(t, repo.gormClient.First(&hostWindow, hostWindow.ID).Error)
+	require.Equal(t, "new-name", hostWindow.ScopeID)
+	require.NoError(t, repo.gormClient.First(&deviceWindow, deviceWindow.ID).Error)
+	require.Equal(t, "old-name", deviceWindow.ScopeID, "only host-scoped windows are renamed")
+
+	require.NoError(t, repo.gormClient.First(&routed, routed.ID).Error)
+	require.Equal(t, []string{"other", "new-name"}, routed.Rules[0].HostIDs)
+	require.Equal(t, []string{"SmartFailure"}, routed.Rules[1].FailureTypes)
+	require.NoError(t, repo.gormClient.First(&unrouted, unrouted.ID).Error)
+	require.Equal(t, []string{"other"}, unrouted.Rules[0].HostIDs)
+}
+
+func TestGetHostSummaries_AggregatesStatusPerHost(t *testing.T) {
+	repo := createHostTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "nas", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.RecordHostCheckIn(ctx, "backup", models.CollectorTypeMetrics, models.HostCollectorInfo{}))
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sda", HostId: "nas", DeviceStatus: pkg.DeviceStatusPassed}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sdb", HostId: "nas", DeviceStatus: pkg.DeviceStatusFailedSmart}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sdc", HostId: "nas", Archived: true}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.ZFSPool{GUID: "pool-1", Name: "tank", HostID: "nas", Status: models.ZFSPoolStatusDegraded}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.MDADMArray{UUID: "md-1", Name: "md0", HostID: "backup", Archived: true}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.FilesystemCapacity{HostID: "backup", MountPoint: "/"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.FilesystemHostStatus{HostID: "backup", Status: models.FilesystemHostStatusAvailable}).Error)
+
+	summaries, err := repo.GetHostSummaries(ctx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+
+	backup, nas := summaries[0], summaries[1]
+	require.Equal(t, "backup", backup.HostID)
+	require.Equal(t, models.HostStatusCounts{Total: 1, Archived: 1}, backup.MdadmArrays)
+	require.Equal(t, 1, backup.FilesystemCount)
+	require.Equal(t, string(models.FilesystemHostStatusAvailable), backup.FilesystemStatus)
+	require.NotNil(t, backup.LastSeenAt)
+
+	require.Equal(t, "nas", nas.HostID)
+	require.Equal(t, models.HostStatusCounts{Total: 3, Passed: 1, Failed: 1, Archived: 1}, nas.Devices)
+	require.Equal(t, models.HostStatusCounts{Total: 1, Failed: 1}, nas.ZFSPools)
+}
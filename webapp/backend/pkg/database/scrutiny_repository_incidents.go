@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrIncidentNotFound is returned when an incident does not exist.
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// ErrIncidentResolved is returned when acknowledging an incident that is already resolved.
+var ErrIncidentResolved = errors.New("incident is already resolved")
+
+const queryIncidentUnresolved = "device_id = ? AND status <> ?"
+
+// OpenDeviceIncident returns the unresolved incident of the device, refreshed with the latest
+// notification details, or opens a new one if the device has none.
+func (sr *scrutinyRepository) OpenDeviceIncident(ctx context.Context, device models.Device, failureType string, subject string, message string) (models.Incident, error) {
+	var incident models.Incident
+	err := sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(queryIncidentUnresolved, device.DeviceID, models.IncidentStatusResolved).Order("id DESC").First(&incident).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			incident = models.Incident{
+				DeviceID:    device.DeviceID,
+				HostID:      device.HostId,
+				DeviceName:  device.DeviceName,
+				DeviceLabel: device.Label,
+				FailureType: failureType,
+				Status:      models.IncidentStatusOpen,
+				Subject:     subject,
+				Message:     message,
+				OpenedAt:    time.Now().UTC(),
+			}
+			return tx.Create(&incident).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		incident.FailureType = failureType
+		incident.Subject = subject
+		incident.Message = message
+		return tx.Model(&incident).Updates(map[string]interface{}{
+			"failure_type": failureType,
+			"subject":      subject,
+			"message":      message,
+		}).Error
+	})
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("could not open incident: %w", err)
+	}
+	return incident, nil
+}
+
+// MarkIncidentNotified records that a notification for the incident was sent.
+func (sr *scrutinyRepository) MarkIncidentNotified(ctx context.Context, id uint, at time.Time) error {
+	return sr.gormClient.WithContext(ctx).Model(&models.Incident{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_notified_at":   at,
+		"notification_count": gorm.Expr("notification_count + 1"),
+	}).Error
+}
+
+// MarkIncidentEscalated records that the escalation URLs were notified about the incident.
+func (sr *scrutinyRepository) MarkIncidentEscalated(ctx context.Context, id uint, at time.Time) error {
+	return sr.gormClient.WithContext(ctx).Model(&models.Incident{}).Where("id = ?", id).Update("escalated_at", at).Error
+}
+
+// GetIncidents returns the incidents with the given status, or all incidents if status is empty,
+// newest first.
+func (sr *scrutinyRepository) GetIncidents(ctx context.Context, status string) ([]models.Incident, error) {
+	incidents := []models.Incident{}
+	query := sr.gormClient.WithContext(ctx).Order("opened_at DESC, id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("could not get incidents from DB: %w", err)
+	}
+	return incidents, nil
+}
+
+// GetIncident returns a single incident by ID.
+func (sr *scrutinyRepository) GetIncident(ctx context.Context, id uint) (models.Incident, error) {
+	var incident models.Incident
+	if err := sr.gormClient.WithContext(ctx).First(&incident, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return incident, ErrIncidentNotFound
+		}
+		return incident, fmt.Errorf("could not get incident from DB: %w", err)
+	}
+	return incident, nil
+}
+
+// AcknowledgeIncident moves an open incident to acknowledged, which stops its notifications and
+// escalation. Acknowledging an already acknowledged incident returns it unchanged.
+func (sr *scrutinyRepository) AcknowledgeIncident(ctx context.Context, id uint, by string, note string, at time.Time) (models.Incident, error) {
+	incident, err := sr.GetIncident(ctx, id)
+	if err != nil {
+		return incident, err
+	}
+	switch incident.Status {
+	case models.IncidentStatusResolved:
+		return incident, ErrIncidentResolved
+	case models.IncidentStatusAcknowledged:
+		return incident, nil
+	}
+
+	incident.Status = models.IncidentStatusAcknowledged
+	incident.AcknowledgedAt = &at
+	incident.AcknowledgedBy = by
+	incident.AckNote = note
+	if err := sr.gormClient.WithContext(ctx).Model(&incident).Updates(map[string]interface{}{
+		"status":          incident.Status,
+		"acknowledged_at": at,
+		"acknowledged_by": by,
+		"ack_note":        note,
+	}).Error; err != nil {
+		return incident, fmt.Errorf("could not acknowledge incident: %w", err)
+	}
+	return incident, nil
+}
+
+// ResolveIncident resolves a single incident. Resolving a resolved incident returns it unchanged.
+func (sr *scrutinyRepository) ResolveIncident(ctx context.Context, id uint, at time.Time) (models.Incident, error) {
+	incident, err := sr.GetIncident(ctx, id)
+	if err != nil || incident.Status == models.IncidentStatusResolved {
+		return incident, err
+	}
+
+	incident.Status = models.IncidentStatusResolved
+	incident.ResolvedAt = &at
+	if err := sr.gormClient.WithContext(ctx).Model(&incident).Updates(map[string]interface{}{
+		"status":      incident.Status,
+		"resolved_at": at,
+	}).Error; err != nil {
+		return incident, fmt.Errorf("could not resolve incident: %w", err)
+	}
+	return incident, nil
+}
+
+// resolveDeviceIncidents resolves every unresolved incident of a device. Called whenever the
+// device status returns to passed.
+func (sr *scrutinyRepository) resolveDeviceIncidents(ctx context.Context, deviceID string) error {
+	result := sr.gormClient.WithContext(ctx).Model(&models.Incident{}).
+		Where(queryIncidentUnresolved, deviceID, models.IncidentStatusResolved).
+		Updates(map[string]interface{}{
+			"status":      models.IncidentStatusResolved,
+			"resolved_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("could not resolve device incidents: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		sr.logger.Infof("Resolved %d incident(s) for device %s - device status returned to passed", result.RowsAffected, deviceID)
+	}
+	return nil
+}
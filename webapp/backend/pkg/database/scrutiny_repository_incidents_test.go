@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDeviceIncident_ReusesUnresolvedIncident(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+	device := models.Device{DeviceID: "sda", DeviceName: "/dev/sda", HostId: "nas"}
+
+	first, err := repo.OpenDeviceIncident(ctx, device, "ScrutinyFailure", "first", "first message")
+	require.NoError(t, err)
+	require.Equal(t, models.IncidentStatusOpen, first.Status)
+	require.Equal(t, "nas", first.HostID)
+
+	second, err := repo.OpenDeviceIncident(ctx, device, "SmartFailure", "second", "second message")
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, "SmartFailure", second.FailureType)
+
+	now := time.Now().UTC()
+	require.NoError(t, repo.MarkIncidentNotified(ctx, first.ID, now))
+	require.NoError(t, repo.MarkIncidentNotified(ctx, first.ID, now))
+	stored, err := repo.GetIncident(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.NotificationCount)
+	require.NotNil(t, stored.LastNotifiedAt)
+	require.Equal(t, "second", stored.Subject)
+
+	// a resolved incident is never reopened
+	_, err = repo.ResolveIncident(ctx, first.ID, now)
+	require.NoError(t, err)
+	third, err := repo.OpenDeviceIncident(ctx, device, "SmartFailure", "third", "third message")
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, third.ID)
+}
+
+func TestAcknowledgeIncident(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	incident, err := repo.OpenDeviceIncident(ctx, models.Device{DeviceID: "sda"}, "SmartFailure", "subject", "message")
+	require.NoError(t, err)
+
+	acked, err := repo.AcknowledgeIncident(ctx, incident.ID, "alice", "replacement ordered", now)
+	require.NoError(t, err)
+	require.Equal(t, models.IncidentStatusAcknowledged, acked.Status)
+	require.Equal(t, "alice", acked.AcknowledgedBy)
+
+	// repeated acknowledgements keep the original details
+	again, err := repo.AcknowledgeIncident(ctx, incident.ID, "bob", "", now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, "alice", again.AcknowledgedBy)
+
+	// further failures keep the acknowledged incident
+	reopened, err := repo.OpenDeviceIncident(ctx, models.Device{DeviceID: "sda"}, "SmartFailure", "subject", "message")
+	require.NoError(t, err)
+	require.Equal(t, incident.ID, reopened.ID)
+	require.Equal(t, models.IncidentStatusAcknowledged, reopened.Status)
+
+	open, err := repo.GetIncidents(ctx, models.IncidentStatusOpen)
+	require.NoError(t, err)
+	require.Empty(t, open)
+
+	_, err = repo.ResolveIncident(ctx, incident.ID, now)
+	require.NoError(t, err)
+	_, err = repo.AcknowledgeIncident(ctx, incident.ID, "alice", "", now)
+	require.ErrorIs(t, err, ErrIncidentResolved)
+
+	_, err = repo.GetIncident(ctx, 999)
+	require.ErrorIs(t, err, ErrIncidentNotFound)
+}
+
+func TestIncidents_AutoResolveWhenDevicePasses(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sda", DeviceStatus: pkg.DeviceStatusFailedSmart}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sdb", DeviceStatus: pkg.DeviceStatusFailedScrutiny}).Error)
+	sda, err := repo.OpenDeviceIncident(ctx, models.Device{DeviceID: "sda"}, "SmartFailure", "subject", "message")
+	require.NoError(t, err)
+	sdb, err := repo.OpenDeviceIncident(ctx, models.Device{DeviceID: "sdb"}, "ScrutinyFailure", "subject", "message")
+	require.NoError(t, err)
+
+	// a passing SMART upload clears the SMART failure bit and resolves the incident
+	var passing collector.SmartInfo
+	passing.SmartStatus.Passed = true
+	_, err = repo.UpdateDevice(ctx, "sda", &passing)
+	require.NoError(t, err)
+	resolved, err := repo.GetIncident(ctx, sda.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.IncidentStatusResolved, resolved.Status)
+	require.NotNil(t, resolved.ResolvedAt)
+
+	_, err = repo.ResetDeviceStatus(ctx, "sdb")
+	require.NoError(t, err)
+	resolved, err = repo.GetIncident(ctx, sdb.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.IncidentStatusResolved, resolved.Status)
+
+	require.NoError(t, repo.DeleteDevice(ctx, "sdb"))
+	_, err = repo.GetIncident(ctx, sdb.ID)
+	require.ErrorIs(t, err, ErrIncidentNotFound)
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrMaintenanceWindowNotFound is returned when a maintenance window does not exist.
+var ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+// ErrMaintenanceWindowEnded is returned when ending a maintenance window that has already ended.
+var ErrMaintenanceWindowEnded = errors.New("maintenance window has already ended")
+
+// CreateMaintenanceWindow stores a new maintenance window.
+func (sr *scrutinyRepository) CreateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	if err := sr.gormClient.WithContext(ctx).Create(window).Error; err != nil {
+		return fmt.Errorf("could not create maintenance window: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenanceWindows returns all maintenance windows, newest first.
+func (sr *scrutinyRepository) GetMaintenanceWindows(ctx context.Context) ([]models.MaintenanceWindow, error) {
+	windows := []models.MaintenanceWindow{}
+	if err := sr.gormClient.WithContext(ctx).Order("starts_at DESC, id DESC").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("could not get maintenance windows from DB: %w", err)
+	}
+	return windows, nil
+}
+
+// GetMaintenanceWindow returns a single maintenance window by ID.
+func (sr *scrutinyRepository) GetMaintenanceWindow(ctx context.Context, id uint) (models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := sr.gormClient.WithContext(ctx).First(&window, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return window, ErrMaintenanceWindowNotFound
+		}
+		return window, fmt.Errorf("could not get maintenance window from DB: %w", err)
+	}
+	return window, nil
+}
+
+// EndMaintenanceWindow ends a window at the given time. Recurring windows do not recur afterwards.
+func (sr *scrutinyRepository) EndMaintenanceWindow(ctx context.Context, id uint, at time.Time) (models.MaintenanceWindow, error) {
+	window, err := sr.GetMaintenanceWindow(ctx, id)
+	if err != nil {
+		return window, err
+	}
+	if window.EndedAt != nil {
+		return window, ErrMaintenanceWindowEnded
+	}
+
+	window.EndedAt = &at
+	if err := sr.gormClient.WithContext(ctx).Model(&window).Update("ended_at", at).Error; err != nil {
+		return window, fmt.Errorf("could not end maintenance window: %w", err)
+	}
+	return window, nil
+}
+
+// GetActiveMaintenanceWindows returns the windows active at now, with the member devices of pool
+// and array scopes resolved.
+func (sr *scrutinyRepository) GetActiveMaintenanceWindows(ctx context.Context, now time.Time) ([]models.MaintenanceWindow, error) {
+	candidates := []models.MaintenanceWindow{}
+	if err := sr.gormClient.WithContext(ctx).
+		Where("ended_at IS NULL OR ended_at > ?", now).
+		Where("starts_at <= ?", now).
+		Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("could not get maintenance windows from DB: %w", err)
+	}
+
+	active := []models.MaintenanceWindow{}
+	for _, window := range candidates {
+		if !window.ActiveAt(now) {
+			continue
+		}
+		if err := sr.resolveMaintenanceMembers(ctx, &window); err != nil {
+			sr.logger.Warnf("Failed to resolve members of maintenance window %d: %v", window.ID, err)
+		}
+		active = append(active, window)
+	}
+	return active, nil
+}
+
+// resolveMaintenanceMembers fills in the member devices of pool and array windows and the
+// devices of their host they resolve to.
+func (sr *scrutinyRepository) resolveMaintenanceMembers(ctx context.Context, window *models.MaintenanceWindow) error {
+	switch window.ScopeType {
+	case models.MaintenanceScopePool:
+		var pool models.ZFSPool
+		if err := sr.gormClient.WithContext(ctx).Where(queryGUID, window.ScopeID).First(&pool).Error; err != nil {
+			return err
+		}
+		var vdevs []models.ZFSVdev
+		if err := sr.gormClient.WithContext(ctx).
+			Where("pool_guid = ? AND type IN ?", window.ScopeID, []models.ZFSVdevType{models.ZFSVdevTypeDisk, models.ZFSVdevTypeFile}).
+			Find(&vdevs).Error; err != nil {
+			return err
+		}
+		window.MemberHostID = pool.HostID
+		for _, vdev := range vdevs {
+			if vdev.Path != "" {
+				window.MemberDevices = append(window.MemberDevices, vdev.Path)
+			} else {
+				window.MemberDevices = append(window.MemberDevices, vdev.Name)
+			}
+		}
+	case models.MaintenanceScopeArray:
+		var array models.MDADMArray
+		if err := sr.gormClient.WithContext(ctx).Where("uuid = ?", window.ScopeID).First(&array).Error; err != nil {
+			return err
+		}
+		window.MemberHostID = array.HostID
+		window.MemberDevices = array.Devices
+	default:
+		return nil
+	}
+
+	// resolve members to devices, so /dev/disk/by-id members match notifications about /dev/sdX
+	devices := []models.Device{}
+	if err := sr.gormClient.WithContext(ctx).Where("host_id = ?", window.MemberHostID).Find(&devices).Error; err != nil {
+		return err
+	}
+	for _, device := range devices {
+		for _, member := range window.MemberDevices {
+			if models.MaintenanceMemberMatchesDevice(member, device) {
+				window.MemberDeviceIDs = append(window.MemberDeviceIDs, device.DeviceID)
+				break
+			}
+		}
+	}
+	return nil
+}
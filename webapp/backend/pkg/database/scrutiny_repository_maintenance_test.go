@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindows_ActiveResolvesMembersAndEnds(t *testing.T) {
+	repo := createNotificationHistoryTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.MaintenanceWindow{}, &models.MDADMArray{}))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, repo.gormClient.Create(&models.MDADMArray{UUID: "uuid-1", Name: "md0", HostID: "nas", Devices: []string{"/dev/sda", "/dev/sdb"}}).Error)
+
+	array := models.MaintenanceWindow{Name: "resilver", ScopeType: models.MaintenanceScopeArray, ScopeID: "uuid-1", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	future := models.MaintenanceWindow{ScopeType: models.MaintenanceScopeHost, ScopeID: "nas", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	require.NoError(t, repo.CreateMaintenanceWindow(ctx, &array))
+	require.NoError(t, repo.CreateMaintenanceWindow(ctx, &future))
+
+	active, err := repo.GetActiveMaintenanceWindows(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, array.ID, active[0].ID)
+	require.Equal(t, "nas", active[0].MemberHostID)
+	require.Equal(t, []string{"/dev/sda", "/dev/sdb"}, active[0].MemberDevices)
+
+	all, err := repo.GetMaintenanceWindows(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	ended, err := repo.EndMaintenanceWindow(ctx, array.ID, now)
+	require.NoError(t, err)
+	require.NotNil(t, ended.EndedAt)
+	_, err = repo.EndMaintenanceWindow(ctx, array.ID, now)
+	require.ErrorIs(t, err, ErrMaintenanceWindowEnded)
+	_, err = repo.GetMaintenanceWindow(ctx, 999)
+	require.ErrorIs(t, err, ErrMaintenanceWindowNotFound)
+
+	active, err = repo.GetActiveMaintenanceWindows(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
+
+func TestMaintenanceWindows_ResolvesByIDPoolMembersToDevices(t *testing.T) {
+	repo := createNotificationHistoryTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.MaintenanceWindow{}, &models.ZFSPool{}, &models.ZFSVdev{}))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, repo.gormClient.Create(&models.ZFSPool{GUID: "tank-guid", Name: "tank", HostID: "nas"}).Error)
+	require.NoError(t, repo.gormClient.Create(&[]models.ZFSVdev{
+		{PoolGUID: "tank-guid", Name: "wwn-0x5000c500a1b2c3d4-part1", Type: models.ZFSVdevTypeDisk, Path: "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1"},
+		{PoolGUID: "tank-guid", Name: "ata-WDC_WD40EFRX_WD-2", Type: models.ZFSVdevTypeDisk, Path: "/dev/disk/by-id/ata-WDC_WD40EFRX_WD-2"},
+	}).Error)
+	require.NoError(t, repo.gormClient.Create(&[]models.Device{
+		{DeviceID: "dev-1", HostId: "nas", DeviceName: "sda", WWN: "0x5000c500a1b2c3d4", SerialNumber: "WD-1"},
+		{DeviceID: "dev-2", HostId: "nas", DeviceName: "sdb", WWN: "0x5000c500deadbeef", SerialNumber: "WD-2"},
+		{DeviceID: "dev-3", HostId: "nas", DeviceName: "sdc", WWN: "0x5000c500cafef00d", SerialNumber: "WD-3"},
+		{DeviceID: "dev-4", HostId: "backup", DeviceName: "sda", WWN: "0x5000c500a1b2c3d4", SerialNumber: "WD-1"},
+	}).Error)
+
+	window := models.MaintenanceWindow{ScopeType: models.MaintenanceScopePool, ScopeID: "tank-guid", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	require.NoError(t, repo.CreateMaintenanceWindow(ctx, &window))
+
+	active, err := repo.GetActiveMaintenanceWindows(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.ElementsMatch(t, []string{"dev-1", "dev-2"}, active[0].MemberDeviceIDs)
+	require.NotNil(t, models.ActiveMaintenanceWindow(active, models.MaintenanceTarget{HostID: "nas", DeviceID: "dev-1", DeviceName: "sda"}, now))
+	require.Nil(t, models.ActiveMaintenanceWindow(active, models.MaintenanceTarget{HostID: "nas", DeviceID: "dev-3", DeviceName: "sdc"}, now))
+}
+
+func TestNotificationHistory_FiltersByMaintenanceWindow(t *testing.T) {
+	repo := createNotificationHistoryTestRepository(t)
+	ctx := context.Background()
+
+	windowID := uint(3)
+	require.NoError(t, repo.SaveNotificationRecord(ctx, &models.NotificationRecord{FailureType: "SmartFailure", Outcome: models.NotificationOutcomeSuppressed, SuppressedBy: models.NotificationSuppressedMaintenance, MaintenanceWindowID: &windowID}))
+	require.NoError(t, repo.SaveNotificationRecord(ctx, &models.NotificationRecord{FailureType: "SmartFailure", Outcome: models.NotificationOutcomeSuppressed, SuppressedBy: models.NotificationSuppressedMute}))
+
+	records, err := repo.GetNotificationHistory(ctx, models.NotificationHistoryFilter{MaintenanceWindowID: windowID})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, models.NotificationSuppressedMaintenance, records[0].SuppressedBy)
+}
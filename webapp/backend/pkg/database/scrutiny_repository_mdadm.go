@@ -60,6 +60,9 @@ func (sr *scrutinyRepository) GetMdadmArrays(ctx context.Context) ([]models.MDAD
 		Find(&arrays).Error; err != nil {
 		return nil, fmt.Errorf("could not get MDADM arrays from DB: %v", err)
 	}
+	if err := sr.attachMdadmArrayTags(ctx, arrays); err != nil {
+		return nil, err
+	}
 	return arrays, nil
 }
 
@@ -69,6 +72,11 @@ func (sr *scrutinyRepository) GetMdadmArrayDetails(ctx context.Context, uuid str
 	if err := sr.gormClient.WithContext(ctx).Where(mdadmUUIDFilter, uuid).First(&array).Error; err != nil {
 		return models.MDADMArray{}, err
 	}
+	tags, err := sr.getResourceTagsOf(ctx, models.TagResourceMdadmArray, array.UUID)
+	if err != nil {
+		return models.MDADMArray{}, err
+	}
+	array.Tags = tags
 	return array, nil
 }
 
@@ -93,26 +101,13 @@ func (sr *scrutinyRepository) DeleteMdadmArray(ctx context.Context, uuid string)
 	if err := sr.gormClient.WithContext(ctx).Where(mdadmUUIDFilter, uuid).Delete(&models.MDADMArray{}).Error; err != nil {
 		return err
 	}
-
-	// Delete data from InfluxDB
-	buckets := []string{
-		sr.appConfig.GetString(cfgInfluxDBBucket),
-		sr.appConfig.GetString(cfgInfluxDBBucket) + "_weekly",
-		sr.appConfig.GetString(cfgInfluxDBBucket) + "_monthly",
-		sr.appConfig.GetString(cfgInfluxDBBucket) + "_yearly",
+	if err := sr.deleteResourceTags(ctx, models.TagResourceMdadmArray, uuid); err != nil {
+		return err
 	}
 
-	for _, bucket := range buckets {
-		if err := sr.influxClient.DeleteAPI().DeleteWithName(
-			ctx,
-			sr.appConfig.GetString(cfgInfluxDBOrg),
-			bucket,
-			time.Now().AddDate(-10, 0, 0),
-			time.Now(),
-			fmt.Sprintf(`array_uuid="%s"`, uuid),
-		); err != nil {
-			return err
-		}
+	// Delete data from the time-series backend
+	if err := sr.timeSeries.DeleteSeries(ctx, "array_uuid", uuid); err != nil {
+		return err
 	}
 
 	return nil
@@ -134,10 +129,10 @@ func (sr *scrutinyRepository) GetMdadmArraysSummary(ctx context.Context) (map[st
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// MDADM Array Metrics (InfluxDB)
+// MDADM Array Metrics (time-series)
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// SaveMdadmMetrics saves MDADM array metrics to InfluxDB
+// SaveMdadmMetrics saves MDADM array metrics to the time-series backend
 func (sr *scrutinyRepository) SaveMdadmMetrics(ctx context.Context, uuid string, metrics collector.MDADMMetrics) error {
 	// Get array name for tagging
 	var array models.MDADMArray
@@ -158,18 +153,12 @@ func (sr *scrutinyRepository) SaveMdadmMetrics(ctx context.Context, uuid string,
 		RawMdstat:      metrics.RawMdstat,
 		ArraySize:      metrics.ArraySize,
 		UsedBytes:      metrics.UsedBytes,
+		MismatchCount:  metrics.MismatchCount,
 	}
 
 	tags, fields := influxMetrics.Flatten()
 
-	return sr.saveDatapoint(
-		sr.influxWriteApi,
-		"mdadm_array",
-		tags,
-		fields,
-		influxMetrics.Date,
-		ctx,
-	)
+	return sr.timeSeries.WritePoint(ctx, "mdadm_array", tags, fields, influxMetrics.Date)
 }
 
 // GetMdadmMetricsHistory retrieves historical metrics for an MDADM array.
@@ -177,34 +166,14 @@ func (sr *scrutinyRepository) SaveMdadmMetrics(ctx context.Context, uuid string,
 // (state, raw_mdstat) that aggregateWindow(fn: last) silently drops.
 // Note: UUID is validated at the handler level before reaching this function.
 func (sr *scrutinyRepository) GetMdadmMetricsHistory(ctx context.Context, uuid string, durationKey string) ([]measurements.MDADMMetrics, error) {
-	bucketName := sr.lookupBucketName(durationKey)
-	duration := sr.lookupDuration(durationKey)
-
-	queryStr := fmt.Sprintf(`
-		import "influxdata/influxdb/schema"
-		from(bucket: "%s")
-		|> range(start: %s, stop: %s)
-		|> filter(fn: (r) => r["_measurement"] == "mdadm_array")
-		|> filter(fn: (r) => r["array_uuid"] == "%s")
-		|> schema.fieldsAsCols()
-		|> group()
-		|> sort(columns: ["_time"], desc: false)
-	`, bucketName, duration[0], duration[1], uuid)
-
-	sr.logger.Debugf("GetMdadmMetricsHistory query for uuid=%s bucket=%s", uuid, bucketName)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryMdadmHistory(ctx, uuid, durationKey)
 	if err != nil {
 		sr.logger.Errorf("GetMdadmMetricsHistory query failed: %v", err)
 		return nil, fmt.Errorf("failed to query MDADM array metrics: %v", err)
 	}
-	defer result.Close()
 
 	var metricsHistory []measurements.MDADMMetrics
-	for result.Next() {
-		record := result.Record()
-		values := record.Values()
-
+	for _, values := range rows {
 		metrics, err := measurements.NewMDADMMetricsFromInfluxDB(values)
 		if err != nil {
 			sr.logger.Warnf("Failed to parse MDADM array metrics: %v", err)
@@ -214,40 +183,25 @@ func (sr *scrutinyRepository) GetMdadmMetricsHistory(ctx context.Context, uuid s
 		metricsHistory = append(metricsHistory, *metrics)
 	}
 
-	sr.logger.Debugf("GetMdadmMetricsHistory returned %d records, resultErr=%v", len(metricsHistory), result.Err())
+	sr.logger.Debugf("GetMdadmMetricsHistory returned %d records", len(metricsHistory))
 
-	return metricsHistory, result.Err()
+	return metricsHistory, nil
 }
 
 // GetLatestMdadmMetrics fetches the single most recent datapoint with all fields preserved.
 // Uses schema.fieldsAsCols() to correctly merge string and numeric fields into a single row.
 // Note: UUID is validated at the handler level before reaching this function.
 func (sr *scrutinyRepository) GetLatestMdadmMetrics(ctx context.Context, uuid string) (*measurements.MDADMMetrics, error) {
-	bucketName := sr.appConfig.GetString(cfgInfluxDBBucket)
-
-	queryStr := fmt.Sprintf(`
-		import "influxdata/influxdb/schema"
-		from(bucket: "%s")
-		|> range(start: -7d)
-		|> filter(fn: (r) => r["_measurement"] == "mdadm_array")
-		|> filter(fn: (r) => r["array_uuid"] == "%s")
-		|> schema.fieldsAsCols()
-		|> group()
-		|> sort(columns: ["_time"], desc: true)
-		|> limit(n: 1)
-	`, bucketName, uuid)
-
 	sr.logger.Debugf("GetLatestMdadmMetrics query for uuid=%s", uuid)
 
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryMdadmLatest(ctx, uuid)
 	if err != nil {
 		sr.logger.Errorf("GetLatestMdadmMetrics query failed: %v", err)
 		return nil, fmt.Errorf("failed to query latest MDADM array metrics: %v", err)
 	}
-	defer result.Close()
 
-	if result.Next() {
-		metrics, err := measurements.NewMDADMMetricsFromInfluxDB(result.Record().Values())
+	if len(rows) > 0 {
+		metrics, err := measurements.NewMDADMMetricsFromInfluxDB(rows[0])
 		if err != nil {
 			return nil, err
 		}
@@ -16,7 +16,7 @@ func createMdadmTestRepository(t *testing.T) *scrutinyRepository {
 
 	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, db.AutoMigrate(&models.MDADMArray{}))
+	require.NoError(t, db.AutoMigrate(&models.MDADMArray{}, &models.ResourceTag{}))
 
 	return &scrutinyRepository{gormClient: db}
 }
@@ -34,6 +34,20 @@ import (
 	m20260608000000 "github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20260608000000"
 	m20260610000000 "github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20260610000000"
 	m20260616000000 "github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20260616000000"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000000"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000001"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000003"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000004"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000005"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000006"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000007"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000008"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000009"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000010"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000011"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000014"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000015"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database/migrations/m20261018000016"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/deviceid"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
@@ -42,6 +56,7 @@ import (
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api/http"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -207,11 +222,10 @@ func (sr *scrutinyRepository) migrateBucketIfNew(ctx context.Context, bucketName
 // migrateWriteDatapoint writes the smart and temperature points for a single datapoint to the named
 // bucket, tolerating past-retention-policy write errors.
 func (sr *scrutinyRepository) migrateWriteDatapoint(ctx context.Context, bucketName string, point migrationDatapoint) error {
-	writeAPI := sr.influxClient.WriteAPIBlocking(sr.appConfig.GetString(cfgInfluxDBOrg), bucketName)
-	if err := sr.saveDatapoint(writeAPI, "smart", point.smartTags, point.smartFields, point.date, ctx); sr.ignorePastRetentionPolicyError(err) != nil {
+	if err := sr.timeSeries.WriteBucketPoint(ctx, bucketName, "smart", point.smartTags, point.smartFields, point.date); sr.ignorePastRetentionPolicyError(err) != nil {
 		return err
 	}
-	if err := sr.saveDatapoint(writeAPI, "temp", point.tempTags, point.tempFields, point.date, ctx); sr.ignorePastRetentionPolicyError(err) != nil {
+	if err := sr.timeSeries.WriteBucketPoint(ctx, bucketName, "temp", point.tempTags, point.tempFields, point.date); sr.ignorePastRetentionPolicyError(err) != nil {
 		return err
 	}
 	return nil
@@ -224,7 +238,59 @@ func (sr *scrutinyRepository) Migrate(ctx context.Context) error {
 	gormMigrateOptions := gormigrate.DefaultOptions
 	gormMigrateOptions.UseTransaction = true
 
-	m := gormigrate.New(sr.gormClient, gormMigrateOptions, []*gormigrate.Migration{
+	m := gormigrate.New(sr.gormClient, gormMigrateOptions, sr.schemaMigrations(ctx))
+
+	if err := m.Migrate(); err != nil {
+		if strings.Contains(err.Error(), "readonly database") ||
+			strings.Contains(err.Error(), "attempt to write") {
+			sr.logger.Errorf("Database migration failed: unable to write to database.\n\n"+
+				"This error commonly occurs in Docker containers with restricted capabilities.\n"+
+				"Solutions:\n"+
+				"1. Check file permissions on the database directory\n"+
+				"2. If using 'cap_drop: [ALL]', add necessary capabilities back\n"+
+				"3. Verify the volume mount has correct ownership\n\n"+
+				"Original error: %v", err)
+		} else {
+			sr.logger.Errorf("Database migration failed with error.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
+		}
+		return err
+	}
+
+	if err := sr.ensureDeviceModelFamilyColumn(); err != nil {
+		sr.logger.Errorf("Database schema verification failed.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
+		return err
+	}
+	sr.logger.Infoln("Database migration completed successfully")
+
+	//these migrations cannot be done within a transaction, so they are done as a separate group, with `UseTransaction = false`
+	sr.logger.Infoln("SQLite global configuration migrations starting. Please wait....")
+	globalMigrateOptions := gormigrate.DefaultOptions
+	globalMigrateOptions.UseTransaction = false
+	gm := gormigrate.New(sr.gormClient, globalMigrateOptions, sr.globalMigrations())
+
+	if err := gm.Migrate(); err != nil {
+		if strings.Contains(err.Error(), "readonly database") ||
+			strings.Contains(err.Error(), "attempt to write") {
+			sr.logger.Errorf("SQLite global configuration migrations failed: unable to write to database.\n\n"+
+				"This error commonly occurs in Docker containers with restricted capabilities.\n"+
+				"Solutions:\n"+
+				"1. Check file permissions on the database directory\n"+
+				"2. If using 'cap_drop: [ALL]', add necessary capabilities back\n"+
+				"3. Verify the volume mount has correct ownership\n\n"+
+				"Original error: %v", err)
+		} else {
+			sr.logger.Errorf("SQLite global configuration migrations failed with error.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
+		}
+		return err
+	}
+	sr.logger.Infoln("SQLite global configuration migrations completed successfully")
+
+	return nil
+}
+
+// schemaMigrations returns the ordered list of schema/data migrations applied by Migrate.
+func (sr *scrutinyRepository) schemaMigrations(ctx context.Context) []*gormigrate.Migration {
+	return []*gormigrate.Migration{
 		{
 			ID: "20201107210306", // v0.3.13 (pre-influxdb schema). 9fac3c6308dc6cb6cd5bbc43a68cd93e8fb20b87
 			Migrate: func(tx *gorm.DB) error {
@@ -613,61 +679,142 @@ func (sr *scrutinyRepository) Migrate(ctx context.Context) error {
 			ID:      "m20260701000000", // add consumer drive profile family denylist setting (#552)
 			Migrate: sr.migrateM20260701000000,
 		},
-	})
+		{
+			ID: "m20261018000000", // add time_series_points table for the embedded SQLite time-series backend
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000000.TimeSeriesPoint{})
+			},
+		},
+		{
+			ID: "m20261018000001", // add device inventory and warranty columns
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000001.Device{})
+			},
+		},
+		{
+			ID:      "m20261018000002", // add warranty expiry notification settings
+			Migrate: sr.migrateM20261018000002,
+		},
+		{
+			ID: "m20261018000003", // add device_replacements table for drive replacement lineage
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000003.DeviceReplacement{})
+			},
+		},
+		{
+			ID:      "m20261018000004", // add hosts and host_check_ins tables, backfilled from existing host_id values
+			Migrate: sr.migrateM20261018000004,
+		},
+		{
+			ID: "m20261018000005", // add resource_tags table for device, pool and array tags
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000005.ResourceTag{})
+			},
+		},
+		{
+			ID: "m20261018000006", // add routing rules to notify_urls table
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000006.NotifyUrl{})
+			},
+		},
+		{
+			ID:      "m20261018000007", // add incidents table, escalation notify URLs and escalation delay setting
+			Migrate: sr.migrateM20261018000007,
+		},
+		{
+			ID: "m20261018000008", // add notification history and persistent quiet hours queue tables
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000008.NotificationRecord{}, &m20261018000008.QueuedNotification{})
+			},
+		},
+		{
+			ID: "m20261018000009", // add maintenance windows and link suppressed notifications to them
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000009.MaintenanceWindow{}, &m20261018000009.NotificationRecord{})
+			},
+		},
+		{
+			ID: "m20261018000010", // add the durable notification retry queue
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000010.NotificationRetry{})
+			},
+		},
+		{
+			ID:      "m20261018000011", // add device lifecycle events table and lifecycle notification setting
+			Migrate: sr.migrateM20261018000011,
+		},
+		{
+			ID:      "m20261018000012", // add opt-in recovery (resolved) notification settings
+			Migrate: sr.migrateM20261018000012,
+		},
+		{
+			ID:      "m20261018000013", // add notification digest settings
+			Migrate: sr.migrateM20261018000013,
+		},
+		{
+			ID: "m20261018000014", // add inode counts to filesystem capacity snapshots
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000014.FilesystemCapacity{})
+			},
+		},
+		{
+			ID: "m20261018000015", // keep the routing of notifications queued during quiet hours
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000015.QueuedNotification{})
+			},
+		},
+		{
+			ID: "m20261018000016", // track the one-time warranty expired notification per device
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&m20261018000016.Device{})
+			},
+		},
+		{
+			ID:      "m20261018000017", // split the ZFS pool recovery setting from the mdadm array one
+			Migrate: sr.migrateM20261018000017,
+		},
+	}
+}
 
-	if err := m.Migrate(); err != nil {
-		if strings.Contains(err.Error(), "readonly database") ||
-			strings.Contains(err.Error(), "attempt to write") {
-			sr.logger.Errorf("Database migration failed: unable to write to database.\n\n"+
-				"This error commonly occurs in Docker containers with restricted capabilities.\n"+
-				"Solutions:\n"+
-				"1. Check file permissions on the database directory\n"+
-				"2. If using 'cap_drop: [ALL]', add necessary capabilities back\n"+
-				"3. Verify the volume mount has correct ownership\n\n"+
-				"Original error: %v", err)
-		} else {
-			sr.logger.Errorf("Database migration failed with error.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
-		}
-		return err
+// KnownMigrationIDs returns the IDs of every migration this build of Scrutiny knows how to apply,
+// in order. Backup restore uses it to reject archives created by a newer schema.
+func KnownMigrationIDs() []string {
+	sr := &scrutinyRepository{}
+	ids := []string{}
+	for _, migration := range sr.schemaMigrations(context.Background()) {
+		ids = append(ids, migration.ID)
+	}
+	for _, migration := range sr.globalMigrations() {
+		ids = append(ids, migration.ID)
 	}
+	return ids
+}
 
-	if err := sr.ensureDeviceModelFamilyColumn(); err != nil {
-		sr.logger.Errorf("Database schema verification failed.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
-		return err
+// SchemaVersion returns the ID of the newest schema migration contained in applied, or "" if none are.
+func SchemaVersion(applied []string) string {
+	appliedSet := map[string]bool{}
+	for _, id := range applied {
+		appliedSet[id] = true
 	}
-	sr.logger.Infoln("Database migration completed successfully")
+	version := ""
+	for _, migration := range (&scrutinyRepository{}).schemaMigrations(context.Background()) {
+		if appliedSet[migration.ID] {
+			version = migration.ID
+		}
+	}
+	return version
+}
 
-	//these migrations cannot be done within a transaction, so they are done as a separate group, with `UseTransaction = false`
-	sr.logger.Infoln("SQLite global configuration migrations starting. Please wait....")
-	globalMigrateOptions := gormigrate.DefaultOptions
-	globalMigrateOptions.UseTransaction = false
-	gm := gormigrate.New(sr.gormClient, globalMigrateOptions, []*gormigrate.Migration{
+// globalMigrations returns the migrations that cannot run inside a transaction.
+func (sr *scrutinyRepository) globalMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
 		{
 			ID: "g20220802211500",
 			Migrate: func(tx *gorm.DB) error {
 				return sr.migrateG20220802211500(tx)
 			},
 		},
-	})
-
-	if err := gm.Migrate(); err != nil {
-		if strings.Contains(err.Error(), "readonly database") ||
-			strings.Contains(err.Error(), "attempt to write") {
-			sr.logger.Errorf("SQLite global configuration migrations failed: unable to write to database.\n\n"+
-				"This error commonly occurs in Docker containers with restricted capabilities.\n"+
-				"Solutions:\n"+
-				"1. Check file permissions on the database directory\n"+
-				"2. If using 'cap_drop: [ALL]', add necessary capabilities back\n"+
-				"3. Verify the volume mount has correct ownership\n\n"+
-				"Original error: %v", err)
-		} else {
-			sr.logger.Errorf("SQLite global configuration migrations failed with error.\nPlease open a github issue at https://github.com/Staros-Labs/scrutiny and attach a copy of your scrutiny.db file.\n%v", err)
-		}
-		return err
 	}
-	sr.logger.Infoln("SQLite global configuration migrations completed successfully")
-
-	return nil
 }
 
 // migrate20220503120000 cleans up legacy GORM SMART tables and migrates the device schema.
@@ -922,6 +1069,9 @@ func (sr *scrutinyRepository) migrateM20260315000000(tx *gorm.DB) error {
 	}
 
 	// Backfill: compute device_id for all existing devices
+	if isPostgres(tx) {
+		return sr.backfillDeviceIDsPostgres(tx)
+	}
 	var devices []struct {
 		RowID        int64 `gorm:"column:row_id"`
 		WWN          string
@@ -952,6 +1102,10 @@ func (sr *scrutinyRepository) migrateM20260401000000(tx *gorm.DB) error {
 		return fmt.Errorf("found %d devices with NULL/empty device_id; run m20260315000000 backfill first", nullCount)
 	}
 
+	if isPostgres(tx) {
+		return sr.migrateM20260401000000Postgres(tx)
+	}
+
 	// Step 1: Create new table with device_id as PRIMARY KEY
 	createSQL := `CREATE TABLE devices_new (
 device_id TEXT PRIMARY KEY,
@@ -1114,6 +1268,14 @@ func (sr *scrutinyRepository) migrateM20260414000000(tx *gorm.DB) error {
 		return fmt.Errorf("failed to purge soft-deleted attribute overrides: %w", err)
 	}
 
+	// PostgreSQL supports DROP COLUMN natively; the unique composite index is left untouched.
+	if isPostgres(tx) {
+		if err := tx.Exec("ALTER TABLE attribute_overrides DROP COLUMN IF EXISTS deleted_at").Error; err != nil {
+			return fmt.Errorf("failed to drop attribute_overrides.deleted_at: %w", err)
+		}
+		return nil
+	}
+
 	// Step 2: Recreate table without deleted_at column (SQLite lacks DROP COLUMN on older versions).
 	createSQL := `CREATE TABLE attribute_overrides_new (
 id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -1163,6 +1325,10 @@ source TEXT DEFAULT ui
 }
 
 func (sr *scrutinyRepository) migrateM20260508000000(tx *gorm.DB) error {
+	if isPostgres(tx) {
+		return sr.migrateM20260508000000Postgres(tx)
+	}
+
 	createSQL := `CREATE TABLE devices_new (
 device_id TEXT PRIMARY KEY,
 wwn TEXT,
@@ -1247,6 +1413,67 @@ missed_ping_timeout_override INTEGER DEFAULT 0
 	return tx.AutoMigrate(&m20260508000000.Device{})
 }
 
+// backfillDeviceIDsPostgres is the PostgreSQL variant of the m20260315000000 backfill. PostgreSQL
+// has no rowid, but wwn is still the primary key of the devices table at this point.
+func (sr *scrutinyRepository) backfillDeviceIDsPostgres(tx *gorm.DB) error {
+	var devices []struct {
+		WWN          string
+		ModelName    string
+		SerialNumber string
+	}
+	if err := tx.Raw("SELECT wwn, model_name, serial_number FROM devices").Scan(&devices).Error; err != nil {
+		return fmt.Errorf("could not query devices for backfill: %w", err)
+	}
+
+	for _, dev := range devices {
+		id := deviceid.Generate(dev.ModelName, dev.SerialNumber, dev.WWN)
+		if err := tx.Exec("UPDATE devices SET device_id = ? WHERE wwn = ?", id, dev.WWN).Error; err != nil {
+			return fmt.Errorf("could not backfill device_id for %s: %w", dev.WWN, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateM20260401000000Postgres moves the devices primary key from wwn to device_id in place,
+// instead of the SQLite table rebuild.
+func (sr *scrutinyRepository) migrateM20260401000000Postgres(tx *gorm.DB) error {
+	statements := []struct {
+		sql  string
+		desc string
+	}{
+		{"ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_pkey", "drop wwn primary key"},
+		{"ALTER TABLE devices ALTER COLUMN wwn DROP NOT NULL", "make wwn nullable"},
+		{"ALTER TABLE devices ADD PRIMARY KEY (device_id)", "add device_id primary key"},
+		{"DROP INDEX IF EXISTS idx_devices_wwn", "drop old wwn index"},
+		{"CREATE UNIQUE INDEX idx_devices_wwn ON devices(wwn) WHERE wwn IS NOT NULL AND wwn != ''", "create wwn unique index"},
+		{"CREATE INDEX IF NOT EXISTS idx_devices_deleted_at ON devices(deleted_at)", "create deleted_at index"},
+	}
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt.sql).Error; err != nil {
+			return fmt.Errorf("failed to %s: %w", stmt.desc, err)
+		}
+	}
+	return nil
+}
+
+// migrateM20260508000000Postgres converts the boolean smart_support column to the JSON
+// SmartSupport payload in place, instead of the SQLite table rebuild.
+func (sr *scrutinyRepository) migrateM20260508000000Postgres(tx *gorm.DB) error {
+	convertSQL := `ALTER TABLE devices ALTER COLUMN smart_support TYPE JSONB USING
+		CASE
+			WHEN smart_support IS NULL THEN '{"available":false}'::jsonb
+			WHEN lower(trim(smart_support::text)) IN ('true', '1', 't') THEN '{"available":true}'::jsonb
+			WHEN lower(trim(smart_support::text)) IN ('false', '0', 'f', '') THEN '{"available":false}'::jsonb
+			ELSE smart_support::text::jsonb
+		END`
+	if err := tx.Exec(convertSQL).Error; err != nil {
+		return fmt.Errorf("failed to convert smart_support column: %w", err)
+	}
+
+	return tx.AutoMigrate(&m20260508000000.Device{})
+}
+
 func (sr *scrutinyRepository) migrateM20260523000000(tx *gorm.DB) error {
 	if tx.Migrator().HasColumn(&m20260523000000.Device{}, "model_family") {
 		return tx.AutoMigrate(&m20260523000000.Device{})
@@ -1318,6 +1545,10 @@ func (sr *scrutinyRepository) migrateM20260609000000(tx *gorm.DB) error {
 
 func (sr *scrutinyRepository) migrateG20220802211500(tx *gorm.DB) error {
 	//shrink the Database (maybe necessary after 20220503113100)
+	//PostgreSQL databases are maintained by autovacuum, and a fresh install has nothing to reclaim.
+	if isPostgres(tx) {
+		return nil
+	}
 	if err := tx.Exec("VACUUM;").Error; err != nil {
 		return err
 	}
@@ -1563,3 +1794,213 @@ func (sr *scrutinyRepository) migrateM20260701000000(tx *gorm.DB) error {
 	}
 	return tx.Create(&defaultSetting).Error
 }
+
+func (sr *scrutinyRepository) migrateM20261018000002(tx *gorm.DB) error {
+	defaultSettings := []m20220716214900.Setting{
+		{
+			SettingKeyName:        "metrics.notify_on_warranty_expiry",
+			SettingKeyDescription: "Send a notification before a device warranty expires (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      true,
+		},
+		{
+			SettingKeyName:        "metrics.warranty_notify_days",
+			SettingKeyDescription: "Number of days before warranty expiry to send the notification",
+			SettingDataType:       "numeric",
+			SettingValueNumeric:   30,
+		},
+	}
+	for _, setting := range defaultSettings {
+		var count int64
+		if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", setting.SettingKeyName).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(&setting).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateM20261018000004 creates the hosts tables and registers every host_id already referenced by
+// devices, pools and filesystems, so existing installs see their hosts before the next check-in.
+func (sr *scrutinyRepository) migrateM20261018000004(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&m20261018000004.Host{}, &m20261018000004.HostCheckIn{}); err != nil {
+		return err
+	}
+
+	hostIDs := map[string]bool{}
+	for _, table := range []string{"devices", "zfs_pools", "mdadm_arrays", "btrfs_filesystems", "filesystem_host_statuses"} {
+		if !tx.Migrator().HasTable(table) {
+			continue
+		}
+		var ids []string
+		if err := tx.Table(table).Where("host_id <> ''").Distinct().Pluck("host_id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			hostIDs[id] = true
+		}
+	}
+
+	for hostID := range hostIDs {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&m20261018000004.Host{HostID: hostID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateM20261018000007 creates the incidents table, adds the escalation flag to notify_urls and
+// seeds the escalation delay setting.
+func (sr *scrutinyRepository) migrateM20261018000007(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&m20261018000007.Incident{}, &m20261018000007.NotifyUrl{}); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", "metrics.incident_escalation_minutes").Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(&m20220716214900.Setting{
+		SettingKeyName:        "metrics.incident_escalation_minutes",
+		SettingKeyDescription: "Minutes an incident may stay unacknowledged before escalation URLs are notified (0 = disabled)",
+		SettingDataType:       "numeric",
+		SettingValueNumeric:   60,
+	}).Error
+}
+
+// migrateM20261018000011 creates the device lifecycle events table and seeds the lifecycle
+// notification setting.
+func (sr *scrutinyRepository) migrateM20261018000011(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&m20261018000011.DeviceLifecycleEvent{}); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", "metrics.notify_on_lifecycle_events").Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(&m20220716214900.Setting{
+		SettingKeyName:        "metrics.notify_on_lifecycle_events",
+		SettingKeyDescription: "Notify when a device is added, disappears from a host that keeps checking in, reappears or is swapped (true | false)",
+		SettingDataType:       "bool",
+		SettingValueBool:      true,
+	}).Error
+}
+
+// migrateM20261018000012 seeds the recovery notification settings, all disabled so upgrades keep
+// their current notifications.
+func (sr *scrutinyRepository) migrateM20261018000012(tx *gorm.DB) error {
+	defaultSettings := []m20220716214900.Setting{
+		{
+			SettingKeyName:        "metrics.notify_on_device_recovered",
+			SettingKeyDescription: "Notify when a failed device returns to passed, on new data or a status reset (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      false,
+		},
+		{
+			SettingKeyName:        "metrics.notify_on_missed_ping_recovered",
+			SettingKeyDescription: "Notify when a device reports again after a missed ping notification (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      false,
+		},
+		{
+			SettingKeyName:        "metrics.notify_on_array_recovered",
+			SettingKeyDescription: "Notify when a degraded mdadm array or ZFS pool recovers, e.g. after a rebuild (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      false,
+		},
+		{
+			SettingKeyName:        "metrics.notify_on_collector_error_cleared",
+			SettingKeyDescription: "Notify when a device uploads SMART data again after a collector error notification (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      false,
+		},
+	}
+	for _, setting := range defaultSettings {
+		var count int64
+		if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", setting.SettingKeyName).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(&setting).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateM20261018000017 seeds metrics.notify_on_zfs_pool_recovered with the current value of
+// metrics.notify_on_array_recovered, which covered ZFS pools until now, so upgrades keep their
+// notifications.
+func (sr *scrutinyRepository) migrateM20261018000017(tx *gorm.DB) error {
+	var arraySetting m20220716214900.Setting
+	enabled := false
+	err := tx.Where("setting_key_name = ?", "metrics.notify_on_array_recovered").First(&arraySetting).Error
+	if err == nil {
+		enabled = arraySetting.SettingValueBool
+		if err := tx.Model(&arraySetting).Update("setting_key_description", "Notify when a degraded mdadm array recovers, e.g. after a rebuild (true | false)").Error; err != nil {
+			return err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", "metrics.notify_on_zfs_pool_recovered").Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(&m20220716214900.Setting{
+		SettingKeyName:        "metrics.notify_on_zfs_pool_recovered",
+		SettingKeyDescription: "Notify when a ZFS pool is ONLINE again, e.g. after a resilver (true | false)",
+		SettingDataType:       "bool",
+		SettingValueBool:      enabled,
+	}).Error
+}
+
+// migrateM20261018000013 seeds the notification digest settings. The digest window defaults to 0
+// (disabled) and critical notifications bypass the digest once it is enabled.
+func (sr *scrutinyRepository) migrateM20261018000013(tx *gorm.DB) error {
+	defaultSettings := []m20220716214900.Setting{
+		{
+			SettingKeyName:        "metrics.notification_digest_minutes",
+			SettingKeyDescription: "Buffer notifications for this many minutes and send them as one digest per host and severity (0 disables digests)",
+			SettingDataType:       "numeric",
+			SettingValueNumeric:   0,
+		},
+		{
+			SettingKeyName:        "metrics.notification_digest_bypass_critical",
+			SettingKeyDescription: "Send critical notifications, e.g. SMART failures, immediately instead of buffering them for a digest (true | false)",
+			SettingDataType:       "bool",
+			SettingValueBool:      true,
+		},
+	}
+	for _, setting := range defaultSettings {
+		var count int64
+		if err := tx.Model(&m20220716214900.Setting{}).Where("setting_key_name = ?", setting.SettingKeyName).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(&setting).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
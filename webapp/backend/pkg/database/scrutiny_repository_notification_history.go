@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+const (
+	// notificationHistoryRetention is how long notification attempts are kept
+	notificationHistoryRetention = 90 * 24 * time.Hour
+
+	defaultNotificationHistoryLimit = 100
+	maxNotificationHistoryLimit     = 1000
+)
+
+// SaveNotificationRecord stores a notification attempt and prunes records older than the
+// retention window.
+func (sr *scrutinyRepository) SaveNotificationRecord(ctx context.Context, record *models.NotificationRecord) error {
+	if err := sr.gormClient.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("could not save notification record: %w", err)
+	}
+	cutoff := time.Now().Add(-notificationHistoryRetention)
+	if err := sr.gormClient.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&models.NotificationRecord{}).Error; err != nil {
+		sr.logger.Warnf("Failed to prune notification history: %v", err)
+	}
+	return nil
+}
+
+// GetNotificationHistory returns notification attempts matching the filter, newest first.
+func (sr *scrutinyRepository) GetNotificationHistory(ctx context.Context, filter models.NotificationHistoryFilter) ([]models.NotificationRecord, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultNotificationHistoryLimit
+	}
+	if limit > maxNotificationHistoryLimit {
+		limit = maxNotificationHistoryLimit
+	}
+
+	query := sr.gormClient.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit).Offset(filter.Offset)
+	if filter.FailureType != "" {
+		query = query.Where("failure_type = ?", filter.FailureType)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.HostID != "" {
+		query = query.Where("host_id = ?", filter.HostID)
+	}
+	if filter.MaintenanceWindowID != 0 {
+		query = query.Where("maintenance_window_id = ?", filter.MaintenanceWindowID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	records := []models.NotificationRecord{}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("could not get notification history from DB: %w", err)
+	}
+	return records, nil
+}
+
+// EnqueueQuietNotification persists a notification deferred during quiet hours.
+func (sr *scrutinyRepository) EnqueueQuietNotification(ctx context.Context, queued *models.QueuedNotification) error {
+	return sr.gormClient.WithContext(ctx).Create(queued).Error
+}
+
+// GetQuietNotifications returns the persisted quiet hours queue, oldest first.
+func (sr *scrutinyRepository) GetQuietNotifications(ctx context.Context) ([]models.QueuedNotification, error) {
+	queued := []models.QueuedNotification{}
+	if err := sr.gormClient.WithContext(ctx).Order("queued_at ASC, id ASC").Find(&queued).Error; err != nil {
+		return nil, fmt.Errorf("could not get quiet hours queue from DB: %w", err)
+	}
+	return queued, nil
+}
+
+// DeleteQuietNotifications removes delivered (or discarded) entries from the quiet hours queue.
+func (sr *scrutinyRepository) DeleteQuietNotifications(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return sr.gormClient.WithContext(ctx).Where("id IN ?", ids).Delete(&models.QueuedNotification{}).Error
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func createNotificationHistoryTestRepository(t *testing.T) *scrutinyRepository {
+	t.Helper()
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.NotificationRecord{}, &models.QueuedNotification{}))
+	return repo
+}
+
+func TestNotificationHistory_FiltersAndPrunes(t *testing.T) {
+	repo := createNotificationHistoryTestRepository(t)
+	ctx := context.Background()
+
+	stale := models.NotificationRecord{CreatedAt: time.Now().Add(-notificationHistoryRetention - time.Hour), FailureType: "SmartFailure", Outcome: models.NotificationOutcomeSent}
+	require.NoError(t, repo.gormClient.Create(&stale).Error)
+
+	require.NoError(t, repo.SaveNotificationRecord(ctx, &models.NotificationRecord{
+		FailureType: "SmartFailure",
+		HostID:      "nas",
+		Subject:     "disk failed",
+		Outcome:     models.NotificationOutcomePartial,
+		Targets: []models.NotificationTargetResult{
+			{URL: "discord://***@123", Success: true},
+			{URL: "https://hooks.example.com/***", Error: "connection refused"},
+		},
+	}))
+	require.NoError(t, repo.SaveNotificationRecord(ctx, &models.NotificationRecord{
+		FailureType:  "MissedPing",
+		Outcome:      models.NotificationOutcomeSuppressed,
+		SuppressedBy: models.NotificationSuppressedQuietHours,
+	}))
+
+	all, err := repo.GetNotificationHistory(ctx, models.NotificationHistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 2, "records past the retention window are pruned")
+	require.Equal(t, "MissedPing", all[0].FailureType)
+
+	partial, err := repo.GetNotificationHistory(ctx, models.NotificationHistoryFilter{HostID: "nas", FailureType: "SmartFailure"})
+	require.NoError(t, err)
+	require.Len(t, partial, 1)
+	require.Len(t, partial[0].Targets, 2)
+	require.Equal(t, "connection refused", partial[0].Targets[1].Error)
+
+	suppressed, err := repo.GetNotificationHistory(ctx, models.NotificationHistoryFilter{Outcome: models.NotificationOutcomeSuppressed, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, suppressed, 1)
+	require.Equal(t, models.NotificationSuppressedQuietHours, suppressed[0].SuppressedBy)
+}
+
+func TestQuietNotificationQueue(t *testing.T) {
+	repo := createNotificationHistoryTestRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	second := models.QueuedNotification{QueuedAt: now, Subject: "second"}
+	first := models.QueuedNotification{QueuedAt: now.Add(-time.Minute), Subject: "first"}
+	require.NoError(t, repo.EnqueueQuietNotification(ctx, &second))
+	require.NoError(t, repo.EnqueueQuietNotification(ctx, &first))
+
+	queued, err := repo.GetQuietNotifications(ctx)
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	require.Equal(t, "first", queued[0].Subject)
+
+	require.NoError(t, repo.DeleteQuietNotifications(ctx, []uint{first.ID}))
+	require.NoError(t, repo.DeleteQuietNotifications(ctx, nil))
+	queued, err = repo.GetQuietNotifications(ctx)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	require.Equal(t, "second", queued[0].Subject)
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+// dead letters are kept as long as the notification history
+const notificationDeadLetterRetention = notificationHistoryRetention
+
+// EnqueueNotificationRetry persists a notification that could not be delivered to one target.
+func (sr *scrutinyRepository) EnqueueNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	if retry.Status == "" {
+		retry.Status = models.NotificationRetryPending
+	}
+	if err := sr.gormClient.WithContext(ctx).Create(retry).Error; err != nil {
+		return fmt.Errorf("could not queue notification retry: %w", err)
+	}
+	return nil
+}
+
+// GetDueNotificationRetries returns pending retries whose next attempt is due at now, oldest first.
+func (sr *scrutinyRepository) GetDueNotificationRetries(ctx context.Context, now time.Time, limit int) ([]models.NotificationRetry, error) {
+	retries := []models.NotificationRetry{}
+	query := sr.gormClient.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.NotificationRetryPending, now).
+		Order("next_attempt_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&retries).Error; err != nil {
+		return nil, fmt.Errorf("could not get notification retries from DB: %w", err)
+	}
+	return retries, nil
+}
+
+// GetNotificationRetries returns the retry queue, optionally filtered by status, newest first.
+func (sr *scrutinyRepository) GetNotificationRetries(ctx context.Context, status string) ([]models.NotificationRetry, error) {
+	retries := []models.NotificationRetry{}
+	query := sr.gormClient.WithContext(ctx).Order("created_at DESC, id DESC").Limit(maxNotificationHistoryLimit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&retries).Error; err != nil {
+		return nil, fmt.Errorf("could not get notification retries from DB: %w", err)
+	}
+	return retries, nil
+}
+
+// UpdateNotificationRetry saves the attempt count, schedule and status of a retry. Dead letters
+// older than the retention window are pruned.
+func (sr *scrutinyRepository) UpdateNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	if err := sr.gormClient.WithContext(ctx).Model(retry).Select("attempts", "next_attempt_at", "last_error", "status", "dead_at").Updates(retry).Error; err != nil {
+		return fmt.Errorf("could not update notification retry: %w", err)
+	}
+	if retry.Status == models.NotificationRetryDead {
+		cutoff := time.Now().Add(-notificationDeadLetterRetention)
+		if err := sr.gormClient.WithContext(ctx).
+			Where("status = ? AND dead_at < ?", models.NotificationRetryDead, cutoff).
+			Delete(&models.NotificationRetry{}).Error; err != nil {
+			sr.logger.Warnf("Failed to prune notification dead letters: %v", err)
+		}
+	}
+	return nil
+}
+
+// DeleteNotificationRetry removes a delivered retry from the queue.
+func (sr *scrutinyRepository) DeleteNotificationRetry(ctx context.Context, id uint) error {
+	return sr.gormClient.WithContext(ctx).Delete(&models.NotificationRetry{}, id).Error
+}
+
+// CountNotificationRetries returns the number of pending retries and dead letters.
+func (sr *scrutinyRepository) CountNotificationRetries(ctx context.Context) (int64, int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := sr.gormClient.WithContext(ctx).Model(&models.NotificationRetry{}).
+		Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return 0, 0, fmt.Errorf("could not count notification retries: %w", err)
+	}
+	var pending, dead int64
+	for _, row := range rows {
+		switch row.Status {
+		case models.NotificationRetryPending:
+			pending = row.Count
+		case models.NotificationRetryDead:
+			dead = row.Count
+		}
+	}
+	return pending, dead, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func createNotificationRetryTestRepository(t *testing.T) *scrutinyRepository {
+	t.Helper()
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.NotificationRetry{}))
+	return repo
+}
+
+func TestNotificationRetryQueue(t *testing.T) {
+	repo := createNotificationRetryTestRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	due := models.NotificationRetry{URL: "https://hooks.example.com/a", Subject: "due", NextAttemptAt: now.Add(-time.Minute)}
+	later := models.NotificationRetry{URL: "https://hooks.example.com/b", Subject: "later", NextAttemptAt: now.Add(time.Hour)}
+	require.NoError(t, repo.EnqueueNotificationRetry(ctx, &due))
+	require.NoError(t, repo.EnqueueNotificationRetry(ctx, &later))
+	require.Equal(t, models.NotificationRetryPending, due.Status)
+
+	retries, err := repo.GetDueNotificationRetries(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	require.Equal(t, "due", retries[0].Subject)
+	require.Equal(t, "https://hooks.example.com/a", retries[0].URL)
+
+	// dead letters are no longer due, but still counted
+	deadAt := now
+	retries[0].Attempts = 5
+	retries[0].Status = models.NotificationRetryDead
+	retries[0].DeadAt = &deadAt
+	retries[0].LastError = "connection refused"
+	require.NoError(t, repo.UpdateNotificationRetry(ctx, &retries[0]))
+
+	retries, err = repo.GetDueNotificationRetries(ctx, now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	require.Equal(t, "later", retries[0].Subject)
+
+	pending, dead, err := repo.CountNotificationRetries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pending)
+	require.Equal(t, int64(1), dead)
+
+	deadLetters, err := repo.GetNotificationRetries(ctx, models.NotificationRetryDead)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, 5, deadLetters[0].Attempts)
+	require.Equal(t, "connection refused", deadLetters[0].LastError)
+
+	require.NoError(t, repo.DeleteNotificationRetry(ctx, later.ID))
+	pending, dead, err = repo.CountNotificationRetries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), pending)
+	require.Equal(t, int64(1), dead)
+}
+
+func TestNotificationRetryQueue_PrunesOldDeadLetters(t *testing.T) {
+	repo := createNotificationRetryTestRepository(t)
+	ctx := context.Background()
+
+	staleAt := time.Now().Add(-notificationDeadLetterRetention - time.Hour)
+	stale := models.NotificationRetry{Status: models.NotificationRetryDead, DeadAt: &staleAt}
+	require.NoError(t, repo.gormClient.Create(&stale).Error)
+
+	now := time.Now()
+	fresh := models.NotificationRetry{NextAttemptAt: now}
+	require.NoError(t, repo.EnqueueNotificationRetry(ctx, &fresh))
+	fresh.Status = models.NotificationRetryDead
+	fresh.DeadAt = &now
+	require.NoError(t, repo.UpdateNotificationRetry(ctx, &fresh))
+
+	deadLetters, err := repo.GetNotificationRetries(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, fresh.ID, deadLetters[0].ID)
+}
@@ -41,3 +41,33 @@ func (sr *scrutinyRepository) UpdateNotifyUrlHeartbeat(ctx context.Context, id u
 	}
 	return nil
 }
+
+// UpdateNotifyUrlRules replaces the routing rules of a notification URL
+func (sr *scrutinyRepository) UpdateNotifyUrlRules(ctx context.Context, id uint, rules []models.NotifyRoutingRule) error {
+	result := sr.gormClient.WithContext(ctx).
+		Model(&models.NotifyUrl{ID: id}).
+		Select("rules").
+		Updates(&models.NotifyUrl{Rules: rules})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateNotifyUrlEscalation updates the escalation flag for a notification URL
+func (sr *scrutinyRepository) UpdateNotifyUrlEscalation(ctx context.Context, id uint, escalation bool) error {
+	result := sr.gormClient.WithContext(ctx).
+		Model(&models.NotifyUrl{}).
+		Where("id = ?", id).
+		Update("escalation", escalation)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUpdateNotifyUrlRules_RoundTrip(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	require.NoError(t, repo.gormClient.AutoMigrate(&models.NotifyUrl{}))
+	ctx := context.Background()
+
+	entry := &models.NotifyUrl{URL: "pagerduty://token", HeartbeatEnabled: true}
+	require.NoError(t, repo.SaveNotifyUrl(ctx, entry))
+
+	rules := []models.NotifyRoutingRule{
+		{FailureTypes: []string{"SmartFailure"}, HostIDs: []string{"nas"}, MinSeverity: models.NotifySeverityCritical},
+	}
+	require.NoError(t, repo.UpdateNotifyUrlRules(ctx, entry.ID, rules))
+	require.ErrorIs(t, repo.UpdateNotifyUrlRules(ctx, entry.ID+1, rules), gorm.ErrRecordNotFound)
+
+	urls, err := repo.GetNotifyUrls(ctx)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Equal(t, rules, urls[0].Rules)
+	require.True(t, urls[0].HeartbeatEnabled)
+
+	// clearing the rules routes every notification to the URL again
+	require.NoError(t, repo.UpdateNotifyUrlRules(ctx, entry.ID, nil))
+	urls, err = repo.GetNotifyUrls(ctx)
+	require.NoError(t, err)
+	require.Empty(t, urls[0].Rules)
+}
@@ -8,14 +8,14 @@ import (
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Performance Benchmarks (InfluxDB)
+// Performance Benchmarks (time-series)
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// SavePerformanceResults saves performance benchmark results to InfluxDB
+// SavePerformanceResults saves performance benchmark results to the time-series backend
 func (sr *scrutinyRepository) SavePerformanceResults(ctx context.Context, wwn string, perfData *measurements.Performance) error {
 	perfData.DeviceWWN = wwn
 
-	// Look up DeviceID for dual-tagging in InfluxDB
+	// Look up DeviceID for dual-tagging in the time-series backend
 	device, devErr := sr.GetDeviceDetails(ctx, wwn)
 	if devErr == nil {
 		perfData.DeviceID = device.DeviceID
@@ -23,42 +23,18 @@ func (sr *scrutinyRepository) SavePerformanceResults(ctx context.Context, wwn st
 
 	tags, fields := perfData.Flatten()
 
-	return sr.saveDatapoint(
-		sr.influxWriteApi,
-		"performance",
-		tags,
-		fields,
-		perfData.Date,
-		ctx,
-	)
+	return sr.timeSeries.WritePoint(ctx, "performance", tags, fields, perfData.Date)
 }
 
 // GetPerformanceHistory retrieves historical performance metrics for a device
 func (sr *scrutinyRepository) GetPerformanceHistory(ctx context.Context, wwn string, durationKey string) ([]measurements.Performance, error) {
-	bucketName := sr.lookupBucketName(durationKey)
-	duration := sr.lookupDuration(durationKey)
-
-	queryStr := fmt.Sprintf(`
-		from(bucket: "%s")
-		|> range(start: %s, stop: %s)
-		|> filter(fn: (r) => r["_measurement"] == "performance")
-		|> filter(fn: (r) => r["device_wwn"] == "%s")
-		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
-		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-		|> sort(columns: ["_time"], desc: false)
-	`, bucketName, duration[0], duration[1], wwn)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryPerformanceHistory(ctx, wwn, durationKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query performance metrics: %v", err)
 	}
-	defer result.Close()
 
 	var history []measurements.Performance
-	for result.Next() {
-		record := result.Record()
-		values := record.Values()
-
+	for _, values := range rows {
 		perf, err := measurements.NewPerformanceFromInfluxDB(values)
 		if err != nil {
 			sr.logger.Warnf("Failed to parse performance metrics: %v", err)
@@ -68,38 +44,31 @@ func (sr *scrutinyRepository) GetPerformanceHistory(ctx context.Context, wwn str
 		history = append(history, *perf)
 	}
 
-	if result.Err() != nil {
-		return nil, fmt.Errorf("query error: %v", result.Err())
-	}
-
 	return history, nil
 }
 
+// StreamPerformanceHistory passes each stored benchmark result within durationKey to fn, oldest first.
+// Rows that cannot be parsed are skipped, matching GetPerformanceHistory.
+func (sr *scrutinyRepository) StreamPerformanceHistory(ctx context.Context, wwn string, durationKey string, fn func(perf measurements.Performance) error) error {
+	return sr.timeSeries.StreamDeviceSeries(ctx, "performance", wwn, durationKey, func(values timeSeriesRow) error {
+		perf, err := measurements.NewPerformanceFromInfluxDB(values)
+		if err != nil {
+			sr.logger.Warnf("Failed to parse performance metrics: %v", err)
+			return nil
+		}
+		return fn(*perf)
+	})
+}
+
 // GetPerformanceBaseline calculates a baseline from the last N performance results
 func (sr *scrutinyRepository) GetPerformanceBaseline(ctx context.Context, wwn string, count int) (*measurements.PerformanceBaseline, error) {
-	bucketName := sr.appConfig.GetString(cfgInfluxDBBucket)
-
-	queryStr := fmt.Sprintf(`
-		from(bucket: "%s")
-		|> range(start: -30d)
-		|> filter(fn: (r) => r["_measurement"] == "performance")
-		|> filter(fn: (r) => r["device_wwn"] == "%s")
-		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-		|> sort(columns: ["_time"], desc: true)
-		|> limit(n: %d)
-	`, bucketName, wwn, count)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryPerformanceRecent(ctx, wwn, count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query performance baseline: %v", err)
 	}
-	defer result.Close()
 
 	var results []measurements.Performance
-	for result.Next() {
-		record := result.Record()
-		values := record.Values()
-
+	for _, values := range rows {
 		perf, err := measurements.NewPerformanceFromInfluxDB(values)
 		if err != nil {
 			sr.logger.Warnf("Failed to parse performance baseline: %v", err)
@@ -109,10 +78,6 @@ func (sr *scrutinyRepository) GetPerformanceBaseline(ctx context.Context, wwn st
 		results = append(results, *perf)
 	}
 
-	if result.Err() != nil {
-		return nil, fmt.Errorf("query error: %v", result.Err())
-	}
-
 	if len(results) == 0 {
 		return nil, nil
 	}
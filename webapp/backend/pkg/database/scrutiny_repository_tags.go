@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const queryResourceTag = "resource_type = ? AND resource_id = ?"
+
+// SetResourceTags replaces the tags of a device, ZFS pool, mdadm array or Btrfs filesystem and
+// returns the normalized tag list that was stored.
+func (sr *scrutinyRepository) SetResourceTags(ctx context.Context, resourceType string, resourceID string, tags []string) ([]string, error) {
+	if !models.ValidTagResourceType(resourceType) {
+		return nil, fmt.Errorf("unknown tag resource type %q", resourceType)
+	}
+	normalized, err := models.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sr.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(queryResourceTag, resourceType, resourceID).Delete(&models.ResourceTag{}).Error; err != nil {
+			return err
+		}
+		if len(normalized) == 0 {
+			return nil
+		}
+		now := time.Now().UTC()
+		rows := make([]models.ResourceTag, 0, len(normalized))
+		for _, tag := range normalized {
+			rows = append(rows, models.ResourceTag{CreatedAt: now, ResourceType: resourceType, ResourceID: resourceID, Tag: tag})
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not save tags: %w", err)
+	}
+	return normalized, nil
+}
+
+// GetResourceTags returns the sorted tags of every resource of the given type, keyed by resource ID.
+func (sr *scrutinyRepository) GetResourceTags(ctx context.Context, resourceType string) (map[string][]string, error) {
+	rows := []models.ResourceTag{}
+	if err := sr.gormClient.WithContext(ctx).Where("resource_type = ?", resourceType).Order("resource_id ASC, tag ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not get tags from DB: %w", err)
+	}
+	tags := make(map[string][]string)
+	for _, row := range rows {
+		tags[row.ResourceID] = append(tags[row.ResourceID], row.Tag)
+	}
+	return tags, nil
+}
+
+// GetTags returns every tag in use with per-resource-type counts, ordered by tag.
+func (sr *scrutinyRepository) GetTags(ctx context.Context) ([]models.TagCount, error) {
+	rows := []models.ResourceTag{}
+	if err := sr.gormClient.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not get tags from DB: %w", err)
+	}
+	counts := map[string]*models.TagCount{}
+	for _, row := range rows {
+		count := counts[row.Tag]
+		if count == nil {
+			count = &models.TagCount{Tag: row.Tag, ResourceCounts: map[string]int{}}
+			counts[row.Tag] = count
+		}
+		count.Count++
+		count.ResourceCounts[row.ResourceType]++
+	}
+	result := make([]models.TagCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tag < result[j].Tag })
+	return result, nil
+}
+
+func (sr *scrutinyRepository) deleteResourceTags(ctx context.Context, resourceType string, resourceID string) error {
+	return sr.gormClient.WithContext(ctx).Where(queryResourceTag, resourceType, resourceID).Delete(&models.ResourceTag{}).Error
+}
+
+// attachResourceTags loads the tags of resourceType and passes each resource's tags to set.
+func (sr *scrutinyRepository) attachResourceTags(ctx context.Context, resourceType string, count int, resourceID func(i int) string, set func(i int, tags []string)) error {
+	if count == 0 {
+		return nil
+	}
+	tags, err := sr.GetResourceTags(ctx, resourceType)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		set(i, tags[resourceID(i)])
+	}
+	return nil
+}
+
+func (sr *scrutinyRepository) attachDeviceTags(ctx context.Context, devices []models.Device) error {
+	return sr.attachResourceTags(ctx, models.TagResourceDevice, len(devices),
+		func(i int) string { return devices[i].DeviceID },
+		func(i int, tags []string) { devices[i].Tags = tags })
+}
+
+func (sr *scrutinyRepository) attachZFSPoolTags(ctx context.Context, pools []models.ZFSPool) error {
+	return sr.attachResourceTags(ctx, models.TagResourceZFSPool, len(pools),
+		func(i int) string { return pools[i].GUID },
+		func(i int, tags []string) { pools[i].Tags = tags })
+}
+
+func (sr *scrutinyRepository) attachMdadmArrayTags(ctx context.Context, arrays []models.MDADMArray) error {
+	return sr.attachResourceTags(ctx, models.TagResourceMdadmArray, len(arrays),
+		func(i int) string { return arrays[i].UUID },
+		func(i int, tags []string) { arrays[i].Tags = tags })
+}
+
+func (sr *scrutinyRepository) attachBtrfsFilesystemTags(ctx context.Context, filesystems []models.BtrfsFilesystem) error {
+	return sr.attachResourceTags(ctx, models.TagResourceBtrfsFilesystem, len(filesystems),
+		func(i int) string { return filesystems[i].UUID },
+		func(i int, tags []string) { filesystems[i].Tags = tags })
+}
+
+func (sr *scrutinyRepository) getResourceTagsOf(ctx context.Context, resourceType string, resourceID string) ([]string, error) {
+	tags := []string{}
+	if err := sr.gormClient.WithContext(ctx).Model(&models.ResourceTag{}).Where(queryResourceTag, resourceType, resourceID).Order("tag ASC").Pluck("tag", &tags).Error; err != nil {
+		return nil, fmt.Errorf("could not get tags from DB: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// copyResourceTags adds the tags of one resource to another, keeping the tags the target already has.
+func copyResourceTags(tx *gorm.DB, resourceType string, fromID string, toID string) error {
+	rows := []models.ResourceTag{}
+	if err := tx.Where(queryResourceTag, resourceType, fromID).Find(&rows).Error; err != nil {
+		return fmt.Errorf("could not get tags from DB: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for i := range rows {
+		rows[i].ResourceID = toID
+		rows[i].CreatedAt = now
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return fmt.Errorf("could not copy tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetResourceTags_NormalizesAndReplaces(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+
+	tags, err := repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", []string{" Backup ", "critical", "backup"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup", "critical"}, tags)
+
+	tags, err = repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", []string{"offsite"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"offsite"}, tags)
+
+	_, err = repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", []string{"a,b"})
+	require.Error(t, err)
+	_, err = repo.SetResourceTags(ctx, "volume", "sda", []string{"backup"})
+	require.Error(t, err)
+
+	byDevice, err := repo.GetResourceTags(ctx, models.TagResourceDevice)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"sda": {"offsite"}}, byDevice)
+
+	// an empty list removes every tag
+	tags, err = repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", nil)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+	byDevice, err = repo.GetResourceTags(ctx, models.TagResourceDevice)
+	require.NoError(t, err)
+	require.Empty(t, byDevice)
+}
+
+func TestGetTags_CountsPerResourceType(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", []string{"backup", "critical"})
+	require.NoError(t, err)
+	_, err = repo.SetResourceTags(ctx, models.TagResourceDevice, "sdb", []string{"backup"})
+	require.NoError(t, err)
+	_, err = repo.SetResourceTags(ctx, models.TagResourceZFSPool, "pool-1", []string{"backup"})
+	require.NoError(t, err)
+
+	tags, err := repo.GetTags(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.TagCount{
+		{Tag: "backup", Count: 3, ResourceCounts: map[string]int{models.TagResourceDevice: 2, models.TagResourceZFSPool: 1}},
+		{Tag: "critical", Count: 1, ResourceCounts: map[string]int{models.TagResourceDevice: 1}},
+	}, tags)
+}
+
+func TestDeviceTags_AttachedAndCleanedUp(t *testing.T) {
+	repo := createDeviceRegisterTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sda"}).Error)
+	require.NoError(t, repo.gormClient.Create(&models.Device{DeviceID: "sdb"}).Error)
+	_, err := repo.SetResourceTags(ctx, models.TagResourceDevice, "sda", []string{"backup"})
+	require.NoError(t, err)
+	_, err = repo.SetResourceTags(ctx, models.TagResourceDevice, "sdb", []string{"scratch"})
+	require.NoError(t, err)
+
+	// copying keeps the tags the target already has
+	require.NoError(t, copyResourceTags(repo.gormClient, models.TagResourceDevice, "sda", "sdb"))
+	require.NoError(t, copyResourceTags(repo.gormClient, models.TagResourceDevice, "sda", "sdb"))
+
+	device, err := repo.GetDeviceDetails(ctx, "sdb")
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup", "scratch"}, device.Tags)
+
+	require.NoError(t, repo.DeleteDevice(ctx, "sdb"))
+	byDevice, err := repo.GetResourceTags(ctx, models.TagResourceDevice)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"sda": {"backup"}}, byDevice)
+}
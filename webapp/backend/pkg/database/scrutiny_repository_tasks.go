@@ -3,31 +3,32 @@ package database
 import (
 	"context"
 	"fmt"
+
 	"github.com/influxdata/influxdb-client-go/v2/api"
 )
 
 // //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Tasks
+// Tasks (InfluxDB down-sampling; see timeseries_sqlite_downsample.go for the embedded equivalent)
 // //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-func (sr *scrutinyRepository) EnsureTasks(ctx context.Context, orgID string) error {
+func (is *influxTimeSeriesStore) EnsureTasks(ctx context.Context, orgID string) error {
 	weeklyTaskName := "tsk-weekly-aggr"
 	// weekly on Sunday at 1:00am
-	weeklyTaskScript := sr.DownsampleScript("weekly", weeklyTaskName, "0 1 * * 0")
-	if err := sr.ensureDownsampleTask(ctx, orgID, weeklyTaskName, weeklyTaskScript, "weekly"); err != nil {
+	weeklyTaskScript := is.DownsampleScript("weekly", weeklyTaskName, "0 1 * * 0")
+	if err := is.ensureDownsampleTask(ctx, orgID, weeklyTaskName, weeklyTaskScript, "weekly"); err != nil {
 		return err
 	}
 
 	monthlyTaskName := "tsk-monthly-aggr"
 	// monthly on first day of the month at 1:30am
-	monthlyTaskScript := sr.DownsampleScript("monthly", monthlyTaskName, "30 1 1 * *")
-	if err := sr.ensureDownsampleTask(ctx, orgID, monthlyTaskName, monthlyTaskScript, "monthly"); err != nil {
+	monthlyTaskScript := is.DownsampleScript("monthly", monthlyTaskName, "30 1 1 * *")
+	if err := is.ensureDownsampleTask(ctx, orgID, monthlyTaskName, monthlyTaskScript, "monthly"); err != nil {
 		return err
 	}
 
 	yearlyTaskName := "tsk-yearly-aggr"
 	// yearly on the first day of the year at 2:00am
-	yearlyTaskScript := sr.DownsampleScript("yearly", yearlyTaskName, "0 2 1 1 *")
-	if err := sr.ensureDownsampleTask(ctx, orgID, yearlyTaskName, yearlyTaskScript, "yearly"); err != nil {
+	yearlyTaskScript := is.DownsampleScript("yearly", yearlyTaskName, "0 2 1 1 *")
+	if err := is.ensureDownsampleTask(ctx, orgID, yearlyTaskName, yearlyTaskScript, "yearly"); err != nil {
 		return err
 	}
 	return nil
@@ -35,25 +36,25 @@ func (sr *scrutinyRepository) EnsureTasks(ctx context.Context, orgID string) err
 
 // ensureDownsampleTask creates the named downsample task when it does not exist,
 // or updates its flux script when the single existing task differs.
-func (sr *scrutinyRepository) ensureDownsampleTask(ctx context.Context, orgID, taskName, taskScript, label string) error {
-	found, findErr := sr.influxTaskApi.FindTasks(ctx, &api.TaskFilter{Name: taskName})
+func (is *influxTimeSeriesStore) ensureDownsampleTask(ctx context.Context, orgID, taskName, taskScript, label string) error {
+	found, findErr := is.influxTaskApi.FindTasks(ctx, &api.TaskFilter{Name: taskName})
 	if findErr == nil && len(found) == 0 {
-		_, err := sr.influxTaskApi.CreateTaskByFlux(ctx, taskScript, orgID)
+		_, err := is.influxTaskApi.CreateTaskByFlux(ctx, taskScript, orgID)
 		return err
 	} else if len(found) == 1 {
 		//check if we should update
 		task := &found[0]
 		if taskScript != task.Flux {
-			sr.logger.Infoln("updating " + label + " task script")
+			is.logger.Infoln("updating " + label + " task script")
 			task.Flux = taskScript
-			_, err := sr.influxTaskApi.UpdateTask(ctx, task)
+			_, err := is.influxTaskApi.UpdateTask(ctx, task)
 			return err
 		}
 	}
 	return nil
 }
 
-func (sr *scrutinyRepository) DownsampleScript(aggregationType string, name string, cron string) string {
+func (is *influxTimeSeriesStore) DownsampleScript(aggregationType string, name string, cron string) string {
 	var sourceBucket string // the source of the data
 	var destBucket string   // the destination for the aggregated data
 	var rangeStart string
@@ -61,20 +62,20 @@ func (sr *scrutinyRepository) DownsampleScript(aggregationType string, name stri
 	var aggWindow string
 	switch aggregationType {
 	case "weekly":
-		sourceBucket = sr.appConfig.GetString(cfgInfluxDBBucket)
-		destBucket = fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket))
+		sourceBucket = is.appConfig.GetString(cfgInfluxDBBucket)
+		destBucket = fmt.Sprintf("%s_weekly", is.appConfig.GetString(cfgInfluxDBBucket))
 		rangeStart = "-2w"
 		rangeEnd = "-1w"
 		aggWindow = "1w"
 	case "monthly":
-		sourceBucket = fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket))
-		destBucket = fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket))
+		sourceBucket = fmt.Sprintf("%s_weekly", is.appConfig.GetString(cfgInfluxDBBucket))
+		destBucket = fmt.Sprintf("%s_monthly", is.appConfig.GetString(cfgInfluxDBBucket))
 		rangeStart = "-2mo"
 		rangeEnd = "-1mo"
 		aggWindow = "1mo"
 	case "yearly":
-		sourceBucket = fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket))
-		destBucket = fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket))
+		sourceBucket = fmt.Sprintf("%s_monthly", is.appConfig.GetString(cfgInfluxDBBucket))
+		destBucket = fmt.Sprintf("%s_yearly", is.appConfig.GetString(cfgInfluxDBBucket))
 		rangeStart = "-2y"
 		rangeEnd = "-1y"
 		aggWindow = "1y"
@@ -138,6 +139,6 @@ from(bucket: sourceBucket)
 		rangeEnd,
 		aggWindow,
 		destBucket,
-		sr.appConfig.GetString(cfgInfluxDBOrg),
+		is.appConfig.GetString(cfgInfluxDBOrg),
 	)
 }
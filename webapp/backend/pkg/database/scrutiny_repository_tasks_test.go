@@ -17,14 +17,14 @@ func Test_DownsampleScript_Weekly(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := "weekly"
 
 	//test
-	influxDbScript := deviceRepo.DownsampleScript(aggregationType, "tsk-weekly-aggr", "0 1 * * 0")
+	influxDbScript := influxStore.DownsampleScript(aggregationType, "tsk-weekly-aggr", "0 1 * * 0")
 
 	//assert
 	require.Equal(t, `
@@ -69,14 +69,14 @@ func Test_DownsampleScript_Monthly(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := "monthly"
 
 	//test
-	influxDbScript := deviceRepo.DownsampleScript(aggregationType, "tsk-monthly-aggr", "30 1 1 * *")
+	influxDbScript := influxStore.DownsampleScript(aggregationType, "tsk-monthly-aggr", "30 1 1 * *")
 
 	//assert
 	require.Equal(t, `
@@ -121,14 +121,14 @@ func Test_DownsampleScript_Yearly(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := "yearly"
 
 	//test
-	influxDbScript := deviceRepo.DownsampleScript(aggregationType, "tsk-yearly-aggr", "0 2 1 1 *")
+	influxDbScript := influxStore.DownsampleScript(aggregationType, "tsk-yearly-aggr", "0 2 1 1 *")
 
 	//assert
 	require.Equal(t, `
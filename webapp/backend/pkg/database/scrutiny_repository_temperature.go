@@ -2,13 +2,10 @@ package database
 
 import (
 	"context"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 )
 
 // //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -34,11 +31,7 @@ func (sr *scrutinyRepository) SaveSmartTemperature(ctx context.Context, wwn stri
 			tags, fields := smartTemp.Flatten()
 			tags["device_wwn"] = wwn
 			tags["device_id"] = deviceID
-			p := influxdb2.NewPoint("temp",
-				tags,
-				fields,
-				smartTemp.Date)
-			err := sr.influxWriteApi.WritePoint(ctx, p)
+			err := sr.timeSeries.WritePoint(ctx, "temp", tags, fields, smartTemp.Date)
 			if err != nil {
 				return err
 			}
@@ -54,17 +47,13 @@ func (sr *scrutinyRepository) SaveSmartTemperature(ctx context.Context, wwn stri
 	tags, fields := smartTemp.Flatten()
 	tags["device_wwn"] = wwn
 	tags["device_id"] = deviceID
-	p := influxdb2.NewPoint("temp",
-		tags,
-		fields,
-		smartTemp.Date)
-	return sr.influxWriteApi.WritePoint(ctx, p)
+	return sr.timeSeries.WritePoint(ctx, "temp", tags, fields, smartTemp.Date)
 }
 
 func (sr *scrutinyRepository) GetSmartTemperatureHistory(ctx context.Context, durationKey string) (map[string][]measurements.SmartTemperature, error) {
 	//we can get temp history for "week", "month", DURATION_KEY_YEAR, "forever"
 
-	// Build WWN-to-DeviceID map for re-keying InfluxDB results
+	// Build WWN-to-DeviceID map for re-keying time-series results
 	devices, devErr := sr.GetDevices(ctx)
 	wwnToDeviceID := map[string]string{}
 	if devErr == nil {
@@ -75,24 +64,29 @@ func (sr *scrutinyRepository) GetSmartTemperatureHistory(ctx context.Context, du
 
 	deviceTempHistory := map[string][]measurements.SmartTemperature{}
 
-	queryStr := sr.aggregateTempQuery(durationKey)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryTemperatureHistory(ctx, durationKey)
 	if err != nil {
 		return nil, err
 	}
-	defer result.Close()
-
-	// Use Next() to iterate over query result lines
-	for result.Next() {
-		appendTempRecord(deviceTempHistory, result.Record().Values(), wwnToDeviceID)
-	}
-	if result.Err() != nil {
-		sr.logger.Errorf("Query error: %s", result.Err().Error())
+	for _, values := range rows {
+		appendTempRecord(deviceTempHistory, values, wwnToDeviceID)
 	}
 	return deviceTempHistory, nil
 }
 
+// StreamTemperatureHistory passes each stored temperature point within durationKey to fn, oldest first.
+// Note: WWN is validated at the handler level before reaching this function.
+func (sr *scrutinyRepository) StreamTemperatureHistory(ctx context.Context, wwn string, durationKey string, fn func(temp measurements.SmartTemperature) error) error {
+	return sr.timeSeries.StreamDeviceSeries(ctx, "temp", wwn, durationKey, func(values timeSeriesRow) error {
+		smartTemp := measurements.SmartTemperature{}
+		for k, val := range values {
+			smartTemp.Inflate(k, val)
+		}
+		smartTemp.Date = values["_time"].(time.Time)
+		return fn(smartTemp)
+	})
+}
+
 // appendTempRecord re-keys a single InfluxDB temperature record from WWN to
 // DeviceID and appends the inflated SmartTemperature to the history map.
 func appendTempRecord(deviceTempHistory map[string][]measurements.SmartTemperature, values map[string]interface{}, wwnToDeviceID map[string]string) {
@@ -119,77 +113,3 @@ func appendTempRecord(deviceTempHistory map[string][]measurements.SmartTemperatu
 	smartTemp.Date = values["_time"].(time.Time)
 	deviceTempHistory[key] = append(deviceTempHistory[key], smartTemp)
 }
-
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Helper Methods
-////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-
-func (sr *scrutinyRepository) aggregateTempQuery(durationKey string) string {
-
-	/*
-		import "influxdata/influxdb/schema"
-		weekData = from(bucket: "metrics")
-		  |> range(start: -1w, stop: now())
-		  |> filter(fn: (r) => r["_measurement"] == "temp" )
-		  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
-		  |> group(columns: ["device_wwn"])
-		  |> toInt()
-
-		monthData = from(bucket: "metrics_weekly")
-		  |> range(start: -1mo, stop: now())
-		  |> filter(fn: (r) => r["_measurement"] == "temp" )
-		  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
-		  |> group(columns: ["device_wwn"])
-		  |> toInt()
-
-		union(tables: [weekData, monthData])
-		  |> group(columns: ["device_wwn"])
-		  |> sort(columns: ["_time"], desc: false)
-		  |> schema.fieldsAsCols()
-
-	*/
-
-	partialQueryStr := []string{
-		`import "influxdata/influxdb/schema"`,
-	}
-
-	nestedDurationKeys := sr.lookupNestedDurationKeys(durationKey)
-
-	subQueryNames := []string{}
-	for _, nestedDurationKey := range nestedDurationKeys {
-		bucketName := sr.lookupBucketName(nestedDurationKey)
-		durationRange := sr.lookupDuration(nestedDurationKey)
-		durationResolution := sr.lookupResolution(nestedDurationKey)
-
-		subQueryNames = append(subQueryNames, fmt.Sprintf(`%sData`, nestedDurationKey))
-		subQuery := []string{
-			fmt.Sprintf(`%sData = from(bucket: "%s")`, nestedDurationKey, bucketName),
-			fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
-			`|> filter(fn: (r) => r["_measurement"] == "temp" )`,
-		}
-		if durationResolution != "" {
-			subQuery = append(subQuery,
-				fmt.Sprintf(`|> aggregateWindow(every: %s, fn: mean, createEmpty: false)`, durationResolution))
-		}
-		subQuery = append(subQuery, `|> group(columns: ["device_wwn"])`, `|> toInt()`, "")
-		partialQueryStr = append(partialQueryStr, subQuery...)
-	}
-
-	if len(subQueryNames) == 1 {
-		//there's only one bucket being queried, no need to union, just aggregate the dataset and return
-		partialQueryStr = append(partialQueryStr, []string{
-			subQueryNames[0],
-			"|> schema.fieldsAsCols()",
-			"|> yield()",
-		}...)
-	} else {
-		partialQueryStr = append(partialQueryStr, []string{
-			fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", ")),
-			`|> group(columns: ["device_wwn"])`,
-			`|> sort(columns: ["_time"], desc: false)`,
-			"|> schema.fieldsAsCols()",
-		}...)
-	}
-
-	return strings.Join(partialQueryStr, "\n")
-}
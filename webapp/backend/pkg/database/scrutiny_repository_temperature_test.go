@@ -17,14 +17,14 @@ func Test_aggregateTempQuery_Day(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := DURATION_KEY_DAY
 
 	//test
-	influxDbScript := deviceRepo.aggregateTempQuery(aggregationType)
+	influxDbScript := influxStore.aggregateTempQuery(aggregationType)
 
 	//assert
 	require.Equal(t, `import "influxdata/influxdb/schema"
@@ -49,14 +49,14 @@ func Test_aggregateTempQuery_Week(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := DURATION_KEY_WEEK
 
 	//test
-	influxDbScript := deviceRepo.aggregateTempQuery(aggregationType)
+	influxDbScript := influxStore.aggregateTempQuery(aggregationType)
 
 	//assert
 	require.Equal(t, `import "influxdata/influxdb/schema"
@@ -82,14 +82,14 @@ func Test_aggregateTempQuery_Month(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := DURATION_KEY_MONTH
 
 	//test
-	influxDbScript := deviceRepo.aggregateTempQuery(aggregationType)
+	influxDbScript := influxStore.aggregateTempQuery(aggregationType)
 
 	//assert
 	require.Equal(t, `import "influxdata/influxdb/schema"
@@ -123,14 +123,14 @@ func Test_aggregateTempQuery_Year(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := DURATION_KEY_YEAR
 
 	//test
-	influxDbScript := deviceRepo.aggregateTempQuery(aggregationType)
+	influxDbScript := influxStore.aggregateTempQuery(aggregationType)
 
 	//assert
 	require.Equal(t, `import "influxdata/influxdb/schema"
@@ -171,14 +171,14 @@ func Test_aggregateTempQuery_Forever(t *testing.T) {
 	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
 	fakeConfig.EXPECT().GetString("web.influxdb.org").Return("scrutiny").AnyTimes()
 
-	deviceRepo := scrutinyRepository{
+	influxStore := influxTimeSeriesStore{
 		appConfig: fakeConfig,
 	}
 
 	aggregationType := DURATION_KEY_FOREVER
 
 	//test
-	influxDbScript := deviceRepo.aggregateTempQuery(aggregationType)
+	influxDbScript := influxStore.aggregateTempQuery(aggregationType)
 
 	//assert
 	require.Equal(t, `import "influxdata/influxdb/schema"
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"strings"
 	"time"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg"
@@ -203,138 +202,34 @@ func (sr *scrutinyRepository) queryWorkloadFirstLast(ctx context.Context, durati
 	firstPoints = map[string]*workloadSnapshot{}
 	lastPoints = map[string]*workloadSnapshot{}
 
-	queryStr := sr.buildWorkloadFirstLastQuery(durationKey)
-	sr.logger.Debugln("Workload first/last query:", queryStr)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	firstRows, lastRows, err := sr.timeSeries.QueryWorkloadFirstLast(ctx, durationKey)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to query workload data: %w", err)
 	}
-	defer result.Close()
-
-	for result.Next() {
-		values := result.Record().Values()
-		deviceWWN, ok := values["device_wwn"]
-		if !ok || deviceWWN == nil {
-			continue
-		}
-		wwn := deviceWWN.(string)
-
-		snap := parseWorkloadSnapshot(values)
 
-		// Determine if this is a "first" or "last" result based on the yield name
-		resultName := result.TableMetadata().Column(0).Name()
-		if result.Record().Result() == "first" {
-			firstPoints[wwn] = snap
-		} else {
-			// "last" result or default
-			lastPoints[wwn] = snap
+	for _, values := range firstRows {
+		if wwn, ok := values["device_wwn"].(string); ok {
+			firstPoints[wwn] = parseWorkloadSnapshot(values)
 		}
-		_ = resultName
 	}
-	if result.Err() != nil {
-		return nil, nil, fmt.Errorf("query iteration error: %w", result.Err())
+	for _, values := range lastRows {
+		if wwn, ok := values["device_wwn"].(string); ok {
+			lastPoints[wwn] = parseWorkloadSnapshot(values)
+		}
 	}
 
 	return firstPoints, lastPoints, nil
 }
 
-func (sr *scrutinyRepository) buildWorkloadFirstLastQuery(durationKey string) string {
-	bucketBaseName := sr.appConfig.GetString(cfgInfluxDBBucket)
-
-	partialQueryStr := []string{
-		`import "influxdata/influxdb/schema"`,
-		``,
-		`workloadFields = (r) =>`,
-		`    r["_field"] == "power_on_hours" or`,
-		`    r["_field"] == "logical_block_size" or`,
-		`    r["_field"] == "attr.241.raw_value" or`,
-		`    r["_field"] == "attr.242.raw_value" or`,
-		`    r["_field"] == "attr.devstat_1_24.value" or`,
-		`    r["_field"] == "attr.devstat_1_40.value" or`,
-		`    r["_field"] == "attr.data_units_written.value" or`,
-		`    r["_field"] == "attr.data_units_read.value" or`,
-		`    r["_field"] == "attr.percentage_used.value" or`,
-		`    r["_field"] == "attr.devstat_7_8.value" or`,
-		`    r["_field"] == "attr.177.value" or`,
-		`    r["_field"] == "attr.231.value" or`,
-		`    r["_field"] == "attr.232.value" or`,
-		`    r["_field"] == "attr.233.value"`,
-		``,
-	}
-
-	nestedDurationKeys := sr.lookupNestedDurationKeys(durationKey)
-	subQueryNames := []string{}
-
-	for _, nestedDurationKey := range nestedDurationKeys {
-		bucketName := sr.lookupBucketName(nestedDurationKey)
-		durationRange := sr.lookupDuration(nestedDurationKey)
-		subQueryName := fmt.Sprintf(`%sData`, nestedDurationKey)
-		subQueryNames = append(subQueryNames, subQueryName)
-
-		partialQueryStr = append(partialQueryStr, []string{
-			fmt.Sprintf("%s = from(bucket: %q)", subQueryName, bucketName),
-			fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
-			`|> filter(fn: (r) => r["_measurement"] == "smart")`,
-			`|> filter(fn: workloadFields)`,
-			``,
-		}...)
-	}
-
-	var combinedExpr string
-	if len(subQueryNames) == 1 {
-		combinedExpr = subQueryNames[0]
-	} else {
-		combinedExpr = fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", "))
-	}
-
-	partialQueryStr = append(partialQueryStr, []string{
-		fmt.Sprintf(`combined = %s`, combinedExpr),
-		`|> schema.fieldsAsCols()`,
-		// Filter out rows where all counter fields are null or zero.
-		// Downsampled buckets can contain entries with zero-filled or missing counter
-		// fields (e.g. from before a device started reporting a particular attribute).
-		// Using such a row as the "first" point makes the delta equal to the device's
-		// entire lifetime of cumulative writes, grossly inflating daily rates.
-		`|> filter(fn: (r) =>`,
-		`    (exists r["attr.241.raw_value"] and r["attr.241.raw_value"] > 0) or`,
-		`    (exists r["attr.242.raw_value"] and r["attr.242.raw_value"] > 0) or`,
-		`    (exists r["attr.devstat_1_24.value"] and r["attr.devstat_1_24.value"] > 0) or`,
-		`    (exists r["attr.devstat_1_40.value"] and r["attr.devstat_1_40.value"] > 0) or`,
-		`    (exists r["attr.data_units_written.value"] and r["attr.data_units_written.value"] > 0) or`,
-		`    (exists r["attr.data_units_read.value"] and r["attr.data_units_read.value"] > 0)`,
-		`)`,
-		`|> group(columns: ["device_wwn"])`,
-		``,
-		`combined`,
-		`|> sort(columns: ["_time"], desc: false)`,
-		`|> limit(n: 1)`,
-		`|> yield(name: "first")`,
-		``,
-		`combined`,
-		`|> sort(columns: ["_time"], desc: true)`,
-		`|> limit(n: 1)`,
-		`|> yield(name: "last")`,
-	}...)
-
-	_ = bucketBaseName
-	return strings.Join(partialQueryStr, "\n")
-}
-
 func (sr *scrutinyRepository) queryWorkloadRecent(ctx context.Context) (map[string][]*workloadSnapshot, error) {
 	recentPoints := map[string][]*workloadSnapshot{}
 
-	queryStr := sr.buildWorkloadRecentQuery()
-	sr.logger.Debugln("Workload recent query:", queryStr)
-
-	result, err := sr.influxQueryApi.Query(ctx, queryStr)
+	rows, err := sr.timeSeries.QueryWorkloadRecent(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query recent workload data: %w", err)
 	}
-	defer result.Close()
 
-	for result.Next() {
-		values := result.Record().Values()
+	for _, values := range rows {
 		deviceWWN, ok := values["device_wwn"]
 		if !ok || deviceWWN == nil {
 			continue
@@ -343,40 +238,10 @@ func (sr *scrutinyRepository) queryWorkloadRecent(ctx context.Context) (map[stri
 		snap := parseWorkloadSnapshot(values)
 		recentPoints[wwn] = append(recentPoints[wwn], snap)
 	}
-	if result.Err() != nil {
-		return nil, fmt.Errorf("query iteration error: %w", result.Err())
-	}
 
 	return recentPoints, nil
 }
 
-func (sr *scrutinyRepository) buildWorkloadRecentQuery() string {
-	bucketName := sr.appConfig.GetString(cfgInfluxDBBucket)
-
-	return strings.Join([]string{
-		`import "influxdata/influxdb/schema"`,
-		``,
-		`workloadFields = (r) =>`,
-		`    r["_field"] == "power_on_hours" or`,
-		`    r["_field"] == "logical_block_size" or`,
-		`    r["_field"] == "attr.241.raw_value" or`,
-		`    r["_field"] == "attr.242.raw_value" or`,
-		`    r["_field"] == "attr.devstat_1_24.value" or`,
-		`    r["_field"] == "attr.devstat_1_40.value" or`,
-		`    r["_field"] == "attr.data_units_written.value" or`,
-		`    r["_field"] == "attr.data_units_read.value"`,
-		``,
-		fmt.Sprintf("from(bucket: %q)", bucketName),
-		`|> range(start: -1w, stop: now())`,
-		`|> filter(fn: (r) => r["_measurement"] == "smart")`,
-		`|> filter(fn: workloadFields)`,
-		`|> schema.fieldsAsCols()`,
-		`|> group(columns: ["device_wwn"])`,
-		`|> sort(columns: ["_time"], desc: true)`,
-		`|> limit(n: 3)`,
-	}, "\n")
-}
-
 func (sr *scrutinyRepository) computeWorkloadInsight(insight *models.WorkloadInsight, first, last *workloadSnapshot, protocol string, maxTBW *float64) {
 	timeSpan := last.Time.Sub(first.Time)
 	timeSpanHours := timeSpan.Hours()
@@ -108,6 +108,9 @@ func (sr *scrutinyRepository) GetZFSPools(ctx context.Context) ([]models.ZFSPool
 	if err := sr.gormClient.WithContext(ctx).Where("archived = ?", false).Find(&pools).Error; err != nil {
 		return nil, fmt.Errorf("could not get ZFS pools from DB: %v", err)
 	}
+	if err := sr.attachZFSPoolTags(ctx, pools); err != nil {
+		return nil, err
+	}
 	return pools, nil
 }
 
@@ -133,6 +136,12 @@ func (sr *scrutinyRepository) GetZFSPoolDetails(ctx context.Context, guid string
 	}
 
 	pool.Vdevs = vdevs
+
+	tags, err := sr.getResourceTagsOf(ctx, models.TagResourceZFSPool, guid)
+	if err != nil {
+		return pool, err
+	}
+	pool.Tags = tags
 	return pool, nil
 }
 
@@ -199,27 +208,13 @@ func (sr *scrutinyRepository) DeleteZFSPool(ctx context.Context, guid string) er
 	if err := sr.gormClient.WithContext(ctx).Where(queryGUID, guid).Delete(&models.ZFSPool{}).Error; err != nil {
 		return err
 	}
-
-	// Delete data from InfluxDB
-	buckets := []string{
-		sr.appConfig.GetString(cfgInfluxDBBucket),
-		fmt.Sprintf("%s_weekly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_monthly", sr.appConfig.GetString(cfgInfluxDBBucket)),
-		fmt.Sprintf("%s_yearly", sr.appConfig.GetString(cfgInfluxDBBucket)),
+	if err := sr.deleteResourceTags(ctx, models.TagResourceZFSPool, guid); err != nil {
+		return err
 	}
 
-	for _, bucket := range buckets {
-		sr.logger.Infof("Deleting ZFS pool data for %s in bucket: %s", guid, bucket)
-		if err := sr.influxClient.DeleteAPI().DeleteWithName(
-			ctx,
-			sr.appConfig.GetString(cfgInfluxDBOrg),
-			bucket,
-			time.Now().AddDate(-10, 0, 0),
-			time.Now(),
-			fmt.Sprintf(`pool_guid="%s"`, guid),
-		); err != nil {
-			return err
-		}
+	// Delete data from the time-series backend
+	if err := sr.timeSeries.DeleteSeries(ctx, "pool_guid", guid); err != nil {
+		return err
 	}
 
 	return nil
@@ -241,10 +236,10 @@ func (sr *scrutinyRepository) GetZFSPoolsSummary(ctx context.Context) (map[strin
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// ZFS Pool Metrics (InfluxDB)
+// ZFS Pool Metrics (time-series)
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// SaveZFSPoolMetrics saves ZFS pool metrics to InfluxDB
+// SaveZFSPoolMetrics saves ZFS pool metrics to the time-series backend
 func (sr *scrutinyRepository) SaveZFSPoolMetrics(ctx context.Context, pool models.ZFSPool) error {
 	// Create metrics from pool data
 	metrics := measurements.ZFSPoolMetrics{
@@ -268,49 +263,19 @@ func (sr *scrutinyRepository) SaveZFSPoolMetrics(ctx context.Context, pool model
 	tags, fields := metrics.Flatten()
 
 	// Save to daily bucket
-	return sr.saveDatapoint(
-		sr.influxWriteApi,
-		"zfs_pool",
-		tags,
-		fields,
-		metrics.Date,
-		ctx,
-	)
+	return sr.timeSeries.WritePoint(ctx, "zfs_pool", tags, fields, metrics.Date)
 }
 
 // GetZFSPoolMetricsHistory retrieves historical metrics for a ZFS pool
 // Note: GUID is validated at the handler level before reaching this function.
 func (sr *scrutinyRepository) GetZFSPoolMetricsHistory(ctx context.Context, guid string, durationKey string) ([]measurements.ZFSPoolMetrics, error) {
-	// Map duration key to actual duration and bucket
-	bucketName := sr.lookupBucketName(durationKey)
-	duration := sr.lookupDuration(durationKey)
-
-	// Use parameterized query to prevent Flux injection
-	queryStr := fmt.Sprintf(`
-		from(bucket: "%s")
-		|> range(start: %s, stop: %s)
-		|> filter(fn: (r) => r["_measurement"] == "zfs_pool")
-		|> filter(fn: (r) => r["pool_guid"] == params.guid)
-		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
-		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-		|> sort(columns: ["_time"], desc: false)
-	`, bucketName, duration[0], duration[1])
-
-	params := map[string]interface{}{
-		"guid": guid,
-	}
-
-	result, err := sr.influxQueryApi.QueryWithParams(ctx, queryStr, params)
+	rows, err := sr.timeSeries.QueryZFSPoolHistory(ctx, guid, durationKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query ZFS pool metrics: %v", err)
 	}
-	defer result.Close()
 
 	var metricsHistory []measurements.ZFSPoolMetrics
-	for result.Next() {
-		record := result.Record()
-		values := record.Values()
-
+	for _, values := range rows {
 		metrics, err := measurements.NewZFSPoolMetricsFromInfluxDB(values)
 		if err != nil {
 			sr.logger.Warnf("Failed to parse ZFS pool metrics: %v", err)
@@ -320,9 +285,5 @@ func (sr *scrutinyRepository) GetZFSPoolMetricsHistory(ctx context.Context, guid
 		metricsHistory = append(metricsHistory, *metrics)
 	}
 
-	if result.Err() != nil {
-		return nil, fmt.Errorf("query error: %v", result.Err())
-	}
-
 	return metricsHistory, nil
 }
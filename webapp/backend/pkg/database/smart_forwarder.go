@@ -0,0 +1,379 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// Supported values for the web.smart_forward.type config key
+	SMART_FORWARD_TYPE_INFLUXDB      = "influxdb"
+	SMART_FORWARD_TYPE_LINE_PROTOCOL = "line_protocol"
+	SMART_FORWARD_TYPE_REMOTE_WRITE  = "remote_write"
+)
+
+// smartForwardMetricPrefix prefixes the remote-write metric names, e.g. scrutiny_smart_attr_5_raw_value
+const smartForwardMetricPrefix = "scrutiny_smart_"
+
+const (
+	// smartForwardMaxPending bounds the SMART points waiting to be forwarded, retries included.
+	// Points submitted while the buffer is full are dropped.
+	smartForwardMaxPending = 256
+	// smartForwardMaxAttempts is how often a point is sent before it is given up on
+	smartForwardMaxAttempts = 5
+
+	smartForwardRetryBaseDelay = 2 * time.Second
+	smartForwardRetryMaxDelay  = time.Minute
+)
+
+// activeSmartForwarder is the forwarder started by StartSmartForwarder and shared by every
+// repository of the process, nil when forwarding is disabled.
+var (
+	activeSmartForwarder   *smartForwarder
+	activeSmartForwarderMu sync.RWMutex
+)
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// smartForwarder copies every stored SMART submission to an external time-series sink, so a
+// long-term metrics store keeps the full history independent of Scrutiny's retention buckets.
+// Forwarding is best effort: failed sends are retried with backoff while the sink is unreachable
+// or overloaded, and are logged but never fail the upload.
+type smartForwarder struct {
+	// ctx stops pending sends and retries on shutdown
+	ctx        context.Context
+	logger     logrus.FieldLogger
+	sinkType   string
+	targetURL  string
+	headers    map[string]string
+	timeout    time.Duration
+	retryDelay time.Duration
+	client     *http.Client
+
+	// pending holds one slot per point that is being forwarded or waiting for a retry
+	pending chan struct{}
+}
+
+// smartForwardStatusError is returned when the sink answers with a non-2xx status.
+type smartForwardStatusError struct {
+	status  string
+	code    int
+	message string
+}
+
+func (e *smartForwardStatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.status, e.message)
+}
+
+// StartSmartForwarder validates web.smart_forward and starts the forwarder that every repository of
+// the process copies SMART submissions to. It is called once at startup, so an invalid
+// configuration fails there instead of in every repository constructor. Cancelling ctx drops the
+// points still waiting to be forwarded.
+func StartSmartForwarder(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger) error {
+	forwarder, err := newSmartForwarder(ctx, appConfig, globalLogger)
+	if err != nil {
+		return err
+	}
+	activeSmartForwarderMu.Lock()
+	activeSmartForwarder = forwarder
+	activeSmartForwarderMu.Unlock()
+	return nil
+}
+
+// currentSmartForwarder returns the forwarder started by StartSmartForwarder, or nil.
+func currentSmartForwarder() *smartForwarder {
+	activeSmartForwarderMu.RLock()
+	defer activeSmartForwarderMu.RUnlock()
+	return activeSmartForwarder
+}
+
+// newSmartForwarder creates the forwarder configured with web.smart_forward, or returns nil
+// when forwarding is disabled.
+func newSmartForwarder(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger) (*smartForwarder, error) {
+	if !appConfig.GetBool(cfgSmartForwardEnabled) {
+		return nil, nil
+	}
+
+	rawURL := strings.TrimSpace(appConfig.GetString(cfgSmartForwardURL))
+	targetURL, err := url.Parse(rawURL)
+	if err != nil || (targetURL.Scheme != "http" && targetURL.Scheme != "https") || targetURL.Host == "" {
+		return nil, fmt.Errorf("invalid %s %q: must be an http(s) URL", cfgSmartForwardURL, rawURL)
+	}
+	headers, err := config.StringMap(appConfig, cfgSmartForwardHeaders)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(appConfig.GetInt(cfgSmartForwardTimeoutSeconds)) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	sinkType := strings.ToLower(strings.TrimSpace(appConfig.GetString(cfgSmartForwardType)))
+	switch sinkType {
+	case SMART_FORWARD_TYPE_INFLUXDB:
+		// the URL is the server address, the write endpoint is derived from the org and bucket
+		org := appConfig.GetString(cfgSmartForwardInfluxDBOrg)
+		bucket := appConfig.GetString(cfgSmartForwardInfluxDBBucket)
+		if org == "" || bucket == "" {
+			return nil, fmt.Errorf("%s and %s are required when %s is %q", cfgSmartForwardInfluxDBOrg, cfgSmartForwardInfluxDBBucket, cfgSmartForwardType, sinkType)
+		}
+		targetURL.Path = strings.TrimSuffix(targetURL.Path, "/") + "/api/v2/write"
+		targetURL.RawQuery = url.Values{"org": {org}, "bucket": {bucket}, "precision": {"ns"}}.Encode()
+		if token := appConfig.GetString(cfgSmartForwardInfluxDBToken); token != "" {
+			headers["Authorization"] = "Token " + token
+		}
+	case SMART_FORWARD_TYPE_LINE_PROTOCOL, SMART_FORWARD_TYPE_REMOTE_WRITE:
+	default:
+		return nil, fmt.Errorf("unsupported %s %q (expected %q, %q or %q)", cfgSmartForwardType, sinkType,
+			SMART_FORWARD_TYPE_INFLUXDB, SMART_FORWARD_TYPE_LINE_PROTOCOL, SMART_FORWARD_TYPE_REMOTE_WRITE)
+	}
+
+	return &smartForwarder{
+		ctx:        ctx,
+		logger:     globalLogger,
+		sinkType:   sinkType,
+		targetURL:  targetURL.String(),
+		headers:    headers,
+		timeout:    timeout,
+		retryDelay: smartForwardRetryBaseDelay,
+		client:     &http.Client{Timeout: timeout},
+		pending:    make(chan struct{}, smartForwardMaxPending),
+	}, nil
+}
+
+// Forward sends a stored SMART point in the background, so a slow sink does not delay the upload.
+// The point is dropped when smartForwardMaxPending points are already waiting to be forwarded.
+func (f *smartForwarder) Forward(tags map[string]string, fields map[string]interface{}, date time.Time) {
+	if f.ctx.Err() != nil {
+		return
+	}
+	select {
+	case f.pending <- struct{}{}:
+	default:
+		f.logger.Warnf("Dropping SMART data for device %s: %d points are already waiting to be forwarded to %s", tags["device_wwn"], smartForwardMaxPending, f.sinkType)
+		return
+	}
+	go func() {
+		defer func() { <-f.pending }()
+		f.deliver(tags, fields, date)
+	}()
+}
+
+// deliver sends a point, retrying failures that may be transient (network errors, 429 and 5xx
+// responses) up to smartForwardMaxAttempts times, until the forwarder's context is cancelled.
+func (f *smartForwarder) deliver(tags map[string]string, fields map[string]interface{}, date time.Time) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(f.ctx, f.timeout)
+		err := f.send(ctx, tags, fields, date)
+		cancel()
+		if err == nil {
+			return
+		}
+		if f.ctx.Err() != nil {
+			f.logger.Debugf("Stopped forwarding SMART data for device %s to %s: %v", tags["device_wwn"], f.sinkType, f.ctx.Err())
+			return
+		}
+		if attempt >= smartForwardMaxAttempts || !smartForwardRetryable(err) {
+			f.logger.Warnf("Failed to forward SMART data for device %s to %s after %d attempt(s): %v", tags["device_wwn"], f.sinkType, attempt, err)
+			return
+		}
+		delay := f.retryBackoff(attempt)
+		f.logger.Debugf("Failed to forward SMART data for device %s to %s, retrying in %v: %v", tags["device_wwn"], f.sinkType, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-f.ctx.Done():
+			timer.Stop()
+			f.logger.Debugf("Stopped forwarding SMART data for device %s to %s: %v", tags["device_wwn"], f.sinkType, f.ctx.Err())
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// retryBackoff returns the delay after the given failed attempt: retryDelay, doubling per
+// attempt, capped at smartForwardRetryMaxDelay.
+func (f *smartForwarder) retryBackoff(attempt int) time.Duration {
+	delay := f.retryDelay
+	for i := 1; i < attempt && delay < smartForwardRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > smartForwardRetryMaxDelay {
+		delay = smartForwardRetryMaxDelay
+	}
+	return delay
+}
+
+// smartForwardRetryable reports whether sending the point again may succeed. Requests the sink
+// rejected (4xx other than 429) fail the same way every time.
+func smartForwardRetryable(err error) bool {
+	var statusErr *smartForwardStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	return true
+}
+
+func (f *smartForwarder) send(ctx context.Context, tags map[string]string, fields map[string]interface{}, date time.Time) error {
+	var body []byte
+	headers := map[string]string{}
+	if f.sinkType == SMART_FORWARD_TYPE_REMOTE_WRITE {
+		body = snappy.Encode(nil, encodeRemoteWriteRequest(tags, fields, date))
+		headers["Content-Type"] = "application/x-protobuf"
+		headers["Content-Encoding"] = "snappy"
+		headers["X-Prometheus-Remote-Write-Version"] = "0.1.0"
+	} else {
+		body = []byte(encodeLineProtocol("smart", tags, fields, date))
+		headers["Content-Type"] = "text/plain; charset=utf-8"
+	}
+	for key, value := range f.headers {
+		headers[key] = value
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.targetURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &smartForwardStatusError{status: resp.Status, code: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+	return nil
+}
+
+// smartForwardTags adds the device identity to the stored SMART tags, so the sink can be
+// queried by host, label or model without joining against Scrutiny's database.
+func smartForwardTags(tags map[string]string, device *models.Device) map[string]string {
+	forwardTags := map[string]string{}
+	for key, value := range tags {
+		forwardTags[key] = value
+	}
+	if device != nil {
+		forwardTags["device_name"] = device.DeviceName
+		forwardTags["device_type"] = device.DeviceType
+		forwardTags["model_name"] = device.ModelName
+		forwardTags["serial_number"] = device.SerialNumber
+		forwardTags["host_id"] = device.HostId
+		forwardTags["label"] = device.Label
+	}
+	// line protocol rejects empty tag values
+	for key, value := range forwardTags {
+		if value == "" {
+			delete(forwardTags, key)
+		}
+	}
+	return forwardTags
+}
+
+// encodeLineProtocol renders a point in InfluxDB line protocol with nanosecond timestamps, as
+// accepted by InfluxDB 1.x/2.x, VictoriaMetrics and Telegraf.
+func encodeLineProtocol(measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) string {
+	return write.PointToLineProtocol(write.NewPoint(measurement, tags, fields, date), time.Nanosecond)
+}
+
+// encodeRemoteWriteRequest builds a Prometheus remote-write WriteRequest protobuf with one series
+// per numeric field. Text fields (attribute names, raw strings) have no sample value and are skipped.
+func encodeRemoteWriteRequest(tags map[string]string, fields map[string]interface{}, date time.Time) []byte {
+	labelNames := make([]string, 0, len(tags))
+	for key := range tags {
+		labelNames = append(labelNames, key)
+	}
+	sort.Strings(labelNames)
+
+	fieldNames := make([]string, 0, len(fields))
+	for key := range fields {
+		fieldNames = append(fieldNames, key)
+	}
+	sort.Strings(fieldNames)
+
+	var request []byte
+	for _, field := range fieldNames {
+		value, ok := sampleValue(fields[field])
+		if !ok {
+			continue
+		}
+
+		// remote-write requires labels sorted by name; "__name__" sorts before the tag names
+		var series []byte
+		series = appendRemoteWriteLabel(series, "__name__", smartForwardMetricPrefix+invalidMetricNameChars.ReplaceAllString(field, "_"))
+		for _, name := range labelNames {
+			series = appendRemoteWriteLabel(series, name, tags[name])
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(date.UnixMilli()))
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, sample)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, series)
+	}
+	return request
+}
+
+func appendRemoteWriteLabel(series []byte, name string, value string) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, name)
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, value)
+	series = protowire.AppendTag(series, 1, protowire.BytesType)
+	return protowire.AppendBytes(series, label)
+}
+
+// sampleValue converts a numeric or boolean field to a float64 sample.
+func sampleValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
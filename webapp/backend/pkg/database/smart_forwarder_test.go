@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/golang/snappy"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type forwardedRequest struct {
+	path, query string
+	header      http.Header
+	body        []byte
+}
+
+func newSmartForwardReceiver(t *testing.T) (*httptest.Server, chan forwardedRequest) {
+	t.Helper()
+	requests := make(chan forwardedRequest, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- forwardedRequest{path: r.URL.Path, query: r.URL.RawQuery, header: r.Header, body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver, requests
+}
+
+func newSmartForwardTestConfig(t *testing.T, sinkType string, targetURL string) config.Interface {
+	t.Helper()
+	cfg, err := config.Create()
+	require.NoError(t, err)
+	cfg.Set("web.smart_forward.enabled", true)
+	cfg.Set("web.smart_forward.type", sinkType)
+	cfg.Set("web.smart_forward.url", targetURL)
+	return cfg
+}
+
+var (
+	smartForwardTestDate   = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	smartForwardTestFields = map[string]interface{}{
+		"temp":                 int64(34),
+		"attr.5.raw_value":     int64(8),
+		"attr.5.name":          "Reallocated Sectors Count",
+		"attr.5.failure_rate":  0.025,
+		"attr.5.status_reason": "",
+	}
+)
+
+func TestSmartForwardTags(t *testing.T) {
+	tags := smartForwardTags(
+		map[string]string{"device_wwn": "0x5000", "device_id": "dev-1", "device_protocol": "ATA"},
+		&models.Device{DeviceName: "sda", ModelName: "WDC WD40", SerialNumber: "WD-123", HostId: "nas-1"},
+	)
+
+	assert.Equal(t, map[string]string{
+		"device_wwn":      "0x5000",
+		"device_id":       "dev-1",
+		"device_protocol": "ATA",
+		"device_name":     "sda",
+		"model_name":      "WDC WD40",
+		"serial_number":   "WD-123",
+		"host_id":         "nas-1",
+	}, tags, "empty device fields are left out")
+
+	assert.Equal(t, map[string]string{"device_wwn": "0x5000"}, smartForwardTags(map[string]string{"device_wwn": "0x5000", "device_id": ""}, nil))
+}
+
+func TestSmartForwarder_LineProtocol(t *testing.T) {
+	receiver, requests := newSmartForwardReceiver(t)
+	cfg := newSmartForwardTestConfig(t, "line_protocol", receiver.URL+"/write?db=scrutiny")
+	cfg.Set("web.smart_forward.headers", "Authorization=Basic dXNlcjpwYXNz")
+
+	forwarder, err := newSmartForwarder(context.Background(), cfg, logrus.New())
+	require.NoError(t, err)
+	forwarder.Forward(map[string]string{"device_wwn": "0x5000", "host_id": "nas 1"}, smartForwardTestFields, smartForwardTestDate)
+
+	var got forwardedRequest
+	select {
+	case got = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink got no request")
+	}
+	assert.Equal(t, "/write", got.path)
+	assert.Equal(t, "db=scrutiny", got.query)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", got.header.Get("Authorization"))
+	assert.Equal(t,
+		`smart,device_wwn=0x5000,host_id=nas\ 1 attr.5.failure_rate=0.025,attr.5.name="Reallocated Sectors Count",attr.5.raw_value=8i,attr.5.status_reason="",temp=34i 1792324800000000000`+"\n",
+		string(got.body))
+}
+
+func TestSmartForwarder_InfluxDB(t *testing.T) {
+	receiver, requests := newSmartForwardReceiver(t)
+	cfg := newSmartForwardTestConfig(t, "influxdb", receiver.URL)
+	cfg.Set("web.smart_forward.influxdb.org", "homelab")
+	cfg.Set("web.smart_forward.influxdb.bucket", "smart")
+	cfg.Set("web.smart_forward.influxdb.token", "secret")
+
+	forwarder, err := newSmartForwarder(context.Background(), cfg, logrus.New())
+	require.NoError(t, err)
+	require.NoError(t, forwarder.send(context.Background(), map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate))
+
+	got := <-requests
+	assert.Equal(t, "/api/v2/write", got.path)
+	assert.Equal(t, "bucket=smart&org=homelab&precision=ns", got.query)
+	assert.Equal(t, "Token secret", got.header.Get("Authorization"))
+	assert.Contains(t, string(got.body), "smart,device_wwn=0x5000 ")
+}
+
+// remoteWriteSeries decodes a snappy compressed WriteRequest into label sets keyed by metric name.
+func remoteWriteSeries(t *testing.T, body []byte) (map[string]map[string]string, map[string]float64, map[string]int64) {
+	t.Helper()
+	request, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+
+	labels := map[string]map[string]string{}
+	values := map[string]float64{}
+	timestamps := map[string]int64{}
+	for len(request) > 0 {
+		_, _, n := protowire.ConsumeTag(request)
+		series, m := protowire.ConsumeBytes(request[n:])
+		require.GreaterOrEqual(t, m, 0)
+		request = request[n+m:]
+
+		seriesLabels := map[string]string{}
+		var value float64
+		var timestamp int64
+		for len(series) > 0 {
+			num, _, n := protowire.ConsumeTag(series)
+			message, m := protowire.ConsumeBytes(series[n:])
+			require.GreaterOrEqual(t, m, 0)
+			series = series[n+m:]
+			if num == 1 {
+				_, _, n := protowire.ConsumeTag(message)
+				name, m := protowire.ConsumeString(message[n:])
+				message = message[n+m:]
+				_, _, n = protowire.ConsumeTag(message)
+				labelValue, _ := protowire.ConsumeString(message[n:])
+				seriesLabels[name] = labelValue
+			} else {
+				_, _, n := protowire.ConsumeTag(message)
+				bits, m := protowire.ConsumeFixed64(message[n:])
+				value = math.Float64frombits(bits)
+				message = message[n+m:]
+				_, _, n = protowire.ConsumeTag(message)
+				ts, _ := protowire.ConsumeVarint(message[n:])
+				timestamp = int64(ts)
+			}
+		}
+		labels[seriesLabels["__name__"]] = seriesLabels
+		values[seriesLabels["__name__"]] = value
+		timestamps[seriesLabels["__name__"]] = timestamp
+	}
+	return labels, values, timestamps
+}
+
+func TestSmartForwarder_RemoteWrite(t *testing.T) {
+	receiver, requests := newSmartForwardReceiver(t)
+	cfg := newSmartForwardTestConfig(t, "remote_write", receiver.URL+"/api/v1/write")
+
+	forwarder, err := newSmartForwarder(context.Background(), cfg, logrus.New())
+	require.NoError(t, err)
+	require.NoError(t, forwarder.send(context.Background(), map[string]string{"device_wwn": "0x5000", "host_id": "nas-1"}, smartForwardTestFields, smartForwardTestDate))
+
+	got := <-requests
+	assert.Equal(t, "/api/v1/write", got.path)
+	assert.Equal(t, "snappy", got.header.Get("Content-Encoding"))
+	assert.Equal(t, "0.1.0", got.header.Get("X-Prometheus-Remote-Write-Version"))
+
+	labels, values, timestamps := remoteWriteSeries(t, got.body)
+	assert.Len(t, labels, 3, "text fields are not sent as samples")
+	assert.Equal(t, map[string]string{"__name__": "scrutiny_smart_attr_5_raw_value", "device_wwn": "0x5000", "host_id": "nas-1"}, labels["scrutiny_smart_attr_5_raw_value"])
+	assert.Equal(t, 8.0, values["scrutiny_smart_attr_5_raw_value"])
+	assert.Equal(t, 0.025, values["scrutiny_smart_attr_5_failure_rate"])
+	assert.Equal(t, 34.0, values["scrutiny_smart_temp"])
+	assert.Equal(t, smartForwardTestDate.UnixMilli(), timestamps["scrutiny_smart_temp"])
+}
+
+func TestSmartForwarder_ReportsSinkErrors(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer receiver.Close()
+
+	forwarder, err := newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "line_protocol", receiver.URL), logrus.New())
+	require.NoError(t, err)
+	err = forwarder.send(context.Background(), map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate)
+	assert.ErrorContains(t, err, "bucket not found")
+}
+
+func TestSmartForwarder_RetriesTransientFailures(t *testing.T) {
+	var attempts int32
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	forwarder, err := newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "line_protocol", receiver.URL), logrus.New())
+	require.NoError(t, err)
+	forwarder.retryDelay = time.Millisecond
+	forwarder.Forward(map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate)
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the point was not retried")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestSmartForwarder_DoesNotRetryRejectedPoints(t *testing.T) {
+	var attempts int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer receiver.Close()
+
+	forwarder, err := newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "line_protocol", receiver.URL), logrus.New())
+	require.NoError(t, err)
+	forwarder.retryDelay = time.Millisecond
+	forwarder.deliver(map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestSmartForwarder_StopsRetryingWhenCancelled(t *testing.T) {
+	var attempts int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	forwarder, err := newSmartForwarder(ctx, newSmartForwardTestConfig(t, "line_protocol", receiver.URL), logrus.New())
+	require.NoError(t, err)
+	forwarder.retryDelay = time.Hour
+
+	done := make(chan struct{})
+	go func() {
+		forwarder.deliver(map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&attempts) == 1 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry was not stopped")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+
+	forwarder.Forward(map[string]string{"device_wwn": "0x5000"}, smartForwardTestFields, smartForwardTestDate)
+	assert.Empty(t, forwarder.pending, "points submitted after shutdown are dropped")
+}
+
+func TestStartSmartForwarder(t *testing.T) {
+	t.Cleanup(func() { activeSmartForwarder = nil })
+
+	err := StartSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "graphite", "http://graphite:2003"), logrus.New())
+	assert.ErrorContains(t, err, "web.smart_forward.type")
+	assert.Nil(t, currentSmartForwarder())
+
+	require.NoError(t, StartSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "line_protocol", "http://victoriametrics:8428/write"), logrus.New()))
+	require.NotNil(t, currentSmartForwarder())
+	assert.Equal(t, "http://victoriametrics:8428/write", currentSmartForwarder().targetURL)
+}
+
+func TestSmartForwarder_RetryBackoff(t *testing.T) {
+	forwarder := &smartForwarder{retryDelay: smartForwardRetryBaseDelay}
+	assert.Equal(t, 2*time.Second, forwarder.retryBackoff(1))
+	assert.Equal(t, 4*time.Second, forwarder.retryBackoff(2))
+	assert.Equal(t, 16*time.Second, forwarder.retryBackoff(4))
+	assert.Equal(t, time.Minute, forwarder.retryBackoff(10))
+}
+
+func TestNewSmartForwarder_Settings(t *testing.T) {
+	cfg, err := config.Create()
+	require.NoError(t, err)
+	forwarder, err := newSmartForwarder(context.Background(), cfg, logrus.New())
+	require.NoError(t, err)
+	assert.Nil(t, forwarder, "forwarding is disabled by default")
+
+	_, err = newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "line_protocol", "victoriametrics:8428/write"), logrus.New())
+	assert.ErrorContains(t, err, "web.smart_forward.url")
+
+	_, err = newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "graphite", "http://graphite:2003"), logrus.New())
+	assert.ErrorContains(t, err, "web.smart_forward.type")
+
+	_, err = newSmartForwarder(context.Background(), newSmartForwardTestConfig(t, "influxdb", "http://influxdb:8086"), logrus.New())
+	assert.ErrorContains(t, err, "web.smart_forward.influxdb.org")
+}
@@ -0,0 +1,1128 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// influxTimeSeriesStore stores time-series data in InfluxDB, using server-side tasks to
+// down-sample the raw bucket into the weekly/monthly/yearly buckets.
+type influxTimeSeriesStore struct {
+	appConfig config.Interface
+	logger    logrus.FieldLogger
+
+	influxWriteApi api.WriteAPIBlocking
+	influxQueryApi api.QueryAPI
+	influxTaskApi  api.TasksAPI
+	influxClient   influxdb2.Client
+}
+
+func newInfluxTimeSeriesStore(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger) (*influxTimeSeriesStore, error) {
+	client, err := setupInfluxClient(ctx, appConfig, globalLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use blocking write client for writes to desired bucket
+	writeAPI := client.WriteAPIBlocking(appConfig.GetString(cfgInfluxDBOrg), appConfig.GetString(cfgInfluxDBBucket))
+
+	// Get query client
+	queryAPI := client.QueryAPI(appConfig.GetString(cfgInfluxDBOrg))
+
+	// Get task client
+	taskAPI := client.TasksAPI()
+
+	if writeAPI == nil || queryAPI == nil || taskAPI == nil {
+		return nil, fmt.Errorf("Failed to connect to influxdb!")
+	}
+
+	store := &influxTimeSeriesStore{
+		appConfig:      appConfig,
+		logger:         globalLogger,
+		influxClient:   client,
+		influxWriteApi: writeAPI,
+		influxQueryApi: queryAPI,
+		influxTaskApi:  taskAPI,
+	}
+
+	orgInfo, err := client.OrganizationsAPI().FindOrganizationByName(ctx, appConfig.GetString(cfgInfluxDBOrg))
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize Buckets (if necessary)
+	err = store.EnsureBuckets(ctx, orgInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize Background Tasks
+	err = store.EnsureTasks(ctx, *orgInfo.Id)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// setupInfluxClient creates the InfluxDB client and runs first-time setup when the server is
+// un-initialized.
+func setupInfluxClient(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger) (influxdb2.Client, error) {
+	// Create a new client using an InfluxDB server base URL and an authentication token
+	influxdbUrl := fmt.Sprintf("%s://%s:%s", appConfig.GetString("web.influxdb.scheme"), appConfig.GetString("web.influxdb.host"), appConfig.GetString("web.influxdb.port"))
+	globalLogger.Debugf("InfluxDB url: %s", influxdbUrl)
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: appConfig.GetBool("web.influxdb.tls.insecure_skip_verify"),
+	}
+	globalLogger.Infof("InfluxDB certificate verification: %t\n", !tlsConfig.InsecureSkipVerify)
+
+	client := influxdb2.NewClientWithOptions(
+		influxdbUrl,
+		appConfig.GetString("web.influxdb.token"),
+		influxdb2.DefaultOptions().SetTLSConfig(tlsConfig),
+	)
+
+	globalLogger.Debugf("Determine Influxdb setup status...")
+	influxSetupComplete, err := InfluxSetupComplete(influxdbUrl, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check influxdb setup status - %w", err)
+	}
+
+	if !influxSetupComplete {
+		globalLogger.Debugf("Influxdb un-initialized, running first-time setup...")
+
+		// if no token is provided, but we have a valid server, we're going to assume this is the first setup of our server.
+		// we will initialize with a predetermined username & password, that you should change.
+
+		// metrics bucket will have a retention period of 8 days (since it will be down-sampled once a week)
+		// in seconds (60seconds * 60minutes * 24hours * 15 days) = 1_296_000 (see EnsureBucket() function)
+		_, err := client.SetupWithToken(
+			ctx,
+			appConfig.GetString("web.influxdb.init_username"),
+			appConfig.GetString("web.influxdb.init_password"),
+			appConfig.GetString(cfgInfluxDBOrg),
+			appConfig.GetString(cfgInfluxDBBucket),
+			0,
+			appConfig.GetString("web.influxdb.token"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func InfluxSetupComplete(influxEndpoint string, tlsConfig *tls.Config) (bool, error) {
+	influxUri, err := url.Parse(influxEndpoint)
+	if err != nil {
+		return false, err
+	}
+	influxUri, err = influxUri.Parse("/api/v2/setup")
+	if err != nil {
+		return false, err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   10 * time.Second,
+	}
+	res, err := client.Get(influxUri.String())
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+
+	type SetupStatus struct {
+		Allowed bool `json:"allowed"`
+	}
+	var data SetupStatus
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return false, err
+	}
+	return !data.Allowed, nil
+}
+
+func (is *influxTimeSeriesStore) Close() {
+	is.influxClient.Close()
+}
+
+func (is *influxTimeSeriesStore) HealthCheck(ctx context.Context) (string, HealthCheckStatus) {
+	// Check InfluxDB health with latency measurement
+	influxStart := time.Now()
+	status, err := is.influxClient.Health(ctx)
+	influxLatency := time.Since(influxStart).Milliseconds()
+
+	if err != nil {
+		return "influxdb", HealthCheckStatus{
+			Status:    "error",
+			LatencyMs: influxLatency,
+			Error:     err.Error(),
+		}
+	} else if status.Status != "pass" {
+		return "influxdb", HealthCheckStatus{
+			Status:    "error",
+			LatencyMs: influxLatency,
+			Error:     fmt.Sprintf("influxdb status: %s", status.Status),
+		}
+	}
+	return "influxdb", HealthCheckStatus{
+		Status:    "ok",
+		LatencyMs: influxLatency,
+	}
+}
+
+func (is *influxTimeSeriesStore) BucketNames(ctx context.Context) ([]string, error) {
+	org := is.appConfig.GetString(cfgInfluxDBOrg)
+
+	// Query InfluxDB for all buckets in the organization
+	buckets, err := is.influxClient.BucketsAPI().FindBucketsByOrgName(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query InfluxDB buckets: %w", err)
+	}
+
+	bucketNames := make([]string, 0, len(*buckets))
+	for _, bucket := range *buckets {
+		bucketNames = append(bucketNames, bucket.Name)
+	}
+
+	return bucketNames, nil
+}
+
+func (is *influxTimeSeriesStore) EnsureBuckets(ctx context.Context, org *domain.Organization) error {
+	// in tests, we may not want to set a retention policy. If "false", we can set data with old timestamps,
+	// then manually run the down sampling scripts. This should be true for production environments.
+	applyRetention := is.appConfig.GetBool(cfgInfluxDBRetentionPolicy)
+
+	var mainRule, weeklyRule, monthlyRule domain.RetentionRule
+	if applyRetention {
+		mainRule = domain.RetentionRule{EverySeconds: int64(is.appConfig.GetInt(cfgRetentionDaily))}
+		weeklyRule = domain.RetentionRule{EverySeconds: int64(is.appConfig.GetInt(cfgRetentionWeekly))}
+		monthlyRule = domain.RetentionRule{EverySeconds: int64(is.appConfig.GetInt(cfgRetentionMonthly))}
+	}
+
+	baseBucket := is.appConfig.GetString(cfgInfluxDBBucket)
+	// main bucket plus the weekly/monthly down-sampling buckets
+	if err := is.ensureRetentionBucket(ctx, org, baseBucket, mainRule, applyRetention); err != nil {
+		return err
+	}
+	if err := is.ensureRetentionBucket(ctx, org, baseBucket+"_weekly", weeklyRule, applyRetention); err != nil {
+		return err
+	}
+	if err := is.ensureRetentionBucket(ctx, org, baseBucket+"_monthly", monthlyRule, applyRetention); err != nil {
+		return err
+	}
+
+	// metrics_yearly bucket will have an infinite retention period
+	yearlyBucket := baseBucket + "_yearly"
+	if _, foundErr := is.influxClient.BucketsAPI().FindBucketByName(ctx, yearlyBucket); foundErr != nil {
+		if _, err := is.influxClient.BucketsAPI().CreateBucketWithName(ctx, org, yearlyBucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureRetentionBucket creates bucketName with the given retention rule when it does not exist,
+// or (when applyRetention is set) updates the existing bucket's retention rule.
+func (is *influxTimeSeriesStore) ensureRetentionBucket(ctx context.Context, org *domain.Organization, bucketName string, retentionRule domain.RetentionRule, applyRetention bool) error {
+	found, foundErr := is.influxClient.BucketsAPI().FindBucketByName(ctx, bucketName)
+	if foundErr != nil {
+		_, err := is.influxClient.BucketsAPI().CreateBucketWithName(ctx, org, bucketName, retentionRule)
+		return err
+	}
+	if applyRetention {
+		// correctly set the retention period (may not be able to do it during setup/creation)
+		found.RetentionRules = domain.RetentionRules{retentionRule}
+		_, _ = is.influxClient.BucketsAPI().UpdateBucket(ctx, found)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Writes & Deletes
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (is *influxTimeSeriesStore) WritePoint(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error {
+	return saveDatapoint(is.influxWriteApi, measurement, tags, fields, date, ctx)
+}
+
+func (is *influxTimeSeriesStore) WriteBucketPoint(ctx context.Context, bucket string, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error {
+	writeAPI := is.influxClient.WriteAPIBlocking(is.appConfig.GetString(cfgInfluxDBOrg), bucket)
+	return saveDatapoint(writeAPI, measurement, tags, fields, date, ctx)
+}
+
+func (is *influxTimeSeriesStore) DeleteSeries(ctx context.Context, tagKey string, tagValue string) error {
+	for _, bucket := range historyBuckets(is.appConfig.GetString(cfgInfluxDBBucket)) {
+		is.logger.Infof("Deleting %s=%s data in bucket: %s", tagKey, tagValue, bucket)
+		if err := is.influxClient.DeleteAPI().DeleteWithName(
+			ctx,
+			is.appConfig.GetString(cfgInfluxDBOrg),
+			bucket,
+			time.Now().AddDate(-10, 0, 0),
+			time.Now(),
+			fmt.Sprintf(`%s=%q`, tagKey, tagValue),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportBucket streams the raw records of bucket. InfluxDB returns one record per field, so every
+// exported point carries a single field; re-importing merges them back into the same series/time.
+func (is *influxTimeSeriesStore) ExportBucket(ctx context.Context, bucket string, fn func(point TimeSeriesExportPoint) error) error {
+	queryStr := fmt.Sprintf(`
+from(bucket: "%s")
+|> range(start: 1970-01-01T00:00:00Z)
+`, bucket)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		record := result.Record()
+		tags := map[string]string{}
+		for key, value := range record.Values() {
+			switch key {
+			case "_start", "_stop", "_time", "_value", "_field", "_measurement", "result", "table":
+				continue
+			}
+			if tagValue, ok := value.(string); ok {
+				tags[key] = tagValue
+			}
+		}
+		point := TimeSeriesExportPoint{
+			Bucket:      bucket,
+			Measurement: record.Measurement(),
+			Tags:        tags,
+			Fields:      map[string]interface{}{record.Field(): record.Value()},
+			Time:        record.Time(),
+		}
+		if err := fn(point); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
+
+func (is *influxTimeSeriesStore) CopyDeviceHistory(ctx context.Context, sourceWWN string, destinationDevice *models.Device) error {
+	for _, bucket := range historyBuckets(is.appConfig.GetString(cfgInfluxDBBucket)) {
+		for _, measurement := range []string{"smart", "temp", "performance"} {
+			rows, err := is.queryDeviceMeasurementRows(ctx, bucket, measurement, sourceWWN)
+			if err != nil {
+				return fmt.Errorf("could not query %s history in bucket %s: %w", measurement, bucket, err)
+			}
+			for _, values := range rows {
+				fields := measurementFields(measurement, values)
+				if len(fields) == 0 {
+					continue
+				}
+				tags := measurementTags(measurement, values, destinationDevice)
+				if err := saveDatapoint(is.influxWriteApi, measurement, tags, fields, values["_time"].(time.Time), ctx); err != nil {
+					return fmt.Errorf("could not write %s history in bucket %s: %w", measurement, bucket, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (is *influxTimeSeriesStore) queryDeviceMeasurementRows(ctx context.Context, bucket string, measurement string, sourceWWN string) ([]timeSeriesRow, error) {
+	if sourceWWN == "" {
+		return nil, nil
+	}
+
+	queryStr := fmt.Sprintf(`
+from(bucket: "%s")
+|> range(start: -10y, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "%s")
+|> filter(fn: (r) => r["device_wwn"] == "%s")
+|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+|> sort(columns: ["_time"], desc: false)
+`, bucket, measurement, sourceWWN)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// SMART
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (is *influxTimeSeriesStore) QuerySmartHistory(ctx context.Context, wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) ([]timeSeriesRow, error) {
+	// Get parser flux query result
+	// Note: WWN is validated at the handler level before reaching this function
+	queryStr := is.aggregateSmartAttributesQuery(wwn, durationKey, selectEntries, selectEntriesOffset, attributes)
+	is.logger.Infoln(queryStr)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return is.collectRowsLoggingErrors(result), nil
+}
+
+func (is *influxTimeSeriesStore) QuerySmartSubmission(ctx context.Context, wwn string, offset int) ([]timeSeriesRow, error) {
+	// Query raw data from the metrics bucket (last week) without aggregation
+	queryStr := fmt.Sprintf(`
+import "influxdata/influxdb/schema"
+from(bucket: "%s")
+|> range(start: -1w, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "smart")
+|> filter(fn: (r) => r["device_wwn"] == "%s")
+|> schema.fieldsAsCols()
+|> group()
+|> sort(columns: ["_time"], desc: true)
+|> limit(n: 1, offset: %d)
+`, is.appConfig.GetString(cfgInfluxDBBucket), wwn, offset)
+
+	is.logger.Debugln("QuerySmartSubmission query:", queryStr)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+func (is *influxTimeSeriesStore) QuerySmartSummary(ctx context.Context) ([]timeSeriesRow, error) {
+	result, err := is.influxQueryApi.Query(ctx, summaryFluxQuery(is.appConfig.GetString(cfgInfluxDBBucket)))
+	if err != nil {
+		return nil, err
+	}
+	return is.collectRowsLoggingErrors(result), nil
+}
+
+func (is *influxTimeSeriesStore) QueryLastSeen(ctx context.Context) ([]timeSeriesRow, error) {
+	result, err := is.influxQueryApi.Query(ctx, lastSeenFluxQuery(is.appConfig.GetString(cfgInfluxDBBucket)))
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+// summaryFluxQuery builds the flux query that fetches the latest summary metrics (basic metrics +
+// SSD health + risk indicator attributes) per device across the daily/weekly/monthly/yearly buckets.
+func summaryFluxQuery(bucketBaseName string) string {
+	return fmt.Sprintf(`
+  	import "influxdata/influxdb/schema"
+  	bucketBaseName = "%s"
+
+	// Fields to retrieve for summary (basic metrics + SSD health + risk indicator attributes)
+	summaryFields = (r) =>
+		r["_field"] == "temp" or
+		r["_field"] == "power_on_hours" or
+		r["_field"] == "date" or
+		r["_field"] == "attr.percentage_used.value" or
+		r["_field"] == "attr.devstat_7_8.raw_value" or
+		r["_field"] == "attr.177.value" or
+		r["_field"] == "attr.233.value" or
+		r["_field"] == "attr.231.value" or
+		r["_field"] == "attr.232.value" or
+		r["_field"] == "attr.5.raw_value" or
+		r["_field"] == "attr.197.raw_value" or
+		r["_field"] == "attr.198.raw_value" or
+		r["_field"] == "attr.media_errors.value" or
+		r["_field"] == "attr.scsi_grown_defect_list.value"
+
+	dailyData = from(bucket: bucketBaseName)
+	|> range(start: -10y, stop: now())
+	|> filter(fn: (r) => r["_measurement"] == "smart" )
+	|> filter(fn: summaryFields)
+	|> last()
+	|> schema.fieldsAsCols()
+	|> group(columns: ["device_wwn"])
+
+	weeklyData = from(bucket: bucketBaseName + "_weekly")
+	|> range(start: -10y, stop: now())
+	|> filter(fn: (r) => r["_measurement"] == "smart" )
+	|> filter(fn: summaryFields)
+	|> last()
+	|> schema.fieldsAsCols()
+	|> group(columns: ["device_wwn"])
+
+	monthlyData = from(bucket: bucketBaseName + "_monthly")
+	|> range(start: -10y, stop: now())
+	|> filter(fn: (r) => r["_measurement"] == "smart" )
+	|> filter(fn: summaryFields)
+	|> last()
+	|> schema.fieldsAsCols()
+	|> group(columns: ["device_wwn"])
+
+	yearlyData = from(bucket: bucketBaseName + "_yearly")
+	|> range(start: -10y, stop: now())
+	|> filter(fn: (r) => r["_measurement"] == "smart" )
+	|> filter(fn: summaryFields)
+	|> last()
+	|> schema.fieldsAsCols()
+	|> group(columns: ["device_wwn"])
+
+	union(tables: [dailyData, weeklyData, monthlyData, yearlyData])
+	|> sort(columns: ["_time"], desc: false)
+	|> group(columns: ["device_wwn"])
+	|> tail(n: 1)
+	|> yield(name: "last")
+		`,
+		bucketBaseName,
+	)
+}
+
+// lastSeenFluxQuery builds the flux query that returns the most recent SMART submission time per
+// device across all buckets. The "temp" field is used because it is always present in SMART data
+// (Date is stored as the point timestamp _time, not a field).
+func lastSeenFluxQuery(bucketBaseName string) string {
+	return fmt.Sprintf(`
+import "influxdata/influxdb/schema"
+bucketBaseName = "%s"
+
+dailyData = from(bucket: bucketBaseName)
+|> range(start: -10y, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "smart")
+|> filter(fn: (r) => r["_field"] == "temp")
+|> last()
+|> group(columns: ["device_wwn"])
+
+weeklyData = from(bucket: bucketBaseName + "_weekly")
+|> range(start: -10y, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "smart")
+|> filter(fn: (r) => r["_field"] == "temp")
+|> last()
+|> group(columns: ["device_wwn"])
+
+monthlyData = from(bucket: bucketBaseName + "_monthly")
+|> range(start: -10y, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "smart")
+|> filter(fn: (r) => r["_field"] == "temp")
+|> last()
+|> group(columns: ["device_wwn"])
+
+yearlyData = from(bucket: bucketBaseName + "_yearly")
+|> range(start: -10y, stop: now())
+|> filter(fn: (r) => r["_measurement"] == "smart")
+|> filter(fn: (r) => r["_field"] == "temp")
+|> last()
+|> group(columns: ["device_wwn"])
+
+union(tables: [dailyData, weeklyData, monthlyData, yearlyData])
+|> group(columns: ["device_wwn"])
+|> sort(columns: ["_time"], desc: false)
+|> last()
+|> yield(name: "last_seen")
+	`, bucketBaseName)
+}
+
+func (is *influxTimeSeriesStore) aggregateSmartAttributesQuery(wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) string {
+
+	/*
+
+		import "influxdata/influxdb/schema"
+		weekData = from(bucket: "metrics")
+		|> range(start: -1w, stop: now())
+		|> filter(fn: (r) => r["_measurement"] == "smart" )
+		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
+		|> tail(n: 10, offset: 0)
+		|> schema.fieldsAsCols()
+
+		monthData = from(bucket: "metrics_weekly")
+		|> range(start: -1mo, stop: -1w)
+		|> filter(fn: (r) => r["_measurement"] == "smart" )
+		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
+		|> tail(n: 10, offset: 0)
+		|> schema.fieldsAsCols()
+
+		yearData = from(bucket: "metrics_monthly")
+		|> range(start: -1y, stop: -1mo)
+		|> filter(fn: (r) => r["_measurement"] == "smart" )
+		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
+		|> tail(n: 10, offset: 0)
+		|> schema.fieldsAsCols()
+
+		foreverData = from(bucket: "metrics_yearly")
+		|> range(start: -10y, stop: -1y)
+		|> filter(fn: (r) => r["_measurement"] == "smart" )
+		|> filter(fn: (r) => r["device_wwn"] == "0x5000c5002df89099" )
+		|> tail(n: 10, offset: 0)
+		|> schema.fieldsAsCols()
+
+		union(tables: [weekData, monthData, yearData, foreverData])
+		|> group()
+		|> sort(columns: ["_time"], desc: true)
+		|> tail(n: 6, offset: 4)
+		|> yield(name: "last")
+
+	*/
+
+	partialQueryStr := []string{
+		`import "influxdata/influxdb/schema"`,
+	}
+
+	nestedDurationKeys := lookupNestedDurationKeys(durationKey)
+
+	if len(nestedDurationKeys) == 1 {
+		//there's only one bucket being queried, no need to union, just aggregate the dataset and return
+		subqueryParts := []string{
+			is.generateSmartAttributesSubquery(wwn, nestedDurationKeys[0], 0, 0, attributes),
+			fmt.Sprintf(`%sData`, nestedDurationKeys[0]),
+			`|> sort(columns: ["_time"], desc: true)`,
+		}
+		if selectEntries > 0 {
+			// Use limit() instead of tail() after desc sort to get the newest entries
+			subqueryParts = append(subqueryParts, fmt.Sprintf(`|> limit(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
+		}
+		subqueryParts = append(subqueryParts, `|> yield()`)
+		partialQueryStr = append(partialQueryStr, subqueryParts...)
+		return strings.Join(partialQueryStr, "\n")
+	}
+
+	subQueries := []string{}
+	subQueryNames := []string{}
+	for _, nestedDurationKey := range nestedDurationKeys {
+		subQueryNames = append(subQueryNames, fmt.Sprintf(`%sData`, nestedDurationKey))
+		if selectEntries > 0 {
+			// We only need the last `n + offset` # of entries from each table to guarantee we can
+			// get the last `n` # of entries starting from `offset` of the union
+			subQueries = append(subQueries, is.generateSmartAttributesSubquery(wwn, nestedDurationKey, selectEntries+selectEntriesOffset, 0, attributes))
+		} else {
+			subQueries = append(subQueries, is.generateSmartAttributesSubquery(wwn, nestedDurationKey, 0, 0, attributes))
+		}
+	}
+	partialQueryStr = append(partialQueryStr, subQueries...)
+	partialQueryStr = append(partialQueryStr, []string{
+		fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", ")),
+		`|> group()`,
+		`|> sort(columns: ["_time"], desc: true)`,
+	}...)
+	if selectEntries > 0 {
+		// Use limit() instead of tail() after desc sort to get the newest entries
+		// tail() would get the oldest entries from the end, but we want the newest from the beginning
+		partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> limit(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
+	}
+	partialQueryStr = append(partialQueryStr, `|> yield(name: "last")`)
+
+	return strings.Join(partialQueryStr, "\n")
+}
+
+// generateSmartAttributesSubquery generates a subquery for SMART attributes.
+// Note: WWN is validated at the handler level before reaching this function.
+func (is *influxTimeSeriesStore) generateSmartAttributesSubquery(wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) string {
+	bucketName := is.lookupBucketName(durationKey)
+	durationRange := is.lookupDuration(durationKey)
+
+	partialQueryStr := []string{
+		fmt.Sprintf(`%sData = from(bucket: "%s")`, durationKey, bucketName),
+		fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
+		`|> filter(fn: (r) => r["_measurement"] == "smart" )`,
+		fmt.Sprintf(`|> filter(fn: (r) => r["device_wwn"] == "%s" )`, wwn),
+	}
+
+	partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> aggregateWindow(every: %s, fn: last, createEmpty: false)`, RESOLUTION_1_DAY))
+
+	if selectEntries > 0 {
+		partialQueryStr = append(partialQueryStr, fmt.Sprintf(`|> tail(n: %d, offset: %d)`, selectEntries, selectEntriesOffset))
+	}
+	partialQueryStr = append(partialQueryStr, "|> schema.fieldsAsCols()")
+
+	return strings.Join(partialQueryStr, "\n")
+}
+
+// StreamDeviceSeries queries each bucket covered by durationKey separately (oldest bucket first) and
+// hands every record to fn as it is decoded, so large histories are never held in memory.
+// Note: WWN is validated at the handler level before reaching this function.
+func (is *influxTimeSeriesStore) StreamDeviceSeries(ctx context.Context, measurement string, wwn string, durationKey string, fn func(row timeSeriesRow) error) error {
+	nestedDurationKeys := lookupNestedDurationKeys(durationKey)
+	for i := len(nestedDurationKeys) - 1; i >= 0; i-- {
+		queryStr := is.deviceSeriesQuery(measurement, wwn, nestedDurationKeys[i])
+		is.logger.Debugln("StreamDeviceSeries query:", queryStr)
+
+		result, err := is.influxQueryApi.Query(ctx, queryStr)
+		if err != nil {
+			return err
+		}
+		for result.Next() {
+			if err := fn(result.Record().Values()); err != nil {
+				result.Close()
+				return err
+			}
+		}
+		err = result.Err()
+		result.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (is *influxTimeSeriesStore) deviceSeriesQuery(measurement string, wwn string, durationKey string) string {
+	durationRange := is.lookupDuration(durationKey)
+	return strings.Join([]string{
+		`import "influxdata/influxdb/schema"`,
+		fmt.Sprintf(`from(bucket: "%s")`, is.lookupBucketName(durationKey)),
+		fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
+		fmt.Sprintf(`|> filter(fn: (r) => r["_measurement"] == "%s" )`, measurement),
+		fmt.Sprintf(`|> filter(fn: (r) => r["device_wwn"] == "%s" )`, wwn),
+		`|> schema.fieldsAsCols()`,
+		`|> group()`,
+		`|> sort(columns: ["_time"])`,
+	}, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Temperature
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (is *influxTimeSeriesStore) QueryTemperatureHistory(ctx context.Context, durationKey string) ([]timeSeriesRow, error) {
+	result, err := is.influxQueryApi.Query(ctx, is.aggregateTempQuery(durationKey))
+	if err != nil {
+		return nil, err
+	}
+	return is.collectRowsLoggingErrors(result), nil
+}
+
+func (is *influxTimeSeriesStore) aggregateTempQuery(durationKey string) string {
+
+	/*
+		import "influxdata/influxdb/schema"
+		weekData = from(bucket: "metrics")
+		  |> range(start: -1w, stop: now())
+		  |> filter(fn: (r) => r["_measurement"] == "temp" )
+		  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+		  |> group(columns: ["device_wwn"])
+		  |> toInt()
+
+		monthData = from(bucket: "metrics_weekly")
+		  |> range(start: -1mo, stop: now())
+		  |> filter(fn: (r) => r["_measurement"] == "temp" )
+		  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+		  |> group(columns: ["device_wwn"])
+		  |> toInt()
+
+		union(tables: [weekData, monthData])
+		  |> group(columns: ["device_wwn"])
+		  |> sort(columns: ["_time"], desc: false)
+		  |> schema.fieldsAsCols()
+
+	*/
+
+	partialQueryStr := []string{
+		`import "influxdata/influxdb/schema"`,
+	}
+
+	nestedDurationKeys := lookupNestedDurationKeys(durationKey)
+
+	subQueryNames := []string{}
+	for _, nestedDurationKey := range nestedDurationKeys {
+		bucketName := is.lookupBucketName(nestedDurationKey)
+		durationRange := is.lookupDuration(nestedDurationKey)
+		durationResolution := lookupResolution(nestedDurationKey)
+
+		subQueryNames = append(subQueryNames, fmt.Sprintf(`%sData`, nestedDurationKey))
+		subQuery := []string{
+			fmt.Sprintf(`%sData = from(bucket: "%s")`, nestedDurationKey, bucketName),
+			fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
+			`|> filter(fn: (r) => r["_measurement"] == "temp" )`,
+		}
+		if durationResolution != "" {
+			subQuery = append(subQuery,
+				fmt.Sprintf(`|> aggregateWindow(every: %s, fn: mean, createEmpty: false)`, durationResolution))
+		}
+		subQuery = append(subQuery, `|> group(columns: ["device_wwn"])`, `|> toInt()`, "")
+		partialQueryStr = append(partialQueryStr, subQuery...)
+	}
+
+	if len(subQueryNames) == 1 {
+		//there's only one bucket being queried, no need to union, just aggregate the dataset and return
+		partialQueryStr = append(partialQueryStr, []string{
+			subQueryNames[0],
+			"|> schema.fieldsAsCols()",
+			"|> yield()",
+		}...)
+	} else {
+		partialQueryStr = append(partialQueryStr, []string{
+			fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", ")),
+			`|> group(columns: ["device_wwn"])`,
+			`|> sort(columns: ["_time"], desc: false)`,
+			"|> schema.fieldsAsCols()",
+		}...)
+	}
+
+	return strings.Join(partialQueryStr, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Workload
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (is *influxTimeSeriesStore) QueryWorkloadFirstLast(ctx context.Context, durationKey string) ([]timeSeriesRow, []timeSeriesRow, error) {
+	queryStr := is.buildWorkloadFirstLastQuery(durationKey)
+	is.logger.Debugln("Workload first/last query:", queryStr)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close()
+
+	first := []timeSeriesRow{}
+	last := []timeSeriesRow{}
+	for result.Next() {
+		// Determine if this is a "first" or "last" result based on the yield name
+		if result.Record().Result() == "first" {
+			first = append(first, result.Record().Values())
+		} else {
+			// "last" result or default
+			last = append(last, result.Record().Values())
+		}
+	}
+	if result.Err() != nil {
+		return nil, nil, result.Err()
+	}
+	return first, last, nil
+}
+
+func (is *influxTimeSeriesStore) QueryWorkloadRecent(ctx context.Context) ([]timeSeriesRow, error) {
+	queryStr := is.buildWorkloadRecentQuery()
+	is.logger.Debugln("Workload recent query:", queryStr)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+func (is *influxTimeSeriesStore) buildWorkloadFirstLastQuery(durationKey string) string {
+	partialQueryStr := []string{
+		`import "influxdata/influxdb/schema"`,
+		``,
+		`workloadFields = (r) =>`,
+		`    r["_field"] == "power_on_hours" or`,
+		`    r["_field"] == "logical_block_size" or`,
+		`    r["_field"] == "attr.241.raw_value" or`,
+		`    r["_field"] == "attr.242.raw_value" or`,
+		`    r["_field"] == "attr.devstat_1_24.value" or`,
+		`    r["_field"] == "attr.devstat_1_40.value" or`,
+		`    r["_field"] == "attr.data_units_written.value" or`,
+		`    r["_field"] == "attr.data_units_read.value" or`,
+		`    r["_field"] == "attr.percentage_used.value" or`,
+		`    r["_field"] == "attr.devstat_7_8.value" or`,
+		`    r["_field"] == "attr.177.value" or`,
+		`    r["_field"] == "attr.231.value" or`,
+		`    r["_field"] == "attr.232.value" or`,
+		`    r["_field"] == "attr.233.value"`,
+		``,
+	}
+
+	nestedDurationKeys := lookupNestedDurationKeys(durationKey)
+	subQueryNames := []string{}
+
+	for _, nestedDurationKey := range nestedDurationKeys {
+		bucketName := is.lookupBucketName(nestedDurationKey)
+		durationRange := is.lookupDuration(nestedDurationKey)
+		subQueryName := fmt.Sprintf(`%sData`, nestedDurationKey)
+		subQueryNames = append(subQueryNames, subQueryName)
+
+		partialQueryStr = append(partialQueryStr, []string{
+			fmt.Sprintf("%s = from(bucket: %q)", subQueryName, bucketName),
+			fmt.Sprintf(`|> range(start: %s, stop: %s)`, durationRange[0], durationRange[1]),
+			`|> filter(fn: (r) => r["_measurement"] == "smart")`,
+			`|> filter(fn: workloadFields)`,
+			``,
+		}...)
+	}
+
+	var combinedExpr string
+	if len(subQueryNames) == 1 {
+		combinedExpr = subQueryNames[0]
+	} else {
+		combinedExpr = fmt.Sprintf("union(tables: [%s])", strings.Join(subQueryNames, ", "))
+	}
+
+	partialQueryStr = append(partialQueryStr, []string{
+		fmt.Sprintf(`combined = %s`, combinedExpr),
+		`|> schema.fieldsAsCols()`,
+		// Filter out rows where all counter fields are null or zero.
+		// Downsampled buckets can contain entries with zero-filled or missing counter
+		// fields (e.g. from before a device started reporting a particular attribute).
+		// Using such a row as the "first" point makes the delta equal to the device's
+		// entire lifetime of cumulative writes, grossly inflating daily rates.
+		`|> filter(fn: (r) =>`,
+		`    (exists r["attr.241.raw_value"] and r["attr.241.raw_value"] > 0) or`,
+		`    (exists r["attr.242.raw_value"] and r["attr.242.raw_value"] > 0) or`,
+		`    (exists r["attr.devstat_1_24.value"] and r["attr.devstat_1_24.value"] > 0) or`,
+		`    (exists r["attr.devstat_1_40.value"] and r["attr.devstat_1_40.value"] > 0) or`,
+		`    (exists r["attr.data_units_written.value"] and r["attr.data_units_written.value"] > 0) or`,
+		`    (exists r["attr.data_units_read.value"] and r["attr.data_units_read.value"] > 0)`,
+		`)`,
+		`|> group(columns: ["device_wwn"])`,
+		``,
+		`combined`,
+		`|> sort(columns: ["_time"], desc: false)`,
+		`|> limit(n: 1)`,
+		`|> yield(name: "first")`,
+		``,
+		`combined`,
+		`|> sort(columns: ["_time"], desc: true)`,
+		`|> limit(n: 1)`,
+		`|> yield(name: "last")`,
+	}...)
+
+	return strings.Join(partialQueryStr, "\n")
+}
+
+func (is *influxTimeSeriesStore) buildWorkloadRecentQuery() string {
+	bucketName := is.appConfig.GetString(cfgInfluxDBBucket)
+
+	return strings.Join([]string{
+		`import "influxdata/influxdb/schema"`,
+		``,
+		`workloadFields = (r) =>`,
+		`    r["_field"] == "power_on_hours" or`,
+		`    r["_field"] == "logical_block_size" or`,
+		`    r["_field"] == "attr.241.raw_value" or`,
+		`    r["_field"] == "attr.242.raw_value" or`,
+		`    r["_field"] == "attr.devstat_1_24.value" or`,
+		`    r["_field"] == "attr.devstat_1_40.value" or`,
+		`    r["_field"] == "attr.data_units_written.value" or`,
+		`    r["_field"] == "attr.data_units_read.value"`,
+		``,
+		fmt.Sprintf("from(bucket: %q)", bucketName),
+		`|> range(start: -1w, stop: now())`,
+		`|> filter(fn: (r) => r["_measurement"] == "smart")`,
+		`|> filter(fn: workloadFields)`,
+		`|> schema.fieldsAsCols()`,
+		`|> group(columns: ["device_wwn"])`,
+		`|> sort(columns: ["_time"], desc: true)`,
+		`|> limit(n: 3)`,
+	}, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Performance, ZFS, MDADM & Btrfs
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (is *influxTimeSeriesStore) QueryPerformanceHistory(ctx context.Context, wwn string, durationKey string) ([]timeSeriesRow, error) {
+	bucketName := is.lookupBucketName(durationKey)
+	duration := is.lookupDuration(durationKey)
+
+	queryStr := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r["_measurement"] == "performance")
+		|> filter(fn: (r) => r["device_wwn"] == "%s")
+		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"], desc: false)
+	`, bucketName, duration[0], duration[1], wwn)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+func (is *influxTimeSeriesStore) QueryPerformanceRecent(ctx context.Context, wwn string, count int) ([]timeSeriesRow, error) {
+	bucketName := is.appConfig.GetString(cfgInfluxDBBucket)
+
+	queryStr := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: -30d)
+		|> filter(fn: (r) => r["_measurement"] == "performance")
+		|> filter(fn: (r) => r["device_wwn"] == "%s")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"], desc: true)
+		|> limit(n: %d)
+	`, bucketName, wwn, count)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+// QueryZFSPoolHistory uses a parameterized query to prevent Flux injection.
+func (is *influxTimeSeriesStore) QueryZFSPoolHistory(ctx context.Context, guid string, durationKey string) ([]timeSeriesRow, error) {
+	// Map duration key to actual duration and bucket
+	bucketName := is.lookupBucketName(durationKey)
+	duration := is.lookupDuration(durationKey)
+
+	queryStr := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r["_measurement"] == "zfs_pool")
+		|> filter(fn: (r) => r["pool_guid"] == params.guid)
+		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"], desc: false)
+	`, bucketName, duration[0], duration[1])
+
+	result, err := is.influxQueryApi.QueryWithParams(ctx, queryStr, map[string]interface{}{"guid": guid})
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+// QueryMdadmHistory uses schema.fieldsAsCols() instead of aggregateWindow+pivot to preserve
+// string fields (state, raw_mdstat) that aggregateWindow(fn: last) silently drops.
+func (is *influxTimeSeriesStore) QueryMdadmHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error) {
+	bucketName := is.lookupBucketName(durationKey)
+	duration := is.lookupDuration(durationKey)
+
+	queryStr := fmt.Sprintf(`
+		import "influxdata/influxdb/schema"
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r["_measurement"] == "mdadm_array")
+		|> filter(fn: (r) => r["array_uuid"] == "%s")
+		|> schema.fieldsAsCols()
+		|> group()
+		|> sort(columns: ["_time"], desc: false)
+	`, bucketName, duration[0], duration[1], uuid)
+
+	is.logger.Debugf("QueryMdadmHistory query for uuid=%s bucket=%s", uuid, bucketName)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+// QueryMdadmLatest uses schema.fieldsAsCols() to correctly merge string and numeric fields into a single row.
+func (is *influxTimeSeriesStore) QueryMdadmLatest(ctx context.Context, uuid string) ([]timeSeriesRow, error) {
+	bucketName := is.appConfig.GetString(cfgInfluxDBBucket)
+
+	queryStr := fmt.Sprintf(`
+		import "influxdata/influxdb/schema"
+		from(bucket: "%s")
+		|> range(start: -7d)
+		|> filter(fn: (r) => r["_measurement"] == "mdadm_array")
+		|> filter(fn: (r) => r["array_uuid"] == "%s")
+		|> schema.fieldsAsCols()
+		|> group()
+		|> sort(columns: ["_time"], desc: true)
+		|> limit(n: 1)
+	`, bucketName, uuid)
+
+	result, err := is.influxQueryApi.Query(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+func (is *influxTimeSeriesStore) QueryBtrfsHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error) {
+	bucketName := is.lookupBucketName(durationKey)
+	duration := is.lookupDuration(durationKey)
+	queryStr := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r["_measurement"] == "btrfs_filesystem")
+		|> filter(fn: (r) => r["filesystem_uuid"] == params.uuid)
+		|> aggregateWindow(every: 1h, fn: last, createEmpty: false)
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"], desc: false)
+	`, bucketName, duration[0], duration[1])
+
+	result, err := is.influxQueryApi.QueryWithParams(ctx, queryStr, map[string]interface{}{"uuid": uuid})
+	if err != nil {
+		return nil, err
+	}
+	return collectInfluxRows(result)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Helper Methods
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func saveDatapoint(influxWriteApi api.WriteAPIBlocking, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time, ctx context.Context) error {
+	p := influxdb2.NewPoint(measurement,
+		tags,
+		fields,
+		date)
+
+	// write point immediately
+	return influxWriteApi.WritePoint(ctx, p)
+}
+
+// collectInfluxRows drains a query result into rows, returning any iteration error.
+func collectInfluxRows(result *api.QueryTableResult) ([]timeSeriesRow, error) {
+	defer result.Close()
+	rows := []timeSeriesRow{}
+	for result.Next() {
+		rows = append(rows, result.Record().Values())
+	}
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+	return rows, nil
+}
+
+// collectRowsLoggingErrors drains a query result into rows, logging (rather than returning) any
+// iteration error so partially read results are still usable.
+func (is *influxTimeSeriesStore) collectRowsLoggingErrors(result *api.QueryTableResult) []timeSeriesRow {
+	defer result.Close()
+	rows := []timeSeriesRow{}
+	for result.Next() {
+		rows = append(rows, result.Record().Values())
+	}
+	if result.Err() != nil {
+		is.logger.Errorf("Query error: %s", result.Err().Error())
+	}
+	return rows
+}
+
+func (is *influxTimeSeriesStore) lookupBucketName(durationKey string) string {
+	return lookupBucketName(is.appConfig.GetString(cfgInfluxDBBucket), durationKey)
+}
+
+func (is *influxTimeSeriesStore) lookupDuration(durationKey string) []string {
+	switch durationKey {
+	case DURATION_KEY_DAY:
+		//data stored in the last day
+		return []string{INFLUX_DURATION_1_DAY, INFLUX_NOW}
+	case DURATION_KEY_WEEK:
+		//data stored in the last week
+		return []string{INFLUX_DURATION_1_WEEK, INFLUX_NOW}
+	case DURATION_KEY_MONTH:
+		// data stored in the last month (after the first week)
+		return []string{INFLUX_DURATION_1_MONTH, INFLUX_DURATION_1_WEEK}
+	case DURATION_KEY_YEAR:
+		// data stored in the last year (after the first month)
+		return []string{INFLUX_DURATION_1_YEAR, INFLUX_DURATION_1_MONTH}
+	case DURATION_KEY_FOREVER:
+		//data stored before the last year
+		return []string{INFLUX_DURATION_10_YEARS, INFLUX_DURATION_1_YEAR}
+	}
+	return []string{INFLUX_DURATION_1_WEEK, INFLUX_NOW}
+}
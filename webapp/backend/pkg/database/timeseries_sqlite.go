@@ -0,0 +1,953 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// timeSeriesPoint is a single point stored by the embedded SQLite time-series backend.
+// Buckets mirror the InfluxDB bucket names (metrics, metrics_weekly, ...) so diagnostics and
+// the down-sampling layout are identical between backends.
+type timeSeriesPoint struct {
+	ID          uint   `gorm:"primaryKey"`
+	Bucket      string `gorm:"not null;index:idx_time_series_lookup,priority:1"`
+	Measurement string `gorm:"not null;index:idx_time_series_lookup,priority:2"`
+	// SeriesKey is the value of the measurement's identifying tag (device_wwn, pool_guid, ...)
+	SeriesKey string `gorm:"index:idx_time_series_lookup,priority:3"`
+	// Timestamp is stored as unix nanoseconds so range scans are plain integer comparisons
+	Timestamp int64 `gorm:"not null;index:idx_time_series_lookup,priority:4"`
+	Tags      string
+	Fields    string
+}
+
+func (timeSeriesPoint) TableName() string {
+	return "time_series_points"
+}
+
+// encodedFields is the JSON representation of a point's fields, grouped by type so that
+// int64/uint64/float64/string/bool values read back exactly as the InfluxDB client returns them.
+type encodedFields struct {
+	Int    map[string]int64   `json:"i,omitempty"`
+	Uint   map[string]uint64  `json:"u,omitempty"`
+	Float  map[string]float64 `json:"f,omitempty"`
+	String map[string]string  `json:"s,omitempty"`
+	Bool   map[string]bool    `json:"b,omitempty"`
+}
+
+// decodedPoint is a timeSeriesPoint with its tags and fields unmarshalled.
+type decodedPoint struct {
+	id          uint
+	measurement string
+	time        time.Time
+	tags        map[string]string
+	fields      map[string]interface{}
+}
+
+// sqliteTimeSeriesStore stores time-series data in the Scrutiny SQLite database, for installs
+// that do not want to run InfluxDB. Queries reproduce the Flux query semantics in Go, and a
+// background loop (see timeseries_sqlite_downsample.go) down-samples and prunes the buckets.
+type sqliteTimeSeriesStore struct {
+	appConfig  config.Interface
+	logger     logrus.FieldLogger
+	gormClient *gorm.DB
+}
+
+func newSqliteTimeSeriesStore(appConfig config.Interface, globalLogger logrus.FieldLogger, database *gorm.DB) (*sqliteTimeSeriesStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("the sqlite time-series backend requires a database connection")
+	}
+	globalLogger.Infof("Using embedded SQLite time-series backend (InfluxDB disabled)")
+	return &sqliteTimeSeriesStore{
+		appConfig:  appConfig,
+		logger:     globalLogger,
+		gormClient: database,
+	}, nil
+}
+
+func (ss *sqliteTimeSeriesStore) Close() {
+	// the database connection is owned (and shared) by the repository
+}
+
+func (ss *sqliteTimeSeriesStore) HealthCheck(ctx context.Context) (string, HealthCheckStatus) {
+	start := time.Now()
+	var ids []uint
+	err := ss.gormClient.WithContext(ctx).Model(&timeSeriesPoint{}).Limit(1).Pluck("id", &ids).Error
+	latency := time.Since(start).Milliseconds()
+
+	if err != nil {
+		return "timeseries", HealthCheckStatus{
+			Status:    "error",
+			LatencyMs: latency,
+			Error:     err.Error(),
+		}
+	}
+	return "timeseries", HealthCheckStatus{
+		Status:    "ok",
+		LatencyMs: latency,
+	}
+}
+
+// BucketNames returns the raw and down-sampled bucket names. Buckets are implicit in the
+// embedded backend, so they always exist.
+func (ss *sqliteTimeSeriesStore) BucketNames(ctx context.Context) ([]string, error) {
+	return historyBuckets(ss.baseBucket()), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Writes & Deletes
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (ss *sqliteTimeSeriesStore) WritePoint(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error {
+	return ss.WriteBucketPoint(ctx, ss.baseBucket(), measurement, tags, fields, date)
+}
+
+// WriteBucketPoint upserts a point. Like InfluxDB, a point with the same measurement, tag set
+// and timestamp as an existing point is merged into it rather than stored twice.
+func (ss *sqliteTimeSeriesStore) WriteBucketPoint(ctx context.Context, bucket string, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("point for measurement %s has no fields", measurement)
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+
+	point := timeSeriesPoint{
+		Bucket:      bucket,
+		Measurement: measurement,
+		SeriesKey:   tags[seriesTagByMeasurement[measurement]],
+		Timestamp:   date.UnixNano(),
+		Tags:        string(tagsJSON),
+	}
+
+	return ss.gormClient.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		normalized := normalizeFields(fields)
+
+		var existing timeSeriesPoint
+		result := tx.Where("bucket = ? AND measurement = ? AND series_key = ? AND timestamp = ? AND tags = ?",
+			point.Bucket, point.Measurement, point.SeriesKey, point.Timestamp, point.Tags).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			merged, err := decodeFields(existing.Fields)
+			if err != nil {
+				return err
+			}
+			for k, v := range normalized {
+				merged[k] = v
+			}
+			encoded, err := encodeFields(merged)
+			if err != nil {
+				return err
+			}
+			return tx.Model(&existing).Update("fields", encoded).Error
+		}
+
+		encoded, err := encodeFields(normalized)
+		if err != nil {
+			return err
+		}
+		point.Fields = encoded
+		return tx.Create(&point).Error
+	})
+}
+
+func (ss *sqliteTimeSeriesStore) DeleteSeries(ctx context.Context, tagKey string, tagValue string) error {
+	measurementNames := []string{}
+	for measurement, seriesTag := range seriesTagByMeasurement {
+		if seriesTag == tagKey {
+			measurementNames = append(measurementNames, measurement)
+		}
+	}
+	if len(measurementNames) == 0 {
+		return fmt.Errorf("%s does not identify a time-series measurement", tagKey)
+	}
+
+	ss.logger.Infof("Deleting %s=%s data from all buckets", tagKey, tagValue)
+	return ss.gormClient.WithContext(ctx).
+		Where("measurement IN ? AND series_key = ?", measurementNames, tagValue).
+		Delete(&timeSeriesPoint{}).Error
+}
+
+// ExportBucket streams the points of bucket in insertion order, in batches to bound memory use.
+func (ss *sqliteTimeSeriesStore) ExportBucket(ctx context.Context, bucket string, fn func(point TimeSeriesExportPoint) error) error {
+	const batchSize = 1000
+	var lastID uint
+	for {
+		var stored []timeSeriesPoint
+		err := ss.gormClient.WithContext(ctx).
+			Where("bucket = ? AND id > ?", bucket, lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&stored).Error
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			return nil
+		}
+
+		points, err := decodePoints(stored)
+		if err != nil {
+			return err
+		}
+		for _, point := range points {
+			exported := TimeSeriesExportPoint{
+				Bucket:      bucket,
+				Measurement: point.measurement,
+				Tags:        point.tags,
+				Fields:      point.fields,
+				Time:        point.time,
+			}
+			if err := fn(exported); err != nil {
+				return err
+			}
+		}
+		lastID = stored[len(stored)-1].ID
+	}
+}
+
+func (ss *sqliteTimeSeriesStore) CopyDeviceHistory(ctx context.Context, sourceWWN string, destinationDevice *models.Device) error {
+	if sourceWWN == "" {
+		return nil
+	}
+	for _, bucket := range historyBuckets(ss.baseBucket()) {
+		for _, measurement := range []string{"smart", "temp", "performance"} {
+			points, err := ss.loadPoints(ctx, bucket, measurement, sourceWWN, time.Time{}, time.Time{})
+			if err != nil {
+				return fmt.Errorf("could not query %s history in bucket %s: %w", measurement, bucket, err)
+			}
+			for _, point := range points {
+				values := point.row(nil)
+				fields := measurementFields(measurement, values)
+				if len(fields) == 0 {
+					continue
+				}
+				tags := measurementTags(measurement, values, destinationDevice)
+				if err := ss.WriteBucketPoint(ctx, bucket, measurement, tags, fields, point.time); err != nil {
+					return fmt.Errorf("could not write %s history in bucket %s: %w", measurement, bucket, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// SMART
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (ss *sqliteTimeSeriesStore) QuerySmartHistory(ctx context.Context, wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) ([]timeSeriesRow, error) {
+	now := time.Now()
+	rows := []timeSeriesRow{}
+	for _, nestedDurationKey := range lookupNestedDurationKeys(durationKey) {
+		start, stop := sqliteDurationRange(nestedDurationKey, now)
+		points, err := ss.loadPoints(ctx, ss.bucketFor(nestedDurationKey), "smart", wwn, start, stop)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range windowLast(points, 24*time.Hour, stop) {
+			rows = append(rows, point.row(nil))
+		}
+	}
+
+	sortRowsByTime(rows, true)
+	return limitRows(rows, selectEntries, selectEntriesOffset), nil
+}
+
+// StreamDeviceSeries iterates the matching rows with a database cursor (oldest bucket first), so
+// large histories are never held in memory.
+func (ss *sqliteTimeSeriesStore) StreamDeviceSeries(ctx context.Context, measurement string, wwn string, durationKey string, fn func(row timeSeriesRow) error) error {
+	now := time.Now()
+	nestedDurationKeys := lookupNestedDurationKeys(durationKey)
+	for i := len(nestedDurationKeys) - 1; i >= 0; i-- {
+		start, stop := sqliteDurationRange(nestedDurationKeys[i], now)
+		if err := ss.streamPoints(ctx, ss.bucketFor(nestedDurationKeys[i]), measurement, wwn, start, stop, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *sqliteTimeSeriesStore) streamPoints(ctx context.Context, bucket string, measurement string, seriesKey string, start time.Time, stop time.Time, fn func(row timeSeriesRow) error) error {
+	cursor, err := ss.gormClient.WithContext(ctx).
+		Model(&timeSeriesPoint{}).
+		Where("bucket = ? AND measurement = ? AND series_key = ?", bucket, measurement, seriesKey).
+		Where("timestamp >= ? AND timestamp < ?", start.UnixNano(), stop.UnixNano()).
+		Order("timestamp ASC, id ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		var stored timeSeriesPoint
+		if err := ss.gormClient.ScanRows(cursor, &stored); err != nil {
+			return err
+		}
+		points, err := decodePoints([]timeSeriesPoint{stored})
+		if err != nil {
+			return err
+		}
+		if err := fn(points[0].row(nil)); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (ss *sqliteTimeSeriesStore) QuerySmartSubmission(ctx context.Context, wwn string, offset int) ([]timeSeriesRow, error) {
+	now := time.Now()
+	start, stop := sqliteDurationRange(DURATION_KEY_WEEK, now)
+	points, err := ss.loadPoints(ctx, ss.baseBucket(), "smart", wwn, start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := pointRows(points, nil)
+	sortRowsByTime(rows, true)
+	return limitRows(rows, 1, offset), nil
+}
+
+// QuerySmartSummary returns the newest summary fields per device across all buckets, like
+// summaryFluxQuery.
+func (ss *sqliteTimeSeriesStore) QuerySmartSummary(ctx context.Context) ([]timeSeriesRow, error) {
+	return ss.queryLatestPerDevice(ctx, summaryFields)
+}
+
+// QueryLastSeen returns the newest SMART submission time per device across all buckets, like
+// lastSeenFluxQuery.
+func (ss *sqliteTimeSeriesStore) QueryLastSeen(ctx context.Context) ([]timeSeriesRow, error) {
+	return ss.queryLatestPerDevice(ctx, []string{"temp"})
+}
+
+func (ss *sqliteTimeSeriesStore) queryLatestPerDevice(ctx context.Context, fieldNames []string) ([]timeSeriesRow, error) {
+	now := time.Now()
+	latest := map[string]decodedPoint{}
+	for _, bucket := range historyBuckets(ss.baseBucket()) {
+		points, err := ss.loadLatestPoints(ctx, bucket, "smart", now.AddDate(-10, 0, 0), now)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range points {
+			wwn := point.tags["device_wwn"]
+			if existing, ok := latest[wwn]; !ok || !point.time.Before(existing.time) {
+				latest[wwn] = point
+			}
+		}
+	}
+
+	rows := []timeSeriesRow{}
+	for _, point := range latest {
+		rows = append(rows, point.row(fieldNames))
+	}
+	sortRowsByTime(rows, false)
+	return rows, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Temperature
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// QueryTemperatureHistory mirrors aggregateTempQuery: hourly means (raw values for the "day"
+// duration) converted to integers, grouped by device and sorted oldest first.
+func (ss *sqliteTimeSeriesStore) QueryTemperatureHistory(ctx context.Context, durationKey string) ([]timeSeriesRow, error) {
+	now := time.Now()
+	byDevice := map[string][]timeSeriesRow{}
+	for _, nestedDurationKey := range lookupNestedDurationKeys(durationKey) {
+		start, stop := sqliteDurationRange(nestedDurationKey, now)
+		points, err := ss.loadPoints(ctx, ss.bucketFor(nestedDurationKey), "temp", "", start, stop)
+		if err != nil {
+			return nil, err
+		}
+
+		for wwn, devicePoints := range groupPointsByTag(points, "device_wwn") {
+			if lookupResolution(nestedDurationKey) != "" {
+				devicePoints = windowMean(devicePoints, time.Hour, stop, "temp")
+			}
+			for _, point := range devicePoints {
+				temp, ok := toInt64(point.fields["temp"])
+				if !ok {
+					continue
+				}
+				byDevice[wwn] = append(byDevice[wwn], timeSeriesRow{
+					"_time":      point.time,
+					"device_wwn": wwn,
+					"temp":       temp,
+				})
+			}
+		}
+	}
+
+	wwns := make([]string, 0, len(byDevice))
+	for wwn := range byDevice {
+		wwns = append(wwns, wwn)
+	}
+	sort.Strings(wwns)
+
+	rows := []timeSeriesRow{}
+	for _, wwn := range wwns {
+		deviceRows := byDevice[wwn]
+		sortRowsByTime(deviceRows, false)
+		rows = append(rows, deviceRows...)
+	}
+	return rows, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Workload
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// QueryWorkloadFirstLast mirrors buildWorkloadFirstLastQuery: rows without any positive workload
+// counter are ignored, then the oldest and newest remaining row per device is returned.
+func (ss *sqliteTimeSeriesStore) QueryWorkloadFirstLast(ctx context.Context, durationKey string) ([]timeSeriesRow, []timeSeriesRow, error) {
+	now := time.Now()
+	firstByDevice := map[string]timeSeriesRow{}
+	lastByDevice := map[string]timeSeriesRow{}
+	for _, nestedDurationKey := range lookupNestedDurationKeys(durationKey) {
+		start, stop := sqliteDurationRange(nestedDurationKey, now)
+		points, err := ss.loadPoints(ctx, ss.bucketFor(nestedDurationKey), "smart", "", start, stop)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, point := range points {
+			if !hasPositiveWorkloadCounter(point.fields) {
+				continue
+			}
+			wwn := point.tags["device_wwn"]
+			row := point.row(workloadFields)
+			if first, ok := firstByDevice[wwn]; !ok || point.time.Before(first["_time"].(time.Time)) {
+				firstByDevice[wwn] = row
+			}
+			if last, ok := lastByDevice[wwn]; !ok || point.time.After(last["_time"].(time.Time)) {
+				lastByDevice[wwn] = row
+			}
+		}
+	}
+
+	first := make([]timeSeriesRow, 0, len(firstByDevice))
+	for _, row := range firstByDevice {
+		first = append(first, row)
+	}
+	last := make([]timeSeriesRow, 0, len(lastByDevice))
+	for _, row := range lastByDevice {
+		last = append(last, row)
+	}
+	return first, last, nil
+}
+
+func (ss *sqliteTimeSeriesStore) QueryWorkloadRecent(ctx context.Context) ([]timeSeriesRow, error) {
+	now := time.Now()
+	start, stop := sqliteDurationRange(DURATION_KEY_WEEK, now)
+	points, err := ss.loadPoints(ctx, ss.baseBucket(), "smart", "", start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []timeSeriesRow{}
+	for _, devicePoints := range groupPointsByTag(points, "device_wwn") {
+		deviceRows := pointRows(devicePoints, workloadRecentFields)
+		sortRowsByTime(deviceRows, true)
+		rows = append(rows, limitRows(deviceRows, 3, 0)...)
+	}
+	return rows, nil
+}
+
+func hasPositiveWorkloadCounter(fields map[string]interface{}) bool {
+	for _, field := range workloadCounterFields {
+		if value, ok := fields[field]; ok {
+			switch v := value.(type) {
+			case int64:
+				if v > 0 {
+					return true
+				}
+			case uint64:
+				if v > 0 {
+					return true
+				}
+			case float64:
+				if v > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Performance, ZFS, MDADM & Btrfs
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (ss *sqliteTimeSeriesStore) QueryPerformanceHistory(ctx context.Context, wwn string, durationKey string) ([]timeSeriesRow, error) {
+	return ss.queryHourlyHistory(ctx, "performance", wwn, durationKey)
+}
+
+func (ss *sqliteTimeSeriesStore) QueryPerformanceRecent(ctx context.Context, wwn string, count int) ([]timeSeriesRow, error) {
+	now := time.Now()
+	points, err := ss.loadPoints(ctx, ss.baseBucket(), "performance", wwn, now.AddDate(0, 0, -30), now)
+	if err != nil {
+		return nil, err
+	}
+	rows := pointRows(points, nil)
+	sortRowsByTime(rows, true)
+	return limitRows(rows, count, 0), nil
+}
+
+func (ss *sqliteTimeSeriesStore) QueryZFSPoolHistory(ctx context.Context, guid string, durationKey string) ([]timeSeriesRow, error) {
+	return ss.queryHourlyHistory(ctx, "zfs_pool", guid, durationKey)
+}
+
+func (ss *sqliteTimeSeriesStore) QueryMdadmHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error) {
+	start, stop := sqliteDurationRange(durationKey, time.Now())
+	points, err := ss.loadPoints(ctx, ss.bucketFor(durationKey), "mdadm_array", uuid, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return pointRows(points, nil), nil
+}
+
+func (ss *sqliteTimeSeriesStore) QueryMdadmLatest(ctx context.Context, uuid string) ([]timeSeriesRow, error) {
+	now := time.Now()
+	points, err := ss.loadPoints(ctx, ss.baseBucket(), "mdadm_array", uuid, now.AddDate(0, 0, -7), now)
+	if err != nil {
+		return nil, err
+	}
+	rows := pointRows(points, nil)
+	sortRowsByTime(rows, true)
+	return limitRows(rows, 1, 0), nil
+}
+
+func (ss *sqliteTimeSeriesStore) QueryBtrfsHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error) {
+	return ss.queryHourlyHistory(ctx, "btrfs_filesystem", uuid, durationKey)
+}
+
+// queryHourlyHistory returns the last value of every field per hour, oldest first
+// (aggregateWindow(every: 1h, fn: last) + pivot).
+func (ss *sqliteTimeSeriesStore) queryHourlyHistory(ctx context.Context, measurement string, seriesKey string, durationKey string) ([]timeSeriesRow, error) {
+	start, stop := sqliteDurationRange(durationKey, time.Now())
+	points, err := ss.loadPoints(ctx, ss.bucketFor(durationKey), measurement, seriesKey, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return pointRows(windowLast(points, time.Hour, stop), nil), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Helper Methods
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (ss *sqliteTimeSeriesStore) baseBucket() string {
+	return ss.appConfig.GetString(cfgInfluxDBBucket)
+}
+
+func (ss *sqliteTimeSeriesStore) bucketFor(durationKey string) string {
+	return lookupBucketName(ss.baseBucket(), durationKey)
+}
+
+// loadPoints returns the points of a measurement in [start, stop), oldest first. An empty
+// seriesKey matches every series; zero start/stop times leave the range open.
+func (ss *sqliteTimeSeriesStore) loadPoints(ctx context.Context, bucket string, measurement string, seriesKey string, start time.Time, stop time.Time) ([]decodedPoint, error) {
+	query := ss.gormClient.WithContext(ctx).Where("bucket = ? AND measurement = ?", bucket, measurement)
+	if seriesKey != "" {
+		query = query.Where("series_key = ?", seriesKey)
+	}
+	if !start.IsZero() {
+		query = query.Where("timestamp >= ?", start.UnixNano())
+	}
+	if !stop.IsZero() {
+		query = query.Where("timestamp < ?", stop.UnixNano())
+	}
+
+	var stored []timeSeriesPoint
+	if err := query.Order("timestamp ASC, id ASC").Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	return decodePoints(stored)
+}
+
+// loadLatestPoints returns the newest point of every series of a measurement in [start, stop).
+func (ss *sqliteTimeSeriesStore) loadLatestPoints(ctx context.Context, bucket string, measurement string, start time.Time, stop time.Time) ([]decodedPoint, error) {
+	var stored []timeSeriesPoint
+	err := ss.gormClient.WithContext(ctx).Raw(`
+SELECT p.* FROM time_series_points p
+JOIN (
+	SELECT series_key, MAX(timestamp) AS latest FROM time_series_points
+	WHERE bucket = ? AND measurement = ? AND timestamp >= ? AND timestamp < ?
+	GROUP BY series_key
+) l ON p.series_key = l.series_key AND p.timestamp = l.latest
+WHERE p.bucket = ? AND p.measurement = ?
+ORDER BY p.id ASC`,
+		bucket, measurement, start.UnixNano(), stop.UnixNano(), bucket, measurement,
+	).Scan(&stored).Error
+	if err != nil {
+		return nil, err
+	}
+	return decodePoints(stored)
+}
+
+func decodePoints(stored []timeSeriesPoint) ([]decodedPoint, error) {
+	points := make([]decodedPoint, 0, len(stored))
+	for i := range stored {
+		tags := map[string]string{}
+		if stored[i].Tags != "" {
+			if err := json.Unmarshal([]byte(stored[i].Tags), &tags); err != nil {
+				return nil, fmt.Errorf("could not decode tags of time-series point %d: %w", stored[i].ID, err)
+			}
+		}
+		fields, err := decodeFields(stored[i].Fields)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode fields of time-series point %d: %w", stored[i].ID, err)
+		}
+		points = append(points, decodedPoint{
+			id:          stored[i].ID,
+			measurement: stored[i].Measurement,
+			time:        time.Unix(0, stored[i].Timestamp).UTC(),
+			tags:        tags,
+			fields:      fields,
+		})
+	}
+	return points, nil
+}
+
+// row flattens the point into a pivoted record. When fieldNames is non-nil only those fields are included.
+func (p decodedPoint) row(fieldNames []string) timeSeriesRow {
+	row := timeSeriesRow{
+		"_time":        p.time,
+		"_measurement": p.measurement,
+	}
+	for k, v := range p.tags {
+		row[k] = v
+	}
+	if fieldNames == nil {
+		for k, v := range p.fields {
+			row[k] = v
+		}
+		return row
+	}
+	for _, k := range fieldNames {
+		if v, ok := p.fields[k]; ok {
+			row[k] = v
+		}
+	}
+	return row
+}
+
+func pointRows(points []decodedPoint, fieldNames []string) []timeSeriesRow {
+	rows := make([]timeSeriesRow, 0, len(points))
+	for _, point := range points {
+		rows = append(rows, point.row(fieldNames))
+	}
+	return rows
+}
+
+func groupPointsByTag(points []decodedPoint, tagKey string) map[string][]decodedPoint {
+	grouped := map[string][]decodedPoint{}
+	for _, point := range points {
+		grouped[point.tags[tagKey]] = append(grouped[point.tags[tagKey]], point)
+	}
+	return grouped
+}
+
+// windowLast aggregates sorted points into fixed windows, keeping the last value of every field
+// and the tags of the newest point. Like aggregateWindow, each window is stamped with its end time
+// (clipped to stop).
+func windowLast(points []decodedPoint, every time.Duration, stop time.Time) []decodedPoint {
+	return aggregateWindows(points, func(t time.Time) (time.Time, time.Time) {
+		windowStart := t.Truncate(every)
+		return windowStart, windowStart.Add(every)
+	}, stop, lastFieldValues)
+}
+
+// windowMean aggregates sorted points into fixed windows, averaging the named numeric field.
+// Only the device_wwn tag is kept, as with a Flux mean aggregate grouped by device_wwn.
+func windowMean(points []decodedPoint, every time.Duration, stop time.Time, field string) []decodedPoint {
+	return aggregateWindows(points, func(t time.Time) (time.Time, time.Time) {
+		windowStart := t.Truncate(every)
+		return windowStart, windowStart.Add(every)
+	}, stop, meanFieldValue(field))
+}
+
+// aggregateWindows groups sorted points by the window returned by bounds and reduces each group with aggregate.
+func aggregateWindows(points []decodedPoint, bounds func(time.Time) (time.Time, time.Time), stop time.Time, aggregate func([]decodedPoint) (map[string]string, map[string]interface{})) []decodedPoint {
+	results := []decodedPoint{}
+	for i := 0; i < len(points); {
+		_, windowEnd := bounds(points[i].time)
+		j := i
+		for j < len(points) && points[j].time.Before(windowEnd) {
+			j++
+		}
+
+		tags, fields := aggregate(points[i:j])
+		if len(fields) > 0 {
+			stamp := windowEnd
+			if !stop.IsZero() && stamp.After(stop) {
+				stamp = stop
+			}
+			results = append(results, decodedPoint{
+				measurement: points[i].measurement,
+				time:        stamp,
+				tags:        tags,
+				fields:      fields,
+			})
+		}
+		i = j
+	}
+	return results
+}
+
+func lastFieldValues(points []decodedPoint) (map[string]string, map[string]interface{}) {
+	fields := map[string]interface{}{}
+	for _, point := range points {
+		for k, v := range point.fields {
+			fields[k] = v
+		}
+	}
+	return points[len(points)-1].tags, fields
+}
+
+func meanFieldValue(field string) func([]decodedPoint) (map[string]string, map[string]interface{}) {
+	return func(points []decodedPoint) (map[string]string, map[string]interface{}) {
+		var sum float64
+		var count int
+		for _, point := range points {
+			if value, ok := toFloat64(point.fields[field]); ok {
+				sum += value
+				count++
+			}
+		}
+		tags := map[string]string{"device_wwn": points[len(points)-1].tags["device_wwn"]}
+		if count == 0 {
+			return tags, nil
+		}
+		return tags, map[string]interface{}{field: sum / float64(count)}
+	}
+}
+
+func sortRowsByTime(rows []timeSeriesRow, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, _ := rows[i]["_time"].(time.Time)
+		tj, _ := rows[j]["_time"].(time.Time)
+		if desc {
+			return ti.After(tj)
+		}
+		return ti.Before(tj)
+	})
+}
+
+// limitRows applies limit(n, offset); n <= 0 returns every row after offset.
+func limitRows(rows []timeSeriesRow, n int, offset int) []timeSeriesRow {
+	if offset >= len(rows) {
+		return []timeSeriesRow{}
+	}
+	rows = rows[offset:]
+	if n > 0 && n < len(rows) {
+		rows = rows[:n]
+	}
+	return rows
+}
+
+// sqliteDurationRange returns the [start, stop) range used by lookupDuration for durationKey.
+func sqliteDurationRange(durationKey string, now time.Time) (time.Time, time.Time) {
+	switch durationKey {
+	case DURATION_KEY_DAY:
+		return now.AddDate(0, 0, -1), now
+	case DURATION_KEY_WEEK:
+		return now.AddDate(0, 0, -7), now
+	case DURATION_KEY_MONTH:
+		return now.AddDate(0, -1, 0), now.AddDate(0, 0, -7)
+	case DURATION_KEY_YEAR:
+		return now.AddDate(-1, 0, 0), now.AddDate(0, -1, 0)
+	case DURATION_KEY_FOREVER:
+		return now.AddDate(-10, 0, 0), now.AddDate(-1, 0, 0)
+	}
+	return now.AddDate(0, 0, -7), now
+}
+
+// normalizeFields converts field values to the types the InfluxDB client would store
+// (int64, uint64, float64, string or bool).
+func normalizeFields(fields map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		switch val := v.(type) {
+		case bool, int64, uint64, float64, string:
+			normalized[k] = val
+		case int:
+			normalized[k] = int64(val)
+		case int32:
+			normalized[k] = int64(val)
+		case int16:
+			normalized[k] = int64(val)
+		case int8:
+			normalized[k] = int64(val)
+		case uint:
+			normalized[k] = uint64(val)
+		case uint32:
+			normalized[k] = uint64(val)
+		case uint16:
+			normalized[k] = uint64(val)
+		case uint8:
+			normalized[k] = uint64(val)
+		case float32:
+			normalized[k] = float64(val)
+		case []byte:
+			normalized[k] = string(val)
+		case time.Time:
+			normalized[k] = val.Format(time.RFC3339Nano)
+		case time.Duration:
+			normalized[k] = val.String()
+		default:
+			normalized[k] = fmt.Sprintf("%v", val)
+		}
+	}
+	return normalized
+}
+
+func encodeFields(fields map[string]interface{}) (string, error) {
+	encoded := encodedFields{}
+	for k, v := range fields {
+		switch val := v.(type) {
+		case int64:
+			if encoded.Int == nil {
+				encoded.Int = map[string]int64{}
+			}
+			encoded.Int[k] = val
+		case uint64:
+			if encoded.Uint == nil {
+				encoded.Uint = map[string]uint64{}
+			}
+			encoded.Uint[k] = val
+		case float64:
+			// JSON cannot represent NaN/Inf (InfluxDB rejects them as well)
+			if math.IsNaN(val) || math.IsInf(val, 0) {
+				continue
+			}
+			if encoded.Float == nil {
+				encoded.Float = map[string]float64{}
+			}
+			encoded.Float[k] = val
+		case string:
+			if encoded.String == nil {
+				encoded.String = map[string]string{}
+			}
+			encoded.String[k] = val
+		case bool:
+			if encoded.Bool == nil {
+				encoded.Bool = map[string]bool{}
+			}
+			encoded.Bool[k] = val
+		default:
+			return "", fmt.Errorf("unsupported field type %T for %s", v, k)
+		}
+	}
+	data, err := json.Marshal(encoded)
+	return string(data), err
+}
+
+func decodeFields(data string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if data == "" {
+		return fields, nil
+	}
+	var encoded encodedFields
+	if err := json.Unmarshal([]byte(data), &encoded); err != nil {
+		return nil, err
+	}
+	for k, v := range encoded.Int {
+		fields[k] = v
+	}
+	for k, v := range encoded.Uint {
+		fields[k] = v
+	}
+	for k, v := range encoded.Float {
+		fields[k] = v
+	}
+	for k, v := range encoded.String {
+		fields[k] = v
+	}
+	for k, v := range encoded.Bool {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// toInt64 converts a numeric field to int64, truncating floats like Flux toInt().
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// deleteOlderThan removes points in bucket with a timestamp before cutoff.
+func (ss *sqliteTimeSeriesStore) deleteOlderThan(ctx context.Context, bucket string, cutoff time.Time) (int64, error) {
+	result := ss.gormClient.WithContext(ctx).
+		Where("bucket = ? AND timestamp < ?", bucket, cutoff.UnixNano()).
+		Delete(&timeSeriesPoint{})
+	return result.RowsAffected, result.Error
+}
+
+// earliestTimestamp returns the oldest point time of a measurement in bucket at or after start,
+// or the zero time.
+func (ss *sqliteTimeSeriesStore) earliestTimestamp(ctx context.Context, bucket string, measurement string, start time.Time) (time.Time, error) {
+	var earliest []int64
+	query := ss.gormClient.WithContext(ctx).Model(&timeSeriesPoint{}).
+		Where("bucket = ? AND measurement = ?", bucket, measurement)
+	if !start.IsZero() {
+		query = query.Where("timestamp >= ?", start.UnixNano())
+	}
+	err := query.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "timestamp"}}).
+		Limit(1).
+		Pluck("timestamp", &earliest).Error
+	if err != nil || len(earliest) == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, earliest[0]).UTC(), nil
+}
+
+// latestTimestamp returns the newest point time of a measurement in bucket, or the zero time.
+func (ss *sqliteTimeSeriesStore) latestTimestamp(ctx context.Context, bucket string, measurement string) (time.Time, error) {
+	var latest []int64
+	err := ss.gormClient.WithContext(ctx).Model(&timeSeriesPoint{}).
+		Where("bucket = ? AND measurement = ?", bucket, measurement).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "timestamp"}, Desc: true}).
+		Limit(1).
+		Pluck("timestamp", &latest).Error
+	if err != nil || len(latest) == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, latest[0]).UTC(), nil
+}
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Down-sampling & retention for the embedded SQLite time-series backend.
+//
+// This is the equivalent of the InfluxDB tasks created by EnsureTasks: smart and temp points are
+// aggregated from the raw bucket into weekly, monthly and yearly buckets (last value per field for
+// smart, integer mean for temp). Instead of cron ranges, every completed window newer than the
+// destination bucket's latest point is processed, so missed runs (e.g. while Scrutiny was stopped)
+// are caught up automatically.
+// //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+const sqliteMaintenanceInterval = 1 * time.Hour
+
+var (
+	sqliteMaintenanceMu      sync.Mutex
+	sqliteMaintenanceStarted = map[string]bool{}
+)
+
+type sqliteDownsampleSpec struct {
+	name        string
+	source      string
+	destination string
+	// window returns the [start, end) aggregation window containing t
+	window func(t time.Time) (time.Time, time.Time)
+}
+
+func sqliteDownsampleSpecs(baseBucket string) []sqliteDownsampleSpec {
+	return []sqliteDownsampleSpec{
+		{
+			name:        "weekly",
+			source:      baseBucket,
+			destination: fmt.Sprintf("%s_weekly", baseBucket),
+			window: func(t time.Time) (time.Time, time.Time) {
+				start := t.UTC().Truncate(7 * 24 * time.Hour)
+				return start, start.AddDate(0, 0, 7)
+			},
+		},
+		{
+			name:        "monthly",
+			source:      fmt.Sprintf("%s_weekly", baseBucket),
+			destination: fmt.Sprintf("%s_monthly", baseBucket),
+			window: func(t time.Time) (time.Time, time.Time) {
+				t = t.UTC()
+				start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+				return start, start.AddDate(0, 1, 0)
+			},
+		},
+		{
+			name:        "yearly",
+			source:      fmt.Sprintf("%s_monthly", baseBucket),
+			destination: fmt.Sprintf("%s_yearly", baseBucket),
+			window: func(t time.Time) (time.Time, time.Time) {
+				t = t.UTC()
+				start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+				return start, start.AddDate(1, 0, 0)
+			},
+		},
+	}
+}
+
+// startMaintenance starts the background down-sampling/retention loop. Only one loop runs per
+// database file, no matter how many repositories are opened; it runs for the life of the process.
+func (ss *sqliteTimeSeriesStore) startMaintenance() {
+	key := ss.appConfig.GetString(cfgDatabaseLocation)
+
+	sqliteMaintenanceMu.Lock()
+	defer sqliteMaintenanceMu.Unlock()
+	if sqliteMaintenanceStarted[key] {
+		return
+	}
+	sqliteMaintenanceStarted[key] = true
+
+	go func() {
+		ctx := context.Background()
+		ss.runMaintenance(ctx, time.Now())
+
+		ticker := time.NewTicker(sqliteMaintenanceInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ss.runMaintenance(ctx, time.Now())
+		}
+	}()
+}
+
+func (ss *sqliteTimeSeriesStore) runMaintenance(ctx context.Context, now time.Time) {
+	for _, spec := range sqliteDownsampleSpecs(ss.baseBucket()) {
+		if err := ss.downsample(ctx, spec, now); err != nil {
+			ss.logger.Errorf("Time-series %s down-sampling failed: %v", spec.name, err)
+		}
+	}
+	if err := ss.applyRetention(ctx, now); err != nil {
+		ss.logger.Errorf("Time-series retention cleanup failed: %v", err)
+	}
+}
+
+// downsample aggregates every completed window of the source bucket that is newer than the
+// destination bucket's latest point. Windows are loaded one at a time, so catching up on a long
+// history (e.g. the first run after upgrading) never holds more than one window in memory.
+func (ss *sqliteTimeSeriesStore) downsample(ctx context.Context, spec sqliteDownsampleSpec, now time.Time) error {
+	for _, measurement := range []string{"smart", "temp"} {
+		watermark, err := ss.latestTimestamp(ctx, spec.destination, measurement)
+		if err != nil {
+			return err
+		}
+
+		written := 0
+		for cursor := watermark; ; {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// skip to the window of the next source point, empty windows produce no aggregate
+			next, err := ss.earliestTimestamp(ctx, spec.source, measurement, cursor)
+			if err != nil {
+				return err
+			}
+			if next.IsZero() {
+				break
+			}
+			// only aggregate windows that have completed
+			_, windowEnd := spec.window(next)
+			if windowEnd.After(now) {
+				break
+			}
+
+			count, err := ss.downsampleWindow(ctx, spec, measurement, cursor, windowEnd)
+			if err != nil {
+				return err
+			}
+			written += count
+			cursor = windowEnd
+		}
+		if written > 0 {
+			ss.logger.Infof("Down-sampled %d %s points into %s", written, measurement, spec.destination)
+		}
+	}
+	return nil
+}
+
+// downsampleWindow aggregates the source points in [start, end) into the destination bucket and
+// returns the number of points written.
+func (ss *sqliteTimeSeriesStore) downsampleWindow(ctx context.Context, spec sqliteDownsampleSpec, measurement string, start time.Time, end time.Time) (int, error) {
+	points, err := ss.loadPoints(ctx, spec.source, measurement, "", start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, devicePoints := range groupPointsByTag(points, "device_wwn") {
+		var aggregated []decodedPoint
+		if measurement == "temp" {
+			aggregated = aggregateWindows(devicePoints, spec.window, time.Time{}, meanFieldValue("temp"))
+		} else {
+			aggregated = aggregateWindows(devicePoints, spec.window, time.Time{}, lastFieldValues)
+		}
+
+		for _, point := range aggregated {
+			if measurement == "temp" {
+				temp, _ := toInt64(point.fields["temp"])
+				point.fields = map[string]interface{}{"temp": temp}
+			}
+			if err := ss.WriteBucketPoint(ctx, spec.destination, measurement, point.tags, point.fields, point.time); err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
+	return written, nil
+}
+
+// applyRetention prunes the raw, weekly and monthly buckets using the web.influxdb.retention.*
+// periods when web.influxdb.retention_policy is enabled. The yearly bucket is kept forever.
+func (ss *sqliteTimeSeriesStore) applyRetention(ctx context.Context, now time.Time) error {
+	if !ss.appConfig.GetBool(cfgInfluxDBRetentionPolicy) {
+		return nil
+	}
+
+	buckets := historyBuckets(ss.baseBucket())
+	retention := map[string]int{
+		buckets[0]: ss.appConfig.GetInt(cfgRetentionDaily),
+		buckets[1]: ss.appConfig.GetInt(cfgRetentionWeekly),
+		buckets[2]: ss.appConfig.GetInt(cfgRetentionMonthly),
+	}
+	for bucket, seconds := range retention {
+		if seconds <= 0 {
+			continue
+		}
+		deleted, err := ss.deleteOlderThan(ctx, bucket, now.Add(-time.Duration(seconds)*time.Second))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			ss.logger.Debugf("Pruned %d expired points from %s", deleted, bucket)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	"github.com/glebarez/sqlite"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func createSqliteTimeSeriesStore(t *testing.T, retentionPolicy bool) *sqliteTimeSeriesStore {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetString("web.influxdb.bucket").Return("metrics").AnyTimes()
+	fakeConfig.EXPECT().GetBool("web.influxdb.retention_policy").Return(retentionPolicy).AnyTimes()
+	fakeConfig.EXPECT().GetInt("web.influxdb.retention.daily").Return(DEFAULT_RETENTION_PERIOD_15_DAYS_IN_SECONDS).AnyTimes()
+	fakeConfig.EXPECT().GetInt("web.influxdb.retention.weekly").Return(DEFAULT_RETENTION_PERIOD_9_WEEKS_IN_SECONDS).AnyTimes()
+	fakeConfig.EXPECT().GetInt("web.influxdb.retention.monthly").Return(DEFAULT_RETENTION_PERIOD_25_MONTHS_IN_SECONDS).AnyTimes()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&timeSeriesPoint{}))
+
+	store, err := newSqliteTimeSeriesStore(fakeConfig, logrus.New(), db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestSqliteTimeSeriesStore_WritePointPreservesFieldTypes(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	date := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1", "device_protocol": "ATA"}, map[string]interface{}{
+		"temp":                int(38),
+		"power_on_hours":      int64(1234),
+		"attr.5.raw_value":    int64(9007199254740993), // not representable as float64
+		"attr.241.raw_value":  uint64(18446744073709551615),
+		"attr.percentage":     float32(1.5),
+		"attr.5.when_failed":  "",
+		"attr.5.transient":    true,
+		"attr.5.status":       uint8(2),
+		"attr.5.status_label": []byte("passed"),
+	}, date))
+
+	rows, err := store.QuerySmartSubmission(ctx, "wwn-1", 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	row := rows[0]
+	require.Equal(t, date.UTC(), row["_time"])
+	require.Equal(t, "wwn-1", row["device_wwn"])
+	require.Equal(t, "ATA", row["device_protocol"])
+	require.Equal(t, int64(38), row["temp"])
+	require.Equal(t, int64(1234), row["power_on_hours"])
+	require.Equal(t, int64(9007199254740993), row["attr.5.raw_value"])
+	require.Equal(t, uint64(18446744073709551615), row["attr.241.raw_value"])
+	require.Equal(t, float64(1.5), row["attr.percentage"])
+	require.Equal(t, "", row["attr.5.when_failed"])
+	require.Equal(t, true, row["attr.5.transient"])
+	require.Equal(t, uint64(2), row["attr.5.status"])
+	require.Equal(t, "passed", row["attr.5.status_label"])
+}
+
+func TestSqliteTimeSeriesStore_WritePointMergesDuplicateSeriesTimestamp(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	date := time.Now().Add(-time.Hour)
+	tags := map[string]string{"device_wwn": "wwn-1", "device_id": "device-1"}
+
+	require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(30)}, date))
+	require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(31)}, date))
+
+	var count int64
+	require.NoError(t, store.gormClient.Model(&timeSeriesPoint{}).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	rows, err := store.QueryTemperatureHistory(ctx, DURATION_KEY_DAY)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(31), rows[0]["temp"])
+}
+
+func TestSqliteTimeSeriesStore_QuerySmartHistoryAggregatesDaily(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// two submissions yesterday, one the day before, and another device's submission
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(30), "power_on_hours": int64(10)}, today.Add(-47*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(31), "power_on_hours": int64(33)}, today.Add(-23*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(32), "power_on_hours": int64(34)}, today.Add(-22*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-2"}, map[string]interface{}{"temp": int64(50), "power_on_hours": int64(99)}, today.Add(-22*time.Hour)))
+
+	rows, err := store.QuerySmartHistory(ctx, "wwn-1", DURATION_KEY_WEEK, 0, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	// newest first, stamped with the end of each daily window, last value wins
+	require.Equal(t, today, rows[0]["_time"])
+	require.Equal(t, int64(32), rows[0]["temp"])
+	require.Equal(t, int64(34), rows[0]["power_on_hours"])
+	require.Equal(t, today.Add(-24*time.Hour), rows[1]["_time"])
+	require.Equal(t, int64(30), rows[1]["temp"])
+
+	rows, err = store.QuerySmartHistory(ctx, "wwn-1", DURATION_KEY_WEEK, 1, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(30), rows[0]["temp"])
+}
+
+func TestSqliteTimeSeriesStore_QuerySmartSummaryUsesNewestBucket(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.WriteBucketPoint(ctx, "metrics_weekly", "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(20), "power_on_hours": int64(1)}, now.AddDate(0, 0, -20)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(40), "power_on_hours": int64(2), "attr.9.value": int64(100)}, now.Add(-time.Hour)))
+	require.NoError(t, store.WriteBucketPoint(ctx, "metrics_yearly", "smart", map[string]string{"device_wwn": "wwn-2"}, map[string]interface{}{"temp": int64(25), "power_on_hours": int64(3)}, now.AddDate(-2, 0, 0)))
+
+	rows, err := store.QuerySmartSummary(ctx)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	byWWN := map[string]timeSeriesRow{}
+	for _, row := range rows {
+		byWWN[row["device_wwn"].(string)] = row
+	}
+	require.Equal(t, int64(40), byWWN["wwn-1"]["temp"])
+	require.NotContains(t, byWWN["wwn-1"], "attr.9.value")
+	require.Equal(t, int64(25), byWWN["wwn-2"]["temp"])
+
+	lastSeen, err := store.QueryLastSeen(ctx)
+	require.NoError(t, err)
+	require.Len(t, lastSeen, 2)
+}
+
+func TestSqliteTimeSeriesStore_DownsampleAndRetention(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, true)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC)
+	weekStart, weekEnd := sqliteDownsampleSpecs("metrics")[0].window(now.AddDate(0, 0, -7))
+
+	tags := map[string]string{"device_wwn": "wwn-1", "device_id": "device-1"}
+	require.NoError(t, store.WritePoint(ctx, "smart", tags, map[string]interface{}{"temp": int64(30), "attr.5.raw_value": int64(0)}, weekStart.Add(time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", tags, map[string]interface{}{"temp": int64(33), "attr.5.raw_value": int64(2)}, weekStart.Add(48*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(30)}, weekStart.Add(time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(35)}, weekStart.Add(2*time.Hour)))
+	// the current (incomplete) week must not be down-sampled yet
+	require.NoError(t, store.WritePoint(ctx, "smart", tags, map[string]interface{}{"temp": int64(40), "attr.5.raw_value": int64(3)}, now.Add(-time.Hour)))
+	// older than the daily retention period
+	require.NoError(t, store.WritePoint(ctx, "smart", tags, map[string]interface{}{"temp": int64(20)}, now.AddDate(0, 0, -30)))
+
+	store.runMaintenance(ctx, now)
+	// a second run must not duplicate the aggregated window
+	store.runMaintenance(ctx, now)
+
+	weekly, err := store.loadPoints(ctx, "metrics_weekly", "smart", "wwn-1", weekStart, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, weekly, 1)
+	require.Equal(t, weekEnd, weekly[0].time)
+	require.Equal(t, int64(33), weekly[0].fields["temp"])
+	require.Equal(t, int64(2), weekly[0].fields["attr.5.raw_value"])
+	require.Equal(t, "device-1", weekly[0].tags["device_id"])
+
+	weeklyTemp, err := store.loadPoints(ctx, "metrics_weekly", "temp", "wwn-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, weeklyTemp, 1)
+	require.Equal(t, int64(32), weeklyTemp[0].fields["temp"])
+	require.Equal(t, map[string]string{"device_wwn": "wwn-1"}, weeklyTemp[0].tags)
+
+	raw, err := store.loadPoints(ctx, "metrics", "smart", "wwn-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, raw, 3, "the point older than the daily retention period should be pruned")
+}
+
+func TestSqliteTimeSeriesStore_DownsampleCatchesUpOneWindowAtATime(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC)
+	spec := sqliteDownsampleSpecs("metrics")[0]
+
+	// weeks 6, 5 and 2 before now hold points, the weeks between them are empty
+	tags := map[string]string{"device_wwn": "wwn-1"}
+	var windowEnds []time.Time
+	for i, weeksAgo := range []int{6, 5, 2} {
+		weekStart, weekEnd := spec.window(now.AddDate(0, 0, -7*weeksAgo))
+		windowEnds = append(windowEnds, weekEnd)
+		require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(30 + i)}, weekStart.Add(time.Hour)))
+		require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(32 + i)}, weekStart.Add(2*time.Hour)))
+	}
+
+	require.NoError(t, store.downsample(ctx, spec, now))
+
+	weekly, err := store.loadPoints(ctx, "metrics_weekly", "temp", "wwn-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, weekly, 3)
+	for i, point := range weekly {
+		require.Equal(t, windowEnds[i], point.time)
+		require.Equal(t, int64(31+i), point.fields["temp"])
+	}
+
+	// a point in a window that was already aggregated is not picked up again
+	require.NoError(t, store.WritePoint(ctx, "temp", tags, map[string]interface{}{"temp": int64(50)}, windowEnds[0].Add(-time.Hour)))
+	require.NoError(t, store.downsample(ctx, spec, now))
+	weekly, err = store.loadPoints(ctx, "metrics_weekly", "temp", "wwn-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, weekly, 3)
+}
+
+func TestSqliteTimeSeriesStore_DeleteSeries(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(30)}, now))
+	require.NoError(t, store.WriteBucketPoint(ctx, "metrics_weekly", "temp", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(30)}, now))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-2"}, map[string]interface{}{"temp": int64(30)}, now))
+	require.NoError(t, store.WritePoint(ctx, "zfs_pool", map[string]string{"pool_guid": "wwn-1"}, map[string]interface{}{"size": int64(1)}, now))
+
+	require.NoError(t, store.DeleteSeries(ctx, "device_wwn", "wwn-1"))
+
+	var remaining []timeSeriesPoint
+	require.NoError(t, store.gormClient.Order("id").Find(&remaining).Error)
+	require.Len(t, remaining, 2)
+	require.Equal(t, "wwn-2", remaining[0].SeriesKey)
+	require.Equal(t, "zfs_pool", remaining[1].Measurement)
+
+	require.Error(t, store.DeleteSeries(ctx, "unknown_tag", "wwn-2"))
+}
+
+func TestSqliteTimeSeriesStore_StreamDeviceSeriesReturnsRawRowsOldestFirst(t *testing.T) {
+	store := createSqliteTimeSeriesStore(t, false)
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// two submissions on the same day are streamed individually, not aggregated
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(31), "attr.5.raw_value": int64(1)}, today.Add(-23*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(32), "attr.5.raw_value": int64(2)}, today.Add(-22*time.Hour)))
+	require.NoError(t, store.WritePoint(ctx, "smart", map[string]string{"device_wwn": "wwn-2"}, map[string]interface{}{"temp": int64(50)}, today.Add(-22*time.Hour)))
+	// older data lives in the down-sampled buckets
+	require.NoError(t, store.WriteBucketPoint(ctx, store.bucketFor(DURATION_KEY_MONTH), "smart", map[string]string{"device_wwn": "wwn-1"}, map[string]interface{}{"temp": int64(29)}, today.AddDate(0, 0, -20)))
+
+	var temps []int64
+	err := store.StreamDeviceSeries(ctx, "smart", "wwn-1", DURATION_KEY_FOREVER, func(row timeSeriesRow) error {
+		temps = append(temps, row["temp"].(int64))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{29, 31, 32}, temps)
+
+	temps = nil
+	err = store.StreamDeviceSeries(ctx, "smart", "wwn-1", DURATION_KEY_WEEK, func(row timeSeriesRow) error {
+		temps = append(temps, row["temp"].(int64))
+		require.Contains(t, row, "attr.5.raw_value")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{31, 32}, temps)
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// Supported values for the web.timeseries.backend config key
+	TIMESERIES_BACKEND_INFLUXDB = "influxdb"
+	TIMESERIES_BACKEND_SQLITE   = "sqlite"
+)
+
+// timeSeriesRow is a single time-series record with tags, fields and "_time" merged into one map,
+// matching the shape of a pivoted InfluxDB record (schema.fieldsAsCols / pivot).
+type timeSeriesRow = map[string]interface{}
+
+// timeSeriesStore is the storage backend for the time-series half of the repository: SMART
+// attributes, temperature, ZFS/mdadm/Btrfs metrics and performance benchmarks.
+//
+// Query methods return rows shaped like pivoted InfluxDB records, so the measurement parsers
+// (measurements.NewSmartFromInfluxDB, etc.) work unchanged against every implementation.
+// Points are written to the raw bucket and down-sampled into the weekly/monthly/yearly buckets
+// by the backend itself.
+type timeSeriesStore interface {
+	Close()
+	// HealthCheck returns the name of the backend check (e.g. "influxdb") and its status.
+	HealthCheck(ctx context.Context) (string, HealthCheckStatus)
+	// BucketNames returns the names of the raw and down-sampled buckets that currently exist.
+	BucketNames(ctx context.Context) ([]string, error)
+
+	// WritePoint stores a single point in the raw (daily) bucket.
+	WritePoint(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error
+	// WriteBucketPoint stores a single point in the named bucket. Used by the legacy data migration.
+	WriteBucketPoint(ctx context.Context, bucket string, measurement string, tags map[string]string, fields map[string]interface{}, date time.Time) error
+	// DeleteSeries removes every point tagged tagKey=tagValue from all buckets.
+	DeleteSeries(ctx context.Context, tagKey string, tagValue string) error
+	// ExportBucket streams every point stored in bucket to fn, oldest first. Used by backups.
+	ExportBucket(ctx context.Context, bucket string, fn func(point TimeSeriesExportPoint) error) error
+	// CopyDeviceHistory re-tags every smart/temp/performance point for sourceWWN with the destination device identity.
+	CopyDeviceHistory(ctx context.Context, sourceWWN string, destinationDevice *models.Device) error
+
+	// QuerySmartHistory returns daily-aggregated SMART rows, newest first.
+	QuerySmartHistory(ctx context.Context, wwn string, durationKey string, selectEntries int, selectEntriesOffset int, attributes []string) ([]timeSeriesRow, error)
+	// QuerySmartSubmission returns the raw SMART submission at offset (0 = newest) from the last week.
+	QuerySmartSubmission(ctx context.Context, wwn string, offset int) ([]timeSeriesRow, error)
+	// QuerySmartSummary returns the latest summary fields for every device across all buckets.
+	QuerySmartSummary(ctx context.Context) ([]timeSeriesRow, error)
+	// QueryLastSeen returns the newest SMART submission time for every device across all buckets.
+	QueryLastSeen(ctx context.Context) ([]timeSeriesRow, error)
+	// StreamDeviceSeries streams the stored (non-aggregated) rows of a device_wwn-tagged measurement
+	// from every bucket covered by durationKey to fn, oldest first.
+	StreamDeviceSeries(ctx context.Context, measurement string, wwn string, durationKey string, fn func(row timeSeriesRow) error) error
+	// QueryTemperatureHistory returns temperature rows for every device, oldest first.
+	QueryTemperatureHistory(ctx context.Context, durationKey string) ([]timeSeriesRow, error)
+	// QueryWorkloadFirstLast returns the oldest and newest workload counter rows per device.
+	QueryWorkloadFirstLast(ctx context.Context, durationKey string) (first []timeSeriesRow, last []timeSeriesRow, err error)
+	// QueryWorkloadRecent returns the three newest raw workload counter rows per device.
+	QueryWorkloadRecent(ctx context.Context) ([]timeSeriesRow, error)
+	// QueryPerformanceHistory returns hourly performance rows, oldest first.
+	QueryPerformanceHistory(ctx context.Context, wwn string, durationKey string) ([]timeSeriesRow, error)
+	// QueryPerformanceRecent returns up to count raw performance rows from the last 30 days, newest first.
+	QueryPerformanceRecent(ctx context.Context, wwn string, count int) ([]timeSeriesRow, error)
+	// QueryZFSPoolHistory returns hourly ZFS pool rows, oldest first.
+	QueryZFSPoolHistory(ctx context.Context, guid string, durationKey string) ([]timeSeriesRow, error)
+	// QueryMdadmHistory returns raw mdadm array rows, oldest first.
+	QueryMdadmHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error)
+	// QueryMdadmLatest returns the newest mdadm array row from the last week, if any.
+	QueryMdadmLatest(ctx context.Context, uuid string) ([]timeSeriesRow, error)
+	// QueryBtrfsHistory returns hourly Btrfs filesystem rows, oldest first.
+	QueryBtrfsHistory(ctx context.Context, uuid string, durationKey string) ([]timeSeriesRow, error)
+}
+
+// newTimeSeriesStore creates the time-series backend selected by web.timeseries.backend.
+// The SQLite backend shares the relational database connection, so a single database file
+// holds all Scrutiny state.
+func newTimeSeriesStore(ctx context.Context, appConfig config.Interface, globalLogger logrus.FieldLogger, database *gorm.DB) (timeSeriesStore, error) {
+	switch backend := strings.ToLower(appConfig.GetString(cfgTimeSeriesBackend)); backend {
+	case "", TIMESERIES_BACKEND_INFLUXDB:
+		return newInfluxTimeSeriesStore(ctx, appConfig, globalLogger)
+	case TIMESERIES_BACKEND_SQLITE:
+		return newSqliteTimeSeriesStore(appConfig, globalLogger, database)
+	default:
+		return nil, fmt.Errorf("unsupported time-series backend %q (expected %q or %q)", backend, TIMESERIES_BACKEND_INFLUXDB, TIMESERIES_BACKEND_SQLITE)
+	}
+}
+
+// seriesTagByMeasurement maps each measurement to the tag that identifies its series.
+var seriesTagByMeasurement = map[string]string{
+	"smart":            "device_wwn",
+	"temp":             "device_wwn",
+	"performance":      "device_wwn",
+	"zfs_pool":         "pool_guid",
+	"mdadm_array":      "array_uuid",
+	"btrfs_filesystem": "filesystem_uuid",
+}
+
+// workloadCounterFields are the cumulative counters used to compute workload rates.
+var workloadCounterFields = []string{
+	"attr.241.raw_value",
+	"attr.242.raw_value",
+	"attr.devstat_1_24.value",
+	"attr.devstat_1_40.value",
+	"attr.data_units_written.value",
+	"attr.data_units_read.value",
+}
+
+// workloadFields are the SMART fields fetched for workload first/last computation.
+var workloadFields = append([]string{
+	"power_on_hours",
+	"logical_block_size",
+}, append(workloadCounterFields,
+	"attr.percentage_used.value",
+	"attr.devstat_7_8.value",
+	"attr.177.value",
+	"attr.231.value",
+	"attr.232.value",
+	"attr.233.value",
+)...)
+
+// workloadRecentFields are the SMART fields fetched for spike detection.
+var workloadRecentFields = append([]string{
+	"power_on_hours",
+	"logical_block_size",
+}, workloadCounterFields...)
+
+// summaryFields are the SMART fields fetched for the dashboard summary.
+var summaryFields = []string{
+	"temp",
+	"power_on_hours",
+	"date",
+	"attr.percentage_used.value",
+	"attr.devstat_7_8.raw_value",
+	"attr.177.value",
+	"attr.233.value",
+	"attr.231.value",
+	"attr.232.value",
+	"attr.5.raw_value",
+	"attr.197.raw_value",
+	"attr.198.raw_value",
+	"attr.media_errors.value",
+	"attr.scsi_grown_defect_list.value",
+}
+
+// lookupBucketName returns the bucket holding data for durationKey.
+func lookupBucketName(baseBucket string, durationKey string) string {
+	switch durationKey {
+	case DURATION_KEY_DAY:
+	case DURATION_KEY_WEEK:
+		//data stored in the last week
+		return baseBucket
+	case DURATION_KEY_MONTH:
+		// data stored in the last month (after the first week)
+		return fmt.Sprintf("%s_weekly", baseBucket)
+	case DURATION_KEY_YEAR:
+		// data stored in the last year (after the first month)
+		return fmt.Sprintf("%s_monthly", baseBucket)
+	case DURATION_KEY_FOREVER:
+		//data stored before the last year
+		return fmt.Sprintf("%s_yearly", baseBucket)
+	}
+	return baseBucket
+}
+
+// historyBuckets returns the raw bucket followed by the weekly/monthly/yearly down-sampling buckets.
+func historyBuckets(baseBucket string) []string {
+	return []string{
+		baseBucket,
+		fmt.Sprintf("%s_weekly", baseBucket),
+		fmt.Sprintf("%s_monthly", baseBucket),
+		fmt.Sprintf("%s_yearly", baseBucket),
+	}
+}
+
+func lookupResolution(durationKey string) string {
+	switch durationKey {
+	case DURATION_KEY_DAY:
+		// Return raw data for daily view so tooltip timestamps match actual collection times
+		return ""
+	default:
+		// Return data with 1h resolution for other summaries
+		return RESOLUTION_1_HOUR
+	}
+}
+
+func lookupNestedDurationKeys(durationKey string) []string {
+	switch durationKey {
+	case DURATION_KEY_DAY:
+		//all data is stored in a single bucket, but we want a finer resolution
+		return []string{DURATION_KEY_DAY}
+	case DURATION_KEY_WEEK:
+		//all data is stored in a single bucket
+		return []string{DURATION_KEY_WEEK}
+	case DURATION_KEY_MONTH:
+		//data is stored in the week bucket and the month bucket
+		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH}
+	case DURATION_KEY_YEAR:
+		// data stored in the last year (after the first month)
+		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH, DURATION_KEY_YEAR}
+	case DURATION_KEY_FOREVER:
+		//data stored before the last year
+		return []string{DURATION_KEY_WEEK, DURATION_KEY_MONTH, DURATION_KEY_YEAR, DURATION_KEY_FOREVER}
+	}
+	return []string{DURATION_KEY_WEEK}
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event types published on the bus.
+const (
+	TypeDeviceMetricsIngested = "device_metrics_ingested"
+	TypeDeviceStatusChanged   = "device_status_changed"
+	TypePoolStateChanged      = "pool_state_changed"
+	TypeCollectorError        = "collector_error"
+	TypeNotificationSent      = "notification_sent"
+)
+
+// Types lists every event type, in the order they are documented.
+var Types = []string{
+	TypeDeviceMetricsIngested,
+	TypeDeviceStatusChanged,
+	TypePoolStateChanged,
+	TypeCollectorError,
+	TypeNotificationSent,
+}
+
+// IsValidType reports whether t is one of the event types.
+func IsValidType(t string) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a typed event streamed to /api/events subscribers.
+type Event struct {
+	ID       uint64                 `json:"id"`
+	Type     string                 `json:"type"`
+	Time     time.Time              `json:"time"`
+	HostID   string                 `json:"host_id,omitempty"`
+	DeviceID string                 `json:"device_id,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// Filter selects the events a subscriber receives. Empty fields match any event.
+type Filter struct {
+	HostID   string
+	DeviceID string
+	Types    []string
+}
+
+// Matches reports whether the event passes the filter.
+func (f Filter) Matches(event Event) bool {
+	if f.HostID != "" && f.HostID != event.HostID {
+		return false
+	}
+	if f.DeviceID != "" && f.DeviceID != event.DeviceID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives the events matching its filter until it is unsubscribed.
+type Subscription struct {
+	filter  Filter
+	events  chan Event
+	dropped uint64
+}
+
+// Events returns the channel events are delivered on. It is closed on Unsubscribe and Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Bus is an in-process publish/subscribe bus. Publishing never blocks: a subscriber that does
+// not keep up loses events instead of slowing down the upload handlers.
+type Bus struct {
+	logger      logrus.FieldLogger
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	nextID      uint64
+	closed      bool
+}
+
+// subscriptionBuffer is the number of events buffered per subscriber.
+const subscriptionBuffer = 64
+
+// NewBus creates an event bus.
+func NewBus(logger logrus.FieldLogger) *Bus {
+	return &Bus{
+		logger:      logger,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Subscribe registers a subscriber for the events matching the filter.
+func (b *Bus) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{filter: filter, events: make(chan Event, subscriptionBuffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+	if sub.dropped > 0 {
+		b.logger.Debugf("Event subscriber dropped %d event(s) while it was connected", sub.dropped)
+	}
+}
+
+// HasSubscribers reports whether anyone is listening, so publishers can skip work done only to
+// build events. A nil bus has no subscribers.
+func (b *Bus) HasSubscribers() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// Publish assigns the event an ID and timestamp and delivers it to the matching subscribers.
+// Publishing on a nil bus is a no-op.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// Close closes every subscription, ending their streams. Later publishes are ignored.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	event := Event{Type: TypeDeviceStatusChanged, HostID: "nas", DeviceID: "dev-1"}
+
+	require.True(t, Filter{}.Matches(event))
+	require.True(t, Filter{HostID: "nas", DeviceID: "dev-1", Types: []string{TypeCollectorError, TypeDeviceStatusChanged}}.Matches(event))
+	require.False(t, Filter{HostID: "backup"}.Matches(event))
+	require.False(t, Filter{DeviceID: "dev-2"}.Matches(event))
+	require.False(t, Filter{Types: []string{TypeNotificationSent}}.Matches(event))
+}
+
+func TestBus_PublishDeliversMatchingEvents(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(logrus.StandardLogger())
+	all := bus.Subscribe(Filter{})
+	nas := bus.Subscribe(Filter{HostID: "nas"})
+	require.True(t, bus.HasSubscribers())
+
+	bus.Publish(Event{Type: TypeCollectorError, HostID: "nas"})
+	bus.Publish(Event{Type: TypeCollectorError, HostID: "backup"})
+
+	first := <-all.Events()
+	second := <-all.Events()
+	require.Equal(t, uint64(1), first.ID)
+	require.Equal(t, uint64(2), second.ID)
+	require.False(t, first.Time.IsZero())
+
+	require.Equal(t, "nas", (<-nas.Events()).HostID)
+	require.Empty(t, nas.Events())
+
+	bus.Unsubscribe(nas)
+	_, open := <-nas.Events()
+	require.False(t, open, "unsubscribing closes the channel")
+	bus.Unsubscribe(nas)
+}
+
+func TestBus_PublishDropsEventsForSlowSubscribers(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(logrus.StandardLogger())
+	sub := bus.Subscribe(Filter{})
+	for i := 0; i < subscriptionBuffer+10; i++ {
+		bus.Publish(Event{Type: TypeDeviceMetricsIngested})
+	}
+	require.Len(t, sub.Events(), subscriptionBuffer)
+	require.Equal(t, uint64(10), sub.dropped)
+}
+
+func TestBus_CloseEndsSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(logrus.StandardLogger())
+	sub := bus.Subscribe(Filter{})
+	bus.Close()
+	_, open := <-sub.Events()
+	require.False(t, open)
+	require.False(t, bus.HasSubscribers())
+
+	// publishing after close, or on a nil bus, is ignored
+	bus.Publish(Event{Type: TypeCollectorError})
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: TypeCollectorError})
+	require.False(t, nilBus.HasSubscribers())
+
+	late := bus.Subscribe(Filter{})
+	_, open = <-late.Events()
+	require.False(t, open)
+}
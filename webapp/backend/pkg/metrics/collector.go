@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +32,9 @@ var zfsScrubStateCodes = map[models.ZFSScrubState]float64{
 	models.ZFSScrubStateCanceled: 4,
 }
 
+// zfsPoolLabelNames identify a pool; host_id and label are shared with the mdadm, Btrfs and filesystem metrics.
+var zfsPoolLabelNames = []string{"guid", "pool_name", "host_id", "label"}
+
 var workloadIntensityCodes = map[string]float64{
 	"unknown": 0,
 	"idle":    1,
@@ -43,24 +47,45 @@ const workloadMetricsDurationKey = "week"
 
 var workloadIntensityOrder = []string{"unknown", "idle", "light", "medium", "heavy"}
 
-// Collector manages Prometheus metrics for all devices and pools.
+// tagInfoMetrics maps each taggable resource type to its tag info metric and ID label.
+var tagInfoMetrics = []struct {
+	resourceType string
+	metricName   string
+	idLabel      string
+}{
+	{models.TagResourceDevice, "scrutiny_device_tag_info", "device_id"},
+	{models.TagResourceZFSPool, "scrutiny_zfs_pool_tag_info", "guid"},
+	{models.TagResourceMdadmArray, "scrutiny_mdadm_array_tag_info", "uuid"},
+	{models.TagResourceBtrfsFilesystem, "scrutiny_btrfs_filesystem_tag_info", "uuid"},
+}
+
+// Collector manages Prometheus metrics for all devices and storage layers.
 type Collector struct {
-	mu        sync.RWMutex
-	devices   map[string]*metricsModels.DeviceMetricsData
-	zfsPools  map[string]*metricsModels.ZFSPoolMetricsData
-	workloads map[string]*metricsModels.WorkloadMetricsData
-	registry  *prometheus.Registry
-	logger    *logrus.Entry
+	mu               sync.RWMutex
+	devices          map[string]*metricsModels.DeviceMetricsData
+	zfsPools         map[string]*metricsModels.ZFSPoolMetricsData
+	mdadmArrays      map[string]*metricsModels.MdadmArrayMetricsData
+	btrfsFilesystems map[string]*metricsModels.BtrfsFilesystemMetricsData
+	filesystems      map[string]*metricsModels.FilesystemMetricsData // keyed by host ID and mount point
+	workloads        map[string]*metricsModels.WorkloadMetricsData
+	tags             map[string]map[string][]string // resource type -> resource ID -> tags
+	queue            *notificationQueueDepth        // nil until first refreshed by the retry monitor
+	registry         *prometheus.Registry
+	logger           *logrus.Entry
 }
 
 // NewCollector creates a new metrics collector.
 func NewCollector(logger *logrus.Entry) *Collector {
 	mc := &Collector{
-		devices:   make(map[string]*metricsModels.DeviceMetricsData),
-		zfsPools:  make(map[string]*metricsModels.ZFSPoolMetricsData),
-		workloads: make(map[string]*metricsModels.WorkloadMetricsData),
-		registry:  prometheus.NewRegistry(),
-		logger:    logger,
+		devices:          make(map[string]*metricsModels.DeviceMetricsData),
+		zfsPools:         make(map[string]*metricsModels.ZFSPoolMetricsData),
+		mdadmArrays:      make(map[string]*metricsModels.MdadmArrayMetricsData),
+		btrfsFilesystems: make(map[string]*metricsModels.BtrfsFilesystemMetricsData),
+		filesystems:      make(map[string]*metricsModels.FilesystemMetricsData),
+		workloads:        make(map[string]*metricsModels.WorkloadMetricsData),
+		tags:             make(map[string]map[string][]string),
+		registry:         prometheus.NewRegistry(),
+		logger:           logger,
 	}
 
 	mc.registry.MustRegister(collectors.NewGoCollector())
@@ -137,7 +162,43 @@ func (mc *Collector) RefreshZFSPoolMetrics(deviceRepo database.DeviceRepo, ctx c
 	return nil
 }
 
-// LoadInitialData loads device, workload, and ZFS data at startup.
+// RefreshTagMetrics reloads the tags of devices, pools, arrays and filesystems from the repository.
+func (mc *Collector) RefreshTagMetrics(deviceRepo database.DeviceRepo, ctx context.Context) error {
+	next := make(map[string]map[string][]string, len(tagInfoMetrics))
+	for _, info := range tagInfoMetrics {
+		tags, err := deviceRepo.GetResourceTags(ctx, info.resourceType)
+		if err != nil {
+			return fmt.Errorf("failed to load %s tags: %w", info.resourceType, err)
+		}
+		next[info.resourceType] = tags
+	}
+
+	mc.mu.Lock()
+	mc.tags = next
+	mc.mu.Unlock()
+	return nil
+}
+
+// notificationQueueDepth is the size of the durable notification retry queue.
+type notificationQueueDepth struct {
+	pending int64
+	dead    int64
+}
+
+// RefreshNotificationQueueMetrics reloads the depth of the notification retry queue.
+func (mc *Collector) RefreshNotificationQueueMetrics(deviceRepo database.DeviceRepo, ctx context.Context) error {
+	pending, dead, err := deviceRepo.CountNotificationRetries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count notification retries: %w", err)
+	}
+
+	mc.mu.Lock()
+	mc.queue = &notificationQueueDepth{pending: pending, dead: dead}
+	mc.mu.Unlock()
+	return nil
+}
+
+// LoadInitialData loads device, workload, ZFS, mdadm, Btrfs and filesystem data at startup.
 func (mc *Collector) LoadInitialData(deviceRepo database.DeviceRepo, ctx context.Context) error {
 	start := time.Now()
 	mc.logger.Info("Loading initial metrics data from database...")
@@ -189,10 +250,22 @@ func (mc *Collector) LoadInitialData(deviceRepo database.DeviceRepo, ctx context
 	if err := mc.RefreshZFSPoolMetrics(deviceRepo, ctx); err != nil {
 		return err
 	}
+	if err := mc.RefreshMdadmMetrics(deviceRepo, ctx); err != nil {
+		return err
+	}
+	if err := mc.RefreshBtrfsMetrics(deviceRepo, ctx); err != nil {
+		return err
+	}
+	if err := mc.RefreshFilesystemMetrics(deviceRepo, ctx); err != nil {
+		return err
+	}
+	if err := mc.RefreshTagMetrics(deviceRepo, ctx); err != nil {
+		return err
+	}
 
 	mc.logger.Infof(
-		"Loaded metrics for %d devices, %d workloads, and %d ZFS pools in %v",
-		len(mc.devices), len(mc.workloads), len(mc.zfsPools), time.Since(start),
+		"Loaded metrics for %d devices, %d workloads, %d ZFS pools, %d mdadm arrays, %d Btrfs filesystems, and %d filesystems in %v",
+		len(mc.devices), len(mc.workloads), len(mc.zfsPools), len(mc.mdadmArrays), len(mc.btrfsFilesystems), len(mc.filesystems), time.Since(start),
 	)
 	return nil
 }
@@ -219,7 +292,12 @@ func (mc *Collector) Collect(ch chan<- prometheus.Metric) {
 	mc.collectSummaryMetrics(ch)
 	mc.collectStatistics(ch)
 	mc.collectZFSPoolMetrics(ch)
+	mc.collectMdadmArrayMetrics(ch)
+	mc.collectBtrfsFilesystemMetrics(ch)
+	mc.collectFilesystemMetrics(ch)
 	mc.collectWorkloadMetrics(ch)
+	mc.collectTagMetrics(ch)
+	mc.collectNotificationQueueMetrics(ch)
 
 	mc.logger.Debugf(
 		"Metrics collected in %v for %d devices, %d workloads, and %d pools",
@@ -361,6 +439,18 @@ func (mc *Collector) collectStatistics(ch chan<- prometheus.Metric) {
 		prometheus.NewDesc("scrutiny_zfs_pools_total", "Total number of monitored ZFS pools", nil, nil),
 		prometheus.GaugeValue, float64(len(mc.zfsPools)),
 	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("scrutiny_mdadm_arrays_total", "Total number of monitored mdadm arrays", nil, nil),
+		prometheus.GaugeValue, float64(len(mc.mdadmArrays)),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("scrutiny_btrfs_filesystems_total", "Total number of monitored Btrfs filesystems", nil, nil),
+		prometheus.GaugeValue, float64(len(mc.btrfsFilesystems)),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("scrutiny_filesystems_total", "Total number of monitored mounted filesystems", nil, nil),
+		prometheus.GaugeValue, float64(len(mc.filesystems)),
+	)
 }
 
 func (mc *Collector) collectZFSPoolMetrics(ch chan<- prometheus.Metric) {
@@ -382,78 +472,78 @@ func (mc *Collector) collectZFSPoolMetrics(ch chan<- prometheus.Metric) {
 	}
 
 	for _, data := range mc.zfsPools {
-		labels := []string{data.Pool.GUID, data.Pool.Name, data.Pool.HostID}
+		labels := []string{data.Pool.GUID, data.Pool.Name, data.Pool.HostID, data.Pool.Label}
 
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_size_bytes", "ZFS pool size in bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.Size), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_allocated_bytes", "ZFS pool allocated bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.Allocated), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_free_bytes", "ZFS pool free bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.Free), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_capacity_percent", "ZFS pool capacity percent",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, data.Pool.CapacityPercent, labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_fragmentation_percent", "ZFS pool fragmentation percent",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.Fragmentation), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_errors_read_total", "ZFS pool read errors",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.TotalReadErrors), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_errors_write_total", "ZFS pool write errors",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.TotalWriteErrors), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_errors_checksum_total", "ZFS pool checksum errors",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.TotalChecksumErrors), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_scanned_bytes", "ZFS pool scrub scanned bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.ScrubScannedBytes), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_issued_bytes", "ZFS pool scrub issued bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.ScrubIssuedBytes), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_total_bytes", "ZFS pool scrub total bytes",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.ScrubTotalBytes), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_errors_total", "ZFS pool scrub errors",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, float64(data.Pool.ScrubErrorsCount), labels...,
 		)
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_percent_complete", "ZFS pool scrub percent complete",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, data.Pool.ScrubPercentComplete, labels...,
 		)
 
 		currentStatus := data.Pool.Status
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_status_code", "ZFS pool status code",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, zfsPoolStatusCodes[currentStatus], labels...,
 		)
 		for _, status := range statusOptions {
@@ -463,16 +553,16 @@ func (mc *Collector) collectZFSPoolMetrics(ch chan<- prometheus.Metric) {
 			}
 			ch <- prometheus.MustNewConstMetric(
 				prometheus.NewDesc("scrutiny_zfs_pool_status", "ZFS pool status as one-hot gauge",
-					[]string{"guid", "pool_name", "host_id", "status"}, nil),
+					append(zfsPoolLabelNames, "status"), nil),
 				prometheus.GaugeValue, metricValue(currentStatus, status),
-				data.Pool.GUID, data.Pool.Name, data.Pool.HostID, statusLabel,
+				append(labels, statusLabel)...,
 			)
 		}
 
 		currentScrub := data.Pool.ScrubState
 		ch <- prometheus.MustNewConstMetric(
 			prometheus.NewDesc("scrutiny_zfs_pool_scrub_state_code", "ZFS pool scrub state code",
-				[]string{"guid", "pool_name", "host_id"}, nil),
+				zfsPoolLabelNames, nil),
 			prometheus.GaugeValue, zfsScrubStateCodes[currentScrub], labels...,
 		)
 		for _, state := range scrubOptions {
@@ -482,9 +572,9 @@ func (mc *Collector) collectZFSPoolMetrics(ch chan<- prometheus.Metric) {
 			}
 			ch <- prometheus.MustNewConstMetric(
 				prometheus.NewDesc("scrutiny_zfs_pool_scrub_state", "ZFS pool scrub state as one-hot gauge",
-					[]string{"guid", "pool_name", "host_id", "scrub_state"}, nil),
+					append(zfsPoolLabelNames, "scrub_state"), nil),
 				prometheus.GaugeValue, metricValue(currentScrub, state),
-				data.Pool.GUID, data.Pool.Name, data.Pool.HostID, scrubLabel,
+				append(labels, scrubLabel)...,
 			)
 		}
 	}
@@ -590,3 +680,27 @@ func (mc *Collector) collectWorkloadMetrics(ch chan<- prometheus.Metric) {
 		}
 	}
 }
+
+// collectTagMetrics exports one series per tag, so dashboards can join any metric on its ID label,
+// e.g. scrutiny_device_status * on(device_id) group_left(tag) scrutiny_device_tag_info{tag="backup"}.
+func (mc *Collector) collectTagMetrics(ch chan<- prometheus.Metric) {
+	for _, info := range tagInfoMetrics {
+		desc := prometheus.NewDesc(info.metricName, fmt.Sprintf("Tags assigned to a %s", strings.ReplaceAll(info.resourceType, "_", " ")),
+			[]string{info.idLabel, "tag"}, nil)
+		for resourceID, tags := range mc.tags[info.resourceType] {
+			for _, tag := range tags {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, resourceID, tag)
+			}
+		}
+	}
+}
+
+func (mc *Collector) collectNotificationQueueMetrics(ch chan<- prometheus.Metric) {
+	if mc.queue == nil {
+		return
+	}
+	desc := prometheus.NewDesc("scrutiny_notification_queue_depth",
+		"Notifications waiting in the delivery retry queue (pending) or given up on (dead)", []string{"status"}, nil)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(mc.queue.pending), models.NotificationRetryPending)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(mc.queue.dead), models.NotificationRetryDead)
+}
@@ -271,6 +271,40 @@ func gatherMetricFamilies(t *testing.T, collector *Collector) map[string]*dto.Me
 	return indexed
 }
 
+func TestCollectorExportsTags(t *testing.T) {
+	collector := NewCollector(logrus.New().WithField("test", "collector"))
+	collector.devices["dev-1"] = &metricsModels.DeviceMetricsData{
+		Device: models.Device{DeviceID: "dev-1", WWN: "wwn-1", DeviceName: "sda", HostId: "host-a"},
+	}
+	collector.tags = map[string]map[string][]string{
+		models.TagResourceDevice:  {"dev-1": {"backup", "team-storage"}},
+		models.TagResourceZFSPool: {"pool-guid": {"media"}},
+	}
+
+	families := gatherMetricFamilies(t, collector)
+
+	assertMetricValue(t, families, "scrutiny_device_info", 1, map[string]string{"device_id": "dev-1"})
+	for _, label := range families["scrutiny_device_info"].GetMetric()[0].GetLabel() {
+		assert.NotEqual(t, "tags", label.GetName(), "tags are only exported as tag info series")
+	}
+	assertMetricValue(t, families, "scrutiny_device_tag_info", 1, map[string]string{"device_id": "dev-1", "tag": "backup"})
+	assertMetricValue(t, families, "scrutiny_device_tag_info", 1, map[string]string{"device_id": "dev-1", "tag": "team-storage"})
+	assertMetricValue(t, families, "scrutiny_zfs_pool_tag_info", 1, map[string]string{"guid": "pool-guid", "tag": "media"})
+}
+
+func TestCollectorExportsNotificationQueueDepth(t *testing.T) {
+	collector := NewCollector(logrus.New().WithField("test", "collector"))
+
+	families := gatherMetricFamilies(t, collector)
+	assert.NotContains(t, families, "scrutiny_notification_queue_depth", "queue depth is only exported once refreshed")
+
+	collector.queue = &notificationQueueDepth{pending: 3, dead: 1}
+	families = gatherMetricFamilies(t, collector)
+
+	assertMetricValue(t, families, "scrutiny_notification_queue_depth", 3, map[string]string{"status": "pending"})
+	assertMetricValue(t, families, "scrutiny_notification_queue_depth", 1, map[string]string{"status": "dead"})
+}
+
 func assertMetricValue(t *testing.T, families map[string]*dto.MetricFamily, metricName string, expected float64, labels map[string]string) {
 	t.Helper()
 
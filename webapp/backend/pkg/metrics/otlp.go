@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	prometheusbridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OTLP protocols, selected with web.metrics.otlp.protocol
+const (
+	OTLPProtocolHTTP = "http"
+	OTLPProtocolGRPC = "grpc"
+)
+
+const scrutinyMetricPrefix = "scrutiny_"
+
+const otlpHTTPMetricsPath = "/v1/metrics"
+
+// OTLPExporter pushes the gauges of a Collector to an OpenTelemetry receiver on an interval, for
+// deployments that run an OpenTelemetry collector instead of scraping /api/metrics.
+// Prometheus labels (device_id, host_id, label, ...) become OTLP data point attributes.
+type OTLPExporter struct {
+	provider *sdkmetric.MeterProvider
+	logger   *logrus.Entry
+}
+
+// NewOTLPExporter creates an exporter from the web.metrics.otlp settings and starts pushing.
+func NewOTLPExporter(ctx context.Context, appConfig config.Interface, collector *Collector, logger *logrus.Entry) (*OTLPExporter, error) {
+	endpoint := strings.TrimSpace(appConfig.GetString("web.metrics.otlp.endpoint"))
+	if endpoint == "" {
+		return nil, fmt.Errorf("web.metrics.otlp.endpoint is required")
+	}
+	// the scheme selects TLS, for gRPC as well
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid web.metrics.otlp.endpoint %q: must be a URL like http://otel-collector:4318", endpoint)
+	}
+	headers, err := config.StringMap(appConfig, "web.metrics.otlp.headers")
+	if err != nil {
+		return nil, err
+	}
+	resourceAttributes, err := config.StringMap(appConfig, "web.metrics.otlp.resource_attributes")
+	if err != nil {
+		return nil, err
+	}
+
+	interval := time.Duration(appConfig.GetInt("web.metrics.otlp.interval_seconds")) * time.Second
+	if interval <= 0 {
+		return nil, fmt.Errorf("web.metrics.otlp.interval_seconds must be positive")
+	}
+	timeout := time.Duration(appConfig.GetInt("web.metrics.otlp.timeout_seconds")) * time.Second
+	if timeout <= 0 || timeout > interval {
+		timeout = interval
+	}
+
+	protocol := strings.ToLower(strings.TrimSpace(appConfig.GetString("web.metrics.otlp.protocol")))
+	if protocol == "" || protocol == "http/protobuf" {
+		protocol = OTLPProtocolHTTP
+	}
+
+	var exporter sdkmetric.Exporter
+	switch protocol {
+	case OTLPProtocolHTTP:
+		// the exporter posts to the URL as is, default to the standard metrics path of a receiver
+		if strings.Trim(endpointURL.Path, "/") == "" {
+			endpointURL.Path = otlpHTTPMetricsPath
+			endpoint = endpointURL.String()
+		}
+		exporter, err = otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithEndpointURL(endpoint),
+			otlpmetrichttp.WithHeaders(headers),
+			otlpmetrichttp.WithTimeout(timeout),
+		)
+	case OTLPProtocolGRPC:
+		exporter, err = otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithEndpointURL(endpoint),
+			otlpmetricgrpc.WithHeaders(headers),
+			otlpmetricgrpc.WithTimeout(timeout),
+		)
+	default:
+		return nil, fmt.Errorf("invalid web.metrics.otlp.protocol %q: must be %s or %s", protocol, OTLPProtocolHTTP, OTLPProtocolGRPC)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithInterval(interval),
+		sdkmetric.WithTimeout(timeout),
+		sdkmetric.WithProducer(prometheusbridge.NewMetricProducer(
+			prometheusbridge.WithGatherer(scrutinyGatherer(collector.GetRegistry())),
+		)),
+	)
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(otlpResource(resourceAttributes)),
+	)
+
+	logger.Infof("Pushing metrics over OTLP/%s to %s every %v", protocol, endpoint, interval)
+	return &OTLPExporter{provider: provider, logger: logger}, nil
+}
+
+// Shutdown pushes the current metrics a last time and stops the exporter.
+func (e *OTLPExporter) Shutdown(ctx context.Context) {
+	if err := e.provider.Shutdown(ctx); err != nil {
+		e.logger.Warnf("Failed to shut down the OTLP metrics exporter: %v", err)
+	}
+}
+
+// scrutinyGatherer limits the pushed metrics to the Scrutiny gauges, leaving out the Go runtime
+// metrics of the registry that the receiver's own process metrics already cover.
+func scrutinyGatherer(registry *prometheus.Registry) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := registry.Gather()
+		filtered := families[:0]
+		for _, family := range families {
+			if strings.HasPrefix(family.GetName(), scrutinyMetricPrefix) {
+				filtered = append(filtered, family)
+			}
+		}
+		return filtered, err
+	})
+}
+
+// otlpResource describes this Scrutiny instance. Configured attributes override the defaults,
+// e.g. service.instance.id to tell several instances apart.
+func otlpResource(attributes map[string]string) *resource.Resource {
+	merged := map[string]string{
+		"service.name":    "scrutiny",
+		"service.version": version.VERSION,
+	}
+	for key, value := range attributes {
+		merged[key] = value
+	}
+
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	kvs := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, attribute.String(key, merged[key]))
+	}
+	return resource.NewSchemaless(kvs...)
+}
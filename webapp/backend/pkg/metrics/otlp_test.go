@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	metricsModels "github.com/analogj/scrutiny/webapp/backend/pkg/models/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func newOTLPTestConfig(t *testing.T, endpoint, protocol string) config.Interface {
+	t.Helper()
+
+	cfg, err := config.Create()
+	require.NoError(t, err)
+	cfg.Set("web.metrics.otlp.enabled", true)
+	cfg.Set("web.metrics.otlp.endpoint", endpoint)
+	cfg.Set("web.metrics.otlp.protocol", protocol)
+	// long enough that only the flush in the test exports
+	cfg.Set("web.metrics.otlp.interval_seconds", 3600)
+	return cfg
+}
+
+func newOTLPTestCollector() *Collector {
+	collector := NewCollector(logrus.New().WithField("test", "otlp"))
+	collector.devices["dev-1"] = &metricsModels.DeviceMetricsData{
+		Device: models.Device{DeviceID: "dev-1", WWN: "wwn-1", DeviceName: "sda", HostId: "nas-1", DeviceStatus: 1},
+	}
+	collector.zfsPools["pool-guid"] = &metricsModels.ZFSPoolMetricsData{
+		Pool: models.ZFSPool{GUID: "pool-guid", Name: "tank", HostID: "nas-1", Label: "media", Size: 1000},
+	}
+	return collector
+}
+
+// otlpGauges indexes the gauge data points of an export request by metric name.
+func otlpGauges(request *collectormetricspb.ExportMetricsServiceRequest) map[string][]map[string]string {
+	gauges := map[string][]map[string]string{}
+	for _, resourceMetrics := range request.GetResourceMetrics() {
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, metric := range scopeMetrics.GetMetrics() {
+				for _, point := range metric.GetGauge().GetDataPoints() {
+					gauges[metric.GetName()] = append(gauges[metric.GetName()], otlpAttributes(point.GetAttributes()))
+				}
+			}
+		}
+	}
+	return gauges
+}
+
+func otlpAttributes(attributes []*commonpb.KeyValue) map[string]string {
+	result := map[string]string{}
+	for _, kv := range attributes {
+		result[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return result
+}
+
+func TestOTLPExporter_PushesCollectorGaugesOverHTTP(t *testing.T) {
+	type received struct {
+		path, auth string
+		request    *collectormetricspb.ExportMetricsServiceRequest
+	}
+	requests := make(chan received, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &collectormetricspb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- received{path: r.URL.Path, auth: r.Header.Get("Authorization"), request: request}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		response, _ := proto.Marshal(&collectormetricspb.ExportMetricsServiceResponse{})
+		_, _ = w.Write(response)
+	}))
+	defer receiver.Close()
+
+	cfg := newOTLPTestConfig(t, receiver.URL, "http")
+	// the environment variable format
+	cfg.Set("web.metrics.otlp.headers", "Authorization=Bearer secret, X-Scope-OrgID=nas")
+	cfg.Set("web.metrics.otlp.resource_attributes", map[string]string{"deployment.environment": "home"})
+
+	exporter, err := NewOTLPExporter(context.Background(), cfg, newOTLPTestCollector(), logrus.New().WithField("test", "otlp"))
+	require.NoError(t, err)
+	require.NoError(t, exporter.provider.ForceFlush(context.Background()))
+
+	var got received
+	select {
+	case got = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the OTLP receiver got no request")
+	}
+	assert.Equal(t, "/v1/metrics", got.path)
+	assert.Equal(t, "Bearer secret", got.auth)
+
+	require.Len(t, got.request.GetResourceMetrics(), 1)
+	resourceAttributes := otlpAttributes(got.request.GetResourceMetrics()[0].GetResource().GetAttributes())
+	assert.Equal(t, "scrutiny", resourceAttributes["service.name"])
+	assert.Equal(t, "home", resourceAttributes["deployment.environment"])
+
+	gauges := otlpGauges(got.request)
+	require.Contains(t, gauges, "scrutiny_device_status")
+	assert.Equal(t, "dev-1", gauges["scrutiny_device_status"][0]["device_id"])
+	assert.Equal(t, "nas-1", gauges["scrutiny_device_status"][0]["host_id"])
+	require.Contains(t, gauges, "scrutiny_zfs_pool_size_bytes")
+	assert.Equal(t, "media", gauges["scrutiny_zfs_pool_size_bytes"][0]["label"])
+	assert.Contains(t, gauges, "scrutiny_devices_total")
+	for name := range gauges {
+		assert.Regexp(t, "^scrutiny_", name, "only the Scrutiny metrics are pushed")
+	}
+
+	exporter.Shutdown(context.Background())
+}
+
+type otlpGRPCReceiver struct {
+	collectormetricspb.UnimplementedMetricsServiceServer
+	requests chan *collectormetricspb.ExportMetricsServiceRequest
+	auth     chan []string
+}
+
+func (r *otlpGRPCReceiver) Export(ctx context.Context, request *collectormetricspb.ExportMetricsServiceRequest) (*collectormetricspb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.auth <- md.Get("authorization")
+	r.requests <- request
+	return &collectormetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestOTLPExporter_PushesCollectorGaugesOverGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	receiver := &otlpGRPCReceiver{
+		requests: make(chan *collectormetricspb.ExportMetricsServiceRequest, 4),
+		auth:     make(chan []string, 4),
+	}
+	server := grpc.NewServer()
+	collectormetricspb.RegisterMetricsServiceServer(server, receiver)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	cfg := newOTLPTestConfig(t, "http://"+listener.Addr().String(), "grpc")
+	cfg.Set("web.metrics.otlp.headers", map[string]string{"authorization": "Bearer secret"})
+
+	exporter, err := NewOTLPExporter(context.Background(), cfg, newOTLPTestCollector(), logrus.New().WithField("test", "otlp"))
+	require.NoError(t, err)
+	require.NoError(t, exporter.provider.ForceFlush(context.Background()))
+
+	select {
+	case request := <-receiver.requests:
+		gauges := otlpGauges(request)
+		require.Contains(t, gauges, "scrutiny_device_status")
+		assert.Equal(t, "dev-1", gauges["scrutiny_device_status"][0]["device_id"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("the OTLP receiver got no request")
+	}
+	assert.Equal(t, []string{"Bearer secret"}, <-receiver.auth)
+
+	exporter.Shutdown(context.Background())
+}
+
+func TestNewOTLPExporter_RejectsInvalidSettings(t *testing.T) {
+	logger := logrus.New().WithField("test", "otlp")
+	collector := NewCollector(logger)
+
+	_, err := NewOTLPExporter(context.Background(), newOTLPTestConfig(t, "otel-collector:4318", "http"), collector, logger)
+	assert.ErrorContains(t, err, "web.metrics.otlp.endpoint")
+
+	_, err = NewOTLPExporter(context.Background(), newOTLPTestConfig(t, "http://otel-collector:4318", "thrift"), collector, logger)
+	assert.ErrorContains(t, err, "web.metrics.otlp.protocol")
+
+	cfg := newOTLPTestConfig(t, "http://otel-collector:4318", "http")
+	cfg.Set("web.metrics.otlp.headers", "Authorization")
+	_, err = NewOTLPExporter(context.Background(), cfg, collector, logger)
+	assert.ErrorContains(t, err, "web.metrics.otlp.headers")
+}
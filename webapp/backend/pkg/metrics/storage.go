@@ -0,0 +1,468 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	metricsModels "github.com/analogj/scrutiny/webapp/backend/pkg/models/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The mdadm, Btrfs and filesystem metrics share the host_id and label labels with the ZFS pool
+// metrics, so a single dashboard can filter every storage layer by host or label.
+var (
+	mdadmArrayLabelNames      = []string{"uuid", "array_name", "host_id", "label"}
+	btrfsFilesystemLabelNames = []string{"uuid", "host_id", "label", "mount_point"}
+	btrfsDeviceLabelNames     = []string{"uuid", "host_id", "label", "mount_point", "devid", "device_path"}
+	filesystemLabelNames      = []string{"host_id", "label", "mount_point", "source_device", "filesystem_type"}
+)
+
+var btrfsFilesystemStatusCodes = map[models.BtrfsFilesystemStatus]float64{
+	models.BtrfsFilesystemStatusOnline:   1,
+	models.BtrfsFilesystemStatusDegraded: 2,
+}
+
+var btrfsScrubStateCodes = map[models.BtrfsScrubState]float64{
+	models.BtrfsScrubStateIdle:     1,
+	models.BtrfsScrubStateRunning:  2,
+	models.BtrfsScrubStateFinished: 3,
+	models.BtrfsScrubStateAborted:  4,
+}
+
+// RefreshMdadmMetrics refreshes the metrics of all mdadm arrays from the repository.
+func (mc *Collector) RefreshMdadmMetrics(deviceRepo database.DeviceRepo, ctx context.Context) error {
+	arrays, err := deviceRepo.GetMdadmArraysSummary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load mdadm array summary: %w", err)
+	}
+
+	now := time.Now()
+	next := make(map[string]*metricsModels.MdadmArrayMetricsData, len(arrays))
+	for uuid, array := range arrays {
+		if array == nil {
+			continue
+		}
+		latest, err := deviceRepo.GetLatestMdadmMetrics(ctx, uuid)
+		if err != nil {
+			return fmt.Errorf("failed to load metrics of mdadm array %s: %w", uuid, err)
+		}
+		next[uuid] = &metricsModels.MdadmArrayMetricsData{
+			Array:     *array,
+			Latest:    latest,
+			UpdatedAt: now,
+		}
+	}
+
+	mc.mu.Lock()
+	mc.mdadmArrays = next
+	mc.mu.Unlock()
+
+	mc.logger.Debugf("Refreshed mdadm metrics for %d arrays", len(next))
+	return nil
+}
+
+// RefreshMdadmArrayMetrics refreshes the metrics of a single mdadm array after a metrics upload.
+func (mc *Collector) RefreshMdadmArrayMetrics(deviceRepo database.DeviceRepo, ctx context.Context, uuid string) error {
+	array, err := deviceRepo.GetMdadmArrayDetails(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("failed to load mdadm array %s: %w", uuid, err)
+	}
+	latest, err := deviceRepo.GetLatestMdadmMetrics(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("failed to load metrics of mdadm array %s: %w", uuid, err)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if array.Archived {
+		delete(mc.mdadmArrays, uuid)
+		return nil
+	}
+	mc.mdadmArrays[uuid] = &metricsModels.MdadmArrayMetricsData{
+		Array:     array,
+		Latest:    latest,
+		UpdatedAt: time.Now(),
+	}
+	return nil
+}
+
+// RefreshBtrfsMetrics refreshes the metrics of all Btrfs filesystems and their devices from the repository.
+func (mc *Collector) RefreshBtrfsMetrics(deviceRepo database.DeviceRepo, ctx context.Context) error {
+	filesystems, err := deviceRepo.GetBtrfsFilesystemsSummary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load Btrfs filesystem summary: %w", err)
+	}
+
+	now := time.Now()
+	next := make(map[string]*metricsModels.BtrfsFilesystemMetricsData, len(filesystems))
+	for uuid, filesystem := range filesystems {
+		if filesystem == nil {
+			continue
+		}
+		// the summary omits the devices and their error counters
+		details, err := deviceRepo.GetBtrfsFilesystemDetails(ctx, uuid)
+		if err != nil {
+			return fmt.Errorf("failed to load Btrfs filesystem %s: %w", uuid, err)
+		}
+		next[uuid] = &metricsModels.BtrfsFilesystemMetricsData{
+			Filesystem: details,
+			UpdatedAt:  now,
+		}
+	}
+
+	mc.mu.Lock()
+	mc.btrfsFilesystems = next
+	mc.mu.Unlock()
+
+	mc.logger.Debugf("Refreshed Btrfs metrics for %d filesystems", len(next))
+	return nil
+}
+
+// RefreshBtrfsFilesystemMetrics refreshes the metrics of a single Btrfs filesystem after a metrics upload.
+func (mc *Collector) RefreshBtrfsFilesystemMetrics(deviceRepo database.DeviceRepo, ctx context.Context, uuid string) error {
+	filesystem, err := deviceRepo.GetBtrfsFilesystemDetails(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("failed to load Btrfs filesystem %s: %w", uuid, err)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if filesystem.Archived {
+		delete(mc.btrfsFilesystems, uuid)
+		return nil
+	}
+	mc.btrfsFilesystems[uuid] = &metricsModels.BtrfsFilesystemMetricsData{
+		Filesystem: filesystem,
+		UpdatedAt:  time.Now(),
+	}
+	return nil
+}
+
+// RefreshFilesystemMetrics refreshes the capacity metrics of all mounted filesystems from the repository.
+func (mc *Collector) RefreshFilesystemMetrics(deviceRepo database.DeviceRepo, ctx context.Context) error {
+	filesystemsByHost, _, err := deviceRepo.GetFilesystemSummary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load filesystem summary: %w", err)
+	}
+
+	now := time.Now()
+	next := make(map[string]*metricsModels.FilesystemMetricsData)
+	for hostID, filesystems := range filesystemsByHost {
+		for _, filesystem := range filesystems {
+			next[hostID+":"+filesystem.MountPoint] = &metricsModels.FilesystemMetricsData{
+				Filesystem: filesystem,
+				UpdatedAt:  now,
+			}
+		}
+	}
+
+	mc.mu.Lock()
+	mc.filesystems = next
+	mc.mu.Unlock()
+
+	mc.logger.Debugf("Refreshed filesystem metrics for %d filesystems", len(next))
+	return nil
+}
+
+func (mc *Collector) collectMdadmArrayMetrics(ch chan<- prometheus.Metric) {
+	for _, data := range mc.mdadmArrays {
+		labels := []string{data.Array.UUID, data.Array.Name, data.Array.HostID, data.Array.Label}
+
+		state := ""
+		if data.Latest != nil {
+			state = data.Latest.State
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_info", "mdadm array information",
+				append(mdadmArrayLabelNames, "level", "state"), nil),
+			prometheus.GaugeValue, 1, append(labels, data.Array.Level, state)...,
+		)
+		if data.Latest == nil {
+			continue
+		}
+		latest := data.Latest
+
+		degraded := latest.FailedDevices > 0 || strings.Contains(strings.ToLower(latest.State), "degraded")
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_degraded", "mdadm array degraded (0=no, 1=yes)",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, metricValue(degraded, true), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_active_devices", "mdadm array active devices",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.ActiveDevices), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_working_devices", "mdadm array working devices",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.WorkingDevices), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_failed_devices", "mdadm array failed devices",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.FailedDevices), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_spare_devices", "mdadm array spare devices",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.SpareDevices), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_sync_progress_percent",
+				"mdadm array resync, recovery or check progress percent (0 when idle)",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, latest.SyncProgress, labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_mismatch_count",
+				"Sectors found inconsistent by the last mdadm array check or repair",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.MismatchCount), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_mdadm_array_size_bytes", "mdadm array size in bytes",
+				mdadmArrayLabelNames, nil),
+			prometheus.GaugeValue, float64(latest.ArraySize), labels...,
+		)
+		if latest.UsedBytes > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_mdadm_array_used_bytes", "Used bytes of the filesystem on the mdadm array",
+					mdadmArrayLabelNames, nil),
+				prometheus.GaugeValue, float64(latest.UsedBytes), labels...,
+			)
+		}
+	}
+}
+
+func (mc *Collector) collectBtrfsFilesystemMetrics(ch chan<- prometheus.Metric) {
+	statusOptions := []models.BtrfsFilesystemStatus{
+		"",
+		models.BtrfsFilesystemStatusOnline,
+		models.BtrfsFilesystemStatusDegraded,
+	}
+	scrubOptions := []models.BtrfsScrubState{
+		models.BtrfsScrubStateUnknown,
+		models.BtrfsScrubStateIdle,
+		models.BtrfsScrubStateRunning,
+		models.BtrfsScrubStateFinished,
+		models.BtrfsScrubStateAborted,
+	}
+
+	for _, data := range mc.btrfsFilesystems {
+		fs := data.Filesystem
+		labels := []string{fs.UUID, fs.HostID, fs.Label, fs.MountPoint}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_size_bytes", "Btrfs filesystem size of all devices in bytes",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.DeviceSize), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_used_bytes", "Btrfs filesystem used bytes",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.Used), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_free_estimated_bytes", "Btrfs filesystem estimated free bytes",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.FreeEstimated), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_data_total_bytes", "Btrfs filesystem bytes allocated to data",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.DataTotal), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_data_used_bytes", "Btrfs filesystem data bytes used",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.DataUsed), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_metadata_total_bytes", "Btrfs filesystem bytes allocated to metadata",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.MetadataTotal), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_metadata_used_bytes", "Btrfs filesystem metadata bytes used",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.MetadataUsed), labels...,
+		)
+		if fs.DeviceSize > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_btrfs_filesystem_used_percent", "Btrfs filesystem used percent",
+					btrfsFilesystemLabelNames, nil),
+				prometheus.GaugeValue, float64(fs.Used)/float64(fs.DeviceSize)*100, labels...,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_errors_read_total", "Btrfs filesystem scrub read errors",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.ScrubReadErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_errors_csum_total", "Btrfs filesystem scrub checksum errors",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.ScrubCsumErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_errors_verify_total", "Btrfs filesystem scrub verify errors",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.ScrubVerifyErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_errors_super_total", "Btrfs filesystem scrub superblock errors",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.ScrubSuperErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_errors_total", "Btrfs filesystem scrub errors of all types",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.ScrubReadErrors+fs.ScrubCsumErrors+fs.ScrubVerifyErrors+fs.ScrubSuperErrors), labels...,
+		)
+		if fs.ScrubFinishedAt != nil && !fs.ScrubFinishedAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_age_seconds", "Seconds since the last Btrfs scrub finished",
+					btrfsFilesystemLabelNames, nil),
+				prometheus.GaugeValue, time.Since(*fs.ScrubFinishedAt).Seconds(), labels...,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_status_code", "Btrfs filesystem status code",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, btrfsFilesystemStatusCodes[fs.Status], labels...,
+		)
+		for _, status := range statusOptions {
+			statusLabel := string(status)
+			if statusLabel == "" {
+				statusLabel = "unknown"
+			}
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_btrfs_filesystem_status", "Btrfs filesystem status as one-hot gauge",
+					append(btrfsFilesystemLabelNames, "status"), nil),
+				prometheus.GaugeValue, metricValue(fs.Status, status), append(labels, statusLabel)...,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_state_code", "Btrfs filesystem scrub state code",
+				btrfsFilesystemLabelNames, nil),
+			prometheus.GaugeValue, btrfsScrubStateCodes[fs.ScrubState], labels...,
+		)
+		currentScrub := fs.ScrubState
+		if currentScrub == "" {
+			currentScrub = models.BtrfsScrubStateUnknown
+		}
+		for _, state := range scrubOptions {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_btrfs_filesystem_scrub_state", "Btrfs filesystem scrub state as one-hot gauge",
+					append(btrfsFilesystemLabelNames, "scrub_state"), nil),
+				prometheus.GaugeValue, metricValue(currentScrub, state), append(labels, string(state))...,
+			)
+		}
+
+		mc.collectBtrfsDeviceMetrics(ch, labels, fs.Devices)
+	}
+}
+
+func (mc *Collector) collectBtrfsDeviceMetrics(ch chan<- prometheus.Metric, filesystemLabels []string, devices []models.BtrfsDevice) {
+	for _, device := range devices {
+		labels := append(append([]string{}, filesystemLabels...), strconv.Itoa(device.DeviceID), device.Path)
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_errors_read_total", "Btrfs device read I/O errors",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.ReadIOErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_errors_write_total", "Btrfs device write I/O errors",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.WriteIOErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_errors_flush_total", "Btrfs device flush I/O errors",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.FlushIOErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_errors_corruption_total", "Btrfs device corruption errors",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.CorruptionErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_errors_generation_total", "Btrfs device generation errors",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.GenerationErrors), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_size_bytes", "Btrfs device size in bytes",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, float64(device.Size), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_btrfs_device_missing", "Btrfs device missing (0=no, 1=yes)",
+				btrfsDeviceLabelNames, nil),
+			prometheus.GaugeValue, metricValue(device.Missing, true), labels...,
+		)
+	}
+}
+
+func (mc *Collector) collectFilesystemMetrics(ch chan<- prometheus.Metric) {
+	for _, data := range mc.filesystems {
+		fs := data.Filesystem
+		// mounted filesystems have no user label, the mount point identifies them in dashboards
+		labels := []string{fs.HostID, fs.MountPoint, fs.MountPoint, fs.SourceDevice, fs.FilesystemType}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_filesystem_size_bytes", "Filesystem size in bytes",
+				filesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.TotalBytes), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_filesystem_used_bytes", "Filesystem used bytes",
+				filesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.UsedBytes), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_filesystem_available_bytes", "Filesystem bytes available to unprivileged users",
+				filesystemLabelNames, nil),
+			prometheus.GaugeValue, float64(fs.AvailableBytes), labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("scrutiny_filesystem_used_percent", "Filesystem used percent",
+				filesystemLabelNames, nil),
+			prometheus.GaugeValue, fs.UsedPercent, labels...,
+		)
+
+		// filesystems without a fixed inode table, e.g. Btrfs, report no inodes
+		if fs.InodesTotal > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_filesystem_inodes_total", "Filesystem inodes",
+					filesystemLabelNames, nil),
+				prometheus.GaugeValue, float64(fs.InodesTotal), labels...,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_filesystem_inodes_used", "Filesystem inodes in use",
+					filesystemLabelNames, nil),
+				prometheus.GaugeValue, float64(fs.InodesUsed), labels...,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_filesystem_inodes_free", "Filesystem free inodes",
+					filesystemLabelNames, nil),
+				prometheus.GaugeValue, float64(fs.InodesFree), labels...,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc("scrutiny_filesystem_inodes_used_percent", "Filesystem inodes used percent",
+					filesystemLabelNames, nil),
+				prometheus.GaugeValue, float64(fs.InodesUsed)/float64(fs.InodesTotal)*100, labels...,
+			)
+		}
+	}
+}
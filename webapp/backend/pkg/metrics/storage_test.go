@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	metricsModels "github.com/analogj/scrutiny/webapp/backend/pkg/models/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectorIncludesMdadmBtrfsAndFilesystemMetrics(t *testing.T) {
+	collector := NewCollector(logrus.New().WithField("test", "collector"))
+	collector.zfsPools["pool-guid"] = &metricsModels.ZFSPoolMetricsData{
+		Pool: models.ZFSPool{GUID: "pool-guid", Name: "tank", HostID: "host-a", Label: "media", Status: models.ZFSPoolStatusOnline},
+	}
+	collector.mdadmArrays["md-uuid"] = &metricsModels.MdadmArrayMetricsData{
+		Array: models.MDADMArray{UUID: "md-uuid", Name: "md0", Level: "raid1", HostID: "host-a", Label: "boot"},
+		Latest: &measurements.MDADMMetrics{
+			State:          "clean, degraded, recovering",
+			ActiveDevices:  1,
+			WorkingDevices: 2,
+			FailedDevices:  0,
+			SpareDevices:   1,
+			SyncProgress:   42.5,
+			MismatchCount:  128,
+			ArraySize:      4000,
+		},
+	}
+	collector.mdadmArrays["md-new"] = &metricsModels.MdadmArrayMetricsData{
+		Array: models.MDADMArray{UUID: "md-new", Name: "md1", Level: "raid5", HostID: "host-b"},
+	}
+	scrubFinishedAt := time.Now().Add(-2 * time.Hour)
+	collector.btrfsFilesystems["btrfs-uuid"] = &metricsModels.BtrfsFilesystemMetricsData{
+		Filesystem: models.BtrfsFilesystem{
+			UUID:            "btrfs-uuid",
+			HostID:          "host-a",
+			Label:           "data",
+			MountPoint:      "/mnt/data",
+			DeviceSize:      2000,
+			Used:            500,
+			Status:          models.BtrfsFilesystemStatusDegraded,
+			ScrubState:      models.BtrfsScrubStateFinished,
+			ScrubReadErrors: 1,
+			ScrubCsumErrors: 2,
+			ScrubFinishedAt: &scrubFinishedAt,
+			Devices: []models.BtrfsDevice{
+				{Path: "/dev/sdb", DeviceID: 1, WriteIOErrors: 3, CorruptionErrors: 4},
+				{Path: "/dev/sdc", DeviceID: 2, Missing: true},
+			},
+		},
+	}
+	collector.filesystems["host-a:/"] = &metricsModels.FilesystemMetricsData{
+		Filesystem: models.FilesystemCapacity{
+			HostID:         "host-a",
+			MountPoint:     "/",
+			SourceDevice:   "/dev/sda1",
+			FilesystemType: "ext4",
+			TotalBytes:     1000,
+			UsedBytes:      750,
+			AvailableBytes: 250,
+			UsedPercent:    75,
+			InodesTotal:    100,
+			InodesUsed:     60,
+			InodesFree:     40,
+		},
+	}
+	collector.filesystems["host-a:/mnt/data"] = &metricsModels.FilesystemMetricsData{
+		Filesystem: models.FilesystemCapacity{HostID: "host-a", MountPoint: "/mnt/data", FilesystemType: "btrfs", TotalBytes: 2000},
+	}
+
+	families := gatherMetricFamilies(t, collector)
+
+	// every storage layer can be selected by host_id and label
+	assertMetricValue(t, families, "scrutiny_zfs_pool_status", 1, map[string]string{"host_id": "host-a", "label": "media", "status": "ONLINE"})
+	assertMetricValue(t, families, "scrutiny_mdadm_array_failed_devices", 0, map[string]string{"host_id": "host-a", "label": "boot"})
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_used_bytes", 500, map[string]string{"host_id": "host-a", "label": "data"})
+	assertMetricValue(t, families, "scrutiny_filesystem_used_bytes", 750, map[string]string{"host_id": "host-a", "label": "/"})
+
+	mdLabels := map[string]string{"uuid": "md-uuid", "array_name": "md0", "host_id": "host-a", "label": "boot"}
+	assertMetricValue(t, families, "scrutiny_mdadm_array_info", 1, map[string]string{"uuid": "md-uuid", "level": "raid1", "state": "clean, degraded, recovering"})
+	assertMetricValue(t, families, "scrutiny_mdadm_array_degraded", 1, mdLabels)
+	assertMetricValue(t, families, "scrutiny_mdadm_array_active_devices", 1, mdLabels)
+	assertMetricValue(t, families, "scrutiny_mdadm_array_spare_devices", 1, mdLabels)
+	assertMetricValue(t, families, "scrutiny_mdadm_array_sync_progress_percent", 42.5, mdLabels)
+	assertMetricValue(t, families, "scrutiny_mdadm_array_mismatch_count", 128, mdLabels)
+	assertMetricValue(t, families, "scrutiny_mdadm_array_size_bytes", 4000, mdLabels)
+	assert.Nil(t, findMetric(families["scrutiny_mdadm_array_used_bytes"], mdLabels), "used bytes are only exported for mounted arrays")
+	// arrays without uploaded metrics only export their info
+	assertMetricValue(t, families, "scrutiny_mdadm_array_info", 1, map[string]string{"uuid": "md-new", "state": ""})
+	assert.Nil(t, findMetric(families["scrutiny_mdadm_array_degraded"], map[string]string{"uuid": "md-new"}))
+
+	btrfsLabels := map[string]string{"uuid": "btrfs-uuid", "host_id": "host-a", "label": "data", "mount_point": "/mnt/data"}
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_used_percent", 25, btrfsLabels)
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_scrub_errors_csum_total", 2, btrfsLabels)
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_scrub_errors_total", 3, btrfsLabels)
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_status_code", 2, btrfsLabels)
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_status", 1, map[string]string{"uuid": "btrfs-uuid", "status": "DEGRADED"})
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_scrub_state", 1, map[string]string{"uuid": "btrfs-uuid", "scrub_state": "finished"})
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystem_scrub_state", 0, map[string]string{"uuid": "btrfs-uuid", "scrub_state": "unknown"})
+	scrubAge := findMetric(families["scrutiny_btrfs_filesystem_scrub_age_seconds"], btrfsLabels)
+	require.NotNil(t, scrubAge)
+	assert.InDelta(t, 7200, scrubAge.GetGauge().GetValue(), 60)
+	assertMetricValue(t, families, "scrutiny_btrfs_device_errors_write_total", 3, map[string]string{"uuid": "btrfs-uuid", "devid": "1", "device_path": "/dev/sdb"})
+	assertMetricValue(t, families, "scrutiny_btrfs_device_errors_corruption_total", 4, map[string]string{"devid": "1", "label": "data"})
+	assertMetricValue(t, families, "scrutiny_btrfs_device_missing", 1, map[string]string{"devid": "2", "device_path": "/dev/sdc"})
+
+	rootLabels := map[string]string{"host_id": "host-a", "mount_point": "/", "source_device": "/dev/sda1", "filesystem_type": "ext4"}
+	assertMetricValue(t, families, "scrutiny_filesystem_used_percent", 75, rootLabels)
+	assertMetricValue(t, families, "scrutiny_filesystem_inodes_total", 100, rootLabels)
+	assertMetricValue(t, families, "scrutiny_filesystem_inodes_used_percent", 60, rootLabels)
+	assert.Nil(t, findMetric(families["scrutiny_filesystem_inodes_total"], map[string]string{"mount_point": "/mnt/data"}),
+		"inode metrics are omitted for filesystems without inode counts")
+
+	assertMetricValue(t, families, "scrutiny_mdadm_arrays_total", 2, nil)
+	assertMetricValue(t, families, "scrutiny_btrfs_filesystems_total", 1, nil)
+	assertMetricValue(t, families, "scrutiny_filesystems_total", 2, nil)
+}
+
+func TestCollectorRefreshesStorageMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_database.NewMockDeviceRepo(ctrl)
+	ctx := context.Background()
+
+	repo.EXPECT().GetMdadmArraysSummary(ctx).Return(map[string]*models.MDADMArray{
+		"md-uuid": {UUID: "md-uuid", Name: "md0", HostID: "host-a"},
+	}, nil)
+	repo.EXPECT().GetLatestMdadmMetrics(ctx, "md-uuid").Return(&measurements.MDADMMetrics{MismatchCount: 8}, nil)
+	repo.EXPECT().GetBtrfsFilesystemsSummary(ctx).Return(map[string]*models.BtrfsFilesystem{
+		"btrfs-uuid": {UUID: "btrfs-uuid"},
+	}, nil)
+	repo.EXPECT().GetBtrfsFilesystemDetails(ctx, "btrfs-uuid").Return(models.BtrfsFilesystem{
+		UUID:    "btrfs-uuid",
+		Devices: []models.BtrfsDevice{{Path: "/dev/sdb", DeviceID: 1}},
+	}, nil)
+	repo.EXPECT().GetFilesystemSummary(ctx).Return(map[string][]models.FilesystemCapacity{
+		"host-a": {{HostID: "host-a", MountPoint: "/"}, {HostID: "host-a", MountPoint: "/home"}},
+	}, nil, nil)
+
+	collector := NewCollector(logrus.New().WithField("test", "collector"))
+	require.NoError(t, collector.RefreshMdadmMetrics(repo, ctx))
+	require.NoError(t, collector.RefreshBtrfsMetrics(repo, ctx))
+	require.NoError(t, collector.RefreshFilesystemMetrics(repo, ctx))
+
+	require.Contains(t, collector.mdadmArrays, "md-uuid")
+	assert.Equal(t, int64(8), collector.mdadmArrays["md-uuid"].Latest.MismatchCount)
+	require.Contains(t, collector.btrfsFilesystems, "btrfs-uuid")
+	assert.Len(t, collector.btrfsFilesystems["btrfs-uuid"].Filesystem.Devices, 1)
+	assert.Len(t, collector.filesystems, 2)
+
+	// an archived array is removed when its metrics are refreshed
+	repo.EXPECT().GetMdadmArrayDetails(ctx, "md-uuid").Return(models.MDADMArray{UUID: "md-uuid", Archived: true}, nil)
+	repo.EXPECT().GetLatestMdadmMetrics(ctx, "md-uuid").Return(nil, nil)
+	require.NoError(t, collector.RefreshMdadmArrayMetrics(repo, ctx, "md-uuid"))
+	assert.NotContains(t, collector.mdadmArrays, "md-uuid")
+}
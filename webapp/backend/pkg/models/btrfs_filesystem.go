@@ -30,6 +30,7 @@ type BtrfsFilesystem struct {
 	UUID               string                `json:"uuid" gorm:"primaryKey"`
 	HostID             string                `json:"host_id"`
 	Label              string                `json:"label,omitempty"`
+	Tags               []string              `json:"tags,omitempty" gorm:"-"`
 	MountPoint         string                `json:"mount_point,omitempty"`
 	DataProfile        string                `json:"data_profile,omitempty"`
 	MetadataProfile    string                `json:"metadata_profile,omitempty"`
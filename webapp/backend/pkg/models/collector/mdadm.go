@@ -25,4 +25,6 @@ type MDADMMetrics struct {
 	ArraySize int64 `json:"array_size,omitempty"`
 	// UsedBytes is the filesystem-level used space from statfs (0 if not mounted)
 	UsedBytes int64 `json:"used_bytes,omitempty"`
+	// MismatchCount is the sector count of the last check or repair from /sys/block/<name>/md/mismatch_cnt
+	MismatchCount int64 `json:"mismatch_count,omitempty"`
 }
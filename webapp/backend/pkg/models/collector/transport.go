@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// transportTopicRoot is the topic below which collectors using the MQTT transport (api.transport: mqtt)
+// publish their API requests and receive the responses, one subtree per collector client ID.
+const transportTopicRoot = "scrutiny/collector"
+
+// TransportRequestTopicFilter matches the request topics of all collectors.
+const TransportRequestTopicFilter = transportTopicRoot + "/+/request"
+
+// TransportRequestTopic is the topic a collector publishes its API requests to.
+func TransportRequestTopic(clientID string) string {
+	return fmt.Sprintf("%s/%s/request", transportTopicRoot, clientID)
+}
+
+// TransportResponseTopic is the topic the server publishes the responses to a collector's requests to.
+func TransportResponseTopic(clientID string) string {
+	return fmt.Sprintf("%s/%s/response", transportTopicRoot, clientID)
+}
+
+// TransportClientID returns the client ID from a request topic.
+func TransportClientID(topic string) (string, error) {
+	clientID, ok := strings.CutPrefix(topic, transportTopicRoot+"/")
+	if ok {
+		clientID, ok = strings.CutSuffix(clientID, "/request")
+	}
+	if !ok || clientID == "" || strings.Contains(clientID, "/") {
+		return "", fmt.Errorf("%s is not a collector request topic", topic)
+	}
+	return clientID, nil
+}
+
+// ValidateTransportClientID checks that a client ID can be used as a topic level.
+func ValidateTransportClientID(clientID string) error {
+	if clientID == "" || strings.ContainsAny(clientID, "/+#") {
+		return fmt.Errorf("invalid MQTT client ID %q: must be non-empty and must not contain '/', '+' or '#'", clientID)
+	}
+	return nil
+}
+
+// TransportRequest is an API request a collector sends over MQTT instead of HTTP.
+type TransportRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"` // relative to the API root, e.g. api/devices/register
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// TransportResponse is the server's response to a TransportRequest, matched to it by ID.
+type TransportResponse struct {
+	ID     string          `json:"id"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
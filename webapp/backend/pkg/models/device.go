@@ -34,6 +34,7 @@ type Device struct {
 	CollectorVersion          string              `json:"collector_version"`
 	HostId                    string              `json:"host_id"`
 	Label                     string              `json:"label"`
+	Tags                      []string            `json:"tags,omitempty" gorm:"-"`
 	MaxTBW                    *float64            `json:"max_tbw,omitempty" gorm:"-"`
 	FormFactor                string              `json:"form_factor"`
 	SmartSupport              common.SmartSupport `json:"smart_support"`
@@ -46,6 +47,10 @@ type Device struct {
 	Archived                  bool                `json:"archived"`
 	DeviceStatus              pkg.DeviceStatus    `json:"device_status"`
 	HasForcedFailure          bool                `json:"has_forced_failure" gorm:"default:false"`
+	WarrantyNotifiedAt        *time.Time          `json:"-"` // set once the warranty expiry notification was sent for the current expiry date
+	WarrantyExpiredNotifiedAt *time.Time          `json:"-"` // set once the warranty expired notification was sent for the current expiry date
+
+	DeviceInventory
 }
 
 func (dv *Device) IsAta() bool {
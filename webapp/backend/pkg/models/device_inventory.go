@@ -0,0 +1,71 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// DeviceInventory holds user-maintained asset information for a device. It is embedded in Device,
+// so the fields are stored as columns of the devices table and flattened into the device JSON.
+type DeviceInventory struct {
+	PurchaseDate      *time.Time `json:"purchase_date,omitempty"`
+	WarrantyExpiresAt *time.Time `json:"warranty_expires_at,omitempty"`
+	PurchasePrice     *float64   `json:"purchase_price,omitempty"`
+	Vendor            string     `json:"vendor"`
+	RMAContact        string     `json:"rma_contact"`
+	Location          string     `json:"location"`
+}
+
+// InventoryColumns lists the devices table columns backing DeviceInventory.
+var InventoryColumns = []string{"purchase_date", "warranty_expires_at", "purchase_price", "vendor", "rma_contact", "location"}
+
+type WarrantyState string
+
+const (
+	WarrantyStateUnknown  WarrantyState = "unknown"
+	WarrantyStateActive   WarrantyState = "active"
+	WarrantyStateExpiring WarrantyState = "expiring"
+	WarrantyStateExpired  WarrantyState = "expired"
+)
+
+// WarrantyStatus summarizes whether a device is still covered by its warranty.
+type WarrantyStatus struct {
+	ExpiresAt     *time.Time    `json:"expires_at,omitempty"`
+	State         WarrantyState `json:"state"`
+	Vendor        string        `json:"vendor,omitempty"`
+	RMAContact    string        `json:"rma_contact,omitempty"`
+	DaysRemaining int           `json:"days_remaining"`
+}
+
+// WarrantyStatus returns the warranty state at now. Warranties ending within expiringDays days are
+// reported as expiring; expiringDays <= 0 disables the expiring state.
+func (inv *DeviceInventory) WarrantyStatus(now time.Time, expiringDays int) WarrantyStatus {
+	status := WarrantyStatus{
+		State:      WarrantyStateUnknown,
+		Vendor:     inv.Vendor,
+		RMAContact: inv.RMAContact,
+	}
+	if inv.WarrantyExpiresAt == nil {
+		return status
+	}
+
+	status.ExpiresAt = inv.WarrantyExpiresAt
+	status.DaysRemaining = WarrantyDaysRemaining(*inv.WarrantyExpiresAt, now)
+	switch {
+	case status.DaysRemaining < 0:
+		status.State = WarrantyStateExpired
+	case status.DaysRemaining <= expiringDays:
+		status.State = WarrantyStateExpiring
+	default:
+		status.State = WarrantyStateActive
+	}
+	return status
+}
+
+// WarrantyDaysRemaining returns the number of whole days between now and expiresAt. The warranty
+// covers the whole expiry day, so it is 0 on that day and negative afterwards.
+func WarrantyDaysRemaining(expiresAt time.Time, now time.Time) int {
+	expiry := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(expiry.Sub(today).Hours() / 24))
+}
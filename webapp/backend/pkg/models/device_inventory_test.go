@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceInventory_WarrantyStatus(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	cases := []struct {
+		name      string
+		expiresAt *time.Time
+		state     WarrantyState
+		days      int
+	}{
+		{"no expiry date", nil, WarrantyStateUnknown, 0},
+		{"active", date(2027, 10, 18), WarrantyStateActive, 365},
+		{"expiring", date(2026, 11, 1), WarrantyStateExpiring, 14},
+		{"expires today", date(2026, 10, 18), WarrantyStateExpiring, 0},
+		{"expired", date(2026, 10, 17), WarrantyStateExpired, -1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			inventory := DeviceInventory{WarrantyExpiresAt: tc.expiresAt, Vendor: "Acme"}
+			status := inventory.WarrantyStatus(now, 30)
+			require.Equal(t, tc.state, status.State)
+			require.Equal(t, tc.days, status.DaysRemaining)
+			require.Equal(t, "Acme", status.Vendor)
+		})
+	}
+}
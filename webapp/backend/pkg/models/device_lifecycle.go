@@ -0,0 +1,233 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// Device lifecycle event types, detected server-side when a host registers its devices.
+const (
+	// LifecycleEventNewDevice is a device Scrutiny has never seen before.
+	LifecycleEventNewDevice = "new_device"
+	// LifecycleEventDeviceDisappeared is a device missing from its host's registration while the
+	// host keeps checking in (unlike a missed ping, where the whole host goes quiet).
+	LifecycleEventDeviceDisappeared = "device_disappeared"
+	// LifecycleEventDeviceReappeared is a disappeared device that is registered again.
+	LifecycleEventDeviceReappeared = "device_reappeared"
+	// LifecycleEventDeviceSwapped is a new device at the slot or device path of a disappeared one.
+	LifecycleEventDeviceSwapped = "device_swapped"
+)
+
+// DeviceLifecycleEvent records a device appearing, disappearing or being swapped on a host.
+type DeviceLifecycleEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	Type         string `json:"type" gorm:"index"`
+	HostID       string `json:"host_id" gorm:"index"`
+	DeviceID     string `json:"device_id" gorm:"index"`
+	DeviceName   string `json:"device_name"`
+	ModelName    string `json:"model_name"`
+	SerialNumber string `json:"serial_number"`
+
+	// Swaps only: the device that used to occupy the slot, the slot (its inventory location, or
+	// the device path when it has none) and the pools it was a member of.
+	PreviousDeviceID     string           `json:"previous_device_id,omitempty" gorm:"index"`
+	PreviousModelName    string           `json:"previous_model_name,omitempty"`
+	PreviousSerialNumber string           `json:"previous_serial_number,omitempty"`
+	Slot                 string           `json:"slot,omitempty"`
+	PoolMemberships      []PoolMembership `json:"pool_memberships,omitempty" gorm:"type:text;serializer:json"`
+}
+
+// DeviceLifecycleFilter narrows a lifecycle event lookup. Empty fields match all.
+type DeviceLifecycleFilter struct {
+	HostID   string
+	DeviceID string
+	Type     string
+	Since    *time.Time
+	Limit    int
+}
+
+// ValidLifecycleEventType reports whether t is one of the known lifecycle event types.
+func ValidLifecycleEventType(t string) bool {
+	switch t {
+	case LifecycleEventNewDevice, LifecycleEventDeviceDisappeared, LifecycleEventDeviceReappeared, LifecycleEventDeviceSwapped:
+		return true
+	}
+	return false
+}
+
+// DeviceLifecycleStates returns the lifecycle state of every device in events (oldest first):
+// the type of the latest event about it. The device a swap replaced is in the disappeared state.
+func DeviceLifecycleStates(events []DeviceLifecycleEvent) map[string]string {
+	states := map[string]string{}
+	for _, event := range events {
+		states[event.DeviceID] = event.Type
+		if event.Type == LifecycleEventDeviceSwapped && event.PreviousDeviceID != "" {
+			states[event.PreviousDeviceID] = LifecycleEventDeviceDisappeared
+		}
+	}
+	return states
+}
+
+// DetectDeviceLifecycleEvents compares the devices a host registered with every known device and
+// the lifecycle states of the host's devices (see DeviceLifecycleStates), and returns the events
+// to record. A host without any known device is being set up, so its first registration only sets
+// the baseline. Archived devices are retired and never reported as disappeared.
+//
+// memberships looks up the pools a device is a member of; it is only called for the devices a
+// swap could involve, and may be nil when pool membership is unknown.
+func DetectDeviceLifecycleEvents(hostID string, known []Device, states map[string]string, registered []Device, memberships func(Device) []PoolMembership, now time.Time) []DeviceLifecycleEvent {
+	hostDevices := []Device{}
+	for _, device := range known {
+		if device.HostId == hostID {
+			hostDevices = append(hostDevices, device)
+		}
+	}
+	if len(hostDevices) == 0 {
+		return nil
+	}
+
+	registeredIDs := map[string]bool{}
+	newDevices := []Device{}
+	events := []DeviceLifecycleEvent{}
+	for _, device := range registered {
+		registeredIDs[device.DeviceID] = true
+		match := knownDevice(device, known)
+		if match == nil {
+			newDevices = append(newDevices, device)
+			continue
+		}
+		registeredIDs[match.DeviceID] = true
+		if states[device.DeviceID] == LifecycleEventDeviceDisappeared || states[match.DeviceID] == LifecycleEventDeviceDisappeared {
+			events = append(events, newLifecycleEvent(LifecycleEventDeviceReappeared, device, now))
+		}
+	}
+
+	// devices missing from this registration, whether they just went missing or were reported before
+	missing := []Device{}
+	for _, device := range hostDevices {
+		if device.Archived || registeredIDs[device.DeviceID] {
+			continue
+		}
+		missing = append(missing, device)
+	}
+
+	if memberships == nil {
+		memberships = func(Device) []PoolMembership { return nil }
+	}
+	swapped := map[string]bool{}
+	for _, device := range newDevices {
+		previous := swappedDevice(device, missing, swapped, memberships)
+		if previous == nil {
+			events = append(events, newLifecycleEvent(LifecycleEventNewDevice, device, now))
+			continue
+		}
+		swapped[previous.DeviceID] = true
+		event := newLifecycleEvent(LifecycleEventDeviceSwapped, device, now)
+		event.PreviousDeviceID = previous.DeviceID
+		event.PreviousModelName = previous.ModelName
+		event.PreviousSerialNumber = previous.SerialNumber
+		event.Slot = previous.Location
+		if event.Slot == "" {
+			event.Slot = previous.DeviceName
+		}
+		event.PoolMemberships = memberships(*previous)
+		events = append(events, event)
+	}
+
+	for _, device := range missing {
+		if swapped[device.DeviceID] || states[device.DeviceID] == LifecycleEventDeviceDisappeared {
+			continue
+		}
+		events = append(events, newLifecycleEvent(LifecycleEventDeviceDisappeared, device, now))
+	}
+	return events
+}
+
+func newLifecycleEvent(eventType string, device Device, now time.Time) DeviceLifecycleEvent {
+	return DeviceLifecycleEvent{
+		CreatedAt:    now,
+		Type:         eventType,
+		HostID:       device.HostId,
+		DeviceID:     device.DeviceID,
+		DeviceName:   device.DeviceName,
+		ModelName:    device.ModelName,
+		SerialNumber: device.SerialNumber,
+	}
+}
+
+// knownDevice returns the known device a registered device matches, by ID or by the identity
+// the repository reconciles legacy IDs with (WWN, or model and serial), or nil for a new device.
+func knownDevice(device Device, known []Device) *Device {
+	for i := range known {
+		if known[i].DeviceID == device.DeviceID {
+			return &known[i]
+		}
+	}
+	for i := range known {
+		if wwn := strings.TrimSpace(device.WWN); wwn != "" && strings.Trim(wwn, "0x") != "" && wwn == known[i].WWN {
+			return &known[i]
+		}
+		if device.SerialNumber != "" && device.SerialNumber == known[i].SerialNumber && device.ModelName == known[i].ModelName {
+			return &known[i]
+		}
+	}
+	return nil
+}
+
+// swappedDevice returns the missing device whose place the new device took. The enclosure slot
+// (the inventory location) is the strongest evidence, then membership of the same ZFS pool, mdadm
+// array or Btrfs filesystem, and only then the same device name, which is the slot when collectors
+// address disks by /dev/disk/by-path but is reassigned freely for /dev/sdX names.
+func swappedDevice(device Device, missing []Device, taken map[string]bool, memberships func(Device) []PoolMembership) *Device {
+	candidates := []*Device{}
+	for i := range missing {
+		if !taken[missing[i].DeviceID] {
+			candidates = append(candidates, &missing[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if slot := strings.TrimSpace(device.Location); slot != "" {
+		for _, candidate := range candidates {
+			if strings.EqualFold(strings.TrimSpace(candidate.Location), slot) {
+				return candidate
+			}
+		}
+	}
+
+	if pools := memberships(device); len(pools) > 0 {
+		for _, candidate := range candidates {
+			if sharesPool(pools, memberships(*candidate)) {
+				return candidate
+			}
+		}
+	}
+
+	name := path.Base(strings.TrimSpace(device.DeviceName))
+	if name == "" || name == "." {
+		return nil
+	}
+	for _, candidate := range candidates {
+		if path.Base(strings.TrimSpace(candidate.DeviceName)) == name {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// sharesPool reports whether two sets of pool memberships have a pool in common.
+func sharesPool(a []PoolMembership, b []PoolMembership) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.Type == y.Type && x.ID != "" && x.ID == y.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func lifecycleTestDevice(id, name, serial string) models.Device {
+	return models.Device{DeviceID: id, HostId: "nas", DeviceName: name, ModelName: "WDC WD40EFRX", SerialNumber: serial}
+}
+
+func TestDetectDeviceLifecycleEvents_FirstRegistrationIsBaseline(t *testing.T) {
+	registered := []models.Device{lifecycleTestDevice("a", "sda", "S1")}
+
+	events := models.DetectDeviceLifecycleEvents("nas", nil, nil, registered, nil, time.Now())
+
+	require.Empty(t, events)
+}
+
+func TestDetectDeviceLifecycleEvents_NewAndDisappeared(t *testing.T) {
+	known := []models.Device{lifecycleTestDevice("a", "sda", "S1"), lifecycleTestDevice("b", "sdb", "S2")}
+	registered := []models.Device{lifecycleTestDevice("a", "sda", "S1"), lifecycleTestDevice("c", "sdc", "S3")}
+
+	events := models.DetectDeviceLifecycleEvents("nas", known, nil, registered, nil, time.Now())
+
+	require.Len(t, events, 2)
+	require.Equal(t, models.LifecycleEventNewDevice, events[0].Type)
+	require.Equal(t, "c", events[0].DeviceID)
+	require.Equal(t, models.LifecycleEventDeviceDisappeared, events[1].Type)
+	require.Equal(t, "b", events[1].DeviceID)
+
+	// the disappeared device is only reported once
+	known = append(known, registered[1])
+	states := models.DeviceLifecycleStates(events)
+	require.Empty(t, models.DetectDeviceLifecycleEvents("nas", known, states, registered, nil, time.Now()))
+}
+
+func TestDetectDeviceLifecycleEvents_Reappeared(t *testing.T) {
+	known := []models.Device{lifecycleTestDevice("a", "sda", "S1"), lifecycleTestDevice("b", "sdb", "S2")}
+	states := map[string]string{"b": models.LifecycleEventDeviceDisappeared}
+
+	events := models.DetectDeviceLifecycleEvents("nas", known, states, known, nil, time.Now())
+
+	require.Len(t, events, 1)
+	require.Equal(t, models.LifecycleEventDeviceReappeared, events[0].Type)
+	require.Equal(t, "b", events[0].DeviceID)
+}
+
+func TestDetectDeviceLifecycleEvents_IgnoresArchivedAndKnownIdentities(t *testing.T) {
+	archived := lifecycleTestDevice("b", "sdb", "S2")
+	archived.Archived = true
+	known := []models.Device{lifecycleTestDevice("a", "sda", "S1"), archived}
+	// same disk re-registered under a new ID (e.g. a collector upgrade) is not new
+	registered := []models.Device{lifecycleTestDevice("a2", "sda", "S1")}
+
+	events := models.DetectDeviceLifecycleEvents("nas", known, nil, registered, nil, time.Now())
+
+	require.Empty(t, events)
+}
+
+func TestDetectDeviceLifecycleEvents_Swapped(t *testing.T) {
+	old := lifecycleTestDevice("b", "/dev/disk/by-path/pci-0000:00:17.0-ata-2", "S2")
+	old.Location = "Bay 2"
+	known := []models.Device{lifecycleTestDevice("a", "sda", "S1"), old}
+	replacement := lifecycleTestDevice("c", "/dev/disk/by-path/pci-0000:00:17.0-ata-2", "S9")
+	replacement.ModelName = "ST8000VN004"
+	registered := []models.Device{lifecycleTestDevice("a", "sda", "S1"), replacement}
+
+	events := models.DetectDeviceLifecycleEvents("nas", known, nil, registered, nil, time.Now())
+
+	require.Len(t, events, 1)
+	event := events[0]
+	require.Equal(t, models.LifecycleEventDeviceSwapped, event.Type)
+	require.Equal(t, "c", event.DeviceID)
+	require.Equal(t, "b", event.PreviousDeviceID)
+	require.Equal(t, "WDC WD40EFRX", event.PreviousModelName)
+	require.Equal(t, "S2", event.PreviousSerialNumber)
+	require.Equal(t, "Bay 2", event.Slot)
+
+	states := models.DeviceLifecycleStates(events)
+	require.Equal(t, models.LifecycleEventDeviceDisappeared, states["b"])
+	require.Equal(t, models.LifecycleEventDeviceSwapped, states["c"])
+}
+
+func TestDetectDeviceLifecycleEvents_SwapMatchesSlotAndPoolBeforeName(t *testing.T) {
+	bay1 := lifecycleTestDevice("b", "sdb", "S2")
+	bay1.Location = "Bay 1"
+	pooled := lifecycleTestDevice("c", "sdc", "S3")
+	known := []models.Device{lifecycleTestDevice("a", "sda", "S1"), bay1, pooled}
+
+	// the kernel handed the names out in a different order after the disks were replaced
+	inBay1 := lifecycleTestDevice("d", "sdc", "S4")
+	inBay1.Location = "bay 1"
+	inPool := lifecycleTestDevice("e", "sdb", "S5")
+	registered := []models.Device{lifecycleTestDevice("a", "sda", "S1"), inBay1, inPool}
+
+	memberships := func(device models.Device) []models.PoolMembership {
+		if device.DeviceID == "c" || device.DeviceID == "e" {
+			return []models.PoolMembership{{Type: models.PoolTypeZFS, ID: "tank-guid", Name: "tank", Member: device.DeviceName}}
+		}
+		return nil
+	}
+	events := models.DetectDeviceLifecycleEvents("nas", known, nil, registered, memberships, time.Now())
+
+	require.Len(t, events, 2)
+	require.Equal(t, models.LifecycleEventDeviceSwapped, events[0].Type)
+	require.Equal(t, "d", events[0].DeviceID)
+	require.Equal(t, "b", events[0].PreviousDeviceID, "the enclosure slot wins over the device name")
+	require.Empty(t, events[0].PoolMemberships)
+	require.Equal(t, models.LifecycleEventDeviceSwapped, events[1].Type)
+	require.Equal(t, "e", events[1].DeviceID)
+	require.Equal(t, "c", events[1].PreviousDeviceID, "the pool wins over the device name")
+	require.Equal(t, "tank", events[1].PoolMemberships[0].Name)
+}
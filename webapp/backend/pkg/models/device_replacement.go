@@ -0,0 +1,166 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReplacementReason records why a drive was swapped out.
+type ReplacementReason string
+
+const (
+	ReplacementReasonFailed   ReplacementReason = "failed"
+	ReplacementReasonUpgraded ReplacementReason = "upgraded"
+	ReplacementReasonRMA      ReplacementReason = "rma"
+)
+
+// Valid reports whether r is one of the known replacement reasons.
+func (r ReplacementReason) Valid() bool {
+	switch r {
+	case ReplacementReasonFailed, ReplacementReasonUpgraded, ReplacementReasonRMA:
+		return true
+	}
+	return false
+}
+
+// Pool membership types, matching the storage pools Scrutiny tracks.
+const (
+	PoolTypeZFS   = "zfs"
+	PoolTypeMdadm = "mdadm"
+	PoolTypeBtrfs = "btrfs"
+)
+
+// PoolMembership identifies the pool (ZFS pool, mdadm array or Btrfs filesystem) a device was a
+// member of, and the member path it occupied.
+type PoolMembership struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Member string `json:"member,omitempty"`
+}
+
+// DeviceReplacement records that one device was succeeded by another, unlike MergeDevices which
+// folds a duplicate identity into its canonical device. The replaced device is archived, but both
+// keep their own history so the lineage of a bay or pool member can be followed across swaps.
+//
+// The replaced device's model and serial are copied onto the record so failure statistics survive
+// the device being deleted later.
+type DeviceReplacement struct {
+	ID                   uint              `json:"id" gorm:"primaryKey"`
+	CreatedAt            time.Time         `json:"created_at"`
+	ReplacedAt           time.Time         `json:"replaced_at"`
+	OldDeviceID          string            `json:"old_device_id" gorm:"uniqueIndex;not null"`
+	NewDeviceID          string            `json:"new_device_id" gorm:"uniqueIndex;not null"`
+	Reason               ReplacementReason `json:"reason"`
+	Notes                string            `json:"notes"`
+	HostId               string            `json:"host_id"`
+	Slot                 string            `json:"slot" gorm:"index"`
+	PoolMemberships      []PoolMembership  `json:"pool_memberships" gorm:"type:text;serializer:json"`
+	ReplacedModelName    string            `json:"replaced_model_name"`
+	ReplacedSerialNumber string            `json:"replaced_serial_number"`
+}
+
+// InPool reports whether the replaced device was a member of the given pool. An empty member
+// matches any member path of the pool.
+func (r *DeviceReplacement) InPool(poolType string, poolID string, member string) bool {
+	for _, membership := range r.PoolMemberships {
+		if membership.Type != poolType || membership.ID != poolID {
+			continue
+		}
+		if member == "" || PoolMemberMatchesDevice(membership.Member, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeviceLineage is a chain of devices that succeeded each other in the same slot, with devices and
+// replacements both ordered oldest first. Devices deleted after their replacement was recorded are
+// omitted from Devices, but their replacement records are kept.
+type DeviceLineage struct {
+	Devices      []Device            `json:"devices"`
+	Replacements []DeviceReplacement `json:"replacements"`
+}
+
+// ReplacementFilter narrows a replacement lookup to a slot or pool member. Empty fields match all.
+type ReplacementFilter struct {
+	HostId     string
+	Slot       string
+	PoolType   string
+	PoolID     string
+	PoolMember string
+}
+
+// Matches reports whether the replacement satisfies every set field of the filter.
+func (f ReplacementFilter) Matches(replacement *DeviceReplacement) bool {
+	if f.HostId != "" && replacement.HostId != f.HostId {
+		return false
+	}
+	if f.Slot != "" && !strings.EqualFold(replacement.Slot, f.Slot) {
+		return false
+	}
+	if f.PoolType != "" && !replacement.InPool(f.PoolType, f.PoolID, f.PoolMember) {
+		return false
+	}
+	return true
+}
+
+// ReplacementStats counts recorded replacements for fleet failure statistics.
+type ReplacementStats struct {
+	Total         int                       `json:"total"`
+	ByReason      map[ReplacementReason]int `json:"by_reason"`
+	FailedByModel map[string]int            `json:"failed_by_model"`
+}
+
+// NewReplacementStats tallies replacements by reason, and failed/RMA'd drives by model.
+func NewReplacementStats(replacements []DeviceReplacement) ReplacementStats {
+	stats := ReplacementStats{
+		ByReason:      map[ReplacementReason]int{},
+		FailedByModel: map[string]int{},
+	}
+	for _, replacement := range replacements {
+		stats.Total++
+		stats.ByReason[replacement.Reason]++
+		if replacement.Reason == ReplacementReasonFailed || replacement.Reason == ReplacementReasonRMA {
+			model := replacement.ReplacedModelName
+			if model == "" {
+				model = "unknown"
+			}
+			stats.FailedByModel[model]++
+		}
+	}
+	return stats
+}
+
+// PoolMemberMatchesDevice reports whether a pool member path (for example /dev/sda, /dev/sda1 or
+// nvme0n1p2) refers to the block device deviceName or one of its partitions.
+func PoolMemberMatchesDevice(memberPath string, deviceName string) bool {
+	member := filepath.Base(strings.TrimSpace(memberPath))
+	name := filepath.Base(strings.TrimSpace(deviceName))
+	if member == "" || name == "" || member == "." || name == "." {
+		return false
+	}
+	if member == name {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(member, name)
+	if !ok {
+		return false
+	}
+	// devices whose name ends in a digit (nvme0n1, mmcblk0) use a "p" partition separator
+	if last := name[len(name)-1]; last >= '0' && last <= '9' {
+		if suffix, ok = strings.CutPrefix(suffix, "p"); !ok {
+			return false
+		}
+	}
+	if suffix == "" {
+		return false
+	}
+	for _, r := range suffix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
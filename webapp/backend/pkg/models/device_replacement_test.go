@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolMemberMatchesDevice(t *testing.T) {
+	cases := []struct {
+		member string
+		device string
+		match  bool
+	}{
+		{"/dev/sda", "sda", true},
+		{"/dev/sda1", "sda", true},
+		{"sda2", "/dev/sda", true},
+		{"/dev/sdaa", "sda", false},
+		{"/dev/sdb1", "sda", false},
+		{"/dev/nvme0n1p1", "nvme0n1", true},
+		{"/dev/nvme0n10", "nvme0n1", false},
+		{"/dev/disk/by-id/ata-WDC_WD40-part1", "sda", false},
+		{"", "sda", false},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.match, PoolMemberMatchesDevice(tc.member, tc.device), "%s vs %s", tc.member, tc.device)
+	}
+}
+
+func TestReplacementFilter_Matches(t *testing.T) {
+	replacement := DeviceReplacement{
+		HostId: "nas",
+		Slot:   "Bay 3",
+		PoolMemberships: []PoolMembership{
+			{Type: PoolTypeZFS, ID: "123", Name: "tank", Member: "/dev/sdc1"},
+		},
+	}
+
+	require.True(t, ReplacementFilter{}.Matches(&replacement))
+	require.True(t, ReplacementFilter{Slot: "bay 3"}.Matches(&replacement))
+	require.False(t, ReplacementFilter{Slot: "Bay 4"}.Matches(&replacement))
+	require.True(t, ReplacementFilter{HostId: "nas", PoolType: PoolTypeZFS, PoolID: "123"}.Matches(&replacement))
+	require.True(t, ReplacementFilter{PoolType: PoolTypeZFS, PoolID: "123", PoolMember: "sdc1"}.Matches(&replacement))
+	require.False(t, ReplacementFilter{PoolType: PoolTypeZFS, PoolID: "123", PoolMember: "sdd"}.Matches(&replacement))
+	require.False(t, ReplacementFilter{PoolType: PoolTypeMdadm, PoolID: "123"}.Matches(&replacement))
+	require.False(t, ReplacementFilter{HostId: "other"}.Matches(&replacement))
+}
+
+func TestNewReplacementStats(t *testing.T) {
+	stats := NewReplacementStats([]DeviceReplacement{
+		{Reason: ReplacementReasonFailed, ReplacedModelName: "WDC WD40EFRX"},
+		{Reason: ReplacementReasonRMA, ReplacedModelName: "WDC WD40EFRX"},
+		{Reason: ReplacementReasonUpgraded, ReplacedModelName: "ST4000VN008"},
+		{Reason: ReplacementReasonFailed},
+	})
+
+	require.Equal(t, 4, stats.Total)
+	require.Equal(t, map[ReplacementReason]int{ReplacementReasonFailed: 2, ReplacementReasonRMA: 1, ReplacementReasonUpgraded: 1}, stats.ByReason)
+	require.Equal(t, map[string]int{"WDC WD40EFRX": 2, "unknown": 1}, stats.FailedByModel)
+}
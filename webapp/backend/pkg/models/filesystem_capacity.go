@@ -15,6 +15,9 @@ type FilesystemCapacity struct {
 	UsedBytes      int64   `json:"used_bytes"`
 	AvailableBytes int64   `json:"available_bytes"`
 	UsedPercent    float64 `json:"used_percent"`
+	InodesTotal    int64   `json:"inodes_total"`
+	InodesUsed     int64   `json:"inodes_used"`
+	InodesFree     int64   `json:"inodes_free"`
 }
 
 type FilesystemSummaryUpload struct {
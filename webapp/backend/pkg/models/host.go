@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Collector types reported in host check-ins.
+const (
+	CollectorTypeMetrics     = "metrics"
+	CollectorTypePerformance = "performance"
+	CollectorTypeZFS         = "zfs"
+	CollectorTypeMdadm       = "mdadm"
+	CollectorTypeBtrfs       = "btrfs"
+	CollectorTypeFilesystem  = "filesystem"
+)
+
+// Host is a machine running one or more collectors, identified by the collector host.id setting.
+// Hosts are created automatically the first time a collector checks in; devices, pools and
+// filesystems reference them through their host_id column.
+type Host struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	HostID      string `json:"host_id" gorm:"primaryKey"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+
+	// Reported by the most recent collector check-in
+	CollectorVersion string `json:"collector_version"`
+	OSInfo           string `json:"os_info"`
+	Architecture     string `json:"architecture"`
+
+	// MissedPingTimeoutMinutes overrides the global missed ping timeout for devices on this host.
+	// 0 uses the global setting; a per-device override still takes precedence.
+	MissedPingTimeoutMinutes int  `json:"missed_ping_timeout_minutes" gorm:"default:0"`
+	Muted                    bool `json:"muted"`
+	Archived                 bool `json:"archived"`
+
+	CheckIns []HostCheckIn `json:"check_ins" gorm:"foreignKey:HostID;references:HostID"`
+}
+
+// HostCheckIn records the last time a collector of a given type reported for a host.
+type HostCheckIn struct {
+	HostID           string    `json:"host_id" gorm:"primaryKey"`
+	CollectorType    string    `json:"collector_type" gorm:"primaryKey"`
+	CollectorVersion string    `json:"collector_version"`
+	LastSeenAt       time.Time `json:"last_seen_at"`
+}
+
+// HostCollectorInfo is the collector metadata sent alongside a check-in (see the
+// X-Scrutiny-Collector-* request headers). Empty fields leave the stored values unchanged.
+type HostCollectorInfo struct {
+	CollectorVersion string
+	OSInfo           string
+	Architecture     string
+}
+
+// HostUpdate holds the user-editable fields of a Host.
+type HostUpdate struct {
+	Label                    string `json:"label"`
+	Description              string `json:"description"`
+	MissedPingTimeoutMinutes int    `json:"missed_ping_timeout_minutes"`
+}
+
+// LatestCheckIn returns the time of the most recent check-in of any collector type.
+func (h *Host) LatestCheckIn() *time.Time {
+	var last *time.Time
+	for i := range h.CheckIns {
+		if last == nil || h.CheckIns[i].LastSeenAt.After(*last) {
+			last = &h.CheckIns[i].LastSeenAt
+		}
+	}
+	return last
+}
+
+// HostStatusCounts tallies the state of the devices, pools or filesystems that belong to a host.
+type HostStatusCounts struct {
+	Total    int `json:"total"`
+	Passed   int `json:"passed"`
+	Failed   int `json:"failed"`
+	Archived int `json:"archived"`
+}
+
+// HostSummary is a host with the aggregated status of everything reported for it.
+type HostSummary struct {
+	Host
+	LastSeenAt       *time.Time       `json:"last_seen_at,omitempty"`
+	Devices          HostStatusCounts `json:"devices"`
+	ZFSPools         HostStatusCounts `json:"zfs_pools"`
+	MdadmArrays      HostStatusCounts `json:"mdadm_arrays"`
+	BtrfsFilesystems HostStatusCounts `json:"btrfs_filesystems"`
+	FilesystemCount  int              `json:"filesystem_count"`
+	FilesystemStatus string           `json:"filesystem_status,omitempty"`
+}
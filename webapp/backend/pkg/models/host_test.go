@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLatestCheckIn(t *testing.T) {
+	host := Host{HostID: "nas"}
+	require.Nil(t, host.LatestCheckIn())
+
+	older := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	host.CheckIns = []HostCheckIn{
+		{HostID: "nas", CollectorType: CollectorTypeZFS, LastSeenAt: older},
+		{HostID: "nas", CollectorType: CollectorTypeMetrics, LastSeenAt: newer},
+	}
+	require.Equal(t, newer, *host.LatestCheckIn())
+}
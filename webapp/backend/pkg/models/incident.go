@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Incident states. An incident is opened by the first failure notification of a device, stops
+// notifying once acknowledged and is resolved when the device returns to passed.
+const (
+	IncidentStatusOpen         = "open"
+	IncidentStatusAcknowledged = "acknowledged"
+	IncidentStatusResolved     = "resolved"
+)
+
+// Incident tracks a device failure from its first notification until the device recovers.
+// A device has at most one unresolved incident at a time.
+type Incident struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	DeviceID    string `json:"device_id" gorm:"index"`
+	HostID      string `json:"host_id,omitempty"`
+	DeviceName  string `json:"device_name"`
+	DeviceLabel string `json:"device_label,omitempty"`
+	FailureType string `json:"failure_type"`
+	Status      string `json:"status" gorm:"index"`
+	Subject     string `json:"subject"`
+	Message     string `json:"message"`
+
+	OpenedAt          time.Time  `json:"opened_at"`
+	LastNotifiedAt    *time.Time `json:"last_notified_at,omitempty"`
+	NotificationCount int        `json:"notification_count"`
+	AcknowledgedAt    *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy    string     `json:"acknowledged_by,omitempty"`
+	AckNote           string     `json:"ack_note,omitempty"`
+	EscalatedAt       *time.Time `json:"escalated_at,omitempty"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty"`
+
+	ID uint `json:"id" gorm:"primaryKey"`
+}
+
+// ValidIncidentStatus reports whether status is one of the known incident states.
+func ValidIncidentStatus(status string) bool {
+	switch status {
+	case IncidentStatusOpen, IncidentStatusAcknowledged, IncidentStatusResolved:
+		return true
+	}
+	return false
+}
+
+// EscalationDue reports whether an open incident has gone unacknowledged for longer than delay
+// and has not been escalated yet.
+func (i Incident) EscalationDue(now time.Time, delay time.Duration) bool {
+	if i.Status != IncidentStatusOpen || i.EscalatedAt != nil || delay <= 0 {
+		return false
+	}
+	return !now.Before(i.OpenedAt.Add(delay))
+}
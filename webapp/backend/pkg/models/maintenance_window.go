@@ -0,0 +1,172 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// Maintenance window scopes. Pool and array windows also cover their member devices.
+const (
+	MaintenanceScopeHost   = "host"
+	MaintenanceScopeDevice = "device"
+	MaintenanceScopePool   = "pool"
+	MaintenanceScopeArray  = "array"
+)
+
+// Maintenance window recurrences. One-off windows have no recurrence.
+const (
+	MaintenanceRecurrenceNone   = ""
+	MaintenanceRecurrenceDaily  = "daily"
+	MaintenanceRecurrenceWeekly = "weekly"
+)
+
+// MaintenanceWindow suppresses notifications and missed ping alerts for a host, device, ZFS pool
+// or mdadm array during planned work. Recurring windows repeat StartsAt..EndsAt every day or week
+// until they are ended.
+type MaintenanceWindow struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name       string     `json:"name"`
+	ScopeType  string     `json:"scope_type" gorm:"index"`
+	ScopeID    string     `json:"scope_id"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at"`
+	Recurrence string     `json:"recurrence,omitempty"`
+	EndedAt    *time.Time `json:"ended_at,omitempty" gorm:"index"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+
+	// MemberDevices are the member paths of a pool or array scope on MemberHostID, and
+	// MemberDeviceIDs the devices they resolve to. Resolved when active windows are loaded, not stored.
+	MemberDevices   []string `json:"member_devices,omitempty" gorm:"-"`
+	MemberDeviceIDs []string `json:"-" gorm:"-"`
+	MemberHostID    string   `json:"-" gorm:"-"`
+
+	ID uint `json:"id" gorm:"primaryKey"`
+}
+
+// MaintenanceTarget identifies what a notification is about, for matching against window scopes.
+type MaintenanceTarget struct {
+	HostID     string
+	DeviceID   string
+	DeviceName string
+	PoolGUID   string
+	ArrayUUID  string
+}
+
+// ValidMaintenanceScope reports whether scope is one of the known scope types.
+func ValidMaintenanceScope(scope string) bool {
+	switch scope {
+	case MaintenanceScopeHost, MaintenanceScopeDevice, MaintenanceScopePool, MaintenanceScopeArray:
+		return true
+	}
+	return false
+}
+
+// MaintenanceRecurrencePeriod returns the repeat period of a recurrence, 0 for one-off windows
+// and false for unknown recurrences.
+func MaintenanceRecurrencePeriod(recurrence string) (time.Duration, bool) {
+	switch recurrence {
+	case MaintenanceRecurrenceNone:
+		return 0, true
+	case MaintenanceRecurrenceDaily:
+		return 24 * time.Hour, true
+	case MaintenanceRecurrenceWeekly:
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// ActiveAt reports whether the window suppresses notifications at now.
+func (w MaintenanceWindow) ActiveAt(now time.Time) bool {
+	if w.EndedAt != nil && !now.Before(*w.EndedAt) {
+		return false
+	}
+	if now.Before(w.StartsAt) {
+		return false
+	}
+	period, _ := MaintenanceRecurrencePeriod(w.Recurrence)
+	if period <= 0 {
+		return now.Before(w.EndsAt)
+	}
+	return now.Sub(w.StartsAt)%period < w.EndsAt.Sub(w.StartsAt)
+}
+
+// OccurrenceStart returns the start of the occurrence active at now, or of the latest one before
+// now. It is StartsAt for one-off windows.
+func (w MaintenanceWindow) OccurrenceStart(now time.Time) time.Time {
+	period, _ := MaintenanceRecurrencePeriod(w.Recurrence)
+	if period <= 0 || now.Before(w.StartsAt) {
+		return w.StartsAt
+	}
+	return w.StartsAt.Add(now.Sub(w.StartsAt) / period * period)
+}
+
+// Covers reports whether the window's scope includes the target.
+func (w MaintenanceWindow) Covers(target MaintenanceTarget) bool {
+	if w.ScopeID == "" {
+		return false
+	}
+	switch w.ScopeType {
+	case MaintenanceScopeHost:
+		return target.HostID == w.ScopeID
+	case MaintenanceScopeDevice:
+		return target.DeviceID == w.ScopeID
+	case MaintenanceScopePool:
+		return target.PoolGUID == w.ScopeID || w.coversMember(target)
+	case MaintenanceScopeArray:
+		return target.ArrayUUID == w.ScopeID || w.coversMember(target)
+	}
+	return false
+}
+
+// coversMember reports whether the target is a member device of a pool or array window: one of the
+// devices the members resolved to, or, for members that did not resolve, a device of the same name.
+func (w MaintenanceWindow) coversMember(target MaintenanceTarget) bool {
+	if target.HostID != w.MemberHostID {
+		return false
+	}
+	for _, deviceID := range w.MemberDeviceIDs {
+		if target.DeviceID != "" && deviceID == target.DeviceID {
+			return true
+		}
+	}
+	if target.DeviceName == "" {
+		return false
+	}
+	for _, member := range w.MemberDevices {
+		if PoolMemberMatchesDevice(member, target.DeviceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceMemberMatchesDevice reports whether a pool or array member path refers to device. Besides
+// device names (/dev/sda1), it resolves /dev/disk/by-id links by WWN (wwn-0x5000c500a1b2c3d4-part1)
+// or by the serial number that ends the other by-id names (ata-WDC_WD40EFRX-68N32N0_WD-WCC7K1234567).
+func MaintenanceMemberMatchesDevice(member string, device Device) bool {
+	if PoolMemberMatchesDevice(member, device.DeviceName) {
+		return true
+	}
+	name := path.Base(strings.TrimSpace(member))
+	if i := strings.LastIndex(name, "-part"); i > 0 {
+		name = name[:i]
+	}
+	if wwn, ok := strings.CutPrefix(name, "wwn-"); ok {
+		return device.WWN != "" && strings.EqualFold(wwn, strings.TrimSpace(device.WWN))
+	}
+	serial := strings.TrimSpace(device.SerialNumber)
+	return serial != "" && strings.HasSuffix(name, "_"+serial)
+}
+
+// ActiveMaintenanceWindow returns the first window that is active at now and covers the target.
+func ActiveMaintenanceWindow(windows []MaintenanceWindow, target MaintenanceTarget, now time.Time) *MaintenanceWindow {
+	for i := range windows {
+		if windows[i].ActiveAt(now) && windows[i].Covers(target) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
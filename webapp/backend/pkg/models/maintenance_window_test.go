@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindow_ActiveAt(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	oneOff := MaintenanceWindow{StartsAt: start, EndsAt: start.Add(2 * time.Hour)}
+	require.False(t, oneOff.ActiveAt(start.Add(-time.Minute)))
+	require.True(t, oneOff.ActiveAt(start))
+	require.True(t, oneOff.ActiveAt(start.Add(119*time.Minute)))
+	require.False(t, oneOff.ActiveAt(start.Add(2*time.Hour)))
+
+	ended := start.Add(30 * time.Minute)
+	oneOff.EndedAt = &ended
+	require.False(t, oneOff.ActiveAt(start.Add(time.Hour)))
+
+	nightly := MaintenanceWindow{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Recurrence: MaintenanceRecurrenceDaily}
+	require.True(t, nightly.ActiveAt(start.Add(3*24*time.Hour+time.Hour)))
+	require.False(t, nightly.ActiveAt(start.Add(3*24*time.Hour+3*time.Hour)))
+
+	weekly := MaintenanceWindow{StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: MaintenanceRecurrenceWeekly}
+	require.True(t, weekly.ActiveAt(start.Add(14*24*time.Hour+30*time.Minute)))
+	require.False(t, weekly.ActiveAt(start.Add(24*time.Hour+30*time.Minute)))
+}
+
+func TestMaintenanceWindow_OccurrenceStart(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	oneOff := MaintenanceWindow{StartsAt: start, EndsAt: start.Add(2 * time.Hour)}
+	require.Equal(t, start, oneOff.OccurrenceStart(start.Add(time.Hour)))
+
+	nightly := MaintenanceWindow{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Recurrence: MaintenanceRecurrenceDaily}
+	require.Equal(t, start, nightly.OccurrenceStart(start.Add(-time.Hour)))
+	require.Equal(t, start.Add(3*24*time.Hour), nightly.OccurrenceStart(start.Add(3*24*time.Hour+time.Hour)))
+	require.Equal(t, start.Add(3*24*time.Hour), nightly.OccurrenceStart(start.Add(3*24*time.Hour+5*time.Hour)), "between occurrences it is the latest one")
+}
+
+func TestMaintenanceWindow_Covers(t *testing.T) {
+	host := MaintenanceWindow{ScopeType: MaintenanceScopeHost, ScopeID: "nas"}
+	require.True(t, host.Covers(MaintenanceTarget{HostID: "nas", DeviceID: "dev-1"}))
+	require.False(t, host.Covers(MaintenanceTarget{HostID: "backup"}))
+	require.False(t, MaintenanceWindow{ScopeType: MaintenanceScopeHost}.Covers(MaintenanceTarget{}), "an empty scope covers nothing")
+
+	device := MaintenanceWindow{ScopeType: MaintenanceScopeDevice, ScopeID: "dev-1"}
+	require.True(t, device.Covers(MaintenanceTarget{DeviceID: "dev-1"}))
+	require.False(t, device.Covers(MaintenanceTarget{DeviceID: "dev-2"}))
+
+	array := MaintenanceWindow{ScopeType: MaintenanceScopeArray, ScopeID: "uuid-1", MemberHostID: "nas", MemberDevices: []string{"/dev/sda", "/dev/sdb"}}
+	require.True(t, array.Covers(MaintenanceTarget{ArrayUUID: "uuid-1"}))
+	require.True(t, array.Covers(MaintenanceTarget{HostID: "nas", DeviceName: "sdb"}))
+	require.False(t, array.Covers(MaintenanceTarget{HostID: "backup", DeviceName: "sdb"}), "members are matched on the array's host only")
+	require.False(t, array.Covers(MaintenanceTarget{HostID: "nas", DeviceName: "sdc"}))
+}
+
+func TestMaintenanceWindow_CoversResolvedMembers(t *testing.T) {
+	pool := MaintenanceWindow{
+		ScopeType:       MaintenanceScopePool,
+		ScopeID:         "tank-guid",
+		MemberHostID:    "nas",
+		MemberDevices:   []string{"/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1"},
+		MemberDeviceIDs: []string{"dev-1"},
+	}
+	require.True(t, pool.Covers(MaintenanceTarget{HostID: "nas", DeviceID: "dev-1", DeviceName: "sda"}), "by-id members match by the device they resolve to")
+	require.False(t, pool.Covers(MaintenanceTarget{HostID: "nas", DeviceID: "dev-2", DeviceName: "sdb"}))
+	require.False(t, pool.Covers(MaintenanceTarget{HostID: "backup", DeviceID: "dev-1"}))
+}
+
+func TestMaintenanceMemberMatchesDevice(t *testing.T) {
+	device := Device{DeviceName: "/dev/sda", WWN: "0x5000C500A1B2C3D4", SerialNumber: "WD-WCC7K1234567"}
+
+	require.True(t, MaintenanceMemberMatchesDevice("/dev/sda1", device))
+	require.True(t, MaintenanceMemberMatchesDevice("/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1", device))
+	require.True(t, MaintenanceMemberMatchesDevice("/dev/disk/by-id/ata-WDC_WD40EFRX-68N32N0_WD-WCC7K1234567", device))
+	require.True(t, MaintenanceMemberMatchesDevice("ata-WDC_WD40EFRX-68N32N0_WD-WCC7K1234567-part2", device))
+	require.False(t, MaintenanceMemberMatchesDevice("/dev/disk/by-id/wwn-0x5000c500deadbeef", device))
+	require.False(t, MaintenanceMemberMatchesDevice("/dev/disk/by-id/ata-WDC_WD40EFRX-68N32N0_WD-WCC7K7654321", device))
+	require.False(t, MaintenanceMemberMatchesDevice("/dev/sdb1", device))
+	require.False(t, MaintenanceMemberMatchesDevice("/dev/disk/by-id/wwn-0x5000c500a1b2c3d4", Device{DeviceName: "sdb"}), "devices without a WWN only match by name or serial")
+}
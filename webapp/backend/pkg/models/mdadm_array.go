@@ -20,7 +20,8 @@ type MDADMArray struct {
 	Devices []string `json:"devices" gorm:"type:text;serializer:json"`
 
 	// User provided metadata
-	Label string `json:"label,omitempty"`
+	Label string   `json:"label,omitempty"`
+	Tags  []string `json:"tags,omitempty" gorm:"-"`
 
 	// Management flags
 	Archived bool `json:"archived"`
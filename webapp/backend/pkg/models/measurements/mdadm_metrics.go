@@ -25,6 +25,8 @@ type MDADMMetrics struct {
 	ArraySize int64 `json:"array_size"`
 	// UsedBytes is the filesystem-level used space from statfs on the mount point
 	UsedBytes int64 `json:"used_bytes"`
+	// MismatchCount is the number of sectors the last check or repair found inconsistent
+	MismatchCount int64 `json:"mismatch_count"`
 }
 
 // Flatten converts the MDADMMetrics struct to tags and fields for InfluxDB
@@ -44,6 +46,7 @@ func (m *MDADMMetrics) Flatten() (tags map[string]string, fields map[string]inte
 		"raw_mdstat":      m.RawMdstat,
 		"array_size":      m.ArraySize,
 		"used_bytes":      m.UsedBytes,
+		"mismatch_count":  m.MismatchCount,
 	}
 
 	return tags, fields
@@ -64,5 +67,6 @@ func NewMDADMMetricsFromInfluxDB(attrs map[string]interface{}) (*MDADMMetrics, e
 		RawMdstat:      influxString(attrs, "raw_mdstat"),
 		ArraySize:      influxInt64(attrs, "array_size"),
 		UsedBytes:      influxInt64(attrs, "used_bytes"),
+		MismatchCount:  influxInt64(attrs, "mismatch_count"),
 	}, nil
 }
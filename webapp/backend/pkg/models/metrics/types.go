@@ -25,3 +25,22 @@ type WorkloadMetricsData struct {
 	UpdatedAt time.Time              `json:"updated_at"`
 	Insight   models.WorkloadInsight `json:"insight"`
 }
+
+// MdadmArrayMetricsData stores metrics data for a single mdadm array.
+type MdadmArrayMetricsData struct {
+	UpdatedAt time.Time                  `json:"updated_at"`
+	Array     models.MDADMArray          `json:"array"`
+	Latest    *measurements.MDADMMetrics `json:"latest,omitempty"` // nil until the collector uploaded metrics
+}
+
+// BtrfsFilesystemMetricsData stores metrics data for a single Btrfs filesystem, including its devices.
+type BtrfsFilesystemMetricsData struct {
+	UpdatedAt  time.Time              `json:"updated_at"`
+	Filesystem models.BtrfsFilesystem `json:"filesystem"`
+}
+
+// FilesystemMetricsData stores capacity metrics data for a single mounted filesystem.
+type FilesystemMetricsData struct {
+	UpdatedAt  time.Time                 `json:"updated_at"`
+	Filesystem models.FilesystemCapacity `json:"filesystem"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Notification history outcomes.
+const (
+	NotificationOutcomeSent       = "sent"
+	NotificationOutcomePartial    = "partial"
+	NotificationOutcomeFailed     = "failed"
+	NotificationOutcomeSuppressed = "suppressed"
+	// NotificationOutcomeDeadLetter marks a notification whose retries were given up on.
+	NotificationOutcomeDeadLetter = "dead_letter"
+)
+
+// Reasons a notification was suppressed instead of sent.
+const (
+	NotificationSuppressedQuietHours  = "quiet_hours"
+	NotificationSuppressedMute        = "mute"
+	NotificationSuppressedRateLimit   = "rate_limit"
+	NotificationSuppressedMaintenance = "maintenance"
+	// NotificationSuppressedDigest marks a notification buffered for a digest, see the Digest failure type.
+	NotificationSuppressedDigest = "digest"
+)
+
+// NotificationRecord is one notification attempt, persisted so delivery can be audited after a
+// restart. Target URLs are stored masked.
+type NotificationRecord struct {
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	FailureType  string `json:"failure_type" gorm:"index"`
+	HostID       string `json:"host_id,omitempty"`
+	DeviceName   string `json:"device_name,omitempty"`
+	DeviceSerial string `json:"device_serial,omitempty"`
+	Subject      string `json:"subject"`
+	Outcome      string `json:"outcome" gorm:"index"`
+	SuppressedBy string `json:"suppressed_by,omitempty"`
+	Error        string `json:"error,omitempty"`
+
+	// MaintenanceWindowID is the window that suppressed the notification, if any.
+	MaintenanceWindowID *uint `json:"maintenance_window_id,omitempty" gorm:"index"`
+
+	Targets []NotificationTargetResult `json:"targets" gorm:"type:text;serializer:json"`
+
+	ID uint `json:"id" gorm:"primaryKey"`
+}
+
+// NotificationTargetResult is the delivery result for a single notification URL.
+type NotificationTargetResult struct {
+	URL     string `json:"url"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NotificationOutcome derives the overall outcome of a delivery from its per-target results.
+func NotificationOutcome(targets []NotificationTargetResult) string {
+	succeeded := 0
+	for _, target := range targets {
+		if target.Success {
+			succeeded++
+		}
+	}
+	switch {
+	case len(targets) > 0 && succeeded == len(targets):
+		return NotificationOutcomeSent
+	case succeeded > 0:
+		return NotificationOutcomePartial
+	default:
+		return NotificationOutcomeFailed
+	}
+}
+
+// NotificationHistoryFilter narrows a notification history query. Zero values match everything.
+type NotificationHistoryFilter struct {
+	FailureType string
+	Outcome     string
+	HostID      string
+	// MaintenanceWindowID limits the query to notifications suppressed by one maintenance window.
+	MaintenanceWindowID uint
+	Since               *time.Time
+	Limit               int
+	Offset              int
+}
+
+// QueuedNotification holds a notification that was deferred during quiet hours. The queue is
+// persisted so a restart does not lose notifications waiting for the quiet hours digest.
+type QueuedNotification struct {
+	QueuedAt    time.Time `json:"queued_at"`
+	FailureType string    `json:"failure_type"`
+	HostID      string    `json:"host_id"`
+	Severity    string    `json:"severity"`
+	Subject     string    `json:"subject"`
+	Message     string    `json:"message"`
+
+	// the database URLs the notification was routed to, so the quiet hours digest reaches them
+	DatabaseUrls []string `json:"database_urls" gorm:"type:text;serializer:json"`
+
+	ID uint `json:"id" gorm:"primaryKey"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Notification retry states. Pending retries are resent with exponential backoff until they are
+// delivered (and deleted) or exceed the maximum age, at which point they are kept as dead letters.
+const (
+	NotificationRetryPending = "pending"
+	NotificationRetryDead    = "dead"
+)
+
+// NotificationRetry is a notification that failed to reach one target URL and waits to be resent.
+type NotificationRetry struct {
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// URL is the raw target URL including credentials; it is never serialized.
+	URL         string `json:"-"`
+	FailureType string `json:"failure_type" gorm:"index"`
+	Subject     string `json:"subject"`
+
+	// Payload is the JSON-encoded notification payload, HTMLMessage its HTML body (not part of
+	// the payload JSON) and WebhookEvent the JSON-encoded event for signed webhook targets.
+	Payload      string `json:"-" gorm:"type:text"`
+	HTMLMessage  string `json:"-" gorm:"type:text"`
+	WebhookEvent string `json:"-" gorm:"type:text"`
+
+	Status        string     `json:"status" gorm:"index"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error,omitempty"`
+	DeadAt        *time.Time `json:"dead_at,omitempty"`
+
+	ID uint `json:"id" gorm:"primaryKey"`
+}
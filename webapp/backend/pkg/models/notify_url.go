@@ -1,6 +1,23 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Notification severities, ordered from least to most severe.
+const (
+	NotifySeverityInfo     = "info"
+	NotifySeverityWarning  = "warning"
+	NotifySeverityCritical = "critical"
+)
+
+var notifySeverityRank = map[string]int{
+	NotifySeverityInfo:     1,
+	NotifySeverityWarning:  2,
+	NotifySeverityCritical: 3,
+}
 
 // NotifyUrl represents a user-configured notification endpoint stored in the database.
 // Only UI-sourced URLs are persisted here. Config/env URLs are read from Viper at runtime.
@@ -12,8 +29,108 @@ type NotifyUrl struct {
 	Source           string    `json:"source" gorm:"default:'ui'"`
 	HeartbeatEnabled bool      `json:"heartbeat_enabled" gorm:"default:true"`
 	ID               uint      `json:"id" gorm:"primaryKey"`
+
+	// Escalation marks a secondary URL that only receives escalations of incidents that stayed
+	// unacknowledged past the configured delay, instead of regular notifications.
+	Escalation bool `json:"escalation" gorm:"default:false"`
+
+	// Rules restrict which notifications are delivered to this URL. A URL without rules
+	// receives every notification; otherwise a notification must match at least one rule.
+	Rules []NotifyRoutingRule `json:"rules" gorm:"type:text;serializer:json"`
 }
 
 func (NotifyUrl) TableName() string {
 	return "notify_urls"
 }
+
+// NotifyRoutingRule matches notifications by their attributes. Empty fields match anything;
+// within a list field any entry may match, and Tags matches when the resource has any of them.
+type NotifyRoutingRule struct {
+	FailureTypes    []string `json:"failure_types,omitempty"`
+	HostIDs         []string `json:"host_ids,omitempty"`
+	DeviceProtocols []string `json:"device_protocols,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	MinSeverity     string   `json:"min_severity,omitempty"`
+}
+
+// NotifyEvent describes a notification for routing purposes.
+type NotifyEvent struct {
+	FailureType    string   `json:"failure_type"`
+	HostID         string   `json:"host_id,omitempty"`
+	DeviceProtocol string   `json:"device_protocol,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Severity       string   `json:"severity"`
+}
+
+// Accepts reports whether the URL's routing rules allow delivery of the event.
+func (u NotifyUrl) Accepts(event NotifyEvent) bool {
+	if len(u.Rules) == 0 {
+		return true
+	}
+	for _, rule := range u.Rules {
+		if rule.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the event satisfies every non-empty field of the rule.
+func (r NotifyRoutingRule) Matches(event NotifyEvent) bool {
+	if len(r.FailureTypes) > 0 && !containsFold(r.FailureTypes, event.FailureType) {
+		return false
+	}
+	if len(r.HostIDs) > 0 && !containsFold(r.HostIDs, event.HostID) {
+		return false
+	}
+	if len(r.DeviceProtocols) > 0 && !containsFold(r.DeviceProtocols, event.DeviceProtocol) {
+		return false
+	}
+	if len(r.Tags) > 0 && !containsAnyFold(r.Tags, event.Tags) {
+		return false
+	}
+	if r.MinSeverity != "" && notifySeverityRank[strings.ToLower(event.Severity)] < notifySeverityRank[strings.ToLower(r.MinSeverity)] {
+		return false
+	}
+	return true
+}
+
+// Validate checks that the rule only uses known severities.
+func (r NotifyRoutingRule) Validate() error {
+	if r.MinSeverity != "" && !ValidNotifySeverity(r.MinSeverity) {
+		return fmt.Errorf("invalid min_severity %q: must be %s, %s or %s", r.MinSeverity, NotifySeverityInfo, NotifySeverityWarning, NotifySeverityCritical)
+	}
+	return nil
+}
+
+// ValidNotifySeverity reports whether severity is one of the known notification severities.
+func ValidNotifySeverity(severity string) bool {
+	_, ok := notifySeverityRank[strings.ToLower(severity)]
+	return ok
+}
+
+// MaxNotifySeverity returns the higher of two notification severities.
+func MaxNotifySeverity(a string, b string) string {
+	if notifySeverityRank[strings.ToLower(b)] > notifySeverityRank[strings.ToLower(a)] {
+		return b
+	}
+	return a
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyFold(values []string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if containsFold(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
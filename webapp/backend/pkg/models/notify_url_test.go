@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyUrlAccepts(t *testing.T) {
+	failure := NotifyEvent{FailureType: "SmartFailure", HostID: "nas", DeviceProtocol: "ATA", Severity: NotifySeverityCritical}
+	report := NotifyEvent{FailureType: "Report", Severity: NotifySeverityInfo}
+
+	// URLs without rules receive everything
+	require.True(t, NotifyUrl{}.Accepts(failure))
+	require.True(t, NotifyUrl{}.Accepts(report))
+
+	pager := NotifyUrl{Rules: []NotifyRoutingRule{{MinSeverity: NotifySeverityCritical, HostIDs: []string{"NAS"}}}}
+	require.True(t, pager.Accepts(failure))
+	require.False(t, pager.Accepts(report))
+	require.False(t, pager.Accepts(NotifyEvent{FailureType: "SmartFailure", HostID: "backup", Severity: NotifySeverityCritical}))
+
+	chat := NotifyUrl{Rules: []NotifyRoutingRule{
+		{FailureTypes: []string{"report", "Heartbeat"}},
+		{DeviceProtocols: []string{"NVMe"}, MinSeverity: NotifySeverityWarning},
+	}}
+	require.True(t, chat.Accepts(report))
+	require.False(t, chat.Accepts(failure))
+	require.True(t, chat.Accepts(NotifyEvent{FailureType: "ReplacementRisk", DeviceProtocol: "NVMe", Severity: NotifySeverityWarning}))
+}
+
+func TestNotifyRoutingRuleValidate(t *testing.T) {
+	require.NoError(t, NotifyRoutingRule{}.Validate())
+	require.NoError(t, NotifyRoutingRule{MinSeverity: "Warning"}.Validate())
+	require.Error(t, NotifyRoutingRule{MinSeverity: "urgent"}.Validate())
+}
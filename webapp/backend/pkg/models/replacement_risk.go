@@ -132,6 +132,10 @@ type ReplacementRiskScore struct {
 	// ConsumerDriveProfileCatalogVersion is the bundled catalog version the applied
 	// profile came from. Omitted when generic ATA logic is used.
 	ConsumerDriveProfileCatalogVersion string `json:"consumer_drive_profile_catalog_version,omitempty"`
+
+	// Warranty reports whether the drive is still under warranty (and can be RMA'd), based on the
+	// inventory data entered for the device. State is "unknown" when no expiry date is set.
+	Warranty WarrantyStatus `json:"warranty"`
 }
 
 // ReplacementRiskResponse is the API response envelope for the
@@ -9,38 +9,49 @@ package models
 
 type Settings struct {
 	Metrics struct {
-		NotificationQuietStart        string `json:"notification_quiet_start" mapstructure:"notification_quiet_start"`
-		ReportPDFPath                 string `json:"report_pdf_path" mapstructure:"report_pdf_path"`
-		ReportMonthlyTime             string `json:"report_monthly_time" mapstructure:"report_monthly_time"`
-		ReportWeeklyTime              string `json:"report_weekly_time" mapstructure:"report_weekly_time"`
-		ReportDailyTime               string `json:"report_daily_time" mapstructure:"report_daily_time"`
-		UptimeKumaPushURL             string `json:"uptime_kuma_push_url" mapstructure:"uptime_kuma_push_url"`
-		ReplacementRiskNotifyCategory string `json:"replacement_risk_notify_category" mapstructure:"replacement_risk_notify_category"`
-		NotificationQuietEnd          string `json:"notification_quiet_end" mapstructure:"notification_quiet_end"`
-		MissedPingCooldownMinutes     int    `json:"missed_ping_cooldown_minutes" mapstructure:"missed_ping_cooldown_minutes"`
-		NotificationRateLimit         int    `json:"notification_rate_limit" mapstructure:"notification_rate_limit"`
-		NotifyLevel                   int    `json:"notify_level" mapstructure:"notify_level"`
-		StatusFilterAttributes        int    `json:"status_filter_attributes" mapstructure:"status_filter_attributes"`
-		StatusThreshold               int    `json:"status_threshold" mapstructure:"status_threshold"`
-		MissedPingCheckIntervalMins   int    `json:"missed_ping_check_interval_mins" mapstructure:"missed_ping_check_interval_mins"`
-		ReportMonthlyDay              int    `json:"report_monthly_day" mapstructure:"report_monthly_day"`
-		HeartbeatIntervalHours        int    `json:"heartbeat_interval_hours" mapstructure:"heartbeat_interval_hours"`
-		ReportWeeklyDay               int    `json:"report_weekly_day" mapstructure:"report_weekly_day"`
-		MissedPingTimeoutMinutes      int    `json:"missed_ping_timeout_minutes" mapstructure:"missed_ping_timeout_minutes"`
-		UptimeKumaIntervalSeconds     int    `json:"uptime_kuma_interval_seconds" mapstructure:"uptime_kuma_interval_seconds"`
-		ConsumerDriveProfilesDenylist string `json:"consumer_drive_profiles_denylist" mapstructure:"consumer_drive_profiles_denylist"`
-		ReportEnabled                 bool   `json:"report_enabled" mapstructure:"report_enabled"`
-		ReportDailyEnabled            bool   `json:"report_daily_enabled" mapstructure:"report_daily_enabled"`
-		ConsumerDriveProfilesEnabled  bool   `json:"consumer_drive_profiles_enabled" mapstructure:"consumer_drive_profiles_enabled"`
-		NotifyOnMissedPing            bool   `json:"notify_on_missed_ping" mapstructure:"notify_on_missed_ping"`
-		ReportWeeklyEnabled           bool   `json:"report_weekly_enabled" mapstructure:"report_weekly_enabled"`
-		UptimeKumaEnabled             bool   `json:"uptime_kuma_enabled" mapstructure:"uptime_kuma_enabled"`
-		RepeatNotifications           bool   `json:"repeat_notifications" mapstructure:"repeat_notifications"`
-		ReportMonthlyEnabled          bool   `json:"report_monthly_enabled" mapstructure:"report_monthly_enabled"`
-		HeartbeatEnabled              bool   `json:"heartbeat_enabled" mapstructure:"heartbeat_enabled"`
-		NotifyOnReplacementRisk       bool   `json:"notify_on_replacement_risk" mapstructure:"notify_on_replacement_risk"`
-		ReportPDFEnabled              bool   `json:"report_pdf_enabled" mapstructure:"report_pdf_enabled"`
-		NotifyOnCollectorError        bool   `json:"notify_on_collector_error" mapstructure:"notify_on_collector_error"`
+		NotificationQuietStart           string `json:"notification_quiet_start" mapstructure:"notification_quiet_start"`
+		ReportPDFPath                    string `json:"report_pdf_path" mapstructure:"report_pdf_path"`
+		ReportMonthlyTime                string `json:"report_monthly_time" mapstructure:"report_monthly_time"`
+		ReportWeeklyTime                 string `json:"report_weekly_time" mapstructure:"report_weekly_time"`
+		ReportDailyTime                  string `json:"report_daily_time" mapstructure:"report_daily_time"`
+		UptimeKumaPushURL                string `json:"uptime_kuma_push_url" mapstructure:"uptime_kuma_push_url"`
+		ReplacementRiskNotifyCategory    string `json:"replacement_risk_notify_category" mapstructure:"replacement_risk_notify_category"`
+		NotificationQuietEnd             string `json:"notification_quiet_end" mapstructure:"notification_quiet_end"`
+		MissedPingCooldownMinutes        int    `json:"missed_ping_cooldown_minutes" mapstructure:"missed_ping_cooldown_minutes"`
+		NotificationRateLimit            int    `json:"notification_rate_limit" mapstructure:"notification_rate_limit"`
+		NotifyLevel                      int    `json:"notify_level" mapstructure:"notify_level"`
+		StatusFilterAttributes           int    `json:"status_filter_attributes" mapstructure:"status_filter_attributes"`
+		StatusThreshold                  int    `json:"status_threshold" mapstructure:"status_threshold"`
+		MissedPingCheckIntervalMins      int    `json:"missed_ping_check_interval_mins" mapstructure:"missed_ping_check_interval_mins"`
+		ReportMonthlyDay                 int    `json:"report_monthly_day" mapstructure:"report_monthly_day"`
+		HeartbeatIntervalHours           int    `json:"heartbeat_interval_hours" mapstructure:"heartbeat_interval_hours"`
+		ReportWeeklyDay                  int    `json:"report_weekly_day" mapstructure:"report_weekly_day"`
+		MissedPingTimeoutMinutes         int    `json:"missed_ping_timeout_minutes" mapstructure:"missed_ping_timeout_minutes"`
+		UptimeKumaIntervalSeconds        int    `json:"uptime_kuma_interval_seconds" mapstructure:"uptime_kuma_interval_seconds"`
+		WarrantyNotifyDays               int    `json:"warranty_notify_days" mapstructure:"warranty_notify_days"`
+		IncidentEscalationMinutes        int    `json:"incident_escalation_minutes" mapstructure:"incident_escalation_minutes"`
+		ConsumerDriveProfilesDenylist    string `json:"consumer_drive_profiles_denylist" mapstructure:"consumer_drive_profiles_denylist"`
+		ReportEnabled                    bool   `json:"report_enabled" mapstructure:"report_enabled"`
+		ReportDailyEnabled               bool   `json:"report_daily_enabled" mapstructure:"report_daily_enabled"`
+		ConsumerDriveProfilesEnabled     bool   `json:"consumer_drive_profiles_enabled" mapstructure:"consumer_drive_profiles_enabled"`
+		NotifyOnMissedPing               bool   `json:"notify_on_missed_ping" mapstructure:"notify_on_missed_ping"`
+		ReportWeeklyEnabled              bool   `json:"report_weekly_enabled" mapstructure:"report_weekly_enabled"`
+		UptimeKumaEnabled                bool   `json:"uptime_kuma_enabled" mapstructure:"uptime_kuma_enabled"`
+		RepeatNotifications              bool   `json:"repeat_notifications" mapstructure:"repeat_notifications"`
+		ReportMonthlyEnabled             bool   `json:"report_monthly_enabled" mapstructure:"report_monthly_enabled"`
+		HeartbeatEnabled                 bool   `json:"heartbeat_enabled" mapstructure:"heartbeat_enabled"`
+		NotifyOnReplacementRisk          bool   `json:"notify_on_replacement_risk" mapstructure:"notify_on_replacement_risk"`
+		ReportPDFEnabled                 bool   `json:"report_pdf_enabled" mapstructure:"report_pdf_enabled"`
+		NotifyOnCollectorError           bool   `json:"notify_on_collector_error" mapstructure:"notify_on_collector_error"`
+		NotifyOnWarrantyExpiry           bool   `json:"notify_on_warranty_expiry" mapstructure:"notify_on_warranty_expiry"`
+		NotifyOnLifecycleEvents          bool   `json:"notify_on_lifecycle_events" mapstructure:"notify_on_lifecycle_events"`
+		NotifyOnDeviceRecovered          bool   `json:"notify_on_device_recovered" mapstructure:"notify_on_device_recovered"`
+		NotifyOnMissedPingRecovered      bool   `json:"notify_on_missed_ping_recovered" mapstructure:"notify_on_missed_ping_recovered"`
+		NotifyOnArrayRecovered           bool   `json:"notify_on_array_recovered" mapstructure:"notify_on_array_recovered"`
+		NotifyOnZFSPoolRecovered         bool   `json:"notify_on_zfs_pool_recovered" mapstructure:"notify_on_zfs_pool_recovered"`
+		NotifyOnCollectorErrorCleared    bool   `json:"notify_on_collector_error_cleared" mapstructure:"notify_on_collector_error_cleared"`
+		NotificationDigestMinutes        int    `json:"notification_digest_minutes" mapstructure:"notification_digest_minutes"`
+		NotificationDigestBypassCritical bool   `json:"notification_digest_bypass_critical" mapstructure:"notification_digest_bypass_critical"`
 	} `json:"metrics" mapstructure:"metrics"`
 	Theme              string `json:"theme" mapstructure:"theme"`
 	Layout             string `json:"layout" mapstructure:"layout"`
@@ -112,6 +123,7 @@ func (s *Settings) ApplyDefaults() {
 	defaultInt(&s.Metrics.MissedPingCheckIntervalMins, 5)
 	defaultInt(&s.Metrics.HeartbeatIntervalHours, 24)
 	defaultInt(&s.Metrics.UptimeKumaIntervalSeconds, 60)
+	defaultInt(&s.Metrics.WarrantyNotifyDays, 30)
 
 	// Replacement risk notification default
 	defaultStr(&s.Metrics.ReplacementRiskNotifyCategory, "replace_soon")
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Resource types that can be tagged.
+const (
+	TagResourceDevice          = "device"
+	TagResourceZFSPool         = "zfs_pool"
+	TagResourceMdadmArray      = "mdadm_array"
+	TagResourceBtrfsFilesystem = "btrfs_filesystem"
+)
+
+// MaxTagLength is the maximum length of a single tag.
+const MaxTagLength = 64
+
+// ResourceTag assigns a tag to a device (by device ID), ZFS pool (by GUID), mdadm array or Btrfs
+// filesystem (by UUID). A resource can have many tags and a tag can be used by many resources.
+type ResourceTag struct {
+	CreatedAt    time.Time `json:"created_at"`
+	ResourceType string    `json:"resource_type" gorm:"primaryKey"`
+	ResourceID   string    `json:"resource_id" gorm:"primaryKey"`
+	Tag          string    `json:"tag" gorm:"primaryKey;index"`
+}
+
+// TagCount is a tag with the number of resources of each type that use it.
+type TagCount struct {
+	Tag            string         `json:"tag"`
+	Count          int            `json:"count"`
+	ResourceCounts map[string]int `json:"resource_counts"`
+}
+
+// ValidTagResourceType reports whether resourceType is one of the taggable resource types.
+func ValidTagResourceType(resourceType string) bool {
+	switch resourceType {
+	case TagResourceDevice, TagResourceZFSPool, TagResourceMdadmArray, TagResourceBtrfsFilesystem:
+		return true
+	}
+	return false
+}
+
+// NormalizeTags trims, lowercases, de-duplicates and sorts tags, dropping empty ones. Tags may not
+// contain commas, since comma-separated lists are accepted by the tag filters.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		if strings.ContainsAny(tag, ",\n\r\t") {
+			return nil, fmt.Errorf("tag %q contains an invalid character", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// TagFilter selects resources that have every one of its tags. An empty filter matches everything.
+type TagFilter []string
+
+// ParseTagFilter builds a TagFilter from query values, each of which may be a comma-separated list.
+func ParseTagFilter(values []string) TagFilter {
+	filter := TagFilter{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				filter = append(filter, tag)
+			}
+		}
+	}
+	return filter
+}
+
+// Matches reports whether tags contains every tag of the filter.
+func (f TagFilter) Matches(tags []string) bool {
+	for _, want := range f {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
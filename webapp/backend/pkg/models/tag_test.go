@@ -0,0 +1,39 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Critical", "backup", "", "BACKUP", "offsite "})
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup", "critical", "offsite"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	_, err = NormalizeTags([]string{"a,b"})
+	require.Error(t, err)
+	_, err = NormalizeTags([]string{"line\nbreak"})
+	require.Error(t, err)
+	_, err = NormalizeTags([]string{strings.Repeat("x", MaxTagLength+1)})
+	require.Error(t, err)
+}
+
+func TestTagFilter(t *testing.T) {
+	filter := ParseTagFilter([]string{"Backup,critical", " offsite ", ""})
+	require.Equal(t, TagFilter{"backup", "critical", "offsite"}, filter)
+
+	require.True(t, filter.Matches([]string{"offsite", "critical", "backup", "nas"}))
+	require.False(t, filter.Matches([]string{"backup", "critical"}))
+	require.False(t, filter.Matches(nil))
+
+	// an empty filter matches everything
+	var empty TagFilter
+	require.Empty(t, ParseTagFilter(nil))
+	require.True(t, empty.Matches(nil))
+}
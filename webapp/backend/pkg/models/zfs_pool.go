@@ -44,6 +44,7 @@ type ZFSPool struct {
 	HostID               string        `json:"host_id"`
 	Health               string        `json:"health"`
 	Label                string        `json:"label,omitempty"`
+	Tags                 []string      `json:"tags,omitempty" gorm:"-"`
 	GUID                 string        `json:"guid" gorm:"primary_key"`
 	Status               ZFSPoolStatus `json:"status"`
 	Name                 string        `json:"name"`
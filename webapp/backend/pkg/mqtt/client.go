@@ -2,6 +2,7 @@ package mqtt
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	pahomqtt "github.com/eclipse/paho.mqtt.golang"
@@ -23,6 +24,15 @@ type Client struct {
 	client pahomqtt.Client
 	logger *logrus.Entry
 	qos    byte
+
+	// subscriptions are renewed on every (re)connect, as the broker may have dropped them
+	subscriptions map[string]subscription
+	subMu         sync.Mutex
+}
+
+type subscription struct {
+	qos     byte
+	handler func(topic string, payload []byte)
 }
 
 // ClientConfig holds MQTT connection parameters.
@@ -39,8 +49,9 @@ type ClientConfig struct {
 // NewClient creates a new MQTT client configured for Scrutiny.
 func NewClient(cfg *ClientConfig, logger *logrus.Entry) *Client {
 	c := &Client{
-		logger: logger,
-		qos:    byte(cfg.QoS),
+		logger:        logger,
+		qos:           byte(cfg.QoS),
+		subscriptions: map[string]subscription{},
 	}
 
 	opts := pahomqtt.NewClientOptions()
@@ -69,6 +80,7 @@ func NewClient(cfg *ClientConfig, logger *logrus.Entry) *Client {
 		if err := c.publish(availabilityTopic, availabilityOnline, true); err != nil {
 			logger.Warnf("MQTT: failed to publish online status: %v", err)
 		}
+		c.resubscribe()
 	})
 
 	opts.SetConnectionLostHandler(func(_ pahomqtt.Client, err error) {
@@ -112,13 +124,75 @@ func (c *Client) Publish(topic string, payload string, retained bool) error {
 	return c.publish(topic, payload, retained)
 }
 
+// PublishQoS is Publish with a QoS other than the configured one.
+func (c *Client) PublishQoS(topic string, payload string, qos byte, retained bool) error {
+	return c.publishQoS(topic, payload, qos, retained)
+}
+
 // IsConnected returns whether the client is currently connected.
 func (c *Client) IsConnected() bool {
 	return c.client != nil && c.client.IsConnected()
 }
 
+// Subscribe registers a handler for messages on a topic filter. The handler runs on the client's
+// message goroutine and must not block. Retained messages are ignored: subscriptions carry commands
+// and collector requests, which must run once when sent, not again whenever the client reconnects.
+func (c *Client) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+	return c.SubscribeQoS(topic, c.qos, handler)
+}
+
+// SubscribeQoS is Subscribe with a QoS other than the configured one.
+func (c *Client) SubscribeQoS(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	sub := subscription{qos: qos, handler: handler}
+	c.subMu.Lock()
+	c.subscriptions[topic] = sub
+	c.subMu.Unlock()
+
+	if !c.IsConnected() {
+		// subscribed once the connection is established
+		return nil
+	}
+	return c.subscribe(topic, sub)
+}
+
+func (c *Client) resubscribe() {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for topic, sub := range c.subscriptions {
+		if err := c.subscribe(topic, sub); err != nil {
+			c.logger.Warnf("MQTT: %v", err)
+		}
+	}
+}
+
+func (c *Client) subscribe(topic string, sub subscription) error {
+	token := c.client.Subscribe(topic, sub.qos, c.messageHandler(sub))
+	if !token.WaitTimeout(defaultPublishTimeout) {
+		return fmt.Errorf("MQTT subscribe to %s timed out", topic)
+	}
+	if token.Error() != nil {
+		return fmt.Errorf("MQTT subscribe to %s failed: %w", topic, token.Error())
+	}
+	return nil
+}
+
+// messageHandler passes the messages of a subscription to its handler, dropping retained ones.
+func (c *Client) messageHandler(sub subscription) pahomqtt.MessageHandler {
+	return func(_ pahomqtt.Client, msg pahomqtt.Message) {
+		if msg.Retained() {
+			c.logger.Warnf("MQTT: ignoring retained message on %s, commands and requests must be published without the retain flag", msg.Topic())
+			return
+		}
+		sub.handler(msg.Topic(), msg.Payload())
+	}
+}
+
 func (c *Client) publish(topic string, payload string, retained bool) error {
-	token := c.client.Publish(topic, c.qos, retained, payload)
+	return c.publishQoS(topic, payload, c.qos, retained)
+}
+
+func (c *Client) publishQoS(topic string, payload string, qos byte, retained bool) error {
+	token := c.client.Publish(topic, qos, retained, payload)
 	if !token.WaitTimeout(defaultPublishTimeout) {
 		return fmt.Errorf("MQTT publish to %s timed out", topic)
 	}
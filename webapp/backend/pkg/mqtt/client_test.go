@@ -0,0 +1,41 @@
+package mqtt
+
+import (
+	"testing"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// testMessage is a received MQTT message.
+type testMessage struct {
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+func (m testMessage) Duplicate() bool   { return false }
+func (m testMessage) Qos() byte         { return 1 }
+func (m testMessage) Retained() bool    { return m.retained }
+func (m testMessage) Topic() string     { return m.topic }
+func (m testMessage) MessageID() uint16 { return 1 }
+func (m testMessage) Payload() []byte   { return m.payload }
+func (m testMessage) Ack()              {}
+
+var _ pahomqtt.Message = testMessage{}
+
+func TestClientMessageHandler_IgnoresRetainedMessages(t *testing.T) {
+	client := &Client{logger: logrus.NewEntry(logrus.StandardLogger())}
+	received := []string{}
+	handler := client.messageHandler(subscription{handler: func(topic string, payload []byte) {
+		received = append(received, topic+"="+string(payload))
+	}})
+
+	// a retained command is replayed by the broker on every (re)connect and must not run again
+	handler(nil, testMessage{topic: "scrutiny/command/collectors/run", payload: []byte("PRESS"), retained: true})
+	require.Empty(t, received)
+
+	handler(nil, testMessage{topic: "scrutiny/command/collectors/run", payload: []byte("PRESS")})
+	require.Equal(t, []string{"scrutiny/command/collectors/run=PRESS"}, received)
+}
@@ -0,0 +1,118 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+)
+
+// collectorTransportQoS delivers collector requests and responses at least once. Uploads are not
+// idempotent (the SQLite time-series backend inserts every point), so duplicate requests are
+// answered from processedRequests instead of being handled again. The collector ignores responses
+// it is no longer waiting for.
+const collectorTransportQoS = 1
+
+// processedRequestTTL is how long the response to a collector request is kept for duplicates. QoS 1
+// redeliveries arrive within seconds, and collectors give up on a request after api.timeout.
+const processedRequestTTL = 10 * time.Minute
+
+// processedRequests remembers the collector requests handled recently, by client and request ID,
+// and the response to each, so a redelivered request gets the same response without running again.
+type processedRequests struct {
+	mu      sync.Mutex
+	entries map[string]*processedRequest
+}
+
+type processedRequest struct {
+	done     chan struct{} // closed once the response is set
+	response collector.TransportResponse
+	expires  time.Time
+}
+
+func newProcessedRequests() *processedRequests {
+	return &processedRequests{entries: map[string]*processedRequest{}}
+}
+
+// handle returns the response to a request, calling process only for the first delivery of a
+// request ID. Duplicates that arrive while the first delivery is processed wait for its response.
+func (r *processedRequests) handle(clientID string, requestID string, now time.Time, process func() collector.TransportResponse) (collector.TransportResponse, bool) {
+	key := clientID + "\x00" + requestID
+
+	r.mu.Lock()
+	for k, entry := range r.entries {
+		if !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(r.entries, k)
+		}
+	}
+	if entry, ok := r.entries[key]; ok {
+		r.mu.Unlock()
+		<-entry.done
+		return entry.response, true
+	}
+	entry := &processedRequest{done: make(chan struct{})}
+	r.entries[key] = entry
+	r.mu.Unlock()
+
+	response := process()
+
+	r.mu.Lock()
+	entry.response = response
+	entry.expires = now.Add(processedRequestTTL)
+	r.mu.Unlock()
+	close(entry.done)
+	return response, false
+}
+
+// CollectorRequestHandler processes a request a collector sent over MQTT and returns the response.
+type CollectorRequestHandler func(ctx context.Context, clientID string, req *collector.TransportRequest) collector.TransportResponse
+
+// EnableCollectorTransport subscribes to the request topics of collectors using the MQTT transport
+// (api.transport: mqtt) and publishes the response to each request on the collector's response topic.
+func (p *Publisher) EnableCollectorTransport(handler CollectorRequestHandler) error {
+	processed := newProcessedRequests()
+	return p.client.SubscribeQoS(collector.TransportRequestTopicFilter, collectorTransportQoS, func(topic string, payload []byte) {
+		// the client's message goroutine must not block
+		go p.handleCollectorRequest(handler, processed, topic, payload)
+	})
+}
+
+func (p *Publisher) handleCollectorRequest(handler CollectorRequestHandler, processed *processedRequests, topic string, payload []byte) {
+	clientID, err := collector.TransportClientID(topic)
+	if err != nil {
+		p.logger.Warnf("MQTT: %v", err)
+		return
+	}
+
+	var req collector.TransportRequest
+	if err := json.Unmarshal(payload, &req); err != nil || req.ID == "" {
+		// without an ID the collector cannot match a response, it times out instead
+		p.logger.Warnf("MQTT: ignoring invalid collector request on %s", topic)
+		return
+	}
+
+	response, duplicate := processed.handle(clientID, req.ID, time.Now(), func() collector.TransportResponse {
+		response := handler(context.Background(), clientID, &req)
+		response.ID = req.ID
+		if response.Status == 0 {
+			response.Status = http.StatusInternalServerError
+		}
+		return response
+	})
+	if duplicate {
+		p.logger.Debugf("MQTT: collector %s sent request %s again, replaying the response", clientID, req.ID)
+	}
+	p.logger.Debugf("MQTT: collector %s %s %s: %d", clientID, req.Method, req.Path, response.Status)
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		p.logger.Warnf("MQTT: failed to marshal response to collector %s: %v", clientID, err)
+		return
+	}
+	if err := p.client.PublishQoS(collector.TransportResponseTopic(clientID), string(responseJSON), collectorTransportQoS, false); err != nil {
+		p.logger.Warnf("MQTT: failed to publish response to collector %s: %v", clientID, err)
+	}
+}
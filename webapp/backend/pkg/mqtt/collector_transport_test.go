@@ -0,0 +1,78 @@
+package mqtt
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessedRequests_ReplaysDuplicates(t *testing.T) {
+	processed := newProcessedRequests()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	process := func() collector.TransportResponse {
+		calls++
+		return collector.TransportResponse{ID: "req-1", Status: http.StatusOK, Body: []byte(`{"success":true}`)}
+	}
+
+	response, duplicate := processed.handle("scrutiny-collector-nas", "req-1", now, process)
+	require.False(t, duplicate)
+	require.Equal(t, http.StatusOK, response.Status)
+
+	// a QoS 1 redelivery gets the stored response without uploading the points again
+	response, duplicate = processed.handle("scrutiny-collector-nas", "req-1", now.Add(time.Second), process)
+	require.True(t, duplicate)
+	require.Equal(t, `{"success":true}`, string(response.Body))
+	require.Equal(t, 1, calls)
+
+	// request IDs are scoped to the collector
+	_, duplicate = processed.handle("scrutiny-collector-backup", "req-1", now, process)
+	require.False(t, duplicate)
+	require.Equal(t, 2, calls)
+
+	// responses are forgotten once no redelivery can be expected
+	_, duplicate = processed.handle("scrutiny-collector-nas", "req-1", now.Add(processedRequestTTL+time.Minute), process)
+	require.False(t, duplicate)
+	require.Equal(t, 3, calls)
+}
+
+func TestProcessedRequests_DuplicateWaitsForTheFirstDelivery(t *testing.T) {
+	processed := newProcessedRequests()
+	now := time.Now()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		processed.handle("scrutiny-collector-nas", "req-1", now, func() collector.TransportResponse {
+			close(started)
+			<-release
+			return collector.TransportResponse{ID: "req-1", Status: http.StatusCreated}
+		})
+	}()
+	<-started
+
+	type replay struct {
+		response  collector.TransportResponse
+		duplicate bool
+	}
+	replayed := make(chan replay)
+	go func() {
+		response, duplicate := processed.handle("scrutiny-collector-nas", "req-1", now, func() collector.TransportResponse {
+			return collector.TransportResponse{Status: http.StatusInternalServerError}
+		})
+		replayed <- replay{response, duplicate}
+	}()
+
+	close(release)
+	result := <-replayed
+	require.True(t, result.duplicate, "a duplicate is not processed")
+	require.Equal(t, http.StatusCreated, result.response.Status)
+	wg.Wait()
+}
@@ -0,0 +1,196 @@
+package mqtt
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+// commandTimeout bounds a single command, e.g. generating and sending a report.
+const commandTimeout = 2 * time.Minute
+
+// EnableCommands subscribes to the command topics and performs the commands whose topic is in
+// the allowlist. Buttons and switches are published to Home Assistant for the allowed commands.
+func (p *Publisher) EnableCommands(actions CommandActions, allowlist []string) error {
+	p.mu.Lock()
+	p.actions = actions
+	p.allowlist = TopicAllowlist(allowlist)
+	p.mu.Unlock()
+
+	if len(allowlist) == 0 {
+		p.logger.Warn("MQTT: commands are enabled, but web.mqtt.commands.allowed_topics is empty, all commands will be rejected")
+	}
+	return p.client.Subscribe(commandTopicRoot+"/#", p.handleCommand)
+}
+
+// commandAllowlist returns the allowed command topics, empty unless commands are enabled.
+func (p *Publisher) commandAllowlist() TopicAllowlist {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.actions == nil {
+		return nil
+	}
+	return p.allowlist
+}
+
+func (p *Publisher) handleCommand(topic string, payload []byte) {
+	allowlist := p.commandAllowlist()
+	if !allowlist.Allows(topic) {
+		p.logger.Warnf("MQTT: rejected command on %s, the topic is not in web.mqtt.commands.allowed_topics", topic)
+		return
+	}
+	cmd, err := ParseCommand(topic, payload)
+	if err != nil {
+		p.logger.Warnf("MQTT: rejected command on %s: %v", topic, err)
+		return
+	}
+	// the client's message goroutine must not block
+	go p.executeCommand(cmd)
+}
+
+func (p *Publisher) executeCommand(cmd Command) {
+	p.mu.RLock()
+	actions := p.actions
+	p.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	p.logger.Infof("MQTT: received command %s on %s (payload %q)", cmd.Name, cmd.Topic, cmd.Payload)
+
+	var err error
+	switch cmd.Name {
+	case CommandRunCollectors:
+		err = actions.RunCollectors(ctx)
+	case CommandRequestReport:
+		err = actions.RequestReport(ctx, cmd.Target)
+	case CommandAcknowledgeAlerts:
+		var id uint64
+		if cmd.Target != "" {
+			id, _ = strconv.ParseUint(cmd.Target, 10, 0)
+		}
+		var acknowledged int
+		acknowledged, err = actions.AcknowledgeAlerts(ctx, uint(id))
+		if err == nil {
+			p.logger.Infof("MQTT: acknowledged %d incidents", acknowledged)
+		}
+	case CommandMuteDevice:
+		muted := cmd.Payload == commandPayloadOn
+		if err = actions.SetDeviceMuted(ctx, cmd.Target, muted); err == nil {
+			p.publishMutedState(deviceMutedTopic(cmd.Target), muted)
+		}
+	case CommandResetDeviceStatus:
+		err = actions.ResetDeviceStatus(ctx, cmd.Target)
+	case CommandMuteZFSPool:
+		muted := cmd.Payload == commandPayloadOn
+		if err = actions.SetZFSPoolMuted(ctx, cmd.Target, muted); err == nil {
+			p.publishMutedState(zfsPoolMutedTopic(cmd.Target), muted)
+		}
+	case CommandMuteMdadmArray:
+		muted := cmd.Payload == commandPayloadOn
+		if err = actions.SetMdadmArrayMuted(ctx, cmd.Target, muted); err == nil {
+			p.publishMutedState(mdadmArrayMutedTopic(cmd.Target), muted)
+		}
+	case CommandMuteBtrfs:
+		muted := cmd.Payload == commandPayloadOn
+		if err = actions.SetBtrfsFilesystemMuted(ctx, cmd.Target, muted); err == nil {
+			p.publishMutedState(btrfsFilesystemMutedTopic(cmd.Target), muted)
+		}
+	}
+
+	if err != nil {
+		p.logger.Errorf("MQTT: command %s on %s failed: %v", cmd.Name, cmd.Topic, err)
+		return
+	}
+	p.logger.Infof("MQTT: command %s on %s completed", cmd.Name, cmd.Topic)
+}
+
+// publishServerCommands publishes the buttons of the Scrutiny device itself.
+func (p *Publisher) publishServerCommands() {
+	if !p.client.IsConnected() {
+		return
+	}
+	messages := buildCommandDiscoveryMessages(p.topicPrefix, "server", serverDeviceInfo(), serverCommandEntities(), p.commandAllowlist())
+	p.publishDiscoveryMessages("server", messages)
+}
+
+// publishDeviceCommands publishes the mute switch and reset button of a device, with the switch state.
+func (p *Publisher) publishDeviceCommands(device *models.Device) {
+	if !p.client.IsConnected() {
+		return
+	}
+	allowlist := p.commandAllowlist()
+	messages := buildCommandDiscoveryMessages(p.topicPrefix, safeID(device.DeviceID), deviceInfo(device), deviceCommandEntities(device.DeviceID), allowlist)
+	p.publishDiscoveryMessages(device.DeviceID, messages)
+	if allowlist.Allows(deviceMuteTopic(device.DeviceID)) {
+		p.publishMutedState(deviceMutedTopic(device.DeviceID), device.Muted)
+	}
+}
+
+// publishStorageCommands publishes the mute switch of a ZFS pool, mdadm array or Btrfs
+// filesystem, with its state.
+func (p *Publisher) publishStorageCommands(entity storageEntity, muted bool) {
+	if !p.client.IsConnected() {
+		return
+	}
+	allowlist := p.commandAllowlist()
+	entities, mutedTopic := storageCommandEntities(entity.kind, entity.id)
+	messages := buildCommandDiscoveryMessages(p.topicPrefix, entity.safe(), entity.deviceInfo(), entities, allowlist)
+	p.publishDiscoveryMessages(entity.safe(), messages)
+	if allowlist.Allows(entities[0].commandTopic) {
+		p.publishMutedState(mutedTopic, muted)
+	}
+}
+
+// PublishDeviceMuted publishes the mute switch state of a device muted or unmuted outside MQTT,
+// e.g. in the UI, so Home Assistant shows the current state.
+func (p *Publisher) PublishDeviceMuted(deviceID string, muted bool) {
+	if !p.client.IsConnected() || !p.commandAllowlist().Allows(deviceMuteTopic(deviceID)) {
+		return
+	}
+	p.publishMutedState(deviceMutedTopic(deviceID), muted)
+}
+
+// PublishZFSPoolMuted publishes the mute switch state of a ZFS pool muted or unmuted outside MQTT.
+func (p *Publisher) PublishZFSPoolMuted(guid string, muted bool) {
+	if !p.client.IsConnected() || !p.commandAllowlist().Allows(zfsPoolMuteTopic(guid)) {
+		return
+	}
+	p.publishMutedState(zfsPoolMutedTopic(guid), muted)
+}
+
+// PublishMdadmArrayMuted publishes the mute switch state of an mdadm array muted or unmuted outside MQTT.
+func (p *Publisher) PublishMdadmArrayMuted(uuid string, muted bool) {
+	if !p.client.IsConnected() || !p.commandAllowlist().Allows(mdadmArrayMuteTopic(uuid)) {
+		return
+	}
+	p.publishMutedState(mdadmArrayMutedTopic(uuid), muted)
+}
+
+// PublishBtrfsFilesystemMuted publishes the mute switch state of a Btrfs filesystem muted or unmuted outside MQTT.
+func (p *Publisher) PublishBtrfsFilesystemMuted(uuid string, muted bool) {
+	if !p.client.IsConnected() || !p.commandAllowlist().Allows(btrfsFilesystemMuteTopic(uuid)) {
+		return
+	}
+	p.publishMutedState(btrfsFilesystemMutedTopic(uuid), muted)
+}
+
+func (p *Publisher) publishDiscoveryMessages(id string, messages []DiscoveryMessage) {
+	for _, msg := range messages {
+		if err := p.client.Publish(msg.Topic, msg.Payload, true); err != nil {
+			p.logger.Warnf("MQTT: failed to publish discovery for %s: %v", id, err)
+		}
+	}
+}
+
+func (p *Publisher) publishMutedState(topic string, muted bool) {
+	state := commandPayloadOff
+	if muted {
+		state = commandPayloadOn
+	}
+	if err := p.client.Publish(topic, state, true); err != nil {
+		p.logger.Warnf("MQTT: failed to publish mute state to %s: %v", topic, err)
+	}
+}
@@ -0,0 +1,333 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/validation"
+)
+
+// commandTopicRoot is the topic below which Scrutiny receives commands, e.g. from the Home
+// Assistant buttons and switches it publishes.
+const commandTopicRoot = "scrutiny/command"
+
+// Commands received over MQTT.
+const (
+	CommandRunCollectors     = "run_collectors"
+	CommandRequestReport     = "request_report"
+	CommandAcknowledgeAlerts = "acknowledge_alerts"
+	CommandMuteDevice        = "mute_device"
+	CommandResetDeviceStatus = "reset_device_status"
+	CommandMuteZFSPool       = "mute_zfs_pool"
+	CommandMuteMdadmArray    = "mute_mdadm_array"
+	CommandMuteBtrfs         = "mute_btrfs_filesystem"
+)
+
+const (
+	commandPayloadPress = "PRESS"
+	commandPayloadOn    = "ON"
+	commandPayloadOff   = "OFF"
+)
+
+// CommandActions performs the commands received over MQTT. The web server implements it with
+// the same repository calls as the matching API endpoints.
+type CommandActions interface {
+	// RunCollectors starts the local collectors, like POST /api/collectors/run.
+	RunCollectors(ctx context.Context) error
+	// RequestReport generates a report for the period (daily, weekly or monthly) and sends it.
+	RequestReport(ctx context.Context, period string) error
+	// AcknowledgeAlerts acknowledges an open incident, or all of them when incidentID is 0, and
+	// returns the number of incidents acknowledged.
+	AcknowledgeAlerts(ctx context.Context, incidentID uint) (int, error)
+	SetDeviceMuted(ctx context.Context, deviceID string, muted bool) error
+	ResetDeviceStatus(ctx context.Context, deviceID string) error
+	SetZFSPoolMuted(ctx context.Context, guid string, muted bool) error
+	SetMdadmArrayMuted(ctx context.Context, uuid string, muted bool) error
+	SetBtrfsFilesystemMuted(ctx context.Context, uuid string, muted bool) error
+}
+
+// Command is a command parsed from an MQTT message.
+type Command struct {
+	Name    string
+	Target  string // device ID, pool GUID, array or filesystem UUID, incident ID or report period
+	Payload string
+	Topic   string
+}
+
+func collectorsRunTopic() string { return commandTopicRoot + "/collectors/run" }
+func reportTopic() string        { return commandTopicRoot + "/report" }
+func acknowledgeTopic() string   { return commandTopicRoot + "/alerts/acknowledge" }
+
+func deviceMuteTopic(deviceID string) string {
+	return fmt.Sprintf("%s/device/%s/mute", commandTopicRoot, deviceID)
+}
+
+func deviceResetStatusTopic(deviceID string) string {
+	return fmt.Sprintf("%s/device/%s/reset_status", commandTopicRoot, deviceID)
+}
+
+func zfsPoolMuteTopic(guid string) string {
+	return fmt.Sprintf("%s/zfs/%s/mute", commandTopicRoot, guid)
+}
+
+func mdadmArrayMuteTopic(uuid string) string {
+	return fmt.Sprintf("%s/mdadm/%s/mute", commandTopicRoot, uuid)
+}
+
+func btrfsFilesystemMuteTopic(uuid string) string {
+	return fmt.Sprintf("%s/btrfs/%s/mute", commandTopicRoot, uuid)
+}
+
+// deviceMutedTopic, zfsPoolMutedTopic, mdadmArrayMutedTopic and btrfsFilesystemMutedTopic hold
+// the ON/OFF state of the mute switches.
+func deviceMutedTopic(deviceID string) string {
+	return fmt.Sprintf("scrutiny/device/%s/muted", safeID(deviceID))
+}
+
+func zfsPoolMutedTopic(guid string) string {
+	return fmt.Sprintf("scrutiny/zfs/%s/muted", safeID(guid))
+}
+
+func mdadmArrayMutedTopic(uuid string) string {
+	return fmt.Sprintf("scrutiny/mdadm/%s/muted", safeID(uuid))
+}
+
+func btrfsFilesystemMutedTopic(uuid string) string {
+	return fmt.Sprintf("scrutiny/btrfs/%s/muted", safeID(uuid))
+}
+
+// ParseCommand parses a message received on a command topic.
+//
+//	scrutiny/command/collectors/run               any payload
+//	scrutiny/command/report                       daily (default), weekly or monthly
+//	scrutiny/command/alerts/acknowledge           an incident ID, or anything else for all open incidents
+//	scrutiny/command/device/<device_id>/mute      ON or OFF
+//	scrutiny/command/device/<device_id>/reset_status
+//	scrutiny/command/zfs/<guid>/mute              ON or OFF
+//	scrutiny/command/mdadm/<uuid>/mute            ON or OFF
+//	scrutiny/command/btrfs/<uuid>/mute            ON or OFF
+func ParseCommand(topic string, payload []byte) (Command, error) {
+	cmd := Command{Topic: topic, Payload: strings.TrimSpace(string(payload))}
+	if !strings.HasPrefix(topic, commandTopicRoot+"/") {
+		return cmd, fmt.Errorf("%s is not a command topic", topic)
+	}
+	parts := strings.Split(strings.TrimPrefix(topic, commandTopicRoot+"/"), "/")
+
+	switch {
+	case topic == collectorsRunTopic():
+		cmd.Name = CommandRunCollectors
+	case topic == reportTopic():
+		cmd.Name = CommandRequestReport
+		cmd.Target = strings.ToLower(cmd.Payload)
+		if cmd.Target == "" || cmd.Target == strings.ToLower(commandPayloadPress) {
+			cmd.Target = "daily"
+		}
+		if cmd.Target != "daily" && cmd.Target != "weekly" && cmd.Target != "monthly" {
+			return cmd, fmt.Errorf("invalid report period %q: must be daily, weekly or monthly", cmd.Payload)
+		}
+	case topic == acknowledgeTopic():
+		cmd.Name = CommandAcknowledgeAlerts
+		if _, err := strconv.ParseUint(cmd.Payload, 10, 0); err == nil {
+			cmd.Target = cmd.Payload
+		}
+	case len(parts) == 3 && parts[0] == "device" && parts[2] == "mute":
+		cmd.Name = CommandMuteDevice
+		cmd.Target = parts[1]
+		if err := validation.ValidateDeviceIdentifier(cmd.Target); err != nil {
+			return cmd, err
+		}
+		if err := validateSwitchPayload(cmd.Payload); err != nil {
+			return cmd, err
+		}
+	case len(parts) == 3 && parts[0] == "device" && parts[2] == "reset_status":
+		cmd.Name = CommandResetDeviceStatus
+		cmd.Target = parts[1]
+		if err := validation.ValidateDeviceIdentifier(cmd.Target); err != nil {
+			return cmd, err
+		}
+	case len(parts) == 3 && parts[0] == "zfs" && parts[2] == "mute":
+		cmd.Name = CommandMuteZFSPool
+		cmd.Target = parts[1]
+		if err := validation.ValidateGUID(cmd.Target); err != nil {
+			return cmd, err
+		}
+		if err := validateSwitchPayload(cmd.Payload); err != nil {
+			return cmd, err
+		}
+	case len(parts) == 3 && parts[0] == "mdadm" && parts[2] == "mute":
+		cmd.Name = CommandMuteMdadmArray
+		cmd.Target = parts[1]
+		// mdadm reports UUIDs in its own colon-separated format, so only require one
+		if cmd.Target == "" {
+			return cmd, fmt.Errorf("missing mdadm array UUID in %s", topic)
+		}
+		if err := validateSwitchPayload(cmd.Payload); err != nil {
+			return cmd, err
+		}
+	case len(parts) == 3 && parts[0] == "btrfs" && parts[2] == "mute":
+		cmd.Name = CommandMuteBtrfs
+		cmd.Target = parts[1]
+		if err := validation.ValidateUUID(cmd.Target); err != nil {
+			return cmd, err
+		}
+		if err := validateSwitchPayload(cmd.Payload); err != nil {
+			return cmd, err
+		}
+	default:
+		return cmd, fmt.Errorf("unknown command topic %s", topic)
+	}
+	return cmd, nil
+}
+
+func validateSwitchPayload(payload string) error {
+	if payload != commandPayloadOn && payload != commandPayloadOff {
+		return fmt.Errorf("invalid payload %q: must be %s or %s", payload, commandPayloadOn, commandPayloadOff)
+	}
+	return nil
+}
+
+// TopicAllowlist lists the command topics Scrutiny acts on, as MQTT topic filters that may use
+// the + and # wildcards. Commands on any other topic are rejected.
+type TopicAllowlist []string
+
+// Allows returns true if the topic matches one of the filters.
+func (a TopicAllowlist) Allows(topic string) bool {
+	for _, filter := range a {
+		if topicMatches(strings.TrimSpace(filter), topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// topicMatches matches a topic against an MQTT topic filter.
+func topicMatches(filter, topic string) bool {
+	if filter == "" {
+		return false
+	}
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		switch {
+		case level == "#":
+			return i == len(filterLevels)-1
+		case i >= len(topicLevels):
+			return false
+		case level != "+" && level != topicLevels[i]:
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+// commandEntity is a Home Assistant button or switch that publishes to a command topic.
+type commandEntity struct {
+	component    string // button or switch
+	entityID     string
+	name         string
+	commandTopic string
+	stateTopic   string // switches only
+	payloadPress string // buttons only
+	icon         string
+}
+
+// serverDeviceInfo is the Scrutiny device that the drives, pools and arrays are connected via.
+func serverDeviceInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"identifiers":  []string{"scrutiny"},
+		"name":         "Scrutiny",
+		"manufacturer": "Scrutiny",
+		"model":        "Scrutiny",
+	}
+}
+
+func serverCommandEntities() []commandEntity {
+	return []commandEntity{
+		{component: "button", entityID: "run_collectors", name: "Run Collectors", commandTopic: collectorsRunTopic(), payloadPress: commandPayloadPress, icon: "mdi:play"},
+		{component: "button", entityID: "request_report", name: "Send Daily Report", commandTopic: reportTopic(), payloadPress: "daily", icon: "mdi:file-chart"},
+		{component: "button", entityID: "acknowledge_alerts", name: "Acknowledge Alerts", commandTopic: acknowledgeTopic(), payloadPress: commandPayloadPress, icon: "mdi:bell-check"},
+	}
+}
+
+func deviceCommandEntities(deviceID string) []commandEntity {
+	return []commandEntity{
+		{component: "switch", entityID: "mute", name: "Mute Notifications", commandTopic: deviceMuteTopic(deviceID), stateTopic: deviceMutedTopic(deviceID), icon: "mdi:bell-off"},
+		{component: "button", entityID: "reset_status", name: "Reset Status", commandTopic: deviceResetStatusTopic(deviceID), payloadPress: commandPayloadPress, icon: "mdi:restore"},
+	}
+}
+
+func zfsPoolCommandEntities(guid string) []commandEntity {
+	return []commandEntity{
+		{component: "switch", entityID: "mute", name: "Mute Notifications", commandTopic: zfsPoolMuteTopic(guid), stateTopic: zfsPoolMutedTopic(guid), icon: "mdi:bell-off"},
+	}
+}
+
+func mdadmArrayCommandEntities(uuid string) []commandEntity {
+	return []commandEntity{
+		{component: "switch", entityID: "mute", name: "Mute Notifications", commandTopic: mdadmArrayMuteTopic(uuid), stateTopic: mdadmArrayMutedTopic(uuid), icon: "mdi:bell-off"},
+	}
+}
+
+func btrfsFilesystemCommandEntities(uuid string) []commandEntity {
+	return []commandEntity{
+		{component: "switch", entityID: "mute", name: "Mute Notifications", commandTopic: btrfsFilesystemMuteTopic(uuid), stateTopic: btrfsFilesystemMutedTopic(uuid), icon: "mdi:bell-off"},
+	}
+}
+
+// storageCommandEntities returns the command entities of a storage entity, and the state topic
+// of its mute switch. Mounted filesystems have none.
+func storageCommandEntities(kind, id string) ([]commandEntity, string) {
+	switch kind {
+	case StorageKindZFSPool:
+		return zfsPoolCommandEntities(id), zfsPoolMutedTopic(id)
+	case StorageKindMDADMArray:
+		return mdadmArrayCommandEntities(id), mdadmArrayMutedTopic(id)
+	case StorageKindBtrfs:
+		return btrfsFilesystemCommandEntities(id), btrfsFilesystemMutedTopic(id)
+	}
+	return nil, ""
+}
+
+// buildCommandDiscoveryMessages generates discovery messages for the entities whose command
+// topic is allowed, and removal messages for the others.
+func buildCommandDiscoveryMessages(topicPrefix, safeDeviceID string, devInfo map[string]interface{}, entities []commandEntity, allowlist TopicAllowlist) []DiscoveryMessage {
+	messages := make([]DiscoveryMessage, 0, len(entities))
+	for _, entity := range entities {
+		topic := fmt.Sprintf("%s/%s/scrutiny/%s_%s/config", topicPrefix, entity.component, safeDeviceID, entity.entityID)
+		if !allowlist.Allows(entity.commandTopic) {
+			messages = append(messages, DiscoveryMessage{Topic: topic, Payload: ""})
+			continue
+		}
+
+		id := fmt.Sprintf(entityIDFormat, safeDeviceID, entity.entityID)
+		payload := map[string]interface{}{
+			"name":               entity.name,
+			"unique_id":          id,
+			"default_entity_id":  fmt.Sprintf("%s.%s", entity.component, id),
+			"command_topic":      entity.commandTopic,
+			"availability_topic": availabilityTopic,
+			"device":             devInfo,
+			"icon":               entity.icon,
+			"entity_category":    "config",
+		}
+		if entity.component == "switch" {
+			payload["state_topic"] = entity.stateTopic
+			payload["payload_on"] = commandPayloadOn
+			payload["payload_off"] = commandPayloadOff
+		} else {
+			payload["payload_press"] = entity.payloadPress
+		}
+
+		payloadJSON, _ := json.Marshal(payload)
+		messages = append(messages, DiscoveryMessage{Topic: topic, Payload: string(payloadJSON)})
+	}
+	return messages
+}
+
+// buildCommandRemoveMessages generates empty-payload messages to remove command entities.
+func buildCommandRemoveMessages(topicPrefix, safeDeviceID string, entities []commandEntity) []DiscoveryMessage {
+	return buildCommandDiscoveryMessages(topicPrefix, safeDeviceID, nil, entities, nil)
+}
@@ -0,0 +1,234 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const testDeviceID = "d290f1ee-6c54-4b01-90e6-d701748f0851"
+
+func TestParseCommand(t *testing.T) {
+	cmd, err := ParseCommand("scrutiny/command/collectors/run", []byte("PRESS"))
+	require.NoError(t, err)
+	require.Equal(t, CommandRunCollectors, cmd.Name)
+
+	cmd, err = ParseCommand("scrutiny/command/report", []byte("PRESS"))
+	require.NoError(t, err)
+	require.Equal(t, CommandRequestReport, cmd.Name)
+	require.Equal(t, "daily", cmd.Target)
+
+	cmd, err = ParseCommand("scrutiny/command/report", []byte("Weekly"))
+	require.NoError(t, err)
+	require.Equal(t, "weekly", cmd.Target)
+
+	_, err = ParseCommand("scrutiny/command/report", []byte("yearly"))
+	require.Error(t, err)
+
+	cmd, err = ParseCommand("scrutiny/command/alerts/acknowledge", []byte("42"))
+	require.NoError(t, err)
+	require.Equal(t, CommandAcknowledgeAlerts, cmd.Name)
+	require.Equal(t, "42", cmd.Target)
+
+	cmd, err = ParseCommand("scrutiny/command/alerts/acknowledge", []byte("PRESS"))
+	require.NoError(t, err)
+	require.Empty(t, cmd.Target, "acknowledges all open incidents")
+
+	cmd, err = ParseCommand("scrutiny/command/device/"+testDeviceID+"/mute", []byte("ON"))
+	require.NoError(t, err)
+	require.Equal(t, CommandMuteDevice, cmd.Name)
+	require.Equal(t, testDeviceID, cmd.Target)
+
+	_, err = ParseCommand("scrutiny/command/device/"+testDeviceID+"/mute", []byte("maybe"))
+	require.Error(t, err)
+
+	cmd, err = ParseCommand("scrutiny/command/device/"+testDeviceID+"/reset_status", nil)
+	require.NoError(t, err)
+	require.Equal(t, CommandResetDeviceStatus, cmd.Name)
+
+	_, err = ParseCommand("scrutiny/command/device/not a device/reset_status", nil)
+	require.Error(t, err)
+
+	cmd, err = ParseCommand("scrutiny/command/zfs/1234567890/mute", []byte("OFF"))
+	require.NoError(t, err)
+	require.Equal(t, CommandMuteZFSPool, cmd.Name)
+	require.Equal(t, "1234567890", cmd.Target)
+
+	cmd, err = ParseCommand("scrutiny/command/mdadm/a1b2c3d4:e5f6a7b8:c9d0e1f2:a3b4c5d6/mute", []byte("ON"))
+	require.NoError(t, err)
+	require.Equal(t, CommandMuteMdadmArray, cmd.Name)
+	require.Equal(t, "a1b2c3d4:e5f6a7b8:c9d0e1f2:a3b4c5d6", cmd.Target)
+
+	cmd, err = ParseCommand("scrutiny/command/btrfs/"+testDeviceID+"/mute", []byte("OFF"))
+	require.NoError(t, err)
+	require.Equal(t, CommandMuteBtrfs, cmd.Name)
+	require.Equal(t, testDeviceID, cmd.Target)
+
+	_, err = ParseCommand("scrutiny/command/btrfs/not-a-uuid/mute", []byte("OFF"))
+	require.Error(t, err)
+	_, err = ParseCommand("scrutiny/command/mdadm//mute", []byte("OFF"))
+	require.Error(t, err)
+
+	_, err = ParseCommand("scrutiny/command/device/"+testDeviceID+"/delete", nil)
+	require.Error(t, err)
+	_, err = ParseCommand("scrutiny/device/"+testDeviceID+"/state", nil)
+	require.Error(t, err)
+}
+
+func TestTopicAllowlist_Allows(t *testing.T) {
+	allowlist := TopicAllowlist{"scrutiny/command/collectors/run", "scrutiny/command/device/+/mute", "scrutiny/command/alerts/#"}
+
+	require.True(t, allowlist.Allows("scrutiny/command/collectors/run"))
+	require.True(t, allowlist.Allows("scrutiny/command/device/"+testDeviceID+"/mute"))
+	require.True(t, allowlist.Allows("scrutiny/command/alerts/acknowledge"))
+	require.False(t, allowlist.Allows("scrutiny/command/device/"+testDeviceID+"/reset_status"))
+	require.False(t, allowlist.Allows("scrutiny/command/report"))
+	require.False(t, allowlist.Allows("scrutiny/command/collectors/run/now"))
+
+	require.True(t, TopicAllowlist{"scrutiny/command/#"}.Allows("scrutiny/command/zfs/1234567890/mute"))
+	require.True(t, TopicAllowlist{"#"}.Allows("scrutiny/command/report"))
+	require.False(t, TopicAllowlist{}.Allows("scrutiny/command/report"))
+	require.False(t, TopicAllowlist{""}.Allows("scrutiny/command/report"))
+}
+
+func TestStorageCommandEntities_MuteSwitches(t *testing.T) {
+	allowlist := TopicAllowlist{"scrutiny/command/+/+/mute"}
+	for _, entity := range []storageEntity{
+		{kind: StorageKindMDADMArray, id: "a1b2c3d4:e5f6a7b8", name: "md0", model: "RAID1"},
+		{kind: StorageKindBtrfs, id: testDeviceID, name: "data", model: "Btrfs filesystem"},
+	} {
+		entities, mutedTopic := storageCommandEntities(entity.kind, entity.id)
+		messages := buildCommandDiscoveryMessages("homeassistant", entity.safe(), entity.deviceInfo(), entities, allowlist)
+		require.Len(t, messages, 1)
+		require.Equal(t, "homeassistant/switch/scrutiny/"+entity.safe()+"_mute/config", messages[0].Topic)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(messages[0].Payload), &payload))
+		require.Equal(t, fmt.Sprintf("scrutiny/command/%s/%s/mute", entity.kind, entity.id), payload["command_topic"])
+		require.Equal(t, mutedTopic, payload["state_topic"])
+		require.Equal(t, fmt.Sprintf("scrutiny/%s/%s/muted", entity.kind, safeID(entity.id)), mutedTopic)
+	}
+
+	entities, _ := storageCommandEntities(StorageKindFilesystem, "/mnt/data")
+	require.Empty(t, entities, "mounted filesystems have no commands")
+}
+
+func TestBuildCommandDiscoveryMessages_OnlyAllowedCommands(t *testing.T) {
+	allowlist := TopicAllowlist{"scrutiny/command/device/+/mute"}
+	messages := buildCommandDiscoveryMessages("homeassistant", safeID(testDeviceID), deviceInfo(testDevice()), deviceCommandEntities(testDeviceID), allowlist)
+	require.Len(t, messages, 2)
+
+	safe := "d290f1ee6c544b0190e6d701748f0851"
+	require.Equal(t, "homeassistant/switch/scrutiny/"+safe+"_mute/config", messages[0].Topic)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(messages[0].Payload), &payload))
+	require.Equal(t, "scrutiny/command/device/"+testDeviceID+"/mute", payload["command_topic"])
+	require.Equal(t, "scrutiny/device/"+safe+"/muted", payload["state_topic"])
+	require.Equal(t, "ON", payload["payload_on"])
+
+	// the reset button is not allowed, so it is removed
+	require.Equal(t, "homeassistant/button/scrutiny/"+safe+"_reset_status/config", messages[1].Topic)
+	require.Empty(t, messages[1].Payload)
+}
+
+type fakeCommandActions struct {
+	calls []string
+}
+
+func (f *fakeCommandActions) RunCollectors(context.Context) error {
+	f.calls = append(f.calls, "run_collectors")
+	return nil
+}
+
+func (f *fakeCommandActions) RequestReport(_ context.Context, period string) error {
+	f.calls = append(f.calls, "report "+period)
+	return nil
+}
+
+func (f *fakeCommandActions) AcknowledgeAlerts(_ context.Context, incidentID uint) (int, error) {
+	f.calls = append(f.calls, fmt.Sprintf("acknowledge %d", incidentID))
+	return 1, nil
+}
+
+func (f *fakeCommandActions) SetDeviceMuted(_ context.Context, deviceID string, muted bool) error {
+	if muted {
+		f.calls = append(f.calls, "mute "+deviceID)
+	} else {
+		f.calls = append(f.calls, "unmute "+deviceID)
+	}
+	return nil
+}
+
+func (f *fakeCommandActions) ResetDeviceStatus(_ context.Context, deviceID string) error {
+	f.calls = append(f.calls, "reset "+deviceID)
+	return nil
+}
+
+func (f *fakeCommandActions) SetZFSPoolMuted(_ context.Context, guid string, muted bool) error {
+	f.calls = append(f.calls, "mute pool "+guid)
+	return nil
+}
+
+func (f *fakeCommandActions) SetMdadmArrayMuted(_ context.Context, uuid string, muted bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("mute array %s %t", uuid, muted))
+	return nil
+}
+
+func (f *fakeCommandActions) SetBtrfsFilesystemMuted(_ context.Context, uuid string, muted bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("mute filesystem %s %t", uuid, muted))
+	return nil
+}
+
+func TestExecuteCommand_DispatchesToActions(t *testing.T) {
+	logger := logrus.WithField("test", t.Name())
+	// never connected, so the switch state publishes fail without blocking
+	p := &Publisher{client: NewClient(&ClientConfig{Broker: "tcp://127.0.0.1:1"}, logger), logger: logger}
+	actions := &fakeCommandActions{}
+	p.actions = actions
+
+	for _, msg := range []struct{ topic, payload string }{
+		{"scrutiny/command/collectors/run", "PRESS"},
+		{"scrutiny/command/report", "monthly"},
+		{"scrutiny/command/alerts/acknowledge", "7"},
+		{"scrutiny/command/device/" + testDeviceID + "/mute", "OFF"},
+		{"scrutiny/command/device/" + testDeviceID + "/reset_status", "PRESS"},
+		{"scrutiny/command/zfs/1234567890/mute", "ON"},
+		{"scrutiny/command/mdadm/md-uuid/mute", "ON"},
+		{"scrutiny/command/btrfs/" + testDeviceID + "/mute", "OFF"},
+	} {
+		cmd, err := ParseCommand(msg.topic, []byte(msg.payload))
+		require.NoError(t, err)
+		p.executeCommand(cmd)
+	}
+
+	require.Equal(t, []string{
+		"run_collectors",
+		"report monthly",
+		"acknowledge 7",
+		"unmute " + testDeviceID,
+		"reset " + testDeviceID,
+		"mute pool 1234567890",
+		"mute array md-uuid true",
+		"mute filesystem " + testDeviceID + " false",
+	}, actions.calls)
+}
+
+func TestHandleCommand_RejectsTopicsNotInAllowlist(t *testing.T) {
+	logger := logrus.WithField("test", t.Name())
+	p := &Publisher{client: NewClient(&ClientConfig{Broker: "tcp://127.0.0.1:1"}, logger), logger: logger}
+	actions := &fakeCommandActions{}
+	p.actions = actions
+	p.allowlist = TopicAllowlist{"scrutiny/command/collectors/run"}
+
+	p.handleCommand("scrutiny/command/device/"+testDeviceID+"/reset_status", []byte("PRESS"))
+	p.handleCommand("scrutiny/command/report", []byte("daily"))
+	require.Empty(t, actions.calls)
+
+	// without actions, e.g. before EnableCommands, nothing is allowed
+	p.actions = nil
+	require.False(t, p.commandAllowlist().Allows("scrutiny/command/collectors/run"))
+}
@@ -93,7 +93,10 @@ func BuildDiscoveryMessages(device *models.Device, topicPrefix string) []Discove
 			ValueTemplate: "{{ value_json.power_cycle_count }}",
 			Icon:          "mdi:restart",
 		}),
-		buildBinarySensorDiscovery(topicPrefix, safe, "problem", devInfo, st),
+		buildBinarySensorDiscovery(topicPrefix, safe, "problem", devInfo, st, &sensorConfig{
+			Name:          "Drive Problem",
+			ValueTemplate: "{{ value_json.problem }}",
+		}),
 	}
 
 	return messages
@@ -183,15 +186,15 @@ func buildSensorDiscovery(topicPrefix, safeDeviceID, entityID string, devInfo ma
 	}
 }
 
-func buildBinarySensorDiscovery(topicPrefix, safeDeviceID, entityID string, devInfo map[string]interface{}, st string) DiscoveryMessage {
+func buildBinarySensorDiscovery(topicPrefix, safeDeviceID, entityID string, devInfo map[string]interface{}, st string, cfg *sensorConfig) DiscoveryMessage {
 	id := fmt.Sprintf(entityIDFormat, safeDeviceID, entityID)
 
 	payload := map[string]interface{}{
-		"name":               "Drive Problem",
+		"name":               cfg.Name,
 		"unique_id":          id,
 		"default_entity_id":  fmt.Sprintf("binary_sensor.%s", id),
 		"state_topic":        st,
-		"value_template":     "{{ value_json.problem }}",
+		"value_template":     cfg.ValueTemplate,
 		"payload_on":         "ON",
 		"payload_off":        "OFF",
 		"device_class":       "problem",
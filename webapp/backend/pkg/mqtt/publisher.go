@@ -23,6 +23,10 @@ type Publisher struct {
 	topicPrefix string
 	retain      bool
 	mu          sync.RWMutex
+
+	// set by EnableCommands
+	actions   CommandActions
+	allowlist TopicAllowlist
 }
 
 // NewPublisher creates a new MQTT publisher.
@@ -68,6 +72,7 @@ func (p *Publisher) PublishDiscovery(device *models.Device) {
 			p.logger.Warnf("MQTT: failed to publish discovery for %s: %v", device.DeviceID, err)
 		}
 	}
+	p.publishDeviceCommands(device)
 	p.logger.Debugf("MQTT: published discovery for device %s (%s)", device.DeviceID, device.ModelName)
 }
 
@@ -111,6 +116,15 @@ func (p *Publisher) RemoveDevice(device *models.Device) {
 		}
 	}
 
+	// Remove the command buttons and switches, whether or not commands are enabled
+	messages = buildCommandRemoveMessages(p.topicPrefix, safeID(device.DeviceID), deviceCommandEntities(device.DeviceID))
+	for _, msg := range messages {
+		if err := p.client.Publish(msg.Topic, msg.Payload, true); err != nil {
+			p.logger.Warnf("MQTT: failed to remove discovery for %s: %v", device.DeviceID, err)
+		}
+	}
+	_ = p.client.Publish(deviceMutedTopic(device.DeviceID), "", true)
+
 	// Clear the DeviceID-based state topic
 	topic := stateTopic(device.DeviceID)
 	if err := p.client.Publish(topic, "", true); err != nil {
@@ -142,6 +156,8 @@ func (p *Publisher) SyncAllDevices(deviceRepo database.DeviceRepo, ctx context.C
 		return 0, 0, fmt.Errorf("MQTT: failed to load device summary: %w", err)
 	}
 
+	p.publishServerCommands()
+
 	// Clean up legacy WWN-based topics for all devices
 	cleaned := 0
 	for _, deviceSummary := range summary {
@@ -178,7 +194,8 @@ func (p *Publisher) SyncAllDevices(deviceRepo database.DeviceRepo, ctx context.C
 	return published, cleaned, nil
 }
 
-// LoadInitialData publishes discovery and state for all active devices from the database.
+// LoadInitialData publishes discovery and state for all active devices, pools, arrays and
+// filesystems from the database.
 // On startup, it also cleans up old WWN-based MQTT topics by publishing empty payloads.
 func (p *Publisher) LoadInitialData(deviceRepo database.DeviceRepo, ctx context.Context) error {
 	start := time.Now()
@@ -189,7 +206,13 @@ func (p *Publisher) LoadInitialData(deviceRepo database.DeviceRepo, ctx context.
 		return err
 	}
 
-	p.logger.Infof("MQTT: synced %d devices, cleaned %d legacy topics in %v", published, cleaned, time.Since(start))
+	storagePublished, err := p.SyncStorage(deviceRepo, ctx)
+	if err != nil {
+		// the drives are already published, storage is picked up again on the next upload
+		p.logger.Warnf("%v", err)
+	}
+
+	p.logger.Infof("MQTT: synced %d devices and %d storage entities, cleaned %d legacy topics in %v", published, storagePublished, cleaned, time.Since(start))
 	return nil
 }
 
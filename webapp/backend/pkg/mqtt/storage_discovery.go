@@ -0,0 +1,318 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+)
+
+// Kinds of storage entities published next to the drives.
+const (
+	StorageKindZFSPool    = "zfs"
+	StorageKindMDADMArray = "mdadm"
+	StorageKindBtrfs      = "btrfs"
+	StorageKindFilesystem = "filesystem"
+)
+
+// storageEntity is a ZFS pool, mdadm array, Btrfs filesystem or mounted filesystem published to
+// Home Assistant as its own device, with the sensors listed by storageSensors.
+type storageEntity struct {
+	kind  string
+	id    string // pool GUID, array or filesystem UUID, or host and mount point
+	name  string
+	model string
+	host  string
+}
+
+// safe returns the identifier used in discovery topics and unique IDs, e.g. zfs_1234.
+func (e storageEntity) safe() string {
+	return e.kind + "_" + safeID(e.id)
+}
+
+// stateTopic returns the MQTT topic for the entity's state updates.
+func (e storageEntity) stateTopic() string {
+	return fmt.Sprintf("scrutiny/%s/%s/state", e.kind, safeID(e.id))
+}
+
+func (e storageEntity) deviceInfo() map[string]interface{} {
+	name := e.name
+	if e.host != "" {
+		name = fmt.Sprintf("%s (%s)", e.name, e.host)
+	}
+	return map[string]interface{}{
+		"identifiers":  []string{fmt.Sprintf("scrutiny_%s", e.safe())},
+		"name":         name,
+		"manufacturer": "Scrutiny",
+		"model":        e.model,
+		"via_device":   "scrutiny",
+	}
+}
+
+// storageSensor is a sensor, or a problem binary sensor, of a storage entity.
+type storageSensor struct {
+	entityID string
+	binary   bool
+	config   sensorConfig
+}
+
+// storageSensors lists the sensors published for each kind of storage entity. Discovery and
+// removal messages are both built from it, so removal always covers every published sensor.
+var storageSensors = map[string][]storageSensor{
+	StorageKindZFSPool: {
+		{entityID: "status", config: sensorConfig{Name: "Status", ValueTemplate: "{{ value_json.status }}", Icon: "mdi:database"}},
+		{entityID: "capacity", config: sensorConfig{Name: "Capacity Used", UnitOfMeasure: "%", StateClass: "measurement", ValueTemplate: "{{ value_json.capacity_percent }}", Icon: "mdi:chart-donut"}},
+		{entityID: "scrub_state", config: sensorConfig{Name: "Scrub State", ValueTemplate: "{{ value_json.scrub_state }}", Icon: "mdi:magnify-scan", EntityCategory: "diagnostic"}},
+		{entityID: "errors", config: sensorConfig{Name: "Errors", StateClass: "measurement", ValueTemplate: "{{ value_json.errors }}", Icon: "mdi:alert-octagon"}},
+		{entityID: "problem", binary: true, config: sensorConfig{Name: "Pool Problem", ValueTemplate: "{{ value_json.problem }}"}},
+	},
+	StorageKindMDADMArray: {
+		{entityID: "state", config: sensorConfig{Name: "State", ValueTemplate: "{{ value_json.state }}", Icon: "mdi:database"}},
+		{entityID: "sync_progress", config: sensorConfig{Name: "Sync Progress", UnitOfMeasure: "%", StateClass: "measurement", ValueTemplate: "{{ value_json.sync_progress }}", Icon: "mdi:sync"}},
+		{entityID: "failed_devices", config: sensorConfig{Name: "Failed Devices", StateClass: "measurement", ValueTemplate: "{{ value_json.failed_devices }}", Icon: "mdi:harddisk-remove"}},
+		{entityID: "degraded", binary: true, config: sensorConfig{Name: "Array Degraded", ValueTemplate: "{{ value_json.degraded }}"}},
+	},
+	StorageKindBtrfs: {
+		{entityID: "status", config: sensorConfig{Name: "Status", ValueTemplate: "{{ value_json.status }}", Icon: "mdi:database"}},
+		{entityID: "usage", config: sensorConfig{Name: "Usage", UnitOfMeasure: "%", StateClass: "measurement", ValueTemplate: "{{ value_json.used_percent }}", Icon: "mdi:chart-donut"}},
+		{entityID: "device_errors", config: sensorConfig{Name: "Device Errors", StateClass: "measurement", ValueTemplate: "{{ value_json.device_errors }}", Icon: "mdi:alert-octagon"}},
+		{entityID: "problem", binary: true, config: sensorConfig{Name: "Filesystem Problem", ValueTemplate: "{{ value_json.problem }}"}},
+	},
+	StorageKindFilesystem: {
+		{entityID: "usage", config: sensorConfig{Name: "Usage", UnitOfMeasure: "%", StateClass: "measurement", ValueTemplate: "{{ value_json.used_percent }}", Icon: "mdi:chart-donut"}},
+		{entityID: "available", config: sensorConfig{Name: "Available", DeviceClass: "data_size", UnitOfMeasure: "B", StateClass: "measurement", ValueTemplate: "{{ value_json.available_bytes }}", Icon: "mdi:harddisk"}},
+	},
+}
+
+func buildStorageDiscoveryMessages(entity storageEntity, topicPrefix string) []DiscoveryMessage {
+	devInfo := entity.deviceInfo()
+	st := entity.stateTopic()
+	messages := []DiscoveryMessage{}
+	for _, sensor := range storageSensors[entity.kind] {
+		cfg := sensor.config
+		if sensor.binary {
+			messages = append(messages, buildBinarySensorDiscovery(topicPrefix, entity.safe(), sensor.entityID, devInfo, st, &cfg))
+			continue
+		}
+		messages = append(messages, buildSensorDiscovery(topicPrefix, entity.safe(), sensor.entityID, devInfo, st, &cfg))
+	}
+	return messages
+}
+
+func buildStorageRemoveMessages(entity storageEntity, topicPrefix string) []DiscoveryMessage {
+	messages := []DiscoveryMessage{}
+	for _, sensor := range storageSensors[entity.kind] {
+		component := "sensor"
+		if sensor.binary {
+			component = "binary_sensor"
+		}
+		messages = append(messages, DiscoveryMessage{
+			Topic:   fmt.Sprintf("%s/%s/scrutiny/%s_%s/config", topicPrefix, component, entity.safe(), sensor.entityID),
+			Payload: "",
+		})
+	}
+	return messages
+}
+
+func zfsPoolEntity(pool *models.ZFSPool) storageEntity {
+	name := pool.Label
+	if name == "" {
+		name = "ZFS pool " + pool.Name
+	}
+	return storageEntity{kind: StorageKindZFSPool, id: pool.GUID, name: name, model: "ZFS pool", host: pool.HostID}
+}
+
+func mdadmArrayEntity(array *models.MDADMArray) storageEntity {
+	name := array.Label
+	if name == "" {
+		name = "mdadm array " + array.Name
+	}
+	model := "mdadm array"
+	if array.Level != "" {
+		model = fmt.Sprintf("mdadm %s", array.Level)
+	}
+	return storageEntity{kind: StorageKindMDADMArray, id: array.UUID, name: name, model: model, host: array.HostID}
+}
+
+func btrfsFilesystemEntity(filesystem *models.BtrfsFilesystem) storageEntity {
+	name := filesystem.Label
+	switch {
+	case name != "":
+	case filesystem.MountPoint != "":
+		name = "Btrfs " + filesystem.MountPoint
+	default:
+		name = "Btrfs " + filesystem.UUID
+	}
+	return storageEntity{kind: StorageKindBtrfs, id: filesystem.UUID, name: name, model: "Btrfs filesystem", host: filesystem.HostID}
+}
+
+func filesystemEntity(filesystem *models.FilesystemCapacity) storageEntity {
+	mount := strings.Trim(filesystem.MountPoint, "/")
+	if mount == "" {
+		mount = "root"
+	}
+	return storageEntity{
+		kind:  StorageKindFilesystem,
+		id:    slugID(filesystem.HostID + "_" + mount),
+		name:  "Filesystem " + filesystem.MountPoint,
+		model: filesystem.FilesystemType,
+		host:  filesystem.HostID,
+	}
+}
+
+// slugID turns an arbitrary string, e.g. a host and mount point, into a topic-safe identifier.
+func slugID(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func onOff(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// ZFSPoolStatePayload represents the JSON state published for a ZFS pool.
+type ZFSPoolStatePayload struct {
+	Status               string  `json:"status"`
+	Problem              string  `json:"problem"`
+	ScrubState           string  `json:"scrub_state"`
+	LastUpdated          string  `json:"last_updated"`
+	CapacityPercent      float64 `json:"capacity_percent"`
+	ScrubPercentComplete float64 `json:"scrub_percent_complete"`
+	Size                 int64   `json:"size"`
+	Allocated            int64   `json:"allocated"`
+	Free                 int64   `json:"free"`
+	Errors               int64   `json:"errors"`
+	ReadErrors           int64   `json:"read_errors"`
+	WriteErrors          int64   `json:"write_errors"`
+	ChecksumErrors       int64   `json:"checksum_errors"`
+	ScrubErrors          int64   `json:"scrub_errors"`
+	Fragmentation        int     `json:"fragmentation"`
+}
+
+func buildZFSPoolStatePayload(pool *models.ZFSPool) ZFSPoolStatePayload {
+	return ZFSPoolStatePayload{
+		Status:               string(pool.Status),
+		Problem:              onOff(!pool.IsHealthy()),
+		ScrubState:           string(pool.ScrubState),
+		LastUpdated:          lastUpdated(pool.UpdatedAt),
+		CapacityPercent:      pool.CapacityPercent,
+		ScrubPercentComplete: pool.ScrubPercentComplete,
+		Size:                 pool.Size,
+		Allocated:            pool.Allocated,
+		Free:                 pool.Free,
+		Errors:               pool.TotalReadErrors + pool.TotalWriteErrors + pool.TotalChecksumErrors,
+		ReadErrors:           pool.TotalReadErrors,
+		WriteErrors:          pool.TotalWriteErrors,
+		ChecksumErrors:       pool.TotalChecksumErrors,
+		ScrubErrors:          pool.ScrubErrorsCount,
+		Fragmentation:        pool.Fragmentation,
+	}
+}
+
+// MDADMArrayStatePayload represents the JSON state published for an mdadm array.
+type MDADMArrayStatePayload struct {
+	State          string  `json:"state"`
+	Degraded       string  `json:"degraded"`
+	LastUpdated    string  `json:"last_updated"`
+	SyncProgress   float64 `json:"sync_progress"`
+	ActiveDevices  int     `json:"active_devices"`
+	WorkingDevices int     `json:"working_devices"`
+	FailedDevices  int     `json:"failed_devices"`
+	SpareDevices   int     `json:"spare_devices"`
+}
+
+func buildMDADMArrayStatePayload(metrics *collector.MDADMMetrics) MDADMArrayStatePayload {
+	degraded := metrics.FailedDevices > 0 || strings.Contains(strings.ToLower(metrics.State), "degraded")
+	return MDADMArrayStatePayload{
+		State:          metrics.State,
+		Degraded:       onOff(degraded),
+		LastUpdated:    lastUpdated(metrics.UpdatedAt),
+		SyncProgress:   metrics.SyncProgress,
+		ActiveDevices:  metrics.ActiveDevices,
+		WorkingDevices: metrics.WorkingDevices,
+		FailedDevices:  metrics.FailedDevices,
+		SpareDevices:   metrics.SpareDevices,
+	}
+}
+
+// BtrfsFilesystemStatePayload represents the JSON state published for a Btrfs filesystem.
+type BtrfsFilesystemStatePayload struct {
+	Status         string  `json:"status"`
+	Problem        string  `json:"problem"`
+	ScrubState     string  `json:"scrub_state"`
+	LastUpdated    string  `json:"last_updated"`
+	UsedPercent    float64 `json:"used_percent"`
+	Size           int64   `json:"size"`
+	Used           int64   `json:"used"`
+	FreeEstimated  int64   `json:"free_estimated"`
+	DeviceErrors   int64   `json:"device_errors"`
+	MissingDevices int64   `json:"missing_devices"`
+	ScrubErrors    int64   `json:"scrub_errors"`
+}
+
+func buildBtrfsFilesystemStatePayload(filesystem *models.BtrfsFilesystem) BtrfsFilesystemStatePayload {
+	deviceErrors := int64(0)
+	for _, device := range filesystem.Devices {
+		deviceErrors += device.ReadIOErrors + device.WriteIOErrors + device.FlushIOErrors + device.CorruptionErrors + device.GenerationErrors
+	}
+	usedPercent := 0.0
+	if filesystem.DeviceSize > 0 {
+		usedPercent = float64(filesystem.Used) / float64(filesystem.DeviceSize) * 100
+	}
+	return BtrfsFilesystemStatePayload{
+		Status:         string(filesystem.Status),
+		Problem:        onOff(!filesystem.IsHealthy() || filesystem.HasErrors() || deviceErrors > 0),
+		ScrubState:     string(filesystem.ScrubState),
+		LastUpdated:    lastUpdated(filesystem.UpdatedAt),
+		UsedPercent:    usedPercent,
+		Size:           filesystem.DeviceSize,
+		Used:           filesystem.Used,
+		FreeEstimated:  filesystem.FreeEstimated,
+		DeviceErrors:   deviceErrors,
+		MissingDevices: filesystem.DeviceMissing,
+		ScrubErrors:    filesystem.ScrubReadErrors + filesystem.ScrubCsumErrors + filesystem.ScrubVerifyErrors + filesystem.ScrubSuperErrors,
+	}
+}
+
+// FilesystemStatePayload represents the JSON state published for a mounted filesystem.
+type FilesystemStatePayload struct {
+	FilesystemType string  `json:"filesystem_type"`
+	SourceDevice   string  `json:"source_device"`
+	LastUpdated    string  `json:"last_updated"`
+	UsedPercent    float64 `json:"used_percent"`
+	TotalBytes     int64   `json:"total_bytes"`
+	UsedBytes      int64   `json:"used_bytes"`
+	AvailableBytes int64   `json:"available_bytes"`
+}
+
+func buildFilesystemStatePayload(filesystem *models.FilesystemCapacity) FilesystemStatePayload {
+	return FilesystemStatePayload{
+		FilesystemType: filesystem.FilesystemType,
+		SourceDevice:   filesystem.SourceDevice,
+		LastUpdated:    lastUpdated(filesystem.UpdatedAt),
+		UsedPercent:    filesystem.UsedPercent,
+		TotalBytes:     filesystem.TotalBytes,
+		UsedBytes:      filesystem.UsedBytes,
+		AvailableBytes: filesystem.AvailableBytes,
+	}
+}
+
+// lastUpdated formats an update time, using the current time for records not saved yet.
+func lastUpdated(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Format(time.RFC3339)
+}
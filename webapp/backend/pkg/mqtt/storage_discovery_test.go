@@ -0,0 +1,108 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStorageDiscoveryMessages_ZFSPool(t *testing.T) {
+	pool := &models.ZFSPool{GUID: "1234567890", Name: "tank", HostID: "nas"}
+	messages := buildStorageDiscoveryMessages(zfsPoolEntity(pool), "homeassistant")
+
+	expectedTopics := []string{
+		"homeassistant/sensor/scrutiny/zfs_1234567890_status/config",
+		"homeassistant/sensor/scrutiny/zfs_1234567890_capacity/config",
+		"homeassistant/sensor/scrutiny/zfs_1234567890_scrub_state/config",
+		"homeassistant/sensor/scrutiny/zfs_1234567890_errors/config",
+		"homeassistant/binary_sensor/scrutiny/zfs_1234567890_problem/config",
+	}
+	require.Len(t, messages, len(expectedTopics))
+	for i, msg := range messages {
+		require.Equal(t, expectedTopics[i], msg.Topic, "topic mismatch at index %d", i)
+	}
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(messages[4].Payload), &payload))
+	require.Equal(t, "Pool Problem", payload["name"])
+	require.Equal(t, "scrutiny_zfs_1234567890_problem", payload["unique_id"])
+	require.Equal(t, "scrutiny/zfs/1234567890/state", payload["state_topic"])
+	require.Equal(t, "problem", payload["device_class"])
+
+	dev := payload["device"].(map[string]interface{})
+	require.Equal(t, "ZFS pool tank (nas)", dev["name"])
+	require.Equal(t, []interface{}{"scrutiny_zfs_1234567890"}, dev["identifiers"])
+}
+
+func TestBuildStorageRemoveMessages_MatchDiscovery(t *testing.T) {
+	entities := []storageEntity{
+		zfsPoolEntity(&models.ZFSPool{GUID: "1234567890"}),
+		mdadmArrayEntity(&models.MDADMArray{UUID: "a1b2c3d4:e5f6a7b8:c9d0e1f2:a3b4c5d6", Name: "md0"}),
+		btrfsFilesystemEntity(&models.BtrfsFilesystem{UUID: "4c6f2d35-8b3e-4f5e-9a55-0d2b5e1e7c11"}),
+		filesystemEntity(&models.FilesystemCapacity{HostID: "nas", MountPoint: "/mnt/data"}),
+	}
+	for _, entity := range entities {
+		discovery := buildStorageDiscoveryMessages(entity, "homeassistant")
+		remove := buildStorageRemoveMessages(entity, "homeassistant")
+		require.Len(t, remove, len(discovery), entity.kind)
+		for i := range remove {
+			require.Equal(t, discovery[i].Topic, remove[i].Topic)
+			require.Empty(t, remove[i].Payload)
+		}
+	}
+}
+
+func TestFilesystemEntity_IDs(t *testing.T) {
+	root := filesystemEntity(&models.FilesystemCapacity{HostID: "nas", MountPoint: "/"})
+	require.Equal(t, "filesystem_nas_root", root.safe())
+	require.Equal(t, "scrutiny/filesystem/nas_root/state", root.stateTopic())
+
+	data := filesystemEntity(&models.FilesystemCapacity{HostID: "NAS.local", MountPoint: "/mnt/data"})
+	require.Equal(t, "filesystem_nas_local_mnt_data", data.safe())
+}
+
+func TestBuildZFSPoolStatePayload(t *testing.T) {
+	pool := &models.ZFSPool{
+		Status:              models.ZFSPoolStatusDegraded,
+		ScrubState:          models.ZFSScrubStateFinished,
+		CapacityPercent:     71.5,
+		TotalReadErrors:     1,
+		TotalChecksumErrors: 2,
+	}
+	payload := buildZFSPoolStatePayload(pool)
+	require.Equal(t, "DEGRADED", payload.Status)
+	require.Equal(t, "ON", payload.Problem)
+	require.Equal(t, int64(3), payload.Errors)
+	require.InDelta(t, 71.5, payload.CapacityPercent, 0.001)
+
+	pool.Status = models.ZFSPoolStatusOnline
+	require.Equal(t, "OFF", buildZFSPoolStatePayload(pool).Problem)
+}
+
+func TestBuildMDADMArrayStatePayload(t *testing.T) {
+	payload := buildMDADMArrayStatePayload(&collector.MDADMMetrics{State: "clean, degraded, recovering", SyncProgress: 42.5})
+	require.Equal(t, "ON", payload.Degraded)
+	require.InDelta(t, 42.5, payload.SyncProgress, 0.001)
+
+	require.Equal(t, "OFF", buildMDADMArrayStatePayload(&collector.MDADMMetrics{State: "clean"}).Degraded)
+	require.Equal(t, "ON", buildMDADMArrayStatePayload(&collector.MDADMMetrics{State: "active", FailedDevices: 1}).Degraded)
+}
+
+func TestBuildBtrfsFilesystemStatePayload(t *testing.T) {
+	filesystem := &models.BtrfsFilesystem{
+		Status:     models.BtrfsFilesystemStatusOnline,
+		DeviceSize: 1000,
+		Used:       250,
+	}
+	payload := buildBtrfsFilesystemStatePayload(filesystem)
+	require.Equal(t, "OFF", payload.Problem)
+	require.InDelta(t, 25.0, payload.UsedPercent, 0.001)
+
+	filesystem.Devices = []models.BtrfsDevice{{WriteIOErrors: 2}, {CorruptionErrors: 1}}
+	payload = buildBtrfsFilesystemStatePayload(filesystem)
+	require.Equal(t, int64(3), payload.DeviceErrors)
+	require.Equal(t, "ON", payload.Problem)
+}
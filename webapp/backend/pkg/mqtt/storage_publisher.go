@@ -0,0 +1,190 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+)
+
+// PublishZFSPool publishes discovery and state for a ZFS pool asynchronously.
+func (p *Publisher) PublishZFSPool(pool *models.ZFSPool) {
+	entity := zfsPoolEntity(pool)
+	state := buildZFSPoolStatePayload(pool)
+	muted := pool.Muted
+	go func() {
+		p.publishStorage(entity, state)
+		p.publishStorageCommands(entity, muted)
+	}()
+}
+
+// PublishMDADMArray publishes discovery and state for an mdadm array asynchronously.
+func (p *Publisher) PublishMDADMArray(array *models.MDADMArray, metrics *collector.MDADMMetrics) {
+	entity := mdadmArrayEntity(array)
+	state := buildMDADMArrayStatePayload(metrics)
+	muted := array.Muted
+	go func() {
+		p.publishStorage(entity, state)
+		p.publishStorageCommands(entity, muted)
+	}()
+}
+
+// PublishBtrfsFilesystem publishes discovery and state for a Btrfs filesystem asynchronously.
+func (p *Publisher) PublishBtrfsFilesystem(filesystem *models.BtrfsFilesystem) {
+	entity := btrfsFilesystemEntity(filesystem)
+	state := buildBtrfsFilesystemStatePayload(filesystem)
+	muted := filesystem.Muted
+	go func() {
+		p.publishStorage(entity, state)
+		p.publishStorageCommands(entity, muted)
+	}()
+}
+
+// PublishFilesystem publishes discovery and state for a mounted filesystem asynchronously.
+func (p *Publisher) PublishFilesystem(filesystem *models.FilesystemCapacity) {
+	entity := filesystemEntity(filesystem)
+	state := buildFilesystemStatePayload(filesystem)
+	go p.publishStorage(entity, state)
+}
+
+// RemoveZFSPool removes a ZFS pool from HA by publishing empty discovery messages.
+func (p *Publisher) RemoveZFSPool(guid string) {
+	p.removeStorage(storageEntity{kind: StorageKindZFSPool, id: guid})
+}
+
+// RemoveMDADMArray removes an mdadm array from HA by publishing empty discovery messages.
+func (p *Publisher) RemoveMDADMArray(uuid string) {
+	p.removeStorage(storageEntity{kind: StorageKindMDADMArray, id: uuid})
+}
+
+// RemoveBtrfsFilesystem removes a Btrfs filesystem from HA by publishing empty discovery messages.
+func (p *Publisher) RemoveBtrfsFilesystem(uuid string) {
+	p.removeStorage(storageEntity{kind: StorageKindBtrfs, id: uuid})
+}
+
+// RemoveFilesystem removes a mounted filesystem from HA by publishing empty discovery messages.
+func (p *Publisher) RemoveFilesystem(filesystem *models.FilesystemCapacity) {
+	p.removeStorage(filesystemEntity(filesystem))
+}
+
+// SyncStorage re-publishes discovery and state for all active ZFS pools, mdadm arrays, Btrfs
+// filesystems and mounted filesystems. Returns the number of storage entities published.
+func (p *Publisher) SyncStorage(deviceRepo database.DeviceRepo, ctx context.Context) (int, error) {
+	if !p.client.IsConnected() {
+		return 0, fmt.Errorf("MQTT client is not connected")
+	}
+
+	published := 0
+
+	pools, err := deviceRepo.GetZFSPools(ctx)
+	if err != nil {
+		return published, fmt.Errorf("MQTT: failed to load ZFS pools: %w", err)
+	}
+	for i := range pools {
+		p.publishStorage(zfsPoolEntity(&pools[i]), buildZFSPoolStatePayload(&pools[i]))
+		p.publishStorageCommands(zfsPoolEntity(&pools[i]), pools[i].Muted)
+		published++
+	}
+
+	arrays, err := deviceRepo.GetMdadmArrays(ctx)
+	if err != nil {
+		return published, fmt.Errorf("MQTT: failed to load mdadm arrays: %w", err)
+	}
+	for i := range arrays {
+		latest, err := deviceRepo.GetLatestMdadmMetrics(ctx, arrays[i].UUID)
+		if err != nil || latest == nil {
+			continue
+		}
+		metrics := collector.MDADMMetrics{
+			State:          latest.State,
+			ActiveDevices:  latest.ActiveDevices,
+			WorkingDevices: latest.WorkingDevices,
+			FailedDevices:  latest.FailedDevices,
+			SpareDevices:   latest.SpareDevices,
+			SyncProgress:   latest.SyncProgress,
+			ArraySize:      latest.ArraySize,
+			UpdatedAt:      latest.Date,
+		}
+		p.publishStorage(mdadmArrayEntity(&arrays[i]), buildMDADMArrayStatePayload(&metrics))
+		p.publishStorageCommands(mdadmArrayEntity(&arrays[i]), arrays[i].Muted)
+		published++
+	}
+
+	filesystems, err := deviceRepo.GetBtrfsFilesystems(ctx)
+	if err != nil {
+		return published, fmt.Errorf("MQTT: failed to load Btrfs filesystems: %w", err)
+	}
+	for _, filesystem := range filesystems {
+		// the list does not include the member devices and their error counters
+		details, err := deviceRepo.GetBtrfsFilesystemDetails(ctx, filesystem.UUID)
+		if err != nil {
+			details = filesystem
+		}
+		p.publishStorage(btrfsFilesystemEntity(&details), buildBtrfsFilesystemStatePayload(&details))
+		p.publishStorageCommands(btrfsFilesystemEntity(&details), details.Muted)
+		published++
+	}
+
+	mounts, _, err := deviceRepo.GetFilesystemSummary(ctx)
+	if err != nil {
+		return published, fmt.Errorf("MQTT: failed to load filesystem summary: %w", err)
+	}
+	for _, hostMounts := range mounts {
+		for i := range hostMounts {
+			p.publishStorage(filesystemEntity(&hostMounts[i]), buildFilesystemStatePayload(&hostMounts[i]))
+			published++
+		}
+	}
+
+	return published, nil
+}
+
+func (p *Publisher) publishStorage(entity storageEntity, state interface{}) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.client.IsConnected() {
+		return
+	}
+
+	for _, msg := range buildStorageDiscoveryMessages(entity, p.topicPrefix) {
+		if err := p.client.Publish(msg.Topic, msg.Payload, true); err != nil {
+			p.logger.Warnf("MQTT: failed to publish discovery for %s: %v", entity.safe(), err)
+		}
+	}
+
+	payloadJSON, err := json.Marshal(state)
+	if err != nil {
+		p.logger.Warnf("MQTT: failed to marshal state for %s: %v", entity.safe(), err)
+		return
+	}
+	if err := p.client.Publish(entity.stateTopic(), string(payloadJSON), p.retain); err != nil {
+		p.logger.Warnf("MQTT: failed to publish state for %s: %v", entity.safe(), err)
+		return
+	}
+	p.logger.Debugf("MQTT: published state for %s", entity.safe())
+}
+
+func (p *Publisher) removeStorage(entity storageEntity) {
+	if !p.client.IsConnected() {
+		return
+	}
+
+	for _, msg := range buildStorageRemoveMessages(entity, p.topicPrefix) {
+		if err := p.client.Publish(msg.Topic, msg.Payload, true); err != nil {
+			p.logger.Warnf("MQTT: failed to remove discovery for %s: %v", entity.safe(), err)
+		}
+	}
+	if err := p.client.Publish(entity.stateTopic(), "", true); err != nil {
+		p.logger.Warnf("MQTT: failed to clear state for %s: %v", entity.safe(), err)
+	}
+	if entities, mutedTopic := storageCommandEntities(entity.kind, entity.id); len(entities) > 0 {
+		p.publishDiscoveryMessages(entity.safe(), buildCommandRemoveMessages(p.topicPrefix, entity.safe(), entities))
+		_ = p.client.Publish(mutedTopic, "", true)
+	}
+
+	p.logger.Debugf("MQTT: removed %s from Home Assistant", entity.safe())
+}
@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+// NotifyFailureTypeDigest is a group of notifications buffered during the digest window and
+// delivered as one message, see metrics.notification_digest_minutes.
+const NotifyFailureTypeDigest = "Digest"
+
+// digestEntry is a notification buffered for a digest.
+type digestEntry struct {
+	At          time.Time
+	FailureType string
+	Subject     string
+	Message     string
+	DeviceName  string
+	DeviceLabel string
+}
+
+// digestGroup buffers the notifications of one host and severity that are routed to the same
+// database URLs, so the digest reaches exactly the URLs its notifications would have.
+type digestGroup struct {
+	hostID       string
+	severity     string
+	databaseUrls []string
+	template     Notify // logger, config and history of the first notification
+	settings     *models.Settings
+	entries      []digestEntry
+	due          time.Time
+	timer        *time.Timer
+}
+
+// shouldDigest reports whether a notification is buffered for a digest instead of sent. Tests,
+// resends from the retry queue and digests themselves are never buffered, and critical
+// notifications skip the digest when metrics.notification_digest_bypass_critical is enabled.
+func shouldDigest(n *Notify, settings *models.Settings) bool {
+	if settings == nil || settings.Metrics.NotificationDigestMinutes <= 0 {
+		return false
+	}
+	if n.Payload.Test || n.retry != nil || n.Payload.FailureType == NotifyFailureTypeDigest || n.Payload.FailureType == NotifyFailureTypeQuietHoursDigest {
+		return false
+	}
+	if settings.Metrics.NotificationDigestBypassCritical && FailureTypeSeverity(n.Payload.FailureType) == models.NotifySeverityCritical {
+		return false
+	}
+	return true
+}
+
+// bufferDigest adds a notification to the digest of its host and severity, opening the digest
+// window if it is the first one. The digest is flushed when the window ends.
+func (g *NotificationGate) bufferDigest(n *Notify, settings *models.Settings) {
+	severity := FailureTypeSeverity(n.Payload.FailureType)
+	urls := append([]string{}, n.DatabaseUrls...)
+	sort.Strings(urls)
+	key := strings.Join(append([]string{n.Payload.HostId, severity}, urls...), "|")
+	window := time.Duration(settings.Metrics.NotificationDigestMinutes) * time.Minute
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	group, exists := g.digests[key]
+	if !exists {
+		group = &digestGroup{
+			hostID:       n.Payload.HostId,
+			severity:     severity,
+			databaseUrls: urls,
+			template:     Notify{Logger: n.Logger, Config: n.Config, history: n.history},
+			due:          now.Add(window),
+		}
+		group.timer = time.AfterFunc(window, func() { g.FlushDigests(time.Now(), false) })
+		g.digests[key] = group
+	}
+	group.settings = settings
+	group.entries = append(group.entries, digestEntry{
+		At:          now,
+		FailureType: n.Payload.FailureType,
+		Subject:     n.Payload.Subject,
+		Message:     n.Payload.Message,
+		DeviceName:  n.Payload.DeviceName,
+		DeviceLabel: n.Payload.DeviceLabel,
+	})
+}
+
+// FlushDigests sends the digests whose window has ended by now, or every pending digest when
+// force is set (on shutdown). Returns the number of digests flushed.
+func (g *NotificationGate) FlushDigests(now time.Time, force bool) int {
+	g.mu.Lock()
+	due := []*digestGroup{}
+	for key, group := range g.digests {
+		if !force && now.Before(group.due) {
+			continue
+		}
+		group.timer.Stop()
+		delete(g.digests, key)
+		due = append(due, group)
+	}
+	g.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].due.Before(due[j].due) })
+	for _, group := range due {
+		g.sendDigest(group)
+	}
+	return len(due)
+}
+
+// DigestLength returns the number of notifications buffered for digests.
+func (g *NotificationGate) DigestLength() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	count := 0
+	for _, group := range g.digests {
+		count += len(group.entries)
+	}
+	return count
+}
+
+// sendDigest delivers a digest. A digest flushed during quiet hours joins the quiet hours queue,
+// and it counts as a single notification towards the rate limit.
+func (g *NotificationGate) sendDigest(group *digestGroup) {
+	n := newDigest(group)
+	if g.isQuietHours(group.settings) {
+		g.enqueue(newQueuedNotification(&n))
+		n.RecordSuppressed(models.NotificationSuppressedQuietHours)
+		return
+	}
+	if g.isRateLimited(group.settings) {
+		g.logger.Warnf("Notification digest dropped due to rate limit: %s", n.Payload.Subject)
+		n.RecordSuppressed(models.NotificationSuppressedRateLimit)
+		return
+	}
+	if err := n.Send(); err != nil {
+		g.logger.Warnf("Failed to send notification digest: %v", err)
+		return
+	}
+	g.recordSent()
+	g.publishSent(&n)
+	g.logger.Infof("Sent notification digest with %d notification(s)", len(group.entries))
+}
+
+// newDigest renders the buffered notifications of a digest group into one notification.
+func newDigest(group *digestGroup) Notify {
+	count := len(group.entries)
+	hostLabel := group.hostID
+	if hostLabel == "" {
+		hostLabel = "(no host)"
+	}
+	subject := fmt.Sprintf("Scrutiny digest: %d %s notification(s) on host %s", count, group.severity, hostLabel)
+
+	parts := []string{
+		fmt.Sprintf("%d %s notification(s) for host %s were grouped into this digest:", count, group.severity, hostLabel),
+		"",
+	}
+	for _, entry := range group.entries {
+		parts = append(parts, fmt.Sprintf("  [%s] %s: %s", entry.At.Format("15:04"), entry.FailureType, entry.Subject))
+		if entry.Message != "" {
+			lines := strings.SplitN(entry.Message, "\n", 2)
+			parts = append(parts, fmt.Sprintf("    %s", lines[0]))
+		}
+	}
+
+	n := group.template
+	n.DatabaseUrls = group.databaseUrls
+	n.severity = group.severity
+	n.Payload = Payload{
+		HostId:      group.hostID,
+		Date:        time.Now().Format(time.RFC3339),
+		FailureType: NotifyFailureTypeDigest,
+		Subject:     subject,
+		Message:     strings.Join(parts, "\n"),
+		HTMLMessage: formatHTMLDigest(subject, group),
+	}
+	return n
+}
+
+var digestSeverityColors = map[string]string{
+	models.NotifySeverityCritical: "#dc3545",
+	models.NotifySeverityWarning:  "#c58a16",
+	models.NotifySeverityInfo:     "#0d6efd",
+}
+
+func formatHTMLDigest(subject string, group *digestGroup) string {
+	var b strings.Builder
+
+	writeNotificationEmailStart(&b)
+	writeNotificationEmailHeader(
+		&b,
+		digestSeverityColors[group.severity],
+		strings.ToUpper(group.severity)+" DIGEST",
+		subject,
+		fmt.Sprintf("%d notification(s) since %s", len(group.entries), group.entries[0].At.Format("15:04 MST")),
+	)
+
+	b.WriteString(`<tr><td class="scrutiny-pad" style="padding:12px 32px 24px;background-color:#ffffff;">
+<table width="100%" cellpadding="0" cellspacing="0" style="font-size:12px;border-collapse:collapse;">
+<tr style="background-color:#f2f4f7;color:#475467;">
+<th align="left" style="padding:9px 8px;border-bottom:1px solid #dfe5ec;font-size:10px;letter-spacing:0.05em;text-transform:uppercase;">Time</th>
+<th align="left" style="padding:9px 8px;border-bottom:1px solid #dfe5ec;font-size:10px;letter-spacing:0.05em;text-transform:uppercase;">Type</th>
+<th align="left" style="padding:9px 8px;border-bottom:1px solid #dfe5ec;font-size:10px;letter-spacing:0.05em;text-transform:uppercase;">Device</th>
+<th align="left" style="padding:9px 8px;border-bottom:1px solid #dfe5ec;font-size:10px;letter-spacing:0.05em;text-transform:uppercase;">Notification</th>
+</tr>`)
+	for _, entry := range group.entries {
+		device := entry.DeviceName
+		if entry.DeviceLabel != "" {
+			device = fmt.Sprintf(fmtLabelWithName, entry.DeviceLabel, entry.DeviceName)
+		}
+		if device == "" {
+			device = "-"
+		}
+		fmt.Fprintf(&b, `<tr>
+<td style="padding:10px 8px;border-bottom:1px solid #e6eaf0;color:#475467;">%s</td>
+<td style="padding:10px 8px;border-bottom:1px solid #e6eaf0;color:#475467;">%s</td>
+<td style="padding:10px 8px;border-bottom:1px solid #e6eaf0;color:#475467;word-break:break-word;">%s</td>
+<td style="padding:10px 8px;border-bottom:1px solid #e6eaf0;color:#101828;word-break:break-word;">%s</td>
+</tr>`, entry.At.Format("15:04"), htmlEscape(entry.FailureType), htmlEscape(device), htmlEscape(entry.Subject))
+	}
+	b.WriteString(`</table></td></tr>`)
+
+	writeNotificationEmailFooter(&b, fmt.Sprintf("Generated %s by Scrutiny", time.Now().Format("Jan 2, 2006 15:04 MST")))
+	writeNotificationEmailEnd(&b)
+	return b.String()
+}
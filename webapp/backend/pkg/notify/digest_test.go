@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func digestSettings(minutes int, bypassCritical bool) *models.Settings {
+	settings := &models.Settings{}
+	settings.Metrics.NotificationDigestMinutes = minutes
+	settings.Metrics.NotificationDigestBypassCritical = bypassCritical
+	return settings
+}
+
+func digestTestNotification(hostID, failureType, subject string) *Notify {
+	return &Notify{
+		Logger: logrus.StandardLogger(),
+		Payload: Payload{
+			HostId:      hostID,
+			FailureType: failureType,
+			Subject:     subject,
+			Message:     subject + "\nsecond line",
+			DeviceName:  "/dev/sda",
+		},
+	}
+}
+
+func TestShouldDigest(t *testing.T) {
+	t.Parallel()
+
+	smart := digestTestNotification("nas", NotifyFailureTypeSmartFailure, "failed")
+	risk := digestTestNotification("nas", NotifyFailureTypeReplacementRisk, "risk")
+
+	require.False(t, shouldDigest(risk, digestSettings(0, false)), "digests are disabled by default")
+	require.False(t, shouldDigest(risk, nil))
+	require.True(t, shouldDigest(risk, digestSettings(15, true)))
+	require.False(t, shouldDigest(smart, digestSettings(15, true)), "critical notifications bypass the digest")
+	require.True(t, shouldDigest(smart, digestSettings(15, false)))
+
+	test := digestTestNotification("nas", NotifyFailureTypeReplacementRisk, "test")
+	test.Payload.Test = true
+	require.False(t, shouldDigest(test, digestSettings(15, false)))
+
+	digest := digestTestNotification("nas", NotifyFailureTypeDigest, "digest")
+	require.False(t, shouldDigest(digest, digestSettings(15, false)))
+}
+
+func TestGate_BuffersDigestsByHostAndSeverity(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryGateStore{}
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	require.NoError(t, gate.SetStore(context.Background(), store))
+	settings := digestSettings(60, true)
+
+	require.True(t, gate.TrySend(digestTestNotification("nas", NotifyFailureTypeReplacementRisk, "risk sda"), settings, false))
+	require.True(t, gate.TrySend(digestTestNotification("nas", NotifyFailureTypeCollectorError, "collector sdb"), settings, false))
+	require.True(t, gate.TrySend(digestTestNotification("nas", NotifyFailureTypeNewDevice, "new sdc"), settings, false))
+	require.True(t, gate.TrySend(digestTestNotification("backup", NotifyFailureTypeReplacementRisk, "risk sdd"), settings, false))
+
+	require.Equal(t, 4, gate.DigestLength())
+	require.Len(t, gate.digests, 3, "one digest per host and severity")
+	require.Len(t, store.records, 4)
+	for _, record := range store.records {
+		require.Equal(t, models.NotificationSuppressedDigest, record.SuppressedBy)
+	}
+
+	// nothing is due before the window ends
+	require.Equal(t, 0, gate.FlushDigests(time.Now(), false))
+	require.Equal(t, 4, gate.DigestLength())
+
+	// the rate limit is exhausted, so the flushed digests are recorded without sending anything
+	settings.Metrics.NotificationRateLimit = 1
+	gate.recordSent()
+	require.Equal(t, 3, gate.FlushDigests(time.Now().Add(time.Hour), false))
+	require.Equal(t, 0, gate.DigestLength())
+
+	digests := store.records[4:]
+	require.Len(t, digests, 3)
+	subjects := []string{}
+	for _, record := range digests {
+		require.Equal(t, NotifyFailureTypeDigest, record.FailureType)
+		require.Equal(t, models.NotificationSuppressedRateLimit, record.SuppressedBy)
+		subjects = append(subjects, record.Subject)
+	}
+	require.Contains(t, subjects, "Scrutiny digest: 2 warning notification(s) on host nas")
+	require.Contains(t, subjects, "Scrutiny digest: 1 info notification(s) on host nas")
+	require.Contains(t, subjects, "Scrutiny digest: 1 warning notification(s) on host backup")
+}
+
+func TestGate_DigestBypassesCriticalNotifications(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryGateStore{}
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	require.NoError(t, gate.SetStore(context.Background(), store))
+	settings := digestSettings(60, true)
+	settings.Metrics.NotificationRateLimit = 1
+	gate.recordSent()
+
+	require.False(t, gate.TrySend(digestTestNotification("nas", NotifyFailureTypeSmartFailure, "failed"), settings, false))
+	require.Equal(t, 0, gate.DigestLength())
+	require.Len(t, store.records, 1)
+	require.Equal(t, models.NotificationSuppressedRateLimit, store.records[0].SuppressedBy, "critical notifications go straight to delivery")
+}
+
+func TestGate_FlushDigests_ForceQueuesDuringQuietHours(t *testing.T) {
+	t.Parallel()
+
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	settings := digestSettings(60, false)
+	require.True(t, gate.TrySend(digestTestNotification("nas", NotifyFailureTypeSmartFailure, "failed"), settings, false))
+	require.Equal(t, 1, gate.DigestLength())
+
+	// quiet hours start after the notification was buffered
+	settings.Metrics.NotificationQuietStart = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1439) % 1440)
+	settings.Metrics.NotificationQuietEnd = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1) % 1440)
+
+	require.Equal(t, 1, gate.FlushDigests(time.Now(), true))
+	require.Equal(t, 0, gate.DigestLength())
+	require.Equal(t, 1, gate.QueueLength())
+}
+
+func TestNewDigest_RendersTextAndHTML(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	group := &digestGroup{
+		hostID:       "nas",
+		severity:     models.NotifySeverityWarning,
+		databaseUrls: []string{"json://example.com"},
+		entries: []digestEntry{
+			{At: at, FailureType: NotifyFailureTypeReplacementRisk, Subject: "risk <sda>", Message: "first line\nsecond line", DeviceName: "/dev/sda", DeviceLabel: "Parity"},
+			{At: at.Add(time.Minute), FailureType: NotifyFailureTypeCollectorError, Subject: "collector sdb"},
+		},
+	}
+
+	n := newDigest(group)
+	require.Equal(t, NotifyFailureTypeDigest, n.Payload.FailureType)
+	require.Equal(t, "nas", n.Payload.HostId)
+	require.Equal(t, []string{"json://example.com"}, n.DatabaseUrls)
+	require.Equal(t, models.NotifySeverityWarning, n.Severity(), "a digest keeps the severity of its notifications")
+	require.Equal(t, "Scrutiny digest: 2 warning notification(s) on host nas", n.Payload.Subject)
+
+	require.Contains(t, n.Payload.Message, "[09:30] ReplacementRisk: risk <sda>")
+	require.Contains(t, n.Payload.Message, "    first line")
+	require.NotContains(t, n.Payload.Message, "second line")
+	require.Contains(t, n.Payload.Message, "[09:31] CollectorError: collector sdb")
+
+	require.Contains(t, n.Payload.HTMLMessage, "WARNING DIGEST")
+	require.Contains(t, n.Payload.HTMLMessage, "risk &lt;sda&gt;")
+	require.Contains(t, n.Payload.HTMLMessage, "Parity")
+	require.NotContains(t, n.Payload.HTMLMessage, "risk <sda>")
+}
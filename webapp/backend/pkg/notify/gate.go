@@ -1,11 +1,14 @@
 package notify
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/sirupsen/logrus"
 )
@@ -15,17 +18,22 @@ import (
 // directly calling Notify.Send().
 type NotificationGate struct {
 	logger         logrus.FieldLogger
-	sentTimestamps []time.Time          // sliding window for rate limiting
-	quietQueue     []QueuedNotification // queued during quiet hours
-	collectorError map[string]time.Time // dedupe map for collector-side errors
+	sentTimestamps []time.Time             // sliding window for rate limiting
+	quietQueue     []QueuedNotification    // queued during quiet hours
+	collectorError map[string]time.Time    // dedupe map for collector-side errors
+	digests        map[string]*digestGroup // notifications buffered for digests, by host, severity and URLs
+	store          GateStore               // optional persistence for history and the quiet queue
+	eventBus       *events.Bus             // optional, receives a notification_sent event per delivery
 	mu             sync.Mutex
 }
 
 // QueuedNotification holds a notification that was deferred during quiet hours.
-type QueuedNotification struct {
-	QueuedAt time.Time
-	Subject  string
-	Message  string
+type QueuedNotification = models.QueuedNotification
+
+// GateStore persists the notification history and quiet hours queue of the gate.
+type GateStore interface {
+	HistoryStore
+	QueueStore
 }
 
 // NewNotificationGate creates a new gate instance. Should be created once
@@ -34,32 +42,101 @@ func NewNotificationGate(logger logrus.FieldLogger) *NotificationGate {
 	return &NotificationGate{
 		logger:         logger,
 		collectorError: map[string]time.Time{},
+		digests:        map[string]*digestGroup{},
+	}
+}
+
+// SetStore makes the gate persist its quiet hours queue and record suppressed notifications.
+// Notifications queued before a restart are restored into the queue.
+func (g *NotificationGate) SetStore(ctx context.Context, store GateStore) error {
+	queued, err := store.GetQuietNotifications(ctx)
+	if err != nil {
+		return err
 	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.store = store
+	g.quietQueue = append(queued, g.quietQueue...)
+	if len(queued) > 0 {
+		g.logger.Infof("Restored %d notification(s) queued during quiet hours", len(queued))
+	}
+	return nil
+}
+
+// SetEventBus makes the gate publish a notification_sent event for every delivered notification.
+func (g *NotificationGate) SetEventBus(bus *events.Bus) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.eventBus = bus
+}
+
+// publishSent publishes a notification_sent event for a delivered notification.
+func (g *NotificationGate) publishSent(n *Notify) {
+	g.mu.Lock()
+	bus := g.eventBus
+	g.mu.Unlock()
+	if bus == nil {
+		return
+	}
+	deviceID := ""
+	if n.templateDevice != nil {
+		deviceID = n.templateDevice.DeviceID
+	}
+	bus.Publish(events.Event{
+		Type:     events.TypeNotificationSent,
+		HostID:   n.Payload.HostId,
+		DeviceID: deviceID,
+		Data: map[string]interface{}{
+			"failure_type": n.Payload.FailureType,
+			"severity":     n.Severity(),
+			"subject":      n.Payload.Subject,
+			"device_name":  n.Payload.DeviceName,
+		},
+	})
 }
 
 // TrySend checks rate limiting and quiet hours before dispatching a notification.
 // If quiet hours are active, the notification summary is queued for digest delivery.
+// If a digest window is configured, the notification is buffered for its host's digest.
 // If rate limit is exceeded, the notification is dropped (logged).
 // If bypassQuietHours is true, quiet hours are ignored (used for heartbeats).
 // Returns true if sent or queued, false if dropped.
 func (g *NotificationGate) TrySend(n *Notify, settings *models.Settings, bypassQuietHours bool) bool {
+	g.mu.Lock()
+	store := g.store
+	g.mu.Unlock()
+	if n.history == nil && store != nil {
+		n.history = store
+	}
+
+	// render before queuing so deferred notifications keep the user's template
+	n.ApplyTemplates()
+
+	if window := maintenanceWindow(store, n, g.logger); window != nil {
+		n.RecordMaintenanceSuppressed(*window)
+		g.logger.Infof("Notification suppressed by maintenance window %d: %s", window.ID, n.Payload.Subject)
+		return false
+	}
+
 	if !bypassQuietHours && g.isQuietHours(settings) {
-		subject := n.Payload.Subject
-		message := n.Payload.Message
-		g.mu.Lock()
-		g.quietQueue = append(g.quietQueue, QueuedNotification{
-			Subject:  subject,
-			Message:  message,
-			QueuedAt: time.Now(),
-		})
-		g.mu.Unlock()
-		g.logger.Infof("Notification queued during quiet hours: %s", subject)
+		g.enqueue(newQueuedNotification(n))
+		n.RecordSuppressed(models.NotificationSuppressedQuietHours)
+		g.logger.Infof("Notification queued during quiet hours: %s", n.Payload.Subject)
+		return true
+	}
+
+	if !bypassQuietHours && shouldDigest(n, settings) {
+		g.bufferDigest(n, settings)
+		n.RecordSuppressed(models.NotificationSuppressedDigest)
+		g.logger.Debugf("Notification buffered for digest: %s", n.Payload.Subject)
 		return true
 	}
 
 	if g.isRateLimited(settings) {
 		g.logger.Warnf("Notification dropped due to rate limit (%d/hour): %s",
 			settings.Metrics.NotificationRateLimit, n.Payload.Subject)
+		n.RecordSuppressed(models.NotificationSuppressedRateLimit)
 		return false
 	}
 
@@ -69,15 +146,23 @@ func (g *NotificationGate) TrySend(n *Notify, settings *models.Settings, bypassQ
 	}
 
 	g.recordSent()
+	g.publishSent(n)
 	return true
 }
 
 func (g *NotificationGate) TrySendCollectorError(identity, errorType, errorMessage string, n *Notify, settings *models.Settings) bool {
+	key := collectorErrorKey(identity, errorType, errorMessage)
 	if settings.Metrics.RepeatNotifications {
-		return g.TrySend(n, settings, false)
+		// still remembered, so clearing the error can be reported
+		sent := g.TrySend(n, settings, false)
+		if sent {
+			g.mu.Lock()
+			g.collectorError[key] = time.Now()
+			g.mu.Unlock()
+		}
+		return sent
 	}
 
-	key := collectorErrorKey(identity, errorType, errorMessage)
 	g.mu.Lock()
 	if _, exists := g.collectorError[key]; exists {
 		g.mu.Unlock()
@@ -96,24 +181,49 @@ func (g *NotificationGate) TrySendCollectorError(identity, errorType, errorMessa
 	return sent
 }
 
-func (g *NotificationGate) ClearCollectorErrorState(identity string) {
+// ClearCollectorErrorState forgets the collector errors notified for identity, so they notify
+// again if they recur. Returns true if any error was notified, i.e. the error has now cleared.
+func (g *NotificationGate) ClearCollectorErrorState(identity string) bool {
 	prefix := strings.ToLower(strings.TrimSpace(identity)) + "|"
 	if prefix == "|" {
-		return
+		return false
 	}
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	cleared := false
 	for key := range g.collectorError {
 		if strings.HasPrefix(key, prefix) {
 			delete(g.collectorError, key)
+			cleared = true
 		}
 	}
+	return cleared
+}
+
+// NotifyFailureTypeQuietHoursDigest is the digest of the notifications queued during quiet hours,
+// sent when quiet hours end.
+const NotifyFailureTypeQuietHoursDigest = "QuietHoursDigest"
+
+// newQueuedNotification captures a notification for the quiet hours queue, including the
+// database URLs it was routed to.
+func newQueuedNotification(n *Notify) QueuedNotification {
+	return QueuedNotification{
+		QueuedAt:     time.Now(),
+		FailureType:  n.Payload.FailureType,
+		HostID:       n.Payload.HostId,
+		Severity:     n.Severity(),
+		Subject:      n.Payload.Subject,
+		Message:      n.Payload.Message,
+		DatabaseUrls: append([]string{}, n.DatabaseUrls...),
+	}
 }
 
 // FlushQuietQueue checks if quiet hours have ended and sends a digest of all
 // queued notifications. Should be called periodically (e.g., at the start of
-// each missed ping check cycle).
+// each missed ping check cycle). Queued notifications are grouped by the database
+// URLs they were routed to, and each group is sent as its own digest to those URLs;
+// n provides the logger and config of the digests.
 func (g *NotificationGate) FlushQuietQueue(n *Notify, settings *models.Settings) {
 	if g.isQuietHours(settings) {
 		return
@@ -127,14 +237,75 @@ func (g *NotificationGate) FlushQuietQueue(n *Notify, settings *models.Settings)
 	queued := make([]QueuedNotification, len(g.quietQueue))
 	copy(queued, g.quietQueue)
 	g.quietQueue = nil
+	store := g.store
 	g.mu.Unlock()
 
+	if store != nil {
+		ids := make([]uint, 0, len(queued))
+		for _, q := range queued {
+			if q.ID != 0 {
+				ids = append(ids, q.ID)
+			}
+		}
+		if err := store.DeleteQuietNotifications(context.Background(), ids); err != nil {
+			g.logger.Warnf("Failed to clear persisted quiet hours queue: %v", err)
+		}
+		if n.history == nil {
+			n.history = store
+		}
+	}
+
+	for _, group := range groupQuietQueue(queued) {
+		digest := newQuietHoursDigest(*n, group)
+
+		if g.isRateLimited(settings) {
+			g.logger.Warnf("Quiet hours digest dropped due to rate limit")
+			digest.RecordSuppressed(models.NotificationSuppressedRateLimit)
+			continue
+		}
+
+		if err := digest.Send(); err != nil {
+			g.logger.Warnf("Failed to send quiet hours digest: %v", err)
+			continue
+		}
+		g.recordSent()
+		g.publishSent(&digest)
+		g.logger.Infof("Sent quiet hours digest with %d queued notification(s)", len(group))
+	}
+}
+
+// groupQuietQueue groups queued notifications by the database URLs they were routed to, in the
+// order the first notification of each group was queued.
+func groupQuietQueue(queued []QueuedNotification) [][]QueuedNotification {
+	groups := [][]QueuedNotification{}
+	index := map[string]int{}
+	for _, q := range queued {
+		urls := append([]string{}, q.DatabaseUrls...)
+		sort.Strings(urls)
+		key := strings.Join(urls, "|")
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], q)
+	}
+	return groups
+}
+
+// newQuietHoursDigest renders a group of queued notifications into one notification routed to
+// their database URLs, with the highest severity of the group. The host is set when every
+// notification of the group belongs to the same host.
+func newQuietHoursDigest(n Notify, queued []QueuedNotification) Notify {
 	subject := fmt.Sprintf("Scrutiny: %d notification(s) during quiet hours", len(queued))
 	var parts []string
 	parts = append(parts,
 		fmt.Sprintf("The following %d notification(s) were queued during quiet hours:", len(queued)),
 		"",
 	)
+	hostID := queued[0].HostID
+	severity := ""
 	for _, q := range queued {
 		parts = append(parts, fmt.Sprintf("  [%s] %s", q.QueuedAt.Format("15:04"), q.Subject))
 		if q.Message != "" {
@@ -143,25 +314,43 @@ func (g *NotificationGate) FlushQuietQueue(n *Notify, settings *models.Settings)
 			parts = append(parts, fmt.Sprintf("    %s", lines[0]))
 		}
 		parts = append(parts, "")
+		if q.HostID != hostID {
+			hostID = ""
+		}
+		qSeverity := q.Severity
+		if qSeverity == "" {
+			qSeverity = FailureTypeSeverity(q.FailureType)
+		}
+		severity = models.MaxNotifySeverity(severity, qSeverity)
 	}
 
+	n.DatabaseUrls = queued[0].DatabaseUrls
+	n.severity = severity
 	n.Payload = Payload{
-		FailureType: NotifyFailureTypeMissedPing,
+		HostId:      hostID,
+		Date:        time.Now().Format(time.RFC3339),
+		FailureType: NotifyFailureTypeQuietHoursDigest,
 		Subject:     subject,
 		Message:     strings.Join(parts, "\n"),
 	}
+	return n
+}
 
-	if g.isRateLimited(settings) {
-		g.logger.Warnf("Quiet hours digest dropped due to rate limit")
-		return
-	}
+// enqueue appends a notification to the quiet hours queue, persisting it when a store is set.
+func (g *NotificationGate) enqueue(queued QueuedNotification) {
+	g.mu.Lock()
+	store := g.store
+	g.mu.Unlock()
 
-	if err := n.Send(); err != nil {
-		g.logger.Warnf("Failed to send quiet hours digest: %v", err)
-		return
+	if store != nil {
+		if err := store.EnqueueQuietNotification(context.Background(), &queued); err != nil {
+			g.logger.Warnf("Failed to persist quiet hours notification, keeping it in memory only: %v", err)
+		}
 	}
-	g.recordSent()
-	g.logger.Infof("Sent quiet hours digest with %d queued notification(s)", len(queued))
+
+	g.mu.Lock()
+	g.quietQueue = append(g.quietQueue, queued)
+	g.mu.Unlock()
 }
 
 // isQuietHours checks if the current time falls within the configured quiet window.
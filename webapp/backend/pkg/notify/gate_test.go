@@ -2,9 +2,15 @@ package notify
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
@@ -236,11 +242,28 @@ func TestGate_ClearCollectorErrorState_AllowsFutureNotification(t *testing.T) {
 	notification := &Notify{Payload: Payload{Subject: "collector error", Message: "failed"}}
 
 	require.True(t, gate.TrySendCollectorError("device:test-id", "xall", "open failed", notification, settings))
-	gate.ClearCollectorErrorState("device:test-id")
+	require.True(t, gate.ClearCollectorErrorState("device:test-id"))
+	require.False(t, gate.ClearCollectorErrorState("device:test-id"), "nothing left to clear")
 	require.True(t, gate.TrySendCollectorError("device:test-id", "xall", "open failed", notification, settings))
 	require.Equal(t, 2, gate.QueueLength())
 }
 
+func TestGate_ClearCollectorErrorState_ReportsRepeatedErrors(t *testing.T) {
+	t.Parallel()
+
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	settings := &models.Settings{}
+	settings.Metrics.RepeatNotifications = true
+	settings.Metrics.NotificationQuietStart = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1439) % 1440)
+	settings.Metrics.NotificationQuietEnd = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1) % 1440)
+
+	notification := &Notify{Payload: Payload{Subject: "collector error", Message: "failed"}}
+
+	require.True(t, gate.TrySendCollectorError("device:test-id", "xall", "open failed", notification, settings))
+	require.True(t, gate.TrySendCollectorError("device:test-id", "xall", "open failed", notification, settings))
+	require.True(t, gate.ClearCollectorErrorState("device:test-id"))
+}
+
 // minutesToHHMM converts minutes since midnight to "HH:MM" format.
 func minutesToHHMM(minutes int) string {
 	if minutes < 0 {
@@ -253,3 +276,93 @@ func minutesToHHMM(minutes int) string {
 	m := minutes % 60
 	return fmt.Sprintf("%02d:%02d", h, m)
 }
+
+func TestGate_PublishesNotificationSentEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	cfg, err := config.Create()
+	require.NoError(t, err)
+
+	bus := events.NewBus(logrus.StandardLogger())
+	sub := bus.Subscribe(events.Filter{Types: []string{events.TypeNotificationSent}})
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	gate.SetEventBus(bus)
+
+	notification := New(logrus.StandardLogger(), cfg, templateTestDevice(), false)
+	notification.DatabaseUrls = []string{server.URL + "/hook"}
+	require.True(t, gate.TrySend(&notification, &models.Settings{}, false))
+
+	require.Len(t, sub.Events(), 1)
+	event := <-sub.Events()
+	require.Equal(t, "nas", event.HostID)
+	require.Equal(t, templateTestDevice().DeviceID, event.DeviceID)
+	require.Equal(t, NotifyFailureTypeSmartFailure, event.Data["failure_type"])
+	require.Equal(t, models.NotifySeverityCritical, event.Data["severity"])
+}
+
+func TestGate_FlushQuietQueue_RoutesDigestsToQueuedUrls(t *testing.T) {
+	t.Parallel()
+
+	received := map[string][]string{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	cfg, err := config.Create()
+	require.NoError(t, err)
+
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	settings := &models.Settings{}
+	settings.Metrics.NotificationQuietStart = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1439) % 1440)
+	settings.Metrics.NotificationQuietEnd = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1) % 1440)
+
+	smart := digestTestNotification("nas", NotifyFailureTypeSmartFailure, "sda failed")
+	smart.DatabaseUrls = []string{server.URL + "/smart"}
+	ping := digestTestNotification("nas", NotifyFailureTypeMissedPing, "nas missed a ping")
+	ping.DatabaseUrls = []string{server.URL + "/ping"}
+	report := digestTestNotification("nas", NotifyFailureTypeReport, "weekly report")
+	report.DatabaseUrls = []string{server.URL + "/smart"}
+	for _, n := range []*Notify{smart, ping, report} {
+		require.True(t, gate.TrySend(n, settings, false))
+	}
+	require.Equal(t, 3, gate.QueueLength())
+
+	settings.Metrics.NotificationQuietStart = ""
+	settings.Metrics.NotificationQuietEnd = ""
+	gate.FlushQuietQueue(&Notify{Logger: logrus.StandardLogger(), Config: cfg}, settings)
+	require.Equal(t, 0, gate.QueueLength())
+
+	require.Len(t, received["/smart"], 1, "notifications routed to the same URLs share a digest")
+	require.Contains(t, received["/smart"][0], "sda failed")
+	require.Contains(t, received["/smart"][0], "weekly report")
+	require.NotContains(t, received["/smart"][0], "missed a ping")
+	require.Len(t, received["/ping"], 1)
+	require.Contains(t, received["/ping"][0], "nas missed a ping")
+	require.NotContains(t, received["/ping"][0], "sda failed")
+}
+
+func TestNewQuietHoursDigest_KeepsHostAndHighestSeverity(t *testing.T) {
+	t.Parallel()
+
+	queued := []QueuedNotification{
+		{FailureType: NotifyFailureTypeReport, HostID: "nas", Severity: models.NotifySeverityInfo, Subject: "report", DatabaseUrls: []string{"json://example.com"}},
+		{FailureType: NotifyFailureTypeSmartFailure, HostID: "nas", Subject: "failed", DatabaseUrls: []string{"json://example.com"}},
+	}
+	n := newQuietHoursDigest(Notify{Logger: logrus.StandardLogger()}, queued)
+	require.Equal(t, NotifyFailureTypeQuietHoursDigest, n.Payload.FailureType)
+	require.Equal(t, "nas", n.Payload.HostId)
+	require.Equal(t, models.NotifySeverityCritical, n.Severity())
+	require.Equal(t, []string{"json://example.com"}, n.DatabaseUrls)
+
+	queued[1].HostID = "backup"
+	require.Empty(t, newQuietHoursDigest(Notify{}, queued).Payload.HostId, "a digest of several hosts has no host")
+}
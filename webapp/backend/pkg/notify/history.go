@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+)
+
+// HistoryStore persists notification attempts. Implemented by database.DeviceRepo.
+type HistoryStore interface {
+	SaveNotificationRecord(ctx context.Context, record *models.NotificationRecord) error
+}
+
+// QueueStore persists the quiet hours queue so it survives restarts. Implemented by database.DeviceRepo.
+type QueueStore interface {
+	EnqueueQuietNotification(ctx context.Context, queued *models.QueuedNotification) error
+	GetQuietNotifications(ctx context.Context) ([]models.QueuedNotification, error)
+	DeleteQuietNotifications(ctx context.Context, ids []uint) error
+}
+
+// SetHistory sets where delivery attempts of this notification are recorded. The Load*DatabaseUrls
+// helpers set it to the repository they load from.
+func (n *Notify) SetHistory(store HistoryStore) {
+	n.history = store
+}
+
+// RecordSuppressed records that the notification was not sent for the given reason
+// (one of the models.NotificationSuppressed* constants).
+func (n *Notify) RecordSuppressed(reason string) {
+	n.saveRecord(models.NotificationRecord{
+		Outcome:      models.NotificationOutcomeSuppressed,
+		SuppressedBy: reason,
+		Targets:      []models.NotificationTargetResult{},
+	})
+}
+
+// targetRecorder collects per-URL delivery results from the parallel senders.
+type targetRecorder struct {
+	mu      sync.Mutex
+	results []models.NotificationTargetResult
+	failed  []string // raw URLs of the targets that failed with a retryable error, for the retry queue
+}
+
+func (r *targetRecorder) record(rawURL string, err error) error {
+	result := models.NotificationTargetResult{URL: MaskNotifyUrl(rawURL), Success: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.results = append(r.results, result)
+	if IsRetryable(err) {
+		r.failed = append(r.failed, rawURL)
+	}
+	r.mu.Unlock()
+	return err
+}
+
+func (n *Notify) recordDelivery(targets []models.NotificationTargetResult, err error) {
+	record := models.NotificationRecord{
+		Outcome: models.NotificationOutcome(targets),
+		Targets: targets,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	n.saveRecord(record)
+}
+
+func (n *Notify) saveRecord(record models.NotificationRecord) {
+	if n.history == nil || n.Payload.Test {
+		return
+	}
+	record.FailureType = n.Payload.FailureType
+	record.HostID = n.Payload.HostId
+	record.DeviceName = n.Payload.DeviceName
+	record.DeviceSerial = n.Payload.DeviceSerial
+	record.Subject = n.Payload.Subject
+	if err := n.history.SaveNotificationRecord(context.Background(), &record); err != nil && n.Logger != nil {
+		n.Logger.Warnf("Failed to record notification history: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryGateStore is an in-memory GateStore for tests.
+type memoryGateStore struct {
+	mu      sync.Mutex
+	records []models.NotificationRecord
+	queue   []models.QueuedNotification
+	nextID  uint
+}
+
+func (s *memoryGateStore) SaveNotificationRecord(_ context.Context, record *models.NotificationRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, *record)
+	return nil
+}
+
+func (s *memoryGateStore) EnqueueQuietNotification(_ context.Context, queued *models.QueuedNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	queued.ID = s.nextID
+	s.queue = append(s.queue, *queued)
+	return nil
+}
+
+func (s *memoryGateStore) GetQuietNotifications(_ context.Context) ([]models.QueuedNotification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.QueuedNotification{}, s.queue...), nil
+}
+
+func (s *memoryGateStore) DeleteQuietNotifications(_ context.Context, ids []uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remaining := s.queue[:0]
+	for _, q := range s.queue {
+		keep := true
+		for _, id := range ids {
+			if q.ID == id {
+				keep = false
+			}
+		}
+		if keep {
+			remaining = append(remaining, q)
+		}
+	}
+	s.queue = remaining
+	return nil
+}
+
+func TestSendToUrls_RecordsPerTargetResults(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store := &memoryGateStore{}
+	n := Notify{
+		Logger:  logrus.StandardLogger(),
+		Payload: Payload{FailureType: NotifyFailureTypeSmartFailure, HostId: "nas", DeviceName: "/dev/sda", Subject: "disk failed"},
+	}
+	n.SetHistory(store)
+
+	require.Error(t, n.SendToUrls([]string{server.URL + "/hook", "script:///does/not/exist.sh"}))
+
+	require.Len(t, store.records, 1)
+	record := store.records[0]
+	require.Equal(t, models.NotificationOutcomePartial, record.Outcome)
+	require.Equal(t, "disk failed", record.Subject)
+	require.Equal(t, "nas", record.HostID)
+	require.Len(t, record.Targets, 2)
+	require.NotEmpty(t, record.Error)
+}
+
+func TestGate_PersistsQuietQueueAndRecordsSuppression(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryGateStore{}
+	require.NoError(t, store.EnqueueQuietNotification(context.Background(), &models.QueuedNotification{QueuedAt: time.Now(), Subject: "before restart"}))
+
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	require.NoError(t, gate.SetStore(context.Background(), store))
+	require.Equal(t, 1, gate.QueueLength(), "queue is restored from the store")
+
+	settings := &models.Settings{}
+	settings.Metrics.NotificationQuietStart = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1439) % 1440)
+	settings.Metrics.NotificationQuietEnd = minutesToHHMM((time.Now().Hour()*60 + time.Now().Minute() + 1) % 1440)
+
+	notification := &Notify{Logger: logrus.StandardLogger(), Payload: Payload{FailureType: NotifyFailureTypeSmartFailure, Subject: "during quiet hours"}}
+	require.True(t, gate.TrySend(notification, settings, false))
+	require.Equal(t, 2, gate.QueueLength())
+	require.Len(t, store.queue, 2)
+
+	require.Len(t, store.records, 1)
+	require.Equal(t, models.NotificationOutcomeSuppressed, store.records[0].Outcome)
+	require.Equal(t, models.NotificationSuppressedQuietHours, store.records[0].SuppressedBy)
+
+	// once quiet hours are over the persisted queue is cleared with the in-memory one
+	settings.Metrics.NotificationQuietStart = ""
+	settings.Metrics.NotificationQuietEnd = ""
+	settings.Metrics.NotificationRateLimit = 1
+	gate.recordSent()
+	gate.FlushQuietQueue(&Notify{Logger: logrus.StandardLogger()}, settings)
+	require.Equal(t, 0, gate.QueueLength())
+	require.Empty(t, store.queue)
+	require.Equal(t, models.NotificationSuppressedRateLimit, store.records[len(store.records)-1].SuppressedBy)
+}
+
+// maintenanceGateStore adds maintenance windows to memoryGateStore.
+type maintenanceGateStore struct {
+	memoryGateStore
+	windows []models.MaintenanceWindow
+}
+
+func (s *maintenanceGateStore) GetActiveMaintenanceWindows(_ context.Context, now time.Time) ([]models.MaintenanceWindow, error) {
+	active := []models.MaintenanceWindow{}
+	for _, window := range s.windows {
+		if window.ActiveAt(now) {
+			active = append(active, window)
+		}
+	}
+	return active, nil
+}
+
+func TestGate_SuppressesNotificationsInMaintenance(t *testing.T) {
+	t.Parallel()
+
+	store := &maintenanceGateStore{windows: []models.MaintenanceWindow{
+		{ID: 9, ScopeType: models.MaintenanceScopeHost, ScopeID: "nas", StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour)},
+	}}
+	gate := NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	require.NoError(t, gate.SetStore(context.Background(), store))
+
+	device := templateTestDevice()
+	notification := New(logrus.StandardLogger(), nil, device, false)
+	require.False(t, gate.TrySend(&notification, &models.Settings{}, false))
+
+	require.Len(t, store.records, 1)
+	require.Equal(t, models.NotificationSuppressedMaintenance, store.records[0].SuppressedBy)
+	require.NotNil(t, store.records[0].MaintenanceWindowID)
+	require.Equal(t, uint(9), *store.records[0].MaintenanceWindowID)
+
+	// other hosts are not affected by the window
+	device.HostId = "backup"
+	other := New(logrus.StandardLogger(), nil, device, false)
+	require.Nil(t, maintenanceWindow(store, &other, logrus.StandardLogger()))
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const NotifyFailureTypeIncidentEscalation = "IncidentEscalation"
+
+// NewIncidentEscalation constructs a Notify instance re-sending an incident that has not been
+// acknowledged within delay. It is delivered to the escalation URLs only.
+func NewIncidentEscalation(logger logrus.FieldLogger, appconfig config.Interface, incident models.Incident, delay time.Duration) Notify {
+	deviceIdentifier := incident.DeviceName
+	if label := strings.TrimSpace(incident.DeviceLabel); len(label) > 0 {
+		deviceIdentifier = fmt.Sprintf(fmtLabelWithName, label, incident.DeviceName)
+	}
+	hostId := strings.TrimSpace(incident.HostID)
+	unacknowledgedFor := delay.Round(time.Minute).String()
+
+	payload := Payload{
+		HostId:      hostId,
+		DeviceName:  incident.DeviceName,
+		DeviceLabel: strings.TrimSpace(incident.DeviceLabel),
+		Test:        false,
+		Date:        time.Now().Format(time.RFC3339),
+		FailureType: NotifyFailureTypeIncidentEscalation,
+	}
+	if len(hostId) > 0 {
+		payload.Subject = fmt.Sprintf("Scrutiny incident #%d unacknowledged on [host]device: [%s]%s", incident.ID, hostId, deviceIdentifier)
+	} else {
+		payload.Subject = fmt.Sprintf("Scrutiny incident #%d unacknowledged on device: %s", incident.ID, deviceIdentifier)
+	}
+
+	parts := []string{
+		fmt.Sprintf("Scrutiny incident #%d has not been acknowledged for %s.", incident.ID, unacknowledgedFor),
+	}
+	if len(hostId) > 0 {
+		parts = append(parts, fmt.Sprintf(fmtHostId, hostId))
+	}
+	parts = append(parts,
+		fmt.Sprintf("Failure Type: %s", incident.FailureType),
+		fmt.Sprintf("Device Name: %s", incident.DeviceName),
+		fmt.Sprintf("Opened At: %s", incident.OpenedAt.Format(time.RFC3339)),
+		fmt.Sprintf("Notifications Sent: %d", incident.NotificationCount),
+		"",
+		incident.Message,
+		"",
+		fmt.Sprintf(fmtDate, payload.Date),
+	)
+	payload.Message = strings.Join(parts, "\n")
+
+	rows := [][2]string{
+		{notifyRowFailureType, incident.FailureType},
+		{"Incident", fmt.Sprintf("#%d", incident.ID)},
+		{"Device", deviceIdentifier},
+	}
+	if len(hostId) > 0 {
+		rows = append(rows, [2]string{"Host Id", hostId})
+	}
+	rows = append(rows,
+		[2]string{"Opened At", incident.OpenedAt.Format(time.RFC3339)},
+		[2]string{"Unacknowledged For", unacknowledgedFor},
+		[2]string{"Notifications Sent", fmt.Sprintf("%d", incident.NotificationCount)},
+		[2]string{"Date", payload.Date},
+	)
+	payload.HTMLMessage = formatNotificationHTML(
+		payload.Subject,
+		"Scrutiny incident escalation",
+		"INCIDENT ESCALATION",
+		"#dc3545",
+		rows,
+		notifyFooterText,
+	)
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+
+		incidentDeviceID: incident.DeviceID,
+	}
+}
+
+// LoadEscalationDatabaseUrls populates n.DatabaseUrls with only the URLs marked as escalation
+// targets whose routing rules accept the current payload.
+func (n *Notify) LoadEscalationDatabaseUrls(ctx context.Context, repo database.DeviceRepo) {
+	n.history = repo
+	dbUrls, err := repo.GetNotifyUrls(ctx)
+	if err != nil {
+		n.Logger.Warnf("Could not load database notification URLs for escalation: %v", err)
+		return
+	}
+	n.loadResourceTags(ctx, repo, dbUrls)
+	event := n.Payload.Event()
+	for _, u := range dbUrls {
+		if u.Escalation && u.Accepts(event) {
+			n.DatabaseUrls = append(n.DatabaseUrls, u.URL)
+		}
+	}
+}
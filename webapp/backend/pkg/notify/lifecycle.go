@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const NotifyFailureTypeNewDevice = "NewDevice"
+const NotifyFailureTypeDeviceDisappeared = "DeviceDisappeared"
+const NotifyFailureTypeDeviceReappeared = "DeviceReappeared"
+const NotifyFailureTypeDeviceSwapped = "DeviceSwapped"
+
+// lifecycleNotifications maps lifecycle event types to their failure type, subject verb, HTML
+// banner and accent color.
+var lifecycleNotifications = map[string]struct {
+	failureType string
+	verb        string
+	banner      string
+	color       string
+}{
+	models.LifecycleEventNewDevice:         {NotifyFailureTypeNewDevice, "new device detected on", "NEW DEVICE", "#0d6efd"},
+	models.LifecycleEventDeviceDisappeared: {NotifyFailureTypeDeviceDisappeared, "device disappeared from", "DEVICE DISAPPEARED", "#fd7e14"},
+	models.LifecycleEventDeviceReappeared:  {NotifyFailureTypeDeviceReappeared, "device reappeared on", "DEVICE REAPPEARED", "#198754"},
+	models.LifecycleEventDeviceSwapped:     {NotifyFailureTypeDeviceSwapped, "device swapped on", "DEVICE SWAPPED", "#fd7e14"},
+}
+
+// LifecycleFailureType returns the notification failure type of a device lifecycle event type.
+func LifecycleFailureType(eventType string) string {
+	return lifecycleNotifications[eventType].failureType
+}
+
+// NewDeviceLifecycle constructs a Notify instance for a device lifecycle event. device is the
+// device the event is about: the new device for swaps.
+func NewDeviceLifecycle(logger logrus.FieldLogger, appconfig config.Interface, event models.DeviceLifecycleEvent, device *models.Device) Notify {
+	kind := lifecycleNotifications[event.Type]
+	payload := NewPayload(*device, false)
+	payload.FailureType = kind.failureType
+
+	deviceIdentifier := device.DeviceName
+	if label := strings.TrimSpace(device.Label); len(label) > 0 {
+		deviceIdentifier = fmt.Sprintf(fmtLabelWithName, label, device.DeviceName)
+	}
+	hostId := strings.TrimSpace(device.HostId)
+	if len(hostId) > 0 {
+		payload.Subject = fmt.Sprintf("Scrutiny %s [host]device: [%s]%s", kind.verb, hostId, deviceIdentifier)
+	} else {
+		payload.Subject = fmt.Sprintf("Scrutiny %s device: %s", kind.verb, deviceIdentifier)
+	}
+
+	var summary string
+	switch event.Type {
+	case models.LifecycleEventNewDevice:
+		summary = fmt.Sprintf("A device Scrutiny has not seen before was registered: %s.", deviceIdentifier)
+	case models.LifecycleEventDeviceDisappeared:
+		summary = fmt.Sprintf("%s is no longer reported by its host, although the host is still checking in. The disk may have failed, been pulled or dropped off the bus.", deviceIdentifier)
+	case models.LifecycleEventDeviceReappeared:
+		summary = fmt.Sprintf("%s is reported by its host again after it had disappeared.", deviceIdentifier)
+	case models.LifecycleEventDeviceSwapped:
+		summary = fmt.Sprintf("A different disk (serial %s) now occupies slot %s, previously serial %s. Record the replacement to keep the slot's lineage.",
+			event.SerialNumber, event.Slot, event.PreviousSerialNumber)
+	}
+
+	parts := []string{summary, ""}
+	if len(hostId) > 0 {
+		parts = append(parts, fmt.Sprintf(fmtHostId, hostId))
+	}
+	parts = append(parts,
+		fmt.Sprintf("Failure Type: %s", kind.failureType),
+		fmt.Sprintf("Device Name: %s", device.DeviceName),
+		fmt.Sprintf("Device Model: %s", device.ModelName),
+		fmt.Sprintf(fmtDeviceSerial, device.SerialNumber),
+	)
+	rows := [][2]string{
+		{notifyRowFailureType, kind.failureType},
+		{"Device", deviceIdentifier},
+		{"Device Model", device.ModelName},
+		{notifyRowDeviceSerial, device.SerialNumber},
+	}
+	if len(hostId) > 0 {
+		rows = append(rows, [2]string{"Host Id", hostId})
+	}
+	if event.Type == models.LifecycleEventDeviceSwapped {
+		parts = append(parts,
+			fmt.Sprintf("Slot: %s", event.Slot),
+			fmt.Sprintf("Previous Device Model: %s", event.PreviousModelName),
+			fmt.Sprintf("Previous Device Serial: %s", event.PreviousSerialNumber),
+		)
+		rows = append(rows,
+			[2]string{"Slot", event.Slot},
+			[2]string{"Previous Device Model", event.PreviousModelName},
+			[2]string{"Previous Device Serial", event.PreviousSerialNumber},
+		)
+		for _, membership := range event.PoolMemberships {
+			name := membership.Name
+			if name == "" {
+				name = membership.ID
+			}
+			pool := fmt.Sprintf("%s %s (%s)", membership.Type, name, membership.Member)
+			parts = append(parts, fmt.Sprintf("Pool Member: %s", pool))
+			rows = append(rows, [2]string{"Pool Member", pool})
+		}
+	} else if device.Location != "" {
+		parts = append(parts, fmt.Sprintf("Location: %s", device.Location))
+		rows = append(rows, [2]string{"Location", device.Location})
+	}
+	parts = append(parts, "", fmt.Sprintf(fmtDate, payload.Date))
+	rows = append(rows, [2]string{"Date", payload.Date})
+	payload.Message = strings.Join(parts, "\n")
+	payload.HTMLMessage = formatNotificationHTML(
+		payload.Subject,
+		"Scrutiny device lifecycle notification",
+		kind.banner,
+		kind.color,
+		rows,
+		notifyFooterText,
+	)
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+
+		templateDevice: device,
+	}
+}
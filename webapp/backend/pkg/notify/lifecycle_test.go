@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceLifecycle_NewDevice(t *testing.T) {
+	t.Parallel()
+
+	device := &models.Device{DeviceID: "c", HostId: "nas", DeviceName: "sdc", ModelName: "ST8000VN004", SerialNumber: "S3"}
+	event := models.DeviceLifecycleEvent{Type: models.LifecycleEventNewDevice, HostID: "nas", DeviceID: "c"}
+
+	notify := NewDeviceLifecycle(logrus.StandardLogger(), nil, event, device)
+
+	require.Equal(t, NotifyFailureTypeNewDevice, notify.Payload.FailureType)
+	require.Equal(t, "Scrutiny new device detected on [host]device: [nas]sdc", notify.Payload.Subject)
+	require.Contains(t, notify.Payload.Message, "Failure Type: NewDevice")
+	require.Contains(t, notify.Payload.HTMLMessage, "NEW DEVICE")
+	require.Equal(t, models.NotifySeverityInfo, FailureTypeSeverity(notify.Payload.FailureType))
+}
+
+func TestNewDeviceLifecycle_Swapped(t *testing.T) {
+	t.Parallel()
+
+	device := &models.Device{DeviceID: "c", HostId: "nas", DeviceName: "sdb", ModelName: "ST8000VN004", SerialNumber: "S9", Label: "Parity"}
+	event := models.DeviceLifecycleEvent{
+		Type:                 models.LifecycleEventDeviceSwapped,
+		HostID:               "nas",
+		DeviceID:             "c",
+		SerialNumber:         "S9",
+		PreviousDeviceID:     "b",
+		PreviousModelName:    "WDC WD40EFRX",
+		PreviousSerialNumber: "S2",
+		Slot:                 "Bay 2",
+		PoolMemberships:      []models.PoolMembership{{Type: "zfs", ID: "tank-guid", Name: "tank", Member: "sdb"}},
+	}
+
+	notify := NewDeviceLifecycle(logrus.StandardLogger(), nil, event, device)
+
+	require.Equal(t, NotifyFailureTypeDeviceSwapped, notify.Payload.FailureType)
+	require.Contains(t, notify.Payload.Subject, "device swapped on")
+	require.Contains(t, notify.Payload.Message, "Slot: Bay 2")
+	require.Contains(t, notify.Payload.Message, "Previous Device Serial: S2")
+	require.Contains(t, notify.Payload.Message, "Pool Member: zfs tank (sdb)")
+	require.Contains(t, notify.Payload.HTMLMessage, "DEVICE SWAPPED")
+}
+
+func TestLifecycleFailureType(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, NotifyFailureTypeDeviceDisappeared, LifecycleFailureType(models.LifecycleEventDeviceDisappeared))
+	require.Equal(t, NotifyFailureTypeDeviceReappeared, LifecycleFailureType(models.LifecycleEventDeviceReappeared))
+	require.Equal(t, models.NotifySeverityWarning, FailureTypeSeverity(NotifyFailureTypeDeviceSwapped))
+	require.Empty(t, LifecycleFailureType("unknown"))
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// NotifyFailureTypeMaintenanceSummary is sent when a maintenance window is ended early and
+// suppressed notifications during the window.
+const NotifyFailureTypeMaintenanceSummary = "MaintenanceSummary"
+
+// MaintenanceStore looks up active maintenance windows. Implemented by database.DeviceRepo.
+type MaintenanceStore interface {
+	GetActiveMaintenanceWindows(ctx context.Context, now time.Time) ([]models.MaintenanceWindow, error)
+}
+
+// MaintenanceTarget returns what this notification is about, for matching maintenance window scopes.
+// Notifications that are not about a single device, array or pool only match host windows.
+func (n *Notify) MaintenanceTarget() models.MaintenanceTarget {
+	target := models.MaintenanceTarget{
+		HostID:    strings.TrimSpace(n.Payload.HostId),
+		PoolGUID:  n.maintenancePoolGUID,
+		ArrayUUID: n.maintenanceArrayUUID,
+	}
+	if device := n.templateDevice; device != nil {
+		target.HostID = strings.TrimSpace(device.HostId)
+		target.DeviceID = device.DeviceID
+		target.DeviceName = device.DeviceName
+	}
+	return target
+}
+
+// RecordMaintenanceSuppressed records that the notification was suppressed by a maintenance window.
+func (n *Notify) RecordMaintenanceSuppressed(window models.MaintenanceWindow) {
+	windowID := window.ID
+	n.saveRecord(models.NotificationRecord{
+		Outcome:             models.NotificationOutcomeSuppressed,
+		SuppressedBy:        models.NotificationSuppressedMaintenance,
+		MaintenanceWindowID: &windowID,
+		Targets:             []models.NotificationTargetResult{},
+	})
+}
+
+// maintenanceWindow returns the active maintenance window covering the notification, if the
+// store supports maintenance windows.
+func maintenanceWindow(store interface{}, n *Notify, logger logrus.FieldLogger) *models.MaintenanceWindow {
+	maintenanceStore, ok := store.(MaintenanceStore)
+	if !ok {
+		return nil
+	}
+	target := n.MaintenanceTarget()
+	if target.HostID == "" && target.DeviceID == "" && target.PoolGUID == "" && target.ArrayUUID == "" {
+		return nil
+	}
+	now := time.Now()
+	windows, err := maintenanceStore.GetActiveMaintenanceWindows(context.Background(), now)
+	if err != nil {
+		logger.Warnf("Failed to load maintenance windows, not suppressing: %v", err)
+		return nil
+	}
+	return models.ActiveMaintenanceWindow(windows, target, now)
+}
+
+// NewMaintenanceSummary creates the summary sent when a maintenance window is ended early,
+// listing the notifications it suppressed.
+func NewMaintenanceSummary(logger logrus.FieldLogger, appconfig config.Interface, window models.MaintenanceWindow, suppressed []models.NotificationRecord) Notify {
+	name := window.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", window.ID)
+	}
+	subject := fmt.Sprintf("Scrutiny maintenance window %s ended: %d notification(s) suppressed", name, len(suppressed))
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "Maintenance window %s (%s %s) was ended early.\n", name, window.ScopeType, window.ScopeID)
+	fmt.Fprintf(&message, "%d notification(s) were suppressed during the window:\n", len(suppressed))
+	rows := make([][2]string, 0, len(suppressed))
+	for _, record := range suppressed {
+		fmt.Fprintf(&message, "- %s [%s] %s\n", record.CreatedAt.Format(time.RFC3339), record.FailureType, record.Subject)
+		rows = append(rows, [2]string{record.CreatedAt.Format(time.RFC3339), record.Subject})
+	}
+
+	payload := Payload{
+		HostId:      strings.TrimSpace(maintenanceSummaryHost(window)),
+		Date:        time.Now().Format(time.RFC3339),
+		FailureType: NotifyFailureTypeMaintenanceSummary,
+		Subject:     subject,
+		Message:     strings.TrimSpace(message.String()),
+	}
+	payload.HTMLMessage = formatNotificationHTML(
+		subject,
+		"Scrutiny maintenance window summary",
+		"MAINTENANCE ENDED",
+		"#0d6efd",
+		rows,
+		notifyFooterText,
+	)
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+	}
+}
+
+func maintenanceSummaryHost(window models.MaintenanceWindow) string {
+	if window.ScopeType == models.MaintenanceScopeHost {
+		return window.ScopeID
+	}
+	return window.MemberHostID
+}
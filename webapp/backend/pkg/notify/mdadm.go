@@ -75,11 +75,13 @@ func NewMDADMNotify(logger logrus.FieldLogger, appconfig config.Interface, array
 	// Convert to standard Payload structure for Send() functionality
 	// It uses DeviceType/DeviceName to house Array details to avoid altering the base payload.
 	payload := Payload{
+		HostId:       array.HostID,
 		DeviceType:   "MDADM",
 		DeviceName:   array.Name,
 		DeviceSerial: array.UUID,
 		DeviceLabel:  fmt.Sprintf("RAID %s", array.Level),
 		Test:         false,
+		Tags:         array.Tags,
 		Date:         mdadmPayload.Date,
 		FailureType:  mdadmPayload.FailureType,
 		Subject:      mdadmPayload.Subject,
@@ -109,5 +111,7 @@ func NewMDADMNotify(logger logrus.FieldLogger, appconfig config.Interface, array
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: payload,
+
+		maintenanceArrayUUID: array.UUID,
 	}
 }
@@ -53,6 +53,7 @@ const NotifyFailureTypePerformanceDegradation = "PerformanceDegradation"
 const NotifyFailureTypeReport = "Report"
 const NotifyFailureTypeCollectorError = "CollectorError"
 const NotifyFailureTypeReplacementRisk = "ReplacementRisk"
+const NotifyFailureTypeWarrantyExpiry = "WarrantyExpiry"
 const AppriseURLPrefix = "apprise+"
 
 const appriseCommandName = "apprise"
@@ -157,19 +158,13 @@ func ShouldNotify(logger logrus.FieldLogger, device *models.Device, smartAttrs *
 		return false
 	}
 
-	// If the device is muted, skip notification regardless of status
-	if device.Muted {
-		logger.Debugf("ShouldNotify: skipping device %s - device is muted", device.WWN)
-		return false
-	}
-
 	// setup constants for comparison
 	requiredDeviceStatus, requiredAttrStatus := requiredNotifyStatuses(statusThreshold, notifyLevel)
 
 	// This is the only case where individual attributes need not be considered.
 	// Warn level is excluded: there is no DeviceStatusWarn, so we must always check individual attributes.
 	if statusFilterAttributes == pkg.MetricsStatusFilterAttributesAll && repeatNotifications && notifyLevel != pkg.MetricsNotifyLevelWarn {
-		return pkg.DeviceStatusHas(device.DeviceStatus, requiredDeviceStatus)
+		return pkg.DeviceStatusHas(device.DeviceStatus, requiredDeviceStatus) && !suppressMuted(logger, device, deviceRepo)
 	}
 
 	failingAttributes := collectFailingAttributes(device, smartAttrs, statusFilterAttributes, cfg)
@@ -190,12 +185,25 @@ func ShouldNotify(logger logrus.FieldLogger, device *models.Device, smartAttrs *
 
 	hasPrevious := err == nil && len(lastPoints) >= 1
 	if hasQualifyingFailure(failingAttributes, smartAttrs, lastPoints, requiredAttrStatus, repeatNotifications, hasPrevious) {
-		return true
+		return !suppressMuted(logger, device, deviceRepo)
 	}
 	logger.Debugf("ShouldNotify: no qualifying failures found for device %s", device.WWN)
 	return false
 }
 
+// suppressMuted reports whether a notification that would otherwise be sent must be skipped
+// because the device is muted. The suppressed notification is recorded in the history.
+func suppressMuted(logger logrus.FieldLogger, device *models.Device, deviceRepo database.DeviceRepo) bool {
+	if !device.Muted {
+		return false
+	}
+	logger.Debugf("ShouldNotify: skipping device %s - device is muted", device.WWN)
+	suppressed := Notify{Logger: logger, Payload: NewPayload(*device, false)}
+	suppressed.SetHistory(deviceRepo)
+	suppressed.RecordSuppressed(models.NotificationSuppressedMute)
+	return true
+}
+
 // hasQualifyingFailure reports whether any failing attribute meets the required status and,
 // when repeat notifications are disabled, has a transformed value that changed from the previous
 // submission (lastPoints). A missing previous submission (hasPrevious=false) is treated as a change.
@@ -216,12 +224,16 @@ func hasQualifyingFailure(failingAttributes []string, smartAttrs *measurements.S
 }
 
 type Payload struct {
-	HostId       string `json:"host_id,omitempty"`      // host id (optional)
-	DeviceType   string `json:"device_type"`            // ATA/SCSI/NVMe
-	DeviceName   string `json:"device_name"`            // dev/sda
-	DeviceSerial string `json:"device_serial"`          // WDDJ324KSO
-	DeviceLabel  string `json:"device_label,omitempty"` //user-provided label (optional)
-	Test         bool   `json:"test"`                   // false
+	HostId         string `json:"host_id,omitempty"`         // host id (optional)
+	DeviceType     string `json:"device_type"`               // ATA/SCSI/NVMe
+	DeviceProtocol string `json:"device_protocol,omitempty"` // ATA/SCSI/NVMe, used for routing rules
+	DeviceName     string `json:"device_name"`               // dev/sda
+	DeviceSerial   string `json:"device_serial"`             // WDDJ324KSO
+	DeviceLabel    string `json:"device_label,omitempty"`    //user-provided label (optional)
+	Test           bool   `json:"test"`                      // false
+
+	// Tags are the tags of the device, array or pool, used for routing rules.
+	Tags []string `json:"tags,omitempty"`
 
 	//private, populated during init (marked as Public for JSON serialization)
 	Date        string `json:"date"`         //populated by Send function.
@@ -229,18 +241,23 @@ type Payload struct {
 	Subject     string `json:"subject"`
 	Message     string `json:"message"`
 
+	// Delayed is set when the notification is resent from the retry queue.
+	Delayed bool `json:"delayed,omitempty"`
+
 	// HTMLMessage is an optional HTML version of Message, used for SMTP emails.
 	HTMLMessage string `json:"-"`
 }
 
 func NewPayload(device models.Device, test bool, currentTime ...time.Time) Payload {
 	payload := Payload{
-		HostId:       strings.TrimSpace(device.HostId),
-		DeviceType:   device.DeviceType,
-		DeviceName:   device.DeviceName,
-		DeviceSerial: device.SerialNumber,
-		DeviceLabel:  strings.TrimSpace(device.Label),
-		Test:         test,
+		HostId:         strings.TrimSpace(device.HostId),
+		DeviceType:     device.DeviceType,
+		DeviceProtocol: device.DeviceProtocol,
+		DeviceName:     device.DeviceName,
+		DeviceSerial:   device.SerialNumber,
+		DeviceLabel:    strings.TrimSpace(device.Label),
+		Test:           test,
+		Tags:           device.Tags,
 	}
 
 	//validate that the Payload is populated
@@ -453,6 +470,8 @@ func New(logger logrus.FieldLogger, appconfig config.Interface, device models.De
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: NewPayload(device, test),
+
+		templateDevice: &device,
 	}
 }
 
@@ -465,33 +484,73 @@ type Notify struct {
 	// Set by the caller via LoadDatabaseUrls before calling Send().
 	// These are merged with config/env URLs during Send().
 	DatabaseUrls []string
+
+	// history records every delivery attempt when set (see SetHistory).
+	history HistoryStore
+
+	// template data beyond the payload, see TemplateData
+	templateDevice     *models.Device
+	templateAttributes []TemplateAttribute
+	templateRisk       *TemplateRisk
+	templatesApplied   bool
+
+	// maintenanceArrayUUID and maintenancePoolGUID scope array and ZFS pool notifications for
+	// maintenance windows, see MaintenanceTarget
+	maintenanceArrayUUID string
+	maintenancePoolGUID  string
+
+	// incidentDeviceID is the device of an incident escalation, whose tags routing rules match on
+	incidentDeviceID string
+
+	// severity overrides the severity derived from the failure type, for digests
+	severity string
+
+	// retry is the queued delivery this notification resends, see NewRetry. retryEvent is its
+	// original webhook event, so receivers see the same delivery ID.
+	retry      *models.NotificationRetry
+	retryEvent *WebhookEvent
 }
 
 // LoadDatabaseUrls queries the repository for all UI-sourced notification URLs
-// and populates n.DatabaseUrls. Safe to call even if the repository returns an
-// error (degrades gracefully to config-only URLs).
+// whose routing rules accept the current payload and populates n.DatabaseUrls.
+// Escalation URLs are skipped; they only receive incident escalations.
+// The payload must be set before calling. Safe to call even if the repository
+// returns an error (degrades gracefully to config-only URLs).
 func (n *Notify) LoadDatabaseUrls(ctx context.Context, repo database.DeviceRepo) {
+	n.history = repo
 	dbUrls, err := repo.GetNotifyUrls(ctx)
 	if err != nil {
 		n.Logger.Warnf("Could not load database notification URLs: %v", err)
 		return
 	}
+	n.loadResourceTags(ctx, repo, dbUrls)
+	event := n.Payload.Event()
 	for _, u := range dbUrls {
+		if u.Escalation {
+			continue
+		}
+		if !u.Accepts(event) {
+			n.Logger.Debugf("Skipping notification URL %d: routing rules do not match %s", u.ID, event.FailureType)
+			continue
+		}
 		n.DatabaseUrls = append(n.DatabaseUrls, u.URL)
 	}
 }
 
 // LoadHeartbeatDatabaseUrls populates n.DatabaseUrls with only the URLs that
-// have HeartbeatEnabled set to true. Used by the heartbeat monitor so users
-// can choose which endpoints receive periodic health pings.
+// have HeartbeatEnabled set to true and whose routing rules accept heartbeats.
+// Used by the heartbeat monitor so users can choose which endpoints receive
+// periodic health pings.
 func (n *Notify) LoadHeartbeatDatabaseUrls(ctx context.Context, repo database.DeviceRepo) {
+	n.history = repo
 	dbUrls, err := repo.GetNotifyUrls(ctx)
 	if err != nil {
 		n.Logger.Warnf("Could not load database notification URLs for heartbeat: %v", err)
 		return
 	}
+	event := n.Payload.Event()
 	for _, u := range dbUrls {
-		if u.HeartbeatEnabled {
+		if u.HeartbeatEnabled && !u.Escalation && u.Accepts(event) {
 			n.DatabaseUrls = append(n.DatabaseUrls, u.URL)
 		}
 	}
@@ -499,6 +558,8 @@ func (n *Notify) LoadHeartbeatDatabaseUrls(ctx context.Context, repo database.De
 
 // Send dispatches notifications to all configured URLs (config/env + database).
 func (n *Notify) Send() error {
+	n.ApplyTemplates()
+
 	// Retrieve list of notification endpoints from config file / env var
 	configUrls := n.Config.GetStringSlice("notify.urls")
 	configString := n.Config.GetString("notify.urls")
@@ -526,7 +587,9 @@ func (n *Notify) Send() error {
 
 	if len(uniqueUrls) == 0 {
 		n.Logger.Warnf("No notification endpoints configured. Cannot send notification.")
-		return errors.New("no notification endpoints configured")
+		err := errors.New("no notification endpoints configured")
+		n.recordDelivery(nil, err)
+		return err
 	}
 
 	return n.sendToUrls(uniqueUrls)
@@ -577,9 +640,12 @@ func StripApprisePrefix(rawURL string) string {
 // sendToUrls routes URLs to the appropriate sender (webhook, script, or shoutrrr)
 // and dispatches them in parallel.
 func (n *Notify) sendToUrls(urls []string) error {
+	n.ApplyTemplates()
+
 	notifyApprise := []string{}
 	notifySMTP := []string{}
 	notifyWebhooks := []string{}
+	notifySignedWebhooks := []string{}
 	notifyScripts := []string{}
 	notifyShoutrrr := []string{}
 
@@ -589,6 +655,8 @@ func (n *Notify) sendToUrls(urls []string) error {
 			notifyApprise = append(notifyApprise, u)
 		case strings.HasPrefix(u, "smtp://"):
 			notifySMTP = append(notifySMTP, u)
+		case IsSignedWebhookURL(u):
+			notifySignedWebhooks = append(notifySignedWebhooks, u)
 		case strings.HasPrefix(u, "https://"), strings.HasPrefix(u, "http://"):
 			notifyWebhooks = append(notifyWebhooks, u)
 		case strings.HasPrefix(u, "script://"):
@@ -603,34 +671,57 @@ func (n *Notify) sendToUrls(urls []string) error {
 	n.Logger.Debugf("Configured SMTP URLs: %v", notifySMTP)
 	n.Logger.Debugf("Configured scripts: %v", notifyScripts)
 	n.Logger.Debugf("Configured webhooks: %v", notifyWebhooks)
+	n.Logger.Debugf("Configured signed webhooks: %d", len(notifySignedWebhooks))
 	n.Logger.Debugf("Configured shoutrrr: %v", notifyShoutrrr)
 
 	var eg errgroup.Group
+	targets := &targetRecorder{}
 
 	for _, u := range notifyApprise {
 		targetUrl := u
-		eg.Go(func() error { return n.SendAppriseNotification(targetUrl) })
+		eg.Go(func() error { return targets.record(targetUrl, n.SendAppriseNotification(targetUrl)) })
 	}
 	for _, u := range notifySMTP {
 		targetURL := u
-		eg.Go(func() error { return n.SendSMTPNotification(targetURL) })
+		eg.Go(func() error { return targets.record(targetURL, n.SendSMTPNotification(targetURL)) })
 	}
 	for _, u := range notifyWebhooks {
 		targetUrl := u
-		eg.Go(func() error { return n.SendWebhookNotification(targetUrl) })
+		eg.Go(func() error { return targets.record(targetUrl, n.SendWebhookNotification(targetUrl)) })
+	}
+	var event *WebhookEvent
+	if len(notifySignedWebhooks) > 0 {
+		// one event per notification, so every target sees the same delivery ID
+		var eventErr error
+		if event = n.retryEvent; event == nil {
+			var built WebhookEvent
+			built, eventErr = n.WebhookEvent()
+			event = &built
+		}
+		for _, u := range notifySignedWebhooks {
+			targetUrl := u
+			if eventErr != nil {
+				eg.Go(func() error { return targets.record(targetUrl, eventErr) })
+				continue
+			}
+			eg.Go(func() error { return targets.record(targetUrl, n.SendSignedWebhookNotification(targetUrl, *event)) })
+		}
 	}
 	for _, u := range notifyScripts {
 		targetUrl := u
-		eg.Go(func() error { return n.SendScriptNotification(targetUrl) })
+		eg.Go(func() error { return targets.record(targetUrl, n.SendScriptNotification(targetUrl)) })
 	}
 	for _, u := range notifyShoutrrr {
 		targetUrl := u
-		eg.Go(func() error { return n.SendShoutrrrNotification(targetUrl) })
+		eg.Go(func() error { return targets.record(targetUrl, n.SendShoutrrrNotification(targetUrl)) })
 	}
 
 	n.Logger.Debugf("Main: waiting for notifications to complete.")
 
-	if err := eg.Wait(); err == nil {
+	err := eg.Wait()
+	n.recordDelivery(targets.results, err)
+	n.queueRetries(targets.failed, event)
+	if err == nil {
 		n.Logger.Info("Successfully sent notifications. Check logs for more information.")
 		return nil
 	} else {
@@ -674,6 +765,9 @@ func (n *Notify) SendAppriseNotification(rawURL string) error {
 			strings.TrimSpace(stderr.String()),
 			strings.TrimSpace(stdout.String()),
 		}, "\n"))
+		if errors.Is(err, exec.ErrNotFound) {
+			return permanent(fmt.Errorf("failed to send Apprise notification to %s: %w", targetURL, err))
+		}
 		if output == "" {
 			return fmt.Errorf("failed to send Apprise notification to %s: %w", targetURL, err)
 		}
@@ -708,7 +802,7 @@ func (n *Notify) SendScriptNotification(scriptUrl string) error {
 
 	if !utils.FileExists(scriptPath) {
 		n.Logger.Errorf("Script does not exist: %s", scriptPath)
-		return errors.New(fmt.Sprintf("custom script path does not exist: %s", scriptPath))
+		return permanent(errors.New(fmt.Sprintf("custom script path does not exist: %s", scriptPath)))
 	}
 
 	copyEnv := os.Environ()
@@ -748,8 +842,9 @@ func (n *Notify) SendShoutrrrNotification(shoutrrrUrl string) error {
 
 	sender, err := shoutrrr.CreateSender(senderUrl)
 	if err != nil {
+		// an unknown service or malformed URL
 		n.Logger.Errorf("An error occurred while sending notifications %v: %v", shoutrrrUrl, err)
-		return err
+		return permanent(err)
 	}
 
 	//sender.SetLogger(n.Logger.)
@@ -758,7 +853,7 @@ func (n *Notify) SendShoutrrrNotification(shoutrrrUrl string) error {
 
 	if err != nil {
 		n.Logger.Errorf("An error occurred  occurred while generating notification payload for %s:\n %v", serviceName, shoutrrrUrl, err)
-		return err
+		return permanent(err)
 	}
 
 	// For SMTP with HTML, send the HTML version; other services get plain text
@@ -934,16 +1029,17 @@ func NewMissedPing(logger logrus.FieldLogger, appconfig config.Interface, device
 
 	// Convert MissedPingPayload to standard Payload for compatibility with Send()
 	payload := Payload{
-		HostId:       missedPingPayload.HostId,
-		DeviceType:   device.DeviceType,
-		DeviceName:   missedPingPayload.DeviceName,
-		DeviceSerial: missedPingPayload.DeviceSerial,
-		DeviceLabel:  missedPingPayload.DeviceLabel,
-		Test:         false,
-		Date:         missedPingPayload.Date,
-		FailureType:  missedPingPayload.FailureType,
-		Subject:      missedPingPayload.Subject,
-		Message:      missedPingPayload.Message,
+		HostId:         missedPingPayload.HostId,
+		DeviceType:     device.DeviceType,
+		DeviceProtocol: device.DeviceProtocol,
+		DeviceName:     missedPingPayload.DeviceName,
+		DeviceSerial:   missedPingPayload.DeviceSerial,
+		DeviceLabel:    missedPingPayload.DeviceLabel,
+		Test:           false,
+		Date:           missedPingPayload.Date,
+		FailureType:    missedPingPayload.FailureType,
+		Subject:        missedPingPayload.Subject,
+		Message:        missedPingPayload.Message,
 	}
 	rows := [][2]string{
 		{notifyRowFailureType, missedPingPayload.FailureType},
@@ -971,6 +1067,8 @@ func NewMissedPing(logger logrus.FieldLogger, appconfig config.Interface, device
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: payload,
+
+		templateDevice: &device,
 	}
 }
 
@@ -985,7 +1083,8 @@ type MissedPingDigestDevice struct {
 	Label        string
 }
 
-// NewMissedPingDigest creates a Notify instance for a batched missed ping digest
+// NewMissedPingDigest creates a Notify instance for a batched missed ping digest. When all devices
+// belong to one host, the digest carries its host ID so host routing rules apply.
 func NewMissedPingDigest(logger logrus.FieldLogger, appconfig config.Interface, devices []MissedPingDigestDevice, timeoutMinutes int) Notify {
 	count := len(devices)
 	subject := fmt.Sprintf("Scrutiny: %d device(s) missed collector pings", count)
@@ -1023,6 +1122,13 @@ func NewMissedPingDigest(logger logrus.FieldLogger, appconfig config.Interface,
 		Message:     message,
 		HTMLMessage: htmlMessage,
 	}
+	for i, d := range devices {
+		if i > 0 && d.HostId != payload.HostId {
+			payload.HostId = ""
+			break
+		}
+		payload.HostId = d.HostId
+	}
 
 	return Notify{
 		Logger:  logger,
@@ -1265,16 +1371,17 @@ func NewPerformanceDegradation(logger logrus.FieldLogger, appconfig config.Inter
 	degradationPayload := NewPerformanceDegradationPayload(device, metric, baselineAvg, currentValue, deviationPct)
 
 	payload := Payload{
-		HostId:       degradationPayload.HostId,
-		DeviceType:   device.DeviceType,
-		DeviceName:   degradationPayload.DeviceName,
-		DeviceSerial: degradationPayload.DeviceSerial,
-		DeviceLabel:  degradationPayload.DeviceLabel,
-		Test:         false,
-		Date:         degradationPayload.Date,
-		FailureType:  degradationPayload.FailureType,
-		Subject:      degradationPayload.Subject,
-		Message:      degradationPayload.Message,
+		HostId:         degradationPayload.HostId,
+		DeviceType:     device.DeviceType,
+		DeviceProtocol: device.DeviceProtocol,
+		DeviceName:     degradationPayload.DeviceName,
+		DeviceSerial:   degradationPayload.DeviceSerial,
+		DeviceLabel:    degradationPayload.DeviceLabel,
+		Test:           false,
+		Date:           degradationPayload.Date,
+		FailureType:    degradationPayload.FailureType,
+		Subject:        degradationPayload.Subject,
+		Message:        degradationPayload.Message,
 	}
 	payload.HTMLMessage = formatNotificationHTML(
 		payload.Subject,
@@ -1300,6 +1407,8 @@ func NewPerformanceDegradation(logger logrus.FieldLogger, appconfig config.Inter
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: payload,
+
+		templateDevice: device,
 	}
 }
 
@@ -1356,16 +1465,17 @@ func NewCollectorError(logger logrus.FieldLogger, appconfig config.Interface, de
 	message := strings.Join(messageParts, "\n")
 
 	payload := Payload{
-		HostId:       strings.TrimSpace(device.HostId),
-		DeviceType:   device.DeviceType,
-		DeviceName:   device.DeviceName,
-		DeviceSerial: device.SerialNumber,
-		DeviceLabel:  strings.TrimSpace(device.Label),
-		Test:         false,
-		Date:         time.Now().Format(time.RFC3339),
-		FailureType:  NotifyFailureTypeCollectorError,
-		Subject:      subject,
-		Message:      message,
+		HostId:         strings.TrimSpace(device.HostId),
+		DeviceType:     device.DeviceType,
+		DeviceProtocol: device.DeviceProtocol,
+		DeviceName:     device.DeviceName,
+		DeviceSerial:   device.SerialNumber,
+		DeviceLabel:    strings.TrimSpace(device.Label),
+		Test:           false,
+		Date:           time.Now().Format(time.RFC3339),
+		FailureType:    NotifyFailureTypeCollectorError,
+		Subject:        subject,
+		Message:        message,
 	}
 	payload.HTMLMessage = formatNotificationHTML(
 		payload.Subject,
@@ -1407,5 +1517,7 @@ func NewCollectorError(logger logrus.FieldLogger, appconfig config.Interface, de
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: payload,
+
+		templateDevice: device,
 	}
 }
@@ -63,6 +63,11 @@ func TestShouldNotify_MustSkipMutedDevices(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 	fakeDatabase := mock_database.NewMockDeviceRepo(mockCtrl)
+	fakeDatabase.EXPECT().SaveNotificationRecord(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, record *models.NotificationRecord) error {
+		require.Equal(t, models.NotificationOutcomeSuppressed, record.Outcome)
+		require.Equal(t, models.NotificationSuppressedMute, record.SuppressedBy)
+		return nil
+	})
 	//assert
 	require.False(t, ShouldNotify(logrus.StandardLogger(), &device, &smartAttrs, pkg.MetricsNotifyLevelFail, statusThreshold, notifyFilterAttributes, true, "", &gin.Context{}, fakeDatabase, nil))
 }
@@ -780,6 +785,23 @@ func TestFormatHTMLMissedPingDigest_UsesResponsiveAlertLayout(t *testing.T) {
 	require.NotContains(t, message, "<parity drive>")
 }
 
+func TestNewMissedPingDigest_SetsHostOfSingleHostDigests(t *testing.T) {
+	t.Parallel()
+
+	single := NewMissedPingDigest(logrus.New(), nil, []MissedPingDigestDevice{
+		{DeviceName: "/dev/sda", HostId: "nas-01"},
+		{DeviceName: "/dev/sdb", HostId: "nas-01"},
+	}, 60)
+	require.Equal(t, "nas-01", single.Payload.HostId)
+	require.Equal(t, "nas-01", single.Payload.Event().HostID)
+
+	mixed := NewMissedPingDigest(logrus.New(), nil, []MissedPingDigestDevice{
+		{DeviceName: "/dev/sda", HostId: "nas-01"},
+		{DeviceName: "/dev/sda", HostId: "nas-02"},
+	}, 60)
+	require.Empty(t, mixed.Payload.HostId)
+}
+
 func TestNormalizeGotifyURL_Port8080_AddsDisableTLS(t *testing.T) {
 	t.Parallel()
 	result := normalizeGotifyURL("gotify://192.168.2.135:8080/A-iI4ewTwguZo_V")
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	colmodels "github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/sirupsen/logrus"
+)
+
+// Resolved notifications, each enabled by its own metrics.notify_on_* setting.
+const NotifyFailureTypeDeviceRecovered = "DeviceRecovered"
+const NotifyFailureTypeMissedPingRecovered = "MissedPingRecovered"
+const NotifyFailureTypeArrayRecovered = "ArrayRecovered"
+const NotifyFailureTypeCollectorErrorCleared = "CollectorErrorCleared"
+
+const recoveryBannerColor = "#198754"
+
+// NewDeviceRecovered constructs a Notify instance for a device whose status returned to passed,
+// after new SMART data or a manual status reset.
+func NewDeviceRecovered(logger logrus.FieldLogger, appconfig config.Interface, device *models.Device, previousStatus pkg.DeviceStatus) Notify {
+	summary := fmt.Sprintf("%s is passing again, it was %s.", recoveryDeviceIdentifier(device), describeDeviceStatus(previousStatus))
+	return newDeviceRecovery(logger, appconfig, device, NotifyFailureTypeDeviceRecovered, "device recovered", "DEVICE RECOVERED", summary,
+		[2]string{"Previous Status", describeDeviceStatus(previousStatus)})
+}
+
+// NewMissedPingRecovered constructs a Notify instance for a device that reports again after a
+// missed ping notification.
+func NewMissedPingRecovered(logger logrus.FieldLogger, appconfig config.Interface, device *models.Device, lastSeen time.Time) Notify {
+	summary := fmt.Sprintf("%s is reporting again after missing its collector pings.", recoveryDeviceIdentifier(device))
+	return newDeviceRecovery(logger, appconfig, device, NotifyFailureTypeMissedPingRecovered, "collector ping recovered", "PING RECOVERED", summary,
+		[2]string{"Last Seen", lastSeen.Format(time.RFC3339)})
+}
+
+// NewCollectorErrorCleared constructs a Notify instance for a device that uploaded SMART data
+// again after a collector error notification.
+func NewCollectorErrorCleared(logger logrus.FieldLogger, appconfig config.Interface, device *models.Device) Notify {
+	summary := fmt.Sprintf("The collector error on %s has cleared, SMART data was uploaded successfully.", recoveryDeviceIdentifier(device))
+	return newDeviceRecovery(logger, appconfig, device, NotifyFailureTypeCollectorErrorCleared, "collector error cleared", "COLLECTOR ERROR CLEARED", summary)
+}
+
+// NewMDADMRecovered constructs a Notify instance for an mdadm array that is no longer degraded,
+// typically once its rebuild finished.
+func NewMDADMRecovered(logger logrus.FieldLogger, appconfig config.Interface, array models.MDADMArray, metrics colmodels.MDADMMetrics) Notify {
+	rows := [][2]string{
+		{"Array Name", array.Name},
+		{"Array UUID", array.UUID},
+		{"RAID Level", array.Level},
+		{"Array State", metrics.State},
+		{"Active Devices", fmt.Sprintf("%d", metrics.ActiveDevices)},
+		{"Working Devices", fmt.Sprintf("%d", metrics.WorkingDevices)},
+		{"Failed Devices", fmt.Sprintf("%d", metrics.FailedDevices)},
+	}
+	n := newArrayRecovery(logger, appconfig, array.HostID, "MDADM", array.Name, array.UUID, fmt.Sprintf("RAID %s", array.Level),
+		fmt.Sprintf("RAID array %s is no longer degraded.", array.Name), rows)
+	n.maintenanceArrayUUID = array.UUID
+	n.Payload.Tags = array.Tags
+	return n
+}
+
+// NewZFSPoolRecovered constructs a Notify instance for a ZFS pool that is ONLINE again, typically
+// once its resilver finished.
+func NewZFSPoolRecovered(logger logrus.FieldLogger, appconfig config.Interface, pool models.ZFSPool, previousStatus models.ZFSPoolStatus) Notify {
+	rows := [][2]string{
+		{"Pool Name", pool.Name},
+		{"Pool GUID", pool.GUID},
+		{"Previous Status", string(previousStatus)},
+		{"Pool Status", string(pool.Status)},
+	}
+	n := newArrayRecovery(logger, appconfig, pool.HostID, "ZFS", pool.Name, pool.GUID, "ZFS pool",
+		fmt.Sprintf("ZFS pool %s is %s again, it was %s.", pool.Name, pool.Status, previousStatus), rows)
+	n.maintenancePoolGUID = pool.GUID
+	n.Payload.Tags = pool.Tags
+	return n
+}
+
+func newDeviceRecovery(logger logrus.FieldLogger, appconfig config.Interface, device *models.Device, failureType, verb, banner, summary string, extra ...[2]string) Notify {
+	payload := NewPayload(*device, false)
+	payload.FailureType = failureType
+
+	deviceIdentifier := recoveryDeviceIdentifier(device)
+	hostId := strings.TrimSpace(device.HostId)
+	if len(hostId) > 0 {
+		payload.Subject = fmt.Sprintf("Scrutiny %s on [host]device: [%s]%s", verb, hostId, deviceIdentifier)
+	} else {
+		payload.Subject = fmt.Sprintf("Scrutiny %s on device: %s", verb, deviceIdentifier)
+	}
+
+	rows := [][2]string{
+		{notifyRowFailureType, failureType},
+		{"Device", deviceIdentifier},
+		{notifyRowDeviceSerial, device.SerialNumber},
+		{notifyRowDeviceType, device.DeviceType},
+	}
+	if len(hostId) > 0 {
+		rows = append(rows, [2]string{"Host Id", hostId})
+	}
+	rows = append(rows, extra...)
+	payload.Message, payload.HTMLMessage = formatRecovery(payload.Subject, summary, rows, payload.Date, banner)
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+
+		templateDevice: device,
+	}
+}
+
+func newArrayRecovery(logger logrus.FieldLogger, appconfig config.Interface, hostId, arrayType, name, id, label, summary string, rows [][2]string) Notify {
+	hostId = strings.TrimSpace(hostId)
+	payload := Payload{
+		HostId:       hostId,
+		DeviceType:   arrayType,
+		DeviceName:   name,
+		DeviceSerial: id,
+		DeviceLabel:  label,
+		Date:         time.Now().Format(time.RFC3339),
+		FailureType:  NotifyFailureTypeArrayRecovered,
+	}
+	if len(hostId) > 0 {
+		payload.Subject = fmt.Sprintf("Scrutiny %s array recovered on [host]array: [%s]%s", arrayType, hostId, name)
+		rows = append(rows, [2]string{"Host Id", hostId})
+	} else {
+		payload.Subject = fmt.Sprintf("Scrutiny %s array recovered: %s", arrayType, name)
+	}
+	rows = append([][2]string{{notifyRowFailureType, NotifyFailureTypeArrayRecovered}}, rows...)
+	payload.Message, payload.HTMLMessage = formatRecovery(payload.Subject, summary, rows, payload.Date, "ARRAY RECOVERED")
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+	}
+}
+
+// formatRecovery renders the plain text and HTML bodies of a resolved notification.
+func formatRecovery(subject, summary string, rows [][2]string, date, banner string) (string, string) {
+	parts := []string{summary, ""}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", row[0], row[1]))
+	}
+	parts = append(parts, "", fmt.Sprintf(fmtDate, date))
+	rows = append(rows, [2]string{"Date", date})
+
+	html := formatNotificationHTML(subject, "Scrutiny resolved notification", banner, recoveryBannerColor, rows, notifyFooterText)
+	return strings.Join(parts, "\n"), html
+}
+
+func recoveryDeviceIdentifier(device *models.Device) string {
+	if label := strings.TrimSpace(device.Label); len(label) > 0 {
+		return fmt.Sprintf(fmtLabelWithName, label, device.DeviceName)
+	}
+	return device.DeviceName
+}
+
+// describeDeviceStatus names the failures of a device status, e.g. "failed (SMART, Scrutiny)".
+func describeDeviceStatus(status pkg.DeviceStatus) string {
+	reasons := []string{}
+	if pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedSmart) {
+		reasons = append(reasons, "SMART")
+	}
+	if pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedScrutiny) {
+		reasons = append(reasons, "Scrutiny")
+	}
+	if len(reasons) == 0 {
+		return "failed"
+	}
+	return fmt.Sprintf("failed (%s)", strings.Join(reasons, ", "))
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	colmodels "github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceRecovered(t *testing.T) {
+	t.Parallel()
+
+	device := &models.Device{DeviceID: "dev-1", HostId: "nas", DeviceName: "sda", Label: "Parity", SerialNumber: "S1"}
+
+	notify := NewDeviceRecovered(logrus.StandardLogger(), nil, device, pkg.DeviceStatusFailedSmart|pkg.DeviceStatusFailedScrutiny)
+
+	require.Equal(t, NotifyFailureTypeDeviceRecovered, notify.Payload.FailureType)
+	require.Equal(t, "Scrutiny device recovered on [host]device: [nas]Parity (sda)", notify.Payload.Subject)
+	require.Contains(t, notify.Payload.Message, "it was failed (SMART, Scrutiny)")
+	require.Contains(t, notify.Payload.HTMLMessage, "DEVICE RECOVERED")
+	require.Equal(t, models.NotifySeverityInfo, FailureTypeSeverity(notify.Payload.FailureType))
+}
+
+func TestNewMissedPingRecovered(t *testing.T) {
+	t.Parallel()
+
+	lastSeen := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	notify := NewMissedPingRecovered(logrus.StandardLogger(), nil, &models.Device{DeviceName: "sdb"}, lastSeen)
+
+	require.Equal(t, NotifyFailureTypeMissedPingRecovered, notify.Payload.FailureType)
+	require.Equal(t, "Scrutiny collector ping recovered on device: sdb", notify.Payload.Subject)
+	require.Contains(t, notify.Payload.Message, "Last Seen: 2026-10-19T08:00:00Z")
+}
+
+func TestNewMDADMRecovered(t *testing.T) {
+	t.Parallel()
+
+	array := models.MDADMArray{UUID: "md-uuid", Name: "md0", Level: "raid1", HostID: "nas"}
+	notify := NewMDADMRecovered(logrus.StandardLogger(), nil, array, colmodels.MDADMMetrics{State: "clean", ActiveDevices: 2, WorkingDevices: 2})
+
+	require.Equal(t, NotifyFailureTypeArrayRecovered, notify.Payload.FailureType)
+	require.Equal(t, "Scrutiny MDADM array recovered on [host]array: [nas]md0", notify.Payload.Subject)
+	require.Contains(t, notify.Payload.Message, "Array State: clean")
+	require.Contains(t, notify.Payload.Message, "Failed Devices: 0")
+	require.Equal(t, "md-uuid", notify.maintenanceArrayUUID)
+	require.Equal(t, models.NotifySeverityInfo, FailureTypeSeverity(notify.Payload.FailureType))
+}
+
+func TestNewZFSPoolRecovered(t *testing.T) {
+	t.Parallel()
+
+	pool := models.ZFSPool{GUID: "123", Name: "tank", Status: models.ZFSPoolStatusOnline}
+	notify := NewZFSPoolRecovered(logrus.StandardLogger(), nil, pool, models.ZFSPoolStatusDegraded)
+
+	require.Equal(t, NotifyFailureTypeArrayRecovered, notify.Payload.FailureType)
+	require.Contains(t, notify.Payload.Message, "ZFS pool tank is ONLINE again, it was DEGRADED.")
+	require.Equal(t, "123", notify.MaintenanceTarget().PoolGUID)
+	require.True(t, models.MaintenanceWindow{ScopeType: models.MaintenanceScopePool, ScopeID: "123"}.Covers(notify.MaintenanceTarget()),
+		"a pool maintenance window suppresses the pool's notifications")
+}
+
+func TestNewCollectorErrorCleared(t *testing.T) {
+	t.Parallel()
+
+	notify := NewCollectorErrorCleared(logrus.StandardLogger(), nil, &models.Device{DeviceName: "sdc", HostId: "nas"})
+
+	require.Equal(t, NotifyFailureTypeCollectorErrorCleared, notify.Payload.FailureType)
+	require.Contains(t, notify.Payload.Subject, "collector error cleared")
+	require.Contains(t, notify.Payload.HTMLMessage, "COLLECTOR ERROR CLEARED")
+}
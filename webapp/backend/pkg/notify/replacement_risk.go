@@ -79,6 +79,9 @@ func NewReplacementRisk(logger logrus.FieldLogger, appconfig config.Interface, d
 		Logger:  logger,
 		Config:  appconfig,
 		Payload: payload,
+
+		templateDevice: device,
+		templateRisk:   &TemplateRisk{Score: score, Category: string(category)},
 	}
 }
 
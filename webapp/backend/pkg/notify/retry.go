@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRetryMaxAge is how long failed deliveries are retried before they are dead-lettered
+	DefaultRetryMaxAge = 24 * time.Hour
+
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = time.Hour
+
+	delayedSubjectPrefix = "[Delayed] "
+)
+
+// retryMaxAge is set once at startup from notify.retry.max_age_hours, see ConfigureRetries.
+var retryMaxAge = DefaultRetryMaxAge
+
+// RetryStore persists deliveries that failed so they can be retried. Implemented by
+// database.DeviceRepo; notifications use it when their history store also implements it.
+type RetryStore interface {
+	EnqueueNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error
+}
+
+// ConfigureRetries sets how long failed deliveries are retried. A max age of 0 disables the
+// retry queue.
+func ConfigureRetries(maxAge time.Duration) {
+	retryMaxAge = maxAge
+}
+
+// RetryMaxAge returns how long failed deliveries are retried, 0 when retries are disabled.
+func RetryMaxAge() time.Duration {
+	return retryMaxAge
+}
+
+// RetryBackoff returns the delay before the next attempt of a retry that has failed attempts
+// times: one minute, doubling per attempt, capped at one hour.
+func RetryBackoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// permanentError marks a delivery failure that resending cannot fix, such as a request the
+// receiver rejected or an invalid notification URL. Targets failing this way are not queued.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as not worth retrying.
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable reports whether a failed delivery may succeed when it is sent again.
+func IsRetryable(err error) bool {
+	var permanentErr *permanentError
+	return err != nil && !errors.As(err, &permanentErr)
+}
+
+// queueRetries stores the targets that failed with a retryable error so the retry monitor can
+// resend them. Test
+// notifications and resends of queued retries are not queued.
+func (n *Notify) queueRetries(failedURLs []string, event *WebhookEvent) {
+	if len(failedURLs) == 0 || retryMaxAge <= 0 || n.Payload.Test || n.retry != nil {
+		return
+	}
+	store, ok := n.history.(RetryStore)
+	if !ok {
+		return
+	}
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		n.Logger.Warnf("Failed to encode notification for retry: %v", err)
+		return
+	}
+	var encodedEvent []byte
+	if event != nil {
+		if encodedEvent, err = json.Marshal(event); err != nil {
+			n.Logger.Warnf("Failed to encode webhook event for retry: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for _, rawURL := range failedURLs {
+		retry := models.NotificationRetry{
+			URL:           rawURL,
+			FailureType:   n.Payload.FailureType,
+			Subject:       n.Payload.Subject,
+			Payload:       string(payload),
+			HTMLMessage:   n.Payload.HTMLMessage,
+			Status:        models.NotificationRetryPending,
+			NextAttemptAt: now.Add(RetryBackoff(1)),
+		}
+		if IsSignedWebhookURL(rawURL) {
+			retry.WebhookEvent = string(encodedEvent)
+		}
+		if err := store.EnqueueNotificationRetry(context.Background(), &retry); err != nil {
+			n.Logger.Warnf("Failed to queue notification to %s for retry: %v", MaskNotifyUrl(rawURL), err)
+			continue
+		}
+		n.Logger.Infof("Queued notification to %s for retry", MaskNotifyUrl(rawURL))
+	}
+}
+
+// NewRetry rebuilds a queued notification for another delivery attempt. The notification is
+// marked as delayed: its subject is prefixed with "[Delayed]", the message notes when delivery
+// first failed, and webhook payloads carry "delayed": true.
+func NewRetry(logger logrus.FieldLogger, appconfig config.Interface, retry models.NotificationRetry) (Notify, error) {
+	var payload Payload
+	if err := json.Unmarshal([]byte(retry.Payload), &payload); err != nil {
+		return Notify{}, fmt.Errorf("could not decode queued notification %d: %w", retry.ID, err)
+	}
+	notice := fmt.Sprintf("Delayed notification: delivery first failed at %s.", retry.CreatedAt.Format(time.RFC1123))
+
+	payload.Delayed = true
+	if !strings.HasPrefix(payload.Subject, delayedSubjectPrefix) {
+		payload.Subject = delayedSubjectPrefix + payload.Subject
+	}
+	payload.Message = notice + "\n\n" + payload.Message
+	payload.HTMLMessage = markHTMLDelayed(retry.HTMLMessage, notice)
+
+	n := Notify{
+		Logger:           logger,
+		Config:           appconfig,
+		Payload:          payload,
+		retry:            &retry,
+		templatesApplied: true,
+	}
+	if retry.WebhookEvent != "" {
+		var event WebhookEvent
+		if err := json.Unmarshal([]byte(retry.WebhookEvent), &event); err != nil {
+			return Notify{}, fmt.Errorf("could not decode queued webhook event %d: %w", retry.ID, err)
+		}
+		event.Delayed = true
+		event.Subject = payload.Subject
+		event.Message = payload.Message
+		n.retryEvent = &event
+	}
+	return n, nil
+}
+
+// SendRetry makes one more delivery attempt to the queued target URL.
+func (n *Notify) SendRetry() error {
+	if n.retry == nil {
+		return fmt.Errorf("notification is not a queued retry")
+	}
+	return n.sendToUrls([]string{n.retry.URL})
+}
+
+// RecordDeadLetter records that delivery of a queued notification was given up on.
+func (n *Notify) RecordDeadLetter(err error) {
+	target := models.NotificationTargetResult{}
+	if n.retry != nil {
+		target.URL = MaskNotifyUrl(n.retry.URL)
+	}
+	record := models.NotificationRecord{
+		Outcome: models.NotificationOutcomeDeadLetter,
+		Targets: []models.NotificationTargetResult{target},
+	}
+	if err != nil {
+		target.Error = err.Error()
+		record.Targets[0] = target
+		record.Error = err.Error()
+	}
+	n.saveRecord(record)
+}
+
+// markHTMLDelayed adds the delay notice at the top of an HTML body.
+func markHTMLDelayed(body string, notice string) string {
+	if body == "" {
+		return ""
+	}
+	banner := `<p style="margin:0;padding:10px 16px;background-color:#fef3c7;color:#92400e;font-family:Arial,Helvetica,sans-serif;font-size:13px;">` + html.EscapeString(notice) + `</p>`
+	if start := strings.Index(body, "<body"); start >= 0 {
+		if end := strings.Index(body[start:], ">"); end >= 0 {
+			at := start + end + 1
+			return body[:at] + "\n" + banner + body[at:]
+		}
+	}
+	return banner + body
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// FailureTypeSeverity returns the severity notification routing rules compare against for a
+// failure type. Drive, array and collector outages are critical, early warnings are warnings,
+// and scheduled, test and resolved notifications are informational.
+func FailureTypeSeverity(failureType string) string {
+	switch failureType {
+	case NotifyFailureTypeSmartFailure, NotifyFailureTypeScrutinyFailure, NotifyFailureTypeMissedPing, NotifyFailureTypeMDADMDegraded, NotifyFailureTypeIncidentEscalation:
+		return models.NotifySeverityCritical
+	case NotifyFailureTypeReport, NotifyFailureTypeHeartbeat, NotifyFailureTypeEmailTest, NotifyFailureTypeMaintenanceSummary,
+		NotifyFailureTypeNewDevice, NotifyFailureTypeDeviceReappeared, NotifyFailureTypeDeviceRecovered, NotifyFailureTypeMissedPingRecovered,
+		NotifyFailureTypeArrayRecovered, NotifyFailureTypeCollectorErrorCleared:
+		return models.NotifySeverityInfo
+	default:
+		return models.NotifySeverityWarning
+	}
+}
+
+// Event returns the attributes of the payload that notification routing rules match on.
+func (p *Payload) Event() models.NotifyEvent {
+	return models.NotifyEvent{
+		FailureType:    p.FailureType,
+		HostID:         p.HostId,
+		DeviceProtocol: p.DeviceProtocol,
+		Tags:           p.Tags,
+		Severity:       FailureTypeSeverity(p.FailureType),
+	}
+}
+
+// tagResource returns the tag resource type and ID of the device, array or pool the notification
+// is about, or empty strings when it is not about a single resource.
+func (n *Notify) tagResource() (string, string) {
+	switch {
+	case n.maintenancePoolGUID != "":
+		return models.TagResourceZFSPool, n.maintenancePoolGUID
+	case n.maintenanceArrayUUID != "":
+		return models.TagResourceMdadmArray, n.maintenanceArrayUUID
+	case n.templateDevice != nil && n.templateDevice.DeviceID != "":
+		return models.TagResourceDevice, n.templateDevice.DeviceID
+	case n.incidentDeviceID != "":
+		return models.TagResourceDevice, n.incidentDeviceID
+	}
+	return "", ""
+}
+
+// loadResourceTags fills the payload tags from the repository when one of the URLs routes on tags
+// and the notification was built from a resource whose tags were not loaded.
+func (n *Notify) loadResourceTags(ctx context.Context, repo database.DeviceRepo, urls []models.NotifyUrl) {
+	if len(n.Payload.Tags) > 0 || n.Payload.Test || !routesOnTags(urls) {
+		return
+	}
+	resourceType, resourceID := n.tagResource()
+	if resourceID == "" {
+		return
+	}
+	tags, err := repo.GetResourceTags(ctx, resourceType)
+	if err != nil {
+		n.Logger.Warnf("Could not load %s tags for notification routing: %v", resourceType, err)
+		return
+	}
+	n.Payload.Tags = tags[resourceID]
+}
+
+func routesOnTags(urls []models.NotifyUrl) bool {
+	for _, u := range urls {
+		for _, rule := range u.Rules {
+			if len(rule.Tags) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewRoutingTest creates a test notification that simulates the given event, so routing rules
+// can be verified end to end. Missing event fields are filled with defaults, and the severity is
+// always derived from the failure type, as it is for real notifications.
+func NewRoutingTest(logger logrus.FieldLogger, appconfig config.Interface, event models.NotifyEvent) (Notify, models.NotifyEvent) {
+	event.FailureType = strings.TrimSpace(event.FailureType)
+	if event.FailureType == "" {
+		event.FailureType = NotifyFailureTypeEmailTest
+	}
+	event.Severity = FailureTypeSeverity(event.FailureType)
+
+	notification := New(logger, appconfig, models.Device{
+		HostId:         event.HostID,
+		SerialNumber:   "FAKEWDDJ324KSO",
+		DeviceType:     "sat",
+		DeviceProtocol: event.DeviceProtocol,
+		DeviceName:     "/dev/sda",
+		Tags:           event.Tags,
+	}, true)
+	notification.Payload.FailureType = event.FailureType
+	notification.Payload.Subject = notification.Payload.GenerateSubject()
+	notification.Payload.Message = notification.Payload.GenerateMessage()
+	notification.Payload.HTMLMessage = notification.Payload.GenerateHTMLMessage()
+	return notification, event
+}
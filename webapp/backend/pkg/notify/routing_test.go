@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureTypeSeverity(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, models.NotifySeverityCritical, FailureTypeSeverity(NotifyFailureTypeSmartFailure))
+	require.Equal(t, models.NotifySeverityCritical, FailureTypeSeverity(NotifyFailureTypeMDADMDegraded))
+	require.Equal(t, models.NotifySeverityWarning, FailureTypeSeverity(NotifyFailureTypeReplacementRisk))
+	require.Equal(t, models.NotifySeverityWarning, FailureTypeSeverity("SomethingNew"))
+	require.Equal(t, models.NotifySeverityInfo, FailureTypeSeverity(NotifyFailureTypeReport))
+}
+
+func TestLoadDatabaseUrls_AppliesRoutingRules(t *testing.T) {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	fakeDatabase := mock_database.NewMockDeviceRepo(mockCtrl)
+
+	fakeDatabase.EXPECT().
+		GetNotifyUrls(gomock.Any()).
+		Return([]models.NotifyUrl{
+			{ID: 1, URL: "pagerduty://token", Rules: []models.NotifyRoutingRule{{MinSeverity: models.NotifySeverityCritical}}},
+			{ID: 2, URL: "slack://token", Rules: []models.NotifyRoutingRule{{FailureTypes: []string{NotifyFailureTypeReport, NotifyFailureTypeHeartbeat}}}},
+			{ID: 3, URL: "generic://all"},
+		}, nil).
+		Times(2)
+
+	failure := New(logrus.StandardLogger(), nil, models.Device{HostId: "nas", DeviceProtocol: "ATA", DeviceStatus: pkg.DeviceStatusFailedSmart}, false)
+	failure.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"pagerduty://token", "generic://all"}, failure.DatabaseUrls)
+
+	report := NewReport(logrus.StandardLogger(), nil, "subject", "message", "")
+	report.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"slack://token", "generic://all"}, report.DatabaseUrls)
+}
+
+func TestLoadDatabaseUrls_MatchesResourceTags(t *testing.T) {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	fakeDatabase := mock_database.NewMockDeviceRepo(mockCtrl)
+
+	fakeDatabase.EXPECT().
+		GetNotifyUrls(gomock.Any()).
+		Return([]models.NotifyUrl{
+			{ID: 1, URL: "pagerduty://backup", Rules: []models.NotifyRoutingRule{{Tags: []string{"Backup"}}}},
+			{ID: 2, URL: "generic://all"},
+		}, nil).
+		Times(3)
+	fakeDatabase.EXPECT().
+		GetResourceTags(gomock.Any(), models.TagResourceDevice).
+		Return(map[string][]string{"device-1": {"backup", "rack-2"}}, nil).
+		Times(2)
+
+	tagged := New(logrus.StandardLogger(), nil, models.Device{DeviceID: "device-1", DeviceStatus: pkg.DeviceStatusFailedSmart}, false)
+	tagged.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"backup", "rack-2"}, tagged.Payload.Tags)
+	require.Equal(t, []string{"pagerduty://backup", "generic://all"}, tagged.DatabaseUrls)
+
+	untagged := New(logrus.StandardLogger(), nil, models.Device{DeviceID: "device-2", DeviceStatus: pkg.DeviceStatusFailedSmart}, false)
+	untagged.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"generic://all"}, untagged.DatabaseUrls)
+
+	recovered := NewZFSPoolRecovered(logrus.StandardLogger(), nil, models.ZFSPool{GUID: "123", Name: "tank", Tags: []string{"backup"}}, models.ZFSPoolStatusDegraded)
+	recovered.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"pagerduty://backup", "generic://all"}, recovered.DatabaseUrls, "loaded pool tags are used as they are")
+}
+
+func TestNewRoutingTest_FillsDefaults(t *testing.T) {
+	t.Parallel()
+	notification, event := NewRoutingTest(logrus.StandardLogger(), nil, models.NotifyEvent{FailureType: NotifyFailureTypeMissedPing, HostID: "nas"})
+
+	require.Equal(t, models.NotifySeverityCritical, event.Severity)
+	require.Equal(t, NotifyFailureTypeMissedPing, notification.Payload.FailureType)
+	require.Equal(t, "nas", notification.Payload.HostId)
+	require.True(t, notification.Payload.Test)
+	require.Contains(t, notification.Payload.Subject, NotifyFailureTypeMissedPing)
+
+	notification, event = NewRoutingTest(logrus.StandardLogger(), nil, models.NotifyEvent{FailureType: NotifyFailureTypeSmartFailure, Tags: []string{"backup"}})
+	require.Equal(t, []string{"backup"}, notification.Payload.Event().Tags)
+	require.True(t, models.NotifyUrl{Rules: []models.NotifyRoutingRule{{Tags: []string{"BACKUP", "offsite"}}}}.Accepts(event))
+	require.False(t, models.NotifyUrl{Rules: []models.NotifyRoutingRule{{Tags: []string{"offsite"}}}}.Accepts(event))
+
+	_, event = NewRoutingTest(logrus.StandardLogger(), nil, models.NotifyEvent{FailureType: NotifyFailureTypeReport, Severity: models.NotifySeverityCritical})
+	require.Equal(t, models.NotifySeverityInfo, event.Severity, "the severity is derived from the failure type")
+}
+
+func TestEscalationUrls_OnlyReceiveEscalations(t *testing.T) {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	fakeDatabase := mock_database.NewMockDeviceRepo(mockCtrl)
+
+	fakeDatabase.EXPECT().
+		GetNotifyUrls(gomock.Any()).
+		Return([]models.NotifyUrl{
+			{ID: 1, URL: "generic://primary"},
+			{ID: 2, URL: "pagerduty://oncall", Escalation: true},
+			{ID: 3, URL: "slack://reports", Escalation: true, Rules: []models.NotifyRoutingRule{{FailureTypes: []string{NotifyFailureTypeReport}}}},
+		}, nil).
+		Times(2)
+
+	failure := New(logrus.StandardLogger(), nil, models.Device{DeviceStatus: pkg.DeviceStatusFailedSmart}, false)
+	failure.LoadDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"generic://primary"}, failure.DatabaseUrls)
+
+	escalation := NewIncidentEscalation(logrus.StandardLogger(), nil, models.Incident{ID: 7, DeviceName: "/dev/sda", FailureType: NotifyFailureTypeSmartFailure}, time.Hour)
+	escalation.LoadEscalationDatabaseUrls(context.Background(), fakeDatabase)
+	require.Equal(t, []string{"pagerduty://oncall"}, escalation.DatabaseUrls)
+	require.Equal(t, models.NotifySeverityCritical, escalation.Payload.Event().Severity)
+	require.Contains(t, escalation.Payload.Subject, "#7")
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"net/smtp"
@@ -22,12 +23,12 @@ import (
 func (n *Notify) SendSMTPNotification(rawURL string) error {
 	serviceURL, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse smtp notification URL: %w", err)
+		return permanent(fmt.Errorf("failed to parse smtp notification URL: %w", err))
 	}
 
 	config, err := n.buildSMTPConfig(serviceURL)
 	if err != nil {
-		return err
+		return permanent(err)
 	}
 
 	n.Logger.Infof("Sending SMTP notification to %s", config.Host)
@@ -42,12 +43,12 @@ func (n *Notify) SendSMTPNotification(rawURL string) error {
 	defer client.Close()
 
 	if err := negotiateSMTP(client, config); err != nil {
-		return err
+		return smtpDeliveryError(err)
 	}
 
 	for _, toAddress := range config.ToAddresses {
 		if err := sendSMTPRecipient(client, config, toAddress, n.Payload.Message, n.Payload.HTMLMessage); err != nil {
-			return err
+			return smtpDeliveryError(err)
 		}
 	}
 
@@ -57,6 +58,16 @@ func (n *Notify) SendSMTPNotification(rawURL string) error {
 	return nil
 }
 
+// smtpDeliveryError marks permanent (5xx) SMTP replies, such as rejected credentials or an
+// unknown recipient, as not worth retrying. Transient (4xx) replies stay retryable.
+func smtpDeliveryError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
+
 // buildSMTPConfig constructs the shoutrrr SMTP config from the service URL and current payload,
 // applying defaults for auth type and subject.
 func (n *Notify) buildSMTPConfig(serviceURL *url.URL) (*shoutrrrsmtp.Config, error) {
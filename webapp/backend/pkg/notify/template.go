@@ -0,0 +1,433 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/thresholds"
+)
+
+// User templates live in the notify.template_dir directory, one set per failure type:
+//
+//	<FailureType>.subject.tmpl  text/template, rendered to a single line
+//	<FailureType>.text.tmpl     text/template, the plain text body
+//	<FailureType>.html.tmpl     html/template, the HTML body used for SMTP emails
+//
+// default.*.tmpl applies to every failure type without its own file. Missing files keep the
+// built-in rendering, and a template that fails to parse or execute falls back to it as well.
+const (
+	templateDefaultName = "default"
+	templateSubjectExt  = ".subject.tmpl"
+	templateTextExt     = ".text.tmpl"
+	templateHTMLExt     = ".html.tmpl"
+)
+
+var (
+	templateDirMu sync.RWMutex
+	templateDir   string
+)
+
+// ConfigureTemplates sets the directory user templates are loaded from. An empty directory
+// disables user templates. Called once at startup from the notify.template_dir config key.
+func ConfigureTemplates(dir string) {
+	templateDirMu.Lock()
+	defer templateDirMu.Unlock()
+	templateDir = strings.TrimSpace(dir)
+}
+
+func configuredTemplateDir() string {
+	templateDirMu.RLock()
+	defer templateDirMu.RUnlock()
+	return templateDir
+}
+
+// TemplateData is the data model available to notification templates.
+type TemplateData struct {
+	FailureType string
+	Severity    string
+	Date        string
+	Test        bool
+
+	// Subject and Message are the built-in renderings, so templates can wrap or reuse them.
+	Subject string
+	Message string
+
+	Host TemplateHost
+	// Device is nil for notifications that are not about a single device (digests, reports, heartbeats).
+	Device *TemplateDevice
+	// Attributes holds the SMART attributes of the submission that triggered the notification,
+	// when there is one, ordered by attribute ID.
+	Attributes []TemplateAttribute
+	// Risk is set for replacement risk notifications.
+	Risk *TemplateRisk
+}
+
+type TemplateHost struct {
+	ID string
+}
+
+type TemplateDevice struct {
+	ID           string
+	Name         string
+	Label        string
+	Serial       string
+	WWN          string
+	Type         string
+	Protocol     string
+	ModelName    string
+	Manufacturer string
+	Capacity     int64
+	Location     string
+	Status       string
+	Failed       bool
+}
+
+type TemplateAttribute struct {
+	ID    string
+	Name  string
+	Value int64
+	// Threshold is the manufacturer threshold, nil when the attribute has none.
+	Threshold    *int64
+	Status       string
+	StatusReason string
+	Failed       bool
+	Warning      bool
+}
+
+type TemplateRisk struct {
+	Score    int
+	Category string
+}
+
+// NotificationTemplates holds template sources for one failure type. Empty fields keep the
+// built-in rendering.
+type NotificationTemplates struct {
+	Subject string `json:"subject,omitempty"`
+	Text    string `json:"text,omitempty"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// IsEmpty reports whether no template is set.
+func (t NotificationTemplates) IsEmpty() bool {
+	return t.Subject == "" && t.Text == "" && t.HTML == ""
+}
+
+// RenderedNotification is the output of rendering a template set.
+type RenderedNotification struct {
+	Subject     string `json:"subject"`
+	Message     string `json:"message"`
+	HTMLMessage string `json:"html_message,omitempty"`
+}
+
+// LoadTemplates reads the templates for a failure type from dir, falling back to the default
+// templates per part. A missing directory or file is not an error.
+func LoadTemplates(dir string, failureType string) (NotificationTemplates, error) {
+	var templates NotificationTemplates
+	if dir == "" {
+		return templates, nil
+	}
+	parts := []struct {
+		ext    string
+		target *string
+	}{
+		{templateSubjectExt, &templates.Subject},
+		{templateTextExt, &templates.Text},
+		{templateHTMLExt, &templates.HTML},
+	}
+	for _, part := range parts {
+		for _, name := range []string{failureType, templateDefaultName} {
+			if name == "" || strings.ContainsAny(name, `/\`) {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(dir, name+part.ext))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return templates, err
+			}
+			*part.target = string(content)
+			break
+		}
+	}
+	return templates, nil
+}
+
+// RenderTemplates renders the template set against data. Parts without a template keep the
+// built-in subject and message from data; fallbackHTML is used when there is no HTML template.
+func RenderTemplates(templates NotificationTemplates, data TemplateData, fallbackHTML string) (RenderedNotification, error) {
+	rendered := RenderedNotification{Subject: data.Subject, Message: data.Message, HTMLMessage: fallbackHTML}
+
+	if templates.Subject != "" {
+		subject, err := renderTextTemplate("subject", templates.Subject, data)
+		if err != nil {
+			return rendered, err
+		}
+		rendered.Subject = strings.Join(strings.Fields(subject), " ")
+	}
+	if templates.Text != "" {
+		message, err := renderTextTemplate("text", templates.Text, data)
+		if err != nil {
+			return rendered, err
+		}
+		rendered.Message = strings.TrimSpace(message)
+		// a custom text body without a custom HTML body would otherwise be hidden from SMTP
+		// recipients behind the built-in HTML body
+		if templates.HTML == "" {
+			rendered.HTMLMessage = ""
+		}
+	}
+	if templates.HTML != "" {
+		tmpl, err := htmltemplate.New("html").Option("missingkey=error").Parse(templates.HTML)
+		if err != nil {
+			return rendered, fmt.Errorf("html template: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return rendered, fmt.Errorf("html template: %w", err)
+		}
+		rendered.HTMLMessage = buf.String()
+	}
+	return rendered, nil
+}
+
+func renderTextTemplate(name string, source string, data TemplateData) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// SetTemplateDevice makes the device available to templates as .Device.
+func (n *Notify) SetTemplateDevice(device models.Device) {
+	n.templateDevice = &device
+}
+
+// SetTemplateAttributes makes the SMART attributes of a submission available to templates as .Attributes.
+func (n *Notify) SetTemplateAttributes(smart *measurements.Smart) {
+	n.templateAttributes = TemplateAttributes(n.Payload.DeviceProtocol, smart)
+}
+
+// Severity returns the severity of the notification: the severity of its notifications for a
+// digest, otherwise the severity of its failure type.
+func (n *Notify) Severity() string {
+	if n.severity != "" {
+		return n.severity
+	}
+	return FailureTypeSeverity(n.Payload.FailureType)
+}
+
+// TemplateData returns the data model templates are rendered against.
+func (n *Notify) TemplateData() TemplateData {
+	data := TemplateData{
+		FailureType: n.Payload.FailureType,
+		Severity:    n.Severity(),
+		Date:        n.Payload.Date,
+		Test:        n.Payload.Test,
+		Subject:     n.Payload.Subject,
+		Message:     n.Payload.Message,
+		Host:        TemplateHost{ID: n.Payload.HostId},
+		Attributes:  n.templateAttributes,
+		Risk:        n.templateRisk,
+	}
+	if data.Date == "" {
+		data.Date = time.Now().Format(time.RFC3339)
+	}
+	if data.Attributes == nil {
+		data.Attributes = []TemplateAttribute{}
+	}
+	if device := n.templateDevice; device != nil {
+		data.Device = &TemplateDevice{
+			ID:           device.DeviceID,
+			Name:         device.DeviceName,
+			Label:        strings.TrimSpace(device.Label),
+			Serial:       device.SerialNumber,
+			WWN:          device.WWN,
+			Type:         device.DeviceType,
+			Protocol:     device.DeviceProtocol,
+			ModelName:    device.ModelName,
+			Manufacturer: device.Manufacturer,
+			Capacity:     device.Capacity,
+			Location:     device.Location,
+			Status:       deviceStatusName(device.DeviceStatus),
+			Failed:       device.DeviceStatus != pkg.DeviceStatusPassed,
+		}
+	}
+	return data
+}
+
+// ApplyTemplates renders the user templates for the payload's failure type, if any, over the
+// built-in subject and bodies. Rendering errors are logged and keep the built-in text.
+// Safe to call more than once.
+func (n *Notify) ApplyTemplates() {
+	if n.templatesApplied {
+		return
+	}
+	n.templatesApplied = true
+
+	templates, err := LoadTemplates(configuredTemplateDir(), n.Payload.FailureType)
+	if err == nil && templates.IsEmpty() {
+		return
+	}
+	var rendered RenderedNotification
+	if err == nil {
+		rendered, err = RenderTemplates(templates, n.TemplateData(), n.Payload.HTMLMessage)
+	}
+	if err != nil {
+		if n.Logger != nil {
+			n.Logger.Warnf("Failed to render notification template for %s, using the built-in template: %v", n.Payload.FailureType, err)
+		}
+		return
+	}
+	n.Payload.Subject = rendered.Subject
+	n.Payload.Message = rendered.Message
+	n.Payload.HTMLMessage = rendered.HTMLMessage
+}
+
+// PreviewTemplates renders templates against this notification without sending it. When
+// templates is empty, the templates from the configured template directory are used. On error
+// the built-in rendering is returned together with the error.
+func (n *Notify) PreviewTemplates(templates NotificationTemplates) (RenderedNotification, error) {
+	builtIn := RenderedNotification{Subject: n.Payload.Subject, Message: n.Payload.Message, HTMLMessage: n.Payload.HTMLMessage}
+	if templates.IsEmpty() {
+		loaded, err := LoadTemplates(configuredTemplateDir(), n.Payload.FailureType)
+		if err != nil {
+			return builtIn, err
+		}
+		templates = loaded
+	}
+	rendered, err := RenderTemplates(templates, n.TemplateData(), n.Payload.HTMLMessage)
+	if err != nil {
+		return builtIn, err
+	}
+	return rendered, nil
+}
+
+// TemplateAttributes converts the attributes of a SMART submission to the template data model.
+func TemplateAttributes(protocol string, smart *measurements.Smart) []TemplateAttribute {
+	attributes := []TemplateAttribute{}
+	if smart == nil {
+		return attributes
+	}
+	for id, attr := range smart.Attributes {
+		if attr == nil {
+			continue
+		}
+		status := attr.GetStatus()
+		attribute := TemplateAttribute{
+			ID:      id,
+			Name:    attributeDisplayName(protocol, id, attr),
+			Value:   attr.GetTransformedValue(),
+			Status:  attributeStatusName(status),
+			Failed:  pkg.AttributeStatusHas(status, pkg.AttributeStatusFailedSmart) || pkg.AttributeStatusHas(status, pkg.AttributeStatusFailedScrutiny),
+			Warning: pkg.AttributeStatusHas(status, pkg.AttributeStatusWarningScrutiny),
+		}
+		switch typed := attr.(type) {
+		case *measurements.SmartAtaAttribute:
+			attribute.StatusReason = typed.StatusReason
+			attribute.Threshold = templateThreshold(typed.Threshold)
+		case *measurements.SmartNvmeAttribute:
+			attribute.StatusReason = typed.StatusReason
+			attribute.Threshold = templateThreshold(typed.Threshold)
+		case *measurements.SmartScsiAttribute:
+			attribute.StatusReason = typed.StatusReason
+			attribute.Threshold = templateThreshold(typed.Threshold)
+		}
+		attributes = append(attributes, attribute)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		a, aErr := strconv.Atoi(attributes[i].ID)
+		b, bErr := strconv.Atoi(attributes[j].ID)
+		switch {
+		case aErr == nil && bErr == nil:
+			return a < b
+		case aErr == nil:
+			return true
+		case bErr == nil:
+			return false
+		default:
+			return attributes[i].ID < attributes[j].ID
+		}
+	})
+	return attributes
+}
+
+// templateThreshold maps the -1 "no threshold" marker used by collectors to nil.
+func templateThreshold(threshold int64) *int64 {
+	if threshold < 0 {
+		return nil
+	}
+	return &threshold
+}
+
+func attributeDisplayName(protocol string, id string, attr measurements.SmartAttribute) string {
+	switch protocol {
+	case pkg.DeviceProtocolAta:
+		if ata, ok := attr.(*measurements.SmartAtaAttribute); ok && ata.Name != "" {
+			return ata.Name
+		}
+		if numericID, err := strconv.Atoi(id); err == nil {
+			if metadata, ok := thresholds.AtaMetadata[numericID]; ok {
+				return metadata.DisplayName
+			}
+		}
+		if metadata, ok := thresholds.AtaDeviceStatsMetadata[id]; ok {
+			return metadata.DisplayName
+		}
+	case pkg.DeviceProtocolNvme:
+		if metadata, ok := thresholds.NmveMetadata[id]; ok {
+			return metadata.DisplayName
+		}
+	case pkg.DeviceProtocolScsi:
+		if metadata, ok := thresholds.ScsiMetadata[id]; ok {
+			return metadata.DisplayName
+		}
+	}
+	return id
+}
+
+func attributeStatusName(status pkg.AttributeStatus) string {
+	switch {
+	case pkg.AttributeStatusHas(status, pkg.AttributeStatusFailedSmart):
+		return "failed_smart"
+	case pkg.AttributeStatusHas(status, pkg.AttributeStatusFailedScrutiny):
+		return "failed_scrutiny"
+	case pkg.AttributeStatusHas(status, pkg.AttributeStatusWarningScrutiny):
+		return "warning"
+	case pkg.AttributeStatusHas(status, pkg.AttributeStatusInvalidValue):
+		return "invalid"
+	default:
+		return "passed"
+	}
+}
+
+func deviceStatusName(status pkg.DeviceStatus) string {
+	switch {
+	case pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedSmart) && pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedScrutiny):
+		return "failed_both"
+	case pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedSmart):
+		return "failed_smart"
+	case pkg.DeviceStatusHas(status, pkg.DeviceStatusFailedScrutiny):
+		return "failed_scrutiny"
+	default:
+		return "passed"
+	}
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func templateTestDevice() models.Device {
+	return models.Device{
+		DeviceID:       "dev-1",
+		WWN:            "0x5000c500abcdef",
+		DeviceName:     "sda",
+		SerialNumber:   "WD-123",
+		DeviceProtocol: pkg.DeviceProtocolAta,
+		HostId:         "nas",
+		DeviceStatus:   pkg.DeviceStatusFailedSmart,
+	}
+}
+
+func writeTemplateFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoadTemplates_FailureTypeOverridesDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "default.subject.tmpl", "default subject")
+	writeTemplateFile(t, dir, "default.text.tmpl", "default text")
+	writeTemplateFile(t, dir, "SmartFailure.subject.tmpl", "smart subject")
+
+	templates, err := LoadTemplates(dir, NotifyFailureTypeSmartFailure)
+	require.NoError(t, err)
+	require.Equal(t, "smart subject", templates.Subject)
+	require.Equal(t, "default text", templates.Text)
+	require.Empty(t, templates.HTML)
+
+	templates, err = LoadTemplates(filepath.Join(dir, "missing"), NotifyFailureTypeSmartFailure)
+	require.NoError(t, err)
+	require.True(t, templates.IsEmpty())
+}
+
+func TestTemplateAttributes_SortedWithNames(t *testing.T) {
+	smart := &measurements.Smart{Attributes: map[string]measurements.SmartAttribute{
+		"197": &measurements.SmartAtaAttribute{AttributeId: 197, TransformedValue: 3, Status: pkg.AttributeStatusFailedScrutiny, StatusReason: "pending sectors"},
+		"5":   &measurements.SmartAtaAttribute{AttributeId: 5, Name: "Reallocated_Sector_Ct", TransformedValue: 0},
+		"10":  &measurements.SmartAtaAttribute{AttributeId: 10, TransformedValue: 1, Status: pkg.AttributeStatusWarningScrutiny},
+	}}
+
+	attributes := TemplateAttributes(pkg.DeviceProtocolAta, smart)
+	require.Len(t, attributes, 3)
+	require.Equal(t, []string{"5", "10", "197"}, []string{attributes[0].ID, attributes[1].ID, attributes[2].ID})
+	require.Equal(t, "Reallocated_Sector_Ct", attributes[0].Name)
+	require.Equal(t, "Spin Retry Count", attributes[1].Name)
+	require.True(t, attributes[1].Warning)
+	require.True(t, attributes[2].Failed)
+	require.Equal(t, "failed_scrutiny", attributes[2].Status)
+	require.Equal(t, "pending sectors", attributes[2].StatusReason)
+}
+
+func TestApplyTemplates_RendersUserTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "SmartFailure.subject.tmpl", "[{{.Severity}}] {{.Device.Name}} on {{.Host.ID}}\n")
+	writeTemplateFile(t, dir, "SmartFailure.text.tmpl", "{{range .Attributes}}{{if .Failed}}{{.ID}}={{.Value}} {{end}}{{end}}")
+	writeTemplateFile(t, dir, "SmartFailure.html.tmpl", "<p>{{.Device.Serial}}</p>")
+	ConfigureTemplates(dir)
+	t.Cleanup(func() { ConfigureTemplates("") })
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	n.SetTemplateAttributes(&measurements.Smart{Attributes: map[string]measurements.SmartAttribute{
+		"5": &measurements.SmartAtaAttribute{AttributeId: 5, TransformedValue: 12, Status: pkg.AttributeStatusFailedSmart},
+	}})
+	n.ApplyTemplates()
+
+	require.Equal(t, "[critical] sda on nas", n.Payload.Subject)
+	require.Equal(t, "5=12", n.Payload.Message)
+	require.Equal(t, "<p>WD-123</p>", n.Payload.HTMLMessage)
+
+	// applying again must not re-render over the rendered text
+	n.ApplyTemplates()
+	require.Equal(t, "[critical] sda on nas", n.Payload.Subject)
+}
+
+func TestApplyTemplates_FallsBackOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "default.subject.tmpl", "{{.NoSuchField}}")
+	ConfigureTemplates(dir)
+	t.Cleanup(func() { ConfigureTemplates("") })
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	builtIn := n.Payload
+	n.ApplyTemplates()
+
+	require.Equal(t, builtIn.Subject, n.Payload.Subject)
+	require.Equal(t, builtIn.Message, n.Payload.Message)
+	require.Equal(t, builtIn.HTMLMessage, n.Payload.HTMLMessage)
+}
+
+func TestRenderTemplates_TextOnlyDropsBuiltInHTML(t *testing.T) {
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	rendered, err := n.PreviewTemplates(NotificationTemplates{Text: "{{.Message}} (custom)"})
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(rendered.Message, "(custom)"))
+	require.Equal(t, n.Payload.Subject, rendered.Subject)
+	require.Empty(t, rendered.HTMLMessage)
+}
+
+func TestRenderTemplates_HTMLIsEscaped(t *testing.T) {
+	device := templateTestDevice()
+	device.Label = "<script>"
+	n := New(logrus.New(), nil, device, false)
+	rendered, err := n.PreviewTemplates(NotificationTemplates{HTML: "<b>{{.Device.Label}}</b>"})
+	require.NoError(t, err)
+	require.Equal(t, "<b>&lt;script&gt;</b>", rendered.HTMLMessage)
+}
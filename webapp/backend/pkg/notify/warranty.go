@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// NewWarrantyExpiry constructs a Notify instance warning that a device warranty is about to expire,
+// or has expired when warranty.DaysRemaining is negative.
+func NewWarrantyExpiry(logger logrus.FieldLogger, appconfig config.Interface, device *models.Device, warranty models.WarrantyStatus) Notify {
+	payload := NewPayload(*device, false)
+	payload.FailureType = NotifyFailureTypeWarrantyExpiry
+
+	deviceIdentifier := device.DeviceName
+	if label := strings.TrimSpace(device.Label); len(label) > 0 {
+		deviceIdentifier = fmt.Sprintf(fmtLabelWithName, label, device.DeviceName)
+	}
+
+	expiresOn := ""
+	if warranty.ExpiresAt != nil {
+		expiresOn = warranty.ExpiresAt.Format("2006-01-02")
+	}
+	remaining := fmt.Sprintf("expiring (%d days)", warranty.DaysRemaining)
+	banner := "WARRANTY EXPIRING"
+	switch {
+	case warranty.DaysRemaining < 0:
+		remaining = fmt.Sprintf("expired (%s)", expiresOn)
+		banner = "WARRANTY EXPIRED"
+	case warranty.DaysRemaining == 0:
+		remaining = "expiring (today)"
+	}
+
+	if hostId := strings.TrimSpace(device.HostId); len(hostId) > 0 {
+		payload.Subject = fmt.Sprintf("Scrutiny warranty %s on [host]device: [%s]%s", remaining, hostId, deviceIdentifier)
+	} else {
+		payload.Subject = fmt.Sprintf("Scrutiny warranty %s on device: %s", remaining, deviceIdentifier)
+	}
+
+	parts := []string{
+		fmt.Sprintf("Scrutiny warranty expiry notification for device: %s", device.DeviceName),
+	}
+	if hostId := strings.TrimSpace(device.HostId); len(hostId) > 0 {
+		parts = append(parts, fmt.Sprintf(fmtHostId, hostId))
+	}
+	parts = append(parts,
+		fmt.Sprintf("Failure Type: %s", NotifyFailureTypeWarrantyExpiry),
+		fmt.Sprintf("Warranty Expires: %s", expiresOn),
+		fmt.Sprintf("Days Remaining: %d", warranty.DaysRemaining),
+		fmt.Sprintf("Device Name: %s", device.DeviceName),
+		fmt.Sprintf(fmtDeviceSerial, device.SerialNumber),
+		fmt.Sprintf("Device Type: %s", device.DeviceType),
+	)
+	if label := strings.TrimSpace(device.Label); len(label) > 0 {
+		parts = append(parts, fmt.Sprintf(fmtDeviceLabel, label))
+	}
+	if warranty.Vendor != "" {
+		parts = append(parts, fmt.Sprintf("Vendor: %s", warranty.Vendor))
+	}
+	if warranty.RMAContact != "" {
+		parts = append(parts, fmt.Sprintf("RMA Contact: %s", warranty.RMAContact))
+	}
+	if device.Location != "" {
+		parts = append(parts, fmt.Sprintf("Location: %s", device.Location))
+	}
+	parts = append(parts, "", fmt.Sprintf(fmtDate, payload.Date))
+	payload.Message = strings.Join(parts, "\n")
+
+	rows := [][2]string{
+		{notifyRowFailureType, NotifyFailureTypeWarrantyExpiry},
+		{"Warranty Expires", expiresOn},
+		{"Days Remaining", fmt.Sprintf("%d", warranty.DaysRemaining)},
+		{"Device", deviceIdentifier},
+		{notifyRowDeviceSerial, device.SerialNumber},
+		{notifyRowDeviceType, device.DeviceType},
+	}
+	if hostId := strings.TrimSpace(device.HostId); len(hostId) > 0 {
+		rows = append(rows, [2]string{"Host Id", hostId})
+	}
+	if warranty.Vendor != "" {
+		rows = append(rows, [2]string{"Vendor", warranty.Vendor})
+	}
+	if warranty.RMAContact != "" {
+		rows = append(rows, [2]string{"RMA Contact", warranty.RMAContact})
+	}
+	if device.Location != "" {
+		rows = append(rows, [2]string{"Location", device.Location})
+	}
+	rows = append(rows, [2]string{"Date", payload.Date})
+	payload.HTMLMessage = formatNotificationHTML(
+		payload.Subject,
+		"Scrutiny warranty expiry notification",
+		banner,
+		"#fd7e14",
+		rows,
+		notifyFooterText,
+	)
+
+	return Notify{
+		Logger:  logger,
+		Config:  appconfig,
+		Payload: payload,
+
+		templateDevice: device,
+	}
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWarrantyExpiry_Subject(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	device := &models.Device{DeviceID: "a", HostId: "nas", DeviceName: "sda", SerialNumber: "S1"}
+
+	expiring := NewWarrantyExpiry(logrus.StandardLogger(), nil, device, models.WarrantyStatus{ExpiresAt: &expiresAt, State: models.WarrantyStateExpiring, DaysRemaining: 10})
+	require.Equal(t, "Scrutiny warranty expiring (10 days) on [host]device: [nas]sda", expiring.Payload.Subject)
+	require.Contains(t, expiring.Payload.HTMLMessage, "WARRANTY EXPIRING")
+
+	today := NewWarrantyExpiry(logrus.StandardLogger(), nil, device, models.WarrantyStatus{ExpiresAt: &expiresAt, State: models.WarrantyStateExpiring, DaysRemaining: 0})
+	require.Equal(t, "Scrutiny warranty expiring (today) on [host]device: [nas]sda", today.Payload.Subject)
+
+	expired := NewWarrantyExpiry(logrus.StandardLogger(), nil, device, models.WarrantyStatus{ExpiresAt: &expiresAt, State: models.WarrantyStateExpired, DaysRemaining: -3})
+	require.Equal(t, "Scrutiny warranty expired (2026-10-15) on [host]device: [nas]sda", expired.Payload.Subject)
+	require.Equal(t, NotifyFailureTypeWarrantyExpiry, expired.Payload.FailureType)
+	require.Contains(t, expired.Payload.HTMLMessage, "WARRANTY EXPIRED")
+}
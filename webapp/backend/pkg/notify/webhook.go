@@ -0,0 +1,298 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedWebhookURLPrefix marks notification URLs delivered by the native webhook notifier, which
+// POSTs a versioned JSON event signed with HMAC-SHA256. The secret is the URL userinfo:
+//
+//	webhook+https://<secret>@hooks.example.com/scrutiny
+const SignedWebhookURLPrefix = "webhook+"
+
+// WebhookSchemaVersion is the version of the WebhookEvent document. It is only incremented for
+// changes that are not backwards compatible; new fields may be added within a version.
+const WebhookSchemaVersion = 1
+
+// Headers sent with every signed webhook request.
+const (
+	WebhookHeaderEvent         = "X-Scrutiny-Event"
+	WebhookHeaderDelivery      = "X-Scrutiny-Delivery"
+	WebhookHeaderTimestamp     = "X-Scrutiny-Timestamp"
+	WebhookHeaderSignature     = "X-Scrutiny-Signature"
+	WebhookHeaderSchemaVersion = "X-Scrutiny-Schema-Version"
+
+	webhookSignaturePrefix = "sha256="
+)
+
+const (
+	webhookMaxAttempts    = 3
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// webhookRetryBaseDelay is the delay before the first retry; it doubles on every further attempt.
+var webhookRetryBaseDelay = 2 * time.Second
+
+var webhookHTTPClient = &http.Client{Timeout: webhookRequestTimeout}
+
+// WebhookEvent is the JSON document POSTed to signed webhook URLs.
+type WebhookEvent struct {
+	SchemaVersion int    `json:"schema_version"`
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	Severity      string `json:"severity"`
+	Test          bool   `json:"test"`
+	Delayed       bool   `json:"delayed"` // resent from the retry queue after a failed delivery
+	OccurredAt    string `json:"occurred_at"`
+
+	Host       WebhookHost        `json:"host"`
+	Device     *WebhookDevice     `json:"device"`
+	Attributes []WebhookAttribute `json:"attributes"`
+	Risk       *WebhookRisk       `json:"risk"`
+
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+type WebhookHost struct {
+	ID string `json:"id"`
+}
+
+type WebhookDevice struct {
+	ID           string `json:"id"`
+	WWN          string `json:"wwn"`
+	Name         string `json:"name"`
+	Label        string `json:"label"`
+	Serial       string `json:"serial"`
+	Type         string `json:"type"`
+	Protocol     string `json:"protocol"`
+	ModelName    string `json:"model_name"`
+	Manufacturer string `json:"manufacturer"`
+	Capacity     int64  `json:"capacity"`
+	Status       string `json:"status"`
+	Failed       bool   `json:"failed"`
+}
+
+type WebhookAttribute struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Value        int64  `json:"value"`
+	Threshold    *int64 `json:"threshold"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason,omitempty"`
+	Failed       bool   `json:"failed"`
+	Warning      bool   `json:"warning"`
+}
+
+type WebhookRisk struct {
+	Score    int    `json:"score"`
+	Category string `json:"category"`
+}
+
+// IsSignedWebhookURL reports whether the URL is delivered by the native webhook notifier.
+func IsSignedWebhookURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, SignedWebhookURLPrefix+"https://") || strings.HasPrefix(rawURL, SignedWebhookURLPrefix+"http://")
+}
+
+// WebhookEvent builds the webhook event document for this notification. Every call returns a new event ID.
+func (n *Notify) WebhookEvent() (WebhookEvent, error) {
+	id, err := newWebhookEventID()
+	if err != nil {
+		return WebhookEvent{}, err
+	}
+	data := n.TemplateData()
+	event := WebhookEvent{
+		SchemaVersion: WebhookSchemaVersion,
+		ID:            id,
+		Type:          data.FailureType,
+		Severity:      data.Severity,
+		Test:          data.Test,
+		OccurredAt:    data.Date,
+		Host:          WebhookHost{ID: data.Host.ID},
+		Attributes:    make([]WebhookAttribute, 0, len(data.Attributes)),
+		Subject:       data.Subject,
+		Message:       data.Message,
+	}
+	if device := data.Device; device != nil {
+		event.Device = &WebhookDevice{
+			ID:           device.ID,
+			WWN:          device.WWN,
+			Name:         device.Name,
+			Label:        device.Label,
+			Serial:       device.Serial,
+			Type:         device.Type,
+			Protocol:     device.Protocol,
+			ModelName:    device.ModelName,
+			Manufacturer: device.Manufacturer,
+			Capacity:     device.Capacity,
+			Status:       device.Status,
+			Failed:       device.Failed,
+		}
+	}
+	for _, attr := range data.Attributes {
+		event.Attributes = append(event.Attributes, WebhookAttribute{
+			ID:           attr.ID,
+			Name:         attr.Name,
+			Value:        attr.Value,
+			Threshold:    attr.Threshold,
+			Status:       attr.Status,
+			StatusReason: attr.StatusReason,
+			Failed:       attr.Failed,
+			Warning:      attr.Warning,
+		})
+	}
+	if data.Risk != nil {
+		event.Risk = &WebhookRisk{Score: data.Risk.Score, Category: data.Risk.Category}
+	}
+	return event, nil
+}
+
+func newWebhookEventID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate webhook event id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SendSignedWebhookNotification POSTs the event to a webhook+http(s):// URL. Each attempt is signed
+// with a fresh timestamp; 5xx responses and transport errors are retried a few times with
+// exponential backoff, so a short receiver outage does not wait for the retry queue. Deliveries
+// that still fail are resent by the retry queue.
+func (n *Notify) SendSignedWebhookNotification(rawURL string, event WebhookEvent) error {
+	targetURL, secret, err := parseSignedWebhookURL(rawURL)
+	if err != nil {
+		return permanent(err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return permanent(fmt.Errorf("could not encode webhook event: %w", err))
+	}
+
+	n.Logger.Infof("Sending signed webhook to %s", MaskNotifyUrl(rawURL))
+	delay := webhookRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := postSignedWebhook(targetURL, secret, event, body, time.Now())
+		if err == nil {
+			return nil
+		}
+		if !webhookRetryable(err) || attempt == webhookMaxAttempts {
+			return fmt.Errorf("signed webhook to %s failed after %d attempt(s): %w", MaskNotifyUrl(rawURL), attempt, err)
+		}
+		n.Logger.Warnf("Signed webhook to %s failed (attempt %d/%d), retrying in %s: %v", MaskNotifyUrl(rawURL), attempt, webhookMaxAttempts, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postSignedWebhook makes one delivery attempt, signed with the current time.
+func postSignedWebhook(targetURL string, secret string, event WebhookEvent, body []byte, now time.Time) error {
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return permanent(err)
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "scrutiny-webhook")
+	req.Header.Set(WebhookHeaderEvent, event.Type)
+	req.Header.Set(WebhookHeaderDelivery, event.ID)
+	req.Header.Set(WebhookHeaderSchemaVersion, strconv.Itoa(event.SchemaVersion))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(secret, timestamp, body))
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return &webhookStatusError{status: resp.StatusCode}
+	default:
+		// the receiver rejected the request, e.g. a wrong secret or a removed endpoint
+		return permanent(&webhookStatusError{status: resp.StatusCode})
+	}
+}
+
+type webhookStatusError struct {
+	status int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.status)
+}
+
+// webhookRetryable reports whether a failed attempt is retried right away: transport errors and
+// 5xx responses. A receiver asking to slow down (408, 429) is left to the retry queue.
+func webhookRetryable(err error) bool {
+	var statusErr *webhookStatusError
+	if errors.As(err, &statusErr) && statusErr.status < 500 {
+		return false
+	}
+	return IsRetryable(err)
+}
+
+// parseSignedWebhookURL strips the webhook+ prefix and the secret from the userinfo.
+func parseSignedWebhookURL(rawURL string) (string, string, error) {
+	parsed, err := url.Parse(strings.TrimPrefix(rawURL, SignedWebhookURLPrefix))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid signed webhook URL: %w", err)
+	}
+	var secret string
+	if parsed.User != nil {
+		secret = parsed.User.Username()
+		if password, ok := parsed.User.Password(); ok {
+			secret = password
+		}
+	}
+	if secret == "" {
+		return "", "", errors.New("signed webhook URL requires a secret, e.g. webhook+https://<secret>@host/path")
+	}
+	parsed.User = nil
+	return parsed.String(), secret, nil
+}
+
+// SignWebhookPayload returns the signature header value for a webhook body: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the secret, prefixed with "sha256=".
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature and timestamp headers of a received webhook request.
+// Requests whose timestamp is more than tolerance away from now are rejected to prevent replay.
+func VerifyWebhookSignature(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(WebhookHeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid webhook timestamp")
+	}
+	sent := time.Unix(timestamp, 0)
+	if now.Sub(sent) > tolerance || sent.Sub(now) > tolerance {
+		return errors.New("webhook timestamp is outside the allowed tolerance")
+	}
+	expected := SignWebhookPayload(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(WebhookHeaderSignature))) {
+		return errors.New("webhook signature does not match")
+	}
+	return nil
+}
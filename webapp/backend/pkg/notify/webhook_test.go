@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func signedWebhookURL(server *httptest.Server, secret string) string {
+	return SignedWebhookURLPrefix + strings.Replace(server.URL, "http://", "http://"+secret+"@", 1) + "/hook"
+}
+
+func TestWebhookEvent_Document(t *testing.T) {
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	n.SetTemplateAttributes(&measurements.Smart{Attributes: map[string]measurements.SmartAttribute{
+		"5": &measurements.SmartAtaAttribute{AttributeId: 5, TransformedValue: 40, Threshold: 36, Status: pkg.AttributeStatusFailedSmart},
+	}})
+
+	event, err := n.WebhookEvent()
+	require.NoError(t, err)
+	require.Equal(t, WebhookSchemaVersion, event.SchemaVersion)
+	require.Len(t, event.ID, 32)
+	require.Equal(t, NotifyFailureTypeSmartFailure, event.Type)
+	require.Equal(t, "critical", event.Severity)
+	require.Equal(t, "nas", event.Host.ID)
+	require.NotNil(t, event.Device)
+	require.Equal(t, "WD-123", event.Device.Serial)
+	require.Len(t, event.Attributes, 1)
+	require.NotNil(t, event.Attributes[0].Threshold)
+	require.Equal(t, int64(36), *event.Attributes[0].Threshold)
+	require.True(t, event.Attributes[0].Failed)
+	require.Nil(t, event.Risk)
+}
+
+func TestSendSignedWebhookNotification_SignsInASingleAttempt(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, "/hook", r.URL.Path)
+		require.Empty(t, r.URL.User, "the secret must not be sent in the URL")
+		require.NoError(t, VerifyWebhookSignature("s3cret", r.Header, body, time.Minute, time.Now()))
+		require.Equal(t, NotifyFailureTypeSmartFailure, r.Header.Get(WebhookHeaderEvent))
+		require.Equal(t, "1", r.Header.Get(WebhookHeaderSchemaVersion))
+		require.NotEmpty(t, r.Header.Get(WebhookHeaderDelivery))
+
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, "WD-123", event.Device.Serial)
+
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	require.NoError(t, n.sendToUrls([]string{signedWebhookURL(server, "s3cret")}))
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSendSignedWebhookNotification_Retries5xx(t *testing.T) {
+	defaultDelay := webhookRetryBaseDelay
+	webhookRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { webhookRetryBaseDelay = defaultDelay })
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	event, err := n.WebhookEvent()
+	require.NoError(t, err)
+	require.NoError(t, n.SendSignedWebhookNotification(signedWebhookURL(server, "s3cret"), event))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestSendSignedWebhookNotification_BoundsRetries(t *testing.T) {
+	defaultDelay := webhookRetryBaseDelay
+	webhookRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { webhookRetryBaseDelay = defaultDelay })
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	event, err := n.WebhookEvent()
+	require.NoError(t, err)
+	err = n.SendSignedWebhookNotification(signedWebhookURL(server, "s3cret"), event)
+	require.ErrorContains(t, err, "status 503")
+	require.True(t, IsRetryable(err), "deliveries that still fail are queued")
+	require.Equal(t, int32(webhookMaxAttempts), atomic.LoadInt32(&calls))
+}
+
+func TestSendSignedWebhookNotification_DoesNotRetry4xx(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	event, err := n.WebhookEvent()
+	require.NoError(t, err)
+	err = n.SendSignedWebhookNotification(signedWebhookURL(server, "s3cret"), event)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "s3cret")
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSendSignedWebhookNotification_RequiresSecret(t *testing.T) {
+	n := New(logrus.New(), nil, templateTestDevice(), false)
+	err := n.SendSignedWebhookNotification("webhook+https://hooks.example.com/scrutiny", WebhookEvent{})
+	require.ErrorContains(t, err, "requires a secret")
+}
+
+func TestVerifyWebhookSignature_RejectsReplayAndTampering(t *testing.T) {
+	body := []byte(`{"type":"SmartFailure"}`)
+	sent := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set(WebhookHeaderTimestamp, strconv.FormatInt(sent.Unix(), 10))
+	header.Set(WebhookHeaderSignature, SignWebhookPayload("s3cret", sent.Unix(), body))
+
+	require.NoError(t, VerifyWebhookSignature("s3cret", header, body, 5*time.Minute, sent.Add(time.Minute)))
+	require.Error(t, VerifyWebhookSignature("s3cret", header, body, 5*time.Minute, sent.Add(10*time.Minute)))
+	require.Error(t, VerifyWebhookSignature("other", header, body, 5*time.Minute, sent))
+	require.Error(t, VerifyWebhookSignature("s3cret", header, []byte(`{}`), 5*time.Minute, sent))
+}
+
+func TestMaskNotifyUrl_SignedWebhookSecret(t *testing.T) {
+	require.Equal(t, "webhook+https://***@hooks.example.com/scrutiny", MaskNotifyUrl("webhook+https://s3cret@hooks.example.com/scrutiny"))
+}
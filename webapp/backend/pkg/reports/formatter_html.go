@@ -40,6 +40,10 @@ func FormatHTMLReport(report *ReportData) string {
 		report.PeriodStart.Format("Jan 2, 2006"),
 		report.PeriodEnd.Format("Jan 2, 2006"),
 		bannerLabel)
+	if len(report.Tags) > 0 {
+		fmt.Fprintf(&b, `<tr><td style="padding:10px 30px 0;color:#6c757d;font-size:13px;">Tags: %s</td></tr>
+`, escapeHTML(strings.Join(report.Tags, ", ")))
+	}
 
 	// Summary counts
 	b.WriteString(`<tr><td style="padding:20px 30px;">
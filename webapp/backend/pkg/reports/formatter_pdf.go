@@ -80,6 +80,9 @@ func writeHeader(pdf *fpdf.Fpdf, report *ReportData) {
 		report.PeriodStart.Format("Jan 2, 2006"),
 		report.PeriodEnd.Format("Jan 2, 2006")), "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 7, fmt.Sprintf("Generated: %s", report.GeneratedAt.Format("Jan 2, 2006 15:04 MST")), "", 1, "L", false, 0, "")
+	if len(report.Tags) > 0 {
+		pdf.CellFormat(0, 7, fmt.Sprintf("Tags: %s", strings.Join(report.Tags, ", ")), "", 1, "L", false, 0, "")
+	}
 
 	pdf.Ln(5)
 	pdf.SetDrawColor(200, 200, 200)
@@ -28,6 +28,9 @@ func FormatTextReport(report *ReportData) (string, string) {
 	if report.ArchivedDevices > 0 {
 		parts = append(parts, fmt.Sprintf("  (%d archived, excluded from report)", report.ArchivedDevices))
 	}
+	if len(report.Tags) > 0 {
+		parts = append(parts, fmt.Sprintf("Tags: %s", strings.Join(report.Tags, ", ")))
+	}
 
 	// Alert sections
 	parts = appendAlertSection(parts, report, "failed", "FAILURES:")
@@ -27,9 +27,13 @@ func NewGenerator(repo SummaryProvider) *Generator {
 	return &Generator{repo: repo}
 }
 
-// Generate builds a complete ReportData for the given period
-func (g *Generator) Generate(ctx context.Context, periodType string, start, end time.Time) (*ReportData, error) {
+// Generate builds a complete ReportData for the given period, limited to the devices and ZFS pools
+// matching the tag filter
+func (g *Generator) Generate(ctx context.Context, periodType string, start, end time.Time, tags models.TagFilter) (*ReportData, error) {
 	report := NewReportData(periodType, start, end)
+	if len(tags) > 0 {
+		report.Tags = tags
+	}
 
 	summaries, err := g.repo.GetSummary(ctx)
 	if err != nil {
@@ -44,6 +48,9 @@ func (g *Generator) Generate(ctx context.Context, periodType string, start, end
 
 	archivedCount := 0
 	for devID, summary := range summaries {
+		if !tags.Matches(summary.Device.Tags) {
+			continue
+		}
 		if summary.Device.Archived {
 			archivedCount++
 			continue
@@ -71,7 +78,7 @@ func (g *Generator) Generate(ctx context.Context, periodType string, start, end
 	report.WarningDevices = report.TotalDevices - report.PassedDevices - report.FailedDevices
 
 	// Populate ZFS pool data
-	g.populateZFSPools(ctx, report)
+	g.populateZFSPools(ctx, report, tags)
 
 	return report, nil
 }
@@ -111,14 +118,14 @@ func (g *Generator) populateAlerts(ctx context.Context, dr *DeviceReport, wwn st
 	}
 }
 
-func (g *Generator) populateZFSPools(ctx context.Context, report *ReportData) {
+func (g *Generator) populateZFSPools(ctx context.Context, report *ReportData, tags models.TagFilter) {
 	poolsSummary, err := g.repo.GetZFSPoolsSummary(ctx)
 	if err != nil {
 		return
 	}
 
 	for _, pool := range poolsSummary {
-		if pool == nil {
+		if pool == nil || !tags.Matches(pool.Tags) {
 			continue
 		}
 
@@ -153,6 +160,7 @@ func buildDeviceReport(summary *models.DeviceSummary, temps []measurements.Smart
 		Protocol: summary.Device.DeviceProtocol,
 		HostID:   summary.Device.HostId,
 		Label:    summary.Device.Label,
+		Tags:     summary.Device.Tags,
 		Status:   int(summary.Device.DeviceStatus),
 
 		NewAlerts:      []AlertEntry{},
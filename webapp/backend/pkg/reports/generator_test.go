@@ -93,7 +93,7 @@ func TestGenerateReport_BasicSummary(t *testing.T) {
 	}
 
 	gen := NewGenerator(mock)
-	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now)
+	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now, nil)
 	require.NoError(t, err)
 
 	assert.Equal(t, "daily", report.PeriodType)
@@ -127,7 +127,7 @@ func TestGenerateReport_TempAggregation(t *testing.T) {
 	}
 
 	gen := NewGenerator(mock)
-	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now)
+	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now, nil)
 	require.NoError(t, err)
 
 	require.Len(t, report.Devices, 1)
@@ -160,7 +160,7 @@ func TestGenerateReport_ArchivedDevicesExcluded(t *testing.T) {
 	}
 
 	gen := NewGenerator(mock)
-	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now)
+	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now, nil)
 	require.NoError(t, err)
 
 	assert.Equal(t, 1, report.TotalDevices)
@@ -190,7 +190,7 @@ func TestGenerateReport_ZFSPoolsPopulated(t *testing.T) {
 	}
 
 	gen := NewGenerator(mock)
-	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now)
+	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now, nil)
 	require.NoError(t, err)
 
 	require.Len(t, report.ZFSPools, 1)
@@ -201,3 +201,38 @@ func TestGenerateReport_ZFSPoolsPopulated(t *testing.T) {
 	assert.Equal(t, "completed", pool.ScrubStatus)
 	assert.NotNil(t, pool.LastScrubDate)
 }
+
+func TestGenerateReport_TagFilter(t *testing.T) {
+	now := time.Now()
+	mock := &mockSummaryProvider{
+		summary: map[string]*models.DeviceSummary{
+			"devid-1": {
+				Device: models.Device{DeviceID: "devid-1", WWN: "wwn1", Tags: []string{"backup", "offsite"}, DeviceStatus: pkg.DeviceStatusPassed},
+			},
+			"devid-2": {
+				Device: models.Device{DeviceID: "devid-2", WWN: "wwn2", Tags: []string{"scratch"}, DeviceStatus: pkg.DeviceStatusFailedSmart},
+			},
+		},
+		tempHistory: map[string][]measurements.SmartTemperature{},
+		zfsPoolSummary: map[string]*models.ZFSPool{
+			"guid1": {GUID: "guid1", Name: "tank", Tags: []string{"backup"}},
+			"guid2": {GUID: "guid2", Name: "scratch"},
+		},
+	}
+
+	gen := NewGenerator(mock)
+	report, err := gen.Generate(context.Background(), "daily", now.Add(-24*time.Hour), now, models.TagFilter{"backup"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"backup"}, report.Tags)
+	assert.Equal(t, 1, report.TotalDevices)
+	assert.Equal(t, 0, report.FailedDevices)
+	require.Len(t, report.Devices, 1)
+	assert.Equal(t, []string{"backup", "offsite"}, report.Devices[0].Tags)
+	require.Len(t, report.ZFSPools, 1)
+	assert.Equal(t, "tank", report.ZFSPools[0].Name)
+
+	subject, message := FormatTextReport(report)
+	assert.NotEmpty(t, subject)
+	assert.Contains(t, message, "Tags: backup")
+}
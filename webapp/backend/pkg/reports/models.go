@@ -9,7 +9,8 @@ type ReportData struct {
 	GeneratedAt time.Time `json:"generated_at"`
 	PeriodStart time.Time `json:"period_start"`
 	PeriodEnd   time.Time `json:"period_end"`
-	PeriodType  string    `json:"period_type"` // "daily", "weekly", "monthly"
+	PeriodType  string    `json:"period_type"`    // "daily", "weekly", "monthly"
+	Tags        []string  `json:"tags,omitempty"` // only devices and pools with all of these tags are included
 
 	Devices  []DeviceReport  `json:"devices"`
 	ZFSPools []ZFSPoolReport `json:"zfs_pools"`
@@ -53,13 +54,14 @@ func (r *ReportData) OverallStatus() string {
 
 // DeviceReport contains health data for a single device within a report period
 type DeviceReport struct {
-	WWN      string `json:"wwn"`
-	Name     string `json:"name"`
-	Model    string `json:"model"`
-	Serial   string `json:"serial"`
-	Protocol string `json:"protocol"` // ATA, NVMe, SCSI
-	HostID   string `json:"host_id"`
-	Label    string `json:"label"`
+	WWN      string   `json:"wwn"`
+	Name     string   `json:"name"`
+	Model    string   `json:"model"`
+	Serial   string   `json:"serial"`
+	Protocol string   `json:"protocol"` // ATA, NVMe, SCSI
+	HostID   string   `json:"host_id"`
+	Label    string   `json:"label"`
+	Tags     []string `json:"tags,omitempty"`
 
 	PercentageUsed *int64              `json:"percentage_used,omitempty"`
 	WearoutValue   *int64              `json:"wearout_value,omitempty"`
@@ -12,6 +12,7 @@ import (
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/version"
 	"github.com/sirupsen/logrus"
@@ -27,12 +28,12 @@ const (
 
 // Scheduler runs report generation on configured schedules
 type Scheduler struct {
-	appConfig  config.Interface
-	logger     logrus.FieldLogger
-	deviceRepo database.DeviceRepo
-	ctx        context.Context
-	cancel     context.CancelFunc
-	stopCh     chan struct{}
+	appConfig   config.Interface
+	logger      logrus.FieldLogger
+	deviceRepo  database.DeviceRepo
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stopCh      chan struct{}
 	repoFactory func() (database.DeviceRepo, error)
 
 	lastDailyRun   time.Time
@@ -194,7 +195,7 @@ func (s *Scheduler) runReport(periodType string, now time.Time, pdfEnabled bool,
 	}
 
 	gen := NewGenerator(repo)
-	report, err := gen.Generate(s.ctx, periodType, start, now)
+	report, err := gen.Generate(s.ctx, periodType, start, now, nil)
 	if err != nil {
 		s.logger.Errorf("Failed to generate %s report: %v", periodType, err)
 		return
@@ -289,8 +290,9 @@ func (s *Scheduler) getRepo() (database.DeviceRepo, error) {
 	return repo, nil
 }
 
-// GenerateOnDemand generates a report immediately and returns the data.
-func (s *Scheduler) GenerateOnDemand(ctx context.Context, periodType string) (*ReportData, error) {
+// GenerateOnDemand generates a report immediately and returns the data. A non-empty tag filter
+// limits the report to devices and pools having all of the tags.
+func (s *Scheduler) GenerateOnDemand(ctx context.Context, periodType string, tags models.TagFilter) (*ReportData, error) {
 	repo, err := s.getRepo()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get device repo: %w", err)
@@ -310,12 +312,12 @@ func (s *Scheduler) GenerateOnDemand(ctx context.Context, periodType string) (*R
 	}
 
 	gen := NewGenerator(repo)
-	return gen.Generate(ctx, periodType, start, now)
+	return gen.Generate(ctx, periodType, start, now, tags)
 }
 
 // SendTestReport generates a report and sends it via the notification system.
-func (s *Scheduler) SendTestReport(ctx context.Context, periodType string) (*ReportData, error) {
-	report, err := s.GenerateOnDemand(ctx, periodType)
+func (s *Scheduler) SendTestReport(ctx context.Context, periodType string, tags models.TagFilter) (*ReportData, error) {
+	report, err := s.GenerateOnDemand(ctx, periodType, tags)
 	if err != nil {
 		return nil, err
 	}
@@ -327,8 +329,8 @@ func (s *Scheduler) SendTestReport(ctx context.Context, periodType string) (*Rep
 }
 
 // GenerateOnDemandPDF generates a PDF and returns the file path.
-func (s *Scheduler) GenerateOnDemandPDF(ctx context.Context, periodType string) (string, error) {
-	report, err := s.GenerateOnDemand(ctx, periodType)
+func (s *Scheduler) GenerateOnDemandPDF(ctx context.Context, periodType string, tags models.TagFilter) (string, error) {
+	report, err := s.GenerateOnDemand(ctx, periodType, tags)
 	if err != nil {
 		return "", err
 	}
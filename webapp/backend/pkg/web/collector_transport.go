@@ -0,0 +1,102 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/mqtt"
+	"github.com/gin-gonic/gin"
+)
+
+// collectorTransportRoutes are the API routes collectors call, the only ones served over MQTT.
+// Parameters match a single path segment.
+var collectorTransportRoutes = []string{
+	"api/devices/register",
+	"api/device/:id/smart",
+	"api/device/:id/selftest",
+	"api/device/:id/performance",
+	"api/device/:id/collector-error",
+	"api/collector/scan-error",
+	"api/zfs/pools/register",
+	"api/zfs/pool/:guid/metrics",
+	"api/mdadm/arrays/register",
+	"api/mdadm/array/:uuid/metrics",
+	"api/btrfs/filesystems/register",
+	"api/btrfs/filesystem/:uuid/metrics",
+	"api/filesystems/summary",
+}
+
+// isCollectorTransportRoute returns true if a request sent over MQTT may be processed.
+func isCollectorTransportRoute(method, path string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+	segments := strings.Split(path, "/")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for _, route := range collectorTransportRoutes {
+		routeSegments := strings.Split(route, "/")
+		if len(routeSegments) != len(segments) {
+			continue
+		}
+		matches := true
+		for i, routeSegment := range routeSegments {
+			if !strings.HasPrefix(routeSegment, ":") && routeSegment != segments[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// collectorTransportHandler processes requests collectors send over MQTT with the same handlers,
+// middleware and authentication as the HTTP routes, by serving them with the router.
+func collectorTransportHandler(r *gin.Engine, basePath string) mqtt.CollectorRequestHandler {
+	basePath = strings.TrimSuffix(basePath, "/")
+	return func(ctx context.Context, _ string, req *collector.TransportRequest) collector.TransportResponse {
+		if !isCollectorTransportRoute(req.Method, req.Path) {
+			return transportErrorResponse(http.StatusNotFound, fmt.Errorf("%s %s is not available over MQTT", req.Method, req.Path))
+		}
+
+		target := basePath + "/" + req.Path
+		if req.Query != "" {
+			target += "?" + req.Query
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, bytes.NewReader(req.Body))
+		if err != nil {
+			return transportErrorResponse(http.StatusBadRequest, err)
+		}
+		for name, value := range req.Headers {
+			httpReq.Header.Set(name, value)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httpReq)
+
+		body := recorder.Body.Bytes()
+		if len(body) > 0 && !json.Valid(body) {
+			// e.g. the plain text 404 page, keep it readable in the collector's error
+			body, _ = json.Marshal(string(body))
+		}
+		return collector.TransportResponse{Status: recorder.Code, Body: body}
+	}
+}
+
+func transportErrorResponse(status int, err error) collector.TransportResponse {
+	body, _ := json.Marshal(gin.H{"success": false, "error": err.Error()})
+	return collector.TransportResponse{Status: status, Body: body}
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCollectorTransportRoute(t *testing.T) {
+	require.True(t, isCollectorTransportRoute(http.MethodPost, "api/devices/register"))
+	require.True(t, isCollectorTransportRoute(http.MethodPost, "api/device/0x5000c500673e6b5f/smart"))
+	require.True(t, isCollectorTransportRoute(http.MethodPost, "api/zfs/pool/1234567890/metrics"))
+	require.True(t, isCollectorTransportRoute(http.MethodPost, "api/filesystems/summary"))
+
+	// only the collector uploads, not the UI endpoints or reads
+	require.False(t, isCollectorTransportRoute(http.MethodGet, "api/devices/register"))
+	require.False(t, isCollectorTransportRoute(http.MethodPost, "api/device/0x5000c500673e6b5f/archive"))
+	require.False(t, isCollectorTransportRoute(http.MethodPost, "api/settings"))
+	require.False(t, isCollectorTransportRoute(http.MethodPost, "api/device/0x5000c500673e6b5f/smart/extra"))
+	require.False(t, isCollectorTransportRoute(http.MethodPost, "api/device/../smart"))
+	require.False(t, isCollectorTransportRoute(http.MethodPost, "/api/devices/register"))
+}
+
+func TestCollectorTransportHandler_ServesRequestWithRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var received struct {
+		path, query, auth, body string
+	}
+	r.POST("/scrutiny/api/device/:id/smart", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received.path = c.Param("id")
+		received.query = c.Query("host")
+		received.auth = c.GetHeader("Authorization")
+		received.body = string(body)
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	handler := collectorTransportHandler(r, "/scrutiny/")
+	response := handler(context.Background(), "scrutiny-collector-nas", &collector.TransportRequest{
+		ID:      "1",
+		Method:  http.MethodPost,
+		Path:    "api/device/0x5000c500673e6b5f/smart",
+		Query:   "host=nas",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Body:    json.RawMessage(`{"smartctl":{}}`),
+	})
+
+	require.Equal(t, http.StatusOK, response.Status)
+	require.JSONEq(t, `{"success":true}`, string(response.Body))
+	require.Equal(t, "0x5000c500673e6b5f", received.path)
+	require.Equal(t, "nas", received.query)
+	require.Equal(t, "Bearer secret", received.auth)
+	require.Equal(t, `{"smartctl":{}}`, received.body)
+
+	response = handler(context.Background(), "scrutiny-collector-nas", &collector.TransportRequest{
+		ID:     "2",
+		Method: http.MethodPost,
+		Path:   "api/settings",
+	})
+	require.Equal(t, http.StatusNotFound, response.Status)
+
+	// a collector route the router does not serve returns the plain text 404 page as a JSON string
+	response = handler(context.Background(), "scrutiny-collector-nas", &collector.TransportRequest{
+		ID:     "3",
+		Method: http.MethodPost,
+		Path:   "api/filesystems/summary",
+	})
+	require.Equal(t, http.StatusNotFound, response.Status)
+	require.True(t, json.Valid(response.Body))
+}
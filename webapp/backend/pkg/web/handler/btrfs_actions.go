@@ -72,6 +72,8 @@ func DeleteBtrfsFilesystem(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	removeMqttBtrfsFilesystem(c, uuid)
+	refreshTagMetrics(c, logger, deviceRepo)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -90,6 +92,11 @@ func updateBtrfsArchived(c *gin.Context, archived bool) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	if archived {
+		removeMqttBtrfsFilesystem(c, uuid)
+	} else {
+		publishMqttBtrfsFilesystem(c, deviceRepo, logger, uuid)
+	}
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
@@ -107,5 +114,6 @@ func updateBtrfsMuted(c *gin.Context, muted bool) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttBtrfsFilesystemMuted(c, uuid, muted)
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -19,6 +19,14 @@ func GetBtrfsFilesystemsSummary(c *gin.Context) {
 		return
 	}
 
+	if tagFilter := tagFilterFromQuery(c); len(tagFilter) > 0 {
+		for uuid, filesystem := range summary {
+			if !tagFilter.Matches(filesystem.Tags) {
+				delete(summary, uuid)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -38,6 +38,10 @@ func RegisterBtrfsFilesystems(c *gin.Context) {
 		return
 	}
 
+	recordHostCheckIns(c, logger, deviceRepo, models.CollectorTypeBtrfs, lo.Map(filesystems, func(filesystem models.BtrfsFilesystem, _ int) string {
+		return filesystem.HostID
+	})...)
+
 	c.JSON(http.StatusOK, models.BtrfsFilesystemWrapper{
 		Success: true,
 		Data:    filesystems,
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/validation"
 	"github.com/gin-gonic/gin"
@@ -39,6 +40,15 @@ func UploadBtrfsMetrics(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttBtrfsFilesystem(c, deviceRepo, logger, uuid)
+
+	if collectorVal, exists := c.Get("METRICS_COLLECTOR"); exists {
+		if collector, ok := collectorVal.(*metrics.Collector); ok && collector != nil {
+			if err := collector.RefreshBtrfsFilesystemMetrics(deviceRepo, c, uuid); err != nil {
+				logger.Warnf("Failed to refresh Prometheus Btrfs metrics for filesystem %s: %v", uuid, err)
+			}
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
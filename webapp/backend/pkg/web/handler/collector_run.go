@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os/exec"
 	"time"
@@ -10,18 +11,33 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ErrCollectorsNotFound is returned by RunCollectors when the collector binaries are not installed.
+var ErrCollectorsNotFound = errors.New("collector binaries not found on this system")
+
 // TriggerCollectors triggers all local collector binaries sequentially in the background
 func TriggerCollectors(c *gin.Context) {
 	logger := c.MustGet("LOGGER").(*logrus.Entry)
 
-	// Check if the primary collector binary exists
-	_, execErr := exec.LookPath("scrutiny-collector-metrics")
-	if execErr != nil {
+	if err := RunCollectors(logger); err != nil {
 		logger.Warn("Manually triggered collectors, but scrutiny-collector-metrics not found in PATH")
 		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Collector binaries not found on this system"})
 		return
 	}
 
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Collectors triggered successfully",
+	})
+}
+
+// RunCollectors starts the local collector binaries sequentially in the background, e.g. in
+// omnibus mode. It is also used by the MQTT run collectors command.
+func RunCollectors(logger logrus.FieldLogger) error {
+	// Check if the primary collector binary exists
+	if _, err := exec.LookPath("scrutiny-collector-metrics"); err != nil {
+		return ErrCollectorsNotFound
+	}
+
 	// Run collectors in the background sequentially
 	go func() {
 		// Use a detached context for background execution
@@ -43,12 +59,12 @@ func TriggerCollectors(c *gin.Context) {
 			}
 
 			logger.Infof("Executing collector: %s", bin)
-			
+
 			// Create command with a timeout to prevent hanging processes
 			ctx, cancel := context.WithTimeout(bgCtx, 5*time.Minute)
 			cmd := exec.CommandContext(ctx, path, "run")
-			
-			// We don't capture stdout/stderr here to keep it simple, 
+
+			// We don't capture stdout/stderr here to keep it simple,
 			// but we log the result. Collectors log to their own destinations usually.
 			output, err := cmd.CombinedOutput()
 			if err != nil {
@@ -57,16 +73,12 @@ func TriggerCollectors(c *gin.Context) {
 				logger.Infof("Collector %s completed successfully", bin)
 			}
 			cancel()
-			
+
 			// Small buffer between collectors
 			time.Sleep(1 * time.Second)
 		}
-		
+
 		logger.Info("Manual sequential collector run finished")
 	}()
-
-	c.JSON(http.StatusAccepted, gin.H{
-		"success": true,
-		"message": "Collectors triggered successfully",
-	})
+	return nil
 }
@@ -26,6 +26,7 @@ func DeleteDevice(c *gin.Context) {
 
 	// Remove device from Home Assistant MQTT discovery (if enabled)
 	removeMqttDevice(c, &device)
+	refreshTagMetrics(c, logger, deviceRepo)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
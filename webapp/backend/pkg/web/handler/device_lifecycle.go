@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetDeviceLifecycleEvents lists device lifecycle events (new, disappeared, reappeared and swapped
+// devices), newest first. Optional filters: host_id, device_id, type, since (RFC3339) and limit.
+func GetDeviceLifecycleEvents(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	filter := models.DeviceLifecycleFilter{
+		HostID:   strings.TrimSpace(c.Query("host_id")),
+		DeviceID: strings.TrimSpace(c.Query("device_id")),
+		Type:     strings.ToLower(strings.TrimSpace(c.Query("type"))),
+	}
+	if filter.Type != "" && !models.ValidLifecycleEventType(filter.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "type must be one of new_device, device_disappeared, device_reappeared or device_swapped"})
+		return
+	}
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "since must be an RFC3339 timestamp"})
+			return
+		}
+		filter.Since = &parsed
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "limit must be a non-negative integer"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := deviceRepo.GetDeviceLifecycleEvents(c, filter)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving device lifecycle events", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": events})
+}
+
+// recordDeviceLifecycleEvents detects devices added, disappeared, reappeared or swapped by a
+// registration, given the devices known before it, and notifies about them when
+// metrics.notify_on_lifecycle_events is enabled. Failures are logged and never fail the registration.
+func recordDeviceLifecycleEvents(c *gin.Context, logger *logrus.Entry, deviceRepo database.DeviceRepo, known []models.Device, registered []models.Device) {
+	events, err := deviceRepo.RecordDeviceLifecycleEvents(c, known, registered)
+	if err != nil {
+		logger.Warnf("Failed to record device lifecycle events: %v", err)
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	devices := map[string]models.Device{}
+	for _, device := range known {
+		devices[device.DeviceID] = device
+	}
+	for _, device := range registered {
+		devices[device.DeviceID] = device
+	}
+	for _, event := range events {
+		logger.Infof("Device lifecycle event %s on host %q: device %s (%s)", event.Type, event.HostID, event.DeviceID, event.DeviceName)
+	}
+
+	settings, err := deviceRepo.LoadSettings(c)
+	if err != nil || settings == nil {
+		logger.Warnf("Failed to load settings for device lifecycle notifications: %v", err)
+		return
+	}
+	if !settings.Metrics.NotifyOnLifecycleEvents {
+		return
+	}
+
+	appConfig := c.MustGet("CONFIG").(config.Interface)
+	var gate *notify.NotificationGate
+	if gateVal, exists := c.Get("NOTIFICATION_GATE"); exists {
+		gate, _ = gateVal.(*notify.NotificationGate)
+	}
+	for _, event := range events {
+		device, ok := devices[event.DeviceID]
+		if !ok || device.Muted {
+			continue
+		}
+		lifecycleNotify := notify.NewDeviceLifecycle(logger, appConfig, event, &device)
+		lifecycleNotify.LoadDatabaseUrls(c, deviceRepo)
+		if gate != nil {
+			gate.TrySend(&lifecycleNotify, settings, false)
+			continue
+		}
+		if sendErr := lifecycleNotify.Send(); sendErr != nil {
+			logger.Warnf("Failed to send device lifecycle notification for device %s: %v", event.DeviceID, sendErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeviceLifecycleRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/devices/lifecycle-events", handler.GetDeviceLifecycleEvents)
+	return r
+}
+
+func TestGetDeviceLifecycleEvents_AppliesFilter(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceLifecycleEvents(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, filter models.DeviceLifecycleFilter) ([]models.DeviceLifecycleEvent, error) {
+			require.Equal(t, "nas", filter.HostID)
+			require.Equal(t, models.LifecycleEventDeviceSwapped, filter.Type)
+			require.Equal(t, 10, filter.Limit)
+			require.NotNil(t, filter.Since)
+			return []models.DeviceLifecycleEvent{{ID: 3, Type: models.LifecycleEventDeviceSwapped, HostID: "nas", DeviceID: "c", PreviousDeviceID: "b", Slot: "Bay 2"}}, nil
+		})
+
+	router := setupDeviceLifecycleRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/devices/lifecycle-events?host_id=nas&type=device_swapped&limit=10&since=2026-10-01T00:00:00Z", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool                          `json:"success"`
+		Data    []models.DeviceLifecycleEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Len(t, response.Data, 1)
+	require.Equal(t, "Bay 2", response.Data[0].Slot)
+}
+
+func TestGetDeviceLifecycleEvents_RejectsInvalidQuery(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	router := setupDeviceLifecycleRouter(t, mock_database.NewMockDeviceRepo(mockCtrl))
+
+	for _, query := range []string{"type=exploded", "since=yesterday", "limit=-1"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/devices/lifecycle-events?"+query, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
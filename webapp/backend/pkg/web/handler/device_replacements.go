@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type recordDeviceReplacementRequest struct {
+	NewDeviceID string `json:"new_device_id"`
+	Reason      string `json:"reason"`
+	ReplacedAt  string `json:"replaced_at"`
+	Notes       string `json:"notes"`
+	Slot        string `json:"slot"`
+}
+
+// RecordDeviceReplacement records that the device in the URL was replaced by new_device_id. The
+// replaced device is archived; its history is kept and linked to the replacement.
+func RecordDeviceReplacement(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	oldDevice, err := ResolveDevice(c, logger, deviceRepo)
+	if err != nil {
+		return
+	}
+
+	var request recordDeviceReplacementRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warnf("Invalid replacement request body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	if request.NewDeviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "new_device_id is required"})
+		return
+	}
+	reason := models.ReplacementReason(strings.ToLower(strings.TrimSpace(request.Reason)))
+	if !reason.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "reason must be one of failed, upgraded, rma"})
+		return
+	}
+	replacedAt, err := parseInventoryDate(request.ReplacedAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "replaced_at: " + err.Error()})
+		return
+	}
+
+	replacement := models.DeviceReplacement{
+		OldDeviceID: oldDevice.DeviceID,
+		NewDeviceID: request.NewDeviceID,
+		Reason:      reason,
+		Notes:       strings.TrimSpace(request.Notes),
+		Slot:        request.Slot,
+	}
+	if replacedAt != nil {
+		replacement.ReplacedAt = *replacedAt
+	}
+
+	replacement, err = deviceRepo.RecordDeviceReplacement(c, replacement)
+	if err != nil {
+		logger.Errorln("An error occurred while recording device replacement", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": replacement})
+}
+
+// GetDeviceLineage returns every device that preceded or succeeded the device in the URL.
+func GetDeviceLineage(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	device, err := ResolveDevice(c, logger, deviceRepo)
+	if err != nil {
+		return
+	}
+
+	lineage, err := deviceRepo.GetDeviceLineage(c, device.DeviceID)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving device lineage", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": lineage})
+}
+
+// GetLineages returns the lineage of a bay (?slot=) or pool member (?pool_type=&pool_id=&member=),
+// optionally limited to one host (?host_id=).
+func GetLineages(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	filter, ok := replacementFilterFromQuery(c)
+	if !ok {
+		return
+	}
+	if filter.Slot == "" && filter.PoolType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "slot or pool_type and pool_id are required"})
+		return
+	}
+
+	lineages, err := deviceRepo.GetDeviceLineages(c, filter)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving lineages", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": lineages})
+}
+
+// GetDeviceReplacements lists recorded replacements, with counts by reason and failed model, using
+// the same filters as GetLineages.
+func GetDeviceReplacements(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	filter, ok := replacementFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	replacements, err := deviceRepo.GetDeviceReplacements(c, filter)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving device replacements", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"replacements": replacements,
+			"stats":        models.NewReplacementStats(replacements),
+		},
+	})
+}
+
+// DeleteDeviceReplacement removes a replacement record created by mistake.
+func DeleteDeviceReplacement(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := deviceRepo.DeleteDeviceReplacement(c, id); err != nil {
+		if errors.Is(err, database.ErrDeviceReplacementNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		logger.Errorln("An error occurred while deleting device replacement", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func replacementFilterFromQuery(c *gin.Context) (models.ReplacementFilter, bool) {
+	filter := models.ReplacementFilter{
+		HostId:     strings.TrimSpace(c.Query("host_id")),
+		Slot:       strings.TrimSpace(c.Query("slot")),
+		PoolType:   strings.ToLower(strings.TrimSpace(c.Query("pool_type"))),
+		PoolID:     strings.TrimSpace(c.Query("pool_id")),
+		PoolMember: strings.TrimSpace(c.Query("member")),
+	}
+	switch filter.PoolType {
+	case "":
+		if filter.PoolID != "" || filter.PoolMember != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "pool_id and member require pool_type"})
+			return filter, false
+		}
+	case models.PoolTypeZFS, models.PoolTypeMdadm, models.PoolTypeBtrfs:
+		if filter.PoolID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "pool_id is required with pool_type"})
+			return filter, false
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "pool_type must be one of zfs, mdadm, btrfs"})
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeviceReplacementsRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.POST("/api/device/:id/replaced-by", handler.RecordDeviceReplacement)
+	r.GET("/api/lineage", handler.GetLineages)
+	return r
+}
+
+func TestRecordDeviceReplacement_InvalidReason(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceID).Return(models.Device{DeviceID: testDeviceID}, nil)
+
+	router := setupDeviceReplacementsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/"+testDeviceID+"/replaced-by", strings.NewReader(`{"new_device_id":"`+testDestinationDeviceID+`","reason":"bored"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "reason")
+}
+
+func TestRecordDeviceReplacement_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceID).Return(models.Device{DeviceID: testDeviceID}, nil)
+	mockRepo.EXPECT().RecordDeviceReplacement(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, replacement models.DeviceReplacement) (models.DeviceReplacement, error) {
+			require.Equal(t, testDeviceID, replacement.OldDeviceID)
+			require.Equal(t, testDestinationDeviceID, replacement.NewDeviceID)
+			require.Equal(t, models.ReplacementReasonRMA, replacement.Reason)
+			require.True(t, replacement.ReplacedAt.Equal(time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)))
+			replacement.ID = 7
+			return replacement, nil
+		})
+
+	router := setupDeviceReplacementsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/"+testDeviceID+"/replaced-by", strings.NewReader(`{"new_device_id":"`+testDestinationDeviceID+`","reason":"RMA","replaced_at":"2026-09-30"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data    models.DeviceReplacement `json:"data"`
+		Success bool                     `json:"success"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Equal(t, uint(7), response.Data.ID)
+}
+
+func TestGetLineages_RequiresSlotOrPool(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceLineages(gomock.Any(), models.ReplacementFilter{PoolType: "zfs", PoolID: "123"}).Return([]models.DeviceLineage{}, nil)
+
+	router := setupDeviceReplacementsRouter(t, mockRepo)
+
+	for _, query := range []string{"", "?pool_id=123", "?pool_type=lvm&pool_id=1", "?pool_type=zfs"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/lineage"+query, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/lineage?pool_type=ZFS&pool_id=123", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
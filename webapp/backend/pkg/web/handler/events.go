@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// eventsKeepAlive is how often an idle stream sends a keep-alive (an SSE comment or a WebSocket
+// ping), so proxies do not close it. WebSocket clients that miss two pings are disconnected.
+const eventsKeepAlive = 25 * time.Second
+
+// eventsWriteTimeout bounds a single write to a WebSocket client.
+const eventsWriteTimeout = 10 * time.Second
+
+var eventsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// GetEvents streams events as they happen, as Server-Sent Events or, for WebSocket upgrade
+// requests, as one JSON message per event. The optional host_id, device_id and types (comma
+// separated) query parameters filter the stream.
+func GetEvents(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+
+	bus := eventBus(c)
+	if bus == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "errors": []string{"the event stream is not available"}})
+		return
+	}
+
+	filter := events.Filter{
+		HostID:   strings.TrimSpace(c.Query("host_id")),
+		DeviceID: strings.TrimSpace(c.Query("device_id")),
+	}
+	if types := strings.TrimSpace(c.Query("types")); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			if !events.IsValidType(t) {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "errors": []string{fmt.Sprintf("unknown event type %q, expected one of %s", t, strings.Join(events.Types, ", "))}})
+				return
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		streamEventsWebSocket(c, logger, bus, filter)
+		return
+	}
+	streamEventsSSE(c, bus, filter)
+}
+
+func streamEventsSSE(c *gin.Context, bus *events.Bus, filter events.Filter) {
+	sub := bus.Subscribe(filter)
+	defer bus.Unsubscribe(sub)
+
+	// the stream outlives web.listen.write_timeout_seconds
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		case event, open := <-sub.Events():
+			if !open {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		c.Writer.Flush()
+	}
+}
+
+func streamEventsWebSocket(c *gin.Context, logger *logrus.Entry, bus *events.Bus, filter events.Filter) {
+	conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already replied with an error status
+		logger.Debugf("Event stream WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := bus.Subscribe(filter)
+	defer bus.Unsubscribe(sub)
+
+	// the client only sends pongs and close frames; reading detects the disconnect
+	closed := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(2 * eventsKeepAlive))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * eventsKeepAlive))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsWriteTimeout)); err != nil {
+				return
+			}
+		case event, open := <-sub.Events():
+			if !open {
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(eventsWriteTimeout))
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventBus returns the event bus registered by the EVENT_BUS middleware, or nil.
+func eventBus(c *gin.Context) *events.Bus {
+	if busVal, exists := c.Get("EVENT_BUS"); exists {
+		if bus, ok := busVal.(*events.Bus); ok {
+			return bus
+		}
+	}
+	return nil
+}
+
+// publishDeviceMetricsIngested publishes that SMART data was saved for a device.
+func publishDeviceMetricsIngested(c *gin.Context, device *models.Device, smartData *measurements.Smart) {
+	bus := eventBus(c)
+	if !bus.HasSubscribers() {
+		return
+	}
+	bus.Publish(events.Event{
+		Type:     events.TypeDeviceMetricsIngested,
+		HostID:   device.HostId,
+		DeviceID: device.DeviceID,
+		Data: map[string]interface{}{
+			"device_name":    device.DeviceName,
+			"wwn":            device.WWN,
+			"device_status":  device.DeviceStatus,
+			"temperature":    smartData.Temp,
+			"power_on_hours": smartData.PowerOnHours,
+			"date":           smartData.Date,
+		},
+	})
+}
+
+// publishDeviceStatusChanged publishes a device status change, e.g. from passed to failed.
+func publishDeviceStatusChanged(c *gin.Context, device *models.Device, previous pkg.DeviceStatus) {
+	if device.DeviceStatus == previous {
+		return
+	}
+	eventBus(c).Publish(events.Event{
+		Type:     events.TypeDeviceStatusChanged,
+		HostID:   device.HostId,
+		DeviceID: device.DeviceID,
+		Data: map[string]interface{}{
+			"device_name":     device.DeviceName,
+			"wwn":             device.WWN,
+			"device_status":   device.DeviceStatus,
+			"previous_status": previous,
+		},
+	})
+}
+
+// publishPoolStateChanged publishes a state change of a ZFS pool or mdadm array.
+func publishPoolStateChanged(c *gin.Context, kind, id, name, hostID, previous, current string) {
+	if previous == current {
+		return
+	}
+	eventBus(c).Publish(events.Event{
+		Type:   events.TypePoolStateChanged,
+		HostID: hostID,
+		Data: map[string]interface{}{
+			"kind":           kind,
+			"id":             id,
+			"name":           name,
+			"state":          current,
+			"previous_state": previous,
+		},
+	})
+}
+
+// publishCollectorError publishes an error reported by a collector, whether or not it is notified.
+func publishCollectorError(c *gin.Context, device *models.Device, errorType, errorMessage string) {
+	eventBus(c).Publish(events.Event{
+		Type:     events.TypeCollectorError,
+		HostID:   device.HostId,
+		DeviceID: device.DeviceID,
+		Data: map[string]interface{}{
+			"device_name":   device.DeviceName,
+			"error_type":    errorType,
+			"error_message": errorMessage,
+		},
+	})
+}
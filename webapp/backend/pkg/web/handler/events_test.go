@@ -0,0 +1,147 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEventsRouter(t *testing.T, bus *events.Bus) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetBool(testNotifySettingKey).Return(false).AnyTimes()
+	fakeRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	fakeRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceWWN).Return(models.Device{DeviceID: "dev-1", WWN: testDeviceWWN, HostId: "nas", DeviceName: "sda"}, nil).AnyTimes()
+
+	logger := logrus.WithField("test", t.Name())
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("CONFIG", fakeConfig)
+		c.Set("DEVICE_REPOSITORY", fakeRepo)
+		if bus != nil {
+			c.Set("EVENT_BUS", bus)
+		}
+		c.Next()
+	})
+	r.GET("/api/events", handler.GetEvents)
+	r.POST("/api/device/:id/collector-error", handler.UploadCollectorError)
+	return r
+}
+
+// waitForSubscriber waits until the stream under test subscribed to the bus.
+func waitForSubscriber(t *testing.T, bus *events.Bus) {
+	t.Helper()
+	require.Eventually(t, bus.HasSubscribers, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestGetEvents_StreamsServerSentEvents(t *testing.T) {
+	bus := events.NewBus(logrus.StandardLogger())
+	server := httptest.NewServer(setupEventsRouter(t, bus))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/events?host_id=nas&types=collector_error")
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	waitForSubscriber(t, bus)
+
+	// filtered out by host and type
+	bus.Publish(events.Event{Type: events.TypeCollectorError, HostID: "backup"})
+	bus.Publish(events.Event{Type: events.TypeNotificationSent, HostID: "nas"})
+
+	// the upload handler publishes the collector error even though it is not notified
+	upload, err := http.Post(server.URL+"/api/device/"+testDeviceWWN+"/collector-error", "application/json",
+		strings.NewReader(`{"error_type":"xall","error_message":"smartctl timed out"}`))
+	require.NoError(t, err)
+	upload.Body.Close()
+	require.Equal(t, http.StatusOK, upload.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	lines := []string{}
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	require.Equal(t, "id: 3", lines[0])
+	require.Equal(t, "event: collector_error", lines[1])
+
+	var event events.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event))
+	require.Equal(t, "dev-1", event.DeviceID)
+	require.Equal(t, "nas", event.HostID)
+	require.Equal(t, "xall", event.Data["error_type"])
+	require.Equal(t, "smartctl timed out", event.Data["error_message"])
+}
+
+func TestGetEvents_StreamsWebSocketMessages(t *testing.T) {
+	bus := events.NewBus(logrus.StandardLogger())
+	server := httptest.NewServer(setupEventsRouter(t, bus))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/events?device_id=dev-1", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	waitForSubscriber(t, bus)
+
+	bus.Publish(events.Event{Type: events.TypeDeviceStatusChanged, DeviceID: "dev-2"})
+	bus.Publish(events.Event{Type: events.TypeDeviceStatusChanged, DeviceID: "dev-1", Data: map[string]interface{}{"device_status": 2}})
+
+	var event events.Event
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&event))
+	require.Equal(t, events.TypeDeviceStatusChanged, event.Type)
+	require.Equal(t, "dev-1", event.DeviceID)
+	require.Equal(t, float64(2), event.Data["device_status"])
+
+	// closing the bus ends the stream
+	bus.Close()
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+}
+
+func TestGetEvents_RejectsUnknownTypes(t *testing.T) {
+	router := setupEventsRouter(t, events.NewBus(logrus.StandardLogger()))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/events?types=collector_error,disk_on_fire", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "disk_on_fire")
+}
+
+func TestGetEvents_WithoutBus(t *testing.T) {
+	router := setupEventsRouter(t, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/events", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
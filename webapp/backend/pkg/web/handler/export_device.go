@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+
+	exportRecordSmart       = "smart"
+	exportRecordAttribute   = "attribute"
+	exportRecordTemperature = "temperature"
+	exportRecordPerformance = "performance"
+)
+
+var exportDurationKeys = map[string]bool{
+	database.DURATION_KEY_DAY:     true,
+	database.DURATION_KEY_WEEK:    true,
+	database.DURATION_KEY_MONTH:   true,
+	database.DURATION_KEY_YEAR:    true,
+	database.DURATION_KEY_FOREVER: true,
+}
+
+// ExportDevice streams the full stored history of a device (every SMART submission with all of its
+// attribute properties, temperature points and performance results) as CSV or JSON.
+//
+// Query parameters:
+//   - format: csv (default) or json
+//   - duration: day, week, month, year or forever (default)
+//   - attributes: optional comma separated list of attribute ids to include
+//
+// Records are written to the response as they are read from the time-series backend, so the full
+// history is never buffered in memory. Once streaming has started the status code can no longer
+// change, so errors after that point are logged and the response is truncated.
+func ExportDevice(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	device, err := ResolveDevice(c, logger, deviceRepo)
+	if err != nil {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", exportFormatCSV))
+	if format != exportFormatCSV && format != exportFormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "format must be csv or json"})
+		return
+	}
+
+	durationKey := c.DefaultQuery("duration", database.DURATION_KEY_FOREVER)
+	if !exportDurationKeys[durationKey] {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": fmt.Sprintf("invalid duration: %s", durationKey)})
+		return
+	}
+
+	var attributeFilter map[string]bool
+	if attributes := c.Query("attributes"); attributes != "" {
+		attributeFilter = map[string]bool{}
+		for _, attributeID := range strings.Split(attributes, ",") {
+			if attributeID = strings.TrimSpace(attributeID); attributeID != "" {
+				attributeFilter[attributeID] = true
+			}
+		}
+	}
+
+	filename := fmt.Sprintf("scrutiny-%s-%s.%s", device.DeviceID, durationKey, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	var exporter deviceExporter
+	if format == exportFormatJSON {
+		c.Header("Content-Type", "application/json")
+		exporter = newJSONDeviceExporter(c.Writer)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		exporter = newCSVDeviceExporter(c.Writer)
+	}
+	c.Status(http.StatusOK)
+
+	if err := exportDeviceHistory(c, deviceRepo, device, durationKey, attributeFilter, exporter); err != nil {
+		logger.Errorf("Device export for %s was truncated: %v", device.DeviceID, err)
+	}
+}
+
+func exportDeviceHistory(c *gin.Context, deviceRepo database.DeviceRepo, device models.Device, durationKey string, attributeFilter map[string]bool, exporter deviceExporter) error {
+	wwn := device.WWN
+	if err := exporter.Begin(device); err != nil {
+		return err
+	}
+
+	if err := exporter.Section(exportRecordSmart); err != nil {
+		return err
+	}
+	err := deviceRepo.StreamSmartHistory(c, wwn, durationKey, func(smart measurements.Smart) error {
+		if attributeFilter != nil {
+			filtered := map[string]measurements.SmartAttribute{}
+			for attributeID, attr := range smart.Attributes {
+				if attributeFilter[attributeID] {
+					filtered[attributeID] = attr
+				}
+			}
+			smart.Attributes = filtered
+		}
+		return exporter.Smart(smart)
+	})
+	if err != nil {
+		return fmt.Errorf("smart history: %w", err)
+	}
+
+	if err := exporter.Section(exportRecordTemperature); err != nil {
+		return err
+	}
+	err = deviceRepo.StreamTemperatureHistory(c, wwn, durationKey, exporter.Temperature)
+	if err != nil {
+		return fmt.Errorf("temperature history: %w", err)
+	}
+
+	if err := exporter.Section(exportRecordPerformance); err != nil {
+		return err
+	}
+	err = deviceRepo.StreamPerformanceHistory(c, wwn, durationKey, exporter.Performance)
+	if err != nil {
+		return fmt.Errorf("performance history: %w", err)
+	}
+
+	return exporter.End()
+}
+
+// deviceExporter writes the records of a device export in a specific format. Section is called
+// before the records of each record type are written.
+type deviceExporter interface {
+	Begin(device models.Device) error
+	Section(recordType string) error
+	Smart(smart measurements.Smart) error
+	Temperature(temp measurements.SmartTemperature) error
+	Performance(perf measurements.Performance) error
+	End() error
+}
+
+// flushResponse flushes the underlying response after every record so data reaches the client
+// while the export is still running.
+func flushResponse(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// CSV
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// csvDeviceExporter writes a "long" CSV with one row per value, so submissions with different
+// attribute sets (or protocols) share a single fixed header.
+type csvDeviceExporter struct {
+	out    io.Writer
+	writer *csv.Writer
+}
+
+func newCSVDeviceExporter(out io.Writer) *csvDeviceExporter {
+	return &csvDeviceExporter{out: out, writer: csv.NewWriter(out)}
+}
+
+func (e *csvDeviceExporter) Begin(device models.Device) error {
+	return e.writer.Write([]string{"record_type", "date", "attribute_id", "field", "value"})
+}
+
+func (e *csvDeviceExporter) Section(recordType string) error {
+	return nil
+}
+
+func (e *csvDeviceExporter) Smart(smart measurements.Smart) error {
+	date := smart.Date.UTC().Format(time.RFC3339)
+	submissionFields := [][2]string{
+		{"temp", fmt.Sprint(smart.Temp)},
+		{"power_on_hours", fmt.Sprint(smart.PowerOnHours)},
+		{"power_cycle_count", fmt.Sprint(smart.PowerCycleCount)},
+		{"status", fmt.Sprint(int64(smart.Status))},
+	}
+	for _, field := range submissionFields {
+		if err := e.writer.Write([]string{exportRecordSmart, date, "", field[0], field[1]}); err != nil {
+			return err
+		}
+	}
+
+	attributeIDs := make([]string, 0, len(smart.Attributes))
+	for attributeID := range smart.Attributes {
+		attributeIDs = append(attributeIDs, attributeID)
+	}
+	sort.Strings(attributeIDs)
+
+	for _, attributeID := range attributeIDs {
+		// attribute fields are flattened as attr.<id>.<field>
+		prefix := "attr." + attributeID + "."
+		fields := smart.Attributes[attributeID].Flatten()
+		if err := e.writeFields(exportRecordAttribute, date, attributeID, fields, prefix); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+func (e *csvDeviceExporter) Temperature(temp measurements.SmartTemperature) error {
+	if err := e.writer.Write([]string{exportRecordTemperature, temp.Date.UTC().Format(time.RFC3339), "", "temp", fmt.Sprint(temp.Temp)}); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+func (e *csvDeviceExporter) Performance(perf measurements.Performance) error {
+	tags, fields := perf.Flatten()
+	fields["profile"] = tags["profile"]
+	if err := e.writeFields(exportRecordPerformance, perf.Date.UTC().Format(time.RFC3339), "", fields, ""); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+func (e *csvDeviceExporter) End() error {
+	return e.flush()
+}
+
+func (e *csvDeviceExporter) writeFields(recordType string, date string, attributeID string, fields map[string]interface{}, prefix string) error {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := fields[key]
+		if value == nil {
+			continue
+		}
+		if err := e.writer.Write([]string{recordType, date, attributeID, strings.TrimPrefix(key, prefix), fmt.Sprint(value)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *csvDeviceExporter) flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	flushResponse(e.out)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// JSON
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// jsonDeviceExporter writes the same envelope as GetDeviceDetails, with the temperature and
+// performance history alongside smart_results. The arrays are written element by element.
+type jsonDeviceExporter struct {
+	out     io.Writer
+	section string
+	count   int
+}
+
+func newJSONDeviceExporter(out io.Writer) *jsonDeviceExporter {
+	return &jsonDeviceExporter{out: out}
+}
+
+func (e *jsonDeviceExporter) Begin(device models.Device) error {
+	deviceJSON, err := json.Marshal(device)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.out, `{"success":true,"data":{"device":%s`, deviceJSON)
+	return err
+}
+
+func (e *jsonDeviceExporter) Section(recordType string) error {
+	closing := ""
+	if e.section != "" {
+		closing = "]"
+	}
+	names := map[string]string{
+		exportRecordSmart:       "smart_results",
+		exportRecordTemperature: "temperature",
+		exportRecordPerformance: "performance",
+	}
+	e.section = recordType
+	e.count = 0
+	_, err := fmt.Fprintf(e.out, `%s,"%s":[`, closing, names[recordType])
+	return err
+}
+
+func (e *jsonDeviceExporter) Smart(smart measurements.Smart) error {
+	return e.writeElement(smart)
+}
+
+func (e *jsonDeviceExporter) Temperature(temp measurements.SmartTemperature) error {
+	return e.writeElement(temp)
+}
+
+func (e *jsonDeviceExporter) Performance(perf measurements.Performance) error {
+	return e.writeElement(perf)
+}
+
+func (e *jsonDeviceExporter) End() error {
+	_, err := io.WriteString(e.out, "]}}")
+	flushResponse(e.out)
+	return err
+}
+
+func (e *jsonDeviceExporter) writeElement(element interface{}) error {
+	data, err := json.Marshal(element)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := io.WriteString(e.out, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	if _, err := e.out.Write(data); err != nil {
+		return err
+	}
+	flushResponse(e.out)
+	return nil
+}
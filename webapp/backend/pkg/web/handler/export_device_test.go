@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportDeviceTest(t *testing.T, durationKey string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	date := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fakeRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	fakeRepo.EXPECT().GetDeviceDetails(gomock.Any(), "device-1").Return(models.Device{DeviceID: "device-1", WWN: testDeviceWWN}, nil).AnyTimes()
+	fakeRepo.EXPECT().StreamSmartHistory(gomock.Any(), testDeviceWWN, durationKey, gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ string, _ string, fn func(measurements.Smart) error) error {
+			return fn(measurements.Smart{
+				Date:         date,
+				Temp:         35,
+				PowerOnHours: 1000,
+				Attributes: map[string]measurements.SmartAttribute{
+					"5":   &measurements.SmartAtaAttribute{AttributeId: 5, Value: 100, Threshold: 10, RawValue: 2, TransformedValue: 2},
+					"194": &measurements.SmartAtaAttribute{AttributeId: 194, Value: 65, RawValue: 35},
+				},
+			})
+		}).AnyTimes()
+	fakeRepo.EXPECT().StreamTemperatureHistory(gomock.Any(), testDeviceWWN, durationKey, gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ string, _ string, fn func(measurements.SmartTemperature) error) error {
+			return fn(measurements.SmartTemperature{Date: date, Temp: 36})
+		}).AnyTimes()
+	fakeRepo.EXPECT().StreamPerformanceHistory(gomock.Any(), testDeviceWWN, durationKey, gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ string, _ string, fn func(measurements.Performance) error) error {
+			return fn(measurements.Performance{Date: date, Profile: "quick", SeqReadBwBytes: 500})
+		}).AnyTimes()
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("DEVICE_REPOSITORY", fakeRepo)
+		c.Set("LOGGER", logrus.WithField("test", t.Name()))
+		c.Next()
+	})
+	r.GET("/api/device/:id/export", handler.ExportDevice)
+	return r
+}
+
+func TestExportDevice_CSV(t *testing.T) {
+	r := setupExportDeviceTest(t, database.DURATION_KEY_FOREVER)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/device/device-1/export?attributes=5", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Disposition"), "scrutiny-device-1-forever.csv")
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"record_type", "date", "attribute_id", "field", "value"}, records[0])
+	require.Contains(t, records, []string{"smart", "2026-10-01T12:00:00Z", "", "power_on_hours", "1000"})
+	require.Contains(t, records, []string{"attribute", "2026-10-01T12:00:00Z", "5", "thresh", "10"})
+	require.Contains(t, records, []string{"attribute", "2026-10-01T12:00:00Z", "5", "transformed_value", "2"})
+	require.Contains(t, records, []string{"temperature", "2026-10-01T12:00:00Z", "", "temp", "36"})
+	require.Contains(t, records, []string{"performance", "2026-10-01T12:00:00Z", "", "profile", "quick"})
+	for _, record := range records {
+		require.NotEqual(t, "194", record[2], "attribute filter should exclude attribute 194")
+	}
+}
+
+func TestExportDevice_JSON(t *testing.T) {
+	r := setupExportDeviceTest(t, database.DURATION_KEY_WEEK)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/device/device-1/export?format=json&duration=week", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Device       models.Device                   `json:"device"`
+			SmartResults []map[string]interface{}        `json:"smart_results"`
+			Temperature  []measurements.SmartTemperature `json:"temperature"`
+			Performance  []measurements.Performance      `json:"performance"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Equal(t, "device-1", response.Data.Device.DeviceID)
+	require.Len(t, response.Data.SmartResults, 1)
+	require.Len(t, response.Data.SmartResults[0]["attrs"], 2)
+	require.Equal(t, int64(36), response.Data.Temperature[0].Temp)
+	require.Equal(t, "quick", response.Data.Performance[0].Profile)
+}
+
+func TestExportDevice_RejectsInvalidParameters(t *testing.T) {
+	r := setupExportDeviceTest(t, database.DURATION_KEY_FOREVER)
+
+	for _, query := range []string{"format=xml", "duration=decade"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/device/device-1/export?"+query, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -20,11 +21,37 @@ func UploadFilesystemSummary(c *gin.Context) {
 		return
 	}
 
+	// the previous mount points are only needed to remove filesystems no longer reported from MQTT
+	var previous map[string][]models.FilesystemCapacity
+	if mqttPublisher(c) != nil {
+		if filesystems, _, err := deviceRepo.GetFilesystemSummary(c); err == nil {
+			previous = filesystems
+		}
+	}
+
 	if err := deviceRepo.SaveFilesystemSummary(c, payload); err != nil {
 		logger.Errorln("An error occurred while saving filesystem summary", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
 
+	hostIDs := make([]string, 0, len(payload.Hosts)+len(payload.Filesystems))
+	for _, host := range payload.Hosts {
+		hostIDs = append(hostIDs, host.HostID)
+	}
+	for _, filesystem := range payload.Filesystems {
+		hostIDs = append(hostIDs, filesystem.HostID)
+	}
+	recordHostCheckIns(c, logger, deviceRepo, models.CollectorTypeFilesystem, hostIDs...)
+	publishMqttFilesystems(c, previous, &payload)
+
+	if collectorVal, exists := c.Get("METRICS_COLLECTOR"); exists {
+		if collector, ok := collectorVal.(*metrics.Collector); ok && collector != nil {
+			if err := collector.RefreshFilesystemMetrics(deviceRepo, c); err != nil {
+				logger.Warnf("Failed to refresh Prometheus filesystem metrics: %v", err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
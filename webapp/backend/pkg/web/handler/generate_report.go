@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/reports"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -11,9 +12,9 @@ import (
 
 // ReportScheduler interface to avoid import cycle with web package
 type ReportScheduler interface {
-	GenerateOnDemand(ctx context.Context, periodType string) (*reports.ReportData, error)
-	GenerateOnDemandPDF(ctx context.Context, periodType string) (string, error)
-	SendTestReport(ctx context.Context, periodType string) (*reports.ReportData, error)
+	GenerateOnDemand(ctx context.Context, periodType string, tags models.TagFilter) (*reports.ReportData, error)
+	GenerateOnDemandPDF(ctx context.Context, periodType string, tags models.TagFilter) (string, error)
+	SendTestReport(ctx context.Context, periodType string, tags models.TagFilter) (*reports.ReportData, error)
 }
 
 func GenerateReport(c *gin.Context) {
@@ -22,6 +23,7 @@ func GenerateReport(c *gin.Context) {
 
 	format := c.DefaultQuery("format", "text")
 	period := c.DefaultQuery("period", "daily")
+	tags := tagFilterFromQuery(c)
 
 	if period != "daily" && period != "weekly" && period != "monthly" {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid period: must be daily, weekly, or monthly"})
@@ -29,7 +31,7 @@ func GenerateReport(c *gin.Context) {
 	}
 
 	if format == "pdf" {
-		pdfPath, err := scheduler.GenerateOnDemandPDF(c.Request.Context(), period)
+		pdfPath, err := scheduler.GenerateOnDemandPDF(c.Request.Context(), period, tags)
 		if err != nil {
 			logger.Errorf("Failed to generate PDF report: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
@@ -45,9 +47,9 @@ func GenerateReport(c *gin.Context) {
 	var err error
 	if sendNotification {
 		logger.Info("Test report requested, generating and sending via notification system")
-		report, err = scheduler.SendTestReport(c.Request.Context(), period)
+		report, err = scheduler.SendTestReport(c.Request.Context(), period, tags)
 	} else {
-		report, err = scheduler.GenerateOnDemand(c.Request.Context(), period)
+		report, err = scheduler.GenerateOnDemand(c.Request.Context(), period, tags)
 	}
 	if err != nil {
 		logger.Errorf("Failed to generate report: %v", err)
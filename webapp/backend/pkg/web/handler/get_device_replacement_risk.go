@@ -101,6 +101,7 @@ func GetDeviceReplacementRisk(c *gin.Context) {
 		ComputedAt:                   time.Now().UTC(),
 		ConsumerDriveProfilesEnabled: profilesEnabled,
 		ConsumerDriveProfileApplied:  profile != nil,
+		Warranty:                     device.WarrantyStatus(time.Now(), warrantyNotifyDays(appConfig)),
 	}
 	if profile != nil {
 		riskScore.ConsumerDriveProfileFamily = profile.ModelFamily
@@ -127,6 +128,16 @@ func consumerDriveProfilesEnabled(cfg config.Interface) bool {
 	return cfg.GetBool(key)
 }
 
+// warrantyNotifyDays returns the number of days before expiry at which a warranty is reported as
+// expiring, matching the warranty expiry notification setting.
+func warrantyNotifyDays(cfg config.Interface) int {
+	key := config.DB_USER_SETTINGS_SUBKEY + ".metrics.warranty_notify_days"
+	if cfg == nil || !cfg.IsSet(key) || cfg.GetInt(key) <= 0 {
+		return 30
+	}
+	return cfg.GetInt(key)
+}
+
 // consumerDriveProfileDenylist returns the set of normalized family keys the
 // operator has excluded from profile matching via settings.
 func consumerDriveProfileDenylist(cfg config.Interface) map[string]struct{} {
@@ -18,6 +18,14 @@ func GetDevicesSummary(c *gin.Context) {
 		return
 	}
 
+	if tagFilter := tagFilterFromQuery(c); len(tagFilter) > 0 {
+		for deviceID, deviceSummary := range summary {
+			if !tagFilter.Matches(deviceSummary.Device.Tags) {
+				delete(summary, deviceID)
+			}
+		}
+	}
+
 	//this must match DeviceSummaryWrapper (webapp/backend/pkg/models/device_summary.go)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
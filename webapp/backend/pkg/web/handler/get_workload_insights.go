@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -21,6 +22,20 @@ func GetWorkloadInsights(c *gin.Context) {
 		return
 	}
 
+	if tagFilter := tagFilterFromQuery(c); len(tagFilter) > 0 {
+		deviceTags, err := deviceRepo.GetResourceTags(c, models.TagResourceDevice)
+		if err != nil {
+			logger.Errorln("An error occurred while retrieving device tags", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+			return
+		}
+		for deviceID := range workload {
+			if !tagFilter.Matches(deviceTags[deviceID]) {
+				delete(workload, deviceID)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": map[string]interface{}{
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// recordHostCheckIns records a check-in of collectorType for every distinct host ID, using the
+// collector metadata headers of the request. Failures are logged but never fail the request.
+func recordHostCheckIns(c *gin.Context, logger *logrus.Entry, deviceRepo database.DeviceRepo, collectorType string, hostIDs ...string) {
+	info := models.HostCollectorInfo{
+		CollectorVersion: c.GetHeader(pkg.HeaderCollectorVersion),
+		OSInfo:           c.GetHeader(pkg.HeaderHostOS),
+		Architecture:     c.GetHeader(pkg.HeaderHostArch),
+	}
+	seen := map[string]bool{}
+	for _, hostID := range hostIDs {
+		if hostID == "" || seen[hostID] {
+			continue
+		}
+		seen[hostID] = true
+		if err := deviceRepo.RecordHostCheckIn(c, hostID, collectorType, info); err != nil {
+			logger.Warnf("Failed to record %s check-in for host %s: %v", collectorType, hostID, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type renameHostRequest struct {
+	NewHostID string `json:"new_host_id"`
+}
+
+// GetHosts returns every host with the aggregated status of its devices, pools and filesystems.
+func GetHosts(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	summaries, err := deviceRepo.GetHostSummaries(c)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving hosts", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": summaries})
+}
+
+// GetHost returns a single host with its collector check-ins.
+func GetHost(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	host, err := deviceRepo.GetHost(c, c.Param("host_id"))
+	if err != nil {
+		respondHostError(c, logger, "retrieving host", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": host})
+}
+
+// UpdateHost sets the label, description and missed ping timeout of a host.
+func UpdateHost(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	var update models.HostUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		logger.Warnf("Invalid host update body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	if update.MissedPingTimeoutMinutes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "missed_ping_timeout_minutes must be >= 0"})
+		return
+	}
+
+	if err := deviceRepo.UpdateHost(c, c.Param("host_id"), update); err != nil {
+		respondHostError(c, logger, "updating host", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// MuteHost mutes a host and everything reported for it.
+func MuteHost(c *gin.Context) {
+	setHostFlag(c, "muting host", func(deviceRepo database.DeviceRepo, ctx context.Context, hostID string) error {
+		return deviceRepo.UpdateHostMuted(ctx, hostID, true)
+	})
+}
+
+// UnmuteHost unmutes a host and everything reported for it.
+func UnmuteHost(c *gin.Context) {
+	setHostFlag(c, "unmuting host", func(deviceRepo database.DeviceRepo, ctx context.Context, hostID string) error {
+		return deviceRepo.UpdateHostMuted(ctx, hostID, false)
+	})
+}
+
+// ArchiveHost archives a host and everything reported for it.
+func ArchiveHost(c *gin.Context) {
+	setHostFlag(c, "archiving host", func(deviceRepo database.DeviceRepo, ctx context.Context, hostID string) error {
+		return deviceRepo.UpdateHostArchived(ctx, hostID, true)
+	})
+}
+
+// UnarchiveHost unarchives a host and everything reported for it.
+func UnarchiveHost(c *gin.Context) {
+	setHostFlag(c, "unarchiving host", func(deviceRepo database.DeviceRepo, ctx context.Context, hostID string) error {
+		return deviceRepo.UpdateHostArchived(ctx, hostID, false)
+	})
+}
+
+// RenameHost changes a host's ID, updating every device, pool and filesystem that references it.
+func RenameHost(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	var request renameHostRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warnf("Invalid host rename body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	newHostID := strings.TrimSpace(request.NewHostID)
+	if newHostID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "new_host_id is required"})
+		return
+	}
+
+	if err := deviceRepo.RenameHost(c, c.Param("host_id"), newHostID); err != nil {
+		if errors.Is(err, database.ErrHostNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		logger.Errorln("An error occurred while renaming host", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func setHostFlag(c *gin.Context, action string, update func(deviceRepo database.DeviceRepo, ctx context.Context, hostID string) error) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	if err := update(deviceRepo, c, c.Param("host_id")); err != nil {
+		respondHostError(c, logger, action, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func respondHostError(c *gin.Context, logger *logrus.Entry, action string, err error) {
+	if errors.Is(err, database.ErrHostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	logger.Errorf("An error occurred while %s: %v", action, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHostsRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/hosts", handler.GetHosts)
+	r.POST("/api/host/:host_id", handler.UpdateHost)
+	r.POST("/api/host/:host_id/mute", handler.MuteHost)
+	r.POST("/api/host/:host_id/rename", handler.RenameHost)
+	return r
+}
+
+func TestGetHosts_ReturnsSummaries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetHostSummaries(gomock.Any()).Return([]models.HostSummary{{
+		Host:    models.Host{HostID: "nas", Label: "Basement NAS"},
+		Devices: models.HostStatusCounts{Total: 2, Passed: 1, Failed: 1},
+	}}, nil)
+
+	router := setupHostsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/hosts", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool                 `json:"success"`
+		Data    []models.HostSummary `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Len(t, response.Data, 1)
+	require.Equal(t, "nas", response.Data[0].HostID)
+	require.Equal(t, 1, response.Data[0].Devices.Failed)
+}
+
+func TestUpdateHost_RejectsNegativeTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+
+	router := setupHostsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/host/nas", strings.NewReader(`{"missed_ping_timeout_minutes":-5}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMuteHost_NotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().UpdateHostMuted(gomock.Any(), "missing", true).Return(database.ErrHostNotFound)
+
+	router := setupHostsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/host/missing/mute", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRenameHost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().RenameHost(gomock.Any(), "nas", "nas-01").Return(nil)
+	mockRepo.EXPECT().RenameHost(gomock.Any(), "nas", "taken").Return(fmt.Errorf(`host "taken" already exists`))
+
+	router := setupHostsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/host/nas/rename", strings.NewReader(`{"new_host_id":" nas-01 "}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/host/nas/rename", strings.NewReader(`{"new_host_id":"taken"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "already exists")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/host/nas/rename", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
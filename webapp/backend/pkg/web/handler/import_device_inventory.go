@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const maxInventoryImportBytes = 5 << 20
+
+// inventoryImportColumns maps accepted CSV header names to their canonical inventory column.
+var inventoryImportColumns = map[string]string{
+	"serial_number":       "serial_number",
+	"serial":              "serial_number",
+	"purchase_date":       "purchase_date",
+	"warranty_expires_at": "warranty_expires_at",
+	"warranty_expiry":     "warranty_expires_at",
+	"purchase_price":      "purchase_price",
+	"price":               "purchase_price",
+	"vendor":              "vendor",
+	"rma_contact":         "rma_contact",
+	"location":            "location",
+}
+
+type inventoryImportRow struct {
+	line   int
+	serial string
+	values map[string]string
+}
+
+type inventoryImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type inventoryImportResult struct {
+	Updated   []string               `json:"updated"`
+	Unmatched []string               `json:"unmatched"`
+	Errors    []inventoryImportError `json:"errors"`
+}
+
+// ImportDeviceInventory updates device inventory fields in bulk from a CSV file, matching rows to
+// devices by serial number. The CSV is sent either as the "file" field of a multipart form or as
+// the raw request body.
+//
+// The header row must contain serial_number plus any of purchase_date, warranty_expires_at,
+// purchase_price, vendor, rma_contact and location. Only the columns present in the file are
+// changed; an empty cell clears the field. The file is validated completely before any device is
+// updated, so a file with errors changes nothing.
+func ImportDeviceInventory(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInventoryImportBytes)
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "missing CSV file (form field \"file\")"})
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "could not read uploaded file"})
+			return
+		}
+		defer opened.Close()
+		reader = opened
+	}
+
+	rows, rowErrors, err := parseInventoryCSV(reader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	devices, err := deviceRepo.GetDevices(c)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving devices for inventory import", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+	devicesBySerial := map[string][]models.Device{}
+	for _, device := range devices {
+		serial := strings.ToLower(strings.TrimSpace(device.SerialNumber))
+		if serial != "" {
+			devicesBySerial[serial] = append(devicesBySerial[serial], device)
+		}
+	}
+
+	type pendingUpdate struct {
+		deviceID  string
+		inventory models.DeviceInventory
+	}
+	result := inventoryImportResult{Updated: []string{}, Unmatched: []string{}, Errors: rowErrors}
+	updates := []pendingUpdate{}
+	for _, row := range rows {
+		matched := devicesBySerial[strings.ToLower(row.serial)]
+		if len(matched) == 0 {
+			result.Unmatched = append(result.Unmatched, row.serial)
+			continue
+		}
+		for _, device := range matched {
+			inventory, err := applyInventoryImportRow(device.DeviceInventory, row.values)
+			if err != nil {
+				result.Errors = append(result.Errors, inventoryImportError{Line: row.line, Error: err.Error()})
+				break
+			}
+			updates = append(updates, pendingUpdate{deviceID: device.DeviceID, inventory: inventory})
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "inventory CSV contains invalid rows", "data": result})
+		return
+	}
+
+	for _, update := range updates {
+		if err := deviceRepo.UpdateDeviceInventory(c, update.deviceID, update.inventory); err != nil {
+			logger.Errorf("An error occurred while importing inventory for device %s: %v", update.deviceID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "data": result})
+			return
+		}
+		result.Updated = append(result.Updated, update.deviceID)
+	}
+
+	logger.Infof("Imported inventory for %d device(s), %d unmatched serial number(s)", len(result.Updated), len(result.Unmatched))
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": result})
+}
+
+// parseInventoryCSV reads the header and rows of an inventory CSV. Structural problems (missing or
+// unknown columns) are returned as an error, per-row problems as inventoryImportErrors.
+func parseInventoryCSV(reader io.Reader) ([]inventoryImportRow, []inventoryImportError, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("inventory CSV is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid inventory CSV: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasSerial := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		column, ok := inventoryImportColumns[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown inventory CSV column %q", name)
+		}
+		columns[i] = column
+		hasSerial = hasSerial || column == "serial_number"
+	}
+	if !hasSerial {
+		return nil, nil, fmt.Errorf("inventory CSV must contain a serial_number column")
+	}
+
+	rows := []inventoryImportRow{}
+	rowErrors := []inventoryImportError{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid inventory CSV: %w", err)
+		}
+
+		row := inventoryImportRow{line: line, values: map[string]string{}}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if columns[i] == "serial_number" {
+				row.serial = value
+				continue
+			}
+			row.values[columns[i]] = value
+		}
+		if row.serial == "" {
+			rowErrors = append(rowErrors, inventoryImportError{Line: line, Error: "serial_number is empty"})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// applyInventoryImportRow returns inventory with the columns present in values replaced.
+func applyInventoryImportRow(inventory models.DeviceInventory, values map[string]string) (models.DeviceInventory, error) {
+	for column, value := range values {
+		switch column {
+		case "purchase_date", "warranty_expires_at":
+			date, err := parseInventoryDate(value)
+			if err != nil {
+				return inventory, fmt.Errorf("%s: %w", column, err)
+			}
+			if column == "purchase_date" {
+				inventory.PurchaseDate = date
+			} else {
+				inventory.WarrantyExpiresAt = date
+			}
+		case "purchase_price":
+			if value == "" {
+				inventory.PurchasePrice = nil
+				continue
+			}
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil || price < 0 {
+				return inventory, fmt.Errorf("purchase_price: invalid price %q", value)
+			}
+			inventory.PurchasePrice = &price
+		case "vendor":
+			inventory.Vendor = value
+		case "rma_contact":
+			inventory.RMAContact = value
+		case "location":
+			inventory.Location = value
+		}
+	}
+	return inventory, nil
+}
@@ -0,0 +1,105 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInventoryImportTest(t *testing.T, fakeRepo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("DEVICE_REPOSITORY", fakeRepo)
+		c.Set("LOGGER", logrus.WithField("test", t.Name()))
+		c.Next()
+	})
+	r.POST("/api/devices/inventory/import", handler.ImportDeviceInventory)
+	return r
+}
+
+func TestImportDeviceInventory_UpdatesPresentColumnsBySerial(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fakeRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	fakeRepo.EXPECT().GetDevices(gomock.Any()).Return([]models.Device{
+		{DeviceID: "device-1", SerialNumber: "S1", DeviceInventory: models.DeviceInventory{Vendor: "Acme", Location: "old shelf"}},
+		{DeviceID: "device-2", SerialNumber: "S2"},
+	}, nil)
+
+	expiry := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	fakeRepo.EXPECT().UpdateDeviceInventory(gomock.Any(), "device-1", gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ string, inventory models.DeviceInventory) error {
+			require.Equal(t, "Acme", inventory.Vendor, "columns missing from the CSV are kept")
+			require.Equal(t, "Rack 1", inventory.Location)
+			require.True(t, expiry.Equal(*inventory.WarrantyExpiresAt))
+			require.Equal(t, 99.5, *inventory.PurchasePrice)
+			return nil
+		})
+
+	r := setupInventoryImportTest(t, fakeRepo)
+	body := "Serial_Number,warranty_expires_at,price,location\ns1,2027-03-01,99.5,Rack 1\nUNKNOWN,,,\n"
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/devices/inventory/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Updated   []string `json:"updated"`
+			Unmatched []string `json:"unmatched"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, []string{"device-1"}, response.Data.Updated)
+	require.Equal(t, []string{"UNKNOWN"}, response.Data.Unmatched)
+}
+
+func TestImportDeviceInventory_RejectsInvalidRowsWithoutUpdating(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fakeRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	fakeRepo.EXPECT().GetDevices(gomock.Any()).Return([]models.Device{
+		{DeviceID: "device-1", SerialNumber: "S1"},
+		{DeviceID: "device-2", SerialNumber: "S2"},
+	}, nil)
+
+	r := setupInventoryImportTest(t, fakeRepo)
+	body := "serial_number,purchase_date\nS1,2025-01-01\nS2,01/02/2025\n"
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/devices/inventory/import", strings.NewReader(body))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"line":3`)
+}
+
+func TestImportDeviceInventory_RejectsUnknownColumns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	r := setupInventoryImportTest(t, mock_database.NewMockDeviceRepo(mockCtrl))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/devices/inventory/import", strings.NewReader("serial_number,colour\nS1,red\n"))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "colour")
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetIncidents lists incidents, newest first, optionally filtered by ?status=open|acknowledged|resolved.
+func GetIncidents(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !models.ValidIncidentStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "status must be one of open, acknowledged or resolved"})
+		return
+	}
+
+	incidents, err := deviceRepo.GetIncidents(c, status)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving incidents", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": incidents})
+}
+
+// GetIncident returns a single incident by ID.
+func GetIncident(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	incident, err := deviceRepo.GetIncident(c, id)
+	if err != nil {
+		respondIncidentError(c, logger, "retrieving incident", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": incident})
+}
+
+// AcknowledgeIncident acknowledges an open incident, which stops its notifications and escalation
+// until the device recovers.
+func AcknowledgeIncident(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// the body is optional
+	var input struct {
+		By   string `json:"by"`
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body"})
+			return
+		}
+	}
+
+	incident, err := deviceRepo.AcknowledgeIncident(c, id, strings.TrimSpace(input.By), strings.TrimSpace(input.Note), time.Now().UTC())
+	if err != nil {
+		respondIncidentError(c, logger, "acknowledging incident", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": incident})
+}
+
+// ResolveIncident manually resolves an incident. Incidents are also resolved automatically when
+// the device status returns to passed.
+func ResolveIncident(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	incident, err := deviceRepo.ResolveIncident(c, id, time.Now().UTC())
+	if err != nil {
+		respondIncidentError(c, logger, "resolving incident", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": incident})
+}
+
+func respondIncidentError(c *gin.Context, logger *logrus.Entry, action string, err error) {
+	switch {
+	case errors.Is(err, database.ErrIncidentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, database.ErrIncidentResolved):
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+	default:
+		logger.Errorf("An error occurred while %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+	}
+}
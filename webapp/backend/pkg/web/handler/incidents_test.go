@@ -0,0 +1,108 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIncidentsRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/incidents", handler.GetIncidents)
+	r.GET("/api/incident/:id", handler.GetIncident)
+	r.POST("/api/incident/:id/ack", handler.AcknowledgeIncident)
+	r.POST("/api/incident/:id/resolve", handler.ResolveIncident)
+	return r
+}
+
+func TestGetIncidents_FiltersByStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetIncidents(gomock.Any(), models.IncidentStatusOpen).Return([]models.Incident{
+		{ID: 1, DeviceID: "sda", Status: models.IncidentStatusOpen},
+	}, nil)
+
+	router := setupIncidentsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/incidents?status=Open", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool              `json:"success"`
+		Data    []models.Incident `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/incidents?status=closed", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAcknowledgeIncident_PassesDetails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().AcknowledgeIncident(gomock.Any(), uint(3), "alice", "replacement ordered", gomock.Any()).
+		Return(models.Incident{ID: 3, Status: models.IncidentStatusAcknowledged, AcknowledgedBy: "alice"}, nil)
+
+	router := setupIncidentsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/incident/3/ack", strings.NewReader(`{"by":" alice ","note":"replacement ordered"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool            `json:"success"`
+		Data    models.Incident `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, models.IncidentStatusAcknowledged, response.Data.Status)
+}
+
+func TestAcknowledgeIncident_Errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().AcknowledgeIncident(gomock.Any(), uint(4), "", "", gomock.Any()).Return(models.Incident{}, database.ErrIncidentNotFound)
+	mockRepo.EXPECT().AcknowledgeIncident(gomock.Any(), uint(5), "", "", gomock.Any()).Return(models.Incident{}, database.ErrIncidentResolved)
+
+	router := setupIncidentsRouter(t, mockRepo)
+
+	for path, code := range map[string]int{
+		"/api/incident/4/ack":   http.StatusNotFound,
+		"/api/incident/5/ack":   http.StatusConflict,
+		"/api/incident/abc/ack": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, code, w.Code, path)
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetMaintenanceWindows lists maintenance windows, newest first. ?active=true returns only the
+// windows suppressing notifications right now.
+func GetMaintenanceWindows(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	var windows []models.MaintenanceWindow
+	var err error
+	if active, _ := strconv.ParseBool(c.Query("active")); active {
+		windows, err = deviceRepo.GetActiveMaintenanceWindows(c, time.Now().UTC())
+	} else {
+		windows, err = deviceRepo.GetMaintenanceWindows(c)
+	}
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving maintenance windows", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": windows})
+}
+
+// GetMaintenanceWindow returns a single maintenance window by ID.
+func GetMaintenanceWindow(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	window, err := deviceRepo.GetMaintenanceWindow(c, id)
+	if err != nil {
+		respondMaintenanceWindowError(c, logger, "retrieving maintenance window", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": window})
+}
+
+// CreateMaintenanceWindow creates a one-off or recurring maintenance window. The window starts
+// now unless starts_at is given, and ends at ends_at or after duration_minutes.
+func CreateMaintenanceWindow(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	var input struct {
+		Name            string     `json:"name"`
+		ScopeType       string     `json:"scope_type"`
+		ScopeID         string     `json:"scope_id"`
+		StartsAt        *time.Time `json:"starts_at"`
+		EndsAt          *time.Time `json:"ends_at"`
+		DurationMinutes int        `json:"duration_minutes"`
+		Recurrence      string     `json:"recurrence"`
+		CreatedBy       string     `json:"created_by"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body"})
+		return
+	}
+
+	window := models.MaintenanceWindow{
+		Name:       strings.TrimSpace(input.Name),
+		ScopeType:  strings.ToLower(strings.TrimSpace(input.ScopeType)),
+		ScopeID:    strings.TrimSpace(input.ScopeID),
+		Recurrence: strings.ToLower(strings.TrimSpace(input.Recurrence)),
+		CreatedBy:  strings.TrimSpace(input.CreatedBy),
+		StartsAt:   time.Now().UTC(),
+	}
+	if input.StartsAt != nil {
+		window.StartsAt = input.StartsAt.UTC()
+	}
+	switch {
+	case input.EndsAt != nil:
+		window.EndsAt = input.EndsAt.UTC()
+	case input.DurationMinutes > 0:
+		window.EndsAt = window.StartsAt.Add(time.Duration(input.DurationMinutes) * time.Minute)
+	}
+
+	if msg := validateMaintenanceWindow(window); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": msg})
+		return
+	}
+
+	if err := deviceRepo.CreateMaintenanceWindow(c, &window); err != nil {
+		logger.Errorln("An error occurred while creating maintenance window", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": window})
+}
+
+func validateMaintenanceWindow(window models.MaintenanceWindow) string {
+	if !models.ValidMaintenanceScope(window.ScopeType) {
+		return "scope_type must be one of host, device, pool or array"
+	}
+	if window.ScopeID == "" {
+		return "scope_id is required"
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		return "ends_at (or duration_minutes) must be after starts_at"
+	}
+	period, ok := models.MaintenanceRecurrencePeriod(window.Recurrence)
+	if !ok {
+		return "recurrence must be empty, daily or weekly"
+	}
+	if period > 0 && window.EndsAt.Sub(window.StartsAt) >= period {
+		return "a recurring window must be shorter than its recurrence"
+	}
+	return ""
+}
+
+// EndMaintenanceWindow ends a maintenance window now. When the window is ended while active and
+// suppressed notifications, a summary of them is sent. For recurring windows the summary only
+// covers the current occurrence.
+func EndMaintenanceWindow(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+	appConfig := c.MustGet("CONFIG").(config.Interface)
+
+	id, ok := uintIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	window, err := deviceRepo.GetMaintenanceWindow(c, id)
+	if err != nil {
+		respondMaintenanceWindowError(c, logger, "retrieving maintenance window", err)
+		return
+	}
+	endedEarly := window.ActiveAt(now)
+
+	window, err = deviceRepo.EndMaintenanceWindow(c, id, now)
+	if err != nil {
+		respondMaintenanceWindowError(c, logger, "ending maintenance window", err)
+		return
+	}
+
+	suppressed := []models.NotificationRecord{}
+	if endedEarly {
+		occurrenceStart := window.OccurrenceStart(now)
+		suppressed, err = deviceRepo.GetNotificationHistory(c, models.NotificationHistoryFilter{
+			MaintenanceWindowID: window.ID,
+			Outcome:             models.NotificationOutcomeSuppressed,
+			Since:               &occurrenceStart,
+			Limit:               1000,
+		})
+		if err != nil {
+			logger.Warnf("Failed to load notifications suppressed by maintenance window %d: %v", window.ID, err)
+		}
+		if len(suppressed) > 0 {
+			sendMaintenanceSummary(c, logger, appConfig, deviceRepo, window, suppressed)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": window, "suppressed": len(suppressed)})
+}
+
+// sendMaintenanceSummary sends the suppressed-notification summary of a window, through the gate
+// when one is available so quiet hours and rate limits still apply.
+func sendMaintenanceSummary(c *gin.Context, logger *logrus.Entry, appConfig config.Interface, deviceRepo database.DeviceRepo, window models.MaintenanceWindow, suppressed []models.NotificationRecord) {
+	summary := notify.NewMaintenanceSummary(logger, appConfig, window, suppressed)
+	summary.LoadDatabaseUrls(c, deviceRepo)
+	if gateVal, exists := c.Get("NOTIFICATION_GATE"); exists {
+		if gate, ok := gateVal.(*notify.NotificationGate); ok {
+			settings, settingsErr := deviceRepo.LoadSettings(c)
+			if settingsErr != nil {
+				logger.Warnf("Failed to load settings for notification gate: %v", settingsErr)
+			}
+			if settings != nil {
+				gate.TrySend(&summary, settings, false)
+				return
+			}
+		}
+	}
+	if err := summary.Send(); err != nil {
+		logger.Warnf("Failed to send summary for maintenance window %d: %v", window.ID, err)
+	}
+}
+
+func respondMaintenanceWindowError(c *gin.Context, logger *logrus.Entry, action string, err error) {
+	switch {
+	case errors.Is(err, database.ErrMaintenanceWindowNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, database.ErrMaintenanceWindowEnded):
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+	default:
+		logger.Errorf("An error occurred while %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+	}
+}
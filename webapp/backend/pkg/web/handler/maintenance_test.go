@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMaintenanceRouter(t *testing.T, mockCtrl *gomock.Controller, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+	cfg := mock_config.NewMockInterface(mockCtrl)
+	cfg.EXPECT().GetStringSlice("notify.urls").Return([]string{}).AnyTimes()
+	cfg.EXPECT().GetString("notify.urls").Return("").AnyTimes()
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Set("CONFIG", cfg)
+		c.Next()
+	})
+	r.POST("/api/maintenance-windows", handler.CreateMaintenanceWindow)
+	r.POST("/api/maintenance-window/:id/end", handler.EndMaintenanceWindow)
+	return r
+}
+
+func TestCreateMaintenanceWindow_Validates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	router := setupMaintenanceRouter(t, mockCtrl, mockRepo)
+
+	for _, body := range []string{
+		`{"scope_type":"rack","scope_id":"a","duration_minutes":10}`,
+		`{"scope_type":"host","duration_minutes":10}`,
+		`{"scope_type":"host","scope_id":"nas"}`,
+		`{"scope_type":"host","scope_id":"nas","duration_minutes":10,"recurrence":"monthly"}`,
+		`{"scope_type":"host","scope_id":"nas","duration_minutes":1500,"recurrence":"daily"}`,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/maintenance-windows", bytes.NewBufferString(body))
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestCreateMaintenanceWindow_Duration(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().CreateMaintenanceWindow(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, window *models.MaintenanceWindow) error {
+			require.Equal(t, models.MaintenanceScopePool, window.ScopeType)
+			require.Equal(t, "tank", window.ScopeID)
+			require.Equal(t, 90*time.Minute, window.EndsAt.Sub(window.StartsAt))
+			window.ID = 4
+			return nil
+		})
+	router := setupMaintenanceRouter(t, mockCtrl, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/maintenance-windows", bytes.NewBufferString(`{"name":"resilver","scope_type":"Pool","scope_id":"tank","duration_minutes":90}`))
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEndMaintenanceWindow_SendsSummaryWhenEndedEarly(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+
+	// a nightly window that started a week ago, in its current occurrence since an hour ago
+	occurrenceStart := time.Now().UTC().Add(-time.Hour)
+	window := models.MaintenanceWindow{ID: 4, ScopeType: models.MaintenanceScopeHost, ScopeID: "nas",
+		StartsAt: occurrenceStart.Add(-7 * 24 * time.Hour), EndsAt: occurrenceStart.Add(-7*24*time.Hour + 2*time.Hour), Recurrence: models.MaintenanceRecurrenceDaily}
+	mockRepo.EXPECT().GetMaintenanceWindow(gomock.Any(), uint(4)).Return(window, nil)
+	mockRepo.EXPECT().EndMaintenanceWindow(gomock.Any(), uint(4), gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ uint, at time.Time) (models.MaintenanceWindow, error) {
+			window.EndedAt = &at
+			return window, nil
+		})
+	mockRepo.EXPECT().GetNotificationHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, filter models.NotificationHistoryFilter) ([]models.NotificationRecord, error) {
+			require.Equal(t, uint(4), filter.MaintenanceWindowID)
+			require.NotNil(t, filter.Since)
+			require.WithinDuration(t, occurrenceStart, *filter.Since, time.Second, "past occurrences are not summarized")
+			return []models.NotificationRecord{{FailureType: "SmartFailure", Subject: "sda failed"}}, nil
+		})
+	// the summary is loaded with the UI URLs and sent; with no URLs configured the failed attempt is recorded
+	mockRepo.EXPECT().GetNotifyUrls(gomock.Any()).Return([]models.NotifyUrl{}, nil)
+	mockRepo.EXPECT().SaveNotificationRecord(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, record *models.NotificationRecord) error {
+			require.Equal(t, "MaintenanceSummary", record.FailureType)
+			return nil
+		})
+	router := setupMaintenanceRouter(t, mockCtrl, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/maintenance-window/4/end", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success    bool                     `json:"success"`
+		Data       models.MaintenanceWindow `json:"data"`
+		Suppressed int                      `json:"suppressed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 1, response.Suppressed)
+	require.NotNil(t, response.Data.EndedAt)
+}
+
+func TestEndMaintenanceWindow_AlreadyEnded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	ended := time.Now().Add(-time.Minute)
+	mockRepo.EXPECT().GetMaintenanceWindow(gomock.Any(), uint(4)).Return(models.MaintenanceWindow{ID: 4, EndedAt: &ended}, nil)
+	mockRepo.EXPECT().EndMaintenanceWindow(gomock.Any(), uint(4), gomock.Any()).Return(models.MaintenanceWindow{}, database.ErrMaintenanceWindowEnded)
+	router := setupMaintenanceRouter(t, mockCtrl, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/maintenance-window/4/end", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+}
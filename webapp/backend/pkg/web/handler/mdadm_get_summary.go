@@ -29,6 +29,7 @@ func GetMdadmSummary(c *gin.Context) {
 		Archived bool     `json:"archived"`
 		Muted    bool     `json:"muted"`
 		HostID   string   `json:"host_id,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
 
 		// Latest metrics (populated from InfluxDB)
 		State        string  `json:"state,omitempty"`
@@ -37,8 +38,12 @@ func GetMdadmSummary(c *gin.Context) {
 		UsedBytes    int64   `json:"used_bytes,omitempty"`
 	}
 
+	tagFilter := tagFilterFromQuery(c)
 	summaries := make([]ArraySummary, 0, len(arrays))
 	for _, array := range arrays {
+		if !tagFilter.Matches(array.Tags) {
+			continue
+		}
 		devices := array.Devices
 		if devices == nil {
 			devices = []string{}
@@ -52,6 +57,7 @@ func GetMdadmSummary(c *gin.Context) {
 			Archived: array.Archived,
 			Muted:    array.Muted,
 			HostID:   array.HostID,
+			Tags:     array.Tags,
 		}
 
 		// Fetch latest metrics for this array
@@ -27,7 +27,9 @@ func RegisterMdadmArrays(c *gin.Context) {
 
 	var registeredArrays []models.MDADMArray
 	var registrationErrors []string
+	hostIDs := make([]string, 0, len(collectorWrapper.Data))
 	for _, collectorArray := range collectorWrapper.Data {
+		hostIDs = append(hostIDs, collectorArray.HostID)
 		trimmedUUID := strings.TrimSpace(collectorArray.UUID)
 		if trimmedUUID == "" {
 			registrationErrors = append(registrationErrors, fmt.Sprintf("array %s rejected: missing UUID", collectorArray.Name))
@@ -54,6 +56,8 @@ func RegisterMdadmArrays(c *gin.Context) {
 		registeredArrays = append(registeredArrays, array)
 	}
 
+	recordHostCheckIns(c, logger, dbRepo, models.CollectorTypeMdadm, hostIDs...)
+
 	c.JSON(http.StatusOK, models.MDADMArrayWrapper{
 		Success: len(registeredArrays) > 0,
 		Errors:  registrationErrors,
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
 	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/gin-gonic/gin"
@@ -66,10 +67,18 @@ func TestRegisterMdadmArraysPassesHostIDThroughRegistration(t *testing.T) {
 		assert.Equal(t, "host-a", array.HostID)
 		return nil
 	})
+	repo.EXPECT().RecordHostCheckIn(gomock.Any(), "host-a", models.CollectorTypeMdadm, models.HostCollectorInfo{
+		CollectorVersion: "1.2.3",
+		OSInfo:           "Debian GNU/Linux 12 (bookworm)",
+		Architecture:     "amd64",
+	}).Return(nil)
 
 	body := `{"data":[{"uuid":"uuid-1","name":"md0","level":"raid1","devices":["/dev/sda"],"host_id":"host-a"}]}`
 	req := httptest.NewRequest(http.MethodPost, "/api/mdadm/arrays/register", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(pkg.HeaderCollectorVersion, "1.2.3")
+	req.Header.Set(pkg.HeaderHostOS, "Debian GNU/Linux 12 (bookworm)")
+	req.Header.Set(pkg.HeaderHostArch, "amd64")
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
@@ -6,6 +6,7 @@ import (
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	appMetrics "github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
 	"github.com/gin-gonic/gin"
@@ -33,8 +34,21 @@ func UploadMdadmMetrics(c *gin.Context) {
 		return
 	}
 
+	publishMDADMStateChanged(c, dbRepo, logger, uuid, &metrics)
+	publishMqttMDADMArray(c, dbRepo, logger, uuid, &metrics)
+
+	if collectorVal, exists := c.Get("METRICS_COLLECTOR"); exists {
+		if metricsCollector, ok := collectorVal.(*appMetrics.Collector); ok && metricsCollector != nil {
+			if err := metricsCollector.RefreshMdadmArrayMetrics(dbRepo, c.Request.Context(), uuid); err != nil {
+				logger.Warnf("Failed to refresh Prometheus mdadm metrics for array %s: %v", uuid, err)
+			}
+		}
+	}
+
 	if shouldNotifyForMDADMFailure(&metrics) {
 		handleMDADMNotification(c, dbRepo, logger, uuid, &metrics)
+	} else {
+		handleMDADMRecovery(c, dbRepo, logger, uuid, &metrics)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
@@ -78,6 +92,58 @@ func shouldSendMDADMNotification(c *gin.Context, dbRepo database.DeviceRepo, uui
 	return !shouldNotifyForMDADMState(lastMetric.State, lastMetric.FailedDevices)
 }
 
+// handleMDADMRecovery sends an ArrayRecovered notification when the previous metrics of a healthy
+// array were degraded, e.g. once a rebuild finished, if metrics.notify_on_array_recovered is enabled.
+func handleMDADMRecovery(c *gin.Context, dbRepo database.DeviceRepo, logger *logrus.Entry, uuid string, metrics *collector.MDADMMetrics) {
+	appConfig := c.MustGet("CONFIG").(config.Interface)
+	if !recoveryNotificationEnabled(appConfig, "notify_on_array_recovered") {
+		return
+	}
+	history, err := dbRepo.GetMdadmMetricsHistory(c.Request.Context(), uuid, "day")
+	if err != nil || len(history) <= 1 {
+		return
+	}
+	lastMetric := history[len(history)-2]
+	if !shouldNotifyForMDADMState(lastMetric.State, lastMetric.FailedDevices) {
+		return
+	}
+
+	array, err := dbRepo.GetMdadmArrayDetails(c.Request.Context(), uuid)
+	if err != nil {
+		logger.Errorf("Failed to retrieve details for MDADM array %s during recovery notification: %v", uuid, err)
+		return
+	}
+	if array.Muted || array.Archived {
+		return
+	}
+	logger.Infof("MDADM array %s recovered: state %q, previously %q", uuid, metrics.State, lastMetric.State)
+	notification := notify.NewMDADMRecovered(logger, appConfig, array, *metrics)
+	notification.LoadDatabaseUrls(c.Request.Context(), dbRepo)
+	sendNotificationWithGate(c, dbRepo, logger, uuid, &notification)
+}
+
+// publishMDADMStateChanged publishes a pool_state_changed event when the array state differs from
+// the previous metrics. The lookups are skipped while nobody is subscribed to the event stream.
+func publishMDADMStateChanged(c *gin.Context, dbRepo database.DeviceRepo, logger *logrus.Entry, uuid string, metrics *collector.MDADMMetrics) {
+	if !eventBus(c).HasSubscribers() {
+		return
+	}
+	history, err := dbRepo.GetMdadmMetricsHistory(c.Request.Context(), uuid, "day")
+	if err != nil || len(history) <= 1 {
+		return
+	}
+	previous := history[len(history)-2].State
+	if previous == metrics.State {
+		return
+	}
+	array, err := dbRepo.GetMdadmArrayDetails(c.Request.Context(), uuid)
+	if err != nil {
+		logger.Warnf("Failed to retrieve details for MDADM array %s for the event stream: %v", uuid, err)
+		return
+	}
+	publishPoolStateChanged(c, "mdadm", uuid, array.Name, array.HostID, previous, metrics.State)
+}
+
 func sendNotificationWithGate(c *gin.Context, dbRepo database.DeviceRepo, logger *logrus.Entry, uuid string, notification *notify.Notify) {
 	if gateVal, exists := c.Get("NOTIFICATION_GATE"); exists {
 		if gate, ok := gateVal.(*notify.NotificationGate); ok {
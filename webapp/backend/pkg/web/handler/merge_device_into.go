@@ -38,6 +38,8 @@ func MergeDeviceInto(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
 		return
 	}
+	// the source device tags were moved to the destination device
+	refreshTagMetrics(c, logger, deviceRepo)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
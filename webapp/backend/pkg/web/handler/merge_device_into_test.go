@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
 	"github.com/gin-gonic/gin"
@@ -97,6 +98,37 @@ func TestMergeDeviceInto_Success(t *testing.T) {
 	require.Equal(t, true, response["success"])
 }
 
+func TestMergeDeviceInto_RefreshesTagMetrics(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	device := models.Device{DeviceID: testDeviceID}
+
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceID).Return(device, nil)
+	mockRepo.EXPECT().MergeDevices(gomock.Any(), testDeviceID, testDestinationDeviceID).Return(nil)
+	// the source device tags moved to the destination device, so the tag metrics are reloaded
+	mockRepo.EXPECT().GetResourceTags(gomock.Any(), models.TagResourceDevice).Return(map[string][]string{testDestinationDeviceID: {"backup"}}, nil)
+	mockRepo.EXPECT().GetResourceTags(gomock.Any(), gomock.Any()).Return(map[string][]string{}, nil).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", mockRepo)
+		c.Set("METRICS_COLLECTOR", metrics.NewCollector(logger))
+		c.Next()
+	})
+	router.POST("/api/device/:id/merge_into", handler.MergeDeviceInto)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/"+testDeviceID+"/merge_into", strings.NewReader(fmt.Sprintf(`{"new_device_id":"%s"}`, testDestinationDeviceID)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestMergeDeviceInto_DeviceNotFound(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	t.Cleanup(mockCtrl.Finish)
@@ -11,7 +11,7 @@ import (
 
 // MqttSync re-syncs all MQTT discovery entities with Home Assistant.
 // It cleans up legacy WWN-based topics, removes archived devices, and re-publishes
-// discovery + state for all active devices.
+// discovery + state for all active devices, pools, arrays and filesystems.
 func MqttSync(c *gin.Context) {
 	logger := c.MustGet("LOGGER").(*logrus.Entry)
 	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
@@ -34,10 +34,18 @@ func MqttSync(c *gin.Context) {
 		return
 	}
 
-	logger.Infof("MQTT sync completed: %d devices published, %d legacy topics cleaned", published, cleaned)
+	storagePublished, err := pub.SyncStorage(deviceRepo, c)
+	if err != nil {
+		logger.Errorf("MQTT storage sync failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	logger.Infof("MQTT sync completed: %d devices and %d storage entities published, %d legacy topics cleaned", published, storagePublished, cleaned)
 	c.JSON(http.StatusOK, gin.H{
 		"success":           true,
 		"devices_published": published,
+		"storage_published": storagePublished,
 		"topics_cleaned":    cleaned,
 	})
 }
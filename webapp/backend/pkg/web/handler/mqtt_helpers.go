@@ -1,33 +1,145 @@
 package handler
 
 import (
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/collector"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/mqtt"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
-// removeMqttDevice removes a device from Home Assistant via MQTT discovery.
-func removeMqttDevice(c *gin.Context, device *models.Device) {
+// mqttPublisher returns the publisher registered by the MQTT middleware, or nil.
+func mqttPublisher(c *gin.Context) *mqtt.Publisher {
 	pubVal, exists := c.Get("MQTT_PUBLISHER")
 	if !exists {
-		return
+		return nil
 	}
 	pub, ok := pubVal.(*mqtt.Publisher)
-	if !ok || pub == nil {
-		return
+	if !ok {
+		return nil
+	}
+	return pub
+}
+
+// removeMqttDevice removes a device from Home Assistant via MQTT discovery.
+func removeMqttDevice(c *gin.Context, device *models.Device) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.RemoveDevice(device)
 	}
-	pub.RemoveDevice(device)
 }
 
 // publishMqttDeviceDiscovery publishes MQTT discovery for a single device.
 func publishMqttDeviceDiscovery(c *gin.Context, device *models.Device) {
-	pubVal, exists := c.Get("MQTT_PUBLISHER")
-	if !exists {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.PublishDiscovery(device)
+	}
+}
+
+// publishMqttZFSPool publishes a ZFS pool as stored, so the label and archived flag are current.
+func publishMqttZFSPool(c *gin.Context, deviceRepo database.DeviceRepo, logger *logrus.Entry, guid string) {
+	pub := mqttPublisher(c)
+	if pub == nil {
 		return
 	}
-	pub, ok := pubVal.(*mqtt.Publisher)
-	if !ok || pub == nil {
+	pool, err := deviceRepo.GetZFSPoolDetails(c, guid)
+	if err != nil {
+		logger.Warnf("MQTT: could not load ZFS pool %s: %v", guid, err)
+		return
+	}
+	if pool.Archived {
+		return
+	}
+	pub.PublishZFSPool(&pool)
+}
+
+// publishMqttDeviceMuted updates the mute switch of a device in Home Assistant.
+func publishMqttDeviceMuted(c *gin.Context, deviceID string, muted bool) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.PublishDeviceMuted(deviceID, muted)
+	}
+}
+
+// publishMqttZFSPoolMuted updates the mute switch of a ZFS pool in Home Assistant.
+func publishMqttZFSPoolMuted(c *gin.Context, guid string, muted bool) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.PublishZFSPoolMuted(guid, muted)
+	}
+}
+
+// removeMqttZFSPool removes a ZFS pool from Home Assistant.
+func removeMqttZFSPool(c *gin.Context, guid string) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.RemoveZFSPool(guid)
+	}
+}
+
+// publishMqttMDADMArray publishes an mdadm array with the metrics just uploaded.
+func publishMqttMDADMArray(c *gin.Context, deviceRepo database.DeviceRepo, logger *logrus.Entry, uuid string, metrics *collector.MDADMMetrics) {
+	pub := mqttPublisher(c)
+	if pub == nil {
+		return
+	}
+	array, err := deviceRepo.GetMdadmArrayDetails(c, uuid)
+	if err != nil {
+		logger.Warnf("MQTT: could not load mdadm array %s: %v", uuid, err)
+		return
+	}
+	if array.Archived {
 		return
 	}
-	pub.PublishDiscovery(device)
+	pub.PublishMDADMArray(&array, metrics)
+}
+
+// publishMqttBtrfsFilesystem publishes a Btrfs filesystem as stored, including its member devices.
+func publishMqttBtrfsFilesystem(c *gin.Context, deviceRepo database.DeviceRepo, logger *logrus.Entry, uuid string) {
+	pub := mqttPublisher(c)
+	if pub == nil {
+		return
+	}
+	filesystem, err := deviceRepo.GetBtrfsFilesystemDetails(c, uuid)
+	if err != nil {
+		logger.Warnf("MQTT: could not load Btrfs filesystem %s: %v", uuid, err)
+		return
+	}
+	if filesystem.Archived {
+		return
+	}
+	pub.PublishBtrfsFilesystem(&filesystem)
+}
+
+// publishMqttBtrfsFilesystemMuted updates the mute switch of a Btrfs filesystem in Home Assistant.
+func publishMqttBtrfsFilesystemMuted(c *gin.Context, uuid string, muted bool) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.PublishBtrfsFilesystemMuted(uuid, muted)
+	}
+}
+
+// removeMqttBtrfsFilesystem removes a Btrfs filesystem from Home Assistant.
+func removeMqttBtrfsFilesystem(c *gin.Context, uuid string) {
+	if pub := mqttPublisher(c); pub != nil {
+		pub.RemoveBtrfsFilesystem(uuid)
+	}
+}
+
+// publishMqttFilesystems publishes the uploaded filesystems and removes the mount points the
+// uploading hosts no longer report, as SaveFilesystemSummary replaces all filesystems of a host.
+func publishMqttFilesystems(c *gin.Context, previous map[string][]models.FilesystemCapacity, payload *models.FilesystemSummaryUpload) {
+	pub := mqttPublisher(c)
+	if pub == nil {
+		return
+	}
+	reported := map[string]bool{}
+	for i := range payload.Filesystems {
+		filesystem := &payload.Filesystems[i]
+		reported[filesystem.HostID+"\x00"+filesystem.MountPoint] = true
+		pub.PublishFilesystem(filesystem)
+	}
+	for _, host := range payload.Hosts {
+		for i := range previous[host.HostID] {
+			if !reported[host.HostID+"\x00"+previous[host.HostID][i].MountPoint] {
+				pub.RemoveFilesystem(&previous[host.HostID][i])
+			}
+		}
+	}
 }
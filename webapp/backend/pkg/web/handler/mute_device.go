@@ -23,6 +23,7 @@ func MuteDevice(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttDeviceMuted(c, device.DeviceID, true)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetNotificationHistory lists recorded notification attempts, newest first.
+// Optional filters: failure_type, outcome, host_id, maintenance_window_id, since (RFC3339), limit
+// and offset.
+func GetNotificationHistory(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	filter := models.NotificationHistoryFilter{
+		FailureType: strings.TrimSpace(c.Query("failure_type")),
+		Outcome:     strings.ToLower(strings.TrimSpace(c.Query("outcome"))),
+		HostID:      strings.TrimSpace(c.Query("host_id")),
+	}
+	switch filter.Outcome {
+	case "", models.NotificationOutcomeSent, models.NotificationOutcomePartial, models.NotificationOutcomeFailed, models.NotificationOutcomeSuppressed, models.NotificationOutcomeDeadLetter:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "outcome must be one of sent, partial, failed, suppressed or dead_letter"})
+		return
+	}
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "since must be an RFC3339 timestamp"})
+			return
+		}
+		filter.Since = &parsed
+	}
+	if raw := c.Query("maintenance_window_id"); raw != "" {
+		windowID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errInvalidIDFormat})
+			return
+		}
+		filter.MaintenanceWindowID = uint(windowID)
+	}
+	for param, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": param + " must be a non-negative integer"})
+				return
+			}
+			*target = value
+		}
+	}
+
+	records, err := deviceRepo.GetNotificationHistory(c, filter)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving notification history", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": records})
+}
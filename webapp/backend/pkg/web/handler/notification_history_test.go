@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupNotificationHistoryRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/notifications/history", handler.GetNotificationHistory)
+	return r
+}
+
+func TestGetNotificationHistory_PassesFilter(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetNotificationHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, filter models.NotificationHistoryFilter) ([]models.NotificationRecord, error) {
+			require.Equal(t, "SmartFailure", filter.FailureType)
+			require.Equal(t, models.NotificationOutcomeSuppressed, filter.Outcome)
+			require.Equal(t, "nas", filter.HostID)
+			require.Equal(t, 10, filter.Limit)
+			require.NotNil(t, filter.Since)
+			return []models.NotificationRecord{{ID: 1, Outcome: models.NotificationOutcomeSuppressed, SuppressedBy: models.NotificationSuppressedMute}}, nil
+		})
+
+	router := setupNotificationHistoryRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/notifications/history?failure_type=SmartFailure&outcome=Suppressed&host_id=nas&limit=10&since=2026-10-01T00:00:00Z", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool                        `json:"success"`
+		Data    []models.NotificationRecord `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	require.Equal(t, models.NotificationSuppressedMute, response.Data[0].SuppressedBy)
+}
+
+func TestGetNotificationHistory_RejectsInvalidParams(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	router := setupNotificationHistoryRouter(t, mock_database.NewMockDeviceRepo(mockCtrl))
+
+	for _, query := range []string{"outcome=lost", "since=yesterday", "limit=-1", "offset=abc"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/notifications/history?"+query, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
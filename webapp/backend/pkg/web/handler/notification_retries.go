@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// notificationRetryResponse adds the masked target URL to a queued retry.
+type notificationRetryResponse struct {
+	models.NotificationRetry
+	Target string `json:"target"`
+}
+
+// GetNotificationRetries lists the notification retry queue, newest first. The optional status
+// filter selects pending retries or dead letters.
+func GetNotificationRetries(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	switch status {
+	case "", models.NotificationRetryPending, models.NotificationRetryDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "status must be pending or dead"})
+		return
+	}
+
+	retries, err := deviceRepo.GetNotificationRetries(c, status)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving the notification retry queue", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	data := make([]notificationRetryResponse, 0, len(retries))
+	for _, retry := range retries {
+		data = append(data, notificationRetryResponse{NotificationRetry: retry, Target: notify.MaskNotifyUrl(retry.URL)})
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": data})
+}
@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupNotificationRetriesRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/notifications/retries", handler.GetNotificationRetries)
+	return r
+}
+
+func TestGetNotificationRetries_MasksTargetURL(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetNotificationRetries(gomock.Any(), models.NotificationRetryDead).Return([]models.NotificationRetry{{
+		ID:       4,
+		URL:      "webhook+https://s3cret@hooks.example.com/scrutiny",
+		Subject:  "Drive failed",
+		Status:   models.NotificationRetryDead,
+		Attempts: 9,
+	}}, nil)
+
+	router := setupNotificationRetriesRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/notifications/retries?status=Dead", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "s3cret")
+	var response struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			ID       uint   `json:"id"`
+			Target   string `json:"target"`
+			Status   string `json:"status"`
+			Attempts int    `json:"attempts"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Len(t, response.Data, 1)
+	require.Equal(t, uint(4), response.Data[0].ID)
+	require.Contains(t, response.Data[0].Target, "hooks.example.com")
+	require.Equal(t, 9, response.Data[0].Attempts)
+}
+
+func TestGetNotificationRetries_RejectsUnknownStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	router := setupNotificationRetriesRouter(t, mock_database.NewMockDeviceRepo(mockCtrl))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/notifications/retries?status=sent", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
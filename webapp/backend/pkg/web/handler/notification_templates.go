@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type previewNotificationTemplateRequest struct {
+	DeviceID    string `json:"device_id"`
+	FailureType string `json:"failure_type"`
+	notify.NotificationTemplates
+}
+
+// PreviewNotificationTemplate renders notification templates against a real device and its
+// latest SMART submission without sending anything. Templates in the request body take
+// precedence; when none are given, the templates from notify.template_dir are rendered.
+// A template error is reported alongside the built-in rendering that would be sent instead.
+func PreviewNotificationTemplate(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+	appConfig := c.MustGet("CONFIG").(config.Interface)
+
+	var req previewNotificationTemplateRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Errorln("Cannot parse notification template preview request", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	req.DeviceID = strings.TrimSpace(req.DeviceID)
+	if req.DeviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "device_id is required"})
+		return
+	}
+
+	device, err := deviceRepo.GetDeviceDetails(c, req.DeviceID)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving device for template preview", err)
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "device not found"})
+		return
+	}
+
+	preview := notify.New(logger, appConfig, device, false)
+	if failureType := strings.TrimSpace(req.FailureType); failureType != "" {
+		preview.Payload.FailureType = failureType
+	}
+	latest, err := deviceRepo.GetLatestSmartSubmission(c, device.WWN)
+	if err != nil {
+		logger.Warnf("Failed to load latest SMART submission for template preview: %v", err)
+	}
+	var smart *measurements.Smart
+	if len(latest) > 0 {
+		smart = &latest[0]
+	}
+	preview.SetTemplateAttributes(smart)
+
+	rendered, renderErr := preview.PreviewTemplates(req.NotificationTemplates)
+	data := gin.H{
+		"failure_type": preview.Payload.FailureType,
+		"subject":      rendered.Subject,
+		"message":      rendered.Message,
+		"html_message": rendered.HTMLMessage,
+		"fallback":     renderErr != nil,
+		"template":     preview.TemplateData(),
+	}
+	if renderErr != nil {
+		data["error"] = renderErr.Error()
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": data})
+}
@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type templatePreviewResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		FailureType string `json:"failure_type"`
+		Subject     string `json:"subject"`
+		Message     string `json:"message"`
+		HTMLMessage string `json:"html_message"`
+		Fallback    bool   `json:"fallback"`
+		Error       string `json:"error"`
+	} `json:"data"`
+}
+
+func setupNotificationTemplatesRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	cfg := mock_config.NewMockInterface(mockCtrl)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Set("CONFIG", cfg)
+		c.Next()
+	})
+	r.POST("/api/notifications/templates/preview", handler.PreviewNotificationTemplate)
+	return r
+}
+
+func postTemplatePreview(t *testing.T, router *gin.Engine, body interface{}) (*httptest.ResponseRecorder, templatePreviewResponse) {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/notifications/templates/preview", bytes.NewReader(payload))
+	router.ServeHTTP(w, req)
+	var response templatePreviewResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w, response
+}
+
+func TestPreviewNotificationTemplate_RendersAgainstDevice(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	device := models.Device{DeviceID: "dev-1", WWN: "0x5000", DeviceName: "sdb", SerialNumber: "SER1", DeviceProtocol: pkg.DeviceProtocolAta, DeviceStatus: pkg.DeviceStatusFailedScrutiny}
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), "dev-1").Return(device, nil)
+	mockRepo.EXPECT().GetLatestSmartSubmission(gomock.Any(), "0x5000").Return([]measurements.Smart{{
+		Attributes: map[string]measurements.SmartAttribute{
+			"5": &measurements.SmartAtaAttribute{AttributeId: 5, TransformedValue: 8, Status: pkg.AttributeStatusFailedScrutiny},
+		},
+	}}, nil)
+
+	router := setupNotificationTemplatesRouter(t, mockRepo)
+	w, response := postTemplatePreview(t, router, map[string]string{
+		"device_id":    "dev-1",
+		"failure_type": "MissedPing",
+		"subject":      "{{.FailureType}}: {{.Device.Serial}}",
+		"text":         "{{range .Attributes}}{{.Name}}={{.Value}}{{end}}",
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, response.Success)
+	require.False(t, response.Data.Fallback)
+	require.Equal(t, "MissedPing", response.Data.FailureType)
+	require.Equal(t, "MissedPing: SER1", response.Data.Subject)
+	require.Equal(t, "Reallocated Sectors Count=8", response.Data.Message)
+}
+
+func TestPreviewNotificationTemplate_ReportsFallbackOnError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	device := models.Device{DeviceID: "dev-1", WWN: "0x5000", DeviceName: "sdb", DeviceStatus: pkg.DeviceStatusFailedSmart}
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), "dev-1").Return(device, nil)
+	mockRepo.EXPECT().GetLatestSmartSubmission(gomock.Any(), "0x5000").Return(nil, errors.New("no data"))
+
+	router := setupNotificationTemplatesRouter(t, mockRepo)
+	w, response := postTemplatePreview(t, router, map[string]string{"device_id": "dev-1", "subject": "{{.Missing"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, response.Data.Fallback)
+	require.NotEmpty(t, response.Data.Error)
+	require.Contains(t, response.Data.Subject, "Scrutiny SMART error")
+}
+
+func TestPreviewNotificationTemplate_RequiresDevice(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	router := setupNotificationTemplatesRouter(t, mockRepo)
+
+	w, _ := postTemplatePreview(t, router, map[string]string{"subject": "x"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), "missing").Return(models.Device{}, errors.New("record not found"))
+	w, _ = postTemplatePreview(t, router, map[string]string{"device_id": "missing"})
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -18,11 +19,13 @@ import (
 const errInvalidIDFormat = "Invalid ID format"
 
 type notifyUrlResponse struct {
-	URL              string `json:"url"`
-	Label            string `json:"label,omitempty"`
-	Source           string `json:"source"`
-	HeartbeatEnabled bool   `json:"heartbeat_enabled"`
-	ID               uint   `json:"id,omitempty"`
+	URL              string                     `json:"url"`
+	Label            string                     `json:"label,omitempty"`
+	Source           string                     `json:"source"`
+	HeartbeatEnabled bool                       `json:"heartbeat_enabled"`
+	Escalation       bool                       `json:"escalation"`
+	ID               uint                       `json:"id,omitempty"`
+	Rules            []models.NotifyRoutingRule `json:"rules,omitempty"`
 }
 
 // GetNotifyUrls returns a merged list of notification URLs from all sources.
@@ -58,6 +61,8 @@ func GetNotifyUrls(c *gin.Context) {
 			Label:            u.Label,
 			Source:           u.Source,
 			HeartbeatEnabled: u.HeartbeatEnabled,
+			Escalation:       u.Escalation,
+			Rules:            u.Rules,
 		})
 	}
 
@@ -74,9 +79,11 @@ func SaveNotifyUrl(c *gin.Context) {
 	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
 
 	var input struct {
-		URL              string `json:"url"`
-		Label            string `json:"label"`
-		HeartbeatEnabled bool   `json:"heartbeat_enabled"`
+		URL              string                     `json:"url"`
+		Label            string                     `json:"label"`
+		HeartbeatEnabled bool                       `json:"heartbeat_enabled"`
+		Escalation       bool                       `json:"escalation"`
+		Rules            []models.NotifyRoutingRule `json:"rules"`
 	}
 	if err := c.BindJSON(&input); err != nil {
 		logger.Errorln("Cannot parse notification URL:", err)
@@ -88,11 +95,17 @@ func SaveNotifyUrl(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "URL is required"})
 		return
 	}
+	if err := validateNotifyRoutingRules(input.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
 
 	entry := &models.NotifyUrl{
 		URL:              input.URL,
 		Label:            input.Label,
 		HeartbeatEnabled: input.HeartbeatEnabled,
+		Escalation:       input.Escalation,
+		Rules:            input.Rules,
 	}
 
 	if err := deviceRepo.SaveNotifyUrl(c, entry); err != nil {
@@ -109,6 +122,8 @@ func SaveNotifyUrl(c *gin.Context) {
 			Label:            entry.Label,
 			Source:           entry.Source,
 			HeartbeatEnabled: entry.HeartbeatEnabled,
+			Escalation:       entry.Escalation,
+			Rules:            entry.Rules,
 		},
 	})
 }
@@ -167,8 +182,92 @@ func UpdateNotifyUrlHeartbeat(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+// UpdateNotifyUrlEscalation marks a notification URL as an escalation target by ID.
+// Escalation URLs only receive notifications for incidents left unacknowledged.
+func UpdateNotifyUrlEscalation(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errInvalidIDFormat})
+		return
+	}
+
+	var input struct {
+		Escalation bool `json:"escalation"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body"})
+		return
+	}
+
+	if err := deviceRepo.UpdateNotifyUrlEscalation(c, uint(id), input.Escalation); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Notification URL not found"})
+			return
+		}
+		logger.Errorln("Error updating notification URL escalation:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update notification URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// UpdateNotifyUrlRules replaces the routing rules of a notification URL by ID.
+// An empty rule list makes the URL receive every notification again.
+func UpdateNotifyUrlRules(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errInvalidIDFormat})
+		return
+	}
+
+	var input struct {
+		Rules []models.NotifyRoutingRule `json:"rules"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body"})
+		return
+	}
+	if err := validateNotifyRoutingRules(input.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	if err := deviceRepo.UpdateNotifyUrlRules(c, uint(id), input.Rules); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Notification URL not found"})
+			return
+		}
+		logger.Errorln("Error updating notification URL rules:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update notification URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": input.Rules})
+}
+
+func validateNotifyRoutingRules(rules []models.NotifyRoutingRule) error {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TestNotifyUrl sends a test notification to a single specific URL by its database ID.
 // Uses SendToUrls to bypass the config URL loading and only send to the target URL.
+// An optional JSON body describes a simulated event (failure_type, host_id, device_protocol, tags);
+// the URL's routing rules are evaluated against it and the test notification is only sent when
+// they match. The severity is derived from the failure type, a posted severity is ignored.
 func TestNotifyUrl(c *gin.Context) {
 	logger := c.MustGet("LOGGER").(*logrus.Entry)
 	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
@@ -189,35 +288,61 @@ func TestNotifyUrl(c *gin.Context) {
 		return
 	}
 
-	var targetUrl string
-	for _, u := range dbUrls {
-		if u.ID == uint(id) {
-			targetUrl = u.URL
+	var target *models.NotifyUrl
+	for i := range dbUrls {
+		if dbUrls[i].ID == uint(id) {
+			target = &dbUrls[i]
 			break
 		}
 	}
 
-	if targetUrl == "" {
+	if target == nil || target.URL == "" {
 		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Notification URL not found"})
 		return
 	}
 
-	testNotify := notify.New(
-		logger,
-		appConfig,
-		models.Device{
-			SerialNumber: "FAKEWDDJ324KSO",
-			DeviceType:   pkg.DeviceProtocolAta,
-			DeviceName:   "/dev/sda",
-		},
-		true,
-	)
+	var simulated *models.NotifyEvent
+	if c.Request.ContentLength != 0 {
+		simulated = &models.NotifyEvent{}
+		if err := c.ShouldBindJSON(simulated); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body"})
+			return
+		}
+	}
 
-	if err := testNotify.SendToUrls([]string{targetUrl}); err != nil {
+	if simulated == nil {
+		testNotify := notify.New(
+			logger,
+			appConfig,
+			models.Device{
+				SerialNumber: "FAKEWDDJ324KSO",
+				DeviceType:   pkg.DeviceProtocolAta,
+				DeviceName:   "/dev/sda",
+			},
+			true,
+		)
+
+		if err := testNotify.SendToUrls([]string{target.URL}); err != nil {
+			logger.Errorln("Test notification failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "errors": []string{err.Error()}})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+		return
+	}
+
+	testNotify, event := notify.NewRoutingTest(logger, appConfig, *simulated)
+	if !target.Accepts(event) {
+		c.JSON(http.StatusOK, gin.H{"success": true, "matched": false, "sent": false, "event": event})
+		return
+	}
+
+	if err := testNotify.SendToUrls([]string{target.URL}); err != nil {
 		logger.Errorln("Test notification failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "errors": []string{err.Error()}})
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "matched": true, "sent": false, "event": event, "errors": []string{err.Error()}})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true})
+	c.JSON(http.StatusOK, gin.H{"success": true, "matched": true, "sent": true, "event": event})
 }
@@ -32,6 +32,8 @@ func setupNotifyUrlsRouter(t *testing.T, mockRepo *mock_database.MockDeviceRepo,
 	r.GET("/api/settings/notify-urls", handler.GetNotifyUrls)
 	r.POST("/api/settings/notify-urls", handler.SaveNotifyUrl)
 	r.PATCH("/api/settings/notify-urls/:id", handler.UpdateNotifyUrlHeartbeat)
+	r.PUT("/api/settings/notify-urls/:id/rules", handler.UpdateNotifyUrlRules)
+	r.POST("/api/settings/notify-urls/:id/test", handler.TestNotifyUrl)
 	return r
 }
 
@@ -115,3 +117,104 @@ func TestUpdateNotifyUrlHeartbeat_Success(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 	require.Equal(t, true, resp["success"])
 }
+
+func TestUpdateNotifyUrlRules_RejectsUnknownSeverity(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockCfg := mock_config.NewMockInterface(mockCtrl)
+
+	router := setupNotifyUrlsRouter(t, mockRepo, mockCfg)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/settings/notify-urls/1/rules", bytes.NewBufferString(`{"rules":[{"min_severity":"urgent"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateNotifyUrlRules_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockCfg := mock_config.NewMockInterface(mockCtrl)
+
+	mockRepo.EXPECT().UpdateNotifyUrlRules(gomock.Any(), uint(1), []models.NotifyRoutingRule{
+		{FailureTypes: []string{"SmartFailure", "MissedPing"}, MinSeverity: "critical"},
+	}).Return(nil)
+
+	router := setupNotifyUrlsRouter(t, mockRepo, mockCfg)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/settings/notify-urls/1/rules", bytes.NewBufferString(`{"rules":[{"failure_types":["SmartFailure","MissedPing"],"min_severity":"critical"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTestNotifyUrl_SimulatedEventRouting(t *testing.T) {
+	var received []map[string]interface{}
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received = append(received, payload)
+	}))
+	t.Cleanup(webhook.Close)
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockCfg := mock_config.NewMockInterface(mockCtrl)
+
+	mockRepo.EXPECT().GetNotifyUrls(gomock.Any()).Return([]models.NotifyUrl{
+		{ID: 7, URL: webhook.URL, Rules: []models.NotifyRoutingRule{{MinSeverity: models.NotifySeverityCritical}}},
+	}, nil).Times(3)
+
+	router := setupNotifyUrlsRouter(t, mockRepo, mockCfg)
+
+	// a report is informational, so the pager URL must not receive it
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/settings/notify-urls/7/test", bytes.NewBufferString(`{"failure_type":"Report"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Matched bool               `json:"matched"`
+		Sent    bool               `json:"sent"`
+		Event   models.NotifyEvent `json:"event"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.False(t, resp.Matched)
+	require.False(t, resp.Sent)
+	require.Equal(t, models.NotifySeverityInfo, resp.Event.Severity)
+	require.Empty(t, received)
+
+	// the severity always follows the failure type, so a report cannot be posted as critical
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/settings/notify-urls/7/test", bytes.NewBufferString(`{"failure_type":"Report","severity":"critical"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.False(t, resp.Matched)
+	require.Equal(t, models.NotifySeverityInfo, resp.Event.Severity)
+	require.Empty(t, received)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/settings/notify-urls/7/test", bytes.NewBufferString(`{"failure_type":"SmartFailure","host_id":"nas"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.Matched)
+	require.True(t, resp.Sent)
+	require.Len(t, received, 1)
+	require.Equal(t, "SmartFailure", received[0]["failure_type"])
+	require.Equal(t, "nas", received[0]["host_id"])
+	require.Equal(t, true, received[0]["test"])
+}
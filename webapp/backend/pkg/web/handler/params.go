@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uintIDParam parses the numeric database ID in the named path parameter, responding with
+// 400 Bad Request when it is not one.
+func uintIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errInvalidIDFormat})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// recoveryNotificationEnabled reports whether a metrics.notify_on_* recovery setting is enabled.
+// Recovery notifications are opt-in per event type.
+func recoveryNotificationEnabled(appConfig config.Interface, setting string) bool {
+	return appConfig.GetBool(fmt.Sprintf("%s.metrics.%s", config.DB_USER_SETTINGS_SUBKEY, setting))
+}
+
+// notificationGate returns the gate registered by the notification gate middleware, or nil.
+func notificationGate(c *gin.Context) *notify.NotificationGate {
+	gateVal, exists := c.Get("NOTIFICATION_GATE")
+	if !exists {
+		return nil
+	}
+	gate, _ := gateVal.(*notify.NotificationGate)
+	return gate
+}
+
+// notifyDeviceRecovered calls NotifyDeviceRecovered with the dependencies of the request.
+func notifyDeviceRecovered(c *gin.Context, logger *logrus.Entry, deviceRepo database.DeviceRepo, device *models.Device, previousStatus pkg.DeviceStatus) {
+	NotifyDeviceRecovered(c, logger, c.MustGet("CONFIG").(config.Interface), deviceRepo, notificationGate(c), device, previousStatus)
+}
+
+// NotifyDeviceRecovered sends a DeviceRecovered notification for a device whose status returned
+// to passed from previousStatus, when metrics.notify_on_device_recovered is enabled. It is shared
+// by the API handlers and the MQTT reset command; gate may be nil.
+func NotifyDeviceRecovered(ctx context.Context, logger logrus.FieldLogger, appConfig config.Interface, deviceRepo database.DeviceRepo, gate *notify.NotificationGate, device *models.Device, previousStatus pkg.DeviceStatus) {
+	if previousStatus == pkg.DeviceStatusPassed || device.Muted {
+		return
+	}
+	if !recoveryNotificationEnabled(appConfig, "notify_on_device_recovered") {
+		return
+	}
+
+	recovered := notify.NewDeviceRecovered(logger, appConfig, device, previousStatus)
+	recovered.LoadDatabaseUrls(ctx, deviceRepo)
+	sendRecoveryNotification(ctx, logger, deviceRepo, gate, &recovered)
+}
+
+// sendRecoveryNotification delivers a resolved notification through the notification gate, or
+// directly when the gate is not available.
+func sendRecoveryNotification(ctx context.Context, logger logrus.FieldLogger, deviceRepo database.DeviceRepo, gate *notify.NotificationGate, n *notify.Notify) {
+	if gate != nil {
+		settings, settingsErr := deviceRepo.LoadSettings(ctx)
+		if settingsErr != nil {
+			logger.Warnf("Failed to load settings for notification gate: %v", settingsErr)
+		}
+		if settings != nil {
+			gate.TrySend(n, settings, false)
+			return
+		}
+	}
+	if sendErr := n.Send(); sendErr != nil {
+		logger.Warnf("Failed to send %s notification: %v", n.Payload.FailureType, sendErr)
+	}
+}
@@ -0,0 +1,178 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg"
+	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// quietHoursSettings returns settings with quiet hours active right now, so notifications passing
+// the gate are queued instead of sent and can be counted.
+func quietHoursSettings() *models.Settings {
+	now := time.Now()
+	minutes := now.Hour()*60 + now.Minute()
+	hhmm := func(m int) string { m = (m + 1440) % 1440; return fmt.Sprintf("%02d:%02d", m/60, m%60) }
+	settings := &models.Settings{}
+	settings.Metrics.NotificationQuietStart = hhmm(minutes - 1)
+	settings.Metrics.NotificationQuietEnd = hhmm(minutes + 1)
+	return settings
+}
+
+func setupRecoveryRouter(t *testing.T, repo *mock_database.MockDeviceRepo, fakeConfig *mock_config.MockInterface, gate *notify.NotificationGate) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	repo.EXPECT().GetNotifyUrls(gomock.Any()).Return(nil, nil).AnyTimes()
+	repo.EXPECT().LoadSettings(gomock.Any()).Return(quietHoursSettings(), nil).AnyTimes()
+	repo.EXPECT().SaveNotificationRecord(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("CONFIG", fakeConfig)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Set("NOTIFICATION_GATE", gate)
+		c.Next()
+	})
+	r.POST("/api/device/:id/reset-status", handler.ResetDeviceStatus)
+	r.POST("/api/mdadm/array/:uuid/metrics", handler.UploadMdadmMetrics)
+	r.POST("/api/zfs/pool/:guid/metrics", handler.UploadZFSPoolMetrics)
+	return r
+}
+
+func TestResetDeviceStatus_NotifiesDeviceRecovered(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			t.Cleanup(mockCtrl.Finish)
+			fakeConfig := mock_config.NewMockInterface(mockCtrl)
+			fakeConfig.EXPECT().GetBool("user.metrics.notify_on_device_recovered").Return(enabled)
+			fakeConfig.EXPECT().GetString(gomock.Any()).Return("").AnyTimes()
+			mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+			failed := models.Device{DeviceID: "dev-1", WWN: testDeviceWWN, DeviceName: "sda", HostId: "nas", DeviceStatus: pkg.DeviceStatusFailedSmart}
+			passed := failed
+			passed.DeviceStatus = pkg.DeviceStatusPassed
+			mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceWWN).Return(failed, nil)
+			mockRepo.EXPECT().ResetDeviceStatus(gomock.Any(), "dev-1").Return(passed, nil)
+
+			gate := notify.NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+			router := setupRecoveryRouter(t, mockRepo, fakeConfig, gate)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/device/"+testDeviceWWN+"/reset-status", nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			if enabled {
+				require.Equal(t, 1, gate.QueueLength())
+			} else {
+				require.Equal(t, 0, gate.QueueLength())
+			}
+		})
+	}
+}
+
+func TestResetDeviceStatus_PassingDeviceDoesNotNotify(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	// strict config mock: reading the recovery setting would fail the test
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	device := models.Device{DeviceID: "dev-1", WWN: testDeviceWWN, DeviceName: "sda"}
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), testDeviceWWN).Return(device, nil)
+	mockRepo.EXPECT().ResetDeviceStatus(gomock.Any(), "dev-1").Return(device, nil)
+
+	gate := notify.NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	router := setupRecoveryRouter(t, mockRepo, fakeConfig, gate)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/"+testDeviceWWN+"/reset-status", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, 0, gate.QueueLength())
+}
+
+func TestUploadMdadmMetrics_NotifiesArrayRecoveredAfterRebuild(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	fakeConfig := mock_config.NewMockInterface(mockCtrl)
+	fakeConfig.EXPECT().GetBool("user.metrics.notify_on_array_recovered").Return(true).Times(2)
+	fakeConfig.EXPECT().GetString(gomock.Any()).Return("").AnyTimes()
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().SaveMdadmMetrics(gomock.Any(), "md-uuid", gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetMdadmMetricsHistory(gomock.Any(), "md-uuid", "day").Return([]measurements.MDADMMetrics{
+			{State: "clean, degraded, recovering", FailedDevices: 0},
+			{State: "clean", FailedDevices: 0},
+		}, nil),
+		mockRepo.EXPECT().GetMdadmMetricsHistory(gomock.Any(), "md-uuid", "day").Return([]measurements.MDADMMetrics{
+			{State: "clean", FailedDevices: 0},
+			{State: "clean", FailedDevices: 0},
+		}, nil),
+	)
+	mockRepo.EXPECT().GetMdadmArrayDetails(gomock.Any(), "md-uuid").Return(models.MDADMArray{UUID: "md-uuid", Name: "md0", Level: "raid1", HostID: "nas"}, nil)
+
+	gate := notify.NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+	router := setupRecoveryRouter(t, mockRepo, fakeConfig, gate)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/mdadm/array/md-uuid/metrics", strings.NewReader(`{"state":"clean","active_devices":2,"working_devices":2}`))
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// only the first healthy upload after the degraded one is a recovery
+	require.Equal(t, 1, gate.QueueLength())
+}
+
+func TestUploadZFSPoolMetrics_NotifiesPoolRecovered(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			t.Cleanup(mockCtrl.Finish)
+			fakeConfig := mock_config.NewMockInterface(mockCtrl)
+			// the ZFS pool recovery has its own setting, independent of notify_on_array_recovered
+			fakeConfig.EXPECT().GetBool("user.metrics.notify_on_zfs_pool_recovered").Return(enabled)
+			fakeConfig.EXPECT().GetString(gomock.Any()).Return("").AnyTimes()
+			mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+			previous := models.ZFSPool{GUID: "1234567890", Name: "tank", HostID: "nas", Status: models.ZFSPoolStatusDegraded}
+			if enabled {
+				mockRepo.EXPECT().GetZFSPoolDetails(gomock.Any(), "1234567890").Return(previous, nil)
+			}
+			mockRepo.EXPECT().RegisterZFSPool(gomock.Any(), gomock.Any()).Return(nil)
+			mockRepo.EXPECT().SaveZFSPoolMetrics(gomock.Any(), gomock.Any()).Return(nil)
+
+			gate := notify.NewNotificationGate(logrus.NewEntry(logrus.StandardLogger()))
+			router := setupRecoveryRouter(t, mockRepo, fakeConfig, gate)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/zfs/pool/1234567890/metrics", strings.NewReader(`{"name":"tank","host_id":"nas","status":"ONLINE"}`))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			if enabled {
+				require.Equal(t, 1, gate.QueueLength())
+			} else {
+				require.Equal(t, 0, gate.QueueLength())
+			}
+		})
+	}
+}
@@ -36,6 +36,12 @@ func RegisterDevices(c *gin.Context) {
 		return
 	}
 
+	// snapshot the known devices, so lifecycle events can be detected once registration is done
+	knownDevices, err := deviceRepo.GetDevices(c)
+	if err != nil {
+		logger.WithError(err).Warn("Cannot load known devices, device lifecycle events will not be detected")
+	}
+
 	errs := []error{}
 	detectedStorageDevices := collectorDeviceWrapper.Data
 	for i := range detectedStorageDevices {
@@ -76,6 +82,16 @@ func RegisterDevices(c *gin.Context) {
 		return
 	}
 
+	hostIDs := make([]string, 0, len(detectedStorageDevices))
+	for i := range detectedStorageDevices {
+		hostIDs = append(hostIDs, detectedStorageDevices[i].HostId)
+	}
+	recordHostCheckIns(c, logger, deviceRepo, models.CollectorTypeMetrics, hostIDs...)
+
+	if knownDevices != nil {
+		recordDeviceLifecycleEvents(c, logger, deviceRepo, knownDevices, detectedStorageDevices)
+	}
+
 	// Publish MQTT discovery for registered devices (if enabled)
 	publishMqttDiscovery(c, deviceRepo, detectedStorageDevices)
 
@@ -17,12 +17,13 @@ func ResetDeviceStatus(c *gin.Context) {
 		return
 	}
 
-	_, err = deviceRepo.ResetDeviceStatus(c, device.DeviceID)
+	resetDevice, err := deviceRepo.ResetDeviceStatus(c, device.DeviceID)
 	if err != nil {
 		logger.Errorln("An error occurred while resetting device status", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	notifyDeviceRecovered(c, logger, deviceRepo, &resetDevice, device.DeviceStatus)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
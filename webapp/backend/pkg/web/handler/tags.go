@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type updateTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// GetTags lists every tag in use with the number of devices, pools, arrays and filesystems using it.
+func GetTags(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	tags, err := deviceRepo.GetTags(c)
+	if err != nil {
+		logger.Errorln("An error occurred while retrieving tags", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": tags})
+}
+
+// UpdateDeviceTags replaces the tags of a device.
+func UpdateDeviceTags(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	device, err := ResolveDevice(c, logger, deviceRepo)
+	if err != nil {
+		return
+	}
+
+	setResourceTags(c, logger, deviceRepo, models.TagResourceDevice, device.DeviceID)
+}
+
+// UpdateZFSPoolTags replaces the tags of a ZFS pool.
+func UpdateZFSPoolTags(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	guid := c.Param("guid")
+	if err := validation.ValidateGUID(guid); err != nil {
+		logger.Warnf(fmtInvalidGUID, guid)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if _, err := deviceRepo.GetZFSPoolDetails(c, guid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "ZFS pool not found"})
+		return
+	}
+
+	setResourceTags(c, logger, deviceRepo, models.TagResourceZFSPool, guid)
+}
+
+// UpdateMdadmArrayTags replaces the tags of an mdadm array.
+func UpdateMdadmArrayTags(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	uuid := c.Param("uuid")
+	if _, err := deviceRepo.GetMdadmArrayDetails(c, uuid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "MDADM array not found"})
+		return
+	}
+
+	setResourceTags(c, logger, deviceRepo, models.TagResourceMdadmArray, uuid)
+}
+
+// UpdateBtrfsFilesystemTags replaces the tags of a Btrfs filesystem.
+func UpdateBtrfsFilesystemTags(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	uuid := c.Param("uuid")
+	if err := validation.ValidateUUID(uuid); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if _, err := deviceRepo.GetBtrfsFilesystemDetails(c, uuid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Btrfs filesystem not found"})
+		return
+	}
+
+	setResourceTags(c, logger, deviceRepo, models.TagResourceBtrfsFilesystem, uuid)
+}
+
+func setResourceTags(c *gin.Context, logger *logrus.Entry, deviceRepo database.DeviceRepo, resourceType string, resourceID string) {
+	var request updateTagsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warnf("Invalid tags request body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	if _, err := models.NormalizeTags(request.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	tags, err := deviceRepo.SetResourceTags(c, resourceType, resourceID, request.Tags)
+	if err != nil {
+		logger.Errorln("An error occurred while saving tags", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	refreshTagMetrics(c, logger, deviceRepo)
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": tags})
+}
+
+// refreshTagMetrics reloads the Prometheus tag metrics after tags were set, copied or removed,
+// e.g. when a tagged resource is deleted or merged.
+func refreshTagMetrics(c *gin.Context, logger *logrus.Entry, deviceRepo database.DeviceRepo) {
+	if collectorVal, exists := c.Get("METRICS_COLLECTOR"); exists {
+		if collector, ok := collectorVal.(*metrics.Collector); ok && collector != nil {
+			if err := collector.RefreshTagMetrics(deviceRepo, c); err != nil {
+				logger.Warnf("Failed to refresh Prometheus tag metrics: %v", err)
+			}
+		}
+	}
+}
+
+// tagFilterFromQuery reads the ?tag= filter. The parameter may be repeated or comma-separated;
+// resources must have every listed tag.
+func tagFilterFromQuery(c *gin.Context) models.TagFilter {
+	return models.ParseTagFilter(c.QueryArray("tag"))
+}
@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mock_database "github.com/analogj/scrutiny/webapp/backend/pkg/database/mock"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTagsRouter(t *testing.T, repo *mock_database.MockDeviceRepo) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := logrus.WithField("test", t.Name())
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("LOGGER", logger)
+		c.Set("DEVICE_REPOSITORY", repo)
+		c.Next()
+	})
+	r.GET("/api/summary", handler.GetDevicesSummary)
+	r.POST("/api/device/:id/tags", handler.UpdateDeviceTags)
+	return r
+}
+
+func TestUpdateDeviceTags_StoresNormalizedTags(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), "sda").Return(models.Device{DeviceID: "sda"}, nil)
+	mockRepo.EXPECT().SetResourceTags(gomock.Any(), models.TagResourceDevice, "sda", []string{"Backup", "critical"}).Return([]string{"backup", "critical"}, nil)
+
+	router := setupTagsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/sda/tags", strings.NewReader(`{"tags":["Backup","critical"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool     `json:"success"`
+		Data    []string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Equal(t, []string{"backup", "critical"}, response.Data)
+}
+
+func TestUpdateDeviceTags_RejectsInvalidTag(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetDeviceDetails(gomock.Any(), "sda").Return(models.Device{DeviceID: "sda"}, nil)
+
+	router := setupTagsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/device/sda/tags", strings.NewReader(`{"tags":["a,b"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetDevicesSummary_FiltersByTag(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockRepo := mock_database.NewMockDeviceRepo(mockCtrl)
+	mockRepo.EXPECT().GetSummary(gomock.Any()).Return(map[string]*models.DeviceSummary{
+		"sda": {Device: models.Device{DeviceID: "sda", Tags: []string{"backup", "critical"}}},
+		"sdb": {Device: models.Device{DeviceID: "sdb", Tags: []string{"backup"}}},
+		"sdc": {Device: models.Device{DeviceID: "sdc"}},
+	}, nil)
+
+	router := setupTagsRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/summary?tag=backup&tag=Critical", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.DeviceSummaryWrapper
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Summary, 1)
+	require.Contains(t, response.Data.Summary, "sda")
+}
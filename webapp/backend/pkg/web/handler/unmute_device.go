@@ -23,6 +23,7 @@ func UnmuteDevice(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttDeviceMuted(c, device.DeviceID, false)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const inventoryDateFormat = "2006-01-02"
+
+// deviceInventoryRequest mirrors models.DeviceInventory, with dates accepted as YYYY-MM-DD (or
+// RFC3339) strings. Empty strings and null values clear a field.
+type deviceInventoryRequest struct {
+	PurchaseDate      string   `json:"purchase_date"`
+	WarrantyExpiresAt string   `json:"warranty_expires_at"`
+	PurchasePrice     *float64 `json:"purchase_price"`
+	Vendor            string   `json:"vendor"`
+	RMAContact        string   `json:"rma_contact"`
+	Location          string   `json:"location"`
+}
+
+func (r deviceInventoryRequest) toInventory() (models.DeviceInventory, error) {
+	purchaseDate, err := parseInventoryDate(r.PurchaseDate)
+	if err != nil {
+		return models.DeviceInventory{}, fmt.Errorf("purchase_date: %w", err)
+	}
+	warrantyExpiresAt, err := parseInventoryDate(r.WarrantyExpiresAt)
+	if err != nil {
+		return models.DeviceInventory{}, fmt.Errorf("warranty_expires_at: %w", err)
+	}
+	if r.PurchasePrice != nil && *r.PurchasePrice < 0 {
+		return models.DeviceInventory{}, fmt.Errorf("purchase_price must be >= 0")
+	}
+	return models.DeviceInventory{
+		PurchaseDate:      purchaseDate,
+		WarrantyExpiresAt: warrantyExpiresAt,
+		PurchasePrice:     r.PurchasePrice,
+		Vendor:            strings.TrimSpace(r.Vendor),
+		RMAContact:        strings.TrimSpace(r.RMAContact),
+		Location:          strings.TrimSpace(r.Location),
+	}, nil
+}
+
+// parseInventoryDate parses a YYYY-MM-DD or RFC3339 date, normalized to midnight UTC. An empty
+// value returns nil.
+func parseInventoryDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(inventoryDateFormat, value)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+		}
+	}
+	date := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+	return &date, nil
+}
+
+// UpdateDeviceInventory replaces the inventory fields (purchase date, warranty expiry, vendor/RMA
+// contact, price and location) of a device.
+func UpdateDeviceInventory(c *gin.Context) {
+	logger := c.MustGet("LOGGER").(*logrus.Entry)
+	deviceRepo := c.MustGet("DEVICE_REPOSITORY").(database.DeviceRepo)
+
+	device, err := ResolveDevice(c, logger, deviceRepo)
+	if err != nil {
+		return
+	}
+
+	var req deviceInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("Invalid request body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
+		return
+	}
+	inventory, err := req.toInventory()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	if err := deviceRepo.UpdateDeviceInventory(c, device.DeviceID, inventory); err != nil {
+		logger.Errorln("An error occurred while updating device inventory", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": inventory})
+}
@@ -64,6 +64,7 @@ func UploadCollectorError(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false})
 		return
 	}
+	publishCollectorError(c, &device, req.ErrorType, req.ErrorMessage)
 
 	notifyEnabled := appConfig.GetBool(fmt.Sprintf("%s.metrics.notify_on_collector_error", config.DB_USER_SETTINGS_SUBKEY))
 	if !notifyEnabled {
@@ -101,6 +102,7 @@ func UploadCollectorScanError(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false})
 		return
 	}
+	publishCollectorError(c, &models.Device{DeviceName: req.DeviceName}, req.ErrorType, req.ErrorMessage)
 
 	notifyEnabled := appConfig.GetBool(fmt.Sprintf("%s.metrics.notify_on_collector_error", config.DB_USER_SETTINGS_SUBKEY))
 	if !notifyEnabled {
@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
@@ -71,7 +72,7 @@ func UploadDeviceMetrics(c *gin.Context) {
 		return
 	}
 
-	clearCollectorErrorState(c, updatedDevice.DeviceID)
+	clearCollectorErrorState(c, logger, appConfig, deviceRepo, &updatedDevice)
 
 	// check for error
 	if notify.ShouldNotify(
@@ -87,7 +88,7 @@ func UploadDeviceMetrics(c *gin.Context) {
 		deviceRepo,
 		appConfig,
 	) {
-		sendDeviceNotification(c, logger, appConfig, deviceRepo, device.DeviceID, &updatedDevice)
+		sendDeviceNotification(c, logger, appConfig, deviceRepo, device.DeviceID, &updatedDevice, &smartData)
 	}
 
 	maybeNotifyReplacementRiskFromSettings(c, logger, appConfig, deviceRepo, &updatedDevice, smartData.Attributes)
@@ -96,15 +97,32 @@ func UploadDeviceMetrics(c *gin.Context) {
 
 	publishMQTTDeviceState(c, device.DeviceID, &updatedDevice, &smartData)
 
+	publishDeviceMetricsIngested(c, &updatedDevice, &smartData)
+
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func clearCollectorErrorState(c *gin.Context, deviceID string) {
-	if gateVal, exists := c.Get("NOTIFICATION_GATE"); exists {
-		if gate, ok := gateVal.(*notify.NotificationGate); ok && gate != nil {
-			gate.ClearCollectorErrorState("device:" + deviceID)
-		}
+// clearCollectorErrorState forgets the collector errors notified for a device that uploaded SMART
+// data again, and reports that they cleared when metrics.notify_on_collector_error_cleared is enabled.
+func clearCollectorErrorState(c *gin.Context, logger *logrus.Entry, appConfig config.Interface, deviceRepo database.DeviceRepo, device *models.Device) {
+	gateVal, exists := c.Get("NOTIFICATION_GATE")
+	if !exists {
+		return
+	}
+	gate, ok := gateVal.(*notify.NotificationGate)
+	if !ok || gate == nil {
+		return
+	}
+	if !gate.ClearCollectorErrorState("device:"+device.DeviceID) || device.Muted {
+		return
+	}
+	if !recoveryNotificationEnabled(appConfig, "notify_on_collector_error_cleared") {
+		return
 	}
+
+	cleared := notify.NewCollectorErrorCleared(logger, appConfig, device)
+	cleared.LoadDatabaseUrls(c, deviceRepo)
+	sendRecoveryNotification(c, logger, deviceRepo, gate, &cleared)
 }
 
 func bindAndValidateSmartInfo(c *gin.Context, logger *logrus.Entry, deviceWWN string) (collector.SmartInfo, bool) {
@@ -147,6 +165,7 @@ func reconcileDeviceStatus(c *gin.Context, logger *logrus.Entry, deviceRepo data
 			c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 			return models.Device{}, false
 		}
+		publishDeviceStatusChanged(c, &device, updatedDevice.DeviceStatus)
 		return device, true
 	}
 	if updatedDevice.DeviceStatus == pkg.DeviceStatusPassed {
@@ -159,12 +178,28 @@ func reconcileDeviceStatus(c *gin.Context, logger *logrus.Entry, deviceRepo data
 		return models.Device{}, false
 	}
 	logger.Infof("Device %s status reset to passed - all SMART attributes now within thresholds", deviceID)
+	publishDeviceStatusChanged(c, &device, updatedDevice.DeviceStatus)
+	notifyDeviceRecovered(c, logger, deviceRepo, &device, updatedDevice.DeviceStatus)
 	return device, true
 }
 
-func sendDeviceNotification(c *gin.Context, logger *logrus.Entry, appConfig config.Interface, deviceRepo database.DeviceRepo, deviceID string, updatedDevice *models.Device) {
+func sendDeviceNotification(c *gin.Context, logger *logrus.Entry, appConfig config.Interface, deviceRepo database.DeviceRepo, deviceID string, updatedDevice *models.Device, smartData *measurements.Smart) {
 	liveNotify := notify.New(logger, appConfig, *updatedDevice, false)
+	liveNotify.SetTemplateAttributes(smartData)
+
+	// Track the failure as an incident; once acknowledged, the device stops notifying until it
+	// recovers. Incident tracking failures must never suppress the notification itself.
+	incident, incidentErr := deviceRepo.OpenDeviceIncident(c, *updatedDevice, liveNotify.Payload.FailureType, liveNotify.Payload.Subject, liveNotify.Payload.Message)
+	if incidentErr != nil {
+		logger.Warnf("Failed to open incident for device %s: %v", deviceID, incidentErr)
+	} else if incident.Status == models.IncidentStatusAcknowledged {
+		logger.Infof("Skipping notification for device %s: incident %d is acknowledged", deviceID, incident.ID)
+		return
+	}
+
 	liveNotify.LoadDatabaseUrls(c, deviceRepo)
+	sent := false
+	gateHandled := false
 	if gateVal, exists := c.Get("NOTIFICATION_GATE"); exists {
 		if gate, ok := gateVal.(*notify.NotificationGate); ok {
 			settings, settingsErr := deviceRepo.LoadSettings(c)
@@ -172,13 +207,23 @@ func sendDeviceNotification(c *gin.Context, logger *logrus.Entry, appConfig conf
 				logger.Warnf("Failed to load settings for notification gate: %v", settingsErr)
 			}
 			if settings != nil {
-				gate.TrySend(&liveNotify, settings, false)
-				return
+				sent = gate.TrySend(&liveNotify, settings, false)
+				gateHandled = true
 			}
 		}
 	}
-	if sendErr := liveNotify.Send(); sendErr != nil {
-		logger.Warnf("Failed to send notification for device %s: %v", deviceID, sendErr)
+	if !gateHandled {
+		if sendErr := liveNotify.Send(); sendErr != nil {
+			logger.Warnf("Failed to send notification for device %s: %v", deviceID, sendErr)
+		} else {
+			sent = true
+		}
+	}
+
+	if sent && incidentErr == nil {
+		if err := deviceRepo.MarkIncidentNotified(c, incident.ID, time.Now().UTC()); err != nil {
+			logger.Warnf("Failed to record notification for incident %d: %v", incident.ID, err)
+		}
 	}
 }
 
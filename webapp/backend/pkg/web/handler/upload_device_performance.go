@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models/measurements"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -49,6 +50,7 @@ func UploadDevicePerformance(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid request body"})
 		return
 	}
+	recordHostCheckIns(c, logger, deviceRepo, models.CollectorTypePerformance, device.HostId)
 
 	perfData := measurements.Performance{
 		Date:              time.Unix(req.Date, 0),
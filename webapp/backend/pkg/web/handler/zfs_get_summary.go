@@ -20,6 +20,14 @@ func GetZFSPoolsSummary(c *gin.Context) {
 		return
 	}
 
+	if tagFilter := tagFilterFromQuery(c); len(tagFilter) > 0 {
+		for guid, pool := range summary {
+			if !tagFilter.Matches(pool.Tags) {
+				delete(summary, guid)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -30,6 +30,7 @@ func ArchiveZFSPool(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	removeMqttZFSPool(c, guid)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -52,6 +53,7 @@ func UnarchiveZFSPool(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttZFSPool(c, deviceRepo, logger, guid)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -74,6 +76,7 @@ func MuteZFSPool(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttZFSPoolMuted(c, guid, true)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -96,6 +99,7 @@ func UnmuteZFSPool(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	publishMqttZFSPoolMuted(c, guid, false)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -149,6 +153,8 @@ func DeleteZFSPool(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
 		return
 	}
+	removeMqttZFSPool(c, guid)
+	refreshTagMetrics(c, logger, deviceRepo)
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
@@ -45,6 +45,10 @@ func RegisterZFSPools(c *gin.Context) {
 		return
 	}
 
+	recordHostCheckIns(c, logger, deviceRepo, models.CollectorTypeZFS, lo.Map(detectedPools, func(pool models.ZFSPool, _ int) string {
+		return pool.HostID
+	})...)
+
 	c.JSON(http.StatusOK, models.ZFSPoolWrapper{
 		Success: true,
 		Data:    detectedPools,
@@ -3,9 +3,11 @@ package handler
 import (
 	"net/http"
 
+	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -34,6 +36,16 @@ func UploadZFSPoolMetrics(c *gin.Context) {
 	// Ensure the GUID matches the URL parameter
 	pool.GUID = guid
 
+	// keep the previous status to report a recovery, e.g. once a resilver finished, or a state change
+	appConfig := c.MustGet("CONFIG").(config.Interface)
+	notifyRecovered := recoveryNotificationEnabled(appConfig, "notify_on_zfs_pool_recovered")
+	var previous *models.ZFSPool
+	if notifyRecovered || eventBus(c).HasSubscribers() {
+		if existing, err := deviceRepo.GetZFSPoolDetails(c, guid); err == nil {
+			previous = &existing
+		}
+	}
+
 	// Update the pool in the database
 	if err := deviceRepo.RegisterZFSPool(c, pool); err != nil {
 		logger.Errorln("An error occurred while updating ZFS pool", err)
@@ -48,6 +60,19 @@ func UploadZFSPoolMetrics(c *gin.Context) {
 		return
 	}
 
+	if previous != nil && previous.Status != "" {
+		publishPoolStateChanged(c, "zfs", guid, pool.Name, pool.HostID, string(previous.Status), string(pool.Status))
+	}
+
+	if previous != nil && previous.Status != "" && !previous.IsHealthy() && pool.IsHealthy() && !previous.Muted && !previous.Archived && notifyRecovered {
+		logger.Infof("ZFS pool %s recovered: status %s, previously %s", guid, pool.Status, previous.Status)
+		recovered := notify.NewZFSPoolRecovered(logger, appConfig, pool, previous.Status)
+		recovered.LoadDatabaseUrls(c, deviceRepo)
+		sendRecoveryNotification(c, logger, deviceRepo, notificationGate(c), &recovered)
+	}
+
+	publishMqttZFSPool(c, deviceRepo, logger, guid)
+
 	if collectorVal, exists := c.Get("METRICS_COLLECTOR"); exists {
 		if collector, ok := collectorVal.(*metrics.Collector); ok && collector != nil {
 			if err := collector.RefreshZFSPoolMetrics(deviceRepo, c); err != nil {
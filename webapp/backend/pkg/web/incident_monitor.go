@@ -0,0 +1,195 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultIncidentCheckInterval is how often open incidents are checked for escalation
+	DefaultIncidentCheckInterval = 5 * time.Minute
+)
+
+// IncidentMonitor escalates incidents that stay open (unacknowledged) for longer than
+// metrics.incident_escalation_minutes by notifying the escalation URLs once per incident.
+type IncidentMonitor struct {
+	appEngine *AppEngine
+	logger    logrus.FieldLogger
+
+	// Persistent repository connection (created once, reused)
+	deviceRepo database.DeviceRepo
+	repoMu     sync.Mutex
+
+	// Channel to signal shutdown and context for cancellation
+	stopCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// WaitGroup to track when the run goroutine has finished
+	wg sync.WaitGroup
+}
+
+// NewIncidentMonitor creates a new incident escalation monitor
+func NewIncidentMonitor(ae *AppEngine) *IncidentMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &IncidentMonitor{
+		appEngine: ae,
+		logger:    ae.Logger,
+		stopCh:    make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the background escalation check loop
+func (m *IncidentMonitor) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop signals the monitor to stop and waits for it to finish
+func (m *IncidentMonitor) Stop() {
+	m.logger.Debug("Stopping incident monitor...")
+	m.cancel()
+	close(m.stopCh)
+	m.wg.Wait()
+
+	// Close the persistent repository connection if it exists
+	m.repoMu.Lock()
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+	m.repoMu.Unlock()
+
+	m.logger.Info("Incident monitor stopped")
+}
+
+func (m *IncidentMonitor) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(DefaultIncidentCheckInterval)
+	defer ticker.Stop()
+
+	m.logger.Infof("Incident monitor started with check interval: %v", DefaultIncidentCheckInterval)
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.checkEscalations(time.Now())
+		}
+	}
+}
+
+// getOrCreateRepo returns the persistent repository, creating it if necessary
+func (m *IncidentMonitor) getOrCreateRepo() (database.DeviceRepo, error) {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		return m.deviceRepo, nil
+	}
+
+	repo, err := database.NewScrutinyRepositoryWithoutMigration(m.appEngine.Config, m.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m.deviceRepo = repo
+	return m.deviceRepo, nil
+}
+
+// resetRepo closes and clears the persistent repository (called on connection errors)
+func (m *IncidentMonitor) resetRepo() {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+}
+
+func (m *IncidentMonitor) checkEscalations(now time.Time) {
+	if m.ctx.Err() != nil {
+		return
+	}
+
+	deviceRepo, err := m.getOrCreateRepo()
+	if err != nil {
+		m.logger.Errorf("Failed to get/create repository for incident escalation check: %v", err)
+		return
+	}
+
+	settings, err := deviceRepo.LoadSettings(m.ctx)
+	if err != nil {
+		m.resetRepo()
+		m.logger.Errorf("Failed to load settings for incident escalation check: %v", err)
+		return
+	}
+	if settings == nil || settings.Metrics.IncidentEscalationMinutes <= 0 {
+		m.logger.Debug("Incident escalation is disabled")
+		return
+	}
+	delay := time.Duration(settings.Metrics.IncidentEscalationMinutes) * time.Minute
+
+	incidents, err := deviceRepo.GetIncidents(m.ctx, models.IncidentStatusOpen)
+	if err != nil {
+		m.resetRepo()
+		m.logger.Errorf("Failed to load incidents for escalation check: %v", err)
+		return
+	}
+
+	// Incidents of devices in maintenance escalate once the window is over
+	maintenance, err := deviceRepo.GetActiveMaintenanceWindows(m.ctx, now)
+	if err != nil {
+		m.logger.Warnf("Failed to load maintenance windows for incident escalation check: %v", err)
+	}
+
+	for _, incident := range dueIncidentEscalations(incidents, maintenance, now, delay) {
+		m.escalate(deviceRepo, incident, now, delay)
+	}
+}
+
+// dueIncidentEscalations returns the open incidents that have gone unacknowledged for at least
+// delay and have not been escalated yet.
+func dueIncidentEscalations(incidents []models.Incident, maintenance []models.MaintenanceWindow, now time.Time, delay time.Duration) []models.Incident {
+	due := []models.Incident{}
+	for _, incident := range incidents {
+		target := models.MaintenanceTarget{HostID: incident.HostID, DeviceID: incident.DeviceID, DeviceName: incident.DeviceName}
+		if incident.EscalationDue(now, delay) && models.ActiveMaintenanceWindow(maintenance, target, now) == nil {
+			due = append(due, incident)
+		}
+	}
+	return due
+}
+
+func (m *IncidentMonitor) escalate(deviceRepo database.DeviceRepo, incident models.Incident, now time.Time, delay time.Duration) {
+	notification := notify.NewIncidentEscalation(m.logger, m.appEngine.Config, incident, delay)
+	notification.LoadEscalationDatabaseUrls(m.ctx, deviceRepo)
+	if len(notification.DatabaseUrls) == 0 {
+		m.logger.Debugf("No escalation notification URLs configured, incident %d not escalated", incident.ID)
+		return
+	}
+
+	// Escalations go straight to the escalation URLs: quiet hours and rate limits would defeat
+	// the point of re-notifying about an incident nobody has acknowledged.
+	if err := notification.SendToUrls(notification.DatabaseUrls); err != nil {
+		m.logger.Errorf("Failed to send escalation for incident %d: %v", incident.ID, err)
+		return
+	}
+
+	if err := deviceRepo.MarkIncidentEscalated(m.ctx, incident.ID, now); err != nil {
+		m.logger.Errorf("Failed to record escalation for incident %d: %v", incident.ID, err)
+		return
+	}
+	m.logger.Infof("Escalated incident %d for device %s", incident.ID, incident.DeviceID)
+}
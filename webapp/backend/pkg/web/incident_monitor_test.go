@@ -0,0 +1,46 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueIncidentEscalations(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	escalatedAt := now.Add(-time.Minute)
+
+	incidents := []models.Incident{
+		{ID: 1, Status: models.IncidentStatusOpen, OpenedAt: now.Add(-2 * time.Hour)},
+		{ID: 2, Status: models.IncidentStatusOpen, OpenedAt: now.Add(-time.Hour)},
+		{ID: 3, Status: models.IncidentStatusOpen, OpenedAt: now.Add(-30 * time.Minute)},
+		{ID: 4, Status: models.IncidentStatusOpen, OpenedAt: now.Add(-2 * time.Hour), EscalatedAt: &escalatedAt},
+		{ID: 5, Status: models.IncidentStatusAcknowledged, OpenedAt: now.Add(-2 * time.Hour)},
+		{ID: 6, Status: models.IncidentStatusResolved, OpenedAt: now.Add(-2 * time.Hour)},
+	}
+
+	ids := []uint{}
+	for _, incident := range dueIncidentEscalations(incidents, nil, now, time.Hour) {
+		ids = append(ids, incident.ID)
+	}
+	require.Equal(t, []uint{1, 2}, ids)
+
+	require.Empty(t, dueIncidentEscalations(incidents, nil, now, 0))
+}
+
+func TestDueIncidentEscalations_SkipsDevicesInMaintenance(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	incidents := []models.Incident{
+		{ID: 1, DeviceID: "dev-1", HostID: "nas", Status: models.IncidentStatusOpen, OpenedAt: now.Add(-2 * time.Hour)},
+		{ID: 2, DeviceID: "dev-2", HostID: "backup", Status: models.IncidentStatusOpen, OpenedAt: now.Add(-2 * time.Hour)},
+	}
+	maintenance := []models.MaintenanceWindow{
+		{ID: 7, ScopeType: models.MaintenanceScopeHost, ScopeID: "nas", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+	}
+
+	due := dueIncidentEscalations(incidents, maintenance, now, time.Hour)
+	require.Len(t, due, 1)
+	require.Equal(t, uint(2), due[0].ID)
+}
@@ -30,6 +30,10 @@ var docsPathSuffixes = []string{
 // which supports an independent authentication token (web.metrics.token).
 const metricsPathSuffix = "/api/metrics"
 
+// eventsPathSuffix is the path suffix for the event stream. Browsers cannot set headers on
+// EventSource and WebSocket requests, so it also accepts the token as access_token query parameter.
+const eventsPathSuffix = "/api/events"
+
 // configKeyJWTSecret is the config key for the JWT signing secret.
 const configKeyJWTSecret = "web.auth.jwt_secret" //nolint:gosec
 
@@ -66,6 +70,16 @@ func isMetricsPath(requestPath string) bool {
 	return strings.HasSuffix(requestPath, metricsPathSuffix)
 }
 
+// requestToken returns the Bearer token of the request, falling back to the access_token query
+// parameter on the event stream.
+func requestToken(c *gin.Context) string {
+	tokenString := auth.ExtractBearerToken(c.GetHeader("Authorization"))
+	if tokenString == "" && strings.HasSuffix(c.Request.URL.Path, eventsPathSuffix) {
+		tokenString = c.Query("access_token")
+	}
+	return tokenString
+}
+
 // validateMetricsToken checks if the request carries a valid dedicated metrics token.
 // Returns true if the metrics token is configured and the request token matches.
 func (ac *authContext) validateMetricsToken(c *gin.Context) bool {
@@ -79,7 +93,7 @@ func (ac *authContext) validateMetricsToken(c *gin.Context) bool {
 // validateGeneralAuth tries API token and JWT validation in order.
 // Returns true and sets context values if authentication succeeds.
 func (ac *authContext) validateGeneralAuth(c *gin.Context) bool {
-	tokenString := auth.ExtractBearerToken(c.GetHeader("Authorization"))
+	tokenString := requestToken(c)
 	if tokenString == "" {
 		return false
 	}
@@ -112,7 +126,7 @@ func rejectUnauthorized(c *gin.Context, message string) {
 // rejectMissingOrInvalid sends a 401 with a message appropriate to whether
 // the request carries a Bearer token at all or an invalid one.
 func rejectMissingOrInvalid(c *gin.Context) {
-	if auth.ExtractBearerToken(c.GetHeader("Authorization")) == "" {
+	if requestToken(c) == "" {
 		rejectUnauthorized(c, "Authentication required. Provide a Bearer token in the Authorization header.")
 	} else {
 		rejectUnauthorized(c, "Invalid or expired token.")
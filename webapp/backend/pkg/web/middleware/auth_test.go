@@ -22,6 +22,7 @@ const pathSummary = "/api/summary"
 const pathMetrics = "/api/metrics"
 const pathHealth = "/api/health"
 const pathOpenAPIDoc = "/api/docs/openapi.yaml"
+const pathEvents = "/api/events"
 const bearerPrefix = "Bearer "
 
 // setupRouter creates a test gin router with the auth middleware and a simple
@@ -74,6 +75,9 @@ func setupRouter(t *testing.T, authEnabled bool, apiToken string, jwtSecret stri
 	r.GET("/docs/api", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"success": true})
 	})
+	r.GET(pathEvents, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
 
 	return r
 }
@@ -500,3 +504,23 @@ func TestAuthMiddleware_MetricsToken_OnlyAffectsMetrics(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, w.Code, "non-metrics endpoints should not require metrics token")
 }
+
+func TestAuthMiddleware_Events_AcceptsAccessTokenQuery(t *testing.T) {
+	router := setupRouter(t, true, testAPIToken, testJWTSecret, "")
+
+	for _, tc := range []struct {
+		path     string
+		expected int
+	}{
+		{pathEvents, http.StatusUnauthorized},
+		{pathEvents + "?access_token=" + testAPIToken, http.StatusOK},
+		{pathEvents + "?access_token=wrong-token", http.StatusUnauthorized},
+		// the query parameter is only accepted by the event stream
+		{pathSummary + "?access_token=" + testAPIToken, http.StatusUnauthorized},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tc.path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, tc.expected, w.Code, tc.path)
+	}
+}
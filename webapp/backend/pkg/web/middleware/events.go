@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
+	"github.com/gin-gonic/gin"
+)
+
+// EventBusMiddleware injects the event bus into gin context
+func EventBusMiddleware(bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("EVENT_BUS", bus)
+		c.Next()
+	}
+}
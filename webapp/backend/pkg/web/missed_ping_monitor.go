@@ -3,6 +3,8 @@ package web
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -190,7 +192,18 @@ type checkMissedPingsData struct {
 	cooldownMinutes int // 0 = use timeoutMinutes for cooldown (backward compat)
 	settings        *models.Settings
 	devices         []models.Device
+	hosts           map[string]models.Host
 	lastSeenTimes   map[string]time.Time
+	maintenance     []models.MaintenanceWindow
+
+	// devices that report again after a missed ping notification, filled in by checkDevice
+	recovered []missedPingRecovery
+}
+
+// missedPingRecovery is a previously notified device that is reporting again.
+type missedPingRecovery struct {
+	device   models.Device
+	lastSeen time.Time
 }
 
 // loadCheckData loads all data needed for missed ping checks
@@ -224,6 +237,16 @@ func (m *MissedPingMonitor) loadCheckData() (*checkMissedPingsData, error) {
 		return nil, err
 	}
 
+	// Host settings only refine the check, so a failure to load them is not fatal
+	hosts := map[string]models.Host{}
+	if hostList, err := deviceRepo.GetHosts(m.ctx); err != nil {
+		m.logger.Warnf("Failed to load hosts from database, ignoring per-host settings: %v", err)
+	} else {
+		for _, host := range hostList {
+			hosts[host.HostID] = host
+		}
+	}
+
 	lastSeenTimes, err := deviceRepo.GetDevicesLastSeenTimes(m.ctx)
 	if err != nil {
 		m.resetRepo()
@@ -231,6 +254,12 @@ func (m *MissedPingMonitor) loadCheckData() (*checkMissedPingsData, error) {
 		return nil, err
 	}
 
+	// Maintenance windows only suppress alerts, so a failure to load them is not fatal either
+	maintenance, err := deviceRepo.GetActiveMaintenanceWindows(m.ctx, time.Now())
+	if err != nil {
+		m.logger.Warnf("Failed to load maintenance windows, missed pings will not be suppressed: %v", err)
+	}
+
 	cooldownMinutes := settings.Metrics.MissedPingCooldownMinutes
 
 	m.logger.Debugf("Loaded missed ping check data: %d devices, timeout=%dm, cooldown=%dm", len(devices), timeoutMinutes, cooldownMinutes)
@@ -241,7 +270,9 @@ func (m *MissedPingMonitor) loadCheckData() (*checkMissedPingsData, error) {
 		cooldownMinutes: cooldownMinutes,
 		settings:        settings,
 		devices:         devices,
+		hosts:           hosts,
 		lastSeenTimes:   lastSeenTimes,
+		maintenance:     maintenance,
 	}, nil
 }
 
@@ -252,6 +283,11 @@ func (m *MissedPingMonitor) checkDevice(device *models.Device, data *checkMissed
 		m.logger.Debugf("Skipping device %s (wwn: %s) - archived: %v, muted: %v", device.DeviceID, device.WWN, device.Archived, device.Muted)
 		return nil
 	}
+	host, hasHost := data.hosts[device.HostId]
+	if hasHost && (host.Archived || host.Muted) {
+		m.logger.Debugf("Skipping device %s (wwn: %s) - host %s archived: %v, muted: %v", device.DeviceID, device.WWN, host.HostID, host.Archived, host.Muted)
+		return nil
+	}
 
 	lastSeen, exists := data.lastSeenTimes[device.DeviceID]
 	if !exists {
@@ -259,15 +295,20 @@ func (m *MissedPingMonitor) checkDevice(device *models.Device, data *checkMissed
 		return nil
 	}
 
-	// Use per-device timeout override if set, otherwise use global timeout
+	// Use per-device timeout override if set, then the host timeout, otherwise the global timeout
 	deviceTimeoutMinutes := data.timeoutMinutes
+	if hasHost && host.MissedPingTimeoutMinutes > 0 {
+		deviceTimeoutMinutes = host.MissedPingTimeoutMinutes
+	}
 	if device.MissedPingTimeoutOverride > 0 {
 		deviceTimeoutMinutes = device.MissedPingTimeoutOverride
 	}
 	deviceTimeout := time.Duration(deviceTimeoutMinutes) * time.Minute
 
 	if now.Sub(lastSeen) <= deviceTimeout {
-		m.clearNotificationState(device.DeviceID)
+		if m.clearNotificationState(device.DeviceID) {
+			data.recovered = append(data.recovered, missedPingRecovery{device: *device, lastSeen: lastSeen})
+		}
 		return nil
 	}
 
@@ -336,11 +377,7 @@ func (m *MissedPingMonitor) checkMissedPings() {
 
 	// Flush any notifications queued during quiet hours
 	if gate := m.appEngine.NotificationGate; gate != nil && data.settings != nil {
-		flushNotify := notify.Notify{
-			Logger: m.logger,
-			Config: m.appEngine.Config,
-		}
-		flushNotify.LoadDatabaseUrls(m.ctx, m.deviceRepo)
+		flushNotify := notify.Notify{Logger: m.logger, Config: m.appEngine.Config}
 		gate.FlushQuietQueue(&flushNotify, data.settings)
 	}
 
@@ -356,17 +393,46 @@ func (m *MissedPingMonitor) checkMissedPings() {
 	for _, device := range data.devices {
 		currentDeviceIDs[device.DeviceID] = true
 		if md := m.checkDevice(&device, data, now); md != nil {
+			if window := models.ActiveMaintenanceWindow(data.maintenance, maintenanceTarget(device), now); window != nil {
+				m.suppressForMaintenance(device, *md, *window, data)
+				continue
+			}
 			missed = append(missed, *md)
 		}
 	}
 
-	if len(missed) > 0 {
-		m.sendMissedPingDigest(missed, data)
+	// one digest per host, so host routing rules apply to missed pings
+	for _, hostMissed := range groupMissedPingsByHost(missed) {
+		m.sendMissedPingDigest(hostMissed, data)
+	}
+	if data.settings != nil && data.settings.Metrics.NotifyOnMissedPingRecovered {
+		for _, recovery := range data.recovered {
+			m.sendMissedPingRecovered(recovery, data)
+		}
 	}
 
 	m.cleanupStaleNotifications(currentDeviceIDs)
 }
 
+// groupMissedPingsByHost splits the missed devices into one group per host, ordered by host ID.
+func groupMissedPingsByHost(devices []notify.MissedPingDigestDevice) [][]notify.MissedPingDigestDevice {
+	byHost := map[string][]notify.MissedPingDigestDevice{}
+	hostIDs := []string{}
+	for _, d := range devices {
+		if _, ok := byHost[d.HostId]; !ok {
+			hostIDs = append(hostIDs, d.HostId)
+		}
+		byHost[d.HostId] = append(byHost[d.HostId], d)
+	}
+	sort.Strings(hostIDs)
+
+	groups := make([][]notify.MissedPingDigestDevice, 0, len(hostIDs))
+	for _, hostID := range hostIDs {
+		groups = append(groups, byHost[hostID])
+	}
+	return groups
+}
+
 func (m *MissedPingMonitor) sendMissedPingDigest(devices []notify.MissedPingDigestDevice, data *checkMissedPingsData) {
 	notification := notify.NewMissedPingDigest(m.logger, m.appEngine.Config, devices, data.timeoutMinutes)
 	notification.LoadDatabaseUrls(m.ctx, m.deviceRepo)
@@ -401,14 +467,53 @@ func (m *MissedPingMonitor) sendMissedPingDigest(devices []notify.MissedPingDige
 	}
 }
 
-func (m *MissedPingMonitor) clearNotificationState(deviceID string) {
+// sendMissedPingRecovered notifies that a device reports again after a missed ping notification.
+func (m *MissedPingMonitor) sendMissedPingRecovered(recovery missedPingRecovery, data *checkMissedPingsData) {
+	notification := notify.NewMissedPingRecovered(m.logger, m.appEngine.Config, &recovery.device, recovery.lastSeen)
+	notification.LoadDatabaseUrls(m.ctx, m.deviceRepo)
+
+	if gate := m.appEngine.NotificationGate; gate != nil {
+		gate.TrySend(&notification, data.settings, false)
+		return
+	}
+	if err := notification.Send(); err != nil {
+		m.logger.Errorf("Failed to send missed ping recovery notification for device %s: %v", recovery.device.DeviceID, err)
+	}
+}
+
+// suppressForMaintenance records a missed ping suppressed by a maintenance window. The device is
+// marked as notified so the suppression is recorded once per cooldown, like a sent alert would be.
+func (m *MissedPingMonitor) suppressForMaintenance(device models.Device, missed notify.MissedPingDigestDevice, window models.MaintenanceWindow, data *checkMissedPingsData) {
+	m.logger.Infof("Missed ping for device %s suppressed by maintenance window %d", device.DeviceID, window.ID)
+	notification := notify.NewMissedPing(m.logger, m.appEngine.Config, device, missed.LastSeen, data.timeoutMinutes)
+	notification.SetHistory(m.deviceRepo)
+	notification.RecordMaintenanceSuppressed(window)
+
+	m.mu.Lock()
+	m.notifiedDevices[device.DeviceID] = time.Now()
+	m.mu.Unlock()
+}
+
+// maintenanceTarget identifies a device for maintenance window matching.
+func maintenanceTarget(device models.Device) models.MaintenanceTarget {
+	return models.MaintenanceTarget{
+		HostID:     strings.TrimSpace(device.HostId),
+		DeviceID:   device.DeviceID,
+		DeviceName: device.DeviceName,
+	}
+}
+
+// clearNotificationState forgets that a device was notified about, and reports whether it was.
+func (m *MissedPingMonitor) clearNotificationState(deviceID string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if _, exists := m.notifiedDevices[deviceID]; exists {
 		delete(m.notifiedDevices, deviceID)
 		m.logger.Debugf("Cleared missed ping notification state for device %s (device is now healthy)", deviceID)
+		return true
 	}
+	return false
 }
 
 // cleanupStaleNotifications removes entries from notifiedDevices for devices that no longer exist
@@ -8,6 +8,7 @@ import (
 	"github.com/analogj/scrutiny/webapp/backend/pkg"
 	mock_config "github.com/analogj/scrutiny/webapp/backend/pkg/config/mock"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
@@ -171,7 +172,35 @@ func TestMissedPingMonitor_ClearNotificationState(t *testing.T) {
 	require.False(t, monitor.IsDeviceNotified("device1"))
 
 	// Clearing non-existent device should not panic
-	monitor.clearNotificationState("non-existent")
+	require.False(t, monitor.clearNotificationState("non-existent"))
+}
+
+func TestMissedPingMonitor_CheckDevice_RecordsRecoveredDevices(t *testing.T) {
+	t.Parallel()
+
+	ae, mockCtrl := createTestAppEngine(t)
+	defer mockCtrl.Finish()
+
+	monitor := NewMissedPingMonitor(ae)
+	monitor.notifiedDevices["notified"] = time.Now().Add(-time.Hour)
+
+	lastSeen := time.Now().Add(-time.Minute)
+	data := &checkMissedPingsData{
+		timeoutMinutes: 60,
+		timeout:        time.Hour,
+		lastSeenTimes: map[string]time.Time{
+			"notified": lastSeen,
+			"healthy":  lastSeen,
+		},
+	}
+
+	require.Nil(t, monitor.checkDevice(&models.Device{DeviceID: "notified", DeviceName: "/dev/sda"}, data, time.Now()))
+	require.Nil(t, monitor.checkDevice(&models.Device{DeviceID: "healthy", DeviceName: "/dev/sdb"}, data, time.Now()))
+
+	require.Len(t, data.recovered, 1)
+	require.Equal(t, "notified", data.recovered[0].device.DeviceID)
+	require.Equal(t, lastSeen, data.recovered[0].lastSeen)
+	require.False(t, monitor.IsDeviceNotified("notified"))
 }
 
 func TestMissedPingMonitor_CheckDevice_ReturnsMissedWhenNoEndpoints(t *testing.T) {
@@ -305,6 +334,73 @@ func TestMissedPingMonitor_CheckDevice_SkipsMuted(t *testing.T) {
 	require.Nil(t, result)
 }
 
+func TestMissedPingMonitor_CheckDevice_SkipsMutedHost(t *testing.T) {
+	t.Parallel()
+
+	ae, mockCtrl := createTestAppEngine(t)
+	defer mockCtrl.Finish()
+
+	monitor := NewMissedPingMonitor(ae)
+
+	device := models.Device{
+		WWN:        "host-muted-device",
+		DeviceID:   "host-muted-device-id",
+		DeviceName: "/dev/sda",
+		HostId:     "nas",
+	}
+
+	data := &checkMissedPingsData{
+		timeoutMinutes: 60,
+		timeout:        60 * time.Minute,
+		hosts: map[string]models.Host{
+			"nas": {HostID: "nas", Muted: true},
+		},
+		lastSeenTimes: map[string]time.Time{
+			"host-muted-device-id": time.Now().Add(-2 * time.Hour),
+		},
+	}
+
+	result := monitor.checkDevice(&device, data, time.Now())
+
+	// Should return nil since the device's host is muted
+	require.Nil(t, result)
+}
+
+func TestMissedPingMonitor_CheckDevice_HostTimeout(t *testing.T) {
+	t.Parallel()
+
+	ae, mockCtrl := createTestAppEngine(t)
+	defer mockCtrl.Finish()
+
+	monitor := NewMissedPingMonitor(ae)
+
+	hosts := map[string]models.Host{
+		"backup": {HostID: "backup", MissedPingTimeoutMinutes: 1440},
+	}
+	lastSeenTimes := map[string]time.Time{
+		"host-timeout-device-id": time.Now().Add(-2 * time.Hour),
+	}
+	data := &checkMissedPingsData{
+		timeoutMinutes: 60,
+		timeout:        60 * time.Minute,
+		hosts:          hosts,
+		lastSeenTimes:  lastSeenTimes,
+	}
+
+	// The host timeout (24h) replaces the global timeout (60m)
+	device := models.Device{
+		WWN:        "host-timeout-device",
+		DeviceID:   "host-timeout-device-id",
+		DeviceName: "/dev/sda",
+		HostId:     "backup",
+	}
+	require.Nil(t, monitor.checkDevice(&device, data, time.Now()))
+
+	// A per-device override still takes precedence over the host timeout
+	device.MissedPingTimeoutOverride = 30
+	require.NotNil(t, monitor.checkDevice(&device, data, time.Now()))
+}
+
 func TestMissedPingMonitor_CheckDevice_SkipsNewlyRegistered(t *testing.T) {
 	t.Parallel()
 
@@ -446,3 +542,17 @@ func TestMissedPingMonitor_ResetRepo(t *testing.T) {
 	// Still nil
 	require.Nil(t, monitor.deviceRepo)
 }
+
+func TestGroupMissedPingsByHost(t *testing.T) {
+	groups := groupMissedPingsByHost([]notify.MissedPingDigestDevice{
+		{DeviceID: "a", HostId: "nas-2"},
+		{DeviceID: "b", HostId: "nas-1"},
+		{DeviceID: "c", HostId: "nas-2"},
+		{DeviceID: "d"},
+	})
+
+	require.Len(t, groups, 3)
+	require.Equal(t, []notify.MissedPingDigestDevice{{DeviceID: "d"}}, groups[0])
+	require.Equal(t, []notify.MissedPingDigestDevice{{DeviceID: "b", HostId: "nas-1"}}, groups[1])
+	require.Equal(t, []notify.MissedPingDigestDevice{{DeviceID: "a", HostId: "nas-2"}, {DeviceID: "c", HostId: "nas-2"}}, groups[2])
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/web/handler"
+)
+
+// mqttAcknowledgedBy is recorded on incidents acknowledged with the MQTT command.
+const mqttAcknowledgedBy = "mqtt"
+
+// mqttCommandActions performs the MQTT commands with the same repository calls as the matching
+// API endpoints. Commands are rare, so each one opens its own repository connection.
+type mqttCommandActions struct {
+	appEngine *AppEngine
+}
+
+func (a *mqttCommandActions) withRepo(fn func(deviceRepo database.DeviceRepo) error) error {
+	deviceRepo, err := database.NewScrutinyRepositoryWithoutMigration(a.appEngine.Config, a.appEngine.Logger)
+	if err != nil {
+		return fmt.Errorf("could not connect to the database: %w", err)
+	}
+	defer deviceRepo.Close()
+	return fn(deviceRepo)
+}
+
+// resolveDevice looks a device up by device_id, falling back to the legacy WWN like ResolveDevice.
+func resolveDevice(ctx context.Context, deviceRepo database.DeviceRepo, id string) (models.Device, error) {
+	device, err := deviceRepo.GetDeviceDetails(ctx, id)
+	if err == nil {
+		return device, nil
+	}
+	if device, wwnErr := deviceRepo.GetDeviceByWWN(ctx, id); wwnErr == nil {
+		return device, nil
+	}
+	return models.Device{}, fmt.Errorf("device not found: %s", id)
+}
+
+func (a *mqttCommandActions) RunCollectors(_ context.Context) error {
+	return handler.RunCollectors(a.appEngine.Logger)
+}
+
+func (a *mqttCommandActions) RequestReport(ctx context.Context, period string) error {
+	if a.appEngine.ReportScheduler == nil {
+		return fmt.Errorf("the report scheduler is not running")
+	}
+	_, err := a.appEngine.ReportScheduler.SendTestReport(ctx, period, models.TagFilter{})
+	return err
+}
+
+func (a *mqttCommandActions) AcknowledgeAlerts(ctx context.Context, incidentID uint) (int, error) {
+	acknowledged := 0
+	err := a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		ids := []uint{incidentID}
+		if incidentID == 0 {
+			open, err := deviceRepo.GetIncidents(ctx, models.IncidentStatusOpen)
+			if err != nil {
+				return err
+			}
+			ids = ids[:0]
+			for _, incident := range open {
+				ids = append(ids, incident.ID)
+			}
+		}
+		for _, id := range ids {
+			if _, err := deviceRepo.AcknowledgeIncident(ctx, id, mqttAcknowledgedBy, "", time.Now().UTC()); err != nil {
+				return fmt.Errorf("could not acknowledge incident %d: %w", id, err)
+			}
+			acknowledged++
+		}
+		return nil
+	})
+	return acknowledged, err
+}
+
+func (a *mqttCommandActions) SetDeviceMuted(ctx context.Context, deviceID string, muted bool) error {
+	return a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		device, err := resolveDevice(ctx, deviceRepo, deviceID)
+		if err != nil {
+			return err
+		}
+		return deviceRepo.UpdateDeviceMuted(ctx, device.DeviceID, muted)
+	})
+}
+
+func (a *mqttCommandActions) ResetDeviceStatus(ctx context.Context, deviceID string) error {
+	return a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		device, err := resolveDevice(ctx, deviceRepo, deviceID)
+		if err != nil {
+			return err
+		}
+		resetDevice, err := deviceRepo.ResetDeviceStatus(ctx, device.DeviceID)
+		if err != nil {
+			return err
+		}
+		handler.NotifyDeviceRecovered(ctx, a.appEngine.Logger, a.appEngine.Config, deviceRepo, a.appEngine.NotificationGate, &resetDevice, device.DeviceStatus)
+		return nil
+	})
+}
+
+func (a *mqttCommandActions) SetZFSPoolMuted(ctx context.Context, guid string, muted bool) error {
+	return a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		return deviceRepo.UpdateZFSPoolMuted(ctx, guid, muted)
+	})
+}
+
+func (a *mqttCommandActions) SetMdadmArrayMuted(ctx context.Context, uuid string, muted bool) error {
+	return a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		return deviceRepo.UpdateMdadmArrayMuted(ctx, uuid, muted)
+	})
+}
+
+func (a *mqttCommandActions) SetBtrfsFilesystemMuted(ctx context.Context, uuid string, muted bool) error {
+	return a.withRepo(func(deviceRepo database.DeviceRepo) error {
+		return deviceRepo.UpdateBtrfsFilesystemMuted(ctx, uuid, muted)
+	})
+}
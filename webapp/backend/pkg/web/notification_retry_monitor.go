@@ -0,0 +1,209 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultNotificationRetryInterval is how often the retry queue is checked for due deliveries
+	DefaultNotificationRetryInterval = time.Minute
+
+	// notificationRetryBatchSize caps the deliveries retried per check
+	notificationRetryBatchSize = 50
+)
+
+// NotificationRetryMonitor resends notifications whose delivery failed, backing off exponentially
+// per target, and dead-letters them once they are older than notify.retry.max_age_hours.
+type NotificationRetryMonitor struct {
+	appEngine *AppEngine
+	logger    logrus.FieldLogger
+
+	// Persistent repository connection (created once, reused)
+	deviceRepo database.DeviceRepo
+	repoMu     sync.Mutex
+
+	// Channel to signal shutdown and context for cancellation
+	stopCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// WaitGroup to track when the run goroutine has finished
+	wg sync.WaitGroup
+}
+
+// NewNotificationRetryMonitor creates a new notification retry monitor
+func NewNotificationRetryMonitor(ae *AppEngine) *NotificationRetryMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &NotificationRetryMonitor{
+		appEngine: ae,
+		logger:    ae.Logger,
+		stopCh:    make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the background retry loop
+func (m *NotificationRetryMonitor) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop signals the monitor to stop and waits for it to finish
+func (m *NotificationRetryMonitor) Stop() {
+	m.logger.Debug("Stopping notification retry monitor...")
+	m.cancel()
+	close(m.stopCh)
+	m.wg.Wait()
+
+	// Close the persistent repository connection if it exists
+	m.repoMu.Lock()
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+	m.repoMu.Unlock()
+
+	m.logger.Info("Notification retry monitor stopped")
+}
+
+func (m *NotificationRetryMonitor) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(DefaultNotificationRetryInterval)
+	defer ticker.Stop()
+
+	m.logger.Infof("Notification retry monitor started with check interval: %v", DefaultNotificationRetryInterval)
+
+	// export the queue depth right away instead of after the first interval
+	m.processRetries(time.Now())
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.processRetries(time.Now())
+		}
+	}
+}
+
+// getOrCreateRepo returns the persistent repository, creating it if necessary
+func (m *NotificationRetryMonitor) getOrCreateRepo() (database.DeviceRepo, error) {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		return m.deviceRepo, nil
+	}
+
+	repo, err := database.NewScrutinyRepositoryWithoutMigration(m.appEngine.Config, m.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m.deviceRepo = repo
+	return m.deviceRepo, nil
+}
+
+// resetRepo closes and clears the persistent repository (called on connection errors)
+func (m *NotificationRetryMonitor) resetRepo() {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+}
+
+func (m *NotificationRetryMonitor) processRetries(now time.Time) {
+	if m.ctx.Err() != nil {
+		return
+	}
+
+	deviceRepo, err := m.getOrCreateRepo()
+	if err != nil {
+		m.logger.Errorf("Failed to get/create repository for notification retries: %v", err)
+		return
+	}
+
+	retries, err := deviceRepo.GetDueNotificationRetries(m.ctx, now, notificationRetryBatchSize)
+	if err != nil {
+		m.resetRepo()
+		m.logger.Errorf("Failed to load notification retries: %v", err)
+		return
+	}
+	for _, retry := range retries {
+		if m.ctx.Err() != nil {
+			return
+		}
+		m.retry(deviceRepo, retry, now)
+	}
+
+	if collector := m.appEngine.MetricsCollector; collector != nil {
+		if err := collector.RefreshNotificationQueueMetrics(deviceRepo, m.ctx); err != nil {
+			m.logger.Warnf("Failed to refresh notification queue metrics: %v", err)
+		}
+	}
+}
+
+func (m *NotificationRetryMonitor) retry(deviceRepo database.DeviceRepo, retry models.NotificationRetry, now time.Time) {
+	notification, err := notify.NewRetry(m.logger, m.appEngine.Config, retry)
+	if err != nil {
+		// a queued entry that cannot be decoded will never be delivered
+		advanceNotificationRetry(&retry, err, now, 0)
+		m.logger.Errorf("Dead-lettering notification %d: %v", retry.ID, err)
+		if updateErr := deviceRepo.UpdateNotificationRetry(m.ctx, &retry); updateErr != nil {
+			m.logger.Errorf("Failed to update notification retry %d: %v", retry.ID, updateErr)
+		}
+		return
+	}
+	notification.SetHistory(deviceRepo)
+	err = notification.SendRetry()
+	if err == nil {
+		if deleteErr := deviceRepo.DeleteNotificationRetry(m.ctx, retry.ID); deleteErr != nil {
+			m.logger.Errorf("Failed to remove delivered notification %d from the retry queue: %v", retry.ID, deleteErr)
+		}
+		m.logger.Infof("Delivered queued notification %d after %d failed attempt(s)", retry.ID, retry.Attempts+1)
+		return
+	}
+
+	maxAge := notify.RetryMaxAge()
+	if !notify.IsRetryable(err) {
+		// e.g. the receiver now rejects the request, further attempts would fail the same way
+		maxAge = 0
+	}
+	if advanceNotificationRetry(&retry, err, now, maxAge) {
+		notification.RecordDeadLetter(err)
+		m.logger.Warnf("Giving up on notification %d to %s after %d attempt(s): %v", retry.ID, notify.MaskNotifyUrl(retry.URL), retry.Attempts, err)
+	} else {
+		m.logger.Infof("Retry of notification %d failed, next attempt at %s: %v", retry.ID, retry.NextAttemptAt.Format(time.RFC3339), err)
+	}
+	if updateErr := deviceRepo.UpdateNotificationRetry(m.ctx, &retry); updateErr != nil {
+		m.logger.Errorf("Failed to update notification retry %d: %v", retry.ID, updateErr)
+	}
+}
+
+// advanceNotificationRetry records a failed attempt and schedules the next one with exponential
+// backoff. Retries older than maxAge become dead letters; it reports whether that happened.
+func advanceNotificationRetry(retry *models.NotificationRetry, err error, now time.Time, maxAge time.Duration) bool {
+	retry.Attempts++
+	if err != nil {
+		retry.LastError = err.Error()
+	}
+	if maxAge <= 0 || now.Sub(retry.CreatedAt) >= maxAge {
+		retry.Status = models.NotificationRetryDead
+		retry.DeadAt = &now
+		return true
+	}
+	retry.NextAttemptAt = now.Add(notify.RetryBackoff(retry.Attempts + 1))
+	return false
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvanceNotificationRetry_BacksOff(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	retry := models.NotificationRetry{CreatedAt: now.Add(-10 * time.Minute), Status: models.NotificationRetryPending}
+
+	require.False(t, advanceNotificationRetry(&retry, errors.New("connection refused"), now, 24*time.Hour))
+	require.Equal(t, 1, retry.Attempts)
+	require.Equal(t, now.Add(2*time.Minute), retry.NextAttemptAt)
+	require.Equal(t, "connection refused", retry.LastError)
+	require.Equal(t, models.NotificationRetryPending, retry.Status)
+
+	require.False(t, advanceNotificationRetry(&retry, errors.New("connection refused"), now, 24*time.Hour))
+	require.Equal(t, now.Add(4*time.Minute), retry.NextAttemptAt)
+}
+
+func TestAdvanceNotificationRetry_DeadLettersAfterMaxAge(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	retry := models.NotificationRetry{CreatedAt: now.Add(-25 * time.Hour), Status: models.NotificationRetryPending, Attempts: 30}
+
+	require.True(t, advanceNotificationRetry(&retry, errors.New("status 503"), now, 24*time.Hour))
+	require.Equal(t, models.NotificationRetryDead, retry.Status)
+	require.NotNil(t, retry.DeadAt)
+	require.Equal(t, now, *retry.DeadAt)
+	require.Equal(t, 31, retry.Attempts)
+}
@@ -13,9 +13,11 @@ import (
 	"time"
 
 	"github.com/analogj/go-util/utils"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/backup"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/config"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/errors"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/events"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/metrics"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/mqtt"
 	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
@@ -27,6 +29,7 @@ import (
 )
 
 const configKeyMetricsEnabled = "web.metrics.enabled"
+const configKeyOTLPEnabled = "web.metrics.otlp.enabled"
 const configKeyMqttEnabled = "web.mqtt.enabled"
 const indexFile = "index.html"
 const apiDocsDirName = "docs"
@@ -38,12 +41,23 @@ type AppEngine struct {
 	Config            config.Interface
 	Logger            *logrus.Entry
 	MetricsCollector  *metrics.Collector
+	OTLPExporter      *metrics.OTLPExporter
 	MqttPublisher     *mqtt.Publisher
 	NotificationGate  *notify.NotificationGate
+	EventBus          *events.Bus
 	MissedPingMonitor *MissedPingMonitor
 	HeartbeatMonitor  *HeartbeatMonitor
 	UptimeKumaMonitor *UptimeKumaMonitor
+	WarrantyMonitor   *WarrantyMonitor
+	IncidentMonitor   *IncidentMonitor
+	RetryMonitor      *NotificationRetryMonitor
 	ReportScheduler   *reports.Scheduler
+	BackupScheduler   *backup.Scheduler
+
+	// notificationRepo backs the notification gate's history and quiet hours queue
+	notificationRepo database.DeviceRepo
+	// stopSmartForward drops the SMART points still waiting to be forwarded
+	stopSmartForward context.CancelFunc
 }
 
 func (ae *AppEngine) registerMiddleware(r *gin.Engine, logger *logrus.Entry) {
@@ -52,7 +66,12 @@ func (ae *AppEngine) registerMiddleware(r *gin.Engine, logger *logrus.Entry) {
 	r.Use(middleware.ConfigMiddleware(ae.Config))
 	r.Use(middleware.AuthMiddleware(ae.Config, logger))
 
+	if ae.EventBus == nil {
+		ae.EventBus = events.NewBus(logger)
+	}
+	r.Use(middleware.EventBusMiddleware(ae.EventBus))
 	if ae.NotificationGate != nil {
+		ae.NotificationGate.SetEventBus(ae.EventBus)
 		r.Use(middleware.NotificationGateMiddleware(ae.NotificationGate))
 	}
 	if ae.MissedPingMonitor != nil {
@@ -62,11 +81,13 @@ func (ae *AppEngine) registerMiddleware(r *gin.Engine, logger *logrus.Entry) {
 		r.Use(middleware.ReportSchedulerMiddleware(ae.ReportScheduler))
 	}
 
-	if ae.Config.GetBool(configKeyMetricsEnabled) {
+	if ae.metricsCollectorEnabled() {
 		if ae.MetricsCollector == nil {
 			ae.MetricsCollector = metrics.NewCollector(logger)
 		}
 		r.Use(middleware.MetricsMiddleware(ae.MetricsCollector))
+	}
+	if ae.Config.GetBool(configKeyMetricsEnabled) {
 		logger.Info("Prometheus metrics endpoint enabled")
 	} else {
 		logger.Info("Prometheus metrics endpoint disabled")
@@ -82,6 +103,14 @@ func (ae *AppEngine) registerMiddleware(r *gin.Engine, logger *logrus.Entry) {
 		} else {
 			r.Use(middleware.MqttPublisherMiddleware(ae.MqttPublisher))
 			logger.Info("MQTT Home Assistant integration enabled")
+			if ae.Config.GetBool("web.mqtt.commands.enabled") {
+				allowlist := ae.Config.GetStringSlice("web.mqtt.commands.allowed_topics")
+				if err := ae.MqttPublisher.EnableCommands(&mqttCommandActions{appEngine: ae}, allowlist); err != nil {
+					logger.Errorf("Failed to subscribe to MQTT command topics: %v", err)
+				} else {
+					logger.Infof("MQTT commands enabled for %d allowed topics", len(allowlist))
+				}
+			}
 		}
 	}
 
@@ -122,13 +151,41 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 			api.POST("/health/uptime-kuma-test", handler.TestUptimeKumaPush)   // test Uptime Kuma push monitor
 			api.POST("/health/mqtt-sync", handler.MqttSync)                    // re-sync all MQTT discovery entities with HA
 
-			api.POST("/devices/register", handler.RegisterDevices)            // used by Collector to register new devices and retrieve filtered list
-			api.GET(apiSummaryPath, handler.GetDevicesSummary)                // used by Dashboard
-			api.GET("/summary/temp", handler.GetDevicesSummaryTempHistory)    // used by Dashboard (Temperature history dropdown)
-			api.GET("/summary/workload", handler.GetWorkloadInsights)         // used by Workload Insights page
-			api.GET("/filesystems/summary", handler.GetFilesystemSummary)     // used by Dashboard filesystem capacity panel
-			api.POST("/filesystems/summary", handler.UploadFilesystemSummary) // used by Filesystem Collector to upload data
-			api.POST("/collectors/run", handler.TriggerCollectors)            // used by Dashboard to trigger local collectors in omnibus mode
+			api.POST("/devices/register", handler.RegisterDevices)                 // used by Collector to register new devices and retrieve filtered list
+			api.GET("/devices/lifecycle-events", handler.GetDeviceLifecycleEvents) // used by UI/API to list devices added, disappeared or swapped on a host
+			api.GET(apiSummaryPath, handler.GetDevicesSummary)                     // used by Dashboard
+			api.GET("/summary/temp", handler.GetDevicesSummaryTempHistory)         // used by Dashboard (Temperature history dropdown)
+			api.GET("/summary/workload", handler.GetWorkloadInsights)              // used by Workload Insights page
+			api.GET("/events", handler.GetEvents)                                  // used by UI/API to stream events over SSE or WebSocket
+			api.GET("/filesystems/summary", handler.GetFilesystemSummary)          // used by Dashboard filesystem capacity panel
+			api.POST("/filesystems/summary", handler.UploadFilesystemSummary)      // used by Filesystem Collector to upload data
+			api.POST("/collectors/run", handler.TriggerCollectors)                 // used by Dashboard to trigger local collectors in omnibus mode
+
+			api.POST("/devices/inventory/import", handler.ImportDeviceInventory) // used by UI/API to bulk import inventory fields by serial number
+
+			api.GET("/replacements", handler.GetDeviceReplacements)          // used by UI/API to list drive replacements and failure statistics
+			api.DELETE("/replacements/:id", handler.DeleteDeviceReplacement) // used by UI/API to remove a mistaken replacement record
+			api.GET("/lineage", handler.GetLineages)                         // used by UI/API to follow a bay or pool member across drive swaps
+
+			api.GET("/hosts", handler.GetHosts)                         // used by UI/API to list hosts with device, pool and filesystem status
+			api.GET("/host/:host_id", handler.GetHost)                  // used by UI/API to view a host and its collector check-ins
+			api.POST("/host/:host_id", handler.UpdateHost)              // used by UI/API to set host label, description and missed ping timeout
+			api.POST("/host/:host_id/mute", handler.MuteHost)           // used by UI to mute a host and everything on it
+			api.POST("/host/:host_id/unmute", handler.UnmuteHost)       // used by UI to unmute a host and everything on it
+			api.POST("/host/:host_id/archive", handler.ArchiveHost)     // used by UI to archive a host and everything on it
+			api.POST("/host/:host_id/unarchive", handler.UnarchiveHost) // used by UI to unarchive a host and everything on it
+			api.POST("/host/:host_id/rename", handler.RenameHost)       // used by UI/API to rename a host and its child records
+
+			api.GET("/tags", handler.GetTags) // used by UI/API to list tags with the number of tagged devices, pools and arrays
+
+			api.GET("/incidents", handler.GetIncidents)                           // used by UI/API to list incidents, optionally by status
+			api.GET("/incident/:id", handler.GetIncident)                         // used by UI/API to view a single incident
+			api.POST("/incident/:id/ack", handler.AcknowledgeIncident)            // used by UI/API to acknowledge an incident and stop its notifications
+			api.POST("/incident/:id/resolve", handler.ResolveIncident)            // used by UI/API to resolve an incident manually
+			api.GET("/maintenance-windows", handler.GetMaintenanceWindows)        // used by UI/API to list maintenance windows, optionally only active ones
+			api.POST("/maintenance-windows", handler.CreateMaintenanceWindow)     // used by UI/API to schedule a maintenance window
+			api.GET("/maintenance-window/:id", handler.GetMaintenanceWindow)      // used by UI/API to view a single maintenance window
+			api.POST("/maintenance-window/:id/end", handler.EndMaintenanceWindow) // used by UI/API to end a maintenance window early
 
 			// Prometheus metrics endpoint (only registered if enabled)
 			if ae.Config.GetBool(configKeyMetricsEnabled) {
@@ -139,16 +196,21 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 			api.POST("/device/:id/selftest", handler.UploadDeviceSelfTests)
 			api.GET("/device/:id/selftest", handler.GetDeviceSelfTests)                        // used by UI to view self-test history
 			api.GET("/device/:id/details", handler.GetDeviceDetails)                           // used by Details
+			api.GET("/device/:id/export", handler.ExportDevice)                                // used by UI/API to download the full device history as CSV or JSON
 			api.POST("/device/:id/archive", handler.ArchiveDevice)                             // used by UI to archive device
 			api.POST("/device/:id/unarchive", handler.UnarchiveDevice)                         // used by UI to unarchive device
 			api.POST("/device/:id/mute", handler.MuteDevice)                                   // used by UI to mute device
 			api.POST("/device/:id/unmute", handler.UnmuteDevice)                               // used by UI to unmute device
 			api.POST("/device/:id/reset-status", handler.ResetDeviceStatus)                    // used by UI to reset device failed status
 			api.POST("/device/:id/label", handler.UpdateDeviceLabel)                           // used by UI to set device label
+			api.POST("/device/:id/tags", handler.UpdateDeviceTags)                             // used by UI/API to replace device tags
 			api.POST("/device/:id/max-tbw", handler.UpdateDeviceMaxTBW)                        // used by UI to set per-device rated TBW
+			api.POST("/device/:id/inventory", handler.UpdateDeviceInventory)                   // used by UI to set purchase, warranty and location details
 			api.POST("/device/:id/smart-display-mode", handler.UpdateDeviceSmartDisplayMode)   // used by UI to set SMART attribute display mode
 			api.POST("/device/:id/missed-ping-timeout", handler.UpdateDeviceMissedPingTimeout) // used by UI to set per-device missed ping timeout override
 			api.POST("/device/:id/merge_into", handler.MergeDeviceInto)                        // used by API/CLI to merge duplicate devices
+			api.POST("/device/:id/replaced-by", handler.RecordDeviceReplacement)               // used by UI/API to record that a device was swapped for another
+			api.GET("/device/:id/lineage", handler.GetDeviceLineage)                           // used by UI/API to view the replacement history of a device
 			api.DELETE("/device/:id", handler.DeleteDevice)                                    // used by UI to delete device
 			api.POST("/device/:id/performance", handler.UploadDevicePerformance)               // used by Collector to upload performance benchmarks
 			api.GET("/device/:id/performance", handler.GetDevicePerformance)                   // used by UI to view performance history
@@ -171,6 +233,11 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 			api.DELETE("/settings/notify-urls/:id", handler.DeleteNotifyUrl)
 			api.POST("/settings/notify-urls/:id/test", handler.TestNotifyUrl)
 			api.PATCH("/settings/notify-urls/:id", handler.UpdateNotifyUrlHeartbeat)
+			api.PUT("/settings/notify-urls/:id/rules", handler.UpdateNotifyUrlRules)
+			api.PUT("/settings/notify-urls/:id/escalation", handler.UpdateNotifyUrlEscalation)
+			api.GET("/notifications/history", handler.GetNotificationHistory)                 // used by UI/API to audit notification delivery
+			api.GET("/notifications/retries", handler.GetNotificationRetries)                 // used by UI/API to inspect the retry queue and dead letters
+			api.POST("/notifications/templates/preview", handler.PreviewNotificationTemplate) // used by UI/API to render notification templates against a device
 
 			// Scheduled report endpoints
 			api.GET("/reports/generate", handler.GenerateReport)
@@ -188,6 +255,7 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 				zfs.POST("/pool/:guid/mute", handler.MuteZFSPool)             // used by UI to mute pool
 				zfs.POST("/pool/:guid/unmute", handler.UnmuteZFSPool)         // used by UI to unmute pool
 				zfs.POST("/pool/:guid/label", handler.UpdateZFSPoolLabel)     // used by UI to set pool label
+				zfs.POST("/pool/:guid/tags", handler.UpdateZFSPoolTags)       // used by UI/API to replace pool tags
 				zfs.DELETE("/pool/:guid", handler.DeleteZFSPool)              // used by UI to delete pool
 			}
 
@@ -202,6 +270,7 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 				btrfs.POST("/filesystem/:uuid/mute", handler.MuteBtrfsFilesystem)
 				btrfs.POST("/filesystem/:uuid/unmute", handler.UnmuteBtrfsFilesystem)
 				btrfs.POST("/filesystem/:uuid/label", handler.UpdateBtrfsFilesystemLabel)
+				btrfs.POST("/filesystem/:uuid/tags", handler.UpdateBtrfsFilesystemTags)
 				btrfs.DELETE("/filesystem/:uuid", handler.DeleteBtrfsFilesystem)
 			}
 
@@ -212,6 +281,7 @@ func (ae *AppEngine) Setup(logger *logrus.Entry) *gin.Engine {
 				mdadm.GET(apiSummaryPath, handler.GetMdadmSummary)              // used by Dashboard
 				mdadm.POST("/array/:uuid/metrics", handler.UploadMdadmMetrics)  // used by Collector to upload metrics
 				mdadm.GET("/array/:uuid/details", handler.GetMdadmArrayDetails) // used by Array Details view
+				mdadm.POST("/array/:uuid/tags", handler.UpdateMdadmArrayTags)   // used by UI/API to replace array tags
 			}
 		}
 	}
@@ -330,6 +400,14 @@ func (ae *AppEngine) Start() error {
 			filepath.Dir(ae.Config.GetString("web.database.location"))))
 	}
 
+	// validate web.smart_forward once, every repository shares the forwarder
+	smartForwardCtx, stopSmartForward := context.WithCancel(context.Background())
+	if err := database.StartSmartForwarder(smartForwardCtx, ae.Config, ae.Logger); err != nil {
+		stopSmartForward()
+		return err
+	}
+	ae.stopSmartForward = stopSmartForward
+
 	migrationRepo, err := database.NewScrutinyRepository(ae.Config, ae.Logger)
 	if err != nil {
 		return err
@@ -338,8 +416,19 @@ func (ae *AppEngine) Start() error {
 		ae.Logger.Warnf("Failed to close migration repository: %v", err)
 	}
 
+	notify.ConfigureTemplates(ae.Config.GetString("notify.template_dir"))
+	notify.ConfigureRetries(time.Duration(ae.Config.GetInt("notify.retry.max_age_hours")) * time.Hour)
+
 	// Create notification gate and monitors BEFORE Setup() so middleware can register them in gin context
 	ae.NotificationGate = notify.NewNotificationGate(ae.Logger)
+	notificationRepo, err := database.NewScrutinyRepositoryWithoutMigration(ae.Config, ae.Logger)
+	if err != nil {
+		return err
+	}
+	ae.notificationRepo = notificationRepo
+	if err := ae.NotificationGate.SetStore(context.Background(), notificationRepo); err != nil {
+		ae.Logger.Warnf("Failed to restore the quiet hours queue, notification history will not be recorded by the gate: %v", err)
+	}
 
 	missedPingMonitor := NewMissedPingMonitor(ae)
 	ae.MissedPingMonitor = missedPingMonitor
@@ -351,6 +440,15 @@ func (ae *AppEngine) Start() error {
 
 	r := ae.Setup(ae.Logger)
 
+	if ae.MqttPublisher != nil && ae.Config.GetBool("web.mqtt.collector_transport.enabled") {
+		handler := collectorTransportHandler(r, ae.Config.GetString("web.listen.basepath"))
+		if err := ae.MqttPublisher.EnableCollectorTransport(handler); err != nil {
+			ae.Logger.Errorf("Failed to subscribe to MQTT collector request topics: %v", err)
+		} else {
+			ae.Logger.Info("MQTT collector transport enabled")
+		}
+	}
+
 	// Start background monitors after router is set up
 	missedPingMonitor.Start()
 	ae.Logger.Info("Missed ping monitor started")
@@ -365,9 +463,42 @@ func (ae *AppEngine) Start() error {
 	uptimeKumaMonitor.Start()
 	ae.Logger.Info("Uptime Kuma monitor started")
 
+	warrantyMonitor := NewWarrantyMonitor(ae)
+	ae.WarrantyMonitor = warrantyMonitor
+	warrantyMonitor.Start()
+	ae.Logger.Info("Warranty monitor started")
+
+	incidentMonitor := NewIncidentMonitor(ae)
+	ae.IncidentMonitor = incidentMonitor
+	incidentMonitor.Start()
+	ae.Logger.Info("Incident monitor started")
+
+	if notify.RetryMaxAge() > 0 {
+		retryMonitor := NewNotificationRetryMonitor(ae)
+		ae.RetryMonitor = retryMonitor
+		retryMonitor.Start()
+		ae.Logger.Info("Notification retry monitor started")
+	}
+
 	reportScheduler.Start()
 	ae.Logger.Info("Report scheduler started")
 
+	backupScheduler := backup.NewScheduler(ae.Config, ae.Logger, func() (database.DeviceRepo, error) {
+		return database.NewScrutinyRepositoryWithoutMigration(ae.Config, ae.Logger)
+	})
+	ae.BackupScheduler = backupScheduler
+	backupScheduler.Start()
+	ae.Logger.Info("Backup scheduler started")
+
+	if ae.Config.GetBool(configKeyOTLPEnabled) && ae.MetricsCollector != nil {
+		otlpExporter, err := metrics.NewOTLPExporter(context.Background(), ae.Config, ae.MetricsCollector, ae.Logger)
+		if err != nil {
+			ae.Logger.Errorf("Failed to start the OTLP metrics exporter: %v (OTLP export disabled)", err)
+		} else {
+			ae.OTLPExporter = otlpExporter
+		}
+	}
+
 	ae.loadInitialMetrics()
 	ae.loadInitialMqttData()
 
@@ -419,8 +550,13 @@ func (ae *AppEngine) Start() error {
 	return nil
 }
 
+// metricsCollectorEnabled returns true if the metrics are served on /api/metrics or pushed over OTLP.
+func (ae *AppEngine) metricsCollectorEnabled() bool {
+	return ae.Config.GetBool(configKeyMetricsEnabled) || ae.Config.GetBool(configKeyOTLPEnabled)
+}
+
 func (ae *AppEngine) loadInitialMetrics() {
-	if !ae.Config.GetBool(configKeyMetricsEnabled) || ae.MetricsCollector == nil {
+	if !ae.metricsCollectorEnabled() || ae.MetricsCollector == nil {
 		return
 	}
 	go func() {
@@ -468,7 +604,39 @@ func (ae *AppEngine) stopBackgroundMonitors() {
 	if ae.UptimeKumaMonitor != nil {
 		ae.UptimeKumaMonitor.Stop()
 	}
+	if ae.WarrantyMonitor != nil {
+		ae.WarrantyMonitor.Stop()
+	}
+	if ae.IncidentMonitor != nil {
+		ae.IncidentMonitor.Stop()
+	}
+	if ae.RetryMonitor != nil {
+		ae.RetryMonitor.Stop()
+	}
 	if ae.ReportScheduler != nil {
 		ae.ReportScheduler.Stop()
 	}
+	if ae.BackupScheduler != nil {
+		ae.BackupScheduler.Stop()
+	}
+	if ae.OTLPExporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ae.OTLPExporter.Shutdown(ctx)
+		cancel()
+	}
+	if ae.NotificationGate != nil {
+		ae.NotificationGate.FlushDigests(time.Now(), true)
+	}
+	if ae.stopSmartForward != nil {
+		ae.stopSmartForward()
+		ae.stopSmartForward = nil
+	}
+	if ae.notificationRepo != nil {
+		ae.notificationRepo.Close()
+		ae.notificationRepo = nil
+	}
+	if ae.EventBus != nil {
+		// ends the open event streams, so the graceful shutdown does not wait for them
+		ae.EventBus.Close()
+	}
 }
@@ -0,0 +1,216 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/database"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/analogj/scrutiny/webapp/backend/pkg/notify"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultWarrantyCheckInterval is how often device warranties are checked for upcoming expiry
+	DefaultWarrantyCheckInterval = 6 * time.Hour
+)
+
+// WarrantyMonitor sends a notification once per device when its warranty expiry date is within the
+// configured number of days (metrics.warranty_notify_days), and once more when the warranty has
+// expired. The sent state is persisted on the device and re-armed whenever the warranty expiry date
+// changes.
+type WarrantyMonitor struct {
+	appEngine *AppEngine
+	logger    logrus.FieldLogger
+
+	// Persistent repository connection (created once, reused)
+	deviceRepo database.DeviceRepo
+	repoMu     sync.Mutex
+
+	// Channel to signal shutdown and context for cancellation
+	stopCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// WaitGroup to track when the run goroutine has finished
+	wg sync.WaitGroup
+}
+
+// NewWarrantyMonitor creates a new warranty expiry monitor
+func NewWarrantyMonitor(ae *AppEngine) *WarrantyMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WarrantyMonitor{
+		appEngine: ae,
+		logger:    ae.Logger,
+		stopCh:    make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the background warranty check loop
+func (m *WarrantyMonitor) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop signals the monitor to stop and waits for it to finish
+func (m *WarrantyMonitor) Stop() {
+	m.logger.Debug("Stopping warranty monitor...")
+	m.cancel()
+	close(m.stopCh)
+	m.wg.Wait()
+
+	// Close the persistent repository connection if it exists
+	m.repoMu.Lock()
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+	m.repoMu.Unlock()
+
+	m.logger.Info("Warranty monitor stopped")
+}
+
+func (m *WarrantyMonitor) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(DefaultWarrantyCheckInterval)
+	defer ticker.Stop()
+
+	m.logger.Infof("Warranty monitor started with check interval: %v", DefaultWarrantyCheckInterval)
+
+	// Check once at startup so expiring warranties are not held back until the first tick
+	m.checkWarranties(time.Now())
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.checkWarranties(time.Now())
+		}
+	}
+}
+
+// getOrCreateRepo returns the persistent repository, creating it if necessary
+func (m *WarrantyMonitor) getOrCreateRepo() (database.DeviceRepo, error) {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		return m.deviceRepo, nil
+	}
+
+	repo, err := database.NewScrutinyRepositoryWithoutMigration(m.appEngine.Config, m.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m.deviceRepo = repo
+	return m.deviceRepo, nil
+}
+
+// resetRepo closes and clears the persistent repository (called on connection errors)
+func (m *WarrantyMonitor) resetRepo() {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if m.deviceRepo != nil {
+		m.deviceRepo.Close()
+		m.deviceRepo = nil
+	}
+}
+
+func (m *WarrantyMonitor) checkWarranties(now time.Time) {
+	if m.ctx.Err() != nil {
+		return
+	}
+
+	deviceRepo, err := m.getOrCreateRepo()
+	if err != nil {
+		m.logger.Errorf("Failed to get/create repository for warranty check: %v", err)
+		return
+	}
+
+	settings, err := deviceRepo.LoadSettings(m.ctx)
+	if err != nil {
+		m.resetRepo()
+		m.logger.Errorf("Failed to load settings for warranty check: %v", err)
+		return
+	}
+	if settings == nil || !settings.Metrics.NotifyOnWarrantyExpiry {
+		m.logger.Debug("Warranty expiry notifications are disabled")
+		return
+	}
+
+	devices, err := deviceRepo.GetDevices(m.ctx)
+	if err != nil {
+		m.resetRepo()
+		m.logger.Errorf("Failed to load devices for warranty check: %v", err)
+		return
+	}
+
+	for _, device := range dueWarrantyNotifications(devices, now, settings.Metrics.WarrantyNotifyDays) {
+		m.sendWarrantyNotification(deviceRepo, device, now, settings)
+	}
+}
+
+// dueWarrantyNotifications returns the monitored devices whose warranty expires within notifyDays
+// days, or has already expired, and that have not been notified of that state for the current
+// expiry date yet.
+func dueWarrantyNotifications(devices []models.Device, now time.Time, notifyDays int) []models.Device {
+	due := []models.Device{}
+	for i := range devices {
+		device := devices[i]
+		if device.Archived || device.Muted || device.WarrantyExpiresAt == nil {
+			continue
+		}
+		remaining := models.WarrantyDaysRemaining(*device.WarrantyExpiresAt, now)
+		switch {
+		case remaining < 0:
+			if device.WarrantyExpiredNotifiedAt == nil {
+				due = append(due, device)
+			}
+		case remaining <= notifyDays:
+			if device.WarrantyNotifiedAt == nil {
+				due = append(due, device)
+			}
+		}
+	}
+	return due
+}
+
+func (m *WarrantyMonitor) sendWarrantyNotification(deviceRepo database.DeviceRepo, device models.Device, now time.Time, settings *models.Settings) {
+	warranty := device.WarrantyStatus(now, settings.Metrics.WarrantyNotifyDays)
+	notification := notify.NewWarrantyExpiry(m.logger, m.appEngine.Config, &device, warranty)
+	notification.LoadDatabaseUrls(m.ctx, deviceRepo)
+
+	sent := false
+	if gate := m.appEngine.NotificationGate; gate != nil {
+		sent = gate.TrySend(&notification, settings, false)
+	} else if err := notification.Send(); err != nil {
+		m.logger.Errorf("Failed to send warranty expiry notification for device %s: %v", device.DeviceID, err)
+	} else {
+		sent = true
+	}
+	if !sent {
+		return
+	}
+
+	if warranty.State == models.WarrantyStateExpired {
+		if err := deviceRepo.MarkDeviceWarrantyExpiredNotified(m.ctx, device.DeviceID, now); err != nil {
+			m.logger.Errorf("Failed to record warranty expired notification for device %s: %v", device.DeviceID, err)
+			return
+		}
+		m.logger.Infof("Sent warranty expired notification for device %s", device.DeviceID)
+		return
+	}
+
+	if err := deviceRepo.MarkDeviceWarrantyNotified(m.ctx, device.DeviceID, now); err != nil {
+		m.logger.Errorf("Failed to record warranty expiry notification for device %s: %v", device.DeviceID, err)
+		return
+	}
+	m.logger.Infof("Sent warranty expiry notification for device %s (%d days remaining)", device.DeviceID, warranty.DaysRemaining)
+}
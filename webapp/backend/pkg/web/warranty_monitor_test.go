@@ -0,0 +1,38 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/analogj/scrutiny/webapp/backend/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueWarrantyNotifications(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	expiresIn := func(days int) *time.Time {
+		d := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		return &d
+	}
+	notifiedAt := now.Add(-time.Hour)
+
+	devices := []models.Device{
+		{DeviceID: "due", DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(10)}},
+		{DeviceID: "due-today", DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(0)}},
+		{DeviceID: "not-yet", DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(31)}},
+		{DeviceID: "expired", DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(-1)}},
+		{DeviceID: "no-warranty"},
+		{DeviceID: "already-notified", WarrantyNotifiedAt: &notifiedAt, DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(5)}},
+		{DeviceID: "archived", Archived: true, DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(5)}},
+		{DeviceID: "muted", Muted: true, DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(5)}},
+		{DeviceID: "expired-after-notice", WarrantyNotifiedAt: &notifiedAt, DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(-3)}},
+		{DeviceID: "expired-notified", WarrantyNotifiedAt: &notifiedAt, WarrantyExpiredNotifiedAt: &notifiedAt, DeviceInventory: models.DeviceInventory{WarrantyExpiresAt: expiresIn(-3)}},
+	}
+
+	due := dueWarrantyNotifications(devices, now, 30)
+	ids := []string{}
+	for _, device := range due {
+		ids = append(ids, device.DeviceID)
+	}
+	require.Equal(t, []string{"due", "due-today", "expired", "expired-after-notice"}, ids)
+}
@@ -143,6 +143,22 @@ export interface AppConfig {
         uptime_kuma_enabled?: boolean;
         uptime_kuma_push_url?: string;
         uptime_kuma_interval_seconds?: number;
+        // Warranty expiry notifications
+        notify_on_warranty_expiry?: boolean;
+        warranty_notify_days?: number;
+        // Incident escalation (0 disables)
+        incident_escalation_minutes?: number;
+        // Device added / disappeared / reappeared / swapped notifications
+        notify_on_lifecycle_events?: boolean;
+        // Resolved notifications (opt-in per event type)
+        notify_on_device_recovered?: boolean;
+        notify_on_missed_ping_recovered?: boolean;
+        notify_on_array_recovered?: boolean;
+        notify_on_zfs_pool_recovered?: boolean;
+        notify_on_collector_error_cleared?: boolean;
+        // Notification digests (0 minutes disables)
+        notification_digest_minutes?: number;
+        notification_digest_bypass_critical?: boolean;
         // Scheduled reports
         report_enabled?: boolean;
         report_daily_enabled?: boolean;
@@ -224,6 +240,17 @@ export const appConfig: AppConfig = {
         uptime_kuma_enabled: false,
         uptime_kuma_push_url: '',
         uptime_kuma_interval_seconds: 60,
+        notify_on_warranty_expiry: true,
+        warranty_notify_days: 30,
+        incident_escalation_minutes: 60,
+        notify_on_lifecycle_events: true,
+        notify_on_device_recovered: false,
+        notify_on_missed_ping_recovered: false,
+        notify_on_array_recovered: false,
+        notify_on_zfs_pool_recovered: false,
+        notify_on_collector_error_cleared: false,
+        notification_digest_minutes: 0,
+        notification_digest_bypass_critical: true,
         report_enabled: false,
         report_daily_enabled: false,
         consumer_drive_profiles_enabled: true,
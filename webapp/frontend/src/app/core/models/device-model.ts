@@ -36,4 +36,12 @@ export interface DeviceModel {
     device_status: number;
     has_forced_failure?: boolean;
     missed_ping_timeout_override?: number;
+
+    // inventory
+    purchase_date?: string;
+    warranty_expires_at?: string;
+    purchase_price?: number;
+    vendor?: string;
+    rma_contact?: string;
+    location?: string;
 }
@@ -7,6 +7,9 @@ export interface FilesystemCapacityModel {
     used_bytes: number;
     available_bytes: number;
     used_percent: number;
+    inodes_total?: number;
+    inodes_used?: number;
+    inodes_free?: number;
     updated_at: string;
 }
 